@@ -1,4 +1,5 @@
 using System;
+using System.Collections.Generic;
 using System.Linq;
 using System.Text.Json;
 using OpenRA.Mods.Common;
@@ -33,6 +34,9 @@ public static void Execute(string commandType, string dataJson, World world, IBo
 					case "deploy":
 						ExecuteDeploy(dataJson, world, bot);
 						break;
+					case "undeploy":
+						ExecuteUndeploy(dataJson, world, bot);
+						break;
 					case "repair_building":
 						ExecuteRepairBuilding(dataJson, world, bot);
 						break;
@@ -63,6 +67,15 @@ public static void Execute(string commandType, string dataJson, World world, IBo
 					case "place_minefield":
 						ExecutePlaceMinefield(dataJson, world, bot);
 						break;
+					case "guard":
+						ExecuteGuard(dataJson, world, bot);
+						break;
+					case "force_attack_ground":
+						ExecuteForceAttackGround(dataJson, world, bot);
+						break;
+					case "sell_building":
+						ExecuteSellBuilding(dataJson, world, bot);
+						break;
 					default:
 						Log.Write("debug", $"CommandExecutor: unknown command type '{commandType}'");
 						break;
@@ -82,8 +95,9 @@ static void ExecuteProduce(string dataJson, World world, IBot bot)
 			var queueType = root.GetProperty("queue").GetString();
 			var item = root.GetProperty("item").GetString();
 			var count = root.TryGetProperty("count", out var countProp) ? countProp.GetInt32() : 1;
+			var queueActor = root.TryGetProperty("queue_actor", out var actorProp) ? actorProp.GetUInt32() : 0;
 
-			var queue = FindQueue(bot, queueType);
+			var queue = queueActor != 0 ? FindQueueOn(bot, queueType, queueActor) : FindQueue(bot, queueType);
 			if (queue == null)
 			{
 				Log.Write("debug", $"CommandExecutor: produce — no queue of type '{queueType}'");
@@ -164,9 +178,10 @@ static void ExecuteAttackMove(string dataJson, World world, IBot bot)
 				return;
 			}
 
+			var queued = root.TryGetProperty("queued", out var queuedProp) && queuedProp.GetBoolean();
 			var target = Target.FromCell(world, new CPos(x, y));
-			bot.QueueOrder(new Order("AttackMove", null, target, false, groupedActors: actors));
-			Log.Write("debug", $"CommandExecutor: attack_move {actors.Length} units to ({x},{y})");
+			bot.QueueOrder(new Order("AttackMove", null, target, queued, groupedActors: actors));
+			Log.Write("debug", $"CommandExecutor: attack_move {actors.Length} units to ({x},{y}){(queued ? " (queued)" : "")}");
 		}
 
 		static void ExecuteMove(string dataJson, World world, IBot bot)
@@ -185,8 +200,9 @@ static void ExecuteMove(string dataJson, World world, IBot bot)
 				return;
 			}
 
-			bot.QueueOrder(new Order("Move", actor, Target.FromCell(world, new CPos(x, y)), false));
-			Log.Write("debug", $"CommandExecutor: move actor {actorId} to ({x},{y})");
+			var queued = root.TryGetProperty("queued", out var queuedProp) && queuedProp.GetBoolean();
+			bot.QueueOrder(new Order("Move", actor, Target.FromCell(world, new CPos(x, y)), queued));
+			Log.Write("debug", $"CommandExecutor: move actor {actorId} to ({x},{y}){(queued ? " (queued)" : "")}");
 		}
 
 		static void ExecuteSetRally(string dataJson, World world, IBot bot)
@@ -231,6 +247,32 @@ static void ExecuteDeploy(string dataJson, World world, IBot bot)
 			Log.Write("debug", $"CommandExecutor: deploy actor {actorId}");
 		}
 
+		static void ExecuteUndeploy(string dataJson, World world, IBot bot)
+		{
+			using var doc = JsonDocument.Parse(dataJson);
+			var root = doc.RootElement;
+
+			var actorId = root.GetProperty("actor_id").GetUInt32();
+
+			var actor = world.GetActorById(actorId);
+			if (!IsValidOwnedActor(actor, bot))
+			{
+				Log.Write("debug", $"CommandExecutor: undeploy — invalid actor {actorId}");
+				return;
+			}
+
+			// Construction yards only carry an enabled Transforms trait when
+			// redeployable MCVs are allowed in the lobby options.
+			if (!actor.TraitsImplementing<Transforms>().Any(t => !t.IsTraitDisabled))
+			{
+				Log.Write("debug", $"CommandExecutor: undeploy — actor {actorId} cannot undeploy");
+				return;
+			}
+
+			bot.QueueOrder(new Order("DeployTransform", actor, true));
+			Log.Write("debug", $"CommandExecutor: undeploy actor {actorId}");
+		}
+
 		static void ExecuteRepairBuilding(string dataJson, World world, IBot bot)
 		{
 			using var doc = JsonDocument.Parse(dataJson);
@@ -249,6 +291,24 @@ static void ExecuteRepairBuilding(string dataJson, World world, IBot bot)
 			Log.Write("debug", $"CommandExecutor: repair_building actor {actorId}");
 		}
 
+		static void ExecuteSellBuilding(string dataJson, World world, IBot bot)
+		{
+			using var doc = JsonDocument.Parse(dataJson);
+			var root = doc.RootElement;
+
+			var actorId = root.GetProperty("actor_id").GetUInt32();
+
+			var actor = world.GetActorById(actorId);
+			if (!IsValidOwnedActor(actor, bot))
+			{
+				Log.Write("debug", $"CommandExecutor: sell_building — invalid actor {actorId}");
+				return;
+			}
+
+			bot.QueueOrder(new Order("Sell", actor, false));
+			Log.Write("debug", $"CommandExecutor: sell_building actor {actorId}");
+		}
+
 		static void ExecuteAttack(string dataJson, World world, IBot bot)
 		{
 			using var doc = JsonDocument.Parse(dataJson);
@@ -284,10 +344,10 @@ static void ExecuteCancelProduction(string dataJson, World world, IBot bot)
 			var item = root.GetProperty("item").GetString();
 			var count = root.TryGetProperty("count", out var countProp) ? countProp.GetInt32() : 1;
 
-			var queue = FindQueue(bot, queueType);
+			var queue = FindQueueWithItem(bot, queueType, item);
 			if (queue == null)
 			{
-				Log.Write("debug", $"CommandExecutor: cancel_production — no queue of type '{queueType}'");
+				Log.Write("debug", $"CommandExecutor: cancel_production — no queue of type '{queueType}' has '{item}' queued");
 				return;
 			}
 
@@ -460,6 +520,67 @@ static void ExecutePlaceMinefield(string dataJson, World world, IBot bot)
 			Log.Write("debug", $"CommandExecutor: place_minefield actor {actorId} from ({startX},{startY}) to ({endX},{endY})");
 		}
 
+		static void ExecuteGuard(string dataJson, World world, IBot bot)
+		{
+			using var doc = JsonDocument.Parse(dataJson);
+			var root = doc.RootElement;
+
+			var actorIdsProp = root.GetProperty("actor_ids");
+			var targetId = root.GetProperty("target_id").GetUInt32();
+
+			var target = world.GetActorById(targetId);
+			if (!IsValidOwnedActor(target, bot) || !target.Info.HasTraitInfo<GuardableInfo>())
+			{
+				Log.Write("debug", $"CommandExecutor: guard — invalid or unguardable target {targetId}");
+				return;
+			}
+
+			var actors = actorIdsProp.EnumerateArray()
+				.Select(id => world.GetActorById(id.GetUInt32()))
+				.Where(a => IsValidOwnedActor(a, bot) && a.Info.HasTraitInfo<GuardInfo>())
+				.ToArray();
+
+			if (actors.Length == 0)
+			{
+				Log.Write("debug", "CommandExecutor: guard — no valid actors");
+				return;
+			}
+
+			bot.QueueOrder(new Order("Guard", null, Target.FromActor(target), false, groupedActors: actors));
+			Log.Write("debug", $"CommandExecutor: guard {actors.Length} units escorting actor {targetId}");
+		}
+
+		static void ExecuteForceAttackGround(string dataJson, World world, IBot bot)
+		{
+			using var doc = JsonDocument.Parse(dataJson);
+			var root = doc.RootElement;
+
+			var actorIdsProp = root.GetProperty("actor_ids");
+			var x = root.GetProperty("x").GetInt32();
+			var y = root.GetProperty("y").GetInt32();
+
+			var cell = new CPos(x, y);
+			if (!world.Map.Contains(cell))
+			{
+				Log.Write("debug", $"CommandExecutor: force_attack_ground — ({x},{y}) is off the map");
+				return;
+			}
+
+			var actors = actorIdsProp.EnumerateArray()
+				.Select(id => world.GetActorById(id.GetUInt32()))
+				.Where(a => IsValidOwnedActor(a, bot) && a.Info.HasTraitInfo<AttackBaseInfo>())
+				.ToArray();
+
+			if (actors.Length == 0)
+			{
+				Log.Write("debug", "CommandExecutor: force_attack_ground — no valid actors");
+				return;
+			}
+
+			bot.QueueOrder(new Order("ForceAttack", null, Target.FromCell(world, cell), false, groupedActors: actors));
+			Log.Write("debug", $"CommandExecutor: force_attack_ground {actors.Length} units at ({x},{y})");
+		}
+
 		static ProductionQueue FindQueue(IBot bot, string queueType)
 		{
 			return AIUtils.FindQueuesByCategory(bot.Player)
@@ -468,6 +589,26 @@ static ProductionQueue FindQueue(IBot bot, string queueType)
 				.FirstOrDefault(q => q.CurrentItem() == null || q.CurrentItem().Done);
 		}
 
+		// The queue of the given type with the item queued or in progress —
+		// FindQueue only returns idle queues, which have nothing to cancel.
+		static ProductionQueue FindQueueWithItem(IBot bot, string queueType, string item)
+		{
+			return AIUtils.FindQueuesByCategory(bot.Player)
+				.Where(q => q.Key == queueType)
+				.SelectMany(g => g)
+				.FirstOrDefault(q => q.AllQueued().Any(i => i.Item == item));
+		}
+
+		// Targeted production: the queue of the given type on a specific actor,
+		// so several same-type queues can each be started in one tick.
+		static ProductionQueue FindQueueOn(IBot bot, string queueType, uint actorId)
+		{
+			return AIUtils.FindQueuesByCategory(bot.Player)
+				.Where(q => q.Key == queueType)
+				.SelectMany(g => g)
+				.FirstOrDefault(q => q.Actor.ActorID == actorId);
+		}
+
 		static bool IsValidOwnedActor(Actor actor, IBot bot)
 		{
 			return actor != null && !actor.IsDead && actor.IsInWorld && actor.Owner == bot.Player;
@@ -494,6 +635,15 @@ static bool IsValidOwnedActor(Actor actor, IBot bot)
 				center = new CPos(centerX, centerY);
 			}
 
+			// Keep production exits clear: never cover an existing building's exit
+			// apron, and never put this building's own exits against a wall.
+			var reservedExits = new HashSet<CPos>();
+			foreach (var a in ownBuildings)
+				foreach (var exit in a.Info.TraitInfos<ExitInfo>())
+					reservedExits.UnionWith(ExitApron(a.Location + exit.ExitCell, a.Info.TraitInfo<BuildingInfo>().Tiles(a.Location)));
+
+			var ownExits = actorInfo.TraitInfos<ExitInfo>().ToArray();
+
 			// Search outward from center
 			foreach (var cell in world.Map.FindTilesInAnnulus(center, 0, 20))
 			{
@@ -503,10 +653,30 @@ static bool IsValidOwnedActor(Actor actor, IBot bot)
 				if (!bi.IsCloseEnoughToBase(world, player, actorInfo, cell))
 					continue;
 
+				var tiles = bi.Tiles(cell).ToArray();
+				if (tiles.Any(reservedExits.Contains))
+					continue;
+
+				if (ownExits.Any(exit => ExitApron(cell + exit.ExitCell, tiles).Any(c => IsBuildingAt(world, c))))
+					continue;
+
 				return cell;
 			}
 
 			return null;
 		}
+
+		// ExitApron returns the cells around an exit cell that lie outside the
+		// building's own footprint — where produced units step out.
+		static IEnumerable<CPos> ExitApron(CPos exitCell, IEnumerable<CPos> footprint)
+		{
+			var own = footprint.ToHashSet();
+			return CVec.Directions.Select(d => exitCell + d).Where(c => !own.Contains(c));
+		}
+
+		static bool IsBuildingAt(World world, CPos cell)
+		{
+			return world.ActorMap.GetActorsAt(cell).Any(a => a.Info.HasTraitInfo<BuildingInfo>());
+		}
 	}
 }
@@ -61,12 +61,18 @@ public class UnitData : ActorData
 
 		[JsonPropertyName("cargoCount")]
 		public int CargoCount { get; set; }
+
+		[JsonPropertyName("veterancy")]
+		public int Veterancy { get; set; }
 	}
 
 	public class EnemyActorData : ActorData
 	{
 		[JsonPropertyName("owner")]
 		public string Owner { get; set; }
+
+		[JsonPropertyName("veterancy")]
+		public int Veterancy { get; set; }
 	}
 
 	public class SupportPowerData
@@ -84,8 +90,17 @@ public class SupportPowerData
 		public int TotalTicks { get; set; }
 	}
 
+	public class EnemySupportPowerData : SupportPowerData
+	{
+		[JsonPropertyName("owner")]
+		public string Owner { get; set; }
+	}
+
 	public class ProductionQueueData
 	{
+		[JsonPropertyName("actorId")]
+		public uint ActorId { get; set; }
+
 		[JsonPropertyName("type")]
 		public string Type { get; set; }
 
@@ -102,6 +117,18 @@ public class ProductionQueueData
 		public int CurrentProgress { get; set; }
 	}
 
+	public class SpawnPointData
+	{
+		[JsonPropertyName("x")]
+		public int X { get; set; }
+
+		[JsonPropertyName("y")]
+		public int Y { get; set; }
+
+		[JsonPropertyName("own")]
+		public bool Own { get; set; }
+	}
+
 	public class GameStateData
 	{
 		[JsonPropertyName("tick")]
@@ -125,14 +152,23 @@ public class GameStateData
 		[JsonPropertyName("capturables")]
 		public List<EnemyActorData> Capturables { get; set; } = new();
 
+		[JsonPropertyName("obstacles")]
+		public List<ActorData> Obstacles { get; set; } = new();
+
 		[JsonPropertyName("supportPowers")]
 		public List<SupportPowerData> SupportPowers { get; set; } = new();
 
+		[JsonPropertyName("enemySupportPowers")]
+		public List<EnemySupportPowerData> EnemySupportPowers { get; set; } = new();
+
 		[JsonPropertyName("mapWidth")]
 		public int MapWidth { get; set; }
 
 		[JsonPropertyName("mapHeight")]
 		public int MapHeight { get; set; }
+
+		[JsonPropertyName("spawnPoints")]
+		public List<SpawnPointData> SpawnPoints { get; set; } = new();
 	}
 
 	public static class GameStateSerializer
@@ -153,9 +189,12 @@ public static string Serialize(World world, IBot bot)
 				ProductionQueues = SerializeProductionQueues(bot),
 				Enemies = SerializeEnemies(world, bot),
 				Capturables = SerializeCapturables(world, bot),
+				Obstacles = SerializeObstacles(world, bot),
 				SupportPowers = SerializeSupportPowers(bot),
+				EnemySupportPowers = SerializeEnemySupportPowers(world, bot),
 				MapWidth = world.Map.MapSize.X,
-				MapHeight = world.Map.MapSize.Y
+				MapHeight = world.Map.MapSize.Y,
+				SpawnPoints = SerializeSpawnPoints(world, bot)
 			};
 
 			return JsonSerializer.Serialize(state, JsonOptions);
@@ -226,7 +265,8 @@ static List<UnitData> SerializeUnits(World world, IBot bot)
 					Hp = health?.HP ?? 0,
 					MaxHp = health?.MaxHP ?? 0,
 					Idle = IsEffectivelyIdle(actor),
-					CargoCount = actor.TraitOrDefault<Cargo>()?.PassengerCount ?? 0
+					CargoCount = actor.TraitOrDefault<Cargo>()?.PassengerCount ?? 0,
+					Veterancy = actor.TraitOrDefault<GainsExperience>()?.Level ?? 0
 				});
 			}
 
@@ -272,6 +312,7 @@ static List<ProductionQueueData> SerializeProductionQueues(IBot bot)
 				{
 					var queueData = new ProductionQueueData
 					{
+						ActorId = queue.Actor.ActorID,
 						Type = queue.Info.Type
 					};
 
@@ -321,13 +362,49 @@ static List<SupportPowerData> SerializeSupportPowers(IBot bot)
 			return powers;
 		}
 
+		// Only powers whose countdown the owner shares with enemies (e.g. the
+		// RA nuke timer) are included — no peeking at hidden timers.
+		static List<EnemySupportPowerData> SerializeEnemySupportPowers(World world, IBot bot)
+		{
+			var powers = new List<EnemySupportPowerData>();
+			foreach (var player in world.Players)
+			{
+				if (bot.Player.RelationshipWith(player) != PlayerRelationship.Enemy)
+					continue;
+
+				var spm = player.PlayerActor.TraitOrDefault<SupportPowerManager>();
+				if (spm == null)
+					continue;
+
+				foreach (var kvp in spm.Powers)
+				{
+					var instance = kvp.Value;
+					if (instance.Disabled || !instance.Info.DisplayTimerRelationships.HasRelationship(PlayerRelationship.Enemy))
+						continue;
+
+					powers.Add(new EnemySupportPowerData
+					{
+						Owner = player.PlayerName,
+						Key = instance.Key,
+						Ready = instance.Ready,
+						RemainingTicks = instance.RemainingTicks,
+						TotalTicks = instance.TotalTicks
+					});
+				}
+			}
+
+			return powers;
+		}
+
 		static List<EnemyActorData> SerializeCapturables(World world, IBot bot)
 		{
 			var capturables = new List<EnemyActorData>();
 
+			// Neutral tech buildings only. Enemy buildings are capturable too, but
+			// the sidecar picks those from the enemy list when an assault covers them.
 			foreach (var actor in world.ActorsHavingTrait<Capturable>())
 			{
-				if (actor.Owner == bot.Player || actor.IsDead || !actor.IsInWorld)
+				if (!actor.Owner.NonCombatant || actor.IsDead || !actor.IsInWorld)
 					continue;
 
 				if (!actor.CanBeViewedByPlayer(bot.Player))
@@ -349,6 +426,54 @@ static List<EnemyActorData> SerializeCapturables(World world, IBot bot)
 			return capturables;
 		}
 
+		// Destructible neutral obstacles (trees, walls, barrels) that force-fire
+		// can clear. Capturable tech buildings are reported as capturables instead.
+		static List<ActorData> SerializeObstacles(World world, IBot bot)
+		{
+			var obstacles = new List<ActorData>();
+
+			foreach (var actor in world.ActorsHavingTrait<Health>())
+			{
+				if (!actor.Owner.NonCombatant || actor.IsDead || !actor.IsInWorld)
+					continue;
+
+				if (actor.Info.HasTraitInfo<CapturableInfo>() || actor.Info.HasTraitInfo<MobileInfo>())
+					continue;
+
+				if (!actor.CanBeViewedByPlayer(bot.Player))
+					continue;
+
+				var health = actor.Trait<Health>();
+				obstacles.Add(new ActorData
+				{
+					Type = actor.Info.Name,
+					Id = actor.ActorID,
+					X = actor.Location.X,
+					Y = actor.Location.Y,
+					Hp = health.HP,
+					MaxHp = health.MaxHP
+				});
+			}
+
+			return obstacles;
+		}
+
+		// Map starting locations. Every player knows where the spawns are from
+		// the map preview, but not which one each opponent took.
+		static List<SpawnPointData> SerializeSpawnPoints(World world, IBot bot)
+		{
+			var home = bot.Player.HomeLocation;
+			return world.Actors
+				.Where(a => a.Info.Name == "mpspawn")
+				.Select(a => new SpawnPointData
+				{
+					X = a.Location.X,
+					Y = a.Location.Y,
+					Own = a.Location == home
+				})
+				.ToList();
+		}
+
 		static List<EnemyActorData> SerializeEnemies(World world, IBot bot)
 		{
 			var enemies = new List<EnemyActorData>();
@@ -377,7 +502,8 @@ static List<EnemyActorData> SerializeEnemies(World world, IBot bot)
 					X = actor.Location.X,
 					Y = actor.Location.Y,
 					Hp = health?.HP ?? 0,
-					MaxHp = health?.MaxHP ?? 0
+					MaxHp = health?.MaxHP ?? 0,
+					Veterancy = actor.TraitOrDefault<GainsExperience>()?.Level ?? 0
 				});
 			}
 
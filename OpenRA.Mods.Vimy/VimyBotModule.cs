@@ -29,6 +29,9 @@ public class VimyBotModule : ConditionalTrait<VimyBotModuleInfo>, IBotTick, IBot
 		bool connected;
 		string playerName;
 
+		// Stable for the lifetime of this game so the sidecar can resume state after a reconnect.
+		readonly string sessionKey = Guid.NewGuid().ToString("N");
+
 		public VimyBotModule(ActorInitializer init, VimyBotModuleInfo info)
 			: base(info)
 		{
@@ -68,10 +71,11 @@ void TryConnect(IBot bot)
 		void SendHello(IBot bot)
 		{
 			var faction = bot.Player.Faction.InternalName;
+			var mod = Game.ModData.Manifest.Id;
 			var terrainJson = TerrainGridSerializer.Serialize(world);
-			var data = $"{{\"player\":\"{bot.Player.PlayerName}\",\"faction\":\"{faction}\",\"terrain\":{terrainJson}}}";
+			var data = $"{{\"player\":\"{bot.Player.PlayerName}\",\"faction\":\"{faction}\",\"mod\":\"{mod}\",\"session\":\"{sessionKey}\",\"terrain\":{terrainJson}}}";
 			SendEnvelope("hello", data);
-			Log.Write("debug", $"Sent hello for player {bot.Player.PlayerName}, faction {faction} (terrain grid included)");
+			Log.Write("debug", $"Sent hello for player {bot.Player.PlayerName}, faction {faction}, mod {mod}, session {sessionKey} (terrain grid included)");
 		}
 
 		void IBotTick.BotTick(IBot bot)
@@ -140,6 +144,7 @@ void ReadMessages(IBot bot)
 						case "move":
 						case "set_rally":
 						case "deploy":
+						case "undeploy":
 						case "repair_building":
 						case "attack":
 						case "cancel_production":
@@ -148,7 +153,19 @@ void ReadMessages(IBot bot)
 						case "support_power":
 						case "enter_transport":
 						case "unload":
+						case "guard":
+						case "force_attack_ground":
+						case "sell_building":
 							CommandExecutor.Execute(envelope.Value.Type, envelope.Value.Data, world, bot);
+							break;
+						case "ping":
+							// Echo the heartbeat after the commands read ahead of it, stamped with the tick they were applied on.
+							using (var ping = JsonDocument.Parse(envelope.Value.Data))
+							{
+								var seq = ping.RootElement.GetProperty("seq").GetUInt64();
+								SendEnvelope("pong", $"{{\"seq\":{seq},\"tick\":{world.WorldTick}}}");
+							}
+
 							break;
 						default:
 							Log.Write("debug", $"Unknown message type: {envelope.Value.Type}");
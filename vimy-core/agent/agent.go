@@ -18,11 +18,26 @@ type Agent struct {
 	Faction    string
 	Engine     *rules.Engine
 	Strategist *Strategist
-	ctx        context.Context
+	Sessions   *SessionStore
+	// Checkpoints persists the session's state to disk and restores it on
+	// a hello whose session isn't held in memory; nil disables it.
+	Checkpoints *Checkpointer
+
+	session        string
+	lastCheckpoint int // tick of the last checkpoint saved or restored
+	ctx            context.Context
 }
 
-func New(conn *ipc.Connection, engine *rules.Engine, strategist *Strategist, ctx context.Context) *Agent {
-	return &Agent{Conn: conn, Engine: engine, Strategist: strategist, ctx: ctx}
+func New(conn *ipc.Connection, engine *rules.Engine, strategist *Strategist, sessions *SessionStore, ctx context.Context) *Agent {
+	return &Agent{Conn: conn, Engine: engine, Strategist: strategist, Sessions: sessions, ctx: ctx}
+}
+
+// Detach releases the agent's session when its connection closes, leaving
+// it available for a reconnect within the store's window.
+func (a *Agent) Detach() {
+	if a.Sessions != nil {
+		a.Sessions.Detach(a.session)
+	}
 }
 
 // HandleHello completes the handshake so the mod knows the bridge is ready.
@@ -34,8 +49,21 @@ func (a *Agent) HandleHello(env ipc.Envelope) (*ipc.Envelope, error) {
 
 	a.Player = hello.Player
 	a.Faction = hello.Faction
-	slog.Info("player identified", "player", a.Player, "faction", a.Faction)
+	slog.Info("player identified", "player", a.Player, "faction", a.Faction, "mod", hello.Mod, "session", hello.Session)
 
+	mod := rules.RA
+	if hello.Mod != "" {
+		if m, ok := rules.LookupMod(hello.Mod); ok {
+			mod = m
+		} else {
+			slog.Warn("unknown mod — playing with Red Alert roles", "mod", hello.Mod, "known", rules.ModNames())
+		}
+	}
+	a.Engine.SetMod(mod)
+	a.Engine.SetFaction(hello.Faction)
+
+	// Terrain goes in before a checkpoint is restored: the restored
+	// doctrine is compiled for this map.
 	if hello.Terrain != nil {
 		grid := &model.TerrainGrid{
 			Cols:  hello.Terrain.Cols,
@@ -52,9 +80,27 @@ func (a *Agent) HandleHello(env ipc.Envelope) (*ipc.Envelope, error) {
 		slog.Warn("no terrain data in hello — terrain awareness disabled")
 	}
 
-	if a.Strategist != nil {
+	ctx, resumed := a.ctx, false
+	if a.Sessions != nil {
+		a.session = hello.Session
+		ctx, resumed = a.Sessions.Attach(a.ctx, hello.Session)
+	}
+	if !resumed {
+		// The engine outlives connections; a new game must not inherit the
+		// previous game's squads, intel, or cooldowns.
+		a.Engine.ResetMemory()
+		if a.Strategist != nil {
+			a.Strategist.NewGame()
+		}
+		a.restoreCheckpoint()
+	}
+
+	if a.Strategist != nil && a.Conn != nil {
+		a.Strategist.SetLink(a.Conn.Heartbeat())
+	}
+	if a.Strategist != nil && !resumed {
 		a.Strategist.SetFaction(hello.Faction)
-		go a.Strategist.Start(a.ctx)
+		go a.Strategist.Start(ctx)
 	}
 
 	ack, err := ipc.NewEnvelope(ipc.TypeAck, ipc.AckMessage{Status: "ok"})
@@ -69,6 +115,13 @@ func (a *Agent) HandleGameState(env ipc.Envelope) (*ipc.Envelope, error) {
 	if err := json.Unmarshal(env.Data, &gs); err != nil {
 		return nil, fmt.Errorf("unmarshal GameState: %w", err)
 	}
+	fixes, err := gs.Sanitize()
+	if err != nil {
+		return nil, fmt.Errorf("reject GameState: %w", err)
+	}
+	if len(fixes) > 0 {
+		slog.Warn("sanitized game state", "tick", gs.Tick, "fixes", fixes)
+	}
 
 	unitTypes := make(map[string]int)
 	for _, u := range gs.Units {
@@ -94,14 +147,70 @@ func (a *Agent) HandleGameState(env ipc.Envelope) (*ipc.Envelope, error) {
 	if err := a.Engine.Evaluate(gs, a.Faction, a.Conn); err != nil {
 		slog.Error("rule engine error", "error", err)
 	}
+	if a.Conn != nil {
+		if err := a.Conn.Ping(gs.Tick); err != nil {
+			slog.Error("heartbeat ping failed", "error", err)
+		}
+	}
 
 	if a.Strategist != nil {
 		a.Strategist.UpdateState(gs)
 	}
 
+	if a.Checkpoints != nil && a.session != "" && a.Checkpoints.due(a.lastCheckpoint, gs.Tick) {
+		a.saveCheckpoint(gs.Tick)
+	}
+
 	ack, err := ipc.NewEnvelope(ipc.TypeAck, ipc.AckMessage{Status: "ok"})
 	if err != nil {
 		return nil, err
 	}
 	return &ack, nil
 }
+
+// restoreCheckpoint reloads the session's checkpoint, if any, into the
+// freshly reset engine: the doctrine first, since swapping its rules in
+// drops squads, then the memory. A session with no checkpoint is a new
+// game, which supersedes every older checkpoint.
+func (a *Agent) restoreCheckpoint() {
+	if a.Checkpoints == nil || a.session == "" {
+		return
+	}
+	cp, err := a.Checkpoints.load(a.session)
+	if err != nil {
+		slog.Error("checkpoint unreadable, starting fresh", "session", a.session, "error", err)
+		return
+	}
+	if cp == nil {
+		a.Checkpoints.prune(a.session)
+		return
+	}
+	if cp.Doctrine != nil && a.Strategist != nil {
+		if err := a.Strategist.RestoreDoctrine(*cp.Doctrine, cp.Tick); err != nil {
+			slog.Error("checkpoint doctrine rejected", "session", a.session, "doctrine", cp.Doctrine.Name, "error", err)
+		}
+	}
+	if err := a.Engine.RestoreMemory(cp.Memory); err != nil {
+		slog.Error("checkpoint memory rejected, starting fresh", "session", a.session, "error", err)
+		return
+	}
+	a.lastCheckpoint = cp.Tick
+	slog.Info("session restored from checkpoint", "session", a.session, "tick", cp.Tick, "saved", cp.Saved)
+}
+
+// saveCheckpoint persists the session's state. Failures are logged and
+// retried at the next interval; the game goes on regardless.
+func (a *Agent) saveCheckpoint(tick int) {
+	var doctrine *rules.Doctrine
+	if a.Strategist != nil {
+		if rec := a.Strategist.GetCurrentDoctrine(); rec != nil {
+			doctrine = &rec.Doctrine
+		}
+	}
+	a.lastCheckpoint = tick
+	if err := a.Checkpoints.save(a.session, tick, a.Engine, doctrine); err != nil {
+		slog.Error("checkpoint failed", "session", a.session, "tick", tick, "error", err)
+		return
+	}
+	slog.Debug("checkpoint saved", "session", a.session, "tick", tick)
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+
+	baml_client "github.com/nstehr/vimy/vimy-core/baml_client"
+)
+
+// Budget caps the strategist's LLM use per game. Zero fields are unlimited.
+// Once either cap is reached the strategist stops calling the LLM and the
+// game plays out on the doctrine it already has — or, if no doctrine has
+// been applied yet, on rules.DefaultDoctrine.
+type Budget struct {
+	MaxEvaluations int   `json:"max_evaluations"`
+	MaxTokens      int64 `json:"max_tokens"` // input plus output tokens
+}
+
+// BudgetStatus is the strategist's LLM spend this game against its Budget.
+type BudgetStatus struct {
+	Budget
+	Evaluations int   `json:"evaluations"` // LLM calls made, failed ones included
+	Tokens      int64 `json:"tokens"`
+	Exhausted   bool  `json:"exhausted"`
+}
+
+// exhaustedBy names the cap the spend has reached, or returns "" while
+// there is budget left.
+func (b BudgetStatus) exhaustedBy() string {
+	switch {
+	case b.MaxEvaluations > 0 && b.Evaluations >= b.MaxEvaluations:
+		return fmt.Sprintf("max evaluations (%d)", b.MaxEvaluations)
+	case b.MaxTokens > 0 && b.Tokens >= b.MaxTokens:
+		return fmt.Sprintf("token budget (%d of %d)", b.Tokens, b.MaxTokens)
+	}
+	return ""
+}
+
+// tokenCollector returns a BAML collector for counting one call's tokens,
+// or nil when the runtime can't make one; the call is then uncounted.
+func tokenCollector() baml_client.Collector {
+	c, err := baml_client.NewCollector("strategist")
+	if err != nil {
+		slog.Warn("LLM token accounting unavailable", "error", err)
+		return nil
+	}
+	return c
+}
+
+// collectedTokens returns the input and output tokens c has seen.
+func collectedTokens(c baml_client.Collector) int64 {
+	if c == nil {
+		return 0
+	}
+	usage, err := c.Usage()
+	if err != nil {
+		slog.Warn("reading LLM token usage failed", "error", err)
+		return 0
+	}
+	in, _ := usage.InputTokens()
+	out, _ := usage.OutputTokens()
+	return in + out
+}
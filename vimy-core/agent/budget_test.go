@@ -0,0 +1,22 @@
+package agent
+
+import "testing"
+
+func TestBudgetExhaustedBy(t *testing.T) {
+	cases := []struct {
+		name string
+		b    BudgetStatus
+		out  bool
+	}{
+		{"unlimited", BudgetStatus{Evaluations: 500, Tokens: 1 << 30}, false},
+		{"evaluations left", BudgetStatus{Budget: Budget{MaxEvaluations: 10}, Evaluations: 9}, false},
+		{"evaluations spent", BudgetStatus{Budget: Budget{MaxEvaluations: 10}, Evaluations: 10}, true},
+		{"tokens left", BudgetStatus{Budget: Budget{MaxTokens: 5000}, Tokens: 4999}, false},
+		{"tokens spent", BudgetStatus{Budget: Budget{MaxTokens: 5000}, Tokens: 6200}, true},
+	}
+	for _, c := range cases {
+		if got := c.b.exhaustedBy() != ""; got != c.out {
+			t.Errorf("%s: exhausted = %v, want %v", c.name, got, c.out)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// Checkpointer writes the engine's memory and the strategist's doctrine to
+// disk every few hundred ticks, one file per session key. When vimy-core
+// crashes mid-match and the mod reconnects with the same key, the new
+// process restores the checkpoint instead of starting from amnesia. The
+// SessionStore covers dropped connections within one process; checkpoints
+// cover the process itself going away.
+type Checkpointer struct {
+	dir      string
+	interval int // ticks between checkpoints
+}
+
+// checkpoint is the on-disk form of one session's state.
+type checkpoint struct {
+	Session  string          `json:"session"`
+	Tick     int             `json:"tick"`
+	Saved    time.Time       `json:"saved"`
+	Doctrine *rules.Doctrine `json:"doctrine,omitempty"` // nil until the strategist has produced one
+	Memory   json.RawMessage `json:"memory"`
+}
+
+// NewCheckpointer creates dir if needed and checkpoints every interval ticks.
+func NewCheckpointer(dir string, interval int) (*Checkpointer, error) {
+	if interval <= 0 {
+		interval = 250
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create checkpoint dir: %w", err)
+	}
+	return &Checkpointer{dir: dir, interval: interval}, nil
+}
+
+// due reports whether a checkpoint taken at last is stale at tick.
+func (c *Checkpointer) due(last, tick int) bool {
+	return tick-last >= c.interval
+}
+
+// unsafeFileChars matches anything that shouldn't appear in a file name.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+func (c *Checkpointer) path(session string) string {
+	return filepath.Join(c.dir, unsafeFileChars.ReplaceAllString(session, "_")+".json")
+}
+
+// save checkpoints the engine's memory and doctrine (nil if none) for
+// session. The file is written beside its final name and renamed into
+// place, so a crash mid-write leaves the previous checkpoint intact.
+func (c *Checkpointer) save(session string, tick int, engine *rules.Engine, doctrine *rules.Doctrine) error {
+	mem, err := engine.MarshalMemory()
+	if err != nil {
+		return fmt.Errorf("marshal memory: %w", err)
+	}
+	data, err := json.Marshal(checkpoint{Session: session, Tick: tick, Saved: time.Now(), Doctrine: doctrine, Memory: mem})
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	f, err := os.CreateTemp(c.dir, ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("create checkpoint: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(f.Name(), c.path(session)); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("commit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// load returns the session's checkpoint, or nil if there is none.
+func (c *Checkpointer) load(session string) (*checkpoint, error) {
+	data, err := os.ReadFile(c.path(session))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+	if cp.Session != session {
+		// Two keys that sanitise to the same file name.
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// prune removes every checkpoint except keep's. A new game supersedes the
+// old ones, as it does detached sessions in the SessionStore.
+func (c *Checkpointer) prune(keep string) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		slog.Warn("list checkpoints failed", "dir", c.dir, "error", err)
+		return
+	}
+	kept := filepath.Base(c.path(keep))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") || e.Name() == kept {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+			slog.Warn("remove checkpoint failed", "file", e.Name(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func hello(t *testing.T, a *Agent, session string) {
+	t.Helper()
+	env, err := ipc.NewEnvelope(ipc.TypeHello, ipc.HelloMessage{Player: "vimy", Faction: "soviet", Session: session})
+	if err != nil {
+		t.Fatalf("NewEnvelope: %v", err)
+	}
+	if _, err := a.HandleHello(env); err != nil {
+		t.Fatalf("HandleHello: %v", err)
+	}
+}
+
+func TestCheckpointRestoredAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	checkpoints, err := NewCheckpointer(dir, 100)
+	if err != nil {
+		t.Fatalf("NewCheckpointer: %v", err)
+	}
+
+	crashed, _ := rules.NewEngine(rules.DefaultRules())
+	crashed.SetPriorityTarget(rules.PriorityTarget{EnemyID: 42, Reason: "test"})
+	if err := checkpoints.save("game-1", 1200, crashed, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// A fresh process: new engine, new session store, same checkpoint dir.
+	engine, _ := rules.NewEngine(rules.DefaultRules())
+	a := New(nil, engine, nil, NewSessionStore(time.Minute), context.Background())
+	a.Checkpoints = checkpoints
+	hello(t, a, "game-1")
+
+	if targets := engine.PriorityTargets(); len(targets) != 1 || targets[0].EnemyID != 42 {
+		t.Errorf("priority targets after restore = %+v, want enemy 42", targets)
+	}
+	if checkpoints.due(a.lastCheckpoint, 1250) {
+		t.Error("the restored checkpoint's tick should count as the last checkpoint")
+	}
+}
+
+func TestNewSessionPrunesOldCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	checkpoints, _ := NewCheckpointer(dir, 100)
+	engine, _ := rules.NewEngine(rules.DefaultRules())
+	if err := checkpoints.save("game-1", 500, engine, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	a := New(nil, engine, nil, NewSessionStore(time.Minute), context.Background())
+	a.Checkpoints = checkpoints
+	hello(t, a, "game-2")
+
+	if _, err := os.Stat(filepath.Join(dir, "game-1.json")); !os.IsNotExist(err) {
+		t.Error("a new game should remove the previous game's checkpoint")
+	}
+	if cp, err := checkpoints.load("game-2"); err != nil || cp != nil {
+		t.Errorf("load of a session never saved = %v, %v; want nil, nil", cp, err)
+	}
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/nstehr/vimy/vimy-core/model"
@@ -21,6 +22,11 @@ const (
 	EventSuperweaponReady     EventKind = "superweapon_ready"
 	EventFirstContact         EventKind = "first_contact"
 	EventStrategyCountered    EventKind = "strategy_countered"
+	EventRushDetected         EventKind = "rush_detected"
+	EventBaseSieged           EventKind = "base_sieged"
+	EventContained            EventKind = "contained"
+	EventProductionStalled    EventKind = "production_stalled"
+	EventIncomingAttack       EventKind = "incoming_attack"
 )
 
 // Event represents a significant game event detected by diffing consecutive
@@ -43,6 +49,15 @@ type stateSnapshot struct {
 	hasEnemyBase bool   // building-based enemy intel exists
 	superReady   map[string]bool
 	enemiesSeen  bool // any enemies visible
+	rushActive   bool // rule engine has a rush alert raised
+	incoming     bool // enemy force heading for our base (rule engine incoming-attack warning)
+	incomingN    int
+	incomingETA  int
+	sieged       bool // enemy camped outside our base (rule engine siege watch)
+	siegeCampers int
+	contained    bool // our attack squads keep getting wiped near home
+	containWipes int
+	stalled      []string // production queues the rule engine flags as chronically idle
 
 	// Per-domain unit tracking for strategy_countered detection
 	infantryIDs map[int]bool
@@ -235,6 +250,15 @@ func takeSnapshot(gs model.GameState, memory map[string]any) stateSnapshot {
 		cash:         gs.Player.Cash + gs.Player.Resources,
 		phase:        gamePhase(gs),
 		enemiesSeen:  len(gs.Enemies) > 0,
+		rushActive:   rules.RushActive(memory),
+		incoming:     rules.IncomingAttackActive(memory),
+		incomingN:    rules.IncomingAttackers(memory),
+		incomingETA:  rules.IncomingAttackETA(memory),
+		sieged:       rules.BaseSieged(memory),
+		siegeCampers: rules.SiegeCampers(memory),
+		contained:    rules.BaseContained(memory, gs.Tick),
+		containWipes: rules.ContainedWipes(memory, gs.Tick),
+		stalled:      rules.StalledQueues(memory),
 		superReady:   make(map[string]bool),
 		harvesterCnt: 0,
 		infantryIDs:  make(map[int]bool),
@@ -271,12 +295,10 @@ func takeSnapshot(gs model.GameState, memory map[string]any) stateSnapshot {
 	}
 
 	// Check for building-based enemy intel
-	if bases, ok := memory["enemyBases"].(map[string]rules.EnemyBaseIntel); ok {
-		for _, base := range bases {
-			if base.FromBuildings {
-				snap.hasEnemyBase = true
-				break
-			}
+	for _, base := range rules.GetEnemyBases(memory) {
+		if base.FromBuildings {
+			snap.hasEnemyBase = true
+			break
 		}
 	}
 
@@ -372,7 +394,62 @@ func detectEvents(gs model.GameState, memory map[string]any, prev *stateSnapshot
 		})
 	}
 
-	// 8. strategy_countered: forces being hard-countered by enemy composition.
+	// 8. rush_detected: the rule engine raised an early rush alert
+	if !prev.rushActive && cur.rushActive {
+		events = append(events, Event{
+			Kind:   EventRushDetected,
+			Tick:   gs.Tick,
+			Detail: fmt.Sprintf("Rush detected: enemy combat units at our base at tick %d with our army small", gs.Tick),
+		})
+	}
+
+	// 9. base_sieged: enemy camped outside the base without attacking. Ground
+	// breakouts into a prepared siege are costly, so hint at other domains.
+	if !prev.sieged && cur.sieged {
+		events = append(events, Event{
+			Kind: EventBaseSieged,
+			Tick: gs.Tick,
+			Detail: fmt.Sprintf("Base sieged: %d enemy units camped outside our base without attacking; "+
+				"consider breaking out by air or sea, or a superweapon-centric doctrine", cur.siegeCampers),
+		})
+	}
+
+	// 10. contained: our attack squads keep being destroyed near our own base
+	if !prev.contained && cur.contained {
+		events = append(events, Event{
+			Kind: EventContained,
+			Tick: gs.Tick,
+			Detail: fmt.Sprintf("Contained: %d attack squads wiped out near our own base recently; "+
+				"ground pushes are failing, consider air, naval or superweapon-centric doctrines", cur.containWipes),
+		})
+	}
+
+	// 11. production_stalled: a unit queue the doctrine wants has sat idle
+	// with cash in hand — usually a rule that can never fire.
+	for _, q := range cur.stalled {
+		if slices.Contains(prev.stalled, q) {
+			continue
+		}
+		events = append(events, Event{
+			Kind: EventProductionStalled,
+			Tick: gs.Tick,
+			Detail: fmt.Sprintf("Production stalled: %s queue idle most of the last %d ticks despite cash and doctrine weight; "+
+				"it may be unable to build what the doctrine asks for, consider shifting weight or preferred units", q, rules.QueueWatchWindowTicks),
+		})
+	}
+
+	// 12. incoming_attack: an enemy force is heading for our base but hasn't
+	// reached it — earlier warning than BaseUnderAttack.
+	if !prev.incoming && cur.incoming {
+		events = append(events, Event{
+			Kind: EventIncomingAttack,
+			Tick: gs.Tick,
+			Detail: fmt.Sprintf("Incoming attack: %d enemy units heading for our base, due in about %d ticks; "+
+				"defenders are moving to meet them, consider shoring up base defense", cur.incomingN, cur.incomingETA),
+		})
+	}
+
+	// 13. strategy_countered: forces being hard-countered by enemy composition.
 	// Fires when we lose 3+ units in a domain AND relevant enemy counter-threats
 	// are visible. 200-tick cooldown prevents spam during prolonged battles.
 	if prev.lastCounterTick == 0 || gs.Tick-prev.lastCounterTick >= counterCooldownTicks {
@@ -1024,3 +1024,28 @@ func containsStr(s, sub string) bool {
 	}
 	return false
 }
+
+func TestDetectEvents_Contained(t *testing.T) {
+	gs := baseGameState(5000)
+	memory := make(map[string]any)
+	prev := takeSnapshot(gs, memory)
+
+	// Two attack squads wiped out near home within the window.
+	gs.Tick = 5001
+	memory["attackHistory"] = []rules.AttackOutcome{
+		{Squad: "ground-attack", End: 4000, Wiped: true, NearBase: true},
+		{Squad: "ground-attack", End: 5000, Wiped: true, NearBase: true},
+	}
+
+	events := detectEvents(gs, memory, &prev)
+	found := false
+	for _, e := range events {
+		if e.Kind == EventContained {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("expected contained event, got %+v", events)
+	}
+}
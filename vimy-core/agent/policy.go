@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Severity decides how urgently an event asks the strategist to re-evaluate.
+type Severity int
+
+const (
+	// SeverityLow events never trigger on their own; they are batched into
+	// the next interval (or higher-severity) evaluation.
+	SeverityLow Severity = iota
+	// SeverityMedium events trigger once the strategist's global cooldown
+	// since the last evaluation has passed.
+	SeverityMedium
+	// SeverityCritical events trigger immediately, ignoring the global cooldown.
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "medium"
+	}
+}
+
+// ParseSeverity parses "low", "medium" or "critical".
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "low":
+		return SeverityLow, nil
+	case "medium", "":
+		return SeverityMedium, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityMedium, fmt.Errorf("unknown severity %q", s)
+}
+
+// MarshalJSON encodes the severity by name.
+func (s Severity) MarshalJSON() ([]byte, error) { return json.Marshal(s.String()) }
+
+// UnmarshalJSON decodes a severity name.
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	v, err := ParseSeverity(name)
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// TriggerPolicy is how one kind of event triggers re-evaluation. Cooldown is
+// the minimum ticks between evaluations triggered by that kind, on top of
+// the severity rules, so a flapping event can't keep the LLM busy.
+type TriggerPolicy struct {
+	Severity Severity `json:"severity"`
+	Cooldown int      `json:"cooldown"`
+}
+
+// defaultTriggerPolicies: losses that change what we can do go straight to
+// the LLM; slow-moving context (phase, first contact) waits for the interval.
+var defaultTriggerPolicies = map[EventKind]TriggerPolicy{
+	EventCriticalBuildingLost: {Severity: SeverityCritical},
+	EventArmyDevastated:       {Severity: SeverityCritical, Cooldown: 250},
+	EventRushDetected:         {Severity: SeverityCritical},
+	EventIncomingAttack:       {Severity: SeverityCritical, Cooldown: 500},
+	EventEconomyCrisis:        {Severity: SeverityMedium, Cooldown: 250},
+	EventStrategyCountered:    {Severity: SeverityMedium, Cooldown: counterCooldownTicks},
+	EventEnemyBaseDiscovered:  {Severity: SeverityMedium},
+	EventSuperweaponReady:     {Severity: SeverityMedium, Cooldown: 500},
+	EventBaseSieged:           {Severity: SeverityMedium},
+	EventContained:            {Severity: SeverityMedium},
+	EventProductionStalled:    {Severity: SeverityMedium, Cooldown: 1000},
+	EventPhaseTransition:      {Severity: SeverityLow},
+	EventFirstContact:         {Severity: SeverityLow},
+}
+
+// TriggerPolicies maps event kinds to their trigger policy. Kinds missing
+// from the map fall back to the defaults, then to medium with no cooldown.
+type TriggerPolicies map[EventKind]TriggerPolicy
+
+// LoadTriggerPolicies reads per-kind trigger policies from a JSON file, e.g.
+// {"phase_transition": {"severity": "critical"}, "superweapon_ready": {"cooldown": 1000}}.
+func LoadTriggerPolicies(path string) (TriggerPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read event policies: %w", err)
+	}
+	var p TriggerPolicies
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal event policies: %w", err)
+	}
+	return p, nil
+}
+
+// policyFor returns the effective policy for an event kind.
+func (p TriggerPolicies) policyFor(kind EventKind) TriggerPolicy {
+	if tp, ok := p[kind]; ok {
+		return tp
+	}
+	if tp, ok := defaultTriggerPolicies[kind]; ok {
+		return tp
+	}
+	return TriggerPolicy{Severity: SeverityMedium}
+}
+
+// eventTriggers reports whether any of the events should start an
+// evaluation now, and records the trigger tick for each kind that did.
+// sinceEval is the ticks since the last evaluation, checked against the
+// global cooldown for medium-severity events.
+func (p TriggerPolicies) eventTriggers(events []Event, tick, sinceEval, cooldown int, lastTrigger map[EventKind]int) bool {
+	fire := false
+	for _, e := range events {
+		tp := p.policyFor(e.Kind)
+		if tp.Severity == SeverityLow {
+			continue
+		}
+		if last, ok := lastTrigger[e.Kind]; ok && tick-last < tp.Cooldown {
+			continue
+		}
+		if tp.Severity == SeverityMedium && sinceEval < cooldown {
+			continue
+		}
+		lastTrigger[e.Kind] = tick
+		fire = true
+	}
+	return fire
+}
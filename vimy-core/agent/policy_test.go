@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEventTriggers_Severity(t *testing.T) {
+	var p TriggerPolicies
+	last := make(map[EventKind]int)
+
+	// Low severity never triggers on its own.
+	if p.eventTriggers([]Event{{Kind: EventPhaseTransition}}, 1000, 1000, 100, last) {
+		t.Error("phase_transition should wait for the interval")
+	}
+
+	// Medium severity respects the global cooldown.
+	if p.eventTriggers([]Event{{Kind: EventEnemyBaseDiscovered}}, 1000, 50, 100, last) {
+		t.Error("medium event triggered inside the global cooldown")
+	}
+	if !p.eventTriggers([]Event{{Kind: EventEnemyBaseDiscovered}}, 1000, 150, 100, last) {
+		t.Error("medium event should trigger after the global cooldown")
+	}
+
+	// Critical severity ignores the global cooldown.
+	if !p.eventTriggers([]Event{{Kind: EventCriticalBuildingLost}}, 1000, 0, 100, last) {
+		t.Error("critical event should trigger immediately")
+	}
+}
+
+func TestEventTriggers_PerKindCooldown(t *testing.T) {
+	p := TriggerPolicies{EventPhaseTransition: {Severity: SeverityCritical, Cooldown: 300}}
+	last := make(map[EventKind]int)
+
+	if !p.eventTriggers([]Event{{Kind: EventPhaseTransition}}, 1000, 0, 100, last) {
+		t.Fatal("overridden phase_transition should trigger")
+	}
+	if p.eventTriggers([]Event{{Kind: EventPhaseTransition}}, 1200, 200, 100, last) {
+		t.Error("phase_transition re-triggered inside its own cooldown")
+	}
+	if !p.eventTriggers([]Event{{Kind: EventPhaseTransition}}, 1300, 300, 100, last) {
+		t.Error("phase_transition should trigger once its cooldown passed")
+	}
+}
+
+func TestTriggerPolicies_JSON(t *testing.T) {
+	var p TriggerPolicies
+	if err := json.Unmarshal([]byte(`{"first_contact": {"severity": "critical", "cooldown": 50}}`), &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := p.policyFor(EventFirstContact); got.Severity != SeverityCritical || got.Cooldown != 50 {
+		t.Errorf("first_contact policy = %+v", got)
+	}
+	if got := p.policyFor(EventRushDetected); got.Severity != SeverityCritical {
+		t.Errorf("rush_detected should keep its default, got %+v", got)
+	}
+	if err := json.Unmarshal([]byte(`{"first_contact": {"severity": "urgent"}}`), &p); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// Match report. The doctrine history already records every swap; the
+// report cuts the current game's swaps into a timeline of phases — which
+// doctrine played from when to when, what prompted it and how it differed
+// from the one before — so the strategist's decision arc can be reviewed
+// at a glance once the game is over.
+
+// gameTicksPerSecond converts ticks to game time at normal speed.
+const gameTicksPerSecond = 25
+
+// DoctrinePhase is a stretch of the game played under one doctrine.
+type DoctrinePhase struct {
+	StartTick int                 `json:"start_tick"`
+	EndTick   int                 `json:"end_tick"` // the next swap, or the last tick seen
+	Doctrine  string              `json:"doctrine"`
+	Rationale string              `json:"rationale"`
+	Source    DoctrineSource      `json:"source"`
+	Trigger   string              `json:"trigger"`
+	Changes   []rules.ParamChange `json:"changes,omitempty"` // against the previous phase's doctrine
+}
+
+// MatchReport summarises the current game for review afterwards.
+type MatchReport struct {
+	Faction   string          `json:"faction"`
+	Directive string          `json:"directive"`
+	LastTick  int             `json:"last_tick"`
+	Timeline  []DoctrinePhase `json:"timeline"`
+}
+
+// Report builds the match report for the current game so far.
+func (s *Strategist) Report() MatchReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := MatchReport{Faction: s.faction, Directive: s.directive}
+	if s.latest != nil {
+		r.LastTick = s.latest.Tick
+	}
+	r.Timeline = buildTimeline(s.history[min(s.gameStart, len(s.history)):], r.LastTick)
+	return r
+}
+
+// buildTimeline turns one game's doctrine swaps into phases, each ending
+// where the next begins and the last at lastTick.
+func buildTimeline(history []DoctrineRecord, lastTick int) []DoctrinePhase {
+	phases := make([]DoctrinePhase, len(history))
+	for i, rec := range history {
+		end := lastTick
+		if i+1 < len(history) {
+			end = history[i+1].Tick
+		}
+		p := DoctrinePhase{
+			StartTick: rec.Tick,
+			EndTick:   max(end, rec.Tick),
+			Doctrine:  rec.Doctrine.Name,
+			Rationale: rec.Doctrine.Rationale,
+			Source:    rec.Source,
+			Trigger:   phaseTrigger(rec, i == 0),
+		}
+		if i > 0 {
+			p.Changes = rules.DiffParams(history[i-1].Doctrine, rec.Doctrine)
+		}
+		phases[i] = p
+	}
+	return phases
+}
+
+// phaseTrigger says what prompted a swap: the events an evaluation reacted
+// to, or the reason another source gave.
+func phaseTrigger(rec DoctrineRecord, first bool) string {
+	switch {
+	case rec.Source != SourceStrategist && rec.Source != "":
+		return rec.Reason
+	case len(rec.Events) > 0:
+		parts := make([]string, len(rec.Events))
+		for i, e := range rec.Events {
+			parts[i] = string(e.Kind)
+			if e.Detail != "" {
+				parts[i] += " (" + e.Detail + ")"
+			}
+		}
+		return strings.Join(parts, "; ")
+	case first:
+		return "opening evaluation"
+	}
+	return "scheduled re-evaluation"
+}
+
+// WriteText renders the report as a plain-text doctrine timeline.
+func (r MatchReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Doctrine timeline: %s, directive %q, %s (tick %d)\n", cmp.Or(r.Faction, "unknown faction"), r.Directive, gameClock(r.LastTick), r.LastTick)
+	if len(r.Timeline) == 0 {
+		b.WriteString("  no doctrine swaps this game\n")
+	}
+	for i, p := range r.Timeline {
+		fmt.Fprintf(&b, "\n%d. %s-%s  %s [%s]\n", i+1, gameClock(p.StartTick), gameClock(p.EndTick), cmp.Or(p.Doctrine, "unnamed doctrine"), cmp.Or(string(p.Source), "unknown"))
+		fmt.Fprintf(&b, "   ticks %d-%d\n", p.StartTick, p.EndTick)
+		fmt.Fprintf(&b, "   trigger: %s\n", p.Trigger)
+		if p.Rationale != "" {
+			fmt.Fprintf(&b, "   rationale: %s\n", p.Rationale)
+		}
+		if len(p.Changes) > 0 {
+			parts := make([]string, len(p.Changes))
+			for j, c := range p.Changes {
+				parts[j] = c.Param + " " + formatParam(c.From) + "->" + formatParam(c.To)
+			}
+			fmt.Fprintf(&b, "   changes: %s\n", strings.Join(parts, ", "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// gameClock formats a tick as game time, m:ss.
+func gameClock(tick int) string {
+	secs := tick / gameTicksPerSecond
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+func formatParam(v float64) string {
+	return strconv.FormatFloat(v, 'g', 3, 64)
+}
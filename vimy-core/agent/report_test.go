@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func TestReportDoctrineTimeline(t *testing.T) {
+	engine, _ := rules.NewEngine(rules.DefaultRules())
+	s := NewStrategist(engine, "balanced", 500)
+	s.SetFaction("soviet")
+
+	opening := rules.DefaultDoctrine()
+	opening.Name = "Steady Build"
+	rush := opening
+	rush.Name, rush.Rationale, rush.Aggression = "Counter Rush", "Enemy infantry at the gates", 0.2
+	s.mu.Lock()
+	s.history = []DoctrineRecord{{Tick: 100, Doctrine: rules.DefaultDoctrine(), Source: SourceStrategist}}
+	s.mu.Unlock()
+
+	// A new game starts the timeline afresh.
+	s.NewGame()
+	s.mu.Lock()
+	s.history = append(s.history,
+		DoctrineRecord{Tick: 0, Doctrine: opening, Source: SourceStrategist},
+		DoctrineRecord{Tick: 900, Doctrine: rush, Source: SourceStrategist, Events: []Event{{Kind: EventRushDetected, Detail: "6 rifles near base"}}},
+	)
+	s.latest = &model.GameState{Tick: 3000}
+	s.mu.Unlock()
+	if _, err := s.TuneDoctrine(map[string]float64{"aggression": 0.8}, 0); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.latest = &model.GameState{Tick: 4500}
+	s.mu.Unlock()
+
+	r := s.Report()
+	if r.Faction != "soviet" || r.LastTick != 4500 || len(r.Timeline) != 3 {
+		t.Fatalf("report = %+v, want soviet's 3 phases to tick 4500", r)
+	}
+	first, second, third := r.Timeline[0], r.Timeline[1], r.Timeline[2]
+	if first.StartTick != 0 || first.EndTick != 900 || first.Trigger != "opening evaluation" || first.Changes != nil {
+		t.Errorf("first phase = %+v, want the opening evaluation to tick 900", first)
+	}
+	if second.EndTick != 3000 || second.Trigger != "rush_detected (6 rifles near base)" || second.Rationale != "Enemy infantry at the gates" {
+		t.Errorf("second phase = %+v, want the rush response to tick 3000", second)
+	}
+	if len(second.Changes) != 1 || second.Changes[0] != (rules.ParamChange{Param: "aggression", From: 0.5, To: 0.2}) {
+		t.Errorf("second phase changes = %+v, want aggression 0.5 -> 0.2", second.Changes)
+	}
+	if third.Source != SourceOperator || third.Trigger != "tuned aggression=0.8" || third.StartTick != 3000 || third.EndTick != 4500 {
+		t.Errorf("third phase = %+v, want the operator's tuning to tick 4500", third)
+	}
+
+	var text strings.Builder
+	if err := r.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"soviet", "3:00 (tick 4500)",
+		"1. 0:00-0:36  Steady Build [strategist]",
+		"2. 0:36-2:00  Counter Rush [strategist]",
+		"trigger: rush_detected (6 rifles near base)",
+		"changes: aggression 0.5->0.2",
+		"[operator]",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report lacks %q:\n%s", want, text.String())
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// DefaultResultsDriver is the database/sql driver name OpenResults expects
+// for SQLite: modernc.org/sqlite's, which the vimy binary links. A build
+// linking another driver (github.com/mattn/go-sqlite3 registers "sqlite3")
+// passes its name instead.
+const DefaultResultsDriver = "sqlite"
+
+const resultsSchema = `CREATE TABLE IF NOT EXISTS evaluations (
+	id               INTEGER PRIMARY KEY,
+	recorded_at      TEXT    NOT NULL,
+	faction          TEXT    NOT NULL,
+	directive        TEXT    NOT NULL,
+	tick             INTEGER NOT NULL,
+	situation_hash   TEXT    NOT NULL,
+	doctrine_name    TEXT,
+	doctrine         TEXT,
+	llm_ms           INTEGER NOT NULL,
+	error            TEXT,
+	outcome_ticks    INTEGER,
+	cash_delta       INTEGER,
+	units_delta      INTEGER,
+	buildings_delta  INTEGER,
+	units_lost       INTEGER,
+	enemies_killed   INTEGER
+)`
+
+// ResultsStore records every strategist evaluation — the situation it saw,
+// the doctrine the LLM chose and how long that took — in a SQL database for
+// offline analysis and prompt tuning. An evaluation's outcome columns are
+// filled in by the next one: what changed between the two is what the
+// doctrine achieved. The last evaluation of a game keeps NULL outcomes.
+type ResultsStore struct {
+	db   *sql.DB
+	mu   sync.Mutex
+	prev *recordedEvaluation // awaiting its outcome; nil before the first
+}
+
+type recordedEvaluation struct {
+	id       int64
+	baseline evalSnapshot
+}
+
+// Evaluation is one strategist evaluation as the results database stores
+// it. Doctrine is nil when the LLM call failed.
+type Evaluation struct {
+	Faction       string
+	Directive     string
+	Tick          int
+	SituationHash string
+	Doctrine      *rules.Doctrine
+	LLMLatency    time.Duration
+	Err           error
+}
+
+// evalSnapshot is the state an evaluation's outcome is measured against.
+type evalSnapshot struct {
+	Tick      int
+	Cash      int
+	Units     int
+	Buildings int
+	Lost      int // our units lost this game
+	Killed    int // enemy units and buildings destroyed this game
+}
+
+// takeEvalSnapshot reads gs and the combat ledger. Callers hold the engine
+// memory lock.
+func takeEvalSnapshot(gs model.GameState, memory map[string]any) evalSnapshot {
+	snap := evalSnapshot{
+		Tick:      gs.Tick,
+		Cash:      gs.Player.Cash,
+		Units:     len(gs.Units),
+		Buildings: len(gs.Buildings),
+	}
+	if l := rules.GetCombatLedger(memory); l != nil {
+		for _, n := range l.Lost {
+			snap.Lost += n
+		}
+		for _, n := range l.Killed {
+			snap.Killed += n
+		}
+	}
+	return snap
+}
+
+// situationHash fingerprints a situation so evaluations of identical
+// situations can be grouped offline without storing the whole prompt.
+func situationHash(situation any) string {
+	data, err := json.Marshal(situation)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// OpenResults opens the results database at dsn with driver and creates
+// its table if needed.
+func OpenResults(driver, dsn string) (*ResultsStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open results database: %w", err)
+	}
+	if _, err := db.Exec(resultsSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create results table: %w", err)
+	}
+	return &ResultsStore{db: db}, nil
+}
+
+// Close closes the database.
+func (r *ResultsStore) Close() error { return r.db.Close() }
+
+// Record stores e. When e applied a doctrine it also closes out the
+// previous doctrine's outcome at snap, and snap becomes the baseline for
+// e's own. A new game (snap's tick behind the previous baseline) leaves the
+// previous outcome NULL rather than mixing games. Failed calls change
+// nothing in the game, so they are stored without an outcome.
+func (r *ResultsStore) Record(e Evaluation, snap evalSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p := r.prev; e.Doctrine != nil && p != nil && snap.Tick >= p.baseline.Tick {
+		b := p.baseline
+		_, err := r.db.Exec(`UPDATE evaluations SET outcome_ticks = ?, cash_delta = ?, units_delta = ?,
+			buildings_delta = ?, units_lost = ?, enemies_killed = ? WHERE id = ?`,
+			snap.Tick-b.Tick, snap.Cash-b.Cash, snap.Units-b.Units,
+			snap.Buildings-b.Buildings, snap.Lost-b.Lost, snap.Killed-b.Killed, p.id)
+		if err != nil {
+			return fmt.Errorf("record evaluation outcome: %w", err)
+		}
+	}
+
+	var name, doctrine, errText sql.NullString
+	if e.Doctrine != nil {
+		data, err := json.Marshal(e.Doctrine)
+		if err != nil {
+			return fmt.Errorf("marshal doctrine: %w", err)
+		}
+		name = sql.NullString{String: e.Doctrine.Name, Valid: true}
+		doctrine = sql.NullString{String: string(data), Valid: true}
+	}
+	if e.Err != nil {
+		errText = sql.NullString{String: e.Err.Error(), Valid: true}
+	}
+	res, err := r.db.Exec(`INSERT INTO evaluations (recorded_at, faction, directive, tick, situation_hash,
+		doctrine_name, doctrine, llm_ms, error) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		time.Now().UTC().Format(time.RFC3339), e.Faction, e.Directive, e.Tick, e.SituationHash,
+		name, doctrine, e.LLMLatency.Milliseconds(), errText)
+	if err != nil {
+		return fmt.Errorf("record evaluation: %w", err)
+	}
+	if e.Doctrine != nil {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("record evaluation: %w", err)
+		}
+		r.prev = &recordedEvaluation{id: id, baseline: snap}
+	}
+	return nil
+}
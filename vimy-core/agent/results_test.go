@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+	_ "modernc.org/sqlite"
+)
+
+// recordingDriver is a database/sql driver that accepts every statement
+// and keeps it, so the store's SQL can be checked without SQLite.
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []recordedExec
+}
+
+type recordedExec struct {
+	query string
+	args  []driver.Value
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(q string) (driver.Stmt, error) { return recordingStmt{c.d, q}, nil }
+func (c recordingConn) Close() error                          { return nil }
+func (c recordingConn) Begin() (driver.Tx, error)             { return nil, errors.New("no transactions") }
+
+type recordingStmt struct {
+	d *recordingDriver
+	q string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no queries")
+}
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, recordedExec{s.q, args})
+	return recordingResult(len(s.d.execs)), nil
+}
+
+// recordingResult uses the statement's position as its insert ID.
+type recordingResult int64
+
+func (r recordingResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r recordingResult) RowsAffected() (int64, error) { return 1, nil }
+
+var resultsDriver = &recordingDriver{}
+
+func init() { sql.Register("vimy-results-test", resultsDriver) }
+
+func TestResultsStoreRecordsOutcomes(t *testing.T) {
+	r, err := OpenResults("vimy-results-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	resultsDriver.execs = nil
+
+	d := rules.Doctrine{Name: "rush"}
+	if err := r.Record(Evaluation{Tick: 100, Doctrine: &d}, evalSnapshot{Tick: 100, Cash: 1000, Units: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(Evaluation{Tick: 300, Err: errors.New("timeout")}, evalSnapshot{Tick: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(Evaluation{Tick: 600, Doctrine: &d}, evalSnapshot{Tick: 600, Cash: 400, Units: 9, Lost: 2, Killed: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	var kinds []string
+	for _, e := range resultsDriver.execs {
+		kinds = append(kinds, strings.Fields(e.query)[0])
+	}
+	if got := strings.Join(kinds, " "); got != "INSERT INSERT UPDATE INSERT" {
+		t.Fatalf("statements = %s, want the failed call to leave the outcome open", got)
+	}
+	want := []driver.Value{int64(500), int64(-600), int64(4), int64(0), int64(2), int64(7)}
+	got := resultsDriver.execs[2].args[:6]
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("outcome args = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestResultsStoreSkipsOutcomeAcrossGames(t *testing.T) {
+	r, err := OpenResults("vimy-results-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	resultsDriver.execs = nil
+
+	d := rules.Doctrine{Name: "turtle"}
+	r.Record(Evaluation{Tick: 5000, Doctrine: &d}, evalSnapshot{Tick: 5000})
+	r.Record(Evaluation{Tick: 10, Doctrine: &d}, evalSnapshot{Tick: 10})
+	for _, e := range resultsDriver.execs {
+		if strings.HasPrefix(e.query, "UPDATE") {
+			t.Error("outcome recorded across a new game")
+		}
+	}
+}
+
+func TestResultsStoreSQLite(t *testing.T) {
+	r, err := OpenResults(DefaultResultsDriver, "file:results?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	d := rules.Doctrine{Name: "rush", Aggression: 0.9}
+	if err := r.Record(Evaluation{Faction: "soviet", Directive: "aggressive", Tick: 100, SituationHash: "abc", Doctrine: &d, LLMLatency: 1500 * time.Millisecond}, evalSnapshot{Tick: 100, Cash: 1000, Units: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(Evaluation{Faction: "soviet", Tick: 250, Err: errors.New("timeout")}, evalSnapshot{Tick: 250}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(Evaluation{Faction: "soviet", Tick: 600, Doctrine: &d}, evalSnapshot{Tick: 600, Cash: 400, Units: 9, Lost: 2, Killed: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := r.db.Query(`SELECT tick, directive, doctrine_name, doctrine, llm_ms, error, outcome_ticks, cash_delta, units_lost, enemies_killed
+		FROM evaluations ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type row struct {
+		tick                        int
+		directive                   string
+		name, doctrine, errText     sql.NullString
+		llmMs                       int64
+		outcome, cash, lost, killed sql.NullInt64
+	}
+	var got []row
+	for rows.Next() {
+		var rw row
+		if err := rows.Scan(&rw.tick, &rw.directive, &rw.name, &rw.doctrine, &rw.llmMs, &rw.errText, &rw.outcome, &rw.cash, &rw.lost, &rw.killed); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rw)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("stored %d evaluations, want 3", len(got))
+	}
+	first, failed, last := got[0], got[1], got[2]
+	if first.tick != 100 || first.directive != "aggressive" || first.name.String != "rush" || first.llmMs != 1500 {
+		t.Errorf("first evaluation = %+v, want tick 100's rush doctrine from the aggressive directive", first)
+	}
+	var back rules.Doctrine
+	if err := json.Unmarshal([]byte(first.doctrine.String), &back); err != nil || back.Aggression != 0.9 {
+		t.Errorf("stored doctrine %q decodes to %+v (%v), want aggression 0.9", first.doctrine.String, back, err)
+	}
+	if first.outcome.Int64 != 500 || first.cash.Int64 != -600 || first.lost.Int64 != 2 || first.killed.Int64 != 7 {
+		t.Errorf("first outcome = %+v, want 500 ticks, -600 cash, 2 lost, 7 killed", first)
+	}
+	if failed.errText.String != "timeout" || failed.name.Valid || failed.outcome.Valid {
+		t.Errorf("failed evaluation = %+v, want its error and no doctrine or outcome", failed)
+	}
+	if last.outcome.Valid {
+		t.Errorf("latest evaluation has outcome %v, want it open", last.outcome.Int64)
+	}
+}
+
+func TestSituationHashStable(t *testing.T) {
+	a := situationHash(map[string]int{"cash": 100, "units": 3})
+	b := situationHash(map[string]int{"units": 3, "cash": 100})
+	c := situationHash(map[string]int{"cash": 101, "units": 3})
+	if a != b || a == c || len(a) != 16 {
+		t.Errorf("hashes %q %q %q: want equal situations equal, different ones different", a, b, c)
+	}
+}
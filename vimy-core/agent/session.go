@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SessionStore lets a reconnecting mod reattach to its in-flight game instead
+// of starting over. The mod sends a per-game session key in its hello; if the
+// same key returns within the reconnect window, engine memory (squads, intel,
+// built roles) is kept and the strategist carries on uninterrupted.
+type SessionStore struct {
+	mu       sync.Mutex
+	window   time.Duration
+	sessions map[string]*session
+}
+
+type session struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	expiry *time.Timer // non-nil while detached and awaiting reconnect
+}
+
+// NewSessionStore creates a store that holds detached sessions for window
+// before discarding them.
+func NewSessionStore(window time.Duration) *SessionStore {
+	return &SessionStore{window: window, sessions: make(map[string]*session)}
+}
+
+// Attach binds a connection to the session identified by key. It returns the
+// session's context (cancelled when the session expires or parent is done)
+// and whether an existing session was resumed. An empty key always starts a
+// fresh, unnamed session so older mods keep the one-game-per-connection model.
+func (s *SessionStore) Attach(parent context.Context, key string) (context.Context, bool) {
+	if key == "" {
+		slog.Info("session started without key, reconnect resume disabled")
+		return parent, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sess, ok := s.sessions[key]; ok && sess.ctx.Err() == nil {
+		if sess.expiry != nil {
+			sess.expiry.Stop()
+			sess.expiry = nil
+		}
+		slog.Info("session resumed", "session", key)
+		return sess.ctx, true
+	}
+
+	// A new game supersedes any sessions still waiting on a reconnect.
+	for k, sess := range s.sessions {
+		if sess.expiry != nil {
+			sess.expiry.Stop()
+			sess.cancel()
+			delete(s.sessions, k)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	s.sessions[key] = &session{ctx: ctx, cancel: cancel}
+	slog.Info("session started", "session", key)
+	return ctx, false
+}
+
+// Detach marks the session as disconnected. If no connection reattaches
+// within the reconnect window, the session is cancelled and forgotten.
+func (s *SessionStore) Detach(key string) {
+	if key == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[key]
+	if !ok {
+		return
+	}
+	sess.expiry = time.AfterFunc(s.window, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.sessions[key] == sess && sess.expiry != nil {
+			sess.cancel()
+			delete(s.sessions, key)
+			slog.Info("session expired", "session", key)
+		}
+	})
+	slog.Info("session detached, awaiting reconnect", "session", key, "window", s.window)
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionStoreResumeWithinWindow(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	ctx, resumed := store.Attach(context.Background(), "game-1")
+	if resumed {
+		t.Fatal("first attach should start a new session")
+	}
+
+	store.Detach("game-1")
+	ctx2, resumed := store.Attach(context.Background(), "game-1")
+	if !resumed {
+		t.Fatal("reattach within window should resume")
+	}
+	if ctx2 != ctx {
+		t.Error("resumed session should reuse the original context")
+	}
+}
+
+func TestSessionStoreExpires(t *testing.T) {
+	store := NewSessionStore(10 * time.Millisecond)
+	ctx, _ := store.Attach(context.Background(), "game-1")
+	store.Detach("game-1")
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("detached session was not cancelled after the window")
+	}
+	if _, resumed := store.Attach(context.Background(), "game-1"); resumed {
+		t.Error("expired session should not resume")
+	}
+}
@@ -1,25 +1,43 @@
 package agent
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	baml_client "github.com/nstehr/vimy/vimy-core/baml_client"
 	"github.com/nstehr/vimy/vimy-core/baml_client/types"
+	"github.com/nstehr/vimy/vimy-core/ipc"
 	"github.com/nstehr/vimy/vimy-core/model"
 	"github.com/nstehr/vimy/vimy-core/rules"
 )
 
-// DoctrineRecord is a timestamped doctrine output from the LLM.
+// DoctrineRecord is a timestamped doctrine swap: the doctrine put in play
+// at Tick and what put it there.
 type DoctrineRecord struct {
 	Tick          int
 	Doctrine      rules.Doctrine
-	Events        []Event
+	Source        DoctrineSource
+	Reason        string  // why a non-strategist source swapped, e.g. the budget cap hit
+	Events        []Event // the events that prompted a strategist evaluation
 	HasEnemyIntel bool
 }
 
+// DoctrineSource is what swapped a doctrine in.
+type DoctrineSource string
+
+const (
+	SourceStrategist DoctrineSource = "strategist" // an LLM evaluation
+	SourceFallback   DoctrineSource = "fallback"   // the defaults, once the LLM budget ran out
+	SourceCheckpoint DoctrineSource = "checkpoint" // restored after a restart
+	SourceOperator   DoctrineSource = "operator"   // tuned from the dashboard
+)
+
 // TypeCount is a type name with a count, used for display purposes.
 type TypeCount struct {
 	Type  string
@@ -41,23 +59,31 @@ type BattlefieldStatus struct {
 // to generate a doctrine and swap the rule engine's rule set.
 type Strategist struct {
 	mu        sync.Mutex
+	applyMu   sync.Mutex // held across adopt, so doctrine swaps land one at a time (see adopt)
 	latest    *model.GameState
 	engine    *rules.Engine
 	faction   string
 	directive string // initial doctrine seed from --doctrine flag
+	opening   string // opening book stamped onto every doctrine; "" for none
 	interval  int    // re-evaluate every N ticks
 	lastTick  int    // tick of last evaluation
 	ready     chan struct{}
-	prevSnap  *stateSnapshot // previous state snapshot for event diff
-	cooldown  int            // minimum ticks between event-driven evaluations
-	pending   []Event        // events accumulated since last evaluation
-	history   []DoctrineRecord // append-only log of all doctrine outputs
-
-	// Cumulative loss tracking — independent of event windowing.
-	// prevFreshIDs holds per-domain unit IDs from the PREVIOUS tick (never merged).
-	// totalLosses accumulates deaths across the entire game.
-	prevFreshIDs map[string]map[int]bool
-	totalLosses  map[string]int
+	prevSnap  *stateSnapshot    // previous state snapshot for event diff
+	cooldown  int               // minimum ticks between event-driven evaluations
+	pending   []Event           // events accumulated since last evaluation
+	policies  TriggerPolicies   // per-kind severity and cooldown overrides
+	triggered map[EventKind]int // tick each event kind last triggered an evaluation
+	history   []DoctrineRecord  // append-only log of all doctrine swaps
+	gameStart int               // index in history of this game's first swap
+	link      *ipc.Heartbeat    // link health of the current game connection
+	results   *ResultsStore     // evaluation log for offline analysis; nil when disabled
+	budget    BudgetStatus      // LLM spend this game against its caps
+	applied   bool              // a doctrine has been applied this game
+
+	// limits are the operator's pins and bounds on doctrine parameters.
+	limits rules.DoctrineConstraints
+	// tweaks are live operator settings held against the LLM (see TuneDoctrine).
+	tweaks map[string]DoctrineTweak
 }
 
 // NewStrategist creates a strategist. If directive is empty, defaults to "balanced".
@@ -77,6 +103,23 @@ func NewStrategist(engine *rules.Engine, directive string, interval int) *Strate
 	}
 }
 
+// SetOpening selects the scripted opening book applied to every doctrine the
+// strategist compiles. The LLM doesn't choose openings; the book only runs
+// for its first few minutes, so later doctrines carrying it are harmless.
+func (s *Strategist) SetOpening(name string) {
+	s.mu.Lock()
+	s.opening = name
+	s.mu.Unlock()
+}
+
+// SetTriggerPolicies overrides how individual event kinds trigger
+// re-evaluation. Kinds not in p keep their default policy.
+func (s *Strategist) SetTriggerPolicies(p TriggerPolicies) {
+	s.mu.Lock()
+	s.policies = p
+	s.mu.Unlock()
+}
+
 // SetFaction sets the faction string (called from HandleHello).
 func (s *Strategist) SetFaction(f string) {
 	s.mu.Lock()
@@ -127,13 +170,110 @@ func (s *Strategist) GetRules() []rules.RuleSummary {
 	return s.engine.Rules()
 }
 
+// ExportRules returns the engine's active rules as data (see
+// rules.Engine.ExportRules).
+func (s *Strategist) ExportRules() []rules.RuleExport {
+	return s.engine.ExportRules()
+}
+
+// GetEvalErrors returns the engine's per-rule condition error records.
+func (s *Strategist) GetEvalErrors() []rules.RuleEvalError {
+	return s.engine.EvalErrors()
+}
+
+// GetQueueIdleStats returns the engine's production queue idle metrics.
+func (s *Strategist) GetQueueIdleStats() []rules.QueueIdleStat {
+	return s.engine.QueueIdleStats()
+}
+
+// GetCommandAudit returns the commands the engine has sent this game and
+// the rules that sent them.
+func (s *Strategist) GetCommandAudit() rules.CommandAudit {
+	return s.engine.CommandAudit()
+}
+
+// SetConstraints sets the operator's pins and bounds on doctrine
+// parameters; every doctrine is clamped to them before it is compiled.
+func (s *Strategist) SetConstraints(c rules.DoctrineConstraints) {
+	s.mu.Lock()
+	s.limits = c
+	s.mu.Unlock()
+}
+
+// constrain clamps doctrine to the operator's constraints.
+func (s *Strategist) constrain(doctrine *rules.Doctrine) {
+	s.mu.Lock()
+	limits := s.limits
+	s.mu.Unlock()
+	if changed := limits.Apply(doctrine); len(changed) > 0 {
+		slog.Info("doctrine clamped to operator constraints", "doctrine", doctrine.Name, "params", changed)
+	}
+}
+
+// SetCooldown sets the minimum ticks between evaluations triggered by
+// medium-severity events.
+func (s *Strategist) SetCooldown(ticks int) {
+	s.mu.Lock()
+	s.cooldown = ticks
+	s.mu.Unlock()
+}
+
+// SetBudget caps LLM use per game; see Budget.
+func (s *Strategist) SetBudget(b Budget) {
+	s.mu.Lock()
+	s.budget.Budget = b
+	s.mu.Unlock()
+}
+
+// GetBudget returns this game's LLM spend against its caps.
+func (s *Strategist) GetBudget() BudgetStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.budget
+}
+
+// NewGame starts a fresh per-game LLM budget and match report timeline.
+// Resumed sessions keep theirs.
+func (s *Strategist) NewGame() {
+	s.mu.Lock()
+	s.budget = BudgetStatus{Budget: s.budget.Budget}
+	s.applied = false
+	s.gameStart = len(s.history)
+	s.tweaks = nil
+	s.mu.Unlock()
+}
+
+// SetResults logs every evaluation to r from now on.
+func (s *Strategist) SetResults(r *ResultsStore) {
+	s.mu.Lock()
+	s.results = r
+	s.mu.Unlock()
+}
+
+// SetLink records the current game connection's heartbeat for the
+// dashboard.
+func (s *Strategist) SetLink(hb *ipc.Heartbeat) {
+	s.mu.Lock()
+	s.link = hb
+	s.mu.Unlock()
+}
+
+// GetLinkStats returns the current connection's link health, or nil before
+// a game has connected.
+func (s *Strategist) GetLinkStats() *ipc.HeartbeatStats {
+	s.mu.Lock()
+	hb := s.link
+	s.mu.Unlock()
+	if hb == nil {
+		return nil
+	}
+	stats := hb.Stats()
+	return &stats
+}
+
 // GetBattlefieldStatus returns current losses and enemy composition.
 func (s *Strategist) GetBattlefieldStatus() *BattlefieldStatus {
 	s.mu.Lock()
-	losses := make(map[string]int, len(s.totalLosses))
-	for k, v := range s.totalLosses {
-		losses[k] = v
-	}
 	gs := s.latest
 	s.mu.Unlock()
 
@@ -141,11 +281,7 @@ func (s *Strategist) GetBattlefieldStatus() *BattlefieldStatus {
 		return nil
 	}
 
-	status := &BattlefieldStatus{
-		InfantryLost: losses["infantry"],
-		VehiclesLost: losses["vehicle"],
-		AircraftLost: losses["aircraft"],
-	}
+	status := &BattlefieldStatus{}
 
 	// Current enemy composition from game state.
 	enemyUnitCounts := make(map[string]int)
@@ -164,8 +300,13 @@ func (s *Strategist) GetBattlefieldStatus() *BattlefieldStatus {
 		status.EnemyBuildings = append(status.EnemyBuildings, TypeCount{Type: t, Count: c})
 	}
 
-	// Historical sightings from engine memory.
+	// Losses and historical sightings from engine memory.
 	s.engine.LockMemory()
+	if l := rules.GetCombatLedger(s.engine.Memory); l != nil {
+		status.InfantryLost = l.LostByDomain["infantry"]
+		status.VehiclesLost = l.LostByDomain["vehicle"]
+		status.AircraftLost = l.LostByDomain["aircraft"]
+	}
 	for t, c := range rules.GetEnemyUnitsSeen(s.engine.Memory) {
 		status.EnemyUnitsSeen = append(status.EnemyUnitsSeen, TypeCount{Type: t, Count: c})
 	}
@@ -184,32 +325,6 @@ func (s *Strategist) UpdateState(gs model.GameState) {
 	first := s.latest == nil
 	s.latest = &gs
 
-	// --- Cumulative loss tracking (independent of event windowing) ---
-	curFresh := map[string]map[int]bool{
-		"infantry": make(map[int]bool),
-		"vehicle":  make(map[int]bool),
-		"aircraft": make(map[int]bool),
-	}
-	for _, u := range gs.Units {
-		d := unitDomain(u)
-		if d != "" {
-			curFresh[d][u.ID] = true
-		}
-	}
-	if s.prevFreshIDs != nil {
-		if s.totalLosses == nil {
-			s.totalLosses = make(map[string]int)
-		}
-		for domain, prevIDs := range s.prevFreshIDs {
-			for id := range prevIDs {
-				if !curFresh[domain][id] {
-					s.totalLosses[domain]++
-				}
-			}
-		}
-	}
-	s.prevFreshIDs = curFresh
-
 	// Detect events against the previous snapshot.
 	// prevSnap's domain ID sets are accumulated (high-water mark) so that
 	// losses add up across multiple state updates instead of resetting each tick.
@@ -250,8 +365,13 @@ func (s *Strategist) UpdateState(gs model.GameState) {
 	s.prevSnap = &snap
 	s.pending = append(s.pending, events...)
 
+	// Critical events trigger at once, medium ones after the global cooldown,
+	// and low ones only ride along with the next evaluation.
 	shouldSignal := first || (gs.Tick-s.lastTick >= s.interval)
-	if !shouldSignal && len(events) > 0 && (gs.Tick-s.lastTick >= s.cooldown) {
+	if s.triggered == nil {
+		s.triggered = make(map[EventKind]int)
+	}
+	if s.policies.eventTriggers(events, gs.Tick, gs.Tick-s.lastTick, s.cooldown, s.triggered) {
 		shouldSignal = true
 	}
 	s.mu.Unlock()
@@ -284,10 +404,6 @@ func (s *Strategist) evaluate(ctx context.Context) {
 	faction := s.faction
 	events := s.pending
 	s.pending = nil
-	losses := make(map[string]int, len(s.totalLosses))
-	for k, v := range s.totalLosses {
-		losses[k] = v
-	}
 	s.mu.Unlock()
 
 	if gs == nil {
@@ -295,36 +411,66 @@ func (s *Strategist) evaluate(ctx context.Context) {
 	}
 
 	for _, e := range events {
-		slog.Info("event detected", "kind", e.Kind, "tick", e.Tick, "detail", e.Detail)
+		slog.Info("event detected", "kind", e.Kind, "severity", s.policies.policyFor(e.Kind).Severity,
+			"tick", e.Tick, "detail", e.Detail)
+	}
+	s.mu.Lock()
+	exhausted := s.budget.exhaustedBy()
+	s.mu.Unlock()
+	if exhausted != "" {
+		s.fallBack(exhausted, gs.Tick)
+		return
 	}
 	slog.Debug("strategist evaluating", "tick", gs.Tick, "directive", s.directive, "events", len(events))
 
 	s.engine.LockMemory()
 	swFires := snapshotSuperweaponFires(s.engine.Memory)
-	situation := buildSituation(*gs, s.engine.Memory, events, swFires, losses)
-	enemyBases, _ := s.engine.Memory["enemyBases"].(map[string]rules.EnemyBaseIntel)
+	situation := buildSituation(*gs, s.engine.Memory, events, swFires)
+	enemyBases := rules.GetEnemyBases(s.engine.Memory)
 	hasEnemyIntel := len(enemyBases) > 0
+	evalSnap := takeEvalSnapshot(*gs, s.engine.Memory)
 	s.engine.UnlockMemory()
 
-	bamlDoctrine, err := baml_client.GenerateDoctrine(ctx, s.directive, situation, faction)
+	eval := Evaluation{Faction: faction, Directive: s.directive, Tick: gs.Tick, SituationHash: situationHash(situation)}
+	collector := tokenCollector()
+	var callOpts []baml_client.CallOptionFunc
+	if collector != nil {
+		callOpts = append(callOpts, baml_client.WithCollector(collector))
+	}
+	start := time.Now()
+	bamlDoctrine, err := baml_client.GenerateDoctrine(ctx, s.directive, situation, faction, callOpts...)
+	eval.LLMLatency = time.Since(start)
+	s.mu.Lock()
+	s.budget.Evaluations++
+	s.budget.Tokens += collectedTokens(collector)
+	s.mu.Unlock()
 	if err != nil {
 		slog.Error("strategist LLM call failed", "error", err)
+		eval.Err = err
+		s.recordResult(eval, evalSnap)
 		return
 	}
 
 	doctrine := fromBAML(bamlDoctrine)
 	doctrine.Validate()
-
 	s.mu.Lock()
-	s.history = append(s.history, DoctrineRecord{
+	doctrine.Opening = s.opening
+	s.mu.Unlock()
+
+	doctrine, err = s.adoptEvaluation(doctrine, DoctrineRecord{
 		Tick:          gs.Tick,
-		Doctrine:      doctrine,
+		Source:        SourceStrategist,
 		Events:        events,
 		HasEnemyIntel: hasEnemyIntel,
 	})
-	s.mu.Unlock()
+	if err != nil {
+		slog.Error("strategist rule swap failed", "error", err)
+		eval.Err = err
+		s.recordResult(eval, evalSnap)
+		return
+	}
 
-	slog.Info("doctrine generated",
+	slog.Info("doctrine applied",
 		"name", doctrine.Name,
 		"rationale", doctrine.Rationale,
 		"economy", doctrine.EconomyPriority,
@@ -346,7 +492,93 @@ func (s *Strategist) evaluate(ctx context.Context) {
 		"prefNaval", doctrine.PreferredNaval,
 		"transportAssault", doctrine.TransportAssault,
 	)
+	eval.Doctrine = &doctrine
+	s.recordResult(eval, evalSnap)
+}
 
+// adoptEvaluation applies the doctrine an evaluation generated, with the
+// operator tweaks holding when it lands rather than when the LLM was asked.
+func (s *Strategist) adoptEvaluation(doctrine rules.Doctrine, rec DoctrineRecord) (rules.Doctrine, error) {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	doctrine, err := s.adopt(doctrine, rec, true)
+	if err != nil {
+		return doctrine, err
+	}
+	s.mu.Lock()
+	s.lastTick = rec.Tick
+	s.mu.Unlock()
+	return doctrine, nil
+}
+
+// fallBack stops LLM use once the budget is spent. The first time, it
+// applies rules.DefaultDoctrine if this game has no doctrine yet; a game
+// that has one keeps playing it.
+func (s *Strategist) fallBack(reason string, tick int) {
+	s.mu.Lock()
+	first := !s.budget.Exhausted
+	s.budget.Exhausted = true
+	applied := s.applied
+	opening := s.opening
+	s.mu.Unlock()
+	if !first {
+		return
+	}
+	slog.Warn("strategist LLM budget exhausted, falling back to heuristic play", "reason", reason, "keep_doctrine", applied)
+	if applied {
+		return
+	}
+
+	doctrine := rules.DefaultDoctrine()
+	doctrine.Opening = opening
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	if _, err := s.adopt(doctrine, DoctrineRecord{Tick: tick, Source: SourceFallback, Reason: "LLM budget exhausted: " + reason}, true); err != nil {
+		slog.Error("fallback doctrine swap failed", "error", err)
+		return
+	}
+	s.mu.Lock()
+	s.lastTick = tick
+	s.mu.Unlock()
+}
+
+// adopt constrains a doctrine, installs it and records it in the history;
+// with tweak, the operator tweaks holding at rec.Tick are set on it first.
+// Callers hold applyMu for the whole step, so swaps can't interleave: the
+// engine never mixes two doctrines' preferences, params and rules, the
+// history lists swaps in the order they landed, and a doctrine generated
+// before an operator tweak picks the tweak up when it is applied after it.
+func (s *Strategist) adopt(doctrine rules.Doctrine, rec DoctrineRecord, tweak bool) (rules.Doctrine, error) {
+	if tweak {
+		s.applyTweaks(&doctrine, rec.Tick)
+	}
+	s.constrain(&doctrine)
+	if err := s.applyDoctrine(doctrine); err != nil {
+		return doctrine, err
+	}
+	rec.Doctrine = doctrine
+	s.mu.Lock()
+	s.history = append(s.history, rec)
+	s.mu.Unlock()
+	return doctrine, nil
+}
+
+// recordResult logs e to the results database, if one is set.
+func (s *Strategist) recordResult(e Evaluation, snap evalSnapshot) {
+	s.mu.Lock()
+	r := s.results
+	s.mu.Unlock()
+	if r == nil {
+		return
+	}
+	if err := r.Record(e, snap); err != nil {
+		slog.Error("recording evaluation failed", "error", err)
+	}
+}
+
+// applyDoctrine installs a doctrine on the engine: its unit preferences,
+// its weights for DoctrineParam, and the rules it compiles to.
+func (s *Strategist) applyDoctrine(doctrine rules.Doctrine) error {
 	s.engine.SetPreferences(rules.UnitPreferences{
 		Infantry: doctrine.PreferredInfantry,
 		Vehicle:  doctrine.PreferredVehicle,
@@ -354,15 +586,55 @@ func (s *Strategist) evaluate(ctx context.Context) {
 		Naval:    doctrine.PreferredNaval,
 	})
 
-	compiled := rules.CompileDoctrine(doctrine)
+	s.engine.SetDoctrine(doctrine)
+
+	compiled := rules.CompileDoctrineForMap(doctrine, s.engine.Mod(), s.engine.TerrainGrid())
+	previous := s.engine.BaseRules()
 	if err := s.engine.Swap(compiled); err != nil {
-		slog.Error("strategist rule swap failed", "error", err)
-		return
+		return err
 	}
+	logRuleDiff(doctrine.Name, rules.Diff(previous, compiled))
+	s.mu.Lock()
+	s.applied = true
+	s.mu.Unlock()
+	return nil
+}
 
+// RestoreDoctrine reinstates the doctrine a checkpoint was taken under, so
+// a restarted vimy-core resumes the match playing the same rules rather
+// than the defaults until the LLM next answers.
+func (s *Strategist) RestoreDoctrine(doctrine rules.Doctrine, tick int) error {
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	if _, err := s.adopt(doctrine, DoctrineRecord{Tick: tick, Source: SourceCheckpoint, Reason: "resumed from checkpoint"}, false); err != nil {
+		return err
+	}
 	s.mu.Lock()
-	s.lastTick = gs.Tick
+	s.lastTick = tick
 	s.mu.Unlock()
+	return nil
+}
+
+// logRuleDiff reports how a doctrine swap changed the active rules, one line
+// per added, removed or altered rule, so operators can see what the LLM's
+// change actually did.
+func logRuleDiff(doctrine string, d rules.RuleDiff) {
+	if d.Empty() {
+		slog.Info("doctrine swap left rules unchanged", "doctrine", doctrine)
+		return
+	}
+	slog.Info("doctrine swap changed rules", "doctrine", doctrine,
+		"added", len(d.Added), "removed", len(d.Removed), "changed", len(d.Changed))
+	for _, name := range d.Added {
+		slog.Info("rule added", "rule", name)
+	}
+	for _, name := range d.Removed {
+		slog.Info("rule removed", "rule", name)
+	}
+	for _, c := range d.Changed {
+		slog.Info("rule changed", "rule", c.Name, "oldPriority", c.OldPriority, "newPriority", c.NewPriority,
+			"category", c.CategoryChanged, "exclusive", c.ExclusiveChanged, "cooldown", c.CooldownChanged, "condition", c.ConditionChanged)
+	}
 }
 
 // fromBAML converts the BAML-generated Doctrine type to our rules.Doctrine.
@@ -387,6 +659,18 @@ func fromBAML(d types.Doctrine) rules.Doctrine {
 		SuperweaponPriority:       d.Superweapon_priority,
 		CapturePriority:           d.Capture_priority,
 		TransportAssault:          d.Transport_assault,
+		// The LLM doesn't set forwardness directly: an offensive posture
+		// builds production forward, a defensive one keeps it back.
+		Forwardness:               d.Aggression - d.Ground_defense_priority,
+		// Nor the defense margin: a defensive posture holds a wider
+		// perimeter around the base.
+		DefenseMargin:             rules.MinDefenseMargin + (rules.MaxDefenseMargin-rules.MinDefenseMargin)*d.Ground_defense_priority,
+		// Nor combined arms: an offensive posture with an air arm times
+		// its strikes to the ground assault.
+		CombinedArms:              min(d.Air_weight, d.Aggression),
+		GroundAggression:          d.Ground_aggression,
+		AirAggression:             d.Air_aggression,
+		NavalAggression:           d.Naval_aggression,
 		PreferredInfantry:         d.Preferred_infantry,
 		PreferredVehicle:          d.Preferred_vehicle,
 		PreferredAircraft:         d.Preferred_aircraft,
@@ -425,10 +709,26 @@ func snapshotSuperweaponFires(memory map[string]any) []types.SuperweaponFire {
 	return fires
 }
 
+// typeCounts converts a count map to TypeCounts, largest first.
+func typeCounts(m map[string]int) []types.TypeCount {
+	out := make([]types.TypeCount, 0, len(m))
+	for t, c := range m {
+		out = append(out, types.TypeCount{Type: t, Count: int64(c)})
+	}
+	slices.SortFunc(out, func(a, b types.TypeCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return strings.Compare(a.Type, b.Type)
+	})
+	return out
+}
+
 // buildSituation constructs a structured GameSituation from the current
-// game state, memory, events, superweapon fire data, and cumulative losses.
+// game state, memory (including the combat ledger), events and superweapon
+// fire data.
 // Pure function (aside from reading memory) — no side effects.
-func buildSituation(gs model.GameState, memory map[string]any, events []Event, swFires []types.SuperweaponFire, totalLosses map[string]int) types.GameSituation {
+func buildSituation(gs model.GameState, memory map[string]any, events []Event, swFires []types.SuperweaponFire) types.GameSituation {
 	sit := types.GameSituation{
 		Tick:              int64(gs.Tick),
 		Phase:             gamePhase(gs),
@@ -532,15 +832,13 @@ func buildSituation(gs model.GameState, memory map[string]any, events []Event, s
 	}
 
 	// Known enemy bases
-	if bases, ok := memory["enemyBases"].(map[string]rules.EnemyBaseIntel); ok {
-		for _, base := range bases {
-			sit.Known_enemy_bases = append(sit.Known_enemy_bases, types.EnemyBase{
-				Owner:          base.Owner,
-				X:              int64(base.X),
-				Y:              int64(base.Y),
-				Last_seen_tick: int64(base.Tick),
-			})
-		}
+	for _, base := range rules.GetEnemyBases(memory) {
+		sit.Known_enemy_bases = append(sit.Known_enemy_bases, types.EnemyBase{
+			Owner:          base.Owner,
+			X:              int64(base.X),
+			Y:              int64(base.Y),
+			Last_seen_tick: int64(base.Tick),
+		})
 	}
 
 	// Recent events
@@ -552,12 +850,28 @@ func buildSituation(gs model.GameState, memory map[string]any, events []Event, s
 		})
 	}
 
-	// Cumulative combat stats
-	if len(totalLosses) > 0 {
+	// Scouting coverage has no dedicated field in the situation schema, so
+	// it rides along as a synthetic event the strategist can weigh when
+	// deciding how much to invest in recon.
+	if pct, age, ok := rules.MapCoverage(memory, gs.Tick); ok {
+		sit.Recent_events = append(sit.Recent_events, types.GameEvent{
+			Kind:   "map_coverage",
+			Tick:   int64(gs.Tick),
+			Detail: fmt.Sprintf("%.0f%% of reachable map seen recently; stalest zone last seen %d ticks ago", pct, age),
+		})
+	}
+
+	// Cumulative combat stats and the recent attrition trend
+	if l := rules.GetCombatLedger(memory); l != nil {
 		sit.Combat_stats = &types.CombatStats{
-			Infantry_lost: int64(totalLosses["infantry"]),
-			Vehicles_lost: int64(totalLosses["vehicle"]),
-			Aircraft_lost: int64(totalLosses["aircraft"]),
+			Infantry_lost:  int64(l.LostByDomain["infantry"]),
+			Vehicles_lost:  int64(l.LostByDomain["vehicle"]),
+			Aircraft_lost:  int64(l.LostByDomain["aircraft"]),
+			Naval_lost:     int64(l.LostByDomain["naval"]),
+			Losses_by_type: typeCounts(l.Lost),
+			Enemy_kills:    typeCounts(l.Killed),
+			Recent_losses:  typeCounts(l.RecentByDomain(gs.Tick, false)),
+			Recent_kills:   typeCounts(l.RecentByDomain(gs.Tick, true)),
 		}
 	}
 
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// Live doctrine tuning. An operator can nudge individual doctrine weights
+// mid-game; the current doctrine is recompiled with them and swapped in at
+// once. Each tweak then holds for a while: every doctrine the LLM produces
+// before it expires has the tweaked parameters set back to the operator's
+// values, so the next scheduled evaluation doesn't quietly undo it.
+// Operator constraints still have the last word.
+
+// DefaultTweakHoldTicks is how long a tweak holds when the caller doesn't
+// say: two minutes, six evaluations at the default 500-tick interval.
+const DefaultTweakHoldTicks = 3000
+
+// DoctrineTweak is an operator's live setting of one doctrine parameter,
+// held against the LLM through UntilTick.
+type DoctrineTweak struct {
+	Param     string  `json:"param"`
+	Value     float64 `json:"value"`
+	UntilTick int     `json:"until_tick"`
+}
+
+// TuneDoctrine sets the given doctrine parameters (by their DoctrineParam
+// names) on the current doctrine, applies it, and holds them against the
+// LLM for holdTicks (DefaultTweakHoldTicks if not positive). It returns
+// the doctrine now in play.
+func (s *Strategist) TuneDoctrine(params map[string]float64, holdTicks int) (rules.Doctrine, error) {
+	if len(params) == 0 {
+		return rules.Doctrine{}, errors.New("no doctrine parameters to tune")
+	}
+	if holdTicks <= 0 {
+		holdTicks = DefaultTweakHoldTicks
+	}
+
+	// Holding applyMu from reading the current doctrine to recording the
+	// tuned one means no evaluation can swap in between: one applied
+	// afterwards sees the tweaks and carries them.
+	s.applyMu.Lock()
+	defer s.applyMu.Unlock()
+	names := slices.Sorted(maps.Keys(params))
+	s.mu.Lock()
+	tick := 0
+	if s.latest != nil {
+		tick = s.latest.Tick
+	}
+	doctrine := rules.DefaultDoctrine()
+	doctrine.Opening = s.opening
+	if n := len(s.history); n > 0 {
+		doctrine = s.history[n-1].Doctrine
+	}
+	for _, name := range names {
+		if err := doctrine.SetParam(name, params[name]); err != nil {
+			s.mu.Unlock()
+			return rules.Doctrine{}, err
+		}
+	}
+	if s.tweaks == nil {
+		s.tweaks = make(map[string]DoctrineTweak)
+	}
+	for _, name := range names {
+		s.tweaks[name] = DoctrineTweak{Param: name, Value: params[name], UntilTick: tick + holdTicks}
+	}
+	s.mu.Unlock()
+
+	doctrine, err := s.adopt(doctrine, DoctrineRecord{Tick: tick, Source: SourceOperator, Reason: tweakReason(names, params)}, false)
+	if err != nil {
+		return rules.Doctrine{}, err
+	}
+	slog.Info("doctrine tuned by operator", "doctrine", doctrine.Name, "params", params, "until", tick+holdTicks)
+	return doctrine, nil
+}
+
+// GetTweaks returns the operator tweaks still holding, by parameter name.
+func (s *Strategist) GetTweaks() []DoctrineTweak {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]DoctrineTweak, 0, len(s.tweaks))
+	for _, name := range slices.Sorted(maps.Keys(s.tweaks)) {
+		out = append(out, s.tweaks[name])
+	}
+	return out
+}
+
+// applyTweaks sets the operator tweaks still holding at tick onto a fresh
+// doctrine, dropping the ones that have expired.
+func (s *Strategist) applyTweaks(doctrine *rules.Doctrine, tick int) {
+	s.mu.Lock()
+	var active []DoctrineTweak
+	for name, tw := range s.tweaks {
+		if tick > tw.UntilTick {
+			delete(s.tweaks, name)
+			slog.Info("operator doctrine tweak expired", "param", name)
+			continue
+		}
+		active = append(active, tw)
+	}
+	s.mu.Unlock()
+
+	for _, tw := range active {
+		if err := doctrine.SetParam(tw.Param, tw.Value); err != nil {
+			slog.Error("reapplying doctrine tweak failed", "param", tw.Param, "error", err)
+		}
+	}
+	if len(active) > 0 {
+		slog.Info("operator tweaks held over doctrine", "doctrine", doctrine.Name, "count", len(active))
+	}
+}
+
+// tweakReason describes an operator tuning for the doctrine timeline, e.g.
+// "tuned aggression=0.8, air_weight=0.2".
+func tweakReason(names []string, params map[string]float64) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.FormatFloat(params[name], 'g', -1, 64)
+	}
+	return "tuned " + strings.Join(parts, ", ")
+}
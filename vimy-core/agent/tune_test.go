@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func TestTuneDoctrineHoldsAgainstLLM(t *testing.T) {
+	engine, _ := rules.NewEngine(rules.DefaultRules())
+	s := NewStrategist(engine, "balanced", 500)
+	s.latest = &model.GameState{Tick: 1000}
+
+	d, err := s.TuneDoctrine(map[string]float64{"aggression": 0.9, "naval_weight": 2}, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Aggression != 0.9 || d.NavalWeight != 1 {
+		t.Errorf("tuned doctrine aggression %v naval %v, want 0.9 and 1 (validated)", d.Aggression, d.NavalWeight)
+	}
+	if cur := s.GetCurrentDoctrine(); cur == nil || cur.Doctrine.Aggression != 0.9 {
+		t.Error("tuned doctrine not recorded as current")
+	}
+
+	// The LLM's next doctrine keeps the tweak while it holds...
+	llm := rules.DefaultDoctrine()
+	s.applyTweaks(&llm, 1400)
+	if llm.Aggression != 0.9 {
+		t.Errorf("LLM doctrine aggression = %v, want the held tweak 0.9", llm.Aggression)
+	}
+	// ...and is left alone once it expires.
+	llm = rules.DefaultDoctrine()
+	s.applyTweaks(&llm, 1600)
+	if llm.Aggression != 0.5 || len(s.GetTweaks()) != 0 {
+		t.Errorf("expired tweak still applied: aggression %v, tweaks %v", llm.Aggression, s.GetTweaks())
+	}
+
+	if _, err := s.TuneDoctrine(map[string]float64{"agression": 1}, 0); err == nil {
+		t.Error("misspelt parameter accepted")
+	}
+}
+
+func TestTuneDuringEvaluationIsKept(t *testing.T) {
+	engine, _ := rules.NewEngine(rules.DefaultRules())
+	s := NewStrategist(engine, "balanced", 500)
+	s.latest = &model.GameState{Tick: 1000}
+
+	// An evaluation asked the LLM before the operator tuned; its doctrine
+	// lands after the tune and must not undo it.
+	llm := rules.DefaultDoctrine()
+	if _, err := s.TuneDoctrine(map[string]float64{"aggression": 0.9}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := s.adoptEvaluation(llm, DoctrineRecord{Tick: 1000, Source: SourceStrategist}); err != nil || d.Aggression != 0.9 {
+		t.Fatalf("evaluation landing after a tune: aggression %v, err %v; want the tweak 0.9", d.Aggression, err)
+	}
+
+	// Tunes and evaluations racing: whichever lands last carries every
+	// tweak made before it.
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := s.adoptEvaluation(llm, DoctrineRecord{Tick: 1000, Source: SourceStrategist}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := s.TuneDoctrine(map[string]float64{"air_weight": 0.1 * float64(i%2)}, 0); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	history := s.GetHistory()
+	for _, rec := range history[2:] {
+		if rec.Doctrine.Aggression != 0.9 {
+			t.Errorf("%s doctrine in history dropped the aggression tweak: %v", rec.Source, rec.Doctrine.Aggression)
+		}
+	}
+	last := history[len(history)-1].Doctrine
+	for _, tw := range s.GetTweaks() {
+		if tw.Param == "air_weight" && last.AirWeight != tw.Value {
+			t.Errorf("last doctrine air weight %v, want the latest tune %v", last.AirWeight, tw.Value)
+		}
+	}
+}
@@ -16,7 +16,7 @@ package baml_client
 var file_map = map[string]string{
 
 	"clients.baml":    "// Learn more about clients at https://docs.boundaryml.com/docs/snippets/clients/overview\n\n// Using the new OpenAI Responses API for enhanced formatting\nclient<llm> CustomGPT5 {\n  provider openai-responses\n  options {\n    model \"gpt-5\"\n    api_key env.OPENAI_API_KEY\n  }\n}\n\nclient<llm> CustomGPT5Mini {\n  provider openai-responses\n  retry_policy Exponential\n  options {\n    model \"gpt-5-mini\"\n    api_key env.OPENAI_API_KEY\n  }\n}\n\n// Openai with chat completion\nclient<llm> CustomGPT5Chat {\n  provider openai\n  options {\n    model \"gpt-5\"\n    api_key env.OPENAI_API_KEY\n  }\n}\n\n// Latest Anthropic Claude 4 models\nclient<llm> CustomOpus4 {\n  provider anthropic\n  options {\n    model \"claude-opus-4-1-20250805\"\n    api_key env.ANTHROPIC_API_KEY\n  }\n}\n\nclient<llm> CustomSonnet4 {\n  provider anthropic\n  options {\n    model \"claude-sonnet-4-20250514\"\n    api_key env.ANTHROPIC_API_KEY\n  }\n}\n\nclient<llm> CustomHaiku {\n  provider anthropic\n  retry_policy Constant\n  options {\n    model \"claude-3-5-haiku-20241022\"\n    api_key env.ANTHROPIC_API_KEY\n  }\n}\n\n// Example Google AI client (uncomment to use)\n// client<llm> CustomGemini {\n//   provider google-ai\n//   options {\n//     model \"gemini-2.5-pro\"\n//     api_key env.GOOGLE_API_KEY\n//   }\n// }\n\n// Example AWS Bedrock client (uncomment to use)\n// client<llm> CustomBedrock {\n//   provider aws-bedrock\n//   options {\n//     model \"anthropic.claude-sonnet-4-20250514-v1:0\"\n//     region \"us-east-1\"\n//     // AWS credentials are auto-detected from env vars\n//   }\n// }\n\n// Example Azure OpenAI client (uncomment to use)\n// client<llm> CustomAzure {\n//   provider azure-openai\n//   options {\n//     model \"gpt-5\"\n//     api_key env.AZURE_OPENAI_API_KEY\n//     base_url \"https://MY_RESOURCE_NAME.openai.azure.com/openai/deployments/MY_DEPLOYMENT_ID\"\n//     api_version \"2024-10-01-preview\"\n//   }\n// }\n\n// Example Vertex AI client (uncomment to use)\n// client<llm> CustomVertex {\n//   provider vertex-ai\n//   options {\n//     model \"gemini-2.5-pro\"\n//     location \"us-central1\"\n//     // Uses Google Cloud Application Default Credentials\n//   }\n// }\n\n// Example Ollama client for local models (uncomment to use)\n// client<llm> CustomOllama {\n//   provider openai-generic\n//   options {\n//     base_url \"http://localhost:11434/v1\"\n//     model \"llama4\"\n//     default_role \"user\" // Most local models prefer the user role\n//     // No API key needed for local Ollama\n//   }\n// }\n\n// https://docs.boundaryml.com/docs/snippets/clients/round-robin\nclient<llm> CustomFast {\n  provider round-robin\n  options {\n    // This will alternate between the two clients\n    strategy [CustomGPT5Mini, CustomHaiku]\n  }\n}\n\n// https://docs.boundaryml.com/docs/snippets/clients/fallback\nclient<llm> OpenaiFallback {\n  provider fallback\n  options {\n    // This will try the clients in order until one succeeds\n    strategy [CustomGPT5Mini, CustomGPT5]\n  }\n}\n\n// https://docs.boundaryml.com/docs/snippets/clients/retry\nretry_policy Constant {\n  max_retries 3\n  strategy {\n    type constant_delay\n    delay_ms 200\n  }\n}\n\nretry_policy Exponential {\n  max_retries 2\n  strategy {\n    type exponential_backoff\n    delay_ms 300\n    multiplier 1.5\n    max_delay_ms 10000\n  }\n}",
-	"doctrine.baml":   "class TypeCount {\n  type string @description(\"Internal type code, e.g. 'fact', '3tnk', 'e1'\")\n  count int\n}\n\nclass PowerStatus {\n  drained int\n  provided int\n  state string @description(\"Normal, Low, or Critical\")\n}\n\nclass ActiveProduction {\n  queue string  @description(\"Queue type: Building, Defense, Vehicle, Infantry, Ship, Aircraft\")\n  item string   @description(\"Item currently being produced\")\n  progress int  @description(\"Completion percentage 0-100\")\n}\n\nclass SupportPowerStatus {\n  key string    @description(\"e.g. 'NukeReady', 'IronCurtain'\")\n  status string @description(\"'READY', 'charging', or percentage like '75%'\")\n}\n\nclass SuperweaponFire {\n  key string\n  total_fires int\n  recent_fires int @description(\"Fires since last doctrine evaluation\")\n}\n\nclass SquadInfo {\n  name string\n  role string @description(\"'attack', 'defend', or 'scout'\")\n  unit_count int\n}\n\nclass EnemyBase {\n  owner string\n  x int\n  y int\n  last_seen_tick int\n}\n\nclass GameEvent {\n  kind string   @description(\"e.g. 'critical_building_lost', 'strategy_countered'\")\n  tick int\n  detail string @description(\"Human-readable description of what happened\")\n}\n\nclass CombatStats {\n  infantry_lost int @description(\"Total infantry killed this game\")\n  vehicles_lost int @description(\"Total vehicles lost this game\")\n  aircraft_lost int @description(\"Total aircraft lost this game\")\n}\n\nclass GameSituation {\n  tick int\n  phase string @description(\"'Early Game', 'Mid Game', or 'Late Game'\")\n  cash int\n  resources int\n  resource_capacity int\n  power PowerStatus\n  buildings TypeCount[]\n  units TypeCount[]\n  idle_unit_count int\n  active_production ActiveProduction[]\n  support_powers SupportPowerStatus[]\n  superweapon_fires SuperweaponFire[]\n  squads SquadInfo[]\n  enemies_visible int\n  enemy_units TypeCount[] @description(\"Currently visible enemy units grouped by type code\")\n  enemy_units_seen TypeCount[] @description(\"All enemy unit types ever observed, cumulative counts\")\n  enemy_buildings TypeCount[] @description(\"Currently visible enemy buildings grouped by type code\")\n  enemy_buildings_seen TypeCount[] @description(\"All enemy building types ever observed, cumulative counts\")\n  known_enemy_bases EnemyBase[]\n  map_width int\n  map_height int\n  recent_events GameEvent[]\n  combat_stats CombatStats | null\n}\n\nclass Doctrine {\n  name string @description(\"Short name for this doctrine, e.g. 'Blitzkrieg', 'Turtle Defense', 'Guerrilla Raids'\")\n  rationale string @description(\"Brief explanation of why this doctrine fits the current situation and directive\")\n  economy_priority float @description(\"0.0-1.0: investment in refineries and harvesters\")\n  aggression float @description(\"0.0-1.0: offensive commitment level\")\n  ground_defense_priority float @description(\"0.0-1.0: ground base defense urgency — controls pillbox/turret/tesla caps, ground unit scramble threshold when base is attacked, and minelayer production for area denial (above 0.3)\")\n  air_defense_priority float @description(\"0.0-1.0: anti-air defense urgency — controls AA structure caps and aircraft scramble priority when base is attacked\")\n  tech_priority float @description(\"0.0-1.0: investment in tech buildings\")\n  infantry_weight float @description(\"0.0-1.0: infantry production preference\")\n  vehicle_weight float @description(\"0.0-1.0: vehicle production preference\")\n  air_weight float @description(\"0.0-1.0: air unit production preference\")\n  naval_weight float @description(\"0.0-1.0: naval unit production preference\")\n  ground_attack_group_size int @description(\"Minimum ground units (infantry+vehicles) before launching a ground attack (3-15)\")\n  air_attack_group_size int @description(\"Minimum combat aircraft before launching an air strike (1-8)\")\n  naval_attack_group_size int @description(\"Minimum naval units before launching a naval attack (2-10)\")\n  scout_priority float @description(\"0.0-1.0: reconnaissance investment\")\n  specialized_infantry_weight float @description(\"0.0-1.0: investment in elite infantry (flamethrowers, shock troopers, Tanya, medics) — requires prerequisite buildings already built via other priorities\")\n  superweapon_priority float @description(\"0.0-1.0: investment in superweapon buildings (Missile Silo, Iron Curtain) and eagerness to use them — requires tech center already built via tech_priority. Set above 0.3 to build superweapon structures. Airfield powers (spy plane, paratroopers) are governed by air_weight instead\")\n  capture_priority float @description(\"0.0-1.0: eagerness to capture neutral tech buildings with engineers — controls engineer and APC production. Set above 0.1 to produce engineers, above 0.3 to also produce APCs for delivery. Set to 0 for pure defense doctrines that shouldn't waste the Infantry queue on engineers\")\n  transport_assault float @description(\"0.0-1.0: use APCs to rush infantry into the enemy base. Requires war factory and infantry production. Pair with preferred_infantry to choose which troops to load (e.g. flamethrowers). Set above 0.1 to produce assault APCs. Higher = more APCs (up to 3). Set to 0 for standard play without APC rushes\")\n  preferred_infantry string[] @description(\"Ordered list of infantry roles to prioritize. Soviet: flamethrower, shock_trooper, medic, engineer. Allied: tanya, medic, engineer. Both: rocket_soldier. Only list roles available to your faction. Empty = default priority.\")\n  preferred_vehicle string[] @description(\"Ordered list of vehicle roles to prioritize. Soviet: heavy_tank, medium_tank, tesla_tank, v2_launcher, flak_truck, demo_truck, apc. Allied: medium_tank, light_tank, artillery, ranger, apc. Only list roles available to your faction. Empty = default priority.\")\n  preferred_aircraft string[] @description(\"Ordered list of aircraft roles to prioritize. Soviet: basic_aircraft (Yak/MiG), advanced_aircraft (MiG/Hind). Allied: basic_aircraft (Black Hawk), advanced_aircraft (Longbow). Empty = default priority.\")\n  preferred_naval string[] @description(\"Ordered list of naval roles to prioritize. Soviet: submarine, missile_sub (requires tech center, can attack land targets). Allied: gunboat, destroyer, cruiser. Empty = default priority.\")\n}\n\nfunction GenerateDoctrine(directive: string, situation: GameSituation, faction: string) -> Doctrine {\n  client CustomGPT5Mini\n  prompt #\"\n    You are a military strategist AI for Command & Conquer: Red Alert, a classic real-time strategy game.\n\n    You control the {{ faction }} faction. Your directive from high command is: \"{{ directive }}\"\n\n    Current battlefield situation:\n    Tick: {{ situation.tick }} | Phase: {{ situation.phase }}\n    Cash: {{ situation.cash }} | Resources: {{ situation.resources }}/{{ situation.resource_capacity }}\n    Power: {{ situation.power.drained }}/{{ situation.power.provided }} ({{ situation.power.state }})\n\n    {% if situation.buildings %}\n    Buildings:{% for b in situation.buildings %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n\n    {% if situation.units %}\n    Units:{% for u in situation.units %} {{ u.count }}x {{ u.type }}{% endfor %} ({{ situation.idle_unit_count }} idle)\n    {% endif %}\n\n    {% for pq in situation.active_production %}\n    Queue {{ pq.queue }}: producing {{ pq.item }} ({{ pq.progress }}%)\n    {% endfor %}\n\n    {% if situation.support_powers %}\n    Support Powers:{% for sp in situation.support_powers %} {{ sp.key }}({{ sp.status }}){% endfor %}\n    {% endif %}\n\n    {% if situation.superweapon_fires %}\n    Superweapon launches:{% for sw in situation.superweapon_fires %} {{ sw.key }}={{ sw.total_fires }}{% if sw.recent_fires > 0 %} (+{{ sw.recent_fires }} since last eval){% endif %}{% endfor %}\n    {% endif %}\n\n    {% if situation.squads %}\n    Squads:{% for sq in situation.squads %} {{ sq.name }}({{ sq.role }}, {{ sq.unit_count }} units){% endfor %}\n    {% endif %}\n\n    Enemies visible: {{ situation.enemies_visible }}\n    {% if situation.enemy_units %}\n    Enemy units (visible now):{% for u in situation.enemy_units %} {{ u.count }}x {{ u.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_units_seen %}\n    Enemy units (all observed):{% for u in situation.enemy_units_seen %} {{ u.count }}x {{ u.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_buildings %}\n    Enemy buildings (visible now):{% for b in situation.enemy_buildings %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_buildings_seen %}\n    Enemy buildings (all observed):{% for b in situation.enemy_buildings_seen %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n\n    {% if situation.known_enemy_bases %}\n    {% for eb in situation.known_enemy_bases %}\n    Known enemy base [{{ eb.owner }}]: ({{ eb.x }}, {{ eb.y }}) last seen tick {{ eb.last_seen_tick }}\n    {% endfor %}\n    {% else %}\n    Known enemy bases: none (not yet scouted)\n    {% endif %}\n\n    Map: {{ situation.map_width }}x{{ situation.map_height }}\n\n    {% if situation.recent_events %}\n    Recent Events:\n    {% for e in situation.recent_events %}\n    - [tick {{ e.tick }}] {{ e.kind }}: {{ e.detail }}\n    {% endfor %}\n    {% endif %}\n\n    {% if situation.combat_stats %}\n    Combat Stats (lifetime): Lost {{ situation.combat_stats.infantry_lost }} infantry, {{ situation.combat_stats.vehicles_lost }} vehicles, {{ situation.combat_stats.aircraft_lost }} aircraft\n    {% endif %}\n\n    CRITICAL ADAPTATION RULES:\n    - Your directive is STRATEGIC INTENT, not a rigid constraint. Adapt to battlefield reality.\n    - If recent_events show \"strategy_countered\", you MUST change your doctrine — do not repeat the same failing strategy.\n    - If combat_stats show sustained high losses in one domain (e.g., 20+ infantry lost), that domain is being hard-countered.\n    - When infantry dies to static defenses (tesla coils, flame towers), shift weight to vehicles (0.5+) or air (0.3+) to break through, OR increase ground_attack_group_size to 10+ to overwhelm with numbers.\n    - When vehicles are countered by tesla coils, shift to air strikes or build up tech for mammoth tanks.\n    - High losses with no progress = strategy failing, adapt immediately. Low losses = strategy working, maintain course.\n\n    Based on the directive and current situation, produce a strategic doctrine.\n    All weight values must be between 0.0 and 1.0.\n    ground_attack_group_size must be between 3 and 15.\n    air_attack_group_size must be between 1 and 8.\n    naval_attack_group_size must be between 2 and 10.\n\n    Key considerations:\n    - Economy (refineries/harvesters) fuels everything — neglect it and you stall\n    - Infantry is cheap and fast to produce, good for early pressure and scouting\n    - Vehicles are expensive but powerful — tanks dominate mid-game\n    - Air units require an airfield and are expensive but bypass ground defenses\n    - Naval units require a naval yard and water on the map — set to 0 if no water\n    - ground_defense_priority controls ground base defense: pillbox/turret/tesla caps, how quickly ground units scramble to defend (high = scramble even 1 unit, low = wait for 3+), and minelayer production above 0.3 for laying mines between your base and the enemy. Always set above 0\n    - air_defense_priority controls AA structures (SAM/AA gun caps) and how eagerly aircraft scramble to defend the base. Can be independent of ground defense\n    - Aggression controls how eagerly you attack; low = defensive turtle, high = constant pressure\n    - ground_attack_group_size: smaller = faster but riskier ground attacks, larger = slower but more decisive\n    - air_attack_group_size: how many aircraft to accumulate before striking (1 = harass constantly, 8 = decisive air raids)\n    - naval_attack_group_size: how many ships to accumulate before attacking\n    - Scout priority: higher = more reconnaissance, important when enemy position unknown\n    - Specialized infantry (flamethrowers, commandos, medics) require prerequisite structures — set above 0 only when those are likely available\n    - Superweapon priority controls building the Missile Silo (nuke) and Iron Curtain — both require a tech center. Set above 0.3 to invest. Airfield support powers (spy plane, paratroopers, parabombs) come free with the airfield and are controlled by air_weight instead\n    - Capture priority controls engineer production for seizing neutral tech buildings (oil derricks, hospitals). Set above 0 only if neutral buildings are likely present on the map. High capture priority pairs well with aggression for \"rush and steal\" plays\n    - Unit preferences (preferred_infantry, preferred_vehicle, preferred_aircraft, preferred_naval) let you steer which specific units get built within each category. List role names in priority order. First buildable role wins. Empty list = default priority. Examples: [\"light_tank\", \"medium_tank\"] for a light tank rush, [\"flamethrower\", \"shock_trooper\"] for flame-heavy infantry\n    - Transport assault loads infantry into APCs and drives them to the enemy base for a surprise attack. Pair with infantry_weight and preferred_infantry (e.g. [\"flamethrower\"]) to choose troops. Requires war factory and scouted enemy base. Best as an early/mid-game rush — late game the APCs get destroyed en route\n\n    {{ ctx.output_format }}\n  \"#\n}\n",
+	"doctrine.baml":   "class TypeCount {\n  type string @description(\"Internal type code, e.g. 'fact', '3tnk', 'e1'\")\n  count int\n}\n\nclass PowerStatus {\n  drained int\n  provided int\n  state string @description(\"Normal, Low, or Critical\")\n}\n\nclass ActiveProduction {\n  queue string  @description(\"Queue type: Building, Defense, Vehicle, Infantry, Ship, Aircraft\")\n  item string   @description(\"Item currently being produced\")\n  progress int  @description(\"Completion percentage 0-100\")\n}\n\nclass SupportPowerStatus {\n  key string    @description(\"e.g. 'NukeReady', 'IronCurtain'\")\n  status string @description(\"'READY', 'charging', or percentage like '75%'\")\n}\n\nclass SuperweaponFire {\n  key string\n  total_fires int\n  recent_fires int @description(\"Fires since last doctrine evaluation\")\n}\n\nclass SquadInfo {\n  name string\n  role string @description(\"'attack', 'defend', or 'scout'\")\n  unit_count int\n}\n\nclass EnemyBase {\n  owner string\n  x int\n  y int\n  last_seen_tick int\n}\n\nclass GameEvent {\n  kind string   @description(\"e.g. 'critical_building_lost', 'strategy_countered'\")\n  tick int\n  detail string @description(\"Human-readable description of what happened\")\n}\n\nclass CombatStats {\n  infantry_lost int @description(\"Total infantry killed this game\")\n  vehicles_lost int @description(\"Total vehicles lost this game\")\n  aircraft_lost int @description(\"Total aircraft lost this game\")\n  naval_lost int @description(\"Total ships lost this game\")\n  losses_by_type TypeCount[] @description(\"Our units lost this game, by type\")\n  enemy_kills TypeCount[] @description(\"Approximate enemy units and buildings destroyed this game, by type\")\n  recent_losses TypeCount[] @description(\"Our losses in the last two minutes, by domain: infantry, vehicle, aircraft, naval\")\n  recent_kills TypeCount[] @description(\"Approximate enemy losses in the last two minutes, by domain: infantry, vehicle, aircraft, naval, building\")\n}\n\nclass GameSituation {\n  tick int\n  phase string @description(\"'Early Game', 'Mid Game', or 'Late Game'\")\n  cash int\n  resources int\n  resource_capacity int\n  power PowerStatus\n  buildings TypeCount[]\n  units TypeCount[]\n  idle_unit_count int\n  active_production ActiveProduction[]\n  support_powers SupportPowerStatus[]\n  superweapon_fires SuperweaponFire[]\n  squads SquadInfo[]\n  enemies_visible int\n  enemy_units TypeCount[] @description(\"Currently visible enemy units grouped by type code\")\n  enemy_units_seen TypeCount[] @description(\"All enemy unit types ever observed, cumulative counts\")\n  enemy_buildings TypeCount[] @description(\"Currently visible enemy buildings grouped by type code\")\n  enemy_buildings_seen TypeCount[] @description(\"All enemy building types ever observed, cumulative counts\")\n  known_enemy_bases EnemyBase[]\n  map_width int\n  map_height int\n  recent_events GameEvent[]\n  combat_stats CombatStats | null\n}\n\nclass Doctrine {\n  name string @description(\"Short name for this doctrine, e.g. 'Blitzkrieg', 'Turtle Defense', 'Guerrilla Raids'\")\n  rationale string @description(\"Brief explanation of why this doctrine fits the current situation and directive\")\n  economy_priority float @description(\"0.0-1.0: investment in refineries and harvesters\")\n  aggression float @description(\"0.0-1.0: offensive commitment level\")\n  ground_defense_priority float @description(\"0.0-1.0: ground base defense urgency — controls pillbox/turret/tesla caps, ground unit scramble threshold when base is attacked, and minelayer production for area denial (above 0.3)\")\n  air_defense_priority float @description(\"0.0-1.0: anti-air defense urgency — controls AA structure caps and aircraft scramble priority when base is attacked\")\n  tech_priority float @description(\"0.0-1.0: investment in tech buildings\")\n  infantry_weight float @description(\"0.0-1.0: infantry production preference\")\n  vehicle_weight float @description(\"0.0-1.0: vehicle production preference\")\n  air_weight float @description(\"0.0-1.0: air unit production preference\")\n  naval_weight float @description(\"0.0-1.0: naval unit production preference\")\n  ground_attack_group_size int @description(\"Minimum ground units (infantry+vehicles) before launching a ground attack (3-15)\")\n  air_attack_group_size int @description(\"Minimum combat aircraft before launching an air strike (1-8)\")\n  naval_attack_group_size int @description(\"Minimum naval units before launching a naval attack (2-10)\")\n  scout_priority float @description(\"0.0-1.0: reconnaissance investment\")\n  specialized_infantry_weight float @description(\"0.0-1.0: investment in elite infantry (flamethrowers, shock troopers, Tanya, medics) — requires prerequisite buildings already built via other priorities\")\n  superweapon_priority float @description(\"0.0-1.0: investment in superweapon buildings (Missile Silo, Iron Curtain) and eagerness to use them — requires tech center already built via tech_priority. Set above 0.3 to build superweapon structures. Airfield powers (spy plane, paratroopers) are governed by air_weight instead\")\n  capture_priority float @description(\"0.0-1.0: eagerness to capture neutral tech buildings with engineers — controls engineer and APC production. Set above 0.1 to produce engineers, above 0.3 to also produce APCs for delivery. Set to 0 for pure defense doctrines that shouldn't waste the Infantry queue on engineers\")\n  transport_assault float @description(\"0.0-1.0: use APCs to rush infantry into the enemy base. Requires war factory and infantry production. Pair with preferred_infantry to choose which troops to load (e.g. flamethrowers). Set above 0.1 to produce assault APCs. Higher = more APCs (up to 3). Set to 0 for standard play without APC rushes\")\n  ground_aggression float? @description(\"0.0-1.0: optional aggression for ground attack squads only. Omit to follow aggression\")\n  air_aggression float? @description(\"0.0-1.0: optional aggression for air strikes only. Omit to follow aggression\")\n  naval_aggression float? @description(\"0.0-1.0: optional aggression for naval attacks only. Omit to follow aggression\")\n  preferred_infantry string[] @description(\"Ordered list of infantry roles to prioritize. Soviet: flamethrower, shock_trooper, medic, engineer. Allied: tanya, medic, engineer. Both: rocket_soldier. Only list roles available to your faction. Empty = default priority.\")\n  preferred_vehicle string[] @description(\"Ordered list of vehicle roles to prioritize. Soviet: heavy_tank, medium_tank, tesla_tank, v2_launcher, flak_truck, demo_truck, apc. Allied: medium_tank, light_tank, artillery, ranger, apc. Only list roles available to your faction. Empty = default priority.\")\n  preferred_aircraft string[] @description(\"Ordered list of aircraft roles to prioritize. Soviet: basic_aircraft (Yak/MiG), advanced_aircraft (MiG/Hind). Allied: basic_aircraft (Black Hawk), advanced_aircraft (Longbow). Empty = default priority.\")\n  preferred_naval string[] @description(\"Ordered list of naval roles to prioritize. Soviet: submarine, missile_sub (requires tech center, can attack land targets). Allied: gunboat, destroyer, cruiser. Empty = default priority.\")\n}\n\nfunction GenerateDoctrine(directive: string, situation: GameSituation, faction: string) -> Doctrine {\n  client CustomGPT5Mini\n  prompt #\"\n    You are a military strategist AI for Command & Conquer: Red Alert, a classic real-time strategy game.\n\n    You control the {{ faction }} faction. Your directive from high command is: \"{{ directive }}\"\n\n    Current battlefield situation:\n    Tick: {{ situation.tick }} | Phase: {{ situation.phase }}\n    Cash: {{ situation.cash }} | Resources: {{ situation.resources }}/{{ situation.resource_capacity }}\n    Power: {{ situation.power.drained }}/{{ situation.power.provided }} ({{ situation.power.state }})\n\n    {% if situation.buildings %}\n    Buildings:{% for b in situation.buildings %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n\n    {% if situation.units %}\n    Units:{% for u in situation.units %} {{ u.count }}x {{ u.type }}{% endfor %} ({{ situation.idle_unit_count }} idle)\n    {% endif %}\n\n    {% for pq in situation.active_production %}\n    Queue {{ pq.queue }}: producing {{ pq.item }} ({{ pq.progress }}%)\n    {% endfor %}\n\n    {% if situation.support_powers %}\n    Support Powers:{% for sp in situation.support_powers %} {{ sp.key }}({{ sp.status }}){% endfor %}\n    {% endif %}\n\n    {% if situation.superweapon_fires %}\n    Superweapon launches:{% for sw in situation.superweapon_fires %} {{ sw.key }}={{ sw.total_fires }}{% if sw.recent_fires > 0 %} (+{{ sw.recent_fires }} since last eval){% endif %}{% endfor %}\n    {% endif %}\n\n    {% if situation.squads %}\n    Squads:{% for sq in situation.squads %} {{ sq.name }}({{ sq.role }}, {{ sq.unit_count }} units){% endfor %}\n    {% endif %}\n\n    Enemies visible: {{ situation.enemies_visible }}\n    {% if situation.enemy_units %}\n    Enemy units (visible now):{% for u in situation.enemy_units %} {{ u.count }}x {{ u.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_units_seen %}\n    Enemy units (all observed):{% for u in situation.enemy_units_seen %} {{ u.count }}x {{ u.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_buildings %}\n    Enemy buildings (visible now):{% for b in situation.enemy_buildings %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n    {% if situation.enemy_buildings_seen %}\n    Enemy buildings (all observed):{% for b in situation.enemy_buildings_seen %} {{ b.count }}x {{ b.type }}{% endfor %}\n    {% endif %}\n\n    {% if situation.known_enemy_bases %}\n    {% for eb in situation.known_enemy_bases %}\n    Known enemy base [{{ eb.owner }}]: ({{ eb.x }}, {{ eb.y }}) last seen tick {{ eb.last_seen_tick }}\n    {% endfor %}\n    {% else %}\n    Known enemy bases: none (not yet scouted)\n    {% endif %}\n\n    Map: {{ situation.map_width }}x{{ situation.map_height }}\n\n    {% if situation.recent_events %}\n    Recent Events:\n    {% for e in situation.recent_events %}\n    - [tick {{ e.tick }}] {{ e.kind }}: {{ e.detail }}\n    {% endfor %}\n    {% endif %}\n\n    {% if situation.combat_stats %}\n    Combat Stats (lifetime): Lost {{ situation.combat_stats.infantry_lost }} infantry, {{ situation.combat_stats.vehicles_lost }} vehicles, {{ situation.combat_stats.aircraft_lost }} aircraft, {{ situation.combat_stats.naval_lost }} ships\n    {% if situation.combat_stats.losses_by_type %}\n    Our losses by type: {% for t in situation.combat_stats.losses_by_type %}{{ t.type }}={{ t.count }} {% endfor %}\n    {% endif %}\n    {% if situation.combat_stats.enemy_kills %}\n    Enemy kills by type (approximate): {% for t in situation.combat_stats.enemy_kills %}{{ t.type }}={{ t.count }} {% endfor %}\n    {% endif %}\n    Last two minutes: lost {% for t in situation.combat_stats.recent_losses %}{{ t.count }} {{ t.type }} {% else %}nothing {% endfor %}/ killed {% for t in situation.combat_stats.recent_kills %}{{ t.count }} {{ t.type }} {% else %}nothing{% endfor %}\n    {% endif %}\n\n    CRITICAL ADAPTATION RULES:\n    - Your directive is STRATEGIC INTENT, not a rigid constraint. Adapt to battlefield reality.\n    - If recent_events show \"strategy_countered\", you MUST change your doctrine — do not repeat the same failing strategy.\n    - If combat_stats show sustained high losses in one domain (e.g., 20+ infantry lost), that domain is being hard-countered.\n    - When infantry dies to static defenses (tesla coils, flame towers), shift weight to vehicles (0.5+) or air (0.3+) to break through, OR increase ground_attack_group_size to 10+ to overwhelm with numbers.\n    - When vehicles are countered by tesla coils, shift to air strikes or build up tech for mammoth tanks.\n    - High losses with no progress = strategy failing, adapt immediately. Low losses = strategy working, maintain course.\n    - Weigh the last two minutes over lifetime totals: losses climbing while kills stay flat means the current approach is being countered now.\n\n    Based on the directive and current situation, produce a strategic doctrine.\n    All weight values must be between 0.0 and 1.0.\n    ground_attack_group_size must be between 3 and 15.\n    air_attack_group_size must be between 1 and 8.\n    naval_attack_group_size must be between 2 and 10.\n\n    Key considerations:\n    - Economy (refineries/harvesters) fuels everything — neglect it and you stall\n    - Infantry is cheap and fast to produce, good for early pressure and scouting\n    - Vehicles are expensive but powerful — tanks dominate mid-game\n    - Air units require an airfield and are expensive but bypass ground defenses\n    - Naval units require a naval yard and water on the map — set to 0 if no water\n    - ground_defense_priority controls ground base defense: pillbox/turret/tesla caps, how quickly ground units scramble to defend (high = scramble even 1 unit, low = wait for 3+), and minelayer production above 0.3 for laying mines between your base and the enemy. Always set above 0\n    - air_defense_priority controls AA structures (SAM/AA gun caps) and how eagerly aircraft scramble to defend the base. Can be independent of ground defense\n    - Aggression controls how eagerly you attack; low = defensive turtle, high = constant pressure\n    - ground_attack_group_size: smaller = faster but riskier ground attacks, larger = slower but more decisive\n    - air_attack_group_size: how many aircraft to accumulate before striking (1 = harass constantly, 8 = decisive air raids)\n    - naval_attack_group_size: how many ships to accumulate before attacking\n    - Scout priority: higher = more reconnaissance, important when enemy position unknown\n    - Specialized infantry (flamethrowers, commandos, medics) require prerequisite structures — set above 0 only when those are likely available\n    - Superweapon priority controls building the Missile Silo (nuke) and Iron Curtain — both require a tech center. Set above 0.3 to invest. Airfield support powers (spy plane, paratroopers, parabombs) come free with the airfield and are controlled by air_weight instead\n    - Capture priority controls engineer production for seizing neutral tech buildings (oil derricks, hospitals). Set above 0 only if neutral buildings are likely present on the map. High capture priority pairs well with aggression for \"rush and steal\" plays\n    - Unit preferences (preferred_infantry, preferred_vehicle, preferred_aircraft, preferred_naval) let you steer which specific units get built within each category. List role names in priority order. First buildable role wins. Empty list = default priority. Examples: [\"light_tank\", \"medium_tank\"] for a light tank rush, [\"flamethrower\", \"shock_trooper\"] for flame-heavy infantry\n    - Transport assault loads infantry into APCs and drives them to the enemy base for a surprise attack. Pair with infantry_weight and preferred_infantry (e.g. [\"flamethrower\"]) to choose troops. Requires war factory and scouted enemy base. Best as an early/mid-game rush — late game the APCs get destroyed en route\n    - ground_aggression, air_aggression and naval_aggression override aggression for one domain's attacks, e.g. air_aggression 0.8 with ground_aggression 0.1 harasses by air while turtling on land. Leave them out unless the domains should differ\n\n    {{ ctx.output_format }}\n  \"#\n}\n",
 	"generators.baml": "// This helps use auto generate libraries you can use in the language of\n// your choice. You can have multiple generators if you use multiple languages.\n// Just ensure that the output_dir is different for each generator.\ngenerator target {\n    // Valid values: \"python/pydantic\", \"typescript\", \"go\", \"rust\", \"ruby/sorbet\", \"rest/openapi\"\n    output_type \"go\"\n\n    // Where the generated code will be saved (relative to baml_src/)\n    output_dir \"../\"\n\n    // The version of the BAML package you have installed (e.g. same version as your baml-py or @boundaryml/baml).\n    // The BAML VSCode extension version should also match this version.\n    version \"0.219.0\"\n\n    // 'baml-cli generate' will run this after generating go code\n    // This command will be run from within $output_dir/baml_client\n    on_generate \"gofmt -w . && goimports -w .\"\n\n    // Your Go packages name as specified in go.mod\n    // We need this to generate correct imports in the generated baml_client\n    client_package_name \"github.com/nstehr/vimy/vimy-core\"\n}\n",
 }
 
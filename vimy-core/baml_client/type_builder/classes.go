@@ -83,6 +83,26 @@ func (t *CombatStatsClassView) PropertyAircraft_lost() (ClassPropertyView, error
 	return t.inner.Property("aircraft_lost")
 }
 
+func (t *CombatStatsClassView) PropertyNaval_lost() (ClassPropertyView, error) {
+	return t.inner.Property("naval_lost")
+}
+
+func (t *CombatStatsClassView) PropertyLosses_by_type() (ClassPropertyView, error) {
+	return t.inner.Property("losses_by_type")
+}
+
+func (t *CombatStatsClassView) PropertyEnemy_kills() (ClassPropertyView, error) {
+	return t.inner.Property("enemy_kills")
+}
+
+func (t *CombatStatsClassView) PropertyRecent_losses() (ClassPropertyView, error) {
+	return t.inner.Property("recent_losses")
+}
+
+func (t *CombatStatsClassView) PropertyRecent_kills() (ClassPropertyView, error) {
+	return t.inner.Property("recent_kills")
+}
+
 func (t *TypeBuilder) CombatStats() (*CombatStatsClassView, error) {
 	bld, err := t.inner.Class("CombatStats")
 	if err != nil {
@@ -187,6 +207,18 @@ func (t *DoctrineClassView) PropertyTransport_assault() (ClassPropertyView, erro
 	return t.inner.Property("transport_assault")
 }
 
+func (t *DoctrineClassView) PropertyGround_aggression() (ClassPropertyView, error) {
+	return t.inner.Property("ground_aggression")
+}
+
+func (t *DoctrineClassView) PropertyAir_aggression() (ClassPropertyView, error) {
+	return t.inner.Property("air_aggression")
+}
+
+func (t *DoctrineClassView) PropertyNaval_aggression() (ClassPropertyView, error) {
+	return t.inner.Property("naval_aggression")
+}
+
 func (t *DoctrineClassView) PropertyPreferred_infantry() (ClassPropertyView, error) {
 	return t.inner.Property("preferred_infantry")
 }
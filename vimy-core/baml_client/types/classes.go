@@ -75,9 +75,14 @@ func (c ActiveProduction) BamlTypeName() string {
 }
 
 type CombatStats struct {
-	Infantry_lost int64 `json:"infantry_lost"`
-	Vehicles_lost int64 `json:"vehicles_lost"`
-	Aircraft_lost int64 `json:"aircraft_lost"`
+	Infantry_lost  int64       `json:"infantry_lost"`
+	Vehicles_lost  int64       `json:"vehicles_lost"`
+	Aircraft_lost  int64       `json:"aircraft_lost"`
+	Naval_lost     int64       `json:"naval_lost"`
+	Losses_by_type []TypeCount `json:"losses_by_type"`
+	Enemy_kills    []TypeCount `json:"enemy_kills"`
+	Recent_losses  []TypeCount `json:"recent_losses"`
+	Recent_kills   []TypeCount `json:"recent_kills"`
 }
 
 func (c *CombatStats) Decode(holder *cffi.CFFIValueClass, typeMap baml.TypeMap) {
@@ -103,6 +108,21 @@ func (c *CombatStats) Decode(holder *cffi.CFFIValueClass, typeMap baml.TypeMap)
 		case "aircraft_lost":
 			c.Aircraft_lost = baml.Decode(valueHolder).Int()
 
+		case "naval_lost":
+			c.Naval_lost = baml.Decode(valueHolder).Int()
+
+		case "losses_by_type":
+			c.Losses_by_type = baml.Decode(valueHolder).Interface().([]TypeCount)
+
+		case "enemy_kills":
+			c.Enemy_kills = baml.Decode(valueHolder).Interface().([]TypeCount)
+
+		case "recent_losses":
+			c.Recent_losses = baml.Decode(valueHolder).Interface().([]TypeCount)
+
+		case "recent_kills":
+			c.Recent_kills = baml.Decode(valueHolder).Interface().([]TypeCount)
+
 		default:
 
 			panic(fmt.Sprintf("unexpected field: %s in class CombatStats", key))
@@ -121,6 +141,16 @@ func (c CombatStats) Encode() (*cffi.HostValue, error) {
 
 	fields["aircraft_lost"] = c.Aircraft_lost
 
+	fields["naval_lost"] = c.Naval_lost
+
+	fields["losses_by_type"] = c.Losses_by_type
+
+	fields["enemy_kills"] = c.Enemy_kills
+
+	fields["recent_losses"] = c.Recent_losses
+
+	fields["recent_kills"] = c.Recent_kills
+
 	return baml.EncodeClass("CombatStats", fields, nil)
 }
 
@@ -148,6 +178,9 @@ type Doctrine struct {
 	Superweapon_priority        float64  `json:"superweapon_priority"`
 	Capture_priority            float64  `json:"capture_priority"`
 	Transport_assault           float64  `json:"transport_assault"`
+	Ground_aggression           *float64 `json:"ground_aggression"`
+	Air_aggression              *float64 `json:"air_aggression"`
+	Naval_aggression            *float64 `json:"naval_aggression"`
 	Preferred_infantry          []string `json:"preferred_infantry"`
 	Preferred_vehicle           []string `json:"preferred_vehicle"`
 	Preferred_aircraft          []string `json:"preferred_aircraft"`
@@ -225,6 +258,15 @@ func (c *Doctrine) Decode(holder *cffi.CFFIValueClass, typeMap baml.TypeMap) {
 		case "transport_assault":
 			c.Transport_assault = baml.Decode(valueHolder).Float()
 
+		case "ground_aggression":
+			c.Ground_aggression = baml.Decode(valueHolder).Interface().(*float64)
+
+		case "air_aggression":
+			c.Air_aggression = baml.Decode(valueHolder).Interface().(*float64)
+
+		case "naval_aggression":
+			c.Naval_aggression = baml.Decode(valueHolder).Interface().(*float64)
+
 		case "preferred_infantry":
 			c.Preferred_infantry = baml.Decode(valueHolder).Interface().([]string)
 
@@ -287,6 +329,12 @@ func (c Doctrine) Encode() (*cffi.HostValue, error) {
 
 	fields["transport_assault"] = c.Transport_assault
 
+	fields["ground_aggression"] = c.Ground_aggression
+
+	fields["air_aggression"] = c.Air_aggression
+
+	fields["naval_aggression"] = c.Naval_aggression
+
 	fields["preferred_infantry"] = c.Preferred_infantry
 
 	fields["preferred_vehicle"] = c.Preferred_vehicle
@@ -0,0 +1,277 @@
+// Package brain wires the rule engine, strategist, dashboard and game
+// socket together, so the vimy AI can be embedded in other Go programs
+// (tournament runners, test harnesses) as well as run from the vimy binary.
+package brain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/agent"
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/rules"
+	"github.com/nstehr/vimy/vimy-core/server"
+)
+
+// DefaultSocketPath is where the OpenRA mod connects (VimyBotModule.PipePath).
+const DefaultSocketPath = "/tmp/vimy.sock"
+
+// Options configures a Brain. The zero value is a rules-only AI listening on
+// DefaultSocketPath with no dashboard.
+type Options struct {
+	// Directive seeds the LLM strategist; empty runs the default rules only.
+	Directive string
+	// Opening names a scripted opening book (see rules.OpeningNames).
+	Opening string
+	// Overrides are per-rule overrides applied to every rule set.
+	Overrides rules.RuleOverrides
+	// CategoryPolicies limit how many rules and commands each rule category
+	// may use per tick, and how its equal-priority rules are ordered.
+	CategoryPolicies rules.CategoryPolicies
+	// TickBudget is how long one rule evaluation may take before it sheds
+	// low-priority categories (see rules.Engine.SetTickBudget); zero disables it.
+	TickBudget time.Duration
+	// ConditionWorkers evaluates non-exclusive rule conditions across this
+	// many goroutines (see rules.Engine.SetConditionWorkers); 0 or 1 is serial.
+	ConditionWorkers int
+	// FactionPreferences override the built-in per-faction unit preferences.
+	FactionPreferences rules.FactionPreferences
+	// EventPolicies override how strategist events trigger re-evaluation.
+	EventPolicies agent.TriggerPolicies
+	// StrategistInterval is ticks between scheduled re-evaluations (default 500).
+	StrategistInterval int
+	// StrategistCooldown is the minimum ticks between evaluations triggered
+	// by medium-severity events (default 100).
+	StrategistCooldown int
+	// DoctrineConstraints pin or bound doctrine parameters the LLM chooses.
+	DoctrineConstraints rules.DoctrineConstraints
+	// LLMBudget caps the strategist's LLM use per game; zero is unlimited.
+	LLMBudget agent.Budget
+	// ReconnectWindow is how long a dropped game session is kept for the mod
+	// to reconnect (default 30s).
+	ReconnectWindow time.Duration
+	// SocketPath is the Unix socket Run listens on (default DefaultSocketPath).
+	SocketPath string
+	// DashboardAddr is the HTTP dashboard listen address; empty disables it.
+	DashboardAddr string
+	// Seed fixes the engine's random source so identical game states produce
+	// identical decisions; 0 seeds from the clock.
+	Seed int64
+	// CheckpointDir is where session state is checkpointed so a restarted
+	// brain can resume a match; empty disables checkpointing.
+	CheckpointDir string
+	// CheckpointInterval is ticks between checkpoints (default 250).
+	CheckpointInterval int
+	// ResultsDB is the data source of a database logging every strategist
+	// evaluation for offline analysis; empty disables it.
+	ResultsDB string
+	// ResultsDriver is the database/sql driver for ResultsDB (default
+	// agent.DefaultResultsDriver). The binary must link it.
+	ResultsDriver string
+}
+
+// Brain is one vimy AI instance: a rule engine, an optional strategist and
+// the session store shared by the game connections it serves.
+type Brain struct {
+	opts        Options
+	engine      *rules.Engine
+	strategist  *agent.Strategist
+	sessions    *agent.SessionStore
+	checkpoints *agent.Checkpointer // nil when checkpointing is disabled
+	results     *agent.ResultsStore // nil when the results database is disabled
+}
+
+// New builds the engine and strategist described by opts.
+func New(opts Options) (*Brain, error) {
+	if opts.SocketPath == "" {
+		opts.SocketPath = DefaultSocketPath
+	}
+	if opts.ReconnectWindow == 0 {
+		opts.ReconnectWindow = 30 * time.Second
+	}
+	if opts.StrategistInterval <= 0 {
+		opts.StrategistInterval = 500
+	}
+	if opts.CheckpointInterval <= 0 {
+		opts.CheckpointInterval = 250
+	}
+	if opts.ResultsDriver == "" {
+		opts.ResultsDriver = agent.DefaultResultsDriver
+	}
+	if opts.Opening != "" {
+		if _, ok := rules.LookupOpening(opts.Opening); !ok {
+			return nil, fmt.Errorf("unknown opening book %q (available: %v)", opts.Opening, rules.OpeningNames())
+		}
+	}
+
+	baseRules := append(rules.DefaultRules(), rules.OpeningRules(opts.Opening)...)
+	engine, err := rules.NewEngine(baseRules)
+	if err != nil {
+		return nil, fmt.Errorf("create rule engine: %w", err)
+	}
+	if opts.Seed != 0 {
+		engine.SetSeed(opts.Seed)
+	}
+	slog.Info("rule engine initialized", "rules", len(baseRules), "opening", opts.Opening, "seed", engine.Seed())
+
+	if opts.FactionPreferences != nil {
+		engine.SetFactionPreferences(opts.FactionPreferences)
+	}
+
+	if opts.Overrides != nil {
+		if err := engine.SetOverrides(opts.Overrides); err != nil {
+			return nil, fmt.Errorf("apply rule overrides: %w", err)
+		}
+	}
+
+	if opts.CategoryPolicies != nil {
+		if err := engine.SetCategoryPolicies(opts.CategoryPolicies); err != nil {
+			return nil, fmt.Errorf("apply category policies: %w", err)
+		}
+	}
+
+	if opts.TickBudget > 0 {
+		engine.SetTickBudget(opts.TickBudget)
+	}
+	if opts.ConditionWorkers > 1 {
+		engine.SetConditionWorkers(opts.ConditionWorkers)
+	}
+
+	var strategist *agent.Strategist
+	if opts.Directive != "" {
+		strategist = agent.NewStrategist(engine, opts.Directive, opts.StrategistInterval)
+		strategist.SetOpening(opts.Opening)
+		if opts.StrategistCooldown > 0 {
+			strategist.SetCooldown(opts.StrategistCooldown)
+		}
+		strategist.SetBudget(opts.LLMBudget)
+		strategist.SetConstraints(opts.DoctrineConstraints)
+		if opts.EventPolicies != nil {
+			strategist.SetTriggerPolicies(opts.EventPolicies)
+		}
+	}
+
+	var checkpoints *agent.Checkpointer
+	if opts.CheckpointDir != "" {
+		checkpoints, err = agent.NewCheckpointer(opts.CheckpointDir, opts.CheckpointInterval)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("session checkpointing enabled", "dir", opts.CheckpointDir)
+	}
+
+	var results *agent.ResultsStore
+	if opts.ResultsDB != "" {
+		if strategist == nil {
+			slog.Warn("results database ignored: no strategist to record", "db", opts.ResultsDB)
+		} else {
+			results, err = agent.OpenResults(opts.ResultsDriver, opts.ResultsDB)
+			if err != nil {
+				return nil, err
+			}
+			strategist.SetResults(results)
+			slog.Info("recording strategist evaluations", "db", opts.ResultsDB, "driver", opts.ResultsDriver)
+		}
+	}
+
+	return &Brain{
+		opts:        opts,
+		engine:      engine,
+		strategist:  strategist,
+		sessions:    agent.NewSessionStore(opts.ReconnectWindow),
+		checkpoints: checkpoints,
+		results:     results,
+	}, nil
+}
+
+// Engine returns the brain's rule engine.
+func (b *Brain) Engine() *rules.Engine { return b.engine }
+
+// Strategist returns the LLM strategist, or nil when no directive was given.
+func (b *Brain) Strategist() *agent.Strategist { return b.strategist }
+
+// Run starts the dashboard (if configured), listens on the game socket and
+// serves connections until ctx is cancelled.
+func (b *Brain) Run(ctx context.Context) error {
+	if b.results != nil {
+		defer b.results.Close()
+	}
+	if b.opts.DashboardAddr != "" {
+		srv := server.New(b.engine, b.strategist)
+		go func() {
+			slog.Info("starting dashboard", "addr", b.opts.DashboardAddr)
+			if err := srv.Start(b.opts.DashboardAddr); err != nil {
+				slog.Error("dashboard server failed", "error", err)
+			}
+		}()
+	}
+
+	// Unix sockets leave behind a file on unclean shutdown; remove it so we can rebind.
+	if err := os.RemoveAll(b.opts.SocketPath); err != nil {
+		return fmt.Errorf("clean up socket %s: %w", b.opts.SocketPath, err)
+	}
+	listener, err := net.Listen("unix", b.opts.SocketPath)
+	if err != nil {
+		return fmt.Errorf("listen on socket %s: %w", b.opts.SocketPath, err)
+	}
+	defer os.Remove(b.opts.SocketPath)
+
+	slog.Info("listening on domain socket", "path", b.opts.SocketPath)
+	return b.Serve(ctx, listener)
+}
+
+// Serve accepts game connections on l until ctx is cancelled, then closes l.
+// Harnesses can pass their own listener (TCP, in-memory) instead of Run's
+// Unix socket.
+func (b *Brain) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			slog.Error("failed to accept connection", "error", err)
+			continue
+		}
+		slog.Info("new connection accepted")
+		go b.handleConn(ctx, conn)
+	}
+}
+
+func (b *Brain) handleConn(ctx context.Context, conn net.Conn) {
+	c := ipc.NewConnection(conn, nil)
+	c.SetAuditor(b.engine.RecordCommand)
+	a := agent.New(c, b.engine, b.strategist, b.sessions, ctx)
+	a.Checkpoints = b.checkpoints
+	c.RegisterHandler(ipc.TypeHello, a.HandleHello)
+	c.RegisterHandler(ipc.TypeGameState, a.HandleGameState)
+	go c.Watch(ctx)
+	c.ReadLoop()
+	a.Detach()
+	if b.strategist != nil {
+		logReport(b.strategist.Report())
+	}
+}
+
+// logReport logs the doctrine timeline when a game's connection closes, so
+// the strategist's decisions can be reviewed from the log after the game.
+// The dashboard serves the same report at /api/report.
+func logReport(r agent.MatchReport) {
+	slog.Info("match report", "faction", r.Faction, "directive", r.Directive, "ticks", r.LastTick, "phases", len(r.Timeline))
+	for i, p := range r.Timeline {
+		slog.Info("doctrine phase", "phase", i+1, "from", p.StartTick, "to", p.EndTick, "doctrine", p.Doctrine,
+			"source", p.Source, "trigger", p.Trigger, "rationale", p.Rationale, "changes", len(p.Changes))
+	}
+}
@@ -0,0 +1,58 @@
+package brain
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNewRejectsUnknownOpening(t *testing.T) {
+	if _, err := New(Options{Opening: "no-such-book"}); err == nil {
+		t.Error("expected an error for an unknown opening book")
+	}
+}
+
+func TestNewRulesOnly(t *testing.T) {
+	b, err := New(Options{Opening: "standard"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if b.Engine() == nil {
+		t.Error("engine not created")
+	}
+	if b.Strategist() != nil {
+		t.Error("strategist created without a directive")
+	}
+}
+
+func TestServeStopsOnCancel(t *testing.T) {
+	b, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Serve(ctx, l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned %v after cancel", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// actorInfo is the little the mock knows about an actor type. Costs follow
+// the RA mod; health, speed and weapons are rough and only need to rank
+// actors sensibly against each other.
+type actorInfo struct {
+	queue   string   // production queue that builds it; "" if none does
+	side    string   // rules.SideSoviet, rules.SideAllies, or "" for both
+	cost    int      // credits
+	hp      int      // maximum health
+	power   int      // buildings: positive provides, negative drains
+	prereqs []string // buildings required first; "a|b" means either
+	speed   int      // ticks per cell moved; 0 for buildings
+	damage  int      // per shot; 0 if unarmed
+	reach   int      // weapon range in cells
+}
+
+// buildTicksPerCredit sets production time from cost: a 300-credit power
+// plant takes 75 ticks, a 1150-credit heavy tank 287.
+const buildTicksPerCredit = 0.25
+
+const (
+	anyBarracks = rules.SovietBarracks + "|" + rules.AlliedBarracks
+	anyAirfield = rules.Airfield + "|" + rules.Helipad
+	anyTech     = rules.SovietTechCenter + "|" + rules.AlliedTechCenter
+)
+
+var catalog = map[string]actorInfo{
+	// Buildings.
+	rules.ConstructionYard: {hp: 1500, power: 0},
+	rules.PowerPlant:       {queue: rules.QueueBuilding, cost: 300, hp: 400, power: 100},
+	rules.AdvancedPower:    {queue: rules.QueueBuilding, cost: 500, hp: 700, power: 200, prereqs: []string{rules.RadarDome}},
+	rules.Refinery:         {queue: rules.QueueBuilding, cost: 1400, hp: 900, power: -30, prereqs: []string{rules.PowerPlant + "|" + rules.AdvancedPower}},
+	rules.OreSilo:          {queue: rules.QueueBuilding, cost: 150, hp: 300, power: -10, prereqs: []string{rules.Refinery}},
+	rules.SovietBarracks:   {queue: rules.QueueBuilding, side: rules.SideSoviet, cost: 400, hp: 600, power: -20, prereqs: []string{rules.PowerPlant + "|" + rules.AdvancedPower}},
+	rules.AlliedBarracks:   {queue: rules.QueueBuilding, side: rules.SideAllies, cost: 400, hp: 600, power: -20, prereqs: []string{rules.PowerPlant + "|" + rules.AdvancedPower}},
+	rules.Kennel:           {queue: rules.QueueBuilding, side: rules.SideSoviet, cost: 200, hp: 300, power: -10, prereqs: []string{rules.SovietBarracks}},
+	rules.WarFactory:       {queue: rules.QueueBuilding, cost: 2000, hp: 1000, power: -30, prereqs: []string{rules.Refinery}},
+	rules.RadarDome:        {queue: rules.QueueBuilding, cost: 1000, hp: 700, power: -40, prereqs: []string{rules.Refinery}},
+	rules.ServiceDepot:     {queue: rules.QueueBuilding, cost: 1200, hp: 800, power: -30, prereqs: []string{rules.WarFactory}},
+	rules.Airfield:         {queue: rules.QueueBuilding, side: rules.SideSoviet, cost: 500, hp: 700, power: -20, prereqs: []string{rules.RadarDome}},
+	rules.Helipad:          {queue: rules.QueueBuilding, side: rules.SideAllies, cost: 500, hp: 700, power: -10, prereqs: []string{rules.RadarDome}},
+	rules.SovietTechCenter: {queue: rules.QueueBuilding, side: rules.SideSoviet, cost: 1500, hp: 1000, power: -100, prereqs: []string{rules.WarFactory, rules.RadarDome}},
+	rules.AlliedTechCenter: {queue: rules.QueueBuilding, side: rules.SideAllies, cost: 1500, hp: 1000, power: -200, prereqs: []string{rules.WarFactory, rules.RadarDome}},
+
+	// Defenses.
+	rules.Pillbox:     {queue: rules.QueueDefense, side: rules.SideAllies, cost: 400, hp: 400, power: -15, prereqs: []string{rules.AlliedBarracks}, damage: 8, reach: 5},
+	rules.CamoPillbox: {queue: rules.QueueDefense, side: rules.SideAllies, cost: 600, hp: 600, power: -15, prereqs: []string{rules.AlliedBarracks}, damage: 8, reach: 5},
+	rules.Turret:      {queue: rules.QueueDefense, side: rules.SideAllies, cost: 600, hp: 400, power: -40, prereqs: []string{rules.AlliedBarracks}, damage: 20, reach: 6},
+	rules.FlameTower:  {queue: rules.QueueDefense, side: rules.SideSoviet, cost: 600, hp: 400, power: -20, prereqs: []string{rules.SovietBarracks}, damage: 15, reach: 4},
+	rules.TeslaCoil:   {queue: rules.QueueDefense, side: rules.SideSoviet, cost: 1200, hp: 400, power: -100, prereqs: []string{rules.WarFactory}, damage: 50, reach: 6},
+	rules.AAGun:       {queue: rules.QueueDefense, side: rules.SideAllies, cost: 800, hp: 400, power: -50, prereqs: []string{rules.RadarDome}, damage: 10, reach: 6},
+	rules.SAMSite:     {queue: rules.QueueDefense, side: rules.SideSoviet, cost: 750, hp: 400, power: -40, prereqs: []string{rules.RadarDome}, damage: 10, reach: 6},
+
+	// Infantry.
+	rules.RifleInfantry: {queue: rules.QueueInfantry, cost: 100, hp: 50, prereqs: []string{anyBarracks}, speed: 4, damage: 3, reach: 5},
+	rules.Grenadier:     {queue: rules.QueueInfantry, side: rules.SideSoviet, cost: 160, hp: 50, prereqs: []string{rules.SovietBarracks}, speed: 4, damage: 6, reach: 4},
+	rules.RocketSoldier: {queue: rules.QueueInfantry, cost: 300, hp: 45, prereqs: []string{anyBarracks}, speed: 4, damage: 8, reach: 5},
+	rules.Flamethrower:  {queue: rules.QueueInfantry, side: rules.SideSoviet, cost: 300, hp: 40, prereqs: []string{rules.SovietBarracks}, speed: 4, damage: 10, reach: 2},
+	rules.Engineer:      {queue: rules.QueueInfantry, cost: 500, hp: 25, prereqs: []string{anyBarracks}, speed: 4},
+	rules.AttackDog:     {queue: rules.QueueInfantry, side: rules.SideSoviet, cost: 200, hp: 12, prereqs: []string{rules.Kennel}, speed: 2, damage: 10, reach: 1},
+	rules.Medic:         {queue: rules.QueueInfantry, side: rules.SideAllies, cost: 200, hp: 80, prereqs: []string{rules.AlliedBarracks}, speed: 4},
+	rules.ShockTrooper:  {queue: rules.QueueInfantry, side: rules.SideSoviet, cost: 350, hp: 80, prereqs: []string{rules.SovietTechCenter}, speed: 4, damage: 12, reach: 3},
+	rules.Tanya:         {queue: rules.QueueInfantry, side: rules.SideAllies, cost: 1200, hp: 100, prereqs: []string{rules.AlliedTechCenter}, speed: 3, damage: 25, reach: 5},
+
+	// Vehicles.
+	rules.Harvester:   {queue: rules.QueueVehicle, cost: 1100, hp: 600, prereqs: []string{rules.WarFactory, rules.Refinery}, speed: 3},
+	rules.MCV:         {queue: rules.QueueVehicle, cost: 2500, hp: 600, prereqs: []string{rules.WarFactory, rules.ServiceDepot}, speed: 4},
+	rules.LightTank:   {queue: rules.QueueVehicle, side: rules.SideAllies, cost: 700, hp: 230, prereqs: []string{rules.WarFactory}, speed: 2, damage: 12, reach: 5},
+	rules.MediumTank:  {queue: rules.QueueVehicle, side: rules.SideAllies, cost: 850, hp: 450, prereqs: []string{rules.WarFactory}, speed: 3, damage: 18, reach: 5},
+	rules.HeavyTank:   {queue: rules.QueueVehicle, side: rules.SideSoviet, cost: 1150, hp: 600, prereqs: []string{rules.WarFactory}, speed: 3, damage: 22, reach: 5},
+	rules.MammothTank: {queue: rules.QueueVehicle, side: rules.SideSoviet, cost: 2000, hp: 900, prereqs: []string{rules.WarFactory, rules.SovietTechCenter}, speed: 4, damage: 35, reach: 5},
+	rules.V2Launcher:  {queue: rules.QueueVehicle, side: rules.SideSoviet, cost: 900, hp: 150, prereqs: []string{rules.WarFactory, rules.RadarDome}, speed: 3, damage: 40, reach: 10},
+	rules.APC:         {queue: rules.QueueVehicle, cost: 800, hp: 300, prereqs: []string{rules.WarFactory}, speed: 2, damage: 6, reach: 4},
+	rules.Ranger:      {queue: rules.QueueVehicle, side: rules.SideAllies, cost: 500, hp: 150, prereqs: []string{rules.WarFactory}, speed: 1, damage: 6, reach: 4},
+	rules.Artillery:   {queue: rules.QueueVehicle, side: rules.SideAllies, cost: 850, hp: 75, prereqs: []string{rules.WarFactory, rules.RadarDome}, speed: 4, damage: 40, reach: 10},
+	rules.FlakTruck:   {queue: rules.QueueVehicle, side: rules.SideSoviet, cost: 600, hp: 150, prereqs: []string{rules.WarFactory}, speed: 2, damage: 8, reach: 6},
+	rules.TeslaTank:   {queue: rules.QueueVehicle, side: rules.SideSoviet, cost: 1350, hp: 300, prereqs: []string{rules.WarFactory, rules.SovietTechCenter}, speed: 3, damage: 40, reach: 5},
+
+	// Aircraft.
+	rules.Yak:       {queue: rules.QueueAircraft, side: rules.SideSoviet, cost: 1000, hp: 60, prereqs: []string{rules.Airfield}, speed: 1, damage: 20, reach: 4},
+	rules.MiG:       {queue: rules.QueueAircraft, side: rules.SideSoviet, cost: 1200, hp: 80, prereqs: []string{rules.Airfield}, speed: 1, damage: 40, reach: 4},
+	rules.Hind:      {queue: rules.QueueAircraft, side: rules.SideSoviet, cost: 1350, hp: 225, prereqs: []string{rules.Airfield}, speed: 1, damage: 20, reach: 4},
+	rules.Longbow:   {queue: rules.QueueAircraft, side: rules.SideAllies, cost: 2000, hp: 225, prereqs: []string{rules.Helipad}, speed: 1, damage: 40, reach: 4},
+	rules.BlackHawk: {queue: rules.QueueAircraft, side: rules.SideAllies, cost: 1350, hp: 225, prereqs: []string{rules.Helipad}, speed: 1, damage: 20, reach: 4},
+}
+
+// producers are the buildings that give each production queue. The
+// Building and Defense queues live on the construction yard.
+var producers = map[string][]string{
+	rules.QueueBuilding: {rules.ConstructionYard},
+	rules.QueueDefense:  {rules.ConstructionYard},
+	rules.QueueInfantry: {rules.SovietBarracks, rules.AlliedBarracks},
+	rules.QueueVehicle:  {rules.WarFactory},
+	rules.QueueAircraft: {rules.Airfield, rules.Helipad},
+}
+
+// queueOrder is the order queues appear in a game state.
+var queueOrder = []string{rules.QueueBuilding, rules.QueueDefense, rules.QueueInfantry, rules.QueueVehicle, rules.QueueAircraft}
+
+// buildTicks is how long t takes to produce.
+func (a actorInfo) buildTicks() int {
+	return max(1, int(float64(a.cost)*buildTicksPerCredit))
+}
+
+// isBuilding reports whether the type is a structure rather than a unit.
+func (a actorInfo) isBuilding() bool { return a.speed == 0 }
+
+// prereqsMet reports whether every prerequisite of a has one of its
+// alternatives among have.
+func (a actorInfo) prereqsMet(have map[string]bool) bool {
+	for _, p := range a.prereqs {
+		met := false
+		for _, alt := range strings.Split(p, "|") {
+			met = met || have[alt]
+		}
+		if !met {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+// Command vimy-mock-sidecar stands in for OpenRA and the Vimy mod so
+// vimy-core can be developed and demoed without installing the game. It
+// connects to vimy-core's Unix socket as the mod would, says hello, and
+// plays a crude skirmish: production takes time and costs credits,
+// refineries pay through their harvesters, units walk a cell at a time and
+// trade shots with whatever is in reach, and an enemy base in the far
+// corner sends ever larger raids. Game states go out every -interval ticks
+// and pings are answered, so the dashboard and heartbeat behave as they do
+// against the real game.
+//
+//	cd vimy-core && go run . &
+//	go run ./cmd/vimy-mock-sidecar -faction england -speed 4
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// defaultSocket is brain.DefaultSocketPath, repeated so the mock doesn't
+// link the strategist's LLM client.
+const defaultSocket = "/tmp/vimy.sock"
+
+// gameTicksPerSecond is OpenRA's "normal" game speed.
+const gameTicksPerSecond = 25
+
+// terrainGridSize matches the mod's coarse terrain grid.
+const terrainGridSize = 32
+
+func main() {
+	socket := flag.String("socket", defaultSocket, "vimy-core's Unix socket")
+	player := flag.String("player", "Vimy", "player name sent in the hello")
+	faction := flag.String("faction", "soviet", "faction to play (soviet, russia, ukraine, england, france, germany)")
+	mapSize := flag.Int("map", 96, "map width and height in cells")
+	cash := flag.Int("cash", 5000, "starting credits")
+	interval := flag.Int("interval", 10, "ticks between game states, as the mod's StateIntervalTicks")
+	speed := flag.Float64("speed", 1, "game speed as a multiple of normal (0: as fast as vimy-core keeps up)")
+	maxTicks := flag.Int("ticks", 0, "stop after this many ticks (0: play until one side has lost)")
+	raidStart := flag.Int("raid-start", 3000, "tick of the first enemy raid (0: no raids)")
+	raidEvery := flag.Int("raid-every", 1500, "ticks between enemy raids")
+	seed := flag.Uint64("seed", 1, "seed for the enemy's raid composition")
+	wait := flag.Duration("wait", 30*time.Second, "how long to keep retrying the socket before giving up")
+	flag.Parse()
+
+	if err := run(*socket, *wait, *player, *faction, *interval, *speed, *maxTicks, worldConfig{
+		faction:   *faction,
+		mapSize:   *mapSize,
+		cash:      *cash,
+		raidStart: *raidStart,
+		raidEvery: *raidEvery,
+		seed:      *seed,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "vimy-mock-sidecar:", err)
+		os.Exit(1)
+	}
+}
+
+func run(socket string, wait time.Duration, player, faction string, interval int, speed float64, maxTicks int, cfg worldConfig) error {
+	conn, err := dial(socket, wait)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	w := newWorld(cfg)
+	if err := send(conn, ipc.TypeHello, hello(player, faction, cfg.mapSize)); err != nil {
+		return fmt.Errorf("send hello: %w", err)
+	}
+	slog.Info("connected to vimy-core", "socket", socket, "player", player, "faction", faction)
+
+	// Reads run on their own goroutine; the game loop drains them between
+	// ticks, as the mod does in BotTick.
+	inbox := make(chan ipc.Envelope, 256)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			env, err := ipc.ReadEnvelope(conn)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			inbox <- env
+		}
+	}()
+
+	var tick <-chan time.Time
+	if speed > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / (gameTicksPerSecond * speed)))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for maxTicks <= 0 || w.tick < maxTicks {
+		if tick != nil {
+			<-tick
+		}
+		for drained := false; !drained; {
+			select {
+			case env := <-inbox:
+				if err := handle(conn, w, env); err != nil {
+					return err
+				}
+			case err := <-readErr:
+				return fmt.Errorf("vimy-core closed the connection: %w", err)
+			default:
+				drained = true
+			}
+		}
+
+		w.step()
+		if done, won := w.over(); done {
+			slog.Info("game over", "won", won, "tick", w.tick)
+			return nil
+		}
+		if w.tick%max(interval, 1) != 0 {
+			continue
+		}
+		if err := send(conn, ipc.TypeGameState, w.state(player)); err != nil {
+			return fmt.Errorf("send game state: %w", err)
+		}
+		if tick == nil {
+			// Flat out, give vimy-core a moment to answer before the
+			// next state supersedes this one.
+			if err := awaitAck(conn, w, inbox, readErr); err != nil {
+				return err
+			}
+		}
+	}
+	slog.Info("tick limit reached", "tick", w.tick)
+	return nil
+}
+
+// handle acts on one message from vimy-core.
+func handle(conn net.Conn, w *world, env ipc.Envelope) error {
+	switch env.Type {
+	case ipc.TypeAck:
+		return nil
+	case ipc.TypePing:
+		var ping ipc.PingMessage
+		if err := json.Unmarshal(env.Data, &ping); err != nil {
+			return fmt.Errorf("decode ping: %w", err)
+		}
+		return send(conn, ipc.TypePong, ipc.PongMessage{Seq: ping.Seq, Tick: w.tick})
+	}
+	if err := w.apply(env); err != nil {
+		slog.Warn("command refused", "type", env.Type, "data", string(env.Data), "error", err)
+	} else {
+		slog.Debug("command applied", "type", env.Type, "data", string(env.Data))
+	}
+	return nil
+}
+
+// awaitAck handles messages until vimy-core acknowledges a game state, or
+// a second passes.
+func awaitAck(conn net.Conn, w *world, inbox <-chan ipc.Envelope, readErr <-chan error) error {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case env := <-inbox:
+			if err := handle(conn, w, env); err != nil {
+				return err
+			}
+			if env.Type == ipc.TypeAck {
+				return nil
+			}
+		case err := <-readErr:
+			return fmt.Errorf("vimy-core closed the connection: %w", err)
+		case <-timeout:
+			return nil
+		}
+	}
+}
+
+// dial connects to the socket, retrying until wait runs out so the mock
+// can be started before vimy-core.
+func dial(socket string, wait time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("connect to %s: %w", socket, err)
+		}
+		slog.Info("waiting for vimy-core", "socket", socket, "error", err)
+		time.Sleep(time.Second)
+	}
+}
+
+// hello is the handshake the mod sends: an all-land terrain grid and a
+// fresh session key.
+func hello(player, faction string, mapSize int) ipc.HelloMessage {
+	cell := (mapSize + terrainGridSize - 1) / terrainGridSize
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	return ipc.HelloMessage{
+		Player:  player,
+		Faction: faction,
+		Mod:     "ra",
+		Session: hex.EncodeToString(key),
+		Terrain: &ipc.TerrainData{
+			Cols:  terrainGridSize,
+			Rows:  terrainGridSize,
+			CellW: cell,
+			CellH: cell,
+			Grid:  make([]int, terrainGridSize*terrainGridSize),
+		},
+	}
+}
+
+func send(conn net.Conn, msgType string, data any) error {
+	env, err := ipc.NewEnvelope(msgType, data)
+	if err != nil {
+		return err
+	}
+	if err := ipc.WriteEnvelope(conn, env); err != nil {
+		if errors.Is(err, net.ErrClosed) {
+			return fmt.Errorf("vimy-core closed the connection")
+		}
+		return err
+	}
+	return nil
+}
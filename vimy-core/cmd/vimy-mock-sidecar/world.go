@@ -0,0 +1,745 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// World simulation tuning.
+const (
+	harvestTicks  = 25 // a working harvester delivers once this often
+	harvestAmount = 35 // credits per delivery
+	fireTicks     = 15 // ticks between shots
+	sightRange    = 10 // cells within which our actors reveal enemies
+	repairPerTick = 2  // HP a building under repair regains each tick
+	placeSpacing  = 3  // cells between placed buildings
+	placeRadius   = 24 // furthest a building is placed from its hint
+	raidGrowth    = 2  // units added to each successive raid
+	raidFirstSize = 3  // units in the first raid
+	enemyOwner    = "Enemy AI"
+	enemyGarrison = 4 // rifle infantry guarding the enemy base
+)
+
+// actor is a unit or building of either side.
+type actor struct {
+	id       int
+	typ      string
+	enemy    bool
+	x, y     int
+	hp       int
+	building bool
+
+	// Orders. A unit with a destination walks to it; attack-moving it stops
+	// for anything hostile in reach. A target is chased until in reach.
+	hasDest    bool
+	destX      int
+	destY      int
+	attackMove bool
+	target     int
+	repairing  bool
+
+	moveWait int // ticks until the next cell
+	fireWait int // ticks until the next shot
+}
+
+func (a *actor) info() actorInfo { return catalog[a.typ] }
+
+func (a *actor) idle() bool { return !a.hasDest && a.target == 0 }
+
+func (a *actor) stop() { a.hasDest, a.attackMove, a.target = false, false, 0 }
+
+// queue is one production queue: items waiting, the first in production.
+type queue struct {
+	typ      string
+	items    []string
+	progress int  // ticks spent on the first item
+	paid     bool // whether the first item has been paid for
+}
+
+// current returns the item in production, or "".
+func (q *queue) current() string {
+	if len(q.items) == 0 {
+		return ""
+	}
+	return q.items[0]
+}
+
+// next drops the first item and starts the one after it.
+func (q *queue) next() {
+	q.items = q.items[1:]
+	q.progress, q.paid = 0, false
+}
+
+// world is a crude RA skirmish: our side, played by vimy-core over the
+// socket, against an enemy base that sends ever larger raids.
+type world struct {
+	tick    int
+	side    string
+	width   int
+	height  int
+	cash    int
+	nextID  int
+	actors  []*actor
+	queues  map[string]*queue
+	rally   map[int][2]int // producing building → rally point
+	enemyX  int
+	enemyY  int
+	ownX    int
+	ownY    int
+	raids   int
+	raidAt  int // tick of the next raid; 0 disables raids
+	raidGap int
+	rng     *rand.Rand
+}
+
+type worldConfig struct {
+	faction   string
+	mapSize   int
+	cash      int
+	raidStart int // tick of the first raid; 0 disables raids
+	raidEvery int
+	seed      uint64
+}
+
+// newWorld sets up the start of a game: our MCV in one corner and the
+// enemy's base, with a small garrison, in the other.
+func newWorld(cfg worldConfig) *world {
+	side := rules.FactionSide(cfg.faction)
+	if side == "" {
+		side = rules.SideSoviet
+	}
+	w := &world{
+		tick:    0,
+		side:    side,
+		width:   cfg.mapSize,
+		height:  cfg.mapSize,
+		cash:    cfg.cash,
+		nextID:  1,
+		queues:  make(map[string]*queue),
+		rally:   make(map[int][2]int),
+		ownX:    cfg.mapSize / 6,
+		ownY:    cfg.mapSize / 6,
+		enemyX:  cfg.mapSize - cfg.mapSize/6,
+		enemyY:  cfg.mapSize - cfg.mapSize/6,
+		raidAt:  cfg.raidStart,
+		raidGap: cfg.raidEvery,
+		rng:     rand.New(rand.NewPCG(cfg.seed, cfg.seed)),
+	}
+	w.spawn(rules.MCV, false, w.ownX, w.ownY)
+	for _, b := range []struct {
+		typ    string
+		dx, dy int
+	}{{rules.ConstructionYard, 0, 0}, {rules.PowerPlant, -3, 0}, {rules.Refinery, 3, 0}, {rules.AlliedBarracks, 0, 3}} {
+		w.spawn(b.typ, true, w.enemyX+b.dx, w.enemyY+b.dy)
+	}
+	for i := range enemyGarrison {
+		w.spawn(rules.RifleInfantry, true, w.enemyX-4+i, w.enemyY-4)
+	}
+	return w
+}
+
+// spawn adds an actor at full health.
+func (w *world) spawn(typ string, enemy bool, x, y int) *actor {
+	info := catalog[typ]
+	a := &actor{id: w.nextID, typ: typ, enemy: enemy, x: x, y: y, hp: max(info.hp, 1), building: info.isBuilding()}
+	w.nextID++
+	w.actors = append(w.actors, a)
+	return a
+}
+
+func (w *world) actor(id int) *actor {
+	for _, a := range w.actors {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// own returns our actor with the given ID, or nil.
+func (w *world) own(id uint32) *actor {
+	if a := w.actor(int(id)); a != nil && !a.enemy {
+		return a
+	}
+	return nil
+}
+
+// ownTypes is the set of building types we have standing.
+func (w *world) ownTypes() map[string]bool {
+	have := make(map[string]bool)
+	for _, a := range w.actors {
+		if !a.enemy && a.building {
+			have[a.typ] = true
+		}
+	}
+	return have
+}
+
+// producer returns our first building that gives queue q, or nil.
+func (w *world) producer(q string) *actor {
+	for _, a := range w.actors {
+		if !a.enemy && a.building && slices.Contains(producers[q], a.typ) {
+			return a
+		}
+	}
+	return nil
+}
+
+// buildable lists what queue q can build now, cheapest first.
+func (w *world) buildable(q string) []string {
+	if w.producer(q) == nil {
+		return nil
+	}
+	have := w.ownTypes()
+	var out []string
+	for typ, info := range catalog {
+		if info.queue == q && (info.side == "" || info.side == w.side) && info.prereqsMet(have) {
+			out = append(out, typ)
+		}
+	}
+	slices.SortFunc(out, func(a, b string) int {
+		if d := catalog[a].cost - catalog[b].cost; d != 0 {
+			return d
+		}
+		if a < b {
+			return -1
+		}
+		return 1
+	})
+	return out
+}
+
+// power returns our power provided and drained.
+func (w *world) power() (provided, drained int) {
+	for _, a := range w.actors {
+		if a.enemy || !a.building {
+			continue
+		}
+		if p := a.info().power; p > 0 {
+			provided += p
+		} else {
+			drained -= p
+		}
+	}
+	return provided, drained
+}
+
+// apply carries out one command from vimy-core. Commands for actors that
+// are gone or items that can't be built are refused with an error, which
+// the caller logs; the game carries on as OpenRA's would.
+func (w *world) apply(env ipc.Envelope) error {
+	switch env.Type {
+	case ipc.TypeProduce:
+		var c ipc.ProduceCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		if !slices.Contains(w.buildable(c.Queue), c.Item) {
+			return fmt.Errorf("%s queue cannot build %q", c.Queue, c.Item)
+		}
+		q := w.queues[c.Queue]
+		if q == nil {
+			q = &queue{typ: c.Queue}
+			w.queues[c.Queue] = q
+		}
+		for range max(c.Count, 1) {
+			q.items = append(q.items, c.Item)
+		}
+	case ipc.TypeCancelProduction:
+		var c ipc.CancelProductionCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		q := w.queues[c.Queue]
+		if q == nil || len(q.items) == 0 {
+			return fmt.Errorf("%s queue is empty", c.Queue)
+		}
+		for n := max(c.Count, 1); n > 0; n-- {
+			i := slices.Index(q.items, c.Item)
+			if c.Item == "" {
+				i = len(q.items) - 1
+			}
+			if i < 0 {
+				break
+			}
+			if i == 0 {
+				if q.paid {
+					w.cash += catalog[q.items[0]].cost
+				}
+				q.next()
+				continue
+			}
+			q.items = slices.Delete(q.items, i, i+1)
+		}
+	case ipc.TypePlaceBuilding:
+		var c ipc.PlaceBuildingCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		return w.place(c)
+	case ipc.TypeDeploy:
+		var c ipc.DeployCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a := w.own(c.ActorID)
+		if a == nil || a.typ != rules.MCV {
+			return fmt.Errorf("no MCV %d to deploy", c.ActorID)
+		}
+		w.remove(a)
+		w.spawn(rules.ConstructionYard, false, a.x, a.y)
+	case ipc.TypeUndeploy:
+		var c ipc.UndeployCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a := w.own(c.ActorID)
+		if a == nil || a.typ != rules.ConstructionYard {
+			return fmt.Errorf("no construction yard %d to undeploy", c.ActorID)
+		}
+		w.remove(a)
+		w.spawn(rules.MCV, false, a.x, a.y)
+	case ipc.TypeMove, ipc.TypeHarvest, ipc.TypeSetRally:
+		var c struct {
+			ActorID uint32 `json:"actor_id"`
+			X       int    `json:"x"`
+			Y       int    `json:"y"`
+		}
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a := w.own(c.ActorID)
+		if a == nil {
+			return fmt.Errorf("no actor %d", c.ActorID)
+		}
+		if env.Type == ipc.TypeSetRally {
+			w.rally[a.id] = [2]int{c.X, c.Y}
+			return nil
+		}
+		w.order(a, c.X, c.Y, false)
+	case ipc.TypeAttackMove, ipc.TypeForceAttackGround:
+		var c ipc.AttackMoveCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		for _, id := range c.ActorIDs {
+			if a := w.own(id); a != nil {
+				w.order(a, c.X, c.Y, true)
+			}
+		}
+	case ipc.TypeAttack:
+		var c ipc.AttackCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a, t := w.own(c.ActorID), w.actor(int(c.TargetID))
+		if a == nil || t == nil || !t.enemy {
+			return fmt.Errorf("attack %d → %d: no such attacker or target", c.ActorID, c.TargetID)
+		}
+		a.stop()
+		a.target = t.id
+	case ipc.TypeGuard:
+		var c ipc.GuardCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		t := w.own(c.TargetID)
+		if t == nil {
+			return fmt.Errorf("no actor %d to guard", c.TargetID)
+		}
+		for _, id := range c.ActorIDs {
+			if a := w.own(id); a != nil {
+				w.order(a, t.x, t.y, true)
+			}
+		}
+	case ipc.TypeRepairBuilding:
+		var c ipc.RepairBuildingCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a := w.own(c.ActorID)
+		if a == nil || !a.building {
+			return fmt.Errorf("no building %d to repair", c.ActorID)
+		}
+		a.repairing = true
+	case ipc.TypeRepairUnit:
+		var c ipc.RepairUnitCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a, depot := w.own(c.ActorID), w.own(c.RepairBuildingID)
+		if a == nil || depot == nil {
+			return fmt.Errorf("repair unit %d at %d: no such unit or depot", c.ActorID, c.RepairBuildingID)
+		}
+		w.order(a, depot.x, depot.y, false)
+		a.repairing = true
+	case ipc.TypeSellBuilding:
+		var c ipc.SellBuildingCommand
+		if err := json.Unmarshal(env.Data, &c); err != nil {
+			return err
+		}
+		a := w.own(c.ActorID)
+		if a == nil || !a.building {
+			return fmt.Errorf("no building %d to sell", c.ActorID)
+		}
+		w.cash += a.info().cost / 2
+		w.remove(a)
+	default:
+		// Capture, transports, minefields and support powers need more of
+		// a game than the mock has.
+		slog.Debug("command not simulated", "type", env.Type)
+	}
+	return nil
+}
+
+// order sends a unit towards (x, y). Buildings ignore it.
+func (w *world) order(a *actor, x, y int, attackMove bool) {
+	if a.building {
+		return
+	}
+	a.stop()
+	a.hasDest, a.destX, a.destY, a.attackMove = true, w.clampX(x), w.clampY(y), attackMove
+}
+
+// place puts a finished building down at the free spot nearest the hint,
+// or our construction yard.
+func (w *world) place(c ipc.PlaceBuildingCommand) error {
+	q := w.queues[c.Queue]
+	if q == nil || q.current() == "" || q.progress < catalog[q.current()].buildTicks() {
+		return fmt.Errorf("nothing ready to place on the %s queue", c.Queue)
+	}
+	if c.Item != "" && c.Item != q.current() {
+		return fmt.Errorf("%s queue has %q ready, not %q", c.Queue, q.current(), c.Item)
+	}
+	cx, cy := c.HintX, c.HintY
+	if cx == 0 && cy == 0 {
+		yard := w.producer(rules.QueueBuilding)
+		if yard == nil {
+			return fmt.Errorf("no construction yard to build %q beside", q.current())
+		}
+		cx, cy = yard.x, yard.y
+	}
+	x, y, ok := w.freeSpot(cx, cy)
+	if !ok {
+		return fmt.Errorf("no room for %q near (%d, %d)", q.current(), cx, cy)
+	}
+	typ := q.current()
+	q.next()
+	w.spawn(typ, false, x, y)
+	if typ == rules.Refinery {
+		w.spawn(rules.Harvester, false, x+1, y+2)
+	}
+	return nil
+}
+
+// freeSpot finds the on-map cell nearest (cx, cy), on the placement grid,
+// with no building within placeSpacing.
+func (w *world) freeSpot(cx, cy int) (int, int, bool) {
+	for r := 0; r <= placeRadius; r += placeSpacing {
+		for dy := -r; dy <= r; dy += placeSpacing {
+			for dx := -r; dx <= r; dx += placeSpacing {
+				if max(abs(dx), abs(dy)) != r {
+					continue // only the ring at this radius
+				}
+				x, y := cx+dx, cy+dy
+				if x < 1 || y < 1 || x >= w.width-1 || y >= w.height-1 {
+					continue
+				}
+				if !w.occupied(x, y) {
+					return x, y, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (w *world) occupied(x, y int) bool {
+	for _, a := range w.actors {
+		if a.building && abs(a.x-x) < placeSpacing && abs(a.y-y) < placeSpacing {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *world) remove(a *actor) {
+	w.actors = slices.DeleteFunc(w.actors, func(b *actor) bool { return b == a })
+	delete(w.rally, a.id)
+}
+
+// step advances the game one tick.
+func (w *world) step() {
+	w.tick++
+	w.produce()
+	w.harvest()
+	w.raid()
+	for _, a := range w.actors {
+		w.act(a)
+	}
+	w.actors = slices.DeleteFunc(w.actors, func(a *actor) bool {
+		if a.hp <= 0 {
+			slog.Debug("actor destroyed", "id", a.id, "type", a.typ, "enemy", a.enemy, "tick", w.tick)
+			delete(w.rally, a.id)
+			return true
+		}
+		return false
+	})
+}
+
+// produce advances every queue. Items are paid for when they start; with
+// low power production runs at half speed. Finished units roll out at
+// their producer and head for its rally point; finished buildings wait
+// for a place_building.
+func (w *world) produce() {
+	provided, drained := w.power()
+	for _, typ := range queueOrder {
+		q := w.queues[typ]
+		if q == nil || q.current() == "" {
+			continue
+		}
+		prod := w.producer(typ)
+		if prod == nil {
+			continue // producer lost; the queue waits for another
+		}
+		info := catalog[q.current()]
+		if !q.paid {
+			if w.cash < info.cost {
+				continue
+			}
+			w.cash -= info.cost
+			q.paid = true
+		}
+		if q.progress < info.buildTicks() && (drained <= provided || w.tick%2 == 0) {
+			q.progress++
+		}
+		if q.progress < info.buildTicks() || info.isBuilding() {
+			continue
+		}
+		u := w.spawn(q.current(), false, prod.x+1, prod.y+2)
+		if p, ok := w.rally[prod.id]; ok {
+			w.order(u, p[0], p[1], false)
+		}
+		q.next()
+	}
+}
+
+// harvest pays for each harvester while we have a refinery.
+func (w *world) harvest() {
+	if w.tick%harvestTicks != 0 || !w.ownTypes()[rules.Refinery] {
+		return
+	}
+	for _, a := range w.actors {
+		if !a.enemy && a.typ == rules.Harvester && a.idle() {
+			w.cash += harvestAmount
+		}
+	}
+}
+
+// raid sends the next enemy raid from its base at our buildings.
+func (w *world) raid() {
+	if w.raidAt <= 0 || w.tick < w.raidAt {
+		return
+	}
+	w.raidAt += max(w.raidGap, 1)
+	if !slices.ContainsFunc(w.actors, func(a *actor) bool { return a.enemy && a.building }) {
+		return // nothing left to raid from
+	}
+	size := raidFirstSize + w.raids*raidGrowth
+	w.raids++
+	tx, ty := w.ownX, w.ownY
+	if b := w.producer(rules.QueueBuilding); b != nil {
+		tx, ty = b.x, b.y
+	}
+	types := []string{rules.RifleInfantry, rules.RifleInfantry, rules.RocketSoldier, rules.LightTank}
+	for i := range size {
+		u := w.spawn(types[w.rng.IntN(len(types))], true, w.enemyX-3+i%5, w.enemyY-6-i/5)
+		w.order(u, tx, ty, true)
+	}
+	slog.Info("enemy raid launched", "raid", w.raids, "size", size, "tick", w.tick)
+}
+
+// act runs one actor's tick: repair, fire, move.
+func (w *world) act(a *actor) {
+	info := a.info()
+	if a.repairing {
+		if a.hp >= info.hp || w.cash <= 0 {
+			a.repairing = false
+		} else if !a.hasDest {
+			a.hp = min(a.hp+repairPerTick, info.hp)
+			w.cash--
+		}
+	}
+
+	if a.fireWait > 0 {
+		a.fireWait--
+	}
+	if info.damage > 0 {
+		if t := w.targetFor(a); t != nil {
+			if a.fireWait == 0 {
+				t.hp -= info.damage
+				a.fireWait = fireTicks
+			}
+			if a.target != 0 || a.attackMove || !a.hasDest {
+				return // hold position while engaging
+			}
+		}
+	}
+
+	if a.building {
+		return
+	}
+	dx, dy, moving := a.destX, a.destY, a.hasDest
+	if a.target != 0 {
+		t := w.actor(a.target)
+		if t == nil || t.hp <= 0 {
+			a.target = 0
+			return
+		}
+		dx, dy, moving = t.x, t.y, true
+	}
+	if !moving {
+		return
+	}
+	if a.moveWait > 0 {
+		a.moveWait--
+		return
+	}
+	a.x += sign(dx - a.x)
+	a.y += sign(dy - a.y)
+	a.moveWait = info.speed - 1
+	if a.hasDest && a.x == a.destX && a.y == a.destY {
+		a.stop()
+	}
+}
+
+// targetFor picks what a shoots at this tick: its ordered target if in
+// reach, else the nearest hostile in reach.
+func (w *world) targetFor(a *actor) *actor {
+	reach := float64(a.info().reach)
+	if a.target != 0 {
+		if t := w.actor(a.target); t != nil && t.hp > 0 && dist(a, t) <= reach {
+			return t
+		}
+		return nil
+	}
+	var best *actor
+	for _, t := range w.actors {
+		if t.enemy == a.enemy || t.hp <= 0 || dist(a, t) > reach {
+			continue
+		}
+		if best == nil || dist(a, t) < dist(a, best) {
+			best = t
+		}
+	}
+	return best
+}
+
+// over reports whether the game has been decided, and who won.
+func (w *world) over() (done, won bool) {
+	var ours, theirs bool
+	for _, a := range w.actors {
+		if a.enemy && a.building {
+			theirs = true
+		}
+		if !a.enemy && (a.building || a.typ == rules.MCV) {
+			ours = true
+		}
+	}
+	return !ours || !theirs, ours && !theirs
+}
+
+// state is the game as the mod would report it to vimy-core.
+func (w *world) state(player string) model.GameState {
+	provided, drained := w.power()
+	gs := model.GameState{
+		Tick:      w.tick,
+		MapWidth:  w.width,
+		MapHeight: w.height,
+		Player: model.Player{
+			Name:             player,
+			Cash:             w.cash,
+			ResourceCapacity: 2000,
+			PowerProvided:    provided,
+			PowerDrained:     drained,
+			PowerState:       powerState(provided, drained),
+		},
+		SpawnPoints: []model.SpawnPoint{{X: w.ownX, Y: w.ownY, Own: true}, {X: w.enemyX, Y: w.enemyY}},
+	}
+	for _, a := range w.actors {
+		maxHP := max(a.info().hp, 1)
+		switch {
+		case a.enemy:
+			if w.visible(a) {
+				gs.Enemies = append(gs.Enemies, model.Enemy{ID: a.id, Owner: enemyOwner, Type: a.typ, X: a.x, Y: a.y, HP: a.hp, MaxHP: maxHP})
+			}
+		case a.building:
+			gs.Buildings = append(gs.Buildings, model.Building{ID: a.id, Type: a.typ, X: a.x, Y: a.y, HP: a.hp, MaxHP: maxHP})
+		default:
+			// Harvesters are always busy harvesting unless ordered away.
+			idle := a.idle() && a.typ != rules.Harvester
+			gs.Units = append(gs.Units, model.Unit{ID: a.id, Type: a.typ, X: a.x, Y: a.y, HP: a.hp, MaxHP: maxHP, Idle: idle})
+		}
+	}
+	for _, typ := range queueOrder {
+		prod := w.producer(typ)
+		if prod == nil {
+			continue
+		}
+		buildable := w.buildable(typ)
+		pq := model.ProductionQueue{ActorID: prod.id, Type: typ, Items: buildable, Buildable: buildable}
+		if q := w.queues[typ]; q != nil && q.current() != "" {
+			pq.CurrentItem = q.current()
+			pq.CurrentProgress = min(100, q.progress*100/catalog[q.current()].buildTicks())
+		}
+		gs.ProductionQueues = append(gs.ProductionQueues, pq)
+	}
+	return gs
+}
+
+// visible reports whether an enemy actor is within sight of one of ours.
+func (w *world) visible(e *actor) bool {
+	for _, a := range w.actors {
+		if !a.enemy && dist(a, e) <= sightRange {
+			return true
+		}
+	}
+	return false
+}
+
+// powerState derives the power state as the game does.
+func powerState(provided, drained int) string {
+	switch {
+	case drained <= provided:
+		return "Normal"
+	case drained < provided*2:
+		return "Low"
+	default:
+		return "Critical"
+	}
+}
+
+func (w *world) clampX(x int) int { return min(max(x, 0), w.width-1) }
+func (w *world) clampY(y int) int { return min(max(y, 0), w.height-1) }
+
+func dist(a, b *actor) float64 { return math.Hypot(float64(a.x-b.x), float64(a.y-b.y)) }
+
+func abs(v int) int { return max(v, -v) }
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func testWorld(faction string) *world {
+	return newWorld(worldConfig{faction: faction, mapSize: 64, cash: 5000, seed: 1})
+}
+
+func command(t *testing.T, msgType string, data any) ipc.Envelope {
+	t.Helper()
+	env, err := ipc.NewEnvelope(msgType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func findType[T interface{ TypeName() string }](items []T, typ string) (T, bool) {
+	for _, it := range items {
+		if it.TypeName() == typ {
+			return it, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func TestProductionTakesTimeAndCredits(t *testing.T) {
+	w := testWorld("soviet")
+	mcv, _ := findType(w.state("p").Units, rules.MCV)
+	if err := w.apply(command(t, ipc.TypeDeploy, ipc.DeployCommand{ActorID: uint32(mcv.ID)})); err != nil {
+		t.Fatal(err)
+	}
+	gs := w.state("p")
+	if _, ok := findType(gs.Buildings, rules.ConstructionYard); !ok {
+		t.Fatal("MCV didn't deploy into a construction yard")
+	}
+	if err := w.apply(command(t, ipc.TypeProduce, ipc.ProduceCommand{Queue: rules.QueueBuilding, Item: rules.SovietBarracks})); err == nil {
+		t.Error("barracks queued before any power plant")
+	}
+	if err := w.apply(command(t, ipc.TypeProduce, ipc.ProduceCommand{Queue: rules.QueueBuilding, Item: rules.PowerPlant})); err != nil {
+		t.Fatal(err)
+	}
+
+	build := catalog[rules.PowerPlant].buildTicks()
+	for range build / 2 {
+		w.step()
+	}
+	pq := w.state("p").ProductionQueues[0]
+	if pq.CurrentItem != rules.PowerPlant || pq.CurrentProgress <= 0 || pq.CurrentProgress >= 100 {
+		t.Errorf("halfway queue = %+v, want the power plant in progress", pq)
+	}
+	if w.cash != 5000-catalog[rules.PowerPlant].cost {
+		t.Errorf("cash = %d, want the power plant paid for", w.cash)
+	}
+	place := command(t, ipc.TypePlaceBuilding, ipc.PlaceBuildingCommand{Queue: rules.QueueBuilding, Item: rules.PowerPlant})
+	if err := w.apply(place); err == nil {
+		t.Error("placed a power plant still in production")
+	}
+	for range build {
+		w.step()
+	}
+	if pq := w.state("p").ProductionQueues[0]; pq.CurrentProgress != 100 {
+		t.Fatalf("finished queue = %+v, want it ready at 100", pq)
+	}
+	if err := w.apply(place); err != nil {
+		t.Fatal(err)
+	}
+	gs = w.state("p")
+	if _, ok := findType(gs.Buildings, rules.PowerPlant); !ok {
+		t.Error("power plant not placed")
+	}
+	if gs.Player.PowerProvided != 100 {
+		t.Errorf("power provided = %d, want 100", gs.Player.PowerProvided)
+	}
+	if !slices.Contains(gs.ProductionQueues[0].Buildable, rules.SovietBarracks) || slices.Contains(gs.ProductionQueues[0].Buildable, rules.AlliedBarracks) {
+		t.Errorf("buildable after power = %v, want Soviet barracks only", gs.ProductionQueues[0].Buildable)
+	}
+}
+
+func TestUnitsMoveAndFight(t *testing.T) {
+	w := testWorld("soviet")
+	tank := w.spawn(rules.HeavyTank, false, 10, 10)
+	if err := w.apply(command(t, ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(tank.id), X: 14, Y: 10})); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 * catalog[rules.HeavyTank].speed {
+		w.step()
+	}
+	if tank.x != 14 || !tank.idle() {
+		t.Errorf("tank at (%d, %d) idle=%v, want arrived at (14, 10)", tank.x, tank.y, tank.idle())
+	}
+
+	raider := w.spawn(rules.RifleInfantry, true, 17, 10)
+	if _, ok := findType(w.state("p").Enemies, rules.RifleInfantry); !ok {
+		t.Error("enemy beside our tank not visible")
+	}
+	for range 3 * fireTicks {
+		w.step()
+	}
+	if raider.hp > 0 {
+		t.Errorf("rifleman in reach of a heavy tank still has %d HP", raider.hp)
+	}
+	if w.actor(raider.id) != nil {
+		t.Error("destroyed rifleman still in the world")
+	}
+}
+
+func TestRaidsTargetOurBase(t *testing.T) {
+	w := newWorld(worldConfig{faction: "england", mapSize: 64, cash: 5000, raidStart: 5, raidEvery: 10, seed: 1})
+	mcv, _ := findType(w.state("p").Units, rules.MCV)
+	if err := w.apply(command(t, ipc.TypeDeploy, ipc.DeployCommand{ActorID: uint32(mcv.ID)})); err != nil {
+		t.Fatal(err)
+	}
+	for range 15 {
+		w.step()
+	}
+	if w.raids != 2 {
+		t.Fatalf("raids by tick 15 = %d, want 2", w.raids)
+	}
+	raiders := 0
+	for _, a := range w.actors {
+		if a.enemy && a.attackMove {
+			raiders++
+			if a.destX != w.ownX || a.destY != w.ownY {
+				t.Errorf("raider heading for (%d, %d), want our yard at (%d, %d)", a.destX, a.destY, w.ownX, w.ownY)
+			}
+		}
+	}
+	if raiders != raidFirstSize+raidFirstSize+raidGrowth {
+		t.Errorf("%d raiders out, want %d", raiders, raidFirstSize+raidFirstSize+raidGrowth)
+	}
+}
+
+// TestRulesPlayTheMock plays vimy-core's default rules against the mock
+// over an in-memory connection, the way the agent would over the socket.
+func TestRulesPlayTheMock(t *testing.T) {
+	engine, err := rules.NewEngine(rules.DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetFaction("soviet")
+	w := testWorld("soviet")
+
+	server, client := net.Pipe()
+	defer server.Close()
+	conn := ipc.NewConnection(server, nil)
+	envs := make(chan ipc.Envelope)
+	go func() {
+		defer client.Close()
+		for {
+			env, err := ipc.ReadEnvelope(client)
+			if err != nil {
+				close(envs)
+				return
+			}
+			envs <- env
+		}
+	}()
+
+	const barrier = "mock_barrier"
+	for w.tick < 3000 {
+		w.step()
+		if w.tick%10 != 0 {
+			continue
+		}
+		gs := w.state("p")
+		go func() {
+			if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+				t.Error(err)
+			}
+			conn.Send(barrier, struct{}{})
+		}()
+		for env := range envs {
+			if env.Type == barrier {
+				break
+			}
+			if err := w.apply(env); err != nil {
+				t.Logf("tick %d: %s refused: %v", w.tick, env.Type, err)
+			}
+		}
+	}
+
+	gs := w.state("p")
+	for _, typ := range []string{rules.ConstructionYard, rules.PowerPlant, rules.Refinery, rules.SovietBarracks} {
+		if _, ok := findType(gs.Buildings, typ); !ok {
+			t.Errorf("no %s after %d ticks; buildings %v", typ, w.tick, typeNames(gs.Buildings))
+		}
+	}
+	if _, ok := findType(gs.Units, rules.Harvester); !ok {
+		t.Error("refinery came without a harvester")
+	}
+}
+
+func typeNames(bs []model.Building) []string {
+	var out []string
+	for _, b := range bs {
+		out = append(out, b.Type)
+	}
+	return out
+}
+
+func TestHelloCarriesLandTerrain(t *testing.T) {
+	h := hello("p", "soviet", 96)
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back ipc.HelloMessage
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Session == "" || back.Terrain == nil || back.Terrain.CellW*terrainGridSize < 96 {
+		t.Errorf("hello = %+v, want a session and a terrain grid covering the map", back)
+	}
+}
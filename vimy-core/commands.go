@@ -0,0 +1,298 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nstehr/vimy/vimy-core/rules"
+	"github.com/nstehr/vimy/vimy-core/sim"
+)
+
+// quietLogs keeps the offline tools' output to their report; the engine
+// logs every squad and rule swap at info level.
+func quietLogs() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+}
+
+// loadDoctrine reads a doctrine JSON or YAML file (see rules.LoadDoctrine),
+// or returns the default doctrine when path is empty.
+func loadDoctrine(path string) (rules.Doctrine, error) {
+	if path == "" {
+		return rules.DefaultDoctrine(), nil
+	}
+	return rules.LoadDoctrine(path)
+}
+
+// doctrineRules compiles the doctrine at path, with opening (if set)
+// replacing the doctrine's own opening book.
+func doctrineRules(path, opening string) ([]*rules.Rule, error) {
+	d, err := loadDoctrine(path)
+	if err != nil {
+		return nil, err
+	}
+	if opening != "" {
+		if _, ok := rules.LookupOpening(opening); !ok {
+			return nil, fmt.Errorf("unknown opening book %q (available: %v)", opening, rules.OpeningNames())
+		}
+		d.Opening = opening
+	}
+	return rules.CompileDoctrine(d), nil
+}
+
+// runCompile prints the rule set a doctrine compiles to, highest priority
+// first, so weight changes can be inspected without running a game.
+func runCompile(args []string) error {
+	fs := flag.NewFlagSet("compile", flag.ExitOnError)
+	doctrinePath := fs.String("doctrine", "", "doctrine JSON or YAML file (default: the built-in balanced doctrine)")
+	opening := fs.String("opening", "", "opening book to compile in")
+	overridesPath := fs.String("overrides", "", "JSON file of per-rule overrides to apply")
+	conditions := fs.Bool("conditions", true, "print each rule's condition")
+	against := fs.String("diff", "", "instead of listing rules, print how they differ from this doctrine file's rules")
+	fs.Parse(args)
+	quietLogs()
+
+	rs, err := doctrineRules(*doctrinePath, *opening)
+	if err != nil {
+		return err
+	}
+	if *against != "" {
+		base, err := doctrineRules(*against, "")
+		if err != nil {
+			return err
+		}
+		d := rules.Diff(base, rs)
+		if d.Empty() {
+			fmt.Println("no rule changes")
+			return nil
+		}
+		fmt.Print(d)
+		return nil
+	}
+	engine, err := rules.NewEngine(rs)
+	if err != nil {
+		return err
+	}
+	if *overridesPath != "" {
+		overrides, err := rules.LoadOverrides(*overridesPath)
+		if err != nil {
+			return err
+		}
+		if err := engine.SetOverrides(overrides); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PRIORITY\tRULE\tCATEGORY\tEXCLUSIVE\tCOOLDOWN\tCONDITION")
+	for _, r := range engine.Rules() {
+		cond := ""
+		if *conditions {
+			cond = r.ConditionSrc
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%d\t%s\n", r.Priority, r.Name, r.Category, r.Exclusive, r.CooldownTicks, cond)
+	}
+	return w.Flush()
+}
+
+// runValidate checks an overrides file: every overridden condition must
+// still compile, every entry must name a real rule and real thresholds, and
+// the resulting rule set must pass rules.Analyze.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	doctrinePath := fs.String("doctrine", "", "doctrine JSON or YAML file whose rules the overrides target (default: the built-in rules)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: vimy validate [-doctrine file] overrides.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected one overrides file")
+	}
+	quietLogs()
+
+	overrides, err := rules.LoadOverrides(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	rs := rules.DefaultRules()
+	if *doctrinePath != "" {
+		if rs, err = doctrineRules(*doctrinePath, ""); err != nil {
+			return err
+		}
+	}
+
+	engine, err := rules.NewEngine(rs)
+	if err != nil {
+		return fmt.Errorf("base rules: %w", err)
+	}
+	if err := engine.SetOverrides(overrides); err != nil {
+		return err
+	}
+	problems := rules.CheckOverrides(rs, overrides)
+	var active []*rules.Rule
+	for _, r := range engine.Rules() {
+		active = append(active, &rules.Rule{
+			Name:            r.Name,
+			Priority:        r.Priority,
+			Category:        r.Category,
+			Group:           r.Group,
+			Exclusive:       r.Exclusive,
+			CooldownTicks:   r.CooldownTicks,
+			ActiveFromTick:  r.ActiveFromTick,
+			ActiveUntilTick: r.ActiveUntilTick,
+			ConditionSrc:    r.ConditionSrc,
+		})
+	}
+	for _, f := range rules.Analyze(active) {
+		problems = append(problems, f.String())
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s):\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	fmt.Printf("%s: %d override(s) OK\n", fs.Arg(0), len(overrides))
+	return nil
+}
+
+// runSimulate replays recorded game states through the engine and reports
+// the commands it would have sent.
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	statesPath := fs.String("states", "", "file of recorded JSON game states, one per line (required)")
+	doctrinePath := fs.String("doctrine", "", "doctrine JSON or YAML file to compile (default: the built-in rules)")
+	opening := fs.String("opening", "", "opening book to run")
+	overridesPath := fs.String("overrides", "", "JSON file of per-rule overrides to apply")
+	faction := fs.String("faction", "soviet", "faction the recorded player is playing")
+	perTick := fs.Bool("ticks", false, "print the commands issued on every tick")
+	seed := fs.Int64("seed", 1, "seed for the rule engine's random choices")
+	checkInvariants := fs.Bool("check", false, "check every command against the replay invariants and fail on a breach")
+	fs.Parse(args)
+	if *statesPath == "" {
+		fs.Usage()
+		return errors.New("-states is required")
+	}
+	quietLogs()
+
+	f, err := os.Open(*statesPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	states, err := sim.ReadStates(f)
+	if err != nil {
+		return err
+	}
+
+	var rs []*rules.Rule
+	if *doctrinePath != "" {
+		rs, err = doctrineRules(*doctrinePath, *opening)
+	} else {
+		if *opening != "" {
+			if _, ok := rules.LookupOpening(*opening); !ok {
+				return fmt.Errorf("unknown opening book %q (available: %v)", *opening, rules.OpeningNames())
+			}
+		}
+		rs = append(rules.DefaultRules(), rules.OpeningRules(*opening)...)
+	}
+	if err != nil {
+		return err
+	}
+	engine, err := rules.NewEngine(rs)
+	if err != nil {
+		return err
+	}
+	engine.SetSeed(*seed)
+	engine.SetFaction(*faction)
+	if *overridesPath != "" {
+		overrides, err := rules.LoadOverrides(*overridesPath)
+		if err != nil {
+			return err
+		}
+		if err := engine.SetOverrides(overrides); err != nil {
+			return err
+		}
+	}
+
+	var invariants []sim.Invariant
+	if *checkInvariants {
+		invariants = sim.Invariants
+	}
+	report, err := sim.Run(engine, *faction, states, invariants...)
+	if *perTick {
+		for _, t := range report.Ticks {
+			fmt.Printf("tick %d: %s\n", t.Tick, formatCounts(t.Commands))
+		}
+	}
+	fmt.Printf("replayed %d of %d states: %s\n", len(report.Ticks), len(states), formatCounts(report.Totals))
+	for _, v := range report.Violations {
+		fmt.Println("violation:", v)
+	}
+	if err == nil && len(report.Violations) > 0 {
+		err = fmt.Errorf("%d invariant violations", len(report.Violations))
+	}
+	return err
+}
+
+// runScenario runs scenario files and reports each expectation the engine
+// missed and each invariant it broke.
+func runScenario(args []string) error {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: vimy scenario file.yaml...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no scenario files given")
+	}
+	quietLogs()
+
+	failed := 0
+	for _, path := range fs.Args() {
+		sc, err := sim.LoadScenario(path)
+		if err != nil {
+			return err
+		}
+		report, failures, err := sim.RunScenario(sc)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(failures) == 0 && len(report.Violations) == 0 {
+			fmt.Printf("ok    %s (%d ticks: %s)\n", path, len(report.Ticks), formatCounts(report.Totals))
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL  %s: %s\n", path, sc.Name)
+		for _, f := range failures {
+			fmt.Println("  ", f)
+		}
+		for _, v := range report.Violations {
+			fmt.Println("   violation:", v)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scenarios failed", failed, fs.NArg())
+	}
+	return nil
+}
+
+// formatCounts renders command counts as "attack_move=3 produce=1", sorted
+// by command type.
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "no commands"
+	}
+	parts := make([]string, 0, len(counts))
+	for _, k := range slices.Sorted(maps.Keys(counts)) {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,286 @@
+// Package fixtures builds synthetic game states for tests and simulation
+// runs, so a scenario reads as what it is — a base, ten heavy tanks, an
+// enemy base to the east — instead of a page of struct literals:
+//
+//	gs := fixtures.NewStateBuilder().
+//		WithBase().
+//		WithArmy(10, "3tnk").
+//		WithEnemyBase("enemy", 100, 20).
+//		Build()
+//
+// Actor IDs are handed out in order from 1, ours and the enemy's alike, so
+// a scenario built the same way always gets the same IDs.
+package fixtures
+
+import (
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Defaults for a new builder.
+const (
+	DefaultMapSize = 128
+	DefaultCash    = 5000
+	DefaultBaseX   = 20
+	DefaultBaseY   = 20
+	DefaultHP      = 100 // HP and MaxHP of every actor unless damaged
+)
+
+// armyColumns is how many units WithArmy puts in each row of a formation.
+const armyColumns = 5
+
+// baseLayout is WithBase's buildings, offset from the base position: a
+// Soviet base with power, economy and ground production.
+var baseLayout = []struct {
+	Type   string
+	DX, DY int
+}{
+	{"fact", 0, 0},
+	{"powr", -3, 0},
+	{"powr", -3, 3},
+	{"proc", 3, 0},
+	{"barr", 0, 3},
+	{"weap", 3, 3},
+}
+
+// enemyBaseLayout is WithEnemyBase's buildings, offset from its position.
+var enemyBaseLayout = []struct {
+	Type   string
+	DX, DY int
+}{
+	{"fact", 0, 0},
+	{"powr", -3, 0},
+	{"proc", 3, 0},
+	{"barr", 0, 3},
+}
+
+// StateBuilder assembles a model.GameState. Methods return the builder so
+// calls chain; Build may be called more than once, e.g. once per tick of a
+// sequence.
+type StateBuilder struct {
+	gs           model.GameState
+	nextID       int
+	baseX, baseY int
+}
+
+// NewStateBuilder returns a builder for tick 1 on an empty
+// DefaultMapSize-square map, with DefaultCash and balanced power.
+func NewStateBuilder() *StateBuilder {
+	return &StateBuilder{
+		gs: model.GameState{
+			Tick:      1,
+			MapWidth:  DefaultMapSize,
+			MapHeight: DefaultMapSize,
+			Player: model.Player{
+				Name:             "vimy",
+				Cash:             DefaultCash,
+				ResourceCapacity: 2000,
+				PowerProvided:    100,
+				PowerDrained:     50,
+				PowerState:       "Normal",
+			},
+		},
+		nextID: 1,
+		baseX:  DefaultBaseX,
+		baseY:  DefaultBaseY,
+	}
+}
+
+func (b *StateBuilder) id() int {
+	id := b.nextID
+	b.nextID++
+	return id
+}
+
+// AtTick sets the game tick.
+func (b *StateBuilder) AtTick(tick int) *StateBuilder {
+	b.gs.Tick = tick
+	return b
+}
+
+// WithMap sets the map size.
+func (b *StateBuilder) WithMap(width, height int) *StateBuilder {
+	b.gs.MapWidth, b.gs.MapHeight = width, height
+	return b
+}
+
+// WithCash sets our cash.
+func (b *StateBuilder) WithCash(cash int) *StateBuilder {
+	b.gs.Player.Cash = cash
+	return b
+}
+
+// WithPower sets our power, deriving the power state as the game does.
+func (b *StateBuilder) WithPower(provided, drained int) *StateBuilder {
+	b.gs.Player.PowerProvided, b.gs.Player.PowerDrained = provided, drained
+	switch {
+	case drained <= provided:
+		b.gs.Player.PowerState = "Normal"
+	case drained < provided*2:
+		b.gs.Player.PowerState = "Low"
+	default:
+		b.gs.Player.PowerState = "Critical"
+	}
+	return b
+}
+
+// BaseAt moves where WithBase builds and WithArmy musters. Call it before
+// either.
+func (b *StateBuilder) BaseAt(x, y int) *StateBuilder {
+	b.baseX, b.baseY = x, y
+	return b
+}
+
+// WithBase adds a construction yard, two power plants, a refinery, barracks
+// and a war factory around the base position, with a spawn point there.
+func (b *StateBuilder) WithBase() *StateBuilder {
+	for _, l := range baseLayout {
+		b.WithBuilding(l.Type, b.baseX+l.DX, b.baseY+l.DY)
+	}
+	b.gs.SpawnPoints = append(b.gs.SpawnPoints, model.SpawnPoint{X: b.baseX, Y: b.baseY, Own: true})
+	return b
+}
+
+// WithBuilding adds one of our buildings at full health.
+func (b *StateBuilder) WithBuilding(t string, x, y int) *StateBuilder {
+	b.gs.Buildings = append(b.gs.Buildings, model.Building{ID: b.id(), Type: t, X: x, Y: y, HP: DefaultHP, MaxHP: DefaultHP})
+	return b
+}
+
+// WithUnit adds one of our units at full health.
+func (b *StateBuilder) WithUnit(t string, x, y int, idle bool) *StateBuilder {
+	b.gs.Units = append(b.gs.Units, model.Unit{ID: b.id(), Type: t, X: x, Y: y, HP: DefaultHP, MaxHP: DefaultHP, Idle: idle})
+	return b
+}
+
+// WithArmy adds n idle units of type t in rows beside the base.
+func (b *StateBuilder) WithArmy(n int, t string) *StateBuilder {
+	return b.WithArmyAt(n, t, b.baseX+6, b.baseY+6)
+}
+
+// WithArmyAt adds n idle units of type t in rows from (x, y).
+func (b *StateBuilder) WithArmyAt(n int, t string, x, y int) *StateBuilder {
+	for i := range n {
+		b.WithUnit(t, x+i%armyColumns, y+i/armyColumns, true)
+	}
+	return b
+}
+
+// WithEnemyBase adds a visible enemy construction yard, power plant,
+// refinery and barracks around (x, y), and a spawn point there.
+func (b *StateBuilder) WithEnemyBase(owner string, x, y int) *StateBuilder {
+	for _, l := range enemyBaseLayout {
+		b.WithEnemy(owner, l.Type, x+l.DX, y+l.DY)
+	}
+	b.gs.SpawnPoints = append(b.gs.SpawnPoints, model.SpawnPoint{X: x, Y: y})
+	return b
+}
+
+// WithEnemy adds one visible enemy actor at full health.
+func (b *StateBuilder) WithEnemy(owner, t string, x, y int) *StateBuilder {
+	b.gs.Enemies = append(b.gs.Enemies, model.Enemy{ID: b.id(), Owner: owner, Type: t, X: x, Y: y, HP: DefaultHP, MaxHP: DefaultHP})
+	return b
+}
+
+// WithEnemyArmy adds n visible enemy units of type t in rows from (x, y).
+func (b *StateBuilder) WithEnemyArmy(owner string, n int, t string, x, y int) *StateBuilder {
+	for i := range n {
+		b.WithEnemy(owner, t, x+i%armyColumns, y+i/armyColumns)
+	}
+	return b
+}
+
+// WithoutEnemies removes every enemy actor, as when a raid is beaten off
+// or walks out of sight.
+func (b *StateBuilder) WithoutEnemies() *StateBuilder {
+	b.gs.Enemies = nil
+	return b
+}
+
+// WithQueue adds an idle production queue of the given type ("Building",
+// "Infantry", ...) that can build the listed items.
+func (b *StateBuilder) WithQueue(queueType string, buildable ...string) *StateBuilder {
+	b.gs.ProductionQueues = append(b.gs.ProductionQueues, model.ProductionQueue{
+		ActorID:   b.id(),
+		Type:      queueType,
+		Items:     slices.Clone(buildable),
+		Buildable: slices.Clone(buildable),
+	})
+	return b
+}
+
+// WithSupportPower adds one of our support powers, ready or charging.
+func (b *StateBuilder) WithSupportPower(key string, ready bool) *StateBuilder {
+	sp := model.SupportPower{Key: key, Ready: ready, TotalTicks: 1000}
+	if !ready {
+		sp.RemainingTicks = sp.TotalTicks / 2
+	}
+	b.gs.SupportPowers = append(b.gs.SupportPowers, sp)
+	return b
+}
+
+// Damaged sets the HP of the most recently added actor to pct of its
+// maximum.
+func (b *StateBuilder) Damaged(pct float64) *StateBuilder {
+	hp := int(float64(DefaultHP) * pct)
+	last := b.nextID - 1
+	for i := range b.gs.Buildings {
+		if b.gs.Buildings[i].ID == last {
+			b.gs.Buildings[i].HP = hp
+		}
+	}
+	for i := range b.gs.Units {
+		if b.gs.Units[i].ID == last {
+			b.gs.Units[i].HP = hp
+		}
+	}
+	for i := range b.gs.Enemies {
+		if b.gs.Enemies[i].ID == last {
+			b.gs.Enemies[i].HP = hp
+		}
+	}
+	return b
+}
+
+// Build returns the state. It shares nothing with the builder, so later
+// calls can change the builder without touching states already built.
+func (b *StateBuilder) Build() model.GameState {
+	gs := b.gs
+	gs.Buildings = slices.Clone(gs.Buildings)
+	gs.Units = slices.Clone(gs.Units)
+	gs.Enemies = slices.Clone(gs.Enemies)
+	gs.ProductionQueues = slices.Clone(gs.ProductionQueues)
+	for i := range gs.ProductionQueues {
+		gs.ProductionQueues[i].Items = slices.Clone(gs.ProductionQueues[i].Items)
+		gs.ProductionQueues[i].Buildable = slices.Clone(gs.ProductionQueues[i].Buildable)
+	}
+	gs.SupportPowers = slices.Clone(gs.SupportPowers)
+	gs.SpawnPoints = slices.Clone(gs.SpawnPoints)
+	return gs
+}
+
+// LandTerrain returns an all-land terrain grid for a map of the given size,
+// for rules that refuse to act without terrain.
+func LandTerrain(width, height int) *model.TerrainGrid {
+	const n = 32
+	return &model.TerrainGrid{
+		Cols:  n,
+		Rows:  n,
+		CellW: (width + n - 1) / n,
+		CellH: (height + n - 1) / n,
+		Grid:  make([]model.TerrainType, n*n),
+	}
+}
+
+// Ticks builds n states at consecutive ticks from the builder's current
+// tick, for replaying a standing scenario through sim.Run, and leaves the
+// builder at the tick after the last.
+func (b *StateBuilder) Ticks(n int) []model.GameState {
+	states := make([]model.GameState, n)
+	for i := range states {
+		states[i] = b.Build()
+		b.gs.Tick++
+	}
+	return states
+}
@@ -0,0 +1,100 @@
+package fixtures
+
+import "testing"
+
+func TestStateBuilder(t *testing.T) {
+	b := NewStateBuilder().
+		WithBase().
+		WithArmy(10, "3tnk").
+		WithEnemyBase("enemy", 100, 20).
+		WithQueue("Vehicle", "3tnk", "harv")
+	gs := b.Build()
+
+	if len(gs.Buildings) != len(baseLayout) {
+		t.Errorf("buildings = %d, want %d", len(gs.Buildings), len(baseLayout))
+	}
+	if len(gs.Units) != 10 {
+		t.Fatalf("units = %d, want 10", len(gs.Units))
+	}
+	for _, u := range gs.Units {
+		if u.Type != "3tnk" || !u.Idle || u.HP != DefaultHP {
+			t.Errorf("unit %+v, want an idle full-health 3tnk", u)
+		}
+	}
+	if len(gs.Enemies) != len(enemyBaseLayout) || gs.Enemies[0].Owner != "enemy" || gs.Enemies[0].X != 100 {
+		t.Errorf("enemies = %+v, want the enemy base at 100,20", gs.Enemies)
+	}
+	if len(gs.SpawnPoints) != 2 || !gs.SpawnPoints[0].Own || gs.SpawnPoints[1].Own {
+		t.Errorf("spawn points = %+v, want ours then the enemy's", gs.SpawnPoints)
+	}
+
+	seen := make(map[int]bool)
+	var ids []int
+	for _, b := range gs.Buildings {
+		ids = append(ids, b.ID)
+	}
+	for _, u := range gs.Units {
+		ids = append(ids, u.ID)
+	}
+	for _, e := range gs.Enemies {
+		ids = append(ids, e.ID)
+	}
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("duplicate actor ID %d", id)
+		}
+		seen[id] = true
+	}
+
+	// Built states are independent of the builder and of each other.
+	b.WithArmy(1, "e1").Damaged(0.5)
+	gs.Units[0].X = -1
+	again := b.Build()
+	if len(again.Units) != 11 || again.Units[0].X == -1 {
+		t.Errorf("rebuilt state shares memory with an earlier build")
+	}
+	if last := again.Units[10]; last.Type != "e1" || last.HP != DefaultHP/2 {
+		t.Errorf("last unit = %+v, want a half-health e1", last)
+	}
+}
+
+func TestTicks(t *testing.T) {
+	b := NewStateBuilder().AtTick(10)
+	states := b.Ticks(3)
+	for i, gs := range states {
+		if gs.Tick != 10+i {
+			t.Errorf("state %d tick = %d, want %d", i, gs.Tick, 10+i)
+		}
+	}
+	if next := b.Build().Tick; next != 13 {
+		t.Errorf("builder tick after Ticks = %d, want 13", next)
+	}
+}
+
+func TestWithPower(t *testing.T) {
+	for _, tc := range []struct {
+		provided, drained int
+		want              string
+	}{
+		{100, 80, "Normal"},
+		{100, 150, "Low"},
+		{100, 250, "Critical"},
+	} {
+		if got := NewStateBuilder().WithPower(tc.provided, tc.drained).Build().Player.PowerState; got != tc.want {
+			t.Errorf("WithPower(%d, %d) state = %q, want %q", tc.provided, tc.drained, got, tc.want)
+		}
+	}
+}
+
+func TestWithoutEnemies(t *testing.T) {
+	b := NewStateBuilder().WithEnemyArmy("enemy", 4, "e1", 30, 30)
+	before := b.Build()
+	b.WithoutEnemies().WithEnemy("enemy", "e3", 40, 40)
+	after := b.Build()
+	if len(before.Enemies) != 4 {
+		t.Errorf("state built before the raid left has %d enemies, want 4", len(before.Enemies))
+	}
+	if len(after.Enemies) != 1 || after.Enemies[0].Type != "e3" {
+		t.Errorf("enemies after WithoutEnemies = %+v, want only the e3 added since", after.Enemies)
+	}
+}
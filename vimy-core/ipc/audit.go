@@ -0,0 +1,40 @@
+package ipc
+
+import "encoding/json"
+
+// Origin is what produced a command: the rule that fired, the condition
+// that matched, and the tick it matched on.
+type Origin struct {
+	Rule      string `json:"rule"`
+	Condition string `json:"condition,omitempty"`
+	Tick      int    `json:"tick"`
+}
+
+// CommandRecord is one command written through a From view, as the mod
+// received it.
+type CommandRecord struct {
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+	Origin Origin          `json:"origin"`
+}
+
+// Auditor receives a record of every command sent through a From view. It
+// runs on the sending goroutine, after the write, so it must be quick.
+type Auditor func(CommandRecord)
+
+// SetAuditor installs fn to receive the commands sent through views of c.
+// Call it before the connection is shared.
+func (c *Connection) SetAuditor(fn Auditor) {
+	c.base().audit = fn
+}
+
+// From returns a view of c whose Sends are attributed to origin. The view
+// shares c's socket, heartbeat and Sent count; messages sent on c itself,
+// such as pings, are not audited. A nil connection gives a nil view.
+func (c *Connection) From(origin Origin) *Connection {
+	if c == nil {
+		return nil
+	}
+	base := c.base()
+	return &Connection{conn: base.conn, handlers: base.handlers, hb: base.hb, done: base.done, root: base, origin: origin}
+}
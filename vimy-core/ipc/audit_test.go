@@ -0,0 +1,54 @@
+package ipc
+
+import (
+	"net"
+	"testing"
+)
+
+func TestFromAttributesCommands(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	c := NewConnection(server, nil)
+	defer server.Close()
+	var got []CommandRecord
+	c.SetAuditor(func(rec CommandRecord) { got = append(got, rec) })
+
+	read := make(chan Envelope, 3)
+	go func() {
+		for {
+			env, err := ReadEnvelope(client)
+			if err != nil {
+				return
+			}
+			read <- env
+		}
+	}()
+
+	origin := Origin{Rule: "build-barracks", Condition: "Cash > 400", Tick: 120}
+	view := c.From(origin)
+	if err := view.Send(TypeProduce, ProduceCommand{Queue: "Building", Item: "barr"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Send(TypePing, PingMessage{Seq: 1, Tick: 120}); err != nil {
+		t.Fatal(err)
+	}
+	if err := view.From(Origin{Rule: "sell"}).Send(TypeSellBuilding, struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{TypeProduce, TypePing, TypeSellBuilding} {
+		if env := <-read; env.Type != want {
+			t.Errorf("mod received %q, want %q", env.Type, want)
+		}
+	}
+
+	if len(got) != 2 || got[0].Type != TypeProduce || got[0].Origin != origin || got[1].Origin.Rule != "sell" {
+		t.Errorf("audited %+v, want the produce from build-barracks and the sell, not the ping", got)
+	}
+	if c.Sent() != 3 || view.Sent() != 3 {
+		t.Errorf("Sent = %d on the connection, %d on the view; want 3 on both", c.Sent(), view.Sent())
+	}
+	var nilConn *Connection
+	if nilConn.From(origin) != nil {
+		t.Error("view of a nil connection isn't nil")
+	}
+}
@@ -2,28 +2,35 @@ package ipc
 
 // Command type constants — must stay in sync with C# CommandExecutor.
 const (
-	TypeProduce          = "produce"
-	TypePlaceBuilding    = "place_building"
-	TypeAttackMove       = "attack_move"
-	TypeMove             = "move"
-	TypeSetRally         = "set_rally"
-	TypeDeploy           = "deploy"
-	TypeRepairBuilding   = "repair_building"
-	TypeAttack           = "attack"
-	TypeCancelProduction = "cancel_production"
-	TypeHarvest          = "harvest"
-	TypeCapture          = "capture"
-	TypeSupportPower     = "support_power"
-	TypeEnterTransport   = "enter_transport"
-	TypeUnload           = "unload"
-	TypeRepairUnit       = "repair_unit"
-	TypePlaceMinefield   = "place_minefield"
+	TypeProduce           = "produce"
+	TypePlaceBuilding     = "place_building"
+	TypeAttackMove        = "attack_move"
+	TypeMove              = "move"
+	TypeSetRally          = "set_rally"
+	TypeDeploy            = "deploy"
+	TypeUndeploy          = "undeploy"
+	TypeRepairBuilding    = "repair_building"
+	TypeAttack            = "attack"
+	TypeCancelProduction  = "cancel_production"
+	TypeHarvest           = "harvest"
+	TypeCapture           = "capture"
+	TypeSupportPower      = "support_power"
+	TypeEnterTransport    = "enter_transport"
+	TypeUnload            = "unload"
+	TypeRepairUnit        = "repair_unit"
+	TypePlaceMinefield    = "place_minefield"
+	TypeGuard             = "guard"
+	TypeForceAttackGround = "force_attack_ground"
+	TypeSellBuilding      = "sell_building"
 )
 
 type ProduceCommand struct {
 	Queue string `json:"queue"`
 	Item  string `json:"item"`
 	Count int    `json:"count,omitempty"`
+	// QueueActor picks a specific queue of the type by its actor ID; 0 lets
+	// the sidecar use the first free one.
+	QueueActor uint32 `json:"queue_actor,omitempty"`
 }
 
 type PlaceBuildingCommand struct {
@@ -33,16 +40,20 @@ type PlaceBuildingCommand struct {
 	HintY int    `json:"hint_y,omitempty"` // optional placement search center
 }
 
+// AttackMoveCommand and MoveCommand replace the actors' current orders
+// unless Queued is set, in which case they run after the existing ones.
 type AttackMoveCommand struct {
 	ActorIDs []uint32 `json:"actor_ids"`
 	X        int      `json:"x"`
 	Y        int      `json:"y"`
+	Queued   bool     `json:"queued,omitempty"`
 }
 
 type MoveCommand struct {
 	ActorID uint32 `json:"actor_id"`
 	X       int    `json:"x"`
 	Y       int    `json:"y"`
+	Queued  bool   `json:"queued,omitempty"`
 }
 
 type SetRallyCommand struct {
@@ -55,6 +66,11 @@ type DeployCommand struct {
 	ActorID uint32 `json:"actor_id"`
 }
 
+// UndeployCommand packs a construction yard back into an MCV.
+type UndeployCommand struct {
+	ActorID uint32 `json:"actor_id"`
+}
+
 type RepairBuildingCommand struct {
 	ActorID uint32 `json:"actor_id"`
 }
@@ -108,3 +124,23 @@ type PlaceMinefieldCommand struct {
 	EndX    int    `json:"end_x"`
 	EndY    int    `json:"end_y"`
 }
+
+// GuardCommand orders units to guard (escort) a friendly actor: they follow
+// it and engage anything that threatens it.
+type GuardCommand struct {
+	ActorIDs []uint32 `json:"actor_ids"`
+	TargetID uint32   `json:"target_id"`
+}
+
+// ForceAttackGroundCommand force-fires at a map cell regardless of what is
+// there — used to clear destructible obstacles blocking a route.
+type ForceAttackGroundCommand struct {
+	ActorIDs []uint32 `json:"actor_ids"`
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+}
+
+// SellBuildingCommand sells one of our buildings for a partial refund.
+type SellBuildingCommand struct {
+	ActorID uint32 `json:"actor_id"`
+}
@@ -3,6 +3,8 @@ package ipc
 import (
 	"log/slog"
 	"net"
+	"sync/atomic"
+	"time"
 )
 
 // Handler processes a received envelope. Return nil to send no reply.
@@ -13,6 +15,13 @@ type Handler func(env Envelope) (*Envelope, error)
 type Connection struct {
 	conn     net.Conn
 	handlers map[string]Handler
+	hb       *Heartbeat
+	done     chan struct{} // closed when ReadLoop returns
+	sent     atomic.Int64  // envelopes written by Send
+	audit    Auditor       // receives attributed commands; see SetAuditor
+
+	root   *Connection // the connection a From view writes through; nil on the connection itself
+	origin Origin      // what a From view's commands are attributed to
 }
 
 func NewConnection(conn net.Conn, handlers map[string]Handler) *Connection {
@@ -22,6 +31,8 @@ func NewConnection(conn net.Conn, handlers map[string]Handler) *Connection {
 	return &Connection{
 		conn:     conn,
 		handlers: handlers,
+		hb:       newHeartbeat(),
+		done:     make(chan struct{}),
 	}
 }
 
@@ -29,18 +40,63 @@ func (c *Connection) RegisterHandler(msgType string, handler Handler) {
 	c.handlers[msgType] = handler
 }
 
+// Send writes one message. On a view made by From, the message is also
+// handed to the connection's auditor along with the view's origin.
 func (c *Connection) Send(msgType string, data any) error {
 	env, err := NewEnvelope(msgType, data)
 	if err != nil {
 		return err
 	}
-	return WriteEnvelope(c.conn, env)
+	if err := WriteEnvelope(c.conn, env); err != nil {
+		return err
+	}
+	base := c.base()
+	base.sent.Add(1)
+	if c.root != nil && base.audit != nil {
+		base.audit(CommandRecord{Type: env.Type, Data: env.Data, Origin: c.origin})
+	}
+	return nil
+}
+
+// Sent returns how many envelopes Send has written, counting those written
+// through views. A nil connection has sent none.
+func (c *Connection) Sent() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.base().sent.Load()
+}
+
+// base is the connection itself, or the one a view writes through.
+func (c *Connection) base() *Connection {
+	if c.root != nil {
+		return c.root
+	}
+	return c
 }
 
 // ReadLoop blocks until the connection closes or errors. It owns the conn lifetime
 // so callers don't need to track cleanup.
+//
+// Reads never wait on handlers: messages go through a bounded mailbox to a
+// single handler goroutine, so handlers still run one at a time and in
+// arrival order, but a game state that is superseded before its turn comes
+// is skipped (see coalescedTypes). ReadLoop returns once the handler
+// goroutine has finished with what was already queued.
 func (c *Connection) ReadLoop() {
-	defer c.conn.Close()
+	defer close(c.done)
+
+	mb := newMailbox()
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		c.dispatch(mb)
+	}()
+	defer func() {
+		mb.close()
+		<-handled
+		c.conn.Close()
+	}()
 
 	for {
 		env, err := ReadEnvelope(c.conn)
@@ -48,6 +104,24 @@ func (c *Connection) ReadLoop() {
 			slog.Info("connection read ended", "error", err)
 			return
 		}
+		now := time.Now()
+		c.hb.received(now)
+		if env.Type == TypePong {
+			c.handlePong(env, now)
+			continue
+		}
+		mb.put(env)
+	}
+}
+
+// dispatch runs handlers for mailbox messages until it is closed and
+// drained. A failed reply write closes the conn, which ends ReadLoop's reads.
+func (c *Connection) dispatch(mb *mailbox) {
+	for {
+		env, ok := mb.next()
+		if !ok {
+			return
+		}
 
 		handler, ok := c.handlers[env.Type]
 		if !ok {
@@ -64,6 +138,7 @@ func (c *Connection) ReadLoop() {
 		if resp != nil {
 			if err := WriteEnvelope(c.conn, *resp); err != nil {
 				slog.Error("failed to send response", "type", resp.Type, "error", err)
+				c.conn.Close()
 				return
 			}
 			slog.Debug("sent response", "type", resp.Type)
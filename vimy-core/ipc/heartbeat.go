@@ -0,0 +1,215 @@
+package ipc
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Heartbeat settings. Pings ride along with game-state handling, so they go
+// out no more often than states arrive; the hung check runs on its own timer
+// because a hung mod sends nothing at all.
+const (
+	PingInterval     = 2 * time.Second  // minimum wall time between pings
+	HungTimeout      = 10 * time.Second // silence this long means the mod is hung
+	rttWindow        = 32               // round trips averaged in HeartbeatStats
+	commandLagWarnAt = 25               // ticks; command lag this high is logged as a warning
+)
+
+// PingMessage asks the mod to echo Seq back. Tick is the game tick whose
+// commands were sent just before the ping.
+type PingMessage struct {
+	Seq  uint64 `json:"seq"`
+	Tick int    `json:"tick"`
+}
+
+// PongMessage answers a ping. Tick is the mod's world tick when it handled
+// the ping — and so applied the commands sent ahead of it.
+type PongMessage struct {
+	Seq  uint64 `json:"seq"`
+	Tick int    `json:"tick"`
+}
+
+// HeartbeatStats summarises link health for the dashboard.
+type HeartbeatStats struct {
+	LastRTTMs       float64 `json:"last_rtt_ms"`
+	AvgRTTMs        float64 `json:"avg_rtt_ms"`
+	MaxRTTMs        float64 `json:"max_rtt_ms"`
+	CommandLagTicks int     `json:"command_lag_ticks"` // game ticks from a state to its commands being applied
+	SilentMs        int64   `json:"silent_ms"`         // since the last message from the mod
+	Outstanding     int     `json:"outstanding"`       // pings sent but not answered
+	Pings           uint64  `json:"pings"`
+	Pongs           uint64  `json:"pongs"`
+	Hung            bool    `json:"hung"`
+}
+
+type pendingPing struct {
+	sent time.Time
+	tick int
+}
+
+// Heartbeat tracks ping round trips and mod silence on one connection.
+type Heartbeat struct {
+	mu       sync.Mutex
+	seq      uint64
+	pongs    uint64
+	pending  map[uint64]pendingPing
+	lastPing time.Time
+	lastRecv time.Time
+	rtts     []time.Duration // most recent last, at most rttWindow
+	lagTicks int
+	hung     bool
+}
+
+func newHeartbeat() *Heartbeat {
+	return &Heartbeat{pending: make(map[uint64]pendingPing)}
+}
+
+// received notes that a message arrived from the mod.
+func (h *Heartbeat) received(now time.Time) {
+	h.mu.Lock()
+	h.lastRecv = now
+	h.mu.Unlock()
+}
+
+// nextPing returns the ping to send after tick's commands, or false if the
+// last one went out less than PingInterval ago.
+func (h *Heartbeat) nextPing(tick int, now time.Time) (PingMessage, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now.Sub(h.lastPing) < PingInterval {
+		return PingMessage{}, false
+	}
+	h.seq++
+	h.lastPing = now
+	// Pings unanswered for HungTimeout were lost; don't let them pile up.
+	for seq, p := range h.pending {
+		if now.Sub(p.sent) > HungTimeout {
+			delete(h.pending, seq)
+		}
+	}
+	h.pending[h.seq] = pendingPing{sent: now, tick: tick}
+	return PingMessage{Seq: h.seq, Tick: tick}, true
+}
+
+// pong records a ping's answer and returns its round trip and command lag.
+// ok is false for an unknown or expired ping.
+func (h *Heartbeat) pong(p PongMessage, now time.Time) (rtt time.Duration, lag int, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sent, ok := h.pending[p.Seq]
+	if !ok {
+		return 0, 0, false
+	}
+	delete(h.pending, p.Seq)
+	h.pongs++
+	rtt = now.Sub(sent.sent)
+	h.rtts = append(h.rtts, rtt)
+	if len(h.rtts) > rttWindow {
+		h.rtts = h.rtts[len(h.rtts)-rttWindow:]
+	}
+	h.lagTicks = max(0, p.Tick-sent.tick)
+	return rtt, h.lagTicks, true
+}
+
+// checkHung updates the hung flag and reports whether it changed. The mod
+// counts as hung once it has said nothing for HungTimeout.
+func (h *Heartbeat) checkHung(now time.Time) (hung, changed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hung = !h.lastRecv.IsZero() && now.Sub(h.lastRecv) > HungTimeout
+	changed = hung != h.hung
+	h.hung = hung
+	return hung, changed
+}
+
+// Stats returns the current link health.
+func (h *Heartbeat) Stats() HeartbeatStats {
+	return h.stats(time.Now())
+}
+
+func (h *Heartbeat) stats(now time.Time) HeartbeatStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := HeartbeatStats{
+		CommandLagTicks: h.lagTicks,
+		Outstanding:     len(h.pending),
+		Pings:           h.seq,
+		Pongs:           h.pongs,
+		Hung:            h.hung,
+	}
+	if !h.lastRecv.IsZero() {
+		s.SilentMs = now.Sub(h.lastRecv).Milliseconds()
+	}
+	if n := len(h.rtts); n > 0 {
+		var sum, peak time.Duration
+		for _, r := range h.rtts {
+			sum += r
+			peak = max(peak, r)
+		}
+		s.LastRTTMs = ms(h.rtts[n-1])
+		s.AvgRTTMs = ms(sum / time.Duration(n))
+		s.MaxRTTMs = ms(peak)
+	}
+	return s
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+
+// Heartbeat returns the connection's link health tracker.
+func (c *Connection) Heartbeat() *Heartbeat { return c.hb }
+
+// Ping sends a heartbeat ping after tick's commands if one is due. Call it
+// from the handler goroutine so it doesn't race other writes.
+func (c *Connection) Ping(tick int) error {
+	p, ok := c.hb.nextPing(tick, time.Now())
+	if !ok {
+		return nil
+	}
+	return c.Send(TypePing, p)
+}
+
+// handlePong records a pong; it is answered inside ReadLoop rather than by a
+// registered handler.
+func (c *Connection) handlePong(env Envelope, now time.Time) {
+	var p PongMessage
+	if err := json.Unmarshal(env.Data, &p); err != nil {
+		slog.Warn("bad pong", "error", err)
+		return
+	}
+	rtt, lag, ok := c.hb.pong(p, now)
+	if !ok {
+		slog.Debug("pong for unknown ping", "seq", p.Seq)
+		return
+	}
+	if lag >= commandLagWarnAt {
+		slog.Warn("commands applied late", "lag_ticks", lag, "rtt", rtt, "tick", p.Tick)
+		return
+	}
+	slog.Debug("pong", "seq", p.Seq, "rtt", rtt, "lag_ticks", lag)
+}
+
+// Watch logs when the mod goes silent for HungTimeout and when it recovers,
+// until ctx is cancelled or ReadLoop returns.
+func (c *Connection) Watch(ctx context.Context) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		case now := <-t.C:
+			hung, changed := c.hb.checkHung(now)
+			switch {
+			case changed && hung:
+				slog.Warn("mod unresponsive", "silent_ms", c.hb.stats(now).SilentMs, "timeout", HungTimeout)
+			case changed:
+				slog.Info("mod responsive again")
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ipc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatPingInterval(t *testing.T) {
+	h := newHeartbeat()
+	t0 := time.Unix(1000, 0)
+
+	p, ok := h.nextPing(10, t0)
+	if !ok || p.Seq != 1 || p.Tick != 10 {
+		t.Fatalf("first ping = %+v, %v; want seq 1 tick 10", p, ok)
+	}
+	if _, ok := h.nextPing(20, t0.Add(PingInterval/2)); ok {
+		t.Error("ping sent before PingInterval elapsed")
+	}
+	if p, ok := h.nextPing(30, t0.Add(PingInterval)); !ok || p.Seq != 2 {
+		t.Errorf("second ping = %+v, %v; want seq 2", p, ok)
+	}
+	if got := h.stats(t0).Outstanding; got != 2 {
+		t.Errorf("outstanding = %d, want 2", got)
+	}
+}
+
+func TestHeartbeatPong(t *testing.T) {
+	h := newHeartbeat()
+	t0 := time.Unix(1000, 0)
+	p, _ := h.nextPing(100, t0)
+
+	rtt, lag, ok := h.pong(PongMessage{Seq: p.Seq, Tick: 103}, t0.Add(40*time.Millisecond))
+	if !ok || rtt != 40*time.Millisecond || lag != 3 {
+		t.Fatalf("pong = %v, %d, %v; want 40ms, 3, true", rtt, lag, ok)
+	}
+	if _, _, ok := h.pong(PongMessage{Seq: p.Seq, Tick: 104}, t0.Add(time.Second)); ok {
+		t.Error("duplicate pong accepted")
+	}
+
+	s := h.stats(t0)
+	if s.LastRTTMs != 40 || s.AvgRTTMs != 40 || s.CommandLagTicks != 3 || s.Pongs != 1 || s.Outstanding != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestHeartbeatExpiresLostPings(t *testing.T) {
+	h := newHeartbeat()
+	t0 := time.Unix(1000, 0)
+	h.nextPing(1, t0)
+	h.nextPing(2, t0.Add(HungTimeout+time.Second))
+	if got := h.stats(t0).Outstanding; got != 1 {
+		t.Errorf("outstanding = %d, want lost ping dropped", got)
+	}
+}
+
+func TestHeartbeatHung(t *testing.T) {
+	h := newHeartbeat()
+	t0 := time.Unix(1000, 0)
+
+	if hung, _ := h.checkHung(t0.Add(time.Hour)); hung {
+		t.Error("hung before the mod ever spoke")
+	}
+	h.received(t0)
+	if hung, changed := h.checkHung(t0.Add(HungTimeout / 2)); hung || changed {
+		t.Errorf("checkHung inside timeout = %v, %v", hung, changed)
+	}
+	if hung, changed := h.checkHung(t0.Add(HungTimeout + time.Second)); !hung || !changed {
+		t.Errorf("checkHung after timeout = %v, %v; want true, true", hung, changed)
+	}
+	if hung, changed := h.checkHung(t0.Add(HungTimeout + 2*time.Second)); !hung || changed {
+		t.Errorf("checkHung still silent = %v, %v; want true, false", hung, changed)
+	}
+	h.received(t0.Add(HungTimeout + 3*time.Second))
+	if hung, changed := h.checkHung(t0.Add(HungTimeout + 3*time.Second)); hung || !changed {
+		t.Errorf("checkHung after recovery = %v, %v; want false, true", hung, changed)
+	}
+}
@@ -0,0 +1,91 @@
+package ipc
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// mailboxPerType bounds how many messages of one type may wait for the
+// handler goroutine. Past it the oldest of that type is dropped.
+const mailboxPerType = 16
+
+// coalescedTypes keep only their newest queued message. A game state
+// supersedes every earlier one, so a burst that arrives while a handler is
+// busy is evaluated once, against the latest state.
+var coalescedTypes = map[string]bool{
+	TypeGameState: true,
+}
+
+// mailbox sits between ReadLoop's reader and the handler goroutine so a slow
+// handler never stalls reads. Messages are handed out in arrival order.
+type mailbox struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []Envelope
+	counts map[string]int
+	closed bool
+}
+
+func newMailbox() *mailbox {
+	m := &mailbox{counts: make(map[string]int)}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// put queues env, replacing a queued message of a coalesced type or
+// dropping the oldest of a type that has hit mailboxPerType.
+func (m *mailbox) put(env Envelope) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	switch {
+	case coalescedTypes[env.Type] && m.counts[env.Type] > 0:
+		m.remove(env.Type)
+		slog.Debug("coalesced queued message", "type", env.Type)
+	case m.counts[env.Type] >= mailboxPerType:
+		m.remove(env.Type)
+		slog.Warn("message queue full, dropped oldest", "type", env.Type, "limit", mailboxPerType)
+	}
+	m.queue = append(m.queue, env)
+	m.counts[env.Type]++
+	m.cond.Signal()
+}
+
+// remove drops the oldest queued message of type t. Callers hold m.mu.
+func (m *mailbox) remove(t string) {
+	for i, env := range m.queue {
+		if env.Type == t {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			m.counts[t]--
+			return
+		}
+	}
+}
+
+// next blocks for the next message. ok is false once the mailbox is closed
+// and drained.
+func (m *mailbox) next() (env Envelope, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.queue) == 0 && !m.closed {
+		m.cond.Wait()
+	}
+	if len(m.queue) == 0 {
+		return Envelope{}, false
+	}
+	env = m.queue[0]
+	m.queue = m.queue[1:]
+	m.counts[env.Type]--
+	return env, true
+}
+
+// close stops further puts and wakes the handler goroutine once the queue
+// has drained.
+func (m *mailbox) close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	m.cond.Broadcast()
+}
@@ -0,0 +1,90 @@
+package ipc
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func drain(m *mailbox) []Envelope {
+	m.close()
+	var out []Envelope
+	for {
+		env, ok := m.next()
+		if !ok {
+			return out
+		}
+		out = append(out, env)
+	}
+}
+
+func TestMailboxCoalescesGameState(t *testing.T) {
+	m := newMailbox()
+	m.put(Envelope{Type: TypeHello, Data: []byte(`1`)})
+	m.put(Envelope{Type: TypeGameState, Data: []byte(`1`)})
+	m.put(Envelope{Type: TypeGameState, Data: []byte(`2`)})
+	m.put(Envelope{Type: TypeGameState, Data: []byte(`3`)})
+
+	got := drain(m)
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want hello and one state", len(got))
+	}
+	if got[0].Type != TypeHello || got[1].Type != TypeGameState || string(got[1].Data) != "3" {
+		t.Errorf("got %s/%s then %s/%s; want hello then the latest state", got[0].Type, got[0].Data, got[1].Type, got[1].Data)
+	}
+}
+
+func TestMailboxBoundsPerType(t *testing.T) {
+	m := newMailbox()
+	for i := 0; i < mailboxPerType+3; i++ {
+		m.put(Envelope{Type: TypeAck, Data: []byte{byte('a' + i)}})
+	}
+	got := drain(m)
+	if len(got) != mailboxPerType {
+		t.Fatalf("got %d messages, want %d", len(got), mailboxPerType)
+	}
+	if got[0].Data[0] != 'd' {
+		t.Errorf("oldest kept = %q, want the three oldest dropped", got[0].Data)
+	}
+}
+
+func TestReadLoopSkipsStatesSupersededWhileHandling(t *testing.T) {
+	client, server := net.Pipe()
+	c := NewConnection(server, nil)
+
+	first := make(chan struct{})
+	release := make(chan struct{})
+	var seen []string
+	c.RegisterHandler(TypeGameState, func(env Envelope) (*Envelope, error) {
+		seen = append(seen, string(env.Data))
+		if len(seen) == 1 {
+			close(first)
+			<-release
+		}
+		return nil, nil
+	})
+	go c.ReadLoop()
+
+	send := func(data string) {
+		if err := WriteEnvelope(client, Envelope{Type: TypeGameState, Data: []byte(data)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	send("1")
+	<-first
+	// The handler is stuck on state 1; reads must still go through.
+	for _, d := range []string{"2", "3", "4"} {
+		send(d)
+	}
+	close(release)
+	client.Close()
+
+	select {
+	case <-c.done:
+	case <-time.After(time.Second):
+		t.Fatal("ReadLoop did not return")
+	}
+	if len(seen) != 2 || seen[0] != "1" || seen[1] != "4" {
+		t.Errorf("handled states %v, want [1 4]", seen)
+	}
+}
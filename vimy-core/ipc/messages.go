@@ -5,11 +5,15 @@ const (
 	TypeHello     = "hello"
 	TypeAck       = "ack"
 	TypeGameState = "game_state"
+	TypePing      = "ping"
+	TypePong      = "pong"
 )
 
 type HelloMessage struct {
 	Player  string       `json:"player"`
 	Faction string       `json:"faction"`
+	Mod     string       `json:"mod,omitempty"`     // OpenRA mod id (e.g. "ra", "cnc", "d2k"); empty is RA
+	Session string       `json:"session,omitempty"` // per-game key; lets a reconnect resume its session
 	Terrain *TerrainData `json:"terrain,omitempty"`
 }
 
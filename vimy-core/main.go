@@ -5,15 +5,16 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
-	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/nstehr/vimy/vimy-core/agent"
-	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/brain"
 	"github.com/nstehr/vimy/vimy-core/rules"
-	"github.com/nstehr/vimy/vimy-core/server"
+	_ "modernc.org/sqlite" // the "sqlite" driver for -results-db
 )
 
 const banner = `
@@ -26,15 +27,73 @@ const banner = `
 
 Doctrine-Driven RTS Intelligence`
 
-var (
-	directive string
-	addr      string
-)
+const usage = `usage: vimy <command> [flags]
+
+commands:
+  serve     run the AI for the OpenRA mod (default when no command is given)
+  compile   print the rules a doctrine compiles to, with priorities
+  validate  check a rule overrides file against the compiled rules
+  simulate  replay recorded game states through the rule engine offline
+  scenario  run scripted YAML scenarios and check the commands sent
+
+Run "vimy <command> -h" for the command's flags.
+`
 
 func main() {
-	flag.StringVar(&directive, "doctrine", "", "initial doctrine directive (e.g. \"Blitzkrieg\", \"guerrilla warfare\")")
-	flag.StringVar(&addr, "addr", ":8080", "HTTP dashboard listen address")
-	flag.Parse()
+	args := os.Args[1:]
+	cmd := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(args)
+	case "compile":
+		err = runCompile(args)
+	case "validate":
+		err = runValidate(args)
+	case "simulate":
+		err = runSimulate(args)
+	case "scenario":
+		err = runScenario(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "vimy "+cmd+":", err)
+		os.Exit(1)
+	}
+}
+
+// runServe runs the AI brain until interrupted — the binary's original and
+// default behavior.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	directive := fs.String("doctrine", "", "initial doctrine directive (e.g. \"Blitzkrieg\", \"guerrilla warfare\")")
+	addr := fs.String("addr", ":8080", "HTTP dashboard listen address")
+	overridesPath := fs.String("overrides", "", "JSON file of per-rule overrides (disable, priority, thresholds)")
+	categoriesPath := fs.String("category-policies", "", "JSON file of per-category rule limits and tie-breaks (e.g. {\"micro\": {\"max_rules\": 3, \"max_commands\": 20}})")
+	tickBudget := fs.Duration("tick-budget", 0, "time one rule evaluation may take before micro and recon rules are shed for the tick, e.g. 200ms; shedding makes play depend on machine speed (0 disables)")
+	workers := fs.Int("condition-workers", 1, "goroutines evaluating non-exclusive rule conditions each tick (1: serial)")
+	factionPrefsPath := fs.String("faction-prefs", "", "JSON file of per-faction unit preference overrides (e.g. {\"soviet\": {\"vehicle\": [\"tesla_tank\"]}})")
+	policiesPath := fs.String("event-policies", "", "JSON file of per-event-kind strategist trigger policies (severity, cooldown)")
+	opening := fs.String("opening", "", "scripted opening build order ("+strings.Join(rules.OpeningNames(), ", ")+"); empty leaves the opening to the rules")
+	reconnect := fs.Duration("reconnect-window", 30*time.Second, "how long a dropped game session is kept for the mod to reconnect")
+	seed := fs.Int64("seed", 0, "seed for the rule engine's random choices, for reproducible games (0: seed from the clock)")
+	checkpointDir := fs.String("checkpoint-dir", "", "directory to checkpoint game sessions to, so a restarted vimy resumes the match (empty disables)")
+	constraintsPath := fs.String("doctrine-constraints", "", "JSON file pinning or bounding doctrine parameters (e.g. {\"naval_weight\": {\"pin\": 0}})")
+	interval := fs.Int("strategist-interval", 500, "game ticks between scheduled strategist evaluations")
+	cooldown := fs.Int("strategist-cooldown", 100, "minimum game ticks between strategist evaluations triggered by medium-severity events")
+	maxEvals := fs.Int("max-evaluations", 0, "LLM calls allowed per game before falling back to heuristic play (0: unlimited)")
+	tokenBudget := fs.Int64("token-budget", 0, "LLM tokens (input plus output) allowed per game before falling back to heuristic play (0: unlimited)")
+	resultsDB := fs.String("results-db", "", "SQLite database to log every strategist evaluation and its outcome to (empty disables)")
+	resultsDriver := fs.String("results-driver", agent.DefaultResultsDriver, "database/sql driver for -results-db (vimy links the pure-Go \"sqlite\")")
+	fs.Parse(args)
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -43,77 +102,71 @@ func main() {
 
 	fmt.Println(banner)
 
-	slog.Info("starting vimy", "doctrine", directive)
-
-	// Create engine and strategist at top level so the dashboard can access them
-	// before a game connection arrives.
-	engine, err := rules.NewEngine(rules.DefaultRules())
-	if err != nil {
-		slog.Error("failed to create rule engine", "error", err)
-		os.Exit(1)
+	slog.Info("starting vimy", "doctrine", *directive)
+
+	opts := brain.Options{
+		Directive:          *directive,
+		Opening:            *opening,
+		ReconnectWindow:    *reconnect,
+		SocketPath:         brain.DefaultSocketPath,
+		DashboardAddr:      *addr,
+		Seed:               *seed,
+		CheckpointDir:      *checkpointDir,
+		ResultsDB:          *resultsDB,
+		ResultsDriver:      *resultsDriver,
+		StrategistInterval: *interval,
+		StrategistCooldown: *cooldown,
+		LLMBudget:          agent.Budget{MaxEvaluations: *maxEvals, MaxTokens: *tokenBudget},
+		TickBudget:         *tickBudget,
+		ConditionWorkers:   *workers,
 	}
-	slog.Info("rule engine initialized", "rules", len(rules.DefaultRules()))
-
-	var strategist *agent.Strategist
-	if directive != "" {
-		strategist = agent.NewStrategist(engine, directive, 500)
+	if *overridesPath != "" {
+		overrides, err := rules.LoadOverrides(*overridesPath)
+		if err != nil {
+			return err
+		}
+		opts.Overrides = overrides
 	}
-
-	// Start the HTTP dashboard.
-	srv := server.New(strategist)
-	go func() {
-		slog.Info("starting dashboard", "addr", addr)
-		if err := srv.Start(addr); err != nil {
-			slog.Error("dashboard server failed", "error", err)
+	if *categoriesPath != "" {
+		policies, err := rules.LoadCategoryPolicies(*categoriesPath)
+		if err != nil {
+			return err
 		}
-	}()
-
-	const socketPath = "/tmp/vimy.sock"
-
-	// Unix sockets leave behind a file on unclean shutdown; remove it so we can rebind.
-	if err := os.RemoveAll(socketPath); err != nil {
-		slog.Error("failed to clean up socket", "path", socketPath, "error", err)
-		os.Exit(1)
+		opts.CategoryPolicies = policies
+	}
+	if *factionPrefsPath != "" {
+		prefs, err := rules.LoadFactionPreferences(*factionPrefsPath)
+		if err != nil {
+			return err
+		}
+		opts.FactionPreferences = prefs
+	}
+	if *constraintsPath != "" {
+		constraints, err := rules.LoadDoctrineConstraints(*constraintsPath)
+		if err != nil {
+			return err
+		}
+		opts.DoctrineConstraints = constraints
+	}
+	if *policiesPath != "" {
+		policies, err := agent.LoadTriggerPolicies(*policiesPath)
+		if err != nil {
+			return err
+		}
+		opts.EventPolicies = policies
 	}
 
-	listener, err := net.Listen("unix", socketPath)
+	b, err := brain.New(opts)
 	if err != nil {
-		slog.Error("failed to listen on socket", "path", socketPath, "error", err)
-		os.Exit(1)
+		return err
 	}
-	defer listener.Close()
-	defer os.Remove(socketPath)
-
-	slog.Info("listening on domain socket", "path", socketPath)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	go func() {
-		for {
-			conn, err := listener.Accept()
-			if err != nil {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					slog.Error("failed to accept connection", "error", err)
-					continue
-				}
-			}
-			slog.Info("new connection accepted")
-			go handleConn(ctx, conn, engine, strategist)
-		}
-	}()
-
-	<-ctx.Done()
+	if err := b.Run(ctx); err != nil {
+		return err
+	}
 	slog.Info("shutting down")
-}
-
-func handleConn(ctx context.Context, conn net.Conn, engine *rules.Engine, strategist *agent.Strategist) {
-	c := ipc.NewConnection(conn, nil)
-	a := agent.New(c, engine, strategist, ctx)
-	c.RegisterHandler(ipc.TypeHello, a.HandleHello)
-	c.RegisterHandler(ipc.TypeGameState, a.HandleGameState)
-	c.ReadLoop()
+	return nil
 }
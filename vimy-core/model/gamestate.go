@@ -8,9 +8,25 @@ type GameState struct {
 	ProductionQueues []ProductionQueue `json:"productionQueues"`
 	Enemies          []Enemy           `json:"enemies"`
 	Capturables      []Enemy           `json:"capturables"`
-	SupportPowers    []SupportPower    `json:"supportPowers"`
-	MapWidth         int               `json:"mapWidth"`
-	MapHeight        int               `json:"mapHeight"`
+	// Obstacles lists visible destructible neutral actors (trees, walls)
+	// that force-fire can clear.
+	Obstacles     []Obstacle     `json:"obstacles"`
+	SupportPowers []SupportPower `json:"supportPowers"`
+	// EnemySupportPowers lists enemy powers whose timers are visible to us
+	// (e.g. the nuke countdown RA shows to all players).
+	EnemySupportPowers []EnemySupportPower `json:"enemySupportPowers"`
+	MapWidth           int                 `json:"mapWidth"`
+	MapHeight          int                 `json:"mapHeight"`
+	// SpawnPoints lists the map's starting locations. Ours is flagged Own;
+	// the rest are where enemies may have started.
+	SpawnPoints []SpawnPoint `json:"spawnPoints"`
+}
+
+// SpawnPoint is a map starting location.
+type SpawnPoint struct {
+	X   int  `json:"x"`
+	Y   int  `json:"y"`
+	Own bool `json:"own"`
 }
 
 type Player struct {
@@ -32,6 +48,7 @@ type Unit struct {
 	MaxHP      int    `json:"maxHp"`
 	Idle       bool   `json:"idle"`
 	CargoCount int    `json:"cargoCount"`
+	Veterancy  int    `json:"veterancy"` // experience level, 0 = rookie
 }
 
 func (u Unit) TypeName() string { return u.Type }
@@ -47,7 +64,20 @@ type Building struct {
 
 func (b Building) TypeName() string { return b.Type }
 
+// Obstacle is a destructible neutral actor that can block a route.
+type Obstacle struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	HP    int    `json:"hp"`
+	MaxHP int    `json:"maxHp"`
+}
+
 type ProductionQueue struct {
+	// ActorID is the actor the queue lives on: the producing building, or
+	// the player actor for shared per-player queues.
+	ActorID         int      `json:"actorId"`
 	Type            string   `json:"type"`
 	Items           []string `json:"items"`
 	Buildable       []string `json:"buildable"`
@@ -56,13 +86,14 @@ type ProductionQueue struct {
 }
 
 type Enemy struct {
-	ID    int    `json:"id"`
-	Owner string `json:"owner"`
-	Type  string `json:"type"`
-	X     int    `json:"x"`
-	Y     int    `json:"y"`
-	HP    int    `json:"hp"`
-	MaxHP int    `json:"maxHp"`
+	ID        int    `json:"id"`
+	Owner     string `json:"owner"`
+	Type      string `json:"type"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	HP        int    `json:"hp"`
+	MaxHP     int    `json:"maxHp"`
+	Veterancy int    `json:"veterancy"` // experience level, 0 = rookie
 }
 
 func (e Enemy) TypeName() string { return e.Type }
@@ -73,3 +104,11 @@ type SupportPower struct {
 	RemainingTicks int    `json:"remainingTicks"`
 	TotalTicks     int    `json:"totalTicks"`
 }
+
+type EnemySupportPower struct {
+	Owner          string `json:"owner"`
+	Key            string `json:"key"`
+	Ready          bool   `json:"ready"`
+	RemainingTicks int    `json:"remainingTicks"`
+	TotalTicks     int    `json:"totalTicks"`
+}
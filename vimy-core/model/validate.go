@@ -0,0 +1,131 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidState marks a game state too broken to sanitize.
+var ErrInvalidState = errors.New("invalid game state")
+
+// Sanitize repairs what it can of a game state from the mod so rules never
+// see negative health, duplicate IDs or positions off the map, and rejects
+// the frame (wrapping ErrInvalidState) when it can't. The returned fixes
+// count each kind of repair, e.g. "enemies: off map" → 2.
+//
+// Repairs:
+//   - HP is clamped to [0, MaxHP]. A MaxHP of 0 means the actor has no
+//     health at all and is left alone unless HP says otherwise, in which
+//     case MaxHP becomes HP.
+//   - Later actors reusing an ID already seen in the same list are dropped.
+//   - Our own units and buildings off the map are clamped onto it; enemies,
+//     capturables, obstacles and spawn points there are dropped, since
+//     nothing can be sent to them.
+//   - Negative cash and queue progress outside 0–100 are clamped.
+func (gs *GameState) Sanitize() (map[string]int, error) {
+	if gs.Tick < 0 {
+		return nil, fmt.Errorf("%w: negative tick %d", ErrInvalidState, gs.Tick)
+	}
+	if gs.MapWidth < 0 || gs.MapHeight < 0 {
+		return nil, fmt.Errorf("%w: map size %dx%d", ErrInvalidState, gs.MapWidth, gs.MapHeight)
+	}
+
+	s := sanitizer{gs: gs, fixes: make(map[string]int)}
+	if gs.Player.Cash < 0 {
+		gs.Player.Cash = 0
+		s.fixes["player: negative cash"]++
+	}
+
+	gs.Units = sanitizeActors(&s, "units", gs.Units, false, func(u *Unit) (*int, *int, *int, *int, *int) {
+		return &u.ID, &u.X, &u.Y, &u.HP, &u.MaxHP
+	})
+	gs.Buildings = sanitizeActors(&s, "buildings", gs.Buildings, false, func(b *Building) (*int, *int, *int, *int, *int) {
+		return &b.ID, &b.X, &b.Y, &b.HP, &b.MaxHP
+	})
+	gs.Enemies = sanitizeActors(&s, "enemies", gs.Enemies, true, enemyFields)
+	gs.Capturables = sanitizeActors(&s, "capturables", gs.Capturables, true, enemyFields)
+	gs.Obstacles = sanitizeActors(&s, "obstacles", gs.Obstacles, true, func(o *Obstacle) (*int, *int, *int, *int, *int) {
+		return &o.ID, &o.X, &o.Y, &o.HP, &o.MaxHP
+	})
+
+	spawns := gs.SpawnPoints[:0]
+	for _, sp := range gs.SpawnPoints {
+		if !s.onMap(sp.X, sp.Y) {
+			s.fixes["spawnPoints: off map"]++
+			continue
+		}
+		spawns = append(spawns, sp)
+	}
+	gs.SpawnPoints = spawns
+
+	for i := range gs.ProductionQueues {
+		pq := &gs.ProductionQueues[i]
+		if pq.CurrentProgress < 0 || pq.CurrentProgress > 100 {
+			pq.CurrentProgress = min(max(pq.CurrentProgress, 0), 100)
+			s.fixes["productionQueues: progress out of range"]++
+		}
+	}
+	return s.fixes, nil
+}
+
+type sanitizer struct {
+	gs    *GameState
+	fixes map[string]int
+}
+
+// onMap reports whether (x, y) is a cell on the map. Without a map size
+// every position passes.
+func (s *sanitizer) onMap(x, y int) bool {
+	if s.gs.MapWidth == 0 || s.gs.MapHeight == 0 {
+		return true
+	}
+	return x >= 0 && y >= 0 && x < s.gs.MapWidth && y < s.gs.MapHeight
+}
+
+func enemyFields(e *Enemy) (*int, *int, *int, *int, *int) {
+	return &e.ID, &e.X, &e.Y, &e.HP, &e.MaxHP
+}
+
+// sanitizeActors applies Sanitize's actor repairs to one list. fields
+// exposes an actor's ID, X, Y, HP and MaxHP; dropOffMap chooses between
+// dropping and clamping actors off the map.
+func sanitizeActors[T any](s *sanitizer, list string, actors []T, dropOffMap bool, fields func(*T) (id, x, y, hp, maxHP *int)) []T {
+	seen := make(map[int]bool, len(actors))
+	out := actors[:0]
+	for i := range actors {
+		a := actors[i]
+		id, x, y, hp, maxHP := fields(&a)
+		if seen[*id] {
+			s.fixes[list+": duplicate id"]++
+			continue
+		}
+		seen[*id] = true
+
+		if !s.onMap(*x, *y) {
+			s.fixes[list+": off map"]++
+			if dropOffMap {
+				continue
+			}
+			*x = min(max(*x, 0), s.gs.MapWidth-1)
+			*y = min(max(*y, 0), s.gs.MapHeight-1)
+		}
+
+		if *hp < 0 {
+			*hp = 0
+			s.fixes[list+": negative hp"]++
+		}
+		switch {
+		case *maxHP <= 0 && *hp > 0:
+			*maxHP = *hp
+			s.fixes[list+": missing max hp"]++
+		case *maxHP < 0:
+			*maxHP = 0
+			s.fixes[list+": missing max hp"]++
+		case *hp > *maxHP:
+			*hp = *maxHP
+			s.fixes[list+": hp above max"]++
+		}
+		out = append(out, a)
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizeRepairsActors(t *testing.T) {
+	gs := GameState{
+		Tick:      100,
+		MapWidth:  64,
+		MapHeight: 64,
+		Player:    Player{Cash: -50},
+		Units: []Unit{
+			{ID: 1, X: 10, Y: 10, HP: -5, MaxHP: 100},
+			{ID: 1, X: 11, Y: 11, HP: 50, MaxHP: 100},
+			{ID: 2, X: 70, Y: -3, HP: 80, MaxHP: 0},
+			{ID: 3, X: 5, Y: 5, HP: 0, MaxHP: 0},
+		},
+		Buildings: []Building{{ID: 10, X: 20, Y: 20, HP: 900, MaxHP: 800}},
+		Enemies: []Enemy{
+			{ID: 20, X: 30, Y: 30, HP: 100, MaxHP: 100},
+			{ID: 21, X: 64, Y: 30, HP: 100, MaxHP: 100},
+		},
+		SpawnPoints:      []SpawnPoint{{X: 8, Y: 8}, {X: -1, Y: 8}},
+		ProductionQueues: []ProductionQueue{{Type: "Infantry", CurrentProgress: 140}},
+	}
+
+	fixes, err := gs.Sanitize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gs.Player.Cash != 0 {
+		t.Errorf("cash = %d, want 0", gs.Player.Cash)
+	}
+	if len(gs.Units) != 3 {
+		t.Fatalf("units = %d, want duplicate dropped", len(gs.Units))
+	}
+	if u := gs.Units[0]; u.HP != 0 || u.X != 10 {
+		t.Errorf("unit 1 = %+v, want first copy kept with hp 0", u)
+	}
+	if u := gs.Units[1]; u.X != 63 || u.Y != 0 || u.MaxHP != 80 {
+		t.Errorf("unit 2 = %+v, want clamped to (63,0) with max hp 80", u)
+	}
+	if u := gs.Units[2]; u.HP != 0 || u.MaxHP != 0 {
+		t.Errorf("unit 3 = %+v, want healthless actor untouched", u)
+	}
+	if b := gs.Buildings[0]; b.HP != 800 {
+		t.Errorf("building hp = %d, want clamped to 800", b.HP)
+	}
+	if len(gs.Enemies) != 1 || gs.Enemies[0].ID != 20 {
+		t.Errorf("enemies = %+v, want off-map enemy dropped", gs.Enemies)
+	}
+	if len(gs.SpawnPoints) != 1 {
+		t.Errorf("spawn points = %+v, want off-map spawn dropped", gs.SpawnPoints)
+	}
+	if p := gs.ProductionQueues[0].CurrentProgress; p != 100 {
+		t.Errorf("progress = %d, want 100", p)
+	}
+
+	want := map[string]int{
+		"player: negative cash":                   1,
+		"units: negative hp":                      1,
+		"units: duplicate id":                     1,
+		"units: off map":                          1,
+		"units: missing max hp":                   1,
+		"buildings: hp above max":                 1,
+		"enemies: off map":                        1,
+		"spawnPoints: off map":                    1,
+		"productionQueues: progress out of range": 1,
+	}
+	for k, n := range want {
+		if fixes[k] != n {
+			t.Errorf("fixes[%q] = %d, want %d", k, fixes[k], n)
+		}
+	}
+	if len(fixes) != len(want) {
+		t.Errorf("fixes = %v, want exactly %v", fixes, want)
+	}
+}
+
+func TestSanitizeCleanStateUnchanged(t *testing.T) {
+	gs := GameState{
+		Tick:      1,
+		MapWidth:  32,
+		MapHeight: 32,
+		Units:     []Unit{{ID: 1, X: 0, Y: 31, HP: 50, MaxHP: 50}},
+	}
+	fixes, err := gs.Sanitize()
+	if err != nil || len(fixes) != 0 {
+		t.Errorf("Sanitize = %v, %v; want no fixes", fixes, err)
+	}
+}
+
+func TestSanitizeWithoutMapSizeKeepsPositions(t *testing.T) {
+	gs := GameState{Enemies: []Enemy{{ID: 1, X: 500, Y: 500, HP: 1, MaxHP: 1}}}
+	if _, err := gs.Sanitize(); err != nil {
+		t.Fatal(err)
+	}
+	if len(gs.Enemies) != 1 {
+		t.Error("enemy dropped with no map size to check against")
+	}
+}
+
+func TestSanitizeRejects(t *testing.T) {
+	for _, gs := range []GameState{
+		{Tick: -1},
+		{Tick: 5, MapWidth: -1, MapHeight: 64},
+	} {
+		if _, err := gs.Sanitize(); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("Sanitize(tick %d, map %dx%d) err = %v, want ErrInvalidState", gs.Tick, gs.MapWidth, gs.MapHeight, err)
+		}
+	}
+}
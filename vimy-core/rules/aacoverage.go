@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// AA coverage. An AA site protects what lies within its weapon range, so
+// where it stands matters more than how many there are: three SAMs on the
+// perimeter annulus defenseHint favours can leave the construction yard
+// open. aaHint places each new AA structure to cover the valuable
+// buildings with the least AA over them, leaning toward the side enemy
+// aircraft will come from.
+
+const (
+	aaDefaultRadius = 8.0 // cells covered by an AA structure missing from the unit table
+	aaApproachBias  = 0.3 // score bonus at full alignment with the enemy's direction
+	aaMinClearance  = 2   // cells to keep between a new AA site and any building
+)
+
+// aaRadius returns the cells an AA structure of type t covers.
+func aaRadius(t string) float64 {
+	if u, ok := LookupUnit(t); ok && u.Range > 0 {
+		return u.Range
+	}
+	return aaDefaultRadius
+}
+
+// aaSite is an AA structure's position and reach.
+type aaSite struct {
+	x, y   int
+	radius float64
+}
+
+func (s aaSite) covers(x, y int) bool {
+	dx, dy := float64(x-s.x), float64(y-s.y)
+	return dx*dx+dy*dy <= s.radius*s.radius
+}
+
+// ownAACoverage counts the AA sites over each high-value building.
+func (e RuleEnv) ownAACoverage() (hv []model.Building, covered []int, sites []aaSite) {
+	for _, b := range e.State.Buildings {
+		if e.defenseRole(b.Type) == "aa_defense" {
+			sites = append(sites, aaSite{b.X, b.Y, aaRadius(b.Type)})
+		}
+	}
+	for _, b := range e.State.Buildings {
+		if !isHighValueBuilding(b.Type) {
+			continue
+		}
+		n := 0
+		for _, s := range sites {
+			if s.covers(b.X, b.Y) {
+				n++
+			}
+		}
+		hv = append(hv, b)
+		covered = append(covered, n)
+	}
+	return hv, covered, sites
+}
+
+// aaHint returns where to place AA structure item: around the least
+// covered high-value buildings, at the spot that brings the most building
+// value under its first (or next) layer of cover, with ties broken toward
+// the nearest enemy base. ok is false with no high-value buildings to
+// protect.
+func aaHint(env RuleEnv, item string) (x, y int, ok bool) {
+	hv, covered, _ := env.ownAACoverage()
+	if len(hv) == 0 {
+		return 0, 0, false
+	}
+	least := covered[0]
+	for _, n := range covered {
+		least = min(least, n)
+	}
+
+	// Direction aircraft will come from: the nearest known enemy base.
+	var ax, ay float64
+	if base := env.NearestEnemyBase(); base != nil {
+		cx, cy, _ := baseRing(env.State.Buildings)
+		dx, dy := float64(base.X-cx), float64(base.Y-cy)
+		if d := math.Hypot(dx, dy); d > 0 {
+			ax, ay = dx/d, dy/d
+		}
+	}
+
+	total := 0.0
+	for i, b := range hv {
+		if covered[i] == least {
+			total += float64(max(costOf(b.Type), 1))
+		}
+	}
+
+	site := aaSite{radius: aaRadius(item)}
+	best := math.Inf(-1)
+	for i, b := range hv {
+		if covered[i] != least {
+			continue
+		}
+		// Candidates ring the building at half the AA radius, so the site
+		// also reaches what stands beside it.
+		for k := range 8 {
+			angle := float64(k) * math.Pi / 4
+			site.x = b.X + int(math.Round(site.radius/2*math.Cos(angle)))
+			site.y = b.Y + int(math.Round(site.radius/2*math.Sin(angle)))
+			if !env.aaPlaceable(site.x, site.y) {
+				continue
+			}
+			// Share of the least-covered building value this site would cover.
+			score := 0.0
+			for j, o := range hv {
+				if covered[j] == least && site.covers(o.X, o.Y) {
+					score += float64(max(costOf(o.Type), 1)) / total
+				}
+			}
+			score += aaApproachBias * (math.Cos(angle)*ax + math.Sin(angle)*ay)
+			if score > best {
+				best, x, y, ok = score, site.x, site.y, true
+			}
+		}
+	}
+	return x, y, ok
+}
+
+// aaPlaceable reports whether (x, y) is open land clear of our buildings.
+func (e RuleEnv) aaPlaceable(x, y int) bool {
+	if e.Terrain != nil {
+		if t := e.Terrain.AtMapPos(x, y); t != model.Land && t != model.Bridge {
+			return false
+		}
+	}
+	for _, b := range e.State.Buildings {
+		if abs(b.X-x) < aaMinClearance && abs(b.Y-y) < aaMinClearance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestAAHintCoversUncoveredBuildings(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{Buildings: []model.Building{
+			{ID: 1, Type: "fact", X: 20, Y: 20},
+			{ID: 2, Type: "proc", X: 50, Y: 20},
+			{ID: 3, Type: "sam", X: 52, Y: 22}, // already over the refinery
+		}},
+		Memory: make(map[string]any),
+	}
+	x, y, ok := aaHint(env, "sam")
+	if !ok {
+		t.Fatal("aaHint found no spot")
+	}
+	if !(aaSite{x, y, aaRadius("sam")}).covers(20, 20) {
+		t.Errorf("aaHint = %d,%d; want a spot covering the uncovered construction yard", x, y)
+	}
+	if abs(x-20) < aaMinClearance && abs(y-20) < aaMinClearance {
+		t.Errorf("aaHint = %d,%d: on top of the construction yard", x, y)
+	}
+}
+
+func TestAAHintLeansTowardEnemy(t *testing.T) {
+	mem := make(map[string]any)
+	enemyBasesMemory.set(mem, map[string]EnemyBaseIntel{"enemy": {Owner: "enemy", X: 90, Y: 20, FromBuildings: true}})
+	env := RuleEnv{
+		State:  model.GameState{Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}}},
+		Memory: mem,
+	}
+	x, y, ok := aaHint(env, "agun")
+	if !ok || x <= 20 || y != 20 {
+		t.Errorf("aaHint = %d,%d,%v; want east of the yard, toward the enemy", x, y, ok)
+	}
+}
+
+func TestAAHintWithoutHighValueBuildings(t *testing.T) {
+	env := RuleEnv{
+		State:  model.GameState{Buildings: []model.Building{{ID: 1, Type: "powr", X: 20, Y: 20}}},
+		Memory: make(map[string]any),
+	}
+	if _, _, ok := aaHint(env, "sam"); ok {
+		t.Error("aaHint placed AA with nothing worth covering")
+	}
+}
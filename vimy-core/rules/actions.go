@@ -3,7 +3,6 @@ package rules
 import (
 	"log/slog"
 	"math"
-	"math/rand"
 	"slices"
 	"strings"
 
@@ -20,18 +19,37 @@ func ActionProduceMCV(env RuleEnv, conn *ipc.Connection) error {
 	})
 }
 
+// relocationArrivalDist is how close (in cells) the MCV must get to its
+// relocation target before deploying; relocationTimeout abandons the move
+// and deploys wherever the MCV is if it can't get there.
+const (
+	relocationArrivalDist = 5
+	relocationTimeout     = 1500
+)
+
+// ActionDeployMCV deploys an idle MCV, or moves it to the relocation site
+// first. Rules running it need a cooldown (deployMCVCooldown): the C# side
+// needs time to process the deploy order, and without one the sidecar
+// would spam deploy commands every tick.
 func ActionDeployMCV(env RuleEnv, conn *ipc.Connection) error {
-	// Cooldown: the C# side needs time to process the deploy order.
-	// Without this, the sidecar would spam deploy commands every tick.
-	if lastTick, ok := env.Memory["deployMCVTick"].(int); ok {
-		if env.State.Tick-lastTick < 50 {
-			return nil
-		}
+	rel := getRelocation(env.Memory)
+	if rel != nil && env.State.Tick-rel.Tick > relocationTimeout {
+		slog.Warn("base relocation timed out, deploying in place", "target_x", rel.X, "target_y", rel.Y)
+		relocationMemory.clear(env.Memory)
+		rel = nil
 	}
-	for _, u := range env.State.Units {
-		if matchesType(u.Type, MCV) && u.Idle {
+	for _, u := range env.units().OfType(MCV) {
+		if u.Idle {
+			if rel != nil && math.Hypot(float64(u.X-rel.X), float64(u.Y-rel.Y)) > relocationArrivalDist {
+				slog.Debug("moving MCV to relocation site", "id", u.ID, "x", rel.X, "y", rel.Y)
+				return conn.Send(ipc.TypeMove, ipc.MoveCommand{
+					ActorID: uint32(u.ID),
+					X:       rel.X,
+					Y:       rel.Y,
+				})
+			}
 			slog.Debug("deploying MCV", "id", u.ID)
-			env.Memory["deployMCVTick"] = env.State.Tick
+			relocationMemory.clear(env.Memory)
 			return conn.Send(ipc.TypeDeploy, ipc.DeployCommand{
 				ActorID: uint32(u.ID),
 			})
@@ -40,6 +58,47 @@ func ActionDeployMCV(env RuleEnv, conn *ipc.Connection) error {
 	return nil
 }
 
+// ActionRelocateBase starts moving the base to the safest map quadrant when
+// the construction yard is about to fall. A spare MCV is sent ahead if we
+// have one; otherwise the construction yard itself is undeployed (requires
+// redeployable MCVs to be enabled in the game options). ActionDeployMCV
+// then drives the MCV to the target and deploys it.
+func ActionRelocateBase(env RuleEnv, conn *ipc.Connection) error {
+	x, y := env.SafestQuadrant()
+	bx, by := env.BuildingCentroid()
+	if math.Hypot(float64(x-bx), float64(y-by)) <= relocationArrivalDist {
+		return nil // already in the safest quadrant — nowhere better to go
+	}
+
+	relocationMemory.set(env.Memory, &relocation{X: x, Y: y, Tick: env.State.Tick})
+
+	if mcvs := env.units().OfType(MCV); len(mcvs) > 0 {
+		u := mcvs[0]
+		slog.Info("relocating base with spare MCV", "id", u.ID, "x", x, "y", y)
+		return conn.Send(ipc.TypeMove, ipc.MoveCommand{
+			ActorID: uint32(u.ID),
+			X:       x,
+			Y:       y,
+		})
+	}
+
+	var yard *model.Building
+	for i := range env.State.Buildings {
+		if matchesType(env.State.Buildings[i].Type, ConstructionYard) {
+			yard = &env.State.Buildings[i]
+			break
+		}
+	}
+	if yard == nil {
+		relocationMemory.clear(env.Memory)
+		return nil
+	}
+	slog.Info("undeploying construction yard to relocate base", "id", yard.ID, "hp", yard.HP, "x", x, "y", y)
+	return conn.Send(ipc.TypeUndeploy, ipc.UndeployCommand{
+		ActorID: uint32(yard.ID),
+	})
+}
+
 func ActionProducePowerPlant(env RuleEnv, conn *ipc.Connection) error {
 	item := env.BuildableType("power_plant")
 	if item == "" {
@@ -160,14 +219,81 @@ func ActionCancelStuckAircraft(env RuleEnv, conn *ipc.Connection) error {
 	return nil
 }
 
+// ActionClearFactoryExits pushes units jammed at a factory exit out along
+// the threat axis and moves that factory's rally point there, so new units
+// don't pile onto the same blocked cells. Its rule is throttled to let
+// orders play out.
+func ActionClearFactoryExits(env RuleEnv, conn *ipc.Connection) error {
+	watches := getExitWatches(env.Memory)
+	factories := make(map[int]model.Building)
+	for _, b := range env.State.Buildings {
+		factories[b.ID] = b
+	}
+	rallied := make(map[int]bool)
+	for _, id := range env.UnitsStuckAtFactory() {
+		f, ok := factories[watches[id].Factory]
+		if !ok {
+			continue
+		}
+		w, h := footprintOf(f.Type)
+		fx, fy := f.X+w/2, f.Y+h
+		tx, ty := env.threatAxis(fx, fy)
+		if tx == 0 && ty == 0 {
+			ty = 1 // exits face south
+		}
+		x := fx + int(math.Round(tx*factoryClearDistance))
+		y := fy + int(math.Round(ty*factoryClearDistance))
+		if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(id), X: x, Y: y}); err != nil {
+			return err
+		}
+		if !rallied[f.ID] {
+			rallied[f.ID] = true
+			slog.Info("clearing blocked factory exit", "factory", f.Type, "id", f.ID, "x", x, "y", y)
+			if err := conn.Send(ipc.TypeSetRally, ipc.SetRallyCommand{ActorID: uint32(f.ID), X: x, Y: y}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ActionRecoverStuckUnits nudges units that have stopped moving despite
+// orders, re-pathing them home after repeated failed nudges. Each recovery
+// is counted against the spot so chronic chokepoints are kept clear of
+// buildings.
+func ActionRecoverStuckUnits(env RuleEnv, conn *ipc.Connection) error {
+	hist := getUnitHistory(env.Memory)
+	pos := env.units().ByID()
+	for _, id := range env.StuckUnits() {
+		u, ok := pos[id]
+		h := hist[id]
+		if !ok || h == nil {
+			continue
+		}
+		x, y, repath := env.stuckRecoveryTarget(u, h)
+		slog.Info("recovering stuck unit", "id", id, "type", u.Type, "x", u.X, "y", u.Y, "nudges", h.Nudges, "repath", repath)
+		if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(id), X: x, Y: y}); err != nil {
+			return err
+		}
+		recordStuckSpot(env.Memory, u.X, u.Y)
+		h.Nudges++
+		h.Since = env.State.Tick
+	}
+	return nil
+}
+
 func ActionPlaceBuilding(env RuleEnv, conn *ipc.Connection) error {
 	for _, pq := range env.State.ProductionQueues {
 		if strings.EqualFold(pq.Type, QueueBuilding) && pq.CurrentItem != "" && pq.CurrentProgress >= 100 {
-			slog.Debug("placing building", "item", pq.CurrentItem)
-			return conn.Send(ipc.TypePlaceBuilding, ipc.PlaceBuildingCommand{
+			cmd := ipc.PlaceBuildingCommand{
 				Queue: QueueBuilding,
 				Item:  pq.CurrentItem,
-			})
+			}
+			if x, y, ok := placementHint(env, pq.CurrentItem); ok {
+				cmd.HintX, cmd.HintY = x, y
+			}
+			slog.Debug("placing building", "item", pq.CurrentItem, "hint_x", cmd.HintX, "hint_y", cmd.HintY)
+			return conn.Send(ipc.TypePlaceBuilding, cmd)
 		}
 	}
 	return nil
@@ -175,11 +301,7 @@ func ActionPlaceBuilding(env RuleEnv, conn *ipc.Connection) error {
 
 func ActionProduceInfantry(env RuleEnv, conn *ipc.Connection) error {
 	slog.Debug("producing infantry")
-	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
-		Queue: QueueInfantry,
-		Item:  RifleInfantry,
-		Count: 1,
-	})
+	return produceParallel(env, conn, QueueInfantry, env.Mod.basicInfantry())
 }
 
 func ActionProduceVehicle(env RuleEnv, conn *ipc.Connection) error {
@@ -188,11 +310,7 @@ func ActionProduceVehicle(env RuleEnv, conn *ipc.Connection) error {
 		return nil
 	}
 	slog.Debug("producing vehicle", "item", item)
-	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
-		Queue: QueueVehicle,
-		Item:  item,
-		Count: 1,
-	})
+	return produceParallel(env, conn, QueueVehicle, item)
 }
 
 func ActionProduceSpecialistInfantry(env RuleEnv, conn *ipc.Connection) error {
@@ -201,11 +319,7 @@ func ActionProduceSpecialistInfantry(env RuleEnv, conn *ipc.Connection) error {
 		return nil
 	}
 	slog.Debug("producing specialist infantry", "item", item)
-	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
-		Queue: QueueInfantry,
-		Item:  item,
-		Count: 1,
-	})
+	return produceParallel(env, conn, QueueInfantry, item)
 }
 
 func ActionProduceAircraft(env RuleEnv, conn *ipc.Connection) error {
@@ -214,11 +328,7 @@ func ActionProduceAircraft(env RuleEnv, conn *ipc.Connection) error {
 		return nil
 	}
 	slog.Debug("producing aircraft", "item", item)
-	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
-		Queue: QueueAircraft,
-		Item:  item,
-		Count: 1,
-	})
+	return produceParallel(env, conn, QueueAircraft, item)
 }
 
 func ActionProduceShip(env RuleEnv, conn *ipc.Connection) error {
@@ -230,17 +340,25 @@ func ActionProduceShip(env RuleEnv, conn *ipc.Connection) error {
 		return nil
 	}
 	slog.Debug("producing ship", "item", item)
-	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
-		Queue: QueueShip,
-		Item:  item,
-		Count: 1,
-	})
+	return produceParallel(env, conn, QueueShip, item)
 }
 
 func ActionPlaceDefense(env RuleEnv, conn *ipc.Connection) error {
 	for _, pq := range env.State.ProductionQueues {
 		if strings.EqualFold(pq.Type, QueueDefense) && pq.CurrentItem != "" && pq.CurrentProgress >= 100 {
-			hx, hy := defenseHint(env)
+			hx, hy, ok := lostDefenseHint(env, pq.CurrentItem)
+			if !ok && env.defenseRole(pq.CurrentItem) == "aa_defense" {
+				hx, hy, ok = aaHint(env, pq.CurrentItem)
+			}
+			if !ok && env.inRoles(pq.CurrentItem, []string{"gap_generator"}) {
+				hx, hy, ok = gapHint(env)
+			}
+			if !ok && env.defenseRole(pq.CurrentItem) != "aa_defense" && !env.inRoles(pq.CurrentItem, []string{"gap_generator"}) {
+				hx, hy, ok = bridgeHint(env)
+			}
+			if !ok {
+				hx, hy = defenseHint(env)
+			}
 			slog.Debug("placing defense", "item", pq.CurrentItem, "hint_x", hx, "hint_y", hy)
 			return conn.Send(ipc.TypePlaceBuilding, ipc.PlaceBuildingCommand{
 				Queue: QueueDefense,
@@ -253,24 +371,17 @@ func ActionPlaceDefense(env RuleEnv, conn *ipc.Connection) error {
 	return nil
 }
 
-// defenseHint generates a scored placement hint for defense buildings.
-// It evaluates 16 candidate positions around the base perimeter annulus
-// (100%-150% of radius), scores each by four weighted factors, then picks
-// randomly from the top 3 to balance strategic placement with unpredictability.
-func defenseHint(env RuleEnv) (int, int) {
-	buildings := env.State.Buildings
-	if len(buildings) == 0 {
-		return 0, 0
-	}
-
+// baseRing returns the base centroid and radius (distance to the furthest
+// building, at least 3) that the perimeter annulus is measured from.
+func baseRing(buildings []model.Building) (cx, cy int, radius float64) {
 	// Compute base centroid.
 	sumX, sumY := 0, 0
 	for _, b := range buildings {
 		sumX += b.X
 		sumY += b.Y
 	}
-	cx := sumX / len(buildings)
-	cy := sumY / len(buildings)
+	cx = sumX / len(buildings)
+	cy = sumY / len(buildings)
 
 	// Compute base radius from the furthest building.
 	var maxDistSq float64
@@ -281,38 +392,53 @@ func defenseHint(env RuleEnv) (int, int) {
 			maxDistSq = d
 		}
 	}
-	radius := math.Sqrt(maxDistSq)
-	if radius < 3 {
-		radius = 3
+	return cx, cy, max(math.Sqrt(maxDistSq), 3)
+}
+
+// threatDirection returns the unit vector from (cx, cy) toward the nearest
+// known enemy base. ok is false with no enemy base on record.
+func threatDirection(env RuleEnv, cx, cy int) (tx, ty float64, ok bool) {
+	base := env.NearestEnemyBase()
+	if base == nil {
+		return 0, 0, false
+	}
+	dx := float64(base.X - cx)
+	dy := float64(base.Y - cy)
+	d := math.Sqrt(dx*dx + dy*dy)
+	if d == 0 {
+		return 0, 0, false
 	}
+	return dx / d, dy / d, true
+}
 
-	// Threat direction: unit vector toward nearest known enemy base.
-	var threatX, threatY float64
-	hasThreat := false
-	if base := env.NearestEnemyBase(); base != nil {
-		dx := float64(base.X - cx)
-		dy := float64(base.Y - cy)
-		d := math.Sqrt(dx*dx + dy*dy)
-		if d > 0 {
-			threatX = dx / d
-			threatY = dy / d
-			hasThreat = true
-		}
+// highValueTypes are the buildings defenses are placed to protect.
+var highValueTypes = []string{
+	ConstructionYard, Refinery, WarFactory,
+	AlliedTechCenter, SovietTechCenter,
+	MissileSilo, IronCurtain, Airfield, Helipad,
+}
+
+func isHighValueBuilding(t string) bool {
+	return slices.ContainsFunc(highValueTypes, func(hv string) bool { return matchesType(t, hv) })
+}
+
+// defenseHint generates a scored placement hint for defense buildings.
+// It evaluates 16 candidate positions around the base perimeter annulus
+// (100%-150% of radius), scores each by four weighted factors, then picks
+// randomly from the top 3 to balance strategic placement with unpredictability.
+func defenseHint(env RuleEnv) (int, int) {
+	buildings := env.State.Buildings
+	if len(buildings) == 0 {
+		return 0, 0
 	}
+	cx, cy, radius := baseRing(buildings)
+	threatX, threatY, hasThreat := threatDirection(env, cx, cy)
 
 	// High-value building positions.
-	highValueTypes := []string{
-		ConstructionYard, Refinery, WarFactory,
-		AlliedTechCenter, SovietTechCenter,
-		MissileSilo, IronCurtain, Airfield, Helipad,
-	}
 	var hvBuildings []model.Building
 	for _, b := range buildings {
-		for _, t := range highValueTypes {
-			if matchesType(b.Type, t) {
-				hvBuildings = append(hvBuildings, b)
-				break
-			}
+		if isHighValueBuilding(b.Type) {
+			hvBuildings = append(hvBuildings, b)
 		}
 	}
 
@@ -334,9 +460,10 @@ func defenseHint(env RuleEnv) (int, int) {
 		score float64
 	}
 	var candidates []candidate
+	rng := env.random()
 	for i := range 16 {
 		angle := float64(i) * 2 * math.Pi / 16
-		r := radius * (1.0 + rand.Float64()*0.5)
+		r := radius * (1.0 + rng.Float64()*0.5)
 		x := cx + int(r*math.Cos(angle))
 		y := cy + int(r*math.Sin(angle))
 
@@ -428,7 +555,7 @@ func defenseHint(env RuleEnv) (int, int) {
 	})
 
 	top := min(3, len(candidates))
-	pick := candidates[rand.Intn(top)]
+	pick := candidates[rng.Intn(top)]
 	return pick.x, pick.y
 }
 
@@ -465,6 +592,32 @@ func ActionProduceDefense(env RuleEnv, conn *ipc.Connection) error {
 	})
 }
 
+// rushDefenseRoles are the cheap static defenses thrown up against a rush,
+// in order of preference.
+var rushDefenseRoles = []string{"pillbox", "flame_tower", "turret"}
+
+// ActionProduceRushDefense queues the first buildable cheap defense.
+func ActionProduceRushDefense(env RuleEnv, conn *ipc.Connection) error {
+	for _, role := range rushDefenseRoles {
+		if item := env.BuildableType(role); item != "" {
+			slog.Info("rush defense queued", "item", item)
+			return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
+				Queue: QueueDefense,
+				Item:  item,
+				Count: 1,
+			})
+		}
+	}
+	return nil
+}
+
+// ActionHoldExpansion deliberately does nothing: its rule holds the
+// exclusive economy category so lower-priority build rules can't spend.
+func ActionHoldExpansion(env RuleEnv, conn *ipc.Connection) error {
+	slog.Debug("holding expansion during rush")
+	return nil
+}
+
 func ActionProduceAADefense(env RuleEnv, conn *ipc.Connection) error {
 	item := env.BuildableType("aa_defense")
 	if item == "" {
@@ -669,7 +822,37 @@ func ActionDefendBase(env RuleEnv, conn *ipc.Connection) error {
 	for i, u := range idle {
 		ids[i] = uint32(u.ID)
 	}
-	slog.Debug("defending base", "count", len(ids), "target", enemy.ID)
+	env.holdUnits(ids)
+	x, y := enemy.X, enemy.Y
+	if rx, ry, ok := env.incomingRally(); ok {
+		x, y = rx, ry
+	}
+	slog.Debug("defending base", "count", len(ids), "target", enemy.ID, "x", x, "y", y)
+	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+		ActorIDs: ids,
+		X:        x,
+		Y:        y,
+	})
+}
+
+// ActionScrambleBaseDefense attack-moves every idle ground unit, squad
+// members and other owned units included, at the enemy nearest the base.
+// Losing the base outranks any task the units were holding for.
+func ActionScrambleBaseDefense(env RuleEnv, conn *ipc.Connection) error {
+	enemy := env.NearestEnemy()
+	if enemy == nil {
+		return nil
+	}
+	idle := env.AllIdleGroundUnits()
+	if len(idle) == 0 {
+		return nil
+	}
+	ids := make([]uint32, len(idle))
+	for i, u := range idle {
+		ids[i] = uint32(u.ID)
+	}
+	env.holdUnits(ids)
+	slog.Debug("scrambling base defense", "count", len(ids), "target", enemy.ID)
 	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 		ActorIDs: ids,
 		X:        enemy.X,
@@ -693,6 +876,7 @@ func ActionEmergencyDefendBase(env RuleEnv, conn *ipc.Connection) error {
 	for i, u := range nearby {
 		ids[i] = uint32(u.ID)
 	}
+	env.holdUnits(ids)
 	slog.Info("emergency base defense — recalling nearby units", "count", len(ids), "target", enemy.ID)
 	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 		ActorIDs: ids,
@@ -722,13 +906,37 @@ func ActionNavalDefendBase(env RuleEnv, conn *ipc.Connection) error {
 	})
 }
 
+// ActionDefendCoast attack-moves idle naval units at the enemy ship nearest
+// our coastal buildings — the naval threat, not whatever enemy is closest.
+func ActionDefendCoast(env RuleEnv, conn *ipc.Connection) error {
+	enemy := env.NearestNavalThreat()
+	if enemy == nil {
+		return nil
+	}
+	idle := env.IdleNavalUnits()
+	if len(idle) == 0 {
+		return nil
+	}
+	ids := make([]uint32, len(idle))
+	for i, u := range idle {
+		ids[i] = uint32(u.ID)
+	}
+	env.holdUnits(ids)
+	slog.Debug("defending coast", "count", len(ids), "target", enemy.ID, "type", enemy.Type)
+	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+		ActorIDs: ids,
+		X:        enemy.X,
+		Y:        enemy.Y,
+	})
+}
+
 func ActionAirDefendBase(env RuleEnv, conn *ipc.Connection) error {
 	enemy := env.NearestEnemy()
 	if enemy == nil {
 		return nil
 	}
 	for _, u := range env.IdleCombatAircraft() {
-		slog.Debug("air defend", "aircraft", u.ID, "target", enemy.ID)
+		env.unitDebug(u.ID, "air defend", "aircraft", u.ID, "target", enemy.ID)
 		if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
 			ActorID:  uint32(u.ID),
 			TargetID: uint32(enemy.ID),
@@ -741,7 +949,7 @@ func ActionAirDefendBase(env RuleEnv, conn *ipc.Connection) error {
 
 func ActionRepairDamagedBuildings(env RuleEnv, conn *ipc.Connection) error {
 	for _, b := range env.DamagedBuildings() {
-		slog.Debug("repairing building", "id", b.ID, "type", b.Type)
+		env.unitDebug(b.ID, "repairing building", "id", b.ID, "type", b.Type)
 		if err := conn.Send(ipc.TypeRepairBuilding, ipc.RepairBuildingCommand{
 			ActorID: uint32(b.ID),
 		}); err != nil {
@@ -752,32 +960,52 @@ func ActionRepairDamagedBuildings(env RuleEnv, conn *ipc.Connection) error {
 }
 
 func ActionScoutWithIdleUnits(env RuleEnv, conn *ipc.Connection) error {
-	waypoints := generateWaypoints(env.State.MapWidth, env.State.MapHeight, env.Terrain)
-	if len(waypoints) == 0 {
+	idle := env.IdleGroundUnits()
+	if len(idle) == 0 {
 		return nil
 	}
-
-	idx, _ := env.Memory["scoutWaypointIdx"].(int)
-	wp := waypoints[idx%len(waypoints)]
-
-	idle := env.IdleGroundUnits()
 	n := min(2, len(idle))
 	ids := make([]uint32, n)
 	for i := range n {
 		ids[i] = uint32(idle[i].ID)
 	}
 
-	slog.Debug("scouting with idle units", "count", n, "waypoint", wp, "wpIdx", idx%len(waypoints))
-
-	env.Memory["scoutWaypointIdx"] = (idx + 1) % len(waypoints)
+	task, ok := env.nextScoutZone(idle[0].X, idle[0].Y)
+	if !ok {
+		// No coverage map yet — fall back to the fixed search pattern.
+		waypoints := env.scoutWaypoints()
+		if len(waypoints) == 0 {
+			return nil
+		}
+		idx, _ := scoutWaypointMemory.get(env.Memory)
+		wp := waypoints[idx%len(waypoints)]
+		scoutWaypointMemory.set(env.Memory, (idx+1)%len(waypoints))
+		task = scoutTask{X: wp[0], Y: wp[1]}
+	} else {
+		for i := range n {
+			assignScoutTask(env.Memory, idle[i].ID, task)
+		}
+	}
 
+	env.holdUnits(ids)
+	slog.Debug("scouting with idle units", "count", n, "x", task.X, "y", task.Y)
 	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 		ActorIDs: ids,
-		X:        wp[0],
-		Y:        wp[1],
+		X:        task.X,
+		Y:        task.Y,
 	})
 }
 
+// scoutWaypoints is the fixed scouting rotation: enemy spawn points first,
+// then the generic search pattern.
+func (e RuleEnv) scoutWaypoints() [][2]int {
+	var out [][2]int
+	for _, sp := range e.enemySpawns() {
+		out = append(out, [2]int{sp.X, sp.Y})
+	}
+	return append(out, generateWaypoints(e.State.MapWidth, e.State.MapHeight, e.Terrain)...)
+}
+
 // generateWaypoints creates a 9-point search pattern (center, corners, edges)
 // with a small margin to avoid map-edge pathing issues. When a terrain grid is
 // available, waypoints in Water or Cliff zones are filtered out so ground
@@ -822,29 +1050,36 @@ func generateWaypoints(mapW, mapH int, terrain *model.TerrainGrid) [][2]int {
 	return filtered
 }
 
+// ActionScoutWithRangers sends each idle scout to the stalest reachable zone
+// not already claimed by another scout, falling back to the fixed waypoint
+// rotation until the coverage map exists.
 func ActionScoutWithRangers(env RuleEnv, conn *ipc.Connection) error {
-	waypoints := generateWaypoints(env.State.MapWidth, env.State.MapHeight, env.Terrain)
+	waypoints := env.scoutWaypoints()
 	if len(waypoints) == 0 {
 		return nil
 	}
 
-	idx, _ := env.Memory["rangerScoutIdx"].(int)
-	scouts := env.IdleScouts()
-
-	for _, s := range scouts {
-		wp := waypoints[idx%len(waypoints)]
-		slog.Debug("scout patrolling", "id", s.ID, "type", s.Type, "waypoint", wp, "wpIdx", idx%len(waypoints))
+	idx, _ := rangerScoutMemory.get(env.Memory)
+	for _, s := range env.IdleScouts() {
+		task, ok := env.nextScoutZone(s.X, s.Y)
+		if ok {
+			assignScoutTask(env.Memory, s.ID, task)
+		} else {
+			wp := waypoints[idx%len(waypoints)]
+			task = scoutTask{X: wp[0], Y: wp[1]}
+			idx++
+		}
+		env.unitDebug(s.ID, "scout patrolling", "id", s.ID, "type", s.Type, "x", task.X, "y", task.Y)
 		if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{
 			ActorID: uint32(s.ID),
-			X:       wp[0],
-			Y:       wp[1],
+			X:       task.X,
+			Y:       task.Y,
 		}); err != nil {
 			return err
 		}
-		idx++
 	}
 
-	env.Memory["rangerScoutIdx"] = idx % len(waypoints)
+	rangerScoutMemory.set(env.Memory, idx%len(waypoints))
 	return nil
 }
 
@@ -880,6 +1115,25 @@ func ActionCaptureBuilding(env RuleEnv, conn *ipc.Connection) error {
 	})
 }
 
+// ActionCaptureEnemyProduction sends the nearest idle engineer to capture
+// a damaged enemy production building the attack squad is covering, and
+// holds the squad's fire on it so the prize isn't destroyed first.
+func ActionCaptureEnemyProduction(env RuleEnv, conn *ipc.Connection) error {
+	target := env.EnemyCaptureTarget()
+	engineers := env.IdleEngineers()
+	if target == nil || len(engineers) == 0 {
+		return nil
+	}
+	eng, _ := nearestTo(engineers, target.X, target.Y)
+	captureHoldMemory.set(env.Memory, &captureHold{EnemyID: target.ID, Engineer: eng.ID, Tick: env.State.Tick})
+	slog.Info("capturing enemy production building", "engineer", eng.ID, "target", target.ID, "type", target.Type,
+		"hp_ratio", float64(target.HP)/float64(target.MaxHP))
+	return conn.Send(ipc.TypeCapture, ipc.CaptureCommand{
+		ActorID:  uint32(eng.ID),
+		TargetID: uint32(target.ID),
+	})
+}
+
 func ActionProduceHarvester(env RuleEnv, conn *ipc.Connection) error {
 	slog.Debug("producing harvester")
 	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
@@ -903,7 +1157,7 @@ func ActionSendIdleHarvesters(env RuleEnv, conn *ipc.Connection) error {
 		ty = env.State.Buildings[0].Y
 	}
 	for _, u := range env.IdleHarvesters() {
-		slog.Debug("sending idle harvester", "id", u.ID)
+		env.unitDebug(u.ID, "sending idle harvester", "id", u.ID)
 		if err := conn.Send(ipc.TypeHarvest, ipc.HarvestCommand{
 			ActorID: uint32(u.ID),
 			X:       tx,
@@ -928,6 +1182,7 @@ func ActionAttackMoveIdleGroundUnits(env RuleEnv, conn *ipc.Connection) error {
 	for i, u := range idle {
 		ids[i] = uint32(u.ID)
 	}
+	env.holdUnits(ids)
 	slog.Debug("attack-moving idle ground units", "count", len(ids), "target", enemy.ID)
 	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 		ActorIDs: ids,
@@ -949,12 +1204,13 @@ func ActionAttackKnownBaseGround(env RuleEnv, conn *ipc.Connection) error {
 	for i, u := range idle {
 		ids[i] = uint32(u.ID)
 	}
-	slog.Debug("ground attacking known enemy base", "count", len(ids), "owner", base.Owner, "x", base.X, "y", base.Y)
-	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
-		ActorIDs: ids,
-		X:        base.X,
-		Y:        base.Y,
-	})
+	env.holdUnits(ids)
+	tx, ty := base.X, base.Y
+	if s := env.knownBaseStructure(base.Owner, ids, false); s != nil {
+		tx, ty = s.X, s.Y
+	}
+	slog.Debug("ground attacking known enemy base", "count", len(ids), "owner", base.Owner, "x", tx, "y", ty)
+	return routedAttackMove(env, conn, ids, tx, ty)
 }
 
 func ActionAirAttackEnemy(env RuleEnv, conn *ipc.Connection) error {
@@ -963,7 +1219,7 @@ func ActionAirAttackEnemy(env RuleEnv, conn *ipc.Connection) error {
 		return nil
 	}
 	for _, u := range env.IdleCombatAircraft() {
-		slog.Debug("air attack enemy", "aircraft", u.ID, "target", enemy.ID)
+		env.unitDebug(u.ID, "air attack enemy", "aircraft", u.ID, "target", enemy.ID)
 		if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
 			ActorID:  uint32(u.ID),
 			TargetID: uint32(enemy.ID),
@@ -987,11 +1243,15 @@ func ActionAirAttackKnownBase(env RuleEnv, conn *ipc.Connection) error {
 	for i, u := range aircraft {
 		ids[i] = uint32(u.ID)
 	}
-	slog.Debug("air attacking known enemy base", "count", len(ids), "owner", base.Owner, "x", base.X, "y", base.Y)
+	tx, ty := base.X, base.Y
+	if s := env.knownBaseStructure(base.Owner, ids, true); s != nil {
+		tx, ty = s.X, s.Y
+	}
+	slog.Debug("air attacking known enemy base", "count", len(ids), "owner", base.Owner, "x", tx, "y", ty)
 	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 		ActorIDs: ids,
-		X:        base.X,
-		Y:        base.Y,
+		X:        tx,
+		Y:        ty,
 	})
 }
 
@@ -1029,7 +1289,9 @@ func ActionProduceIronCurtain(env RuleEnv, conn *ipc.Connection) error {
 
 func ActionFireNuke(env RuleEnv, conn *ipc.Connection) error {
 	x, y := 0, 0
-	if base := env.NearestEnemyBase(); base != nil {
+	if t := env.denialTarget(); t != nil {
+		x, y = t.X, t.Y
+	} else if base := env.NearestEnemyBase(); base != nil {
 		x, y = base.X, base.Y
 	} else if enemy := env.NearestEnemy(); enemy != nil {
 		x, y = enemy.X, enemy.Y
@@ -1082,6 +1344,7 @@ func ActionFireParatroopers(env RuleEnv, conn *ipc.Connection) error {
 		return nil // no valid land target
 	}
 	recordSuperweaponFire(env, "paratroopers")
+	recordParadrop(env)
 	slog.Info("firing paratroopers", "x", x, "y", y)
 	return conn.Send(ipc.TypeSupportPower, ipc.SupportPowerCommand{
 		PowerKey: "SovietParatroopers",
@@ -1092,7 +1355,9 @@ func ActionFireParatroopers(env RuleEnv, conn *ipc.Connection) error {
 
 func ActionFireParabombs(env RuleEnv, conn *ipc.Connection) error {
 	x, y := 0, 0
-	if base := env.NearestEnemyBase(); base != nil && env.IsLandAt(base.X, base.Y) {
+	if t := env.denialTarget(); t != nil && env.IsLandAt(t.X, t.Y) {
+		x, y = t.X, t.Y
+	} else if base := env.NearestEnemyBase(); base != nil && env.IsLandAt(base.X, base.Y) {
 		x, y = base.X, base.Y
 	} else if enemy := env.NearestEnemy(); enemy != nil && env.IsLandAt(enemy.X, enemy.Y) {
 		x, y = enemy.X, enemy.Y
@@ -1290,7 +1555,7 @@ func ActionLayMines(env RuleEnv, conn *ipc.Connection) error {
 		assigned[m.ID] = true
 	}
 
-	env.Memory["minelayerAssigned"] = assigned
+	minelayersMemory.set(env.Memory, assigned)
 	return nil
 }
 
@@ -1302,17 +1567,13 @@ func updateMinelayers(env RuleEnv) {
 		return
 	}
 
-	alive := make(map[int]bool)
-	for _, u := range env.State.Units {
-		alive[u.ID] = true
-	}
-
+	units := env.units()
 	for id := range assigned {
-		if !alive[id] {
+		if !units.Has(id) {
 			delete(assigned, id)
 		}
 	}
-	env.Memory["minelayerAssigned"] = assigned
+	minelayersMemory.set(env.Memory, assigned)
 }
 
 func ActionLoadEngineerIntoAPC(env RuleEnv, conn *ipc.Connection) error {
@@ -1348,7 +1609,7 @@ func nearestTo(units []model.Unit, x, y int) (model.Unit, float64) {
 }
 
 func ActionUnloadAPCNearTarget(env RuleEnv, conn *ipc.Connection) error {
-	target := env.NearestCapturable()
+	target := env.captureDestination()
 	if target == nil {
 		return nil
 	}
@@ -1356,6 +1617,9 @@ func ActionUnloadAPCNearTarget(env RuleEnv, conn *ipc.Connection) error {
 	if len(apcs) == 0 {
 		return nil
 	}
+	if t := env.EnemyCaptureTarget(); t != nil && t.ID == target.ID {
+		captureHoldMemory.set(env.Memory, &captureHold{EnemyID: target.ID, Tick: env.State.Tick})
+	}
 	best, dist := nearestTo(apcs, target.X, target.Y)
 	if dist < 5 {
 		slog.Debug("unloading APC near target", "apc", best.ID, "target", target.ID)
@@ -1371,15 +1635,15 @@ func ActionUnloadAPCNearTarget(env RuleEnv, conn *ipc.Connection) error {
 }
 
 // ActionLoadCombatInfantry loads one idle combat infantry into an idle empty
-// APC each tick. Skips squad-assigned infantry so we don't steal from attack squads.
+// APC each tick. Skips owned infantry so we don't steal from attack squads.
 func ActionLoadCombatInfantry(env RuleEnv, conn *ipc.Connection) error {
 	apcs := env.IdleEmptyAPCs()
 	if len(apcs) == 0 {
 		return nil
 	}
-	assigned := squadUnitIDSet(env.Memory)
+	owners := env.unitOwners()
 	for _, u := range env.IdleCombatInfantry() {
-		if assigned[u.ID] {
+		if owners[u.ID] != "" {
 			continue
 		}
 		slog.Debug("loading combat infantry into APC", "infantry", u.ID, "apc", apcs[0].ID)
@@ -1463,6 +1727,7 @@ func GroundAttackGroup(maxUnits int) ActionFunc {
 		for i := range n {
 			ids[i] = uint32(idle[i].ID)
 		}
+		env.holdUnits(ids)
 		slog.Debug("attack-moving ground group", "count", n, "total_idle", len(idle), "target", enemy.ID)
 		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 			ActorIDs: ids,
@@ -1487,6 +1752,7 @@ func GroundAttackKnownBaseGroup(maxUnits int) ActionFunc {
 		for i := range n {
 			ids[i] = uint32(idle[i].ID)
 		}
+		env.holdUnits(ids)
 		slog.Debug("ground attacking known base (group)", "count", n, "total_idle", len(idle), "owner", base.Owner, "x", base.X, "y", base.Y)
 		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 			ActorIDs: ids,
@@ -1509,7 +1775,7 @@ func AirAttackGroup(maxUnits int) ActionFunc {
 		n := min(maxUnits, len(aircraft))
 		for i := range n {
 			u := aircraft[i]
-			slog.Debug("air attack enemy (group)", "aircraft", u.ID, "target", enemy.ID)
+			env.unitDebug(u.ID, "air attack enemy (group)", "aircraft", u.ID, "target", enemy.ID)
 			if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
 				ActorID:  uint32(u.ID),
 				TargetID: uint32(enemy.ID),
@@ -1551,8 +1817,12 @@ func AirAttackKnownBaseGroup(maxUnits int) ActionFunc {
 // orders. Formation and action are separate rules so the compiler can set
 // different priorities and conditions for each (e.g. form at priority+5,
 // act at priority).
-func FormSquad(name, domain string, size int, role string) ActionFunc {
+func FormSquad(name, domain string, baseSize int, role string) ActionFunc {
 	return func(env RuleEnv, conn *ipc.Connection) error {
+		size := baseSize
+		if role == "attack" {
+			size = env.AttackGroupSize(name, baseSize)
+		}
 		var pool []model.Unit
 		switch domain {
 		case "ground":
@@ -1564,6 +1834,9 @@ func FormSquad(name, domain string, size int, role string) ActionFunc {
 		default:
 			pool = env.UnassignedIdleGround()
 		}
+		// Veterans first, so formation and reinforcement keep elite units
+		// together in the squad rather than leaving them in the loose pool.
+		slices.SortStableFunc(pool, func(a, b model.Unit) int { return b.Veterancy - a.Veterancy })
 
 		squads := getSquads(env.Memory)
 		if sq, ok := squads[name]; ok && len(sq.UnitIDs) > 0 {
@@ -1576,7 +1849,7 @@ func FormSquad(name, domain string, size int, role string) ActionFunc {
 			for i := range add {
 				sq.UnitIDs = append(sq.UnitIDs, pool[i].ID)
 			}
-			env.Memory["squads"] = squads
+			env.setSquads(squads)
 			slog.Info("squad reinforced", "name", name, "added", add, "size", len(sq.UnitIDs), "target", sq.TargetSize)
 			return nil
 		}
@@ -1596,12 +1869,64 @@ func FormSquad(name, domain string, size int, role string) ActionFunc {
 			Role:       role,
 			TargetSize: size,
 		}
-		env.Memory["squads"] = squads
+		env.setSquads(squads)
 		slog.Info("squad formed", "name", name, "domain", domain, "role", role, "size", size)
 		return nil
 	}
 }
 
+// ActionAttachMedics assigns idle medics to the infantry squads that need
+// them most, one support slot at a time.
+func ActionAttachMedics(env RuleEnv, conn *ipc.Connection) error {
+	squads := getSquads(env.Memory)
+	for _, m := range env.IdleUnattachedMedics() {
+		sq := env.medicSquad()
+		if sq == nil {
+			break
+		}
+		sq.SupportIDs = append(sq.SupportIDs, m.ID)
+		slog.Info("medic attached to squad", "medic", m.ID, "squad", sq.Name, "support", len(sq.SupportIDs))
+	}
+	env.setSquads(squads)
+	return nil
+}
+
+// ActionMedicsFollowSquads keeps attached medics a few cells behind their
+// squad's centroid, on the side away from the current target. They use Move
+// rather than AttackMove so they trail the fight instead of leading it, and
+// heal whoever falls back to them. Its rule is throttled to avoid
+// re-pathing every tick.
+func ActionMedicsFollowSquads(env RuleEnv, conn *ipc.Connection) error {
+	pos := env.units().ByID()
+	tx, ty, hasTarget := env.routeTarget()
+	for _, sq := range getSquads(env.Memory) {
+		if len(sq.SupportIDs) == 0 {
+			continue
+		}
+		cx, cy, n := squadCentroid(sq, pos)
+		if n == 0 {
+			continue
+		}
+		x, y := cx, cy
+		if hasTarget {
+			if d := math.Hypot(float64(tx-cx), float64(ty-cy)); d > 0 {
+				x = cx - int(math.Round(float64(tx-cx)/d*medicTrailDistance))
+				y = cy - int(math.Round(float64(ty-cy)/d*medicTrailDistance))
+			}
+		}
+		for _, id := range sq.SupportIDs {
+			m, ok := pos[id]
+			if !ok || math.Hypot(float64(m.X-x), float64(m.Y-y)) <= medicTrailDistance {
+				continue
+			}
+			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(id), X: x, Y: y}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // huntBaseState tracks which radial position a squad is cycling through
 // when hunting around an enemy base. Stored in memory per squad name.
 type huntBaseState struct {
@@ -1609,6 +1934,13 @@ type huntBaseState struct {
 	Step         int
 }
 
+func getHuntBase(memory map[string]any) map[string]*huntBaseState {
+	if v, ok := huntBaseMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]*huntBaseState)
+}
+
 // huntOffset converts a hunt step into an (dx, dy) offset from the base centroid.
 // Step 0 returns (0,0) — the centroid itself. Steps 1-16 produce two concentric
 // rings of 8 positions each, spaced 45° apart:
@@ -1645,9 +1977,12 @@ func SquadAttackMove(name string) ActionFunc {
 			return nil
 		}
 
-		slog.Debug("squad attack-move", "squad", name, "count", len(ids), "target", enemy.ID)
+		// Head the target off rather than chase it.
+		x, y := env.squadIntercept(name, *enemy)
+		slog.Debug("squad attack-move", "squad", name, "count", len(ids), "target", enemy.ID, "x", x, "y", y)
+		env.startAttackRun(name, x, y)
 		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
-			ActorIDs: ids, X: enemy.X, Y: enemy.Y,
+			ActorIDs: ids, X: x, Y: y,
 		})
 	}
 }
@@ -1663,9 +1998,20 @@ func SquadAttackKnownBase(name string, aggression float64) ActionFunc {
 			return nil
 		}
 
+		// With the base's buildings on record, go straight for the best
+		// one. The hunt ring is for bases we know only by their centroid.
+		hunts := getHuntBase(env.Memory)
+		sq := getSquads(env.Memory)[name]
+		if s := env.knownBaseStructure(base.Owner, ids, sq != nil && sq.Domain == "air"); s != nil {
+			delete(hunts, name)
+			slog.Debug("squad attacking known structure", "squad", name, "count", len(ids),
+				"owner", s.Owner, "type", s.Type, "id", s.ID, "x", s.X, "y", s.Y)
+			env.startAttackRun(name, s.X, s.Y)
+			return routedAttackMove(env, conn, ids, s.X, s.Y)
+		}
+
 		// Retrieve or initialize hunt state for this squad.
-		memKey := "huntBase:" + name
-		state, _ := env.Memory[memKey].(*huntBaseState)
+		state := hunts[name]
 		if state == nil {
 			state = &huntBaseState{}
 		}
@@ -1697,8 +2043,6 @@ func SquadAttackKnownBase(name string, aggression float64) ActionFunc {
 			ty = max(0, min(ty, env.State.MapHeight-1))
 
 			// Terrain check for ground/naval squads — skip water/cliff.
-			squads := getSquads(env.Memory)
-			sq := squads[name]
 			if sq != nil && sq.Domain != "air" && env.Terrain != nil {
 				t := env.Terrain.AtMapPos(tx, ty)
 				if t != model.Land && t != model.Bridge {
@@ -1710,14 +2054,23 @@ func SquadAttackKnownBase(name string, aggression float64) ActionFunc {
 		slog.Debug("squad attacking known base", "squad", name, "count", len(ids),
 			"owner", base.Owner, "step", state.Step, "x", tx, "y", ty)
 
+		// Only the approach is routed around known threat; hunt steps are
+		// short hops around the base itself.
+		approach := state.Step == 0
+
 		// Advance step: 0→1, 1→2, ..., 16→1 (wrap, skip 0 on subsequent cycles).
 		if state.Step >= 16 {
 			state.Step = 1
 		} else {
 			state.Step++
 		}
-		env.Memory[memKey] = state
+		hunts[name] = state
+		huntBaseMemory.set(env.Memory, hunts)
 
+		env.startAttackRun(name, tx, ty)
+		if approach {
+			return routedAttackMove(env, conn, ids, tx, ty)
+		}
 		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 			ActorIDs: ids, X: tx, Y: ty,
 		})
@@ -1737,11 +2090,16 @@ func SquadDefend(name string) ActionFunc {
 		if len(ids) == 0 {
 			return nil
 		}
-		slog.Debug("squad defending", "squad", name, "count", len(ids), "target", enemy.ID)
+		// Meet a force still on its way at the building it is heading for.
+		x, y := enemy.X, enemy.Y
+		if rx, ry, ok := env.incomingRally(); ok {
+			x, y = rx, ry
+		}
+		slog.Debug("squad defending", "squad", name, "count", len(ids), "target", enemy.ID, "x", x, "y", y)
 		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
 			ActorIDs: ids,
-			X:        enemy.X,
-			Y:        enemy.Y,
+			X:        x,
+			Y:        y,
 		})
 	}
 }
@@ -1752,17 +2110,10 @@ func squadIdleActorIDs(env RuleEnv, name string) []uint32 {
 	if !ok {
 		return nil
 	}
-	idleSet := make(map[int]bool)
-	for _, u := range env.State.Units {
-		if u.Idle {
-			idleSet[u.ID] = true
-		}
-	}
-	retreating := getRetreatingUnits(env.Memory)
+	idleSet := makeUnitIDSet(env.idleUnits())
 	var ids []uint32
 	for _, id := range sq.UnitIDs {
-		_, isRetreating := retreating[id]
-		if idleSet[id] && !isRetreating {
+		if idleSet[id] {
 			ids = append(ids, uint32(id))
 		}
 	}
@@ -1772,9 +2123,9 @@ func squadIdleActorIDs(env RuleEnv, name string) []uint32 {
 // --- Micro action factories ---
 
 // RetreatDamagedUnits sends Move (not AttackMove) for each damaged combat unit.
-// Routes vehicles to the service depot (auto-repair); infantry and others to
-// the base centroid (safety behind defenses). Marks retreating units in memory
-// so focus-fire and squad-attack rules skip them.
+// Queues vehicles for the service depot (see ActionDispatchRepairQueue); others
+// go to the base centroid (safety behind defenses). Marks retreating units in memory
+// so idle-unit selectors (see doNotTask) leave them alone until healed.
 func RetreatDamagedUnits(hpThreshold float64) ActionFunc {
 	return func(env RuleEnv, conn *ipc.Connection) error {
 		units := env.DamagedCombatUnits(hpThreshold)
@@ -1788,38 +2139,56 @@ func RetreatDamagedUnits(hpThreshold float64) ActionFunc {
 
 		depot := env.ServiceDepot()
 		centX, centY := env.BuildingCentroid()
+		queue := getRepairQueue(env.Memory)
 
 		for _, u := range units {
 			if isInfantry(u) {
 				continue // infantry can't heal — no benefit to retreating
 			}
 			if depot != nil && !isAircraft(u) && !isNaval(u) {
-				// Send repair order — unit will enter the depot pad and heal.
-				slog.Debug("retreating damaged unit to depot", "id", u.ID, "type", u.Type,
-					"hp_ratio", float64(u.HP)/float64(u.MaxHP), "depot", depot.ID)
-				if err := conn.Send(ipc.TypeRepairUnit, ipc.RepairUnitCommand{
-					ActorID:          uint32(u.ID),
-					RepairBuildingID: uint32(depot.ID),
-				}); err != nil {
+				// Queue for the depot and wait at the hold point; the repair
+				// queue dispatches a couple of vehicles at a time.
+				hx, hy := env.depotHoldPoint(depot)
+				env.unitDebug(u.ID, "retreating damaged unit to depot queue", "id", u.ID, "type", u.Type,
+					"hp_ratio", float64(u.HP)/float64(u.MaxHP), "depot", depot.ID, "hold_x", hx, "hold_y", hy)
+				if err := retreatMove(env, conn, u, hx, hy); err != nil {
 					return err
 				}
+				queue.enqueue(u.ID)
 			} else {
 				// Fallback: move to centroid (aircraft, naval, or no depot).
-				slog.Debug("retreating damaged unit to centroid", "id", u.ID, "type", u.Type,
+				env.unitDebug(u.ID, "retreating damaged unit to centroid", "id", u.ID, "type", u.Type,
 					"hp_ratio", float64(u.HP)/float64(u.MaxHP), "dest_x", centX, "dest_y", centY)
-				if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{
-					ActorID: uint32(u.ID), X: centX, Y: centY,
-				}); err != nil {
+				if err := retreatMove(env, conn, u, centX, centY); err != nil {
 					return err
 				}
 			}
 			retreating[u.ID] = env.State.Tick
 		}
-		env.Memory["retreatingUnits"] = retreating
+		retreatingMemory.set(env.Memory, retreating)
+		if !queue.empty() {
+			repairQueueMemory.set(env.Memory, queue)
+		}
 		return nil
 	}
 }
 
+// retreatMove sends a damaged unit to (x, y). Ground units detour around
+// hot zones on the way — a retreat through the fight that damaged them
+// defeats the point. Aircraft fly straight home.
+func retreatMove(env RuleEnv, conn *ipc.Connection, u model.Unit, x, y int) error {
+	if !isAircraft(u) {
+		if wx, wy, ok := env.detourWaypoint(u.X, u.Y, x, y); ok {
+			env.unitDebug(u.ID, "retreat detouring around threat", "id", u.ID, "via_x", wx, "via_y", wy)
+			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(u.ID), X: wx, Y: wy}); err != nil {
+				return err
+			}
+			return conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(u.ID), X: x, Y: y, Queued: true})
+		}
+	}
+	return conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(u.ID), X: x, Y: y})
+}
+
 // ClearHealedUnits removes healed, dead, or timed-out units from the
 // retreating set, returning them to the combat pool. The timeout prevents
 // permanent unit leaks when repair fails (e.g. depot destroyed mid-repair).
@@ -1831,25 +2200,128 @@ func ClearHealedUnits(hpThreshold float64) ActionFunc {
 		if len(retreating) == 0 {
 			return nil
 		}
-		aliveIDs := make(map[int]bool)
-		for _, u := range env.State.Units {
-			aliveIDs[u.ID] = true
-			if _, ok := retreating[u.ID]; ok && u.MaxHP > 0 && float64(u.HP)/float64(u.MaxHP) >= hpThreshold {
+		queue := getRepairQueue(env.Memory)
+		units := env.units()
+		for id := range retreating {
+			if u, ok := units.Get(id); ok && u.MaxHP > 0 && float64(u.HP)/float64(u.MaxHP) >= unitRetreatThreshold(u, hpThreshold) {
 				delete(retreating, u.ID)
-				slog.Debug("unit healed, returning to duty", "id", u.ID, "type", u.Type)
+				queue.remove(u.ID)
+				env.unitDebug(u.ID, "unit healed, returning to duty", "id", u.ID, "type", u.Type)
 			}
 		}
 		for id, tick := range retreating {
-			if !aliveIDs[id] || (env.State.Tick-tick > retreatTimeout) {
-				if aliveIDs[id] {
-					slog.Debug("retreat timeout, returning to duty", "id", id, "elapsed", env.State.Tick-tick)
+			alive := units.Has(id)
+			if alive && slices.Contains(queue.Waiting, id) {
+				continue // still in line for the depot — not a failed repair
+			}
+			if !alive || (env.State.Tick-tick > retreatTimeout) {
+				if alive {
+					env.unitDebug(id, "retreat timeout, returning to duty", "id", id, "elapsed", env.State.Tick-tick)
 				}
 				delete(retreating, id)
+				queue.remove(id)
 			}
 		}
-		env.Memory["retreatingUnits"] = retreating
+		retreatingMemory.set(env.Memory, retreating)
+		if queue.empty() {
+			repairQueueMemory.clear(env.Memory)
+		}
+		return nil
+	}
+}
+
+// ActionDispatchRepairQueue sends waiting vehicles to the service depot as
+// slots free up, keeping at most depotSlots there at once. Dispatch resets
+// the unit's retreat clock so the retreat timeout measures the repair itself,
+// not time spent in line. Healed units leave the queue via ClearHealedUnits
+// and, still squad members, are picked up again by squad re-engage. If the
+// depot is lost the queue is dropped and waiting units time out normally.
+func ActionDispatchRepairQueue(env RuleEnv, conn *ipc.Connection) error {
+	queue := getRepairQueue(env.Memory)
+	depot := env.ServiceDepot()
+	if depot == nil {
+		repairQueueMemory.clear(env.Memory)
+		return nil
+	}
+
+	units := env.units()
+	for id := range queue.Active {
+		if !units.Has(id) {
+			delete(queue.Active, id)
+		}
+	}
+	queue.Waiting = slices.DeleteFunc(queue.Waiting, func(id int) bool { return !units.Has(id) })
+
+	retreating := getRetreatingUnits(env.Memory)
+	if retreating == nil {
+		retreating = make(map[int]int)
+	}
+	for len(queue.Active) < depotSlots && len(queue.Waiting) > 0 {
+		id := queue.Waiting[0]
+		queue.Waiting = queue.Waiting[1:]
+		env.unitDebug(id, "dispatching vehicle to depot", "id", id, "depot", depot.ID, "waiting", len(queue.Waiting))
+		if err := conn.Send(ipc.TypeRepairUnit, ipc.RepairUnitCommand{
+			ActorID:          uint32(id),
+			RepairBuildingID: uint32(depot.ID),
+		}); err != nil {
+			return err
+		}
+		queue.Active[id] = env.State.Tick
+		retreating[id] = env.State.Tick
+	}
+	retreatingMemory.set(env.Memory, retreating)
+
+	if queue.empty() {
+		repairQueueMemory.clear(env.Memory)
 		return nil
 	}
+	repairQueueMemory.set(env.Memory, queue)
+	return nil
+}
+
+// ActionEvadeEnemyNuke fans clustered squads out into a ring around their
+// centroid so an incoming nuke can't wipe a whole squad. Its rule is
+// throttled so units aren't re-ordered every tick while the warning is active.
+func ActionEvadeEnemyNuke(env RuleEnv, conn *ipc.Connection) error {
+	pos := env.units().ByID()
+	for _, sq := range env.ClusteredSquads() {
+		cx, cy, n := squadCentroid(sq, pos)
+		i := 0
+		for _, id := range sq.UnitIDs {
+			if _, ok := pos[id]; !ok {
+				continue
+			}
+			angle := float64(i) * 2 * math.Pi / float64(n)
+			x := cx + int(nukeSpreadRadius*math.Cos(angle))
+			y := cy + int(nukeSpreadRadius*math.Sin(angle))
+			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(id), X: x, Y: y}); err != nil {
+				return err
+			}
+			i++
+		}
+		slog.Info("spreading squad to evade enemy nuke", "squad", sq.Name, "units", n, "eta", env.EnemyNukeTicks(), "launched", env.EnemyNukeLaunched())
+	}
+	return nil
+}
+
+// SquadStrikeSilo sends every member of the squad at the remembered enemy
+// silo — an override of normal targeting while its nuke is about to fire.
+func SquadStrikeSilo(name string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		silo := env.KnownEnemySilo()
+		sq, ok := getSquads(env.Memory)[name]
+		if silo == nil || !ok || len(sq.UnitIDs) == 0 {
+			return nil
+		}
+		ids := make([]uint32, len(sq.UnitIDs))
+		for i, id := range sq.UnitIDs {
+			ids[i] = uint32(id)
+		}
+		slog.Info("squad striking enemy silo", "squad", name, "count", len(ids), "x", silo.X, "y", silo.Y, "eta", env.EnemyNukeTicks())
+		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+			ActorIDs: ids, X: silo.X, Y: silo.Y,
+		})
+	}
 }
 
 // RecallOverextended moves idle squad members that have wandered too far
@@ -1862,7 +2334,7 @@ func RecallOverextended(name string, leashPct float64) ActionFunc {
 		}
 		centX, centY := env.BuildingCentroid()
 		for _, u := range units {
-			slog.Debug("recalling overextended unit", "squad", name, "id", u.ID, "dest_x", centX, "dest_y", centY)
+			env.unitDebug(u.ID, "recalling overextended unit", "squad", name, "id", u.ID, "dest_x", centX, "dest_y", centY)
 			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{
 				ActorID: uint32(u.ID), X: centX, Y: centY,
 			}); err != nil {
@@ -1883,7 +2355,7 @@ func SquadDisengage(name string) ActionFunc {
 		}
 		centX, centY := env.BuildingCentroid()
 		for _, id := range ids {
-			slog.Debug("squad disengaging", "squad", name, "unit", id, "dest_x", centX, "dest_y", centY)
+			env.unitDebug(int(id), "squad disengaging", "squad", name, "unit", id, "dest_x", centX, "dest_y", centY)
 			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{
 				ActorID: id, X: centX, Y: centY,
 			}); err != nil {
@@ -1896,24 +2368,29 @@ func SquadDisengage(name string) ActionFunc {
 
 // SquadFocusFire sends individual Attack commands for each idle squad member
 // targeting the best ground target. Concentrates damage on the highest-value
-// enemy for faster kills.
+// enemy for faster kills; units beyond what it needs to die are split across
+// up to two more targets next to it (see allocateFocusFire).
 func SquadFocusFire(name string) ActionFunc {
 	return func(env RuleEnv, conn *ipc.Connection) error {
-		target := env.BestGroundTarget()
-		if target == nil {
+		targets := env.focusFireTargets()
+		if len(targets) == 0 {
 			return nil
 		}
 		ids := squadIdleActorIDs(env, name)
 		if len(ids) == 0 {
 			return nil
 		}
-		for _, id := range ids {
-			slog.Debug("squad focus fire", "squad", name, "unit", id, "target", target.ID)
-			if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
-				ActorID:  id,
-				TargetID: uint32(target.ID),
-			}); err != nil {
-				return err
+		types := env.unitTypes(ids)
+		alloc := allocateFocusFire(ids, types, targets)
+		for _, t := range targets {
+			for _, id := range alloc[t.ID] {
+				env.unitDebug(int(id), "squad focus fire", "squad", name, "unit", id, "target", t.ID)
+				if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
+					ActorID:  id,
+					TargetID: uint32(t.ID),
+				}); err != nil {
+					return err
+				}
 			}
 		}
 		return nil
@@ -1922,26 +2399,179 @@ func SquadFocusFire(name string) ActionFunc {
 
 // SquadAirStrike sends individual Attack commands for each idle air squad member
 // targeting the best air target (defense structures, production, etc.).
-// Concentrates all aircraft on the highest-value enemy for maximum impact.
+// Aircraft beyond what the best target needs to die are split across up to
+// two more high-value targets nearby (see allocateAirStrike).
 func SquadAirStrike(name string) ActionFunc {
 	return func(env RuleEnv, conn *ipc.Connection) error {
-		target := env.BestAirTarget()
-		if target == nil {
-			return nil
-		}
 		ids := squadIdleActorIDs(env, name)
 		if len(ids) == 0 {
 			return nil
 		}
-		for _, id := range ids {
-			slog.Debug("squad air strike", "squad", name, "unit", id, "target", target.ID)
-			if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
-				ActorID:  id,
-				TargetID: uint32(target.ID),
-			}); err != nil {
+		targets := env.airStrikeTargets(len(ids))
+		if len(targets) == 0 {
+			return nil
+		}
+		types := env.unitTypes(ids)
+		env.startAttackRun(name, targets[0].X, targets[0].Y)
+		alloc := allocateAirStrike(ids, types, targets)
+		for _, t := range targets {
+			for _, id := range alloc[t.ID] {
+				env.unitDebug(int(id), "squad air strike", "squad", name, "unit", id, "target", t.ID)
+				if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
+					ActorID:  id,
+					TargetID: uint32(t.ID),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// AssignEscorts issues guard orders so idle ground units escort harvesters
+// under threat, loaded APCs and siege vehicles, up to maxEscorts in total.
+// Escorts whose charge no longer needs them (harvester safe for a while, APC
+// unloaded) are released back to base to rejoin the idle pool.
+func AssignEscorts(harvesterDangerPct float64, maxEscorts int) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		escorts := getEscorts(env.Memory)
+		needed := make(map[int]escortCharge)
+		for _, c := range env.escortCharges(harvesterDangerPct) {
+			needed[c.Unit.ID] = c
+		}
+
+		bx, by := env.BuildingCentroid()
+		for id, es := range escorts {
+			if _, ok := needed[es.Charge]; ok {
+				es.Tick = env.State.Tick
+				continue
+			}
+			if es.Kind == escortHarvester && env.State.Tick-es.Tick < escortHoldTicks {
+				continue
+			}
+			delete(escorts, id)
+			slog.Info("releasing escort", "id", id, "charge", es.Charge, "kind", es.Kind)
+			if len(env.State.Buildings) > 0 {
+				if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(id), X: bx, Y: by}); err != nil {
+					return err
+				}
+			}
+		}
+
+		guarded := make(map[int]bool)
+		for _, es := range escorts {
+			guarded[es.Charge] = true
+		}
+		charges := make([]escortCharge, 0, len(needed))
+		for _, c := range needed {
+			if !guarded[c.Unit.ID] {
+				charges = append(charges, c)
+			}
+		}
+		slices.SortFunc(charges, func(a, b escortCharge) int { return a.Unit.ID - b.Unit.ID })
+
+		for _, c := range charges {
+			if len(escorts) >= maxEscorts {
+				break
+			}
+			pool := env.escortPool(c.Unit.X, c.Unit.Y)
+			if len(pool) == 0 {
+				break
+			}
+			e := pool[0]
+			slog.Info("assigning escort", "id", e.ID, "type", e.Type, "charge", c.Unit.ID, "kind", c.Kind)
+			if err := conn.Send(ipc.TypeGuard, ipc.GuardCommand{ActorIDs: []uint32{uint32(e.ID)}, TargetID: uint32(c.Unit.ID)}); err != nil {
+				return err
+			}
+			escorts[e.ID] = &escort{Charge: c.Unit.ID, Kind: c.Kind, Tick: env.State.Tick}
+		}
+
+		if len(escorts) == 0 {
+			escortsMemory.clear(env.Memory)
+			return nil
+		}
+		escortsMemory.set(env.Memory, escorts)
+		return nil
+	}
+}
+
+// ActionClearObstacles force-fires siege vehicles at destructible obstacles
+// blocking their direct route. Throttled per unit so the order isn't
+// re-issued every tick while the obstacle burns down.
+func ActionClearObstacles(env RuleEnv, conn *ipc.Connection) error {
+	fired, _ := obstacleFireTickMemory.get(env.Memory)
+	if fired == nil {
+		fired = make(map[int]int)
+	}
+	for _, u := range env.BlockedSiegeUnits() {
+		if last, ok := fired[u.ID]; ok && env.State.Tick-last < obstacleFireTicks {
+			continue
+		}
+		o := env.BlockingObstacle(u)
+		slog.Info("force-firing blocking obstacle", "unit", u.ID, "type", u.Type, "obstacle", o.Type, "x", o.X, "y", o.Y)
+		if err := conn.Send(ipc.TypeForceAttackGround, ipc.ForceAttackGroundCommand{
+			ActorIDs: []uint32{uint32(u.ID)}, X: o.X, Y: o.Y,
+		}); err != nil {
+			return err
+		}
+		fired[u.ID] = env.State.Tick
+	}
+	obstacleFireTickMemory.set(env.Memory, fired)
+	return nil
+}
+
+// KiteRangedUnits steps ranged units back from enemies that have closed
+// inside their danger radius, then re-attacks the nearest enemy once the
+// step has played out — keeping artillery and rockets firing from range
+// instead of trading blows up close.
+func KiteRangedUnits(dangerPct float64, step int) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		kiting := getKitingUnits(env.Memory)
+		pos := env.units().ByID()
+
+		// Re-engage units whose step back has finished.
+		for id, tick := range kiting {
+			if env.State.Tick-tick < kiteStepTicks {
+				continue
+			}
+			delete(kiting, id)
+			u, ok := pos[id]
+			if !ok {
+				continue
+			}
+			en, d := env.nearestMobileEnemy(u.X, u.Y)
+			if en == nil || d > rangeOf(u.Type)*1.5 {
+				continue
+			}
+			if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{ActorID: uint32(id), TargetID: uint32(en.ID)}); err != nil {
 				return err
 			}
 		}
+
+		for _, u := range env.UnitsToKite(dangerPct) {
+			en, d := env.nearestMobileEnemy(u.X, u.Y)
+			if en == nil || d == 0 {
+				continue
+			}
+			x := u.X + int(math.Round(float64(u.X-en.X)/d*float64(step)))
+			y := u.Y + int(math.Round(float64(u.Y-en.Y)/d*float64(step)))
+			if env.State.MapWidth > 0 && env.State.MapHeight > 0 {
+				x = max(0, min(x, env.State.MapWidth-1))
+				y = max(0, min(y, env.State.MapHeight-1))
+			}
+			env.unitDebug(u.ID, "kiting ranged unit", "id", u.ID, "type", u.Type, "enemy", en.ID, "dist", d, "to_x", x, "to_y", y)
+			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{ActorID: uint32(u.ID), X: x, Y: y}); err != nil {
+				return err
+			}
+			kiting[u.ID] = env.State.Tick
+		}
+
+		if len(kiting) == 0 {
+			kitingMemory.clear(env.Memory)
+			return nil
+		}
+		kitingMemory.set(env.Memory, kiting)
 		return nil
 	}
 }
@@ -1988,7 +2618,7 @@ func FleeHarvesters(dangerPct float64) ActionFunc {
 					}
 				}
 			}
-			slog.Debug("fleeing harvester", "id", u.ID, "dest_x", tx, "dest_y", ty)
+			env.unitDebug(u.ID, "fleeing harvester", "id", u.ID, "dest_x", tx, "dest_y", ty)
 			if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{
 				ActorID: uint32(u.ID),
 				X:       tx,
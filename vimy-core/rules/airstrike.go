@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Air strike fan-out. A strike's aircraft are budgeted against their
+// targets' remaining health so a six-plane strike on a damaged pillbox sends
+// two planes and spends the rest on the next targets nearby, instead of all
+// six overkilling one.
+const (
+	airStrikeMaxTargets   = 3    // targets one strike is split across
+	airStrikeSpreadRadius = 12.0 // cells from the primary target a secondary may be
+	airStrikeOverkill     = 1.25 // damage budgeted per target, as a multiple of its remaining health
+)
+
+// airStrikeShare is the fraction of a target's max HP one aircraft's sortie
+// removes, by the target's armor class (see UnitInfo.Armor). Unlisted
+// types use airStrikeShareDefault.
+var airStrikeShare = map[string]float64{
+	"none":     1.0,
+	"light":    0.6,
+	"heavy":    0.35,
+	"wood":     0.2,
+	"concrete": 0.25,
+}
+
+const airStrikeShareDefault = 0.3
+
+// aircraftStrikeWeight scales airStrikeShare by how hard each aircraft hits
+// relative to a Longbow or MiG.
+var aircraftStrikeWeight = map[string]float64{
+	Longbow: 1, MiG: 1, Hind: 0.7, Yak: 0.6, BlackHawk: 0.6,
+}
+
+// airStrikeDamage estimates the fraction of target's max HP one sortie by an
+// aircraft of type aircraft removes.
+func airStrikeDamage(aircraft string, target *model.Enemy) float64 {
+	share := airStrikeShareDefault
+	if u, ok := LookupUnit(target.Type); ok {
+		if s, ok := airStrikeShare[u.Armor]; ok {
+			share = s
+		}
+	}
+	weight := 0.6
+	for t, w := range aircraftStrikeWeight {
+		if matchesType(aircraft, t) {
+			weight = w
+			break
+		}
+	}
+	return share * weight
+}
+
+// airStrikeTargets returns up to airStrikeMaxTargets enemies for a strike
+// of strikeSize aircraft, best first: bestAirTarget, then the
+// highest-scoring enemies within airStrikeSpreadRadius of it that the
+// strike is large enough for. A priority target is struck alone.
+func (e RuleEnv) airStrikeTargets(strikeSize int) []*model.Enemy {
+	primary := e.bestAirTarget(strikeSize)
+	if primary == nil {
+		return nil
+	}
+	if e.priorityTargetOverride() != nil {
+		return []*model.Enemy{primary}
+	}
+	bx, by := e.targetOrigin()
+	return e.targetsNear(primary, airStrikeSpreadRadius, airStrikeMaxTargets, func(en *model.Enemy) float64 {
+		if e.airStrikeSizeFor(en) > strikeSize {
+			return -1
+		}
+		return airTargetScore(en, bx, by) * e.aaRiskFactor(en)
+	})
+}
+
+// targetsNear returns primary followed by up to max-1 other enemies within
+// radius cells of it, highest score first. Enemies score skips (returns a
+// negative score for) are left out.
+func (e RuleEnv) targetsNear(primary *model.Enemy, radius float64, max int, score func(*model.Enemy) float64) []*model.Enemy {
+	type scored struct {
+		en    *model.Enemy
+		score float64
+	}
+	var others []scored
+	for i := range e.State.Enemies {
+		en := &e.State.Enemies[i]
+		if en.ID == primary.ID || en.MaxHP == 0 {
+			continue
+		}
+		if math.Hypot(float64(en.X-primary.X), float64(en.Y-primary.Y)) > radius {
+			continue
+		}
+		if s := score(en); s >= 0 {
+			others = append(others, scored{en, s})
+		}
+	}
+	slices.SortStableFunc(others, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return a.en.ID - b.en.ID
+	})
+	targets := []*model.Enemy{primary}
+	for _, o := range others {
+		if len(targets) == max {
+			break
+		}
+		targets = append(targets, o.en)
+	}
+	return targets
+}
+
+// allocateAirStrike assigns each aircraft to one of targets (best first).
+// Each target takes aircraft until their estimated damage covers its
+// remaining health with airStrikeOverkill to spare; aircraft left once every
+// target is covered join the first. aircraft maps actor ID to type.
+func allocateAirStrike(ids []uint32, aircraft map[uint32]string, targets []*model.Enemy) map[int][]uint32 {
+	return allocateDamage(ids, aircraft, targets, airStrikeOverkill, airStrikeDamage)
+}
+
+// allocateDamage assigns each attacker to one of targets (best first). Each
+// target takes attackers until the damage estimated by damage covers its
+// remaining health times overkill; attackers left once every target is
+// covered join the first. types maps actor ID to type.
+func allocateDamage(ids []uint32, types map[uint32]string, targets []*model.Enemy, overkill float64, damage func(string, *model.Enemy) float64) map[int][]uint32 {
+	out := make(map[int][]uint32, len(targets))
+	if len(targets) == 0 {
+		return out
+	}
+	next := 0
+	for _, t := range targets {
+		need := overkill * float64(t.HP) / float64(t.MaxHP)
+		for dealt := 0.0; dealt < need && next < len(ids); next++ {
+			out[t.ID] = append(out[t.ID], ids[next])
+			dealt += damage(types[ids[next]], t)
+		}
+	}
+	out[targets[0].ID] = append(out[targets[0].ID], ids[next:]...)
+	return out
+}
@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestAllocateAirStrikeSplitsByHealth(t *testing.T) {
+	ids := []uint32{1, 2, 3, 4, 5, 6}
+	types := map[uint32]string{1: Longbow, 2: Longbow, 3: Longbow, 4: Longbow, 5: Longbow, 6: Longbow}
+	pbox := &model.Enemy{ID: 10, Type: "pbox", HP: 40, MaxHP: 100}
+	tsla := &model.Enemy{ID: 11, Type: "tsla", HP: 100, MaxHP: 100}
+	powr := &model.Enemy{ID: 12, Type: "powr", HP: 100, MaxHP: 100}
+
+	alloc := allocateAirStrike(ids, types, []*model.Enemy{pbox, tsla, powr})
+	// Damaged pillbox: 1.25 × 0.4 = 0.5 needed at 0.25 a plane → 2 planes.
+	if got := len(alloc[pbox.ID]); got != 2 {
+		t.Errorf("pillbox got %d aircraft, want 2", got)
+	}
+	if got := len(alloc[tsla.ID]); got != 4 {
+		t.Errorf("tesla coil got %d aircraft, want the remaining 4", got)
+	}
+	if got := len(alloc[powr.ID]); got != 0 {
+		t.Errorf("power plant got %d aircraft, want 0", got)
+	}
+}
+
+func TestAllocateAirStrikeLeftoversJoinPrimary(t *testing.T) {
+	ids := []uint32{1, 2, 3}
+	types := map[uint32]string{1: MiG, 2: MiG, 3: MiG}
+	rifle := &model.Enemy{ID: 10, Type: "e1", HP: 50, MaxHP: 50}
+
+	alloc := allocateAirStrike(ids, types, []*model.Enemy{rifle})
+	if got := len(alloc[rifle.ID]); got != 3 {
+		t.Errorf("single target got %d aircraft, want all 3", got)
+	}
+}
+
+func TestAirStrikeTargetsStayNearPrimary(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Enemies: []model.Enemy{
+				{ID: 20, Type: "tsla", X: 60, Y: 60, HP: 100, MaxHP: 100},
+				{ID: 21, Type: "pbox", X: 64, Y: 60, HP: 100, MaxHP: 100},
+				{ID: 22, Type: "gun", X: 120, Y: 120, HP: 100, MaxHP: 100},
+			},
+		},
+		Memory: map[string]any{},
+	}
+	targets := env.airStrikeTargets(6)
+	if len(targets) != 2 || targets[0].ID != 20 || targets[1].ID != 21 {
+		ids := []int{}
+		for _, t := range targets {
+			ids = append(ids, t.ID)
+		}
+		t.Errorf("targets = %v, want [20 21] (the far turret excluded)", ids)
+	}
+}
+
+func TestAirTargetsAvoidAANests(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Enemies: []model.Enemy{
+				// A tesla coil ringed by three SAMs, and a lone one further out.
+				{ID: 20, Type: "tsla", X: 40, Y: 40, HP: 100, MaxHP: 100},
+				{ID: 21, Type: "sam", X: 43, Y: 40, HP: 100, MaxHP: 100},
+				{ID: 22, Type: "sam", X: 40, Y: 43, HP: 100, MaxHP: 100},
+				{ID: 30, Type: "tsla", X: 80, Y: 80, HP: 100, MaxHP: 100},
+			},
+		},
+		Memory: map[string]any{},
+	}
+	// The third SAM was seen earlier and is now under fog.
+	enemyAAMemory.set(env.Memory, map[int]*enemyAASite{23: {Type: "sam", X: 37, Y: 40}})
+
+	if got := env.aaCoverage(&env.State.Enemies[0]); got != 3 {
+		t.Fatalf("aaCoverage = %d, want 3 (two visible SAMs and one remembered)", got)
+	}
+	if got := env.BestAirTarget(); got == nil || got.ID != 30 {
+		t.Errorf("BestAirTarget = %v, want the undefended tesla coil 30", got)
+	}
+
+	// Take the lone coil away: a small strike has nothing it may hit, a
+	// large enough one goes for the defended coil.
+	env.State.Enemies = env.State.Enemies[:3]
+	if got := env.bestAirTarget(2); got != nil && got.ID == 20 {
+		t.Error("a two-plane strike should not be sent at a coil under three SAMs")
+	}
+	if got := env.bestAirTarget(aaHeavyStrikeSize); got == nil || got.ID != 20 {
+		t.Errorf("bestAirTarget(%d) = %v, want the defended coil 20", aaHeavyStrikeSize, got)
+	}
+}
+
+func TestUpdateEnemyAAForgetsDestroyedSites(t *testing.T) {
+	mem := map[string]any{}
+	sam := model.Enemy{ID: 40, Type: "sam", X: 50, Y: 50, HP: 100, MaxHP: 100}
+	updateEnemyAA(RuleEnv{State: model.GameState{Tick: 100, Enemies: []model.Enemy{sam}}, Memory: mem})
+	if len(getEnemyAA(mem)) != 1 {
+		t.Fatal("visible SAM site should be remembered")
+	}
+
+	updateEnemyAA(RuleEnv{State: model.GameState{Tick: 200}, Memory: mem})
+	if len(getEnemyAA(mem)) != 1 {
+		t.Error("a SAM site out of sight should still be remembered")
+	}
+
+	updateEnemyAA(RuleEnv{State: model.GameState{Tick: 300, Units: []model.Unit{{ID: 1, Type: "3tnk", X: 51, Y: 50}}}, Memory: mem})
+	if len(getEnemyAA(mem)) != 0 {
+		t.Error("a SAM site seen to be gone should be forgotten")
+	}
+}
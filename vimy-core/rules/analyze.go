@@ -0,0 +1,267 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindingKind classifies a static analysis finding.
+type FindingKind string
+
+const (
+	// FindingDuplicateName: two rules share a name, so overrides, diffs and
+	// panic quarantine can't tell them apart.
+	FindingDuplicateName FindingKind = "duplicate_name"
+	// FindingUnreachable: an exclusive rule in the same category with a
+	// higher priority matches whenever this rule does, so this rule never fires.
+	FindingUnreachable FindingKind = "unreachable"
+	// FindingCashContradiction: the rule's condition requires more cash than
+	// it also caps cash at, so it can never match.
+	FindingCashContradiction FindingKind = "cash_contradiction"
+)
+
+// Finding is one problem Analyze found in a rule set.
+type Finding struct {
+	Kind   FindingKind `json:"kind"`
+	Rule   string      `json:"rule"`
+	Other  string      `json:"other,omitempty"` // the shadowing rule, for FindingUnreachable
+	Detail string      `json:"detail"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Kind, f.Rule, f.Detail)
+}
+
+// Analyze statically checks a rule set for duplicate names, rules shadowed
+// by a higher-priority exclusive rule, and contradictory cash bounds.
+// Implication between conditions is judged conservatively — only from
+// top-level && conjuncts that match textually or as tighter numeric bounds
+// — so a finding is always real but some shadowing goes unreported. For
+// the same reason a rule is only shadowed by one in its own group (groups
+// are enabled and disabled separately) whose tick window covers its own.
+func Analyze(rs []*Rule) []Finding {
+	var out []Finding
+
+	seen := make(map[string]int)
+	for _, r := range rs {
+		seen[r.Name]++
+		if seen[r.Name] == 2 {
+			out = append(out, Finding{Kind: FindingDuplicateName, Rule: r.Name, Detail: "rule name defined more than once"})
+		}
+	}
+
+	parsed := make([][]string, len(rs))
+	for i, r := range rs {
+		parsed[i] = conjuncts(r.ConditionSrc)
+	}
+
+	for i, r := range rs {
+		if lo, hi, ok := cashBounds(parsed[i]); ok && lo >= hi {
+			out = append(out, Finding{
+				Kind:   FindingCashContradiction,
+				Rule:   r.Name,
+				Detail: fmt.Sprintf("requires Cash() >= %g but caps it below %g", lo, hi),
+			})
+		}
+		for j, a := range rs {
+			if i == j || !a.Exclusive || a.Category != r.Category || a.Group != r.Group || a.Priority <= r.Priority || !activeWhenever(a, r) {
+				continue
+			}
+			if implies(parsed[i], parsed[j]) {
+				out = append(out, Finding{
+					Kind:   FindingUnreachable,
+					Rule:   r.Name,
+					Other:  a.Name,
+					Detail: fmt.Sprintf("shadowed by exclusive %q (priority %d > %d) whose condition holds whenever this one does", a.Name, a.Priority, r.Priority),
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Rule < out[j].Rule })
+	return out
+}
+
+// activeWhenever reports whether a is evaluated on every tick r is. A
+// cooldown doesn't matter: a matching exclusive rule blocks its category
+// even while its action is cooling down.
+func activeWhenever(a, r *Rule) bool {
+	if a.ActiveFromTick > r.ActiveFromTick {
+		return false
+	}
+	return a.ActiveUntilTick == 0 || (r.ActiveUntilTick != 0 && r.ActiveUntilTick <= a.ActiveUntilTick)
+}
+
+// conjuncts splits a condition on its && operators into a flat list, with
+// whitespace normalised and redundant parentheses removed. A group joined by
+// a top-level || is kept whole, as a single opaque conjunct.
+func conjuncts(src string) []string {
+	src = stripParens(normalizeSpace(src))
+	if src == "" || src == "true" {
+		return nil
+	}
+	parts := splitAnd(src)
+	if len(parts) == 1 {
+		return parts
+	}
+	var out []string
+	for _, p := range parts {
+		out = append(out, conjuncts(p)...)
+	}
+	return out
+}
+
+// splitAnd splits src on top-level && operators, ignoring any inside
+// parentheses, brackets or string literals. src is returned whole if it has
+// a top-level ||.
+func splitAnd(src string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inStr := false
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"' && (i == 0 || src[i-1] != '\\'):
+			inStr = !inStr
+		case inStr:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && strings.HasPrefix(src[i:], "||"):
+			return []string{src}
+		case depth == 0 && strings.HasPrefix(src[i:], "&&"):
+			parts = append(parts, strings.TrimSpace(src[start:i]))
+			start = i + 2
+			i++
+		}
+	}
+	return append(parts, strings.TrimSpace(src[start:]))
+}
+
+func normalizeSpace(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+// stripParens removes parentheses wrapping the whole expression.
+func stripParens(s string) string {
+	for len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		depth := 0
+		wraps := true
+		for i := 0; i < len(s)-1; i++ {
+			switch s[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				wraps = false
+				break
+			}
+		}
+		if !wraps {
+			break
+		}
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+// comparison matches "lhs op number" conjuncts such as "Cash() >= 500".
+var comparison = regexp.MustCompile(`^(.+?) (>=|>|<=|<) (-?\d+(?:\.\d+)?)$`)
+
+type bound struct {
+	lhs string
+	op  string
+	v   float64
+}
+
+func parseBound(c string) (bound, bool) {
+	m := comparison.FindStringSubmatch(c)
+	if m == nil {
+		return bound{}, false
+	}
+	v, err := strconv.ParseFloat(m[3], 64)
+	if err != nil {
+		return bound{}, false
+	}
+	return bound{lhs: m[1], op: m[2], v: v}, true
+}
+
+// tighter reports whether bound b guarantees bound a (same lhs, and b's
+// range lies inside a's).
+func (b bound) tighter(a bound) bool {
+	if a.lhs != b.lhs {
+		return false
+	}
+	switch a.op {
+	case ">=":
+		return (b.op == ">=" || b.op == ">") && b.v >= a.v
+	case ">":
+		return (b.op == ">" && b.v >= a.v) || (b.op == ">=" && b.v > a.v)
+	case "<=":
+		return (b.op == "<=" || b.op == "<") && b.v <= a.v
+	case "<":
+		return (b.op == "<" && b.v <= a.v) || (b.op == "<=" && b.v < a.v)
+	}
+	return false
+}
+
+// implies reports whether every conjunct of a is guaranteed by some
+// conjunct of b. An empty a (always true) is implied by anything.
+func implies(b, a []string) bool {
+	for _, ca := range a {
+		ok := false
+		ba, isBound := parseBound(ca)
+		for _, cb := range b {
+			if cb == ca {
+				ok = true
+				break
+			}
+			if isBound {
+				if bb, ok2 := parseBound(cb); ok2 && bb.tighter(ba) {
+					ok = true
+					break
+				}
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// cashBounds returns the tightest top-level lower and upper bounds on
+// Cash(). ok is false unless both are present.
+func cashBounds(cs []string) (lo, hi float64, ok bool) {
+	hasLo, hasHi := false, false
+	for _, c := range cs {
+		b, isBound := parseBound(c)
+		if !isBound || b.lhs != "Cash()" {
+			continue
+		}
+		switch b.op {
+		case ">=", ">":
+			// Cash is whole credits, so "> n" means ">= n+1".
+			v := b.v
+			if b.op == ">" {
+				v++
+			}
+			if !hasLo || v > lo {
+				lo, hasLo = v, true
+			}
+		case "<", "<=":
+			v := b.v
+			if b.op == "<=" {
+				v++
+			}
+			if !hasHi || v < hi {
+				hi, hasHi = v, true
+			}
+		}
+	}
+	return lo, hi, hasLo && hasHi
+}
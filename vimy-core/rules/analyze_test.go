@@ -0,0 +1,71 @@
+package rules
+
+import "testing"
+
+func TestAnalyzeFindsShadowedRule(t *testing.T) {
+	rs := []*Rule{
+		{Name: "broad", Priority: 600, Category: "infantry", Exclusive: true, ConditionSrc: `HasRole("barracks") && Cash() >= 100`},
+		{Name: "narrow", Priority: 500, Category: "infantry", ConditionSrc: `HasRole("barracks") && (Cash() >= 500) && UnitCount("e1") < 10`},
+		{Name: "other-category", Priority: 400, Category: "vehicle", ConditionSrc: `HasRole("barracks") && Cash() >= 500`},
+		{Name: "looser", Priority: 300, Category: "infantry", ConditionSrc: `HasRole("barracks") || Cash() >= 500`},
+	}
+	got := Analyze(rs)
+	if len(got) != 1 || got[0].Kind != FindingUnreachable || got[0].Rule != "narrow" || got[0].Other != "broad" {
+		t.Errorf("expected only narrow shadowed by broad, got %v", got)
+	}
+}
+
+func TestAnalyzeShadowingNeedsWindowAndGroup(t *testing.T) {
+	cond := `HasRole("barracks")`
+	rs := []*Rule{
+		{Name: "early", Priority: 600, Category: "infantry", Exclusive: true, ActiveUntilTick: 3000, ConditionSrc: cond},
+		{Name: "late", Priority: 500, Category: "infantry", ActiveFromTick: 4000, ConditionSrc: cond},
+		{Name: "overlapping", Priority: 500, Category: "infantry", ActiveFromTick: 2000, ConditionSrc: cond},
+		{Name: "inside", Priority: 500, Category: "infantry", ActiveFromTick: 1000, ActiveUntilTick: 2000, ConditionSrc: cond},
+		{Name: "grouped", Priority: 700, Category: "vehicle", Group: "capture", Exclusive: true, CooldownTicks: 50, ConditionSrc: cond},
+		{Name: "ungrouped", Priority: 500, Category: "vehicle", ConditionSrc: cond},
+		{Name: "same-group", Priority: 500, Category: "vehicle", Group: "capture", ConditionSrc: cond},
+	}
+	shadowed := make(map[string]string)
+	for _, f := range Analyze(rs) {
+		shadowed[f.Rule] = f.Other
+	}
+	want := map[string]string{"inside": "early", "same-group": "grouped"}
+	if len(shadowed) != len(want) || shadowed["inside"] != "early" || shadowed["same-group"] != "grouped" {
+		t.Errorf("shadowed = %v, want %v", shadowed, want)
+	}
+}
+
+func TestAnalyzeCashContradictionAndDuplicates(t *testing.T) {
+	rs := []*Rule{
+		{Name: "save-and-spend", Priority: 100, Category: "a", ConditionSrc: `Cash() >= 800 && Cash() < 500`},
+		{Name: "window", Priority: 100, Category: "b", ConditionSrc: `Cash() >= 300 && Cash() < 500`},
+		{Name: "window", Priority: 90, Category: "c", ConditionSrc: `true`},
+	}
+	kinds := make(map[FindingKind]string)
+	for _, f := range Analyze(rs) {
+		kinds[f.Kind] = f.Rule
+	}
+	if kinds[FindingCashContradiction] != "save-and-spend" {
+		t.Errorf("cash contradiction not reported: %v", kinds)
+	}
+	if kinds[FindingDuplicateName] != "window" {
+		t.Errorf("duplicate name not reported: %v", kinds)
+	}
+	if len(kinds) != 2 {
+		t.Errorf("unexpected findings: %v", kinds)
+	}
+}
+
+func TestConjunctsRespectNesting(t *testing.T) {
+	got := conjuncts(`(A() && (B() || C())) && D("x && y")`)
+	want := []string{`A()`, `B() || C()`, `D("x && y")`}
+	if len(got) != len(want) {
+		t.Fatalf("conjuncts = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("conjunct %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
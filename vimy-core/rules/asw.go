@@ -0,0 +1,214 @@
+package rules
+
+import (
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Anti-submarine warfare. Enemy submarines are visible only while surfaced
+// to fire, so the naval attack rules, which chase visible enemies, never
+// find them. Each sighting is remembered as a contact — with the line it was
+// moving along once it has been seen twice — and up to aswMaxHunters
+// destroyers or gunboats, the ships carrying depth charges, are taken off
+// other duty to sweep those lines and the water off our naval yards. They
+// attack-move between sweep points so they engage any sub they detect on
+// the way. Hunters are owned (see unitOwners) and go back to the pool once
+// every contact has gone cold.
+const (
+	subContactTicks = 4500 // 3 minutes: a sub unseen this long has moved on
+	aswMaxHunters   = 2
+	aswYardRadius   = 6   // cells off a naval yard its sweep points sit
+	aswLineLead     = 1.0 // sweep ahead along a contact's heading by this multiple of its last leg
+)
+
+// subContact is the last sighting of an enemy submarine. Moved is set once
+// it has been seen somewhere else before, giving the line (Prev → X, Y) it
+// was travelling.
+type subContact struct {
+	X, Y         int
+	PrevX, PrevY int
+	Moved        bool
+	Tick         int
+}
+
+// aswHunter is one hunter's place on the sweep.
+type aswHunter struct {
+	Waypoint int // index of the sweep point it was last sent to
+}
+
+func getSubContacts(memory map[string]any) map[int]*subContact {
+	if v, ok := subContactsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*subContact)
+}
+
+func getASWHunters(memory map[string]any) map[int]*aswHunter {
+	if v, ok := aswHuntersMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*aswHunter)
+}
+
+func isSubmarine(t string) bool { return matchesType(t, Submarine) || matchesType(t, MissileSub) }
+
+// isASWShip reports whether one of our ship types carries depth charges.
+func isASWShip(t string) bool { return matchesType(t, Destroyer) || matchesType(t, Gunboat) }
+
+// updateASW records sub sightings, lets contacts go cold after
+// subContactTicks, and drops hunters that died — or all of them once no
+// contact is left.
+func updateASW(env RuleEnv) {
+	tick := env.State.Tick
+	contacts := getSubContacts(env.Memory)
+	for _, en := range env.State.Enemies {
+		if !isSubmarine(en.Type) {
+			continue
+		}
+		c := contacts[en.ID]
+		switch {
+		case c == nil:
+			slog.Info("sub contact", "id", en.ID, "type", en.Type, "x", en.X, "y", en.Y)
+			contacts[en.ID] = &subContact{X: en.X, Y: en.Y, Tick: tick}
+			continue
+		case c.X != en.X || c.Y != en.Y:
+			c.PrevX, c.PrevY, c.Moved = c.X, c.Y, true
+			c.X, c.Y = en.X, en.Y
+		}
+		c.Tick = tick
+	}
+	for id, c := range contacts {
+		if tick-c.Tick > subContactTicks {
+			delete(contacts, id)
+		}
+	}
+	if len(contacts) == 0 {
+		subContactsMemory.clear(env.Memory)
+	} else {
+		subContactsMemory.set(env.Memory, contacts)
+	}
+
+	hunters := getASWHunters(env.Memory)
+	if len(hunters) == 0 {
+		return
+	}
+	units := env.units()
+	for id := range hunters {
+		if !units.Has(id) || len(contacts) == 0 {
+			delete(hunters, id)
+		}
+	}
+	if len(hunters) == 0 {
+		aswHuntersMemory.clear(env.Memory)
+		return
+	}
+	aswHuntersMemory.set(env.Memory, hunters)
+}
+
+// HasSubContacts reports whether an enemy submarine has been seen recently
+// enough that it is worth hunting.
+func (e RuleEnv) HasSubContacts() bool { return len(getSubContacts(e.Memory)) > 0 }
+
+// HasASWHunters reports whether any ships are assigned to hunt submarines.
+func (e RuleEnv) HasASWHunters() bool { return len(getASWHunters(e.Memory)) > 0 }
+
+// UnassignedIdleASW returns our idle destroyers and gunboats no task owns.
+func (e RuleEnv) UnassignedIdleASW() []model.Unit {
+	var out []model.Unit
+	for _, u := range e.UnassignedIdleNaval() {
+		if isASWShip(u.Type) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// aswSweep returns the points hunters sweep: each contact's line — where it
+// was, where it was last seen and where it was heading — then the water
+// around each of our naval yards. Points off the map or off water are
+// skipped.
+func (e RuleEnv) aswSweep() [][2]int {
+	var out [][2]int
+	add := func(x, y int) {
+		if x < 0 || y < 0 || (e.State.MapWidth > 0 && x >= e.State.MapWidth) || (e.State.MapHeight > 0 && y >= e.State.MapHeight) {
+			return
+		}
+		if e.Terrain != nil && !e.IsWaterAt(x, y) {
+			return
+		}
+		out = append(out, [2]int{x, y})
+	}
+
+	contacts := getSubContacts(e.Memory)
+	for _, id := range slices.Sorted(maps.Keys(contacts)) {
+		c := contacts[id]
+		if c.Moved {
+			add(c.PrevX, c.PrevY)
+		}
+		add(c.X, c.Y)
+		if c.Moved {
+			dx, dy := float64(c.X-c.PrevX), float64(c.Y-c.PrevY)
+			add(c.X+int(math.Round(dx*aswLineLead)), c.Y+int(math.Round(dy*aswLineLead)))
+		}
+	}
+	for _, b := range e.State.Buildings {
+		if !matchesType(b.Type, NavalYard) && !matchesType(b.Type, SubPen) {
+			continue
+		}
+		for i := range 4 {
+			angle := float64(i) * math.Pi / 2
+			add(b.X+int(aswYardRadius*math.Cos(angle)), b.Y+int(aswYardRadius*math.Sin(angle)))
+		}
+	}
+	return out
+}
+
+// AssignASWHunters keeps up to maxHunters destroyers or gunboats sweeping
+// for submarines while there are contacts. New hunters join the sweep at
+// its nearest point; a hunter that has gone idle has reached its point and
+// is attack-moved on to the next.
+func AssignASWHunters(maxHunters int) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		sweep := env.aswSweep()
+		if len(sweep) == 0 || !env.HasSubContacts() {
+			return nil
+		}
+		hunters := getASWHunters(env.Memory)
+		for _, u := range env.UnassignedIdleASW() {
+			if len(hunters) >= maxHunters {
+				break
+			}
+			slog.Info("assigning sub hunter", "id", u.ID, "type", u.Type)
+			// Start one short of the nearest point so the first leg goes there.
+			hunters[u.ID] = &aswHunter{Waypoint: nearestWaypoint(sweep, u.X, u.Y) - 1}
+		}
+
+		for _, u := range env.units().Idle() {
+			h, ok := hunters[u.ID]
+			if !ok {
+				continue
+			}
+			h.Waypoint = (h.Waypoint + 1 + len(sweep)) % len(sweep)
+			wp := sweep[h.Waypoint]
+			if err := conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+				ActorIDs: []uint32{uint32(u.ID)},
+				X:        wp[0],
+				Y:        wp[1],
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(hunters) == 0 {
+			aswHuntersMemory.clear(env.Memory)
+			return nil
+		}
+		aswHuntersMemory.set(env.Memory, hunters)
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestSubContactsTrackLineAndGoCold(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Enemies:   []model.Enemy{{ID: 50, Type: "ss", X: 60, Y: 40}, {ID: 51, Type: "dd", X: 90, Y: 90}},
+		},
+		Memory: make(map[string]any),
+	}
+	updateASW(env)
+	if !env.HasSubContacts() {
+		t.Fatal("surfaced sub left no contact")
+	}
+	if _, ok := getSubContacts(env.Memory)[51]; ok {
+		t.Error("destroyer recorded as a sub contact")
+	}
+
+	// It submerges, surfaces again further south-west: the sweep runs along
+	// its line and on past the second sighting.
+	env.State.Tick = 100
+	env.State.Enemies = nil
+	updateASW(env)
+	env.State.Tick = 200
+	env.State.Enemies = []model.Enemy{{ID: 50, Type: "ss", X: 55, Y: 45}}
+	updateASW(env)
+	want := [][2]int{{60, 40}, {55, 45}, {50, 50}}
+	got := env.aswSweep()
+	if len(got) != len(want) {
+		t.Fatalf("sweep = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sweep[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	env.State.Enemies = nil
+	env.State.Tick = 200 + subContactTicks + 1
+	updateASW(env)
+	if env.HasSubContacts() {
+		t.Error("contact kept after going cold")
+	}
+}
+
+func TestASWHuntersSweepAndRelease(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{{ID: 100, Type: "syrd", X: 20, Y: 60}},
+			Units: []model.Unit{
+				{ID: 1, Type: "dd", X: 22, Y: 66, Idle: true},
+				{ID: 2, Type: "pt", X: 24, Y: 66, Idle: true},
+				{ID: 3, Type: "ca", X: 26, Y: 66, Idle: true}, // no depth charges
+				{ID: 4, Type: "dd", X: 28, Y: 66, Idle: true},
+			},
+			Enemies: []model.Enemy{{ID: 50, Type: "ss", X: 60, Y: 40}},
+		},
+		Memory: make(map[string]any),
+	}
+	updateASW(env)
+
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	if err := AssignASWHunters(aswMaxHunters)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	hunters := getASWHunters(env.Memory)
+	if len(hunters) != aswMaxHunters {
+		t.Fatalf("hunters = %d, want %d", len(hunters), aswMaxHunters)
+	}
+	if _, ok := hunters[3]; ok {
+		t.Error("cruiser assigned to hunt subs")
+	}
+	for id := range hunters {
+		if env.UnitOwner(id) != ownerASW {
+			t.Errorf("hunter %d owned by %q", id, env.UnitOwner(id))
+		}
+	}
+	for _, u := range env.UnassignedIdleNaval() {
+		if hunters[u.ID] != nil {
+			t.Errorf("hunter %d still offered to naval squads", u.ID)
+		}
+	}
+
+	// The contact goes cold: hunters return to the pool.
+	env.State.Enemies = nil
+	env.State.Tick = subContactTicks + 1
+	updateASW(env)
+	if env.HasASWHunters() {
+		t.Error("hunters kept with no contact left")
+	}
+}
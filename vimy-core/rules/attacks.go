@@ -0,0 +1,259 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Attack outcome tracking. Each attack squad launch opens a run; the run
+// tallies squad members lost against the value of enemies that vanished
+// near the squad while we still had eyes on the spot, and closes when the
+// squad dies, goes quiet, or runs too long.
+const (
+	attackEngageRadius    = 10.0 // cells around the squad centroid that count as the fight
+	attackQuietTicks      = 500  // 20s idle with no contact ends a run
+	attackMaxTicks        = 3000 // 2 minutes caps any one run
+	attackHistoryLen      = 10   // finished runs kept for the dashboard/strategist
+	attackFailTrade       = 1.0  // enemy value destroyed per unit lost below which a run failed
+	attackFailStreak      = 2    // consecutive failures before sizing is raised
+	attackSizeStep        = 2    // units added to the group size per adjustment
+	attackMaxSizeBonus    = 6    // cap on the accumulated size increase
+	attackThresholdStep   = 0.1  // readiness added to the activation threshold per adjustment
+	attackMaxThresholdAdd = 0.3  // cap on the accumulated threshold increase
+)
+
+// nearbyEnemy is an enemy seen inside the fight last update, remembered so
+// its disappearance can be credited to the squad.
+type nearbyEnemy struct {
+	X, Y  int
+	Value float64
+}
+
+// attackRun is an in-flight attack by one squad.
+type attackRun struct {
+	Squad       string
+	Launch      int
+	Size        int
+	TargetX     int
+	TargetY     int
+	X, Y        int // last centroid of surviving members
+	Members     []int
+	Lost        int
+	Destroyed   float64
+	LastContact int
+	Nearby      map[int]nearbyEnemy
+}
+
+// AttackOutcome is a finished attack run. Failed means the squad lost more
+// than it destroyed by attackFailTrade; Wiped means no member survived, and
+// NearBase that the squad's last position was close to our own base.
+type AttackOutcome struct {
+	Squad     string  `json:"squad"`
+	Launch    int     `json:"launch"`
+	End       int     `json:"end"`
+	Size      int     `json:"size"`
+	Lost      int     `json:"lost"`
+	Destroyed float64 `json:"destroyed"`
+	Failed    bool    `json:"failed"`
+	Wiped     bool    `json:"wiped"`
+	NearBase  bool    `json:"near_base"`
+}
+
+// attackAdjust is the runtime correction applied on top of the doctrine's
+// group size and activation threshold for one squad.
+type attackAdjust struct {
+	SizeBonus      int
+	ThresholdBonus float64
+	Failures       int // consecutive failed runs
+}
+
+func getAttackRuns(memory map[string]any) map[string]*attackRun {
+	if v, ok := attackRunsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]*attackRun)
+}
+
+func getAttackAdjust(memory map[string]any) map[string]*attackAdjust {
+	if v, ok := attackAdjustMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]*attackAdjust)
+}
+
+// GetAttackHistory returns finished attack runs, oldest first (used by the
+// dashboard and strategist).
+func GetAttackHistory(memory map[string]any) []AttackOutcome {
+	v, _ := attackHistoryMemory.get(memory)
+	return v
+}
+
+// startAttackRun opens a run for an attack squad being sent at (x, y),
+// unless one is already in flight — re-engage orders continue the same run.
+func (e RuleEnv) startAttackRun(name string, x, y int) {
+	sq := getSquads(e.Memory)[name]
+	if sq == nil || sq.Role != "attack" {
+		return
+	}
+	runs := getAttackRuns(e.Memory)
+	if _, ok := runs[name]; ok {
+		return
+	}
+	// Start the run's position at the squad's muster point, so a squad
+	// wiped before the next update still has a sensible last position.
+	sx, sy, _ := squadCentroid(sq, e.units().ByID())
+	runs[name] = &attackRun{
+		Squad:       name,
+		Launch:      e.State.Tick,
+		Size:        len(sq.UnitIDs),
+		TargetX:     x,
+		TargetY:     y,
+		X:           sx,
+		Y:           sy,
+		Members:     append([]int(nil), sq.UnitIDs...),
+		LastContact: e.State.Tick,
+		Nearby:      make(map[int]nearbyEnemy),
+	}
+	attackRunsMemory.set(e.Memory, runs)
+	slog.Info("attack launched", "squad", name, "size", len(sq.UnitIDs), "x", x, "y", y)
+}
+
+// enemyValue scores an enemy for trade accounting, on the same scale as
+// ground target selection (mobile units are worth 1).
+func enemyValue(en model.Enemy) float64 {
+	base := strings.ToLower(en.Type)
+	if idx := strings.IndexByte(base, '.'); idx >= 0 {
+		base = base[:idx]
+	}
+	if v := groundTargetValue[base]; v > 0 {
+		return v
+	}
+	return groundTargetValueDefault
+}
+
+// updateAttackRuns advances every in-flight run and closes finished ones.
+func updateAttackRuns(env RuleEnv) {
+	runs := getAttackRuns(env.Memory)
+	if len(runs) == 0 {
+		return
+	}
+	tick := env.State.Tick
+	units := env.units().ByID()
+	visible := make(map[int]bool, len(env.State.Enemies))
+	for _, en := range env.State.Enemies {
+		visible[en.ID] = true
+	}
+
+	for name, run := range runs {
+		sx, sy, alive, idle := 0, 0, 0, 0
+		for _, id := range run.Members {
+			if u, ok := units[id]; ok {
+				sx += u.X
+				sy += u.Y
+				alive++
+				if u.Idle {
+					idle++
+				}
+			}
+		}
+		run.Lost = len(run.Members) - alive
+
+		// Enemies that were in the fight and are now gone from a spot we can
+		// still see were destroyed; ones that slipped into fog weren't.
+		for id, ne := range run.Nearby {
+			if !visible[id] && siteObserved(env, ne.X, ne.Y) {
+				run.Destroyed += ne.Value
+			}
+		}
+		run.Nearby = make(map[int]nearbyEnemy)
+		if alive > 0 {
+			cx, cy := sx/alive, sy/alive
+			run.X, run.Y = cx, cy
+			for _, en := range env.State.Enemies {
+				if math.Hypot(float64(en.X-cx), float64(en.Y-cy)) <= attackEngageRadius {
+					run.Nearby[en.ID] = nearbyEnemy{X: en.X, Y: en.Y, Value: enemyValue(en)}
+				}
+			}
+			if len(run.Nearby) > 0 {
+				run.LastContact = tick
+			}
+		}
+
+		switch {
+		case alive == 0, tick-run.Launch >= attackMaxTicks,
+			idle == alive && tick-run.LastContact >= attackQuietTicks:
+			delete(runs, name)
+			finishAttackRun(env, run)
+		}
+	}
+	attackRunsMemory.set(env.Memory, runs)
+}
+
+// finishAttackRun records the outcome and adapts sizing: after
+// attackFailStreak failures in a row the squad waits for more units and a
+// fuller muster before launching; each success walks one step back.
+func finishAttackRun(env RuleEnv, run *attackRun) {
+	failed := run.Lost > 0 && run.Destroyed < float64(run.Lost)*attackFailTrade
+	out := AttackOutcome{
+		Squad: run.Squad, Launch: run.Launch, End: env.State.Tick,
+		Size: run.Size, Lost: run.Lost, Destroyed: run.Destroyed, Failed: failed,
+		Wiped:    run.Lost == len(run.Members),
+		NearBase: env.nearOwnBase(run.X, run.Y, env.baseThreatRadius()*containRadiusScale),
+	}
+	history := append(GetAttackHistory(env.Memory), out)
+	if len(history) > attackHistoryLen {
+		history = history[len(history)-attackHistoryLen:]
+	}
+	attackHistoryMemory.set(env.Memory, history)
+	slog.Info("attack finished", "squad", run.Squad, "size", run.Size, "lost", run.Lost,
+		"destroyed", run.Destroyed, "failed", failed, "ticks", env.State.Tick-run.Launch)
+
+	adjusts := getAttackAdjust(env.Memory)
+	adj := adjusts[run.Squad]
+	if adj == nil {
+		adj = &attackAdjust{}
+		adjusts[run.Squad] = adj
+	}
+	prevSize := adj.SizeBonus
+	if failed {
+		adj.Failures++
+		if adj.Failures >= attackFailStreak {
+			adj.SizeBonus = min(adj.SizeBonus+attackSizeStep, attackMaxSizeBonus)
+			adj.ThresholdBonus = math.Min(adj.ThresholdBonus+attackThresholdStep, attackMaxThresholdAdd)
+			adj.Failures = 0
+			slog.Info("attack sizing raised after repeated failures", "squad", run.Squad,
+				"sizeBonus", adj.SizeBonus, "thresholdBonus", adj.ThresholdBonus)
+		}
+	} else {
+		adj.Failures = 0
+		adj.SizeBonus = max(adj.SizeBonus-attackSizeStep/2, 0)
+		adj.ThresholdBonus = math.Max(adj.ThresholdBonus-attackThresholdStep/2, 0)
+	}
+	attackAdjustMemory.set(env.Memory, adjusts)
+
+	// A live squad picks the new size up through reinforcement.
+	if sq := getSquads(env.Memory)[run.Squad]; sq != nil {
+		sq.TargetSize += adj.SizeBonus - prevSize
+	}
+}
+
+// AttackGroupSize returns the formation size for the named attack squad:
+// the doctrine's base size plus any correction learned from failed attacks.
+func (e RuleEnv) AttackGroupSize(name string, base int) int {
+	if adj := getAttackAdjust(e.Memory)[name]; adj != nil {
+		return base + adj.SizeBonus
+	}
+	return base
+}
+
+// AttackThreshold returns the readiness ratio the named squad must reach
+// before launching: the doctrine's threshold plus any learned correction.
+func (e RuleEnv) AttackThreshold(name string, base float64) float64 {
+	if adj := getAttackAdjust(e.Memory)[name]; adj != nil {
+		return math.Min(base+adj.ThresholdBonus, 1)
+	}
+	return base
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// failedAttack launches the ground-attack squad at a defended spot and then
+// loses every member without killing anything.
+func failedAttack(t *testing.T, env *RuleEnv, tick int) {
+	t.Helper()
+	env.State.Tick = tick
+	env.State.Units = []model.Unit{
+		{ID: 1, Type: "1tnk", X: 10, Y: 10},
+		{ID: 2, Type: "1tnk", X: 11, Y: 10},
+	}
+	getSquads(env.Memory)["ground-attack"] = &Squad{
+		Name: "ground-attack", Domain: "ground", Role: "attack", TargetSize: 2, UnitIDs: []int{1, 2},
+	}
+	env.startAttackRun("ground-attack", 50, 50)
+
+	env.State.Tick = tick + 100
+	env.State.Units = nil
+	updateAttackRuns(*env)
+}
+
+func TestAttackRunRecordsTrade(t *testing.T) {
+	mem := map[string]any{"squads": map[string]*Squad{}}
+	env := RuleEnv{State: model.GameState{MapWidth: 64, MapHeight: 64}, Memory: mem}
+
+	env.State.Units = []model.Unit{
+		{ID: 1, Type: "1tnk", X: 10, Y: 10, Idle: true},
+		{ID: 2, Type: "1tnk", X: 11, Y: 10, Idle: true},
+	}
+	getSquads(mem)["ground-attack"] = &Squad{
+		Name: "ground-attack", Domain: "ground", Role: "attack", TargetSize: 2, UnitIDs: []int{1, 2},
+	}
+	env.startAttackRun("ground-attack", 12, 12)
+
+	// Contact: an enemy tank and a power plant inside the fight.
+	env.State.Tick = 50
+	env.State.Enemies = []model.Enemy{
+		{ID: 100, Type: "3tnk", X: 13, Y: 11},
+		{ID: 101, Type: "powr", X: 14, Y: 12},
+	}
+	updateAttackRuns(env)
+
+	// Both die in view; we lose one tank.
+	env.State.Tick = 100
+	env.State.Enemies = nil
+	env.State.Units = env.State.Units[:1]
+	updateAttackRuns(env)
+
+	// Quiet long enough and the run closes as a success.
+	env.State.Tick = 100 + attackQuietTicks
+	updateAttackRuns(env)
+
+	hist := GetAttackHistory(mem)
+	if len(hist) != 1 {
+		t.Fatalf("history has %d entries, want 1", len(hist))
+	}
+	want := groundTargetValueDefault + groundTargetValue[PowerPlant]
+	if h := hist[0]; h.Lost != 1 || h.Destroyed != want || h.Failed {
+		t.Errorf("outcome = %+v, want lost 1, destroyed %.1f, not failed", h, want)
+	}
+}
+
+func TestRepeatedFailuresRaiseAttackSizing(t *testing.T) {
+	mem := map[string]any{"squads": map[string]*Squad{}}
+	env := RuleEnv{State: model.GameState{MapWidth: 64, MapHeight: 64}, Memory: mem}
+
+	failedAttack(t, &env, 0)
+	if got := env.AttackGroupSize("ground-attack", 6); got != 6 {
+		t.Errorf("one failure already changed the group size to %d", got)
+	}
+
+	failedAttack(t, &env, 1000)
+	if got := env.AttackGroupSize("ground-attack", 6); got != 6+attackSizeStep {
+		t.Errorf("group size after two failures = %d, want %d", got, 6+attackSizeStep)
+	}
+	if got := env.AttackThreshold("ground-attack", 0.95); got != 1 {
+		t.Errorf("threshold = %.2f, want capped at 1", got)
+	}
+	if got := getSquads(mem)["ground-attack"].TargetSize; got != 2+attackSizeStep {
+		t.Errorf("live squad target size = %d, want %d", got, 2+attackSizeStep)
+	}
+	if got := env.AttackGroupSize("air-attack", 3); got != 3 {
+		t.Errorf("unrelated squad sized %d, want 3", got)
+	}
+}
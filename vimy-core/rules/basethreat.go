@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Domain-aware base threats. BaseUnderAttack fires for any enemy near the
+// base; these split that by what the attacker is, so a destroyer shelling
+// the shore mobilizes ships and bombers over the base raise AA, rather
+// than everything answering whatever enemy happens to be nearest.
+
+const (
+	domainGround = "ground"
+	domainAir    = "air"
+	domainNaval  = "naval"
+)
+
+// enemyDomain classifies an enemy type as ground, air or naval, by the
+// unit table's target class and falling back to our own role tables.
+func enemyDomain(t string) string {
+	if u, ok := LookupUnit(t); ok {
+		switch u.Target {
+		case "air":
+			return domainAir
+		case "ship":
+			return domainNaval
+		}
+	}
+	switch {
+	case isAircraft(model.Unit{Type: t}):
+		return domainAir
+	case isNaval(model.Unit{Type: t}):
+		return domainNaval
+	}
+	return domainGround
+}
+
+// baseThreatRadius is how close to one of our buildings an enemy must be for
+// BaseUnderAttack: the doctrine's defense margin. The defended area is our
+// building footprint grown by that margin, so it follows the base as it
+// spreads rather than scaling with the map, which made it swallow half a
+// small map's buildable ground and cover empty miles on a huge one.
+func (e RuleEnv) baseThreatRadius() float64 {
+	return e.Doctrine.defenseMargin()
+}
+
+// isCoastal reports whether the position's terrain zone or one next to it
+// is water — where ships can reach. Without terrain every building counts.
+func (e RuleEnv) isCoastal(x, y int) bool {
+	g := e.Terrain
+	if g == nil || g.CellW <= 0 || g.CellH <= 0 {
+		return true
+	}
+	col, row := x/g.CellW, y/g.CellH
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if g.At(col+dc, row+dr) == model.Water {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baseThreats returns the domain's enemies within baseThreatRadius of a
+// building, nearest to a building first. Naval threats count only near
+// coastal buildings: a ship can't reach the rest.
+func (e RuleEnv) baseThreats(domain string) []*model.Enemy {
+	if len(e.State.Buildings) == 0 || len(e.State.Enemies) == 0 {
+		return nil
+	}
+	buildings := e.State.Buildings
+	if domain == domainNaval {
+		buildings = nil
+		for _, b := range e.State.Buildings {
+			if e.isCoastal(b.X, b.Y) {
+				buildings = append(buildings, b)
+			}
+		}
+	}
+	radius := e.baseThreatRadius()
+	threshSq := radius * radius
+
+	var out []*model.Enemy
+	dist := make(map[int]float64)
+	for i := range e.State.Enemies {
+		en := &e.State.Enemies[i]
+		if enemyDomain(en.Type) != domain {
+			continue
+		}
+		best := math.MaxFloat64
+		for _, b := range buildings {
+			dx := float64(en.X - b.X)
+			dy := float64(en.Y - b.Y)
+			best = math.Min(best, dx*dx+dy*dy)
+		}
+		if best < threshSq {
+			out = append(out, en)
+			dist[en.ID] = best
+		}
+	}
+	slices.SortStableFunc(out, func(a, b *model.Enemy) int { return cmp.Compare(dist[a.ID], dist[b.ID]) })
+	return out
+}
+
+// NavalBaseThreat reports enemy ships within range of our coastal buildings.
+func (e RuleEnv) NavalBaseThreat() bool { return len(e.baseThreats(domainNaval)) > 0 }
+
+// AirBaseThreat reports enemy aircraft over or approaching the base.
+func (e RuleEnv) AirBaseThreat() bool { return len(e.baseThreats(domainAir)) > 0 }
+
+// GroundBaseThreat reports enemy ground forces near the base.
+func (e RuleEnv) GroundBaseThreat() bool { return len(e.baseThreats(domainGround)) > 0 }
+
+// NearestNavalThreat returns the enemy ship closest to a coastal building,
+// or nil if none threatens the base.
+func (e RuleEnv) NearestNavalThreat() *model.Enemy {
+	if threats := e.baseThreats(domainNaval); len(threats) > 0 {
+		return threats[0]
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestEnemyDomain(t *testing.T) {
+	for typ, want := range map[string]string{"dd": domainNaval, "ss": domainNaval, "mig": domainAir, "heli": domainAir, "3tnk": domainGround, "e1": domainGround} {
+		if got := enemyDomain(typ); got != want {
+			t.Errorf("enemyDomain(%q) = %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestNavalThreatNeedsCoastalBuilding(t *testing.T) {
+	// 10×10 zones of 10 cells; only the rightmost column is water.
+	grid := &model.TerrainGrid{Cols: 10, Rows: 10, CellW: 10, CellH: 10, Grid: make([]model.TerrainType, 100)}
+	for row := 0; row < 10; row++ {
+		grid.Grid[row*10+9] = model.Water
+	}
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth: 100, MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 15, Y: 50}},
+			Enemies: []model.Enemy{
+				{ID: 10, Type: "dd", X: 70, Y: 50},
+				{ID: 11, Type: "mig", X: 20, Y: 50},
+			},
+		},
+		Terrain: grid,
+		Memory:  map[string]any{},
+	}
+	if env.NavalBaseThreat() {
+		t.Error("a destroyer near an inland base is no naval threat")
+	}
+	if !env.AirBaseThreat() {
+		t.Error("a MiG over the base should be an air threat")
+	}
+	if env.GroundBaseThreat() {
+		t.Error("no ground enemies near the base")
+	}
+
+	// A naval yard on the shore brings the destroyer into play.
+	env.State.Buildings = append(env.State.Buildings, model.Building{ID: 2, Type: "spen", X: 85, Y: 50})
+	if !env.NavalBaseThreat() {
+		t.Fatal("a destroyer near a shore building should be a naval threat")
+	}
+	if got := env.NearestNavalThreat(); got == nil || got.ID != 10 {
+		t.Errorf("NearestNavalThreat() = %+v, want the destroyer", got)
+	}
+}
+
+func TestBaseRadiusFollowsFootprint(t *testing.T) {
+	// Two buildings 50 cells apart on maps of very different sizes: an enemy
+	// beside either one is at the base, one midway between them is not.
+	for _, size := range []int{64, 512} {
+		env := RuleEnv{
+			State: model.GameState{
+				MapWidth: size, MapHeight: size,
+				Buildings: []model.Building{
+					{ID: 1, Type: "fact", X: 10, Y: 10},
+					{ID: 2, Type: "proc", X: 60, Y: 10},
+				},
+			},
+			Memory: map[string]any{},
+		}
+		for _, tc := range []struct {
+			x    int
+			want bool
+		}{{65, true}, {5, true}, {35, false}} {
+			env.State.Enemies = []model.Enemy{{ID: 10, Type: "3tnk", X: tc.x, Y: 10}}
+			if got := env.BaseUnderAttack(); got != tc.want {
+				t.Errorf("map %d, enemy at x=%d: BaseUnderAttack() = %v, want %v", size, tc.x, got, tc.want)
+			}
+		}
+
+		// A wider doctrine margin takes in the gap.
+		env.Doctrine.DefenseMargin = MaxDefenseMargin
+		env.State.Enemies = []model.Enemy{{ID: 10, Type: "3tnk", X: 35, Y: 10}}
+		if !env.BaseUnderAttack() {
+			t.Errorf("map %d: enemy within the widest margin not at the base", size)
+		}
+	}
+}
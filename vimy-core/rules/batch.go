@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"log/slog"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Batch production. Rifle infantry are cheap enough that queueing them one
+// at a time leaves the queue idle between rule evaluations; with cash to
+// spare the infantry rules queue several at once. A batch only ever spends
+// half of what is left over after the savings reservations, so it can't eat
+// into the war factory, tech or vehicle money.
+
+// batchSpareShare is the share of spare cash (above the active savings
+// reservations) one batch may spend.
+const batchSpareShare = 0.5
+
+// savingsReserve returns the cash the active savings hold back: the largest
+// reservation not yet satisfied or relaxed. buildCashCondition checks each
+// reservation on its own, so they don't add up.
+func savingsReserve(env RuleEnv, savings []buildingSaving) int {
+	reserve := 0
+	for _, s := range savings {
+		if env.SavingRelaxed(s.name) || !savingActive(env, s) {
+			continue
+		}
+		reserve = max(reserve, s.cost)
+	}
+	return reserve
+}
+
+// batchSize returns how many of item to queue: at most maxBatch, no more
+// than leaves the unit count at unitCap, and no more than batchSpareShare of
+// the cash above the savings reserve pays for. Never less than 1.
+func batchSize(env RuleEnv, item string, maxBatch, unitCap int, savings []buildingSaving) int {
+	cost := costOf(item)
+	if maxBatch <= 1 || cost <= 0 {
+		return 1
+	}
+	spare := env.Cash() - savingsReserve(env, savings)
+	n := min(maxBatch, unitCap-env.UnitCount(item), int(float64(spare)*batchSpareShare)/cost)
+	return max(1, n)
+}
+
+// ProduceInfantryBatch queues basic infantry in doctrine-sized batches,
+// spread over the free infantry queues.
+func ProduceInfantryBatch(maxBatch, unitCap int, savings []buildingSaving) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		item := env.Mod.basicInfantry()
+		n := batchSize(env, item, maxBatch, unitCap, savings)
+		cmds := env.produceCommands(QueueInfantry, item)
+		if len(cmds) > n {
+			cmds = cmds[:n] // the reserve leaves less than a unit per free queue
+		}
+		// Spread the batch: every queue gets one, the rest go round-robin.
+		for i := len(cmds); i < n; i++ {
+			cmds[i%len(cmds)].Count++
+		}
+		slog.Debug("producing infantry", "item", item, "count", n, "queues", len(cmds))
+		for _, cmd := range cmds {
+			if err := conn.Send(ipc.TypeProduce, cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
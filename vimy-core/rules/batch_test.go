@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestBatchSize(t *testing.T) {
+	rifle := costOf(RifleInfantry)
+	env := RuleEnv{
+		State: model.GameState{
+			Player:    model.Player{Cash: 20 * rifle},
+			Buildings: []model.Building{{ID: 1, Type: "fact"}, {ID: 2, Type: "dome"}},
+			Units:     []model.Unit{{ID: 10, Type: RifleInfantry}, {ID: 11, Type: RifleInfantry}},
+		},
+		Memory: map[string]any{},
+	}
+	warFactory := []buildingSaving{{`HasRole("war_factory")`, roleCost("war_factory"), "war_factory"}}
+
+	tests := []struct {
+		name     string
+		cash     int
+		maxBatch int
+		unitCap  int
+		savings  []buildingSaving
+		want     int
+	}{
+		{"plenty of cash", 20 * rifle, 5, 20, nil, 5},
+		{"batching disabled", 20 * rifle, 1, 20, nil, 1},
+		{"unit cap", 20 * rifle, 5, 4, nil, 2},
+		{"half the spare cash", 6 * rifle, 5, 20, nil, 3},
+		{"reserve eats the spare", roleCost("war_factory") + 2*rifle, 5, 20, warFactory, 1},
+		{"above the reserve", roleCost("war_factory") + 8*rifle, 5, 20, warFactory, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.State.Player.Cash = tt.cash
+			if got := batchSize(env, RifleInfantry, tt.maxBatch, tt.unitCap, tt.savings); got != tt.want {
+				t.Errorf("batchSize = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// A relaxed reservation holds nothing back.
+	deadlockWatchMemory.set(env.Memory, &deadlockWatch{Relaxed: []string{"war_factory"}, RelaxedUntil: 100})
+	env.State.Player.Cash = roleCost("war_factory") + 2*rifle
+	if got := batchSize(env, RifleInfantry, 5, 20, warFactory); got != 5 {
+		t.Errorf("batchSize with the reservation relaxed = %d, want 5", got)
+	}
+}
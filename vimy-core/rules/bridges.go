@@ -0,0 +1,177 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Bridges. On river maps the bridges are the land routes between bases: the
+// terrain grid marks the zones holding bridge tiles, and the bridges
+// themselves are reported as destructible obstacles once seen. Ground
+// defenses go first at our end of each bridge on our side of the map, so
+// the crossing is covered rather than open perimeter; a turtling doctrine
+// goes further and knocks down the bridge on the enemy's route to us.
+
+const (
+	bridgeDefendRange = 20.0        // cells from our nearest building for a bridge to be ours to hold
+	bridgeCoverRadius = 6.0         // a defense this close to a bridge approach already covers it
+	bridgeRouteAngle  = math.Pi / 4 // how far off the line to the enemy base a bridge still carries its route
+)
+
+// bridgeWatch remembers which bridges we have placed a defense at, so a
+// placement the mod shifts away from the hint doesn't draw another.
+type bridgeWatch struct {
+	Defended map[int]int // by terrain grid zone, tick the defense was placed
+}
+
+// isBridge reports whether obstacle type t is a bridge span. RA names its
+// bridges bridge1–4, sbridge1–4 and br1–3; the repair huts are left alone.
+func isBridge(t string) bool {
+	t = strings.ToLower(t)
+	if strings.Contains(t, "hut") {
+		return false
+	}
+	return strings.Contains(t, "bridge") || (len(t) == 3 && strings.HasPrefix(t, "br") && t[2] >= '0' && t[2] <= '9')
+}
+
+// bridgeCrossing is a bridge zone of the terrain grid and the land zone at
+// its end nearest our base.
+type bridgeCrossing struct {
+	Zone   int // row*Cols + col
+	X, Y   int // zone centre
+	AX, AY int // our approach
+}
+
+// ourBridges returns the bridge zones within bridgeDefendRange of our base
+// and nearer it than the nearest known enemy base. Mid-span zones with no
+// land beside them are left out; the zones at either end cover the bridge.
+func (e RuleEnv) ourBridges() []bridgeCrossing {
+	g := e.Terrain
+	if g == nil || len(e.State.Buildings) == 0 {
+		return nil
+	}
+	cx, cy := e.BuildingCentroid()
+	base := e.NearestEnemyBase()
+	var out []bridgeCrossing
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			if g.At(col, row) != model.Bridge {
+				continue
+			}
+			x, y := g.ZoneCenter(col, row)
+			if e.nearestOwnBuilding(x, y) > bridgeDefendRange {
+				continue
+			}
+			if base != nil && math.Hypot(float64(base.X-x), float64(base.Y-y)) <= math.Hypot(float64(cx-x), float64(cy-y)) {
+				continue
+			}
+			c := bridgeCrossing{Zone: row*g.Cols + col, X: x, Y: y}
+			bestDist := math.MaxFloat64
+			for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nc, nr := col+d[0], row+d[1]
+				if nc < 0 || nr < 0 || nc >= g.Cols || nr >= g.Rows || g.At(nc, nr) != model.Land {
+					continue
+				}
+				ax, ay := g.ZoneCenter(nc, nr)
+				if dist := math.Hypot(float64(cx-ax), float64(cy-ay)); dist < bestDist {
+					bestDist = dist
+					c.AX, c.AY = ax, ay
+				}
+			}
+			if bestDist < math.MaxFloat64 {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// bridgeHint returns our approach to the nearest of our bridges that no
+// defense covers yet, and records the bridge as defended.
+func bridgeHint(env RuleEnv) (x, y int, ok bool) {
+	w, _ := bridgeWatchMemory.get(env.Memory)
+	if w == nil {
+		w = &bridgeWatch{Defended: make(map[int]int)}
+		bridgeWatchMemory.set(env.Memory, w)
+	}
+	var best *bridgeCrossing
+	bestDist := math.MaxFloat64
+	for _, c := range env.ourBridges() {
+		if _, done := w.Defended[c.Zone]; done || env.defenseNear(c.AX, c.AY, bridgeCoverRadius) {
+			continue
+		}
+		if d := env.nearestOwnBuilding(c.AX, c.AY); d < bestDist {
+			bestDist = d
+			best = &c
+		}
+	}
+	if best == nil {
+		return 0, 0, false
+	}
+	w.Defended[best.Zone] = env.State.Tick
+	return best.AX, best.AY, true
+}
+
+// defenseNear reports whether one of our ground defenses stands within r
+// cells of (x, y).
+func (e RuleEnv) defenseNear(x, y int, r float64) bool {
+	for _, b := range e.State.Buildings {
+		if e.defenseRole(b.Type) != "" && e.defenseRole(b.Type) != "aa_defense" && math.Hypot(float64(b.X-x), float64(b.Y-y)) <= r {
+			return true
+		}
+	}
+	return false
+}
+
+// bridgeToDemolish returns the visible standing bridge nearest our base that
+// is within bridgeDefendRange of it and on the line to the nearest known
+// enemy base. Nil without a known enemy base.
+func (e RuleEnv) bridgeToDemolish() *model.Obstacle {
+	base := e.NearestEnemyBase()
+	if base == nil || len(e.State.Buildings) == 0 {
+		return nil
+	}
+	cx, cy := e.BuildingCentroid()
+	toEnemy := math.Atan2(float64(base.Y-cy), float64(base.X-cx))
+	var best *model.Obstacle
+	bestDist := math.MaxFloat64
+	for i := range e.State.Obstacles {
+		o := &e.State.Obstacles[i]
+		if !isBridge(o.Type) || o.HP <= 0 {
+			continue
+		}
+		d := e.nearestOwnBuilding(o.X, o.Y)
+		if d > bridgeDefendRange || d >= bestDist {
+			continue
+		}
+		if angleBetween(math.Atan2(float64(o.Y-cy), float64(o.X-cx)), toEnemy) > bridgeRouteAngle {
+			continue
+		}
+		best, bestDist = o, d
+	}
+	return best
+}
+
+// HasBridgeToDemolish reports whether a bridge on the enemy's route to us
+// is in sight.
+func (e RuleEnv) HasBridgeToDemolish() bool { return e.bridgeToDemolish() != nil }
+
+// ActionDemolishBridge has idle unassigned ground units force-fire the
+// bridge on the enemy's route until it falls.
+func ActionDemolishBridge(env RuleEnv, conn *ipc.Connection) error {
+	o := env.bridgeToDemolish()
+	units := env.UnassignedIdleGround()
+	if o == nil || len(units) == 0 {
+		return nil
+	}
+	ids := make([]uint32, len(units))
+	for i, u := range units {
+		ids[i] = uint32(u.ID)
+	}
+	slog.Info("demolishing bridge on the enemy's route", "bridge", o.ID, "type", o.Type, "x", o.X, "y", o.Y, "units", len(ids))
+	return conn.Send(ipc.TypeForceAttackGround, ipc.ForceAttackGroundCommand{ActorIDs: ids, X: o.X, Y: o.Y})
+}
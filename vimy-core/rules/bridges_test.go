@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestBridgeDefenseAndDemolition(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	// A river runs down column 3 with a bridge at row 2; our base is west of
+	// it, the enemy's east.
+	grid := &model.TerrainGrid{Cols: 10, Rows: 10, CellW: 10, CellH: 10, Grid: make([]model.TerrainType, 100)}
+	for row := range 10 {
+		grid.Grid[row*10+3] = model.Water
+	}
+	grid.Grid[2*10+3] = model.Bridge
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+			Units:     []model.Unit{{ID: 2, Type: "2tnk", X: 22, Y: 22, Idle: true}},
+			Obstacles: []model.Obstacle{{ID: 9, Type: "br1", X: 35, Y: 25, HP: 500, MaxHP: 500}},
+		},
+		Terrain: grid,
+		Memory:  map[string]any{"enemyBases": map[string]EnemyBaseIntel{"enemy": {X: 90, Y: 25}}},
+	}
+
+	if x, y, ok := bridgeHint(env); !ok || x != 25 || y != 25 {
+		t.Fatalf("bridgeHint = (%d, %d, %v), want our approach (25, 25)", x, y, ok)
+	}
+	if _, _, ok := bridgeHint(env); ok {
+		t.Error("bridgeHint placed a second defense at the same bridge")
+	}
+
+	if o := env.bridgeToDemolish(); o == nil || o.ID != 9 {
+		t.Fatalf("bridgeToDemolish = %+v, want bridge 9", o)
+	}
+	if err := ActionDemolishBridge(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	// Obstacle clearing must not shoot the bridge the route crosses.
+	if o := env.obstacleOnRoute(22, 25, 90, 25, 100); o != nil {
+		t.Errorf("obstacleOnRoute = %+v, want bridges skipped", o)
+	}
+
+	// Once the enemy base is known to be on our side, the bridge is theirs.
+	env.Memory = map[string]any{"enemyBases": map[string]EnemyBaseIntel{"enemy": {X: 40, Y: 30}}}
+	if got := env.ourBridges(); len(got) != 0 {
+		t.Errorf("ourBridges = %+v, want none nearer the enemy", got)
+	}
+}
+
+func TestCompileDemolishBridge(t *testing.T) {
+	turtle := DefaultDoctrine()
+	turtle.Aggression = 0.1
+	turtle.GroundDefensePriority = 0.9
+	if findRule(CompileDoctrine(turtle), "demolish-bridge") == nil {
+		t.Error("turtling doctrine should demolish bridges")
+	}
+	if findRule(CompileDoctrine(DefaultDoctrine()), "demolish-bridge") != nil {
+		t.Error("balanced doctrine should not demolish bridges")
+	}
+}
@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Tick budget. On huge game states an evaluation can take longer than the
+// game takes to send the next state, and the agent falls further behind
+// every tick. With a budget set, once a tick has spent budgetShedFraction of
+// it the engine stops running the sheddable categories — unit micro and
+// scouting, which only polish what the rest of the rules decide — for the
+// rest of that tick. A tick that overruns the budget outright sheds them
+// from the start of the next, since the memory updates that ran before the
+// first rule are likely the expensive part.
+
+// budgetShedFraction is how much of the budget a tick may spend before it
+// starts shedding.
+const budgetShedFraction = 0.8
+
+// sheddableCategories are skipped first when a tick runs short of time.
+var sheddableCategories = map[string]bool{
+	"micro":  true,
+	"recon":  true,
+	"patrol": true,
+}
+
+// tickClock times one evaluation against the budget.
+type tickClock struct {
+	start    time.Time
+	budget   time.Duration
+	shedding bool // sheddable categories are being skipped
+	skipped  int  // sheddable rules skipped this tick
+}
+
+func newTickClock(budget time.Duration, overran bool) *tickClock {
+	return &tickClock{start: time.Now(), budget: budget, shedding: budget > 0 && overran}
+}
+
+// shed reports whether a rule of the category should be skipped, starting
+// to shed once the tick has spent budgetShedFraction of its budget.
+func (c *tickClock) shed(category string) bool {
+	if !c.sheddable(category) {
+		return false
+	}
+	if !c.shedding && time.Since(c.start) >= time.Duration(float64(c.budget)*budgetShedFraction) {
+		c.shedding = true
+	}
+	if c.shedding {
+		c.skipped++
+	}
+	return c.shedding
+}
+
+// sheddable reports whether rules of the category may be shed this tick.
+func (c *tickClock) sheddable(category string) bool {
+	return c.budget > 0 && sheddableCategories[category]
+}
+
+// SetTickBudget sets how long one Evaluate may take before it sheds
+// low-priority categories; 0 disables the budget.
+func (e *Engine) SetTickBudget(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.budget = max(d, 0)
+	slog.Info("tick budget set", "budget", e.budget)
+}
+
+// TickBudget returns the evaluation deadline per tick; 0 is none.
+func (e *Engine) TickBudget() time.Duration {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.budget
+}
+
+// DegradedTicks returns how many ticks since the engine started have shed
+// low-priority categories to stay within the budget.
+func (e *Engine) DegradedTicks() int {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	return e.degradedTicks
+}
+
+// finishTick records how the tick went against the budget and logs when
+// the engine starts or stops degrading. Caller must hold memMu.
+func (e *Engine) finishTick(c *tickClock, tick int) {
+	if c.budget <= 0 {
+		return
+	}
+	elapsed := time.Since(c.start)
+	e.overran = elapsed > c.budget
+	degraded := c.skipped > 0
+	if degraded {
+		e.degradedTicks++
+	}
+	switch {
+	case degraded && !e.degraded:
+		slog.Warn("tick over budget: shedding low-priority rules", "tick", tick, "elapsed", elapsed, "budget", c.budget, "skipped", c.skipped)
+	case !degraded && e.degraded:
+		slog.Info("tick back within budget", "tick", tick, "elapsed", elapsed, "budget", c.budget)
+	case degraded:
+		slog.Debug("tick over budget", "tick", tick, "elapsed", elapsed, "budget", c.budget, "skipped", c.skipped)
+	}
+	e.degraded = degraded
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestTickBudgetShedsLowPriorityCategories(t *testing.T) {
+	const budget = 20 * time.Millisecond
+	slow := true
+	var ran []string
+	var tick int
+	record := func(name string) ActionFunc {
+		return func(env RuleEnv, conn *ipc.Connection) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+	rules := []*Rule{
+		{
+			Name: "slow", Priority: 300, Category: "economy", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				if slow {
+					time.Sleep(budget)
+				}
+				ran = append(ran, "slow")
+				return nil
+			},
+		},
+		{Name: "micro", Priority: 200, Category: "micro", ConditionSrc: "true", Action: record("micro")},
+		{Name: "combat", Priority: 100, Category: "combat", ConditionSrc: "true", Action: record("combat")},
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	evaluate := func() []string {
+		ran = nil
+		tick++
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+		return ran
+	}
+
+	// Without a budget every rule runs however long the tick takes.
+	if got := evaluate(); !slices.Equal(got, []string{"slow", "micro", "combat"}) {
+		t.Errorf("unbudgeted tick ran %v, want all three", got)
+	}
+
+	engine.SetTickBudget(budget)
+	if got := evaluate(); !slices.Equal(got, []string{"slow", "combat"}) {
+		t.Errorf("slow tick ran %v, want micro shed", got)
+	}
+	// The overrun carries into the next tick, which sheds from the start.
+	slow = false
+	if got := evaluate(); !slices.Equal(got, []string{"slow", "combat"}) {
+		t.Errorf("tick after an overrun ran %v, want micro shed", got)
+	}
+	if got := evaluate(); !slices.Equal(got, []string{"slow", "micro", "combat"}) {
+		t.Errorf("fast tick ran %v, want all three", got)
+	}
+	if n := engine.DegradedTicks(); n != 2 {
+		t.Errorf("DegradedTicks() = %d, want 2", n)
+	}
+}
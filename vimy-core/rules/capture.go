@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Enemy production capture. High-CapturePriority doctrines try to steal a
+// damaged enemy construction yard or war factory mid-assault rather than
+// level it — but only with an attack squad on hand to cover the engineer.
+const (
+	enemyCaptureHPPct   = 0.5  // only buildings at or below this health are worth the engineer
+	captureCoverRadius  = 10.0 // cells: an attack squad this close provides cover
+	captureHoldTicks    = 500  // spare the target from squad fire this long after committing
+	engineerNearCapture = 8.0  // cells: close enough to walk in without an APC
+)
+
+// enemyCaptureTypes are the enemy buildings worth capturing.
+var enemyCaptureTypes = []string{ConstructionYard, WarFactory}
+
+// captureHold marks an enemy building an engineer has been committed to.
+// Squads skip it in target selection so they don't destroy the prize, and
+// the engineer, when known, belongs to the capture (see unitOwners).
+type captureHold struct {
+	EnemyID  int
+	Engineer int // 0 when the engineer is still aboard an APC
+	Tick     int
+}
+
+func getCaptureHold(memory map[string]any) *captureHold {
+	if v, ok := captureHoldMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// heldForCapture reports whether squads should hold fire on the enemy.
+func (e RuleEnv) heldForCapture(id int) bool {
+	h := getCaptureHold(e.Memory)
+	return h != nil && h.EnemyID == id && e.State.Tick-h.Tick < captureHoldTicks
+}
+
+// EnemyCaptureTarget returns a damaged enemy construction yard or war
+// factory covered by the ground attack squad, nearest the squad first, or
+// nil. The squad centroid must be within captureCoverRadius.
+func (e RuleEnv) EnemyCaptureTarget() *model.Enemy {
+	sq, ok := getSquads(e.Memory)["ground-attack"]
+	if !ok {
+		return nil
+	}
+	sx, sy, n := squadCentroid(sq, e.units().ByID())
+	if n == 0 {
+		return nil
+	}
+
+	var best *model.Enemy
+	bestDist := math.MaxFloat64
+	for i := range e.State.Enemies {
+		en := &e.State.Enemies[i]
+		if en.MaxHP == 0 || float64(en.HP)/float64(en.MaxHP) > enemyCaptureHPPct {
+			continue
+		}
+		capturable := false
+		for _, t := range enemyCaptureTypes {
+			if matchesType(en.Type, t) {
+				capturable = true
+				break
+			}
+		}
+		if !capturable {
+			continue
+		}
+		d := math.Hypot(float64(en.X-sx), float64(en.Y-sy))
+		if d <= captureCoverRadius && d < bestDist {
+			bestDist = d
+			best = en
+		}
+	}
+	return best
+}
+
+// EngineerNearEnemyCaptureTarget reports whether an idle engineer is close
+// enough to the enemy capture target to walk in.
+func (e RuleEnv) EngineerNearEnemyCaptureTarget() bool {
+	target := e.EnemyCaptureTarget()
+	if target == nil {
+		return false
+	}
+	for _, eng := range e.IdleEngineers() {
+		if math.Hypot(float64(eng.X-target.X), float64(eng.Y-target.Y)) < engineerNearCapture {
+			return true
+		}
+	}
+	return false
+}
+
+// captureDestination is where capture APCs should deliver engineers: a
+// covered enemy capture target when there is one, else the best neutral
+// capturable.
+func (e RuleEnv) captureDestination() *model.Enemy {
+	if t := e.EnemyCaptureTarget(); t != nil {
+		return t
+	}
+	return e.NearestCapturable()
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func captureEnv(yardHP int) RuleEnv {
+	return RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Units: []model.Unit{
+				{ID: 2, Type: "2tnk", X: 60, Y: 60},
+				{ID: 3, Type: "2tnk", X: 62, Y: 60},
+				{ID: 4, Type: "e6", X: 64, Y: 62, Idle: true},
+			},
+			Enemies: []model.Enemy{
+				{ID: 50, Type: "fact", X: 65, Y: 62, HP: yardHP, MaxHP: 1000},
+				{ID: 51, Type: "powr", X: 66, Y: 66, HP: 100, MaxHP: 1000},
+			},
+		},
+		Memory: map[string]any{"squads": map[string]*Squad{
+			"ground-attack": {Name: "ground-attack", Domain: "ground", UnitIDs: []int{2, 3}},
+		}},
+	}
+}
+
+func TestEnemyCaptureTarget(t *testing.T) {
+	if got := captureEnv(900).EnemyCaptureTarget(); got != nil {
+		t.Errorf("healthy yard should not be a capture target, got %+v", got)
+	}
+	env := captureEnv(300)
+	got := env.EnemyCaptureTarget()
+	if got == nil || got.ID != 50 {
+		t.Fatalf("EnemyCaptureTarget = %+v, want yard 50", got)
+	}
+	if !env.EngineerNearEnemyCaptureTarget() {
+		t.Error("engineer 4 is next to the yard")
+	}
+
+	// Without a covering squad nearby there's no target.
+	env.Memory["squads"].(map[string]*Squad)["ground-attack"].UnitIDs = nil
+	if got := env.EnemyCaptureTarget(); got != nil {
+		t.Errorf("uncovered yard should not be targeted, got %+v", got)
+	}
+}
+
+func TestCaptureHoldSparesTarget(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := captureEnv(300)
+	if err := ActionCaptureEnemyProduction(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if got := env.BestGroundTarget(); got == nil || got.ID == 50 {
+		t.Errorf("BestGroundTarget = %+v, want squads to hold fire on the yard", got)
+	}
+
+	env.State.Tick += captureHoldTicks
+	if env.heldForCapture(50) {
+		t.Error("capture hold should expire")
+	}
+}
@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CategoryPolicy limits how much one rule category may do in a tick, on top
+// of Exclusive. Exclusivity stops a category at its first matching exclusive
+// rule; a policy caps the non-exclusive rest and decides which of several
+// equal-priority rules goes first.
+type CategoryPolicy struct {
+	MaxRules    int    `json:"max_rules,omitempty"`    // rule actions run per tick; 0 is unlimited
+	MaxCommands int    `json:"max_commands,omitempty"` // commands those actions send per tick; 0 is unlimited
+	TieBreak    string `json:"tie_break,omitempty"`    // order of equal-priority rules; see tieBreaks
+}
+
+// Tie-break strategies for rules of equal priority in one category.
+const (
+	TieBreakDeclared    = "declared"     // the order the rules were compiled in (the default)
+	TieBreakName        = "name"         // alphabetical by rule name
+	TieBreakLeastRecent = "least_recent" // the rule whose action ran longest ago first, so equals take turns
+	TieBreakRandom      = "random"       // shuffled each tick from the engine's seeded source
+)
+
+var tieBreaks = []string{TieBreakDeclared, TieBreakName, TieBreakLeastRecent, TieBreakRandom}
+
+// CategoryPolicies is keyed by rule category (e.g. "combat", "micro").
+// Categories without an entry are unlimited, in declared order.
+type CategoryPolicies map[string]CategoryPolicy
+
+// LoadCategoryPolicies reads a JSON category policies file, e.g.
+//
+//	{"micro": {"max_rules": 3, "max_commands": 20},
+//	 "combat": {"tie_break": "least_recent"}}
+func LoadCategoryPolicies(path string) (CategoryPolicies, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read category policies: %w", err)
+	}
+	var p CategoryPolicies
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal category policies: %w", err)
+	}
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check rejects negative limits and unknown tie-break strategies.
+func (p CategoryPolicies) Check() error {
+	for cat, cp := range p {
+		if cp.MaxRules < 0 || cp.MaxCommands < 0 {
+			return fmt.Errorf("category policy %q: limits must not be negative", cat)
+		}
+		if cp.TieBreak != "" && !slices.Contains(tieBreaks, cp.TieBreak) {
+			return fmt.Errorf("category policy %q: unknown tie_break %q (known: %s)", cat, cp.TieBreak, strings.Join(tieBreaks, ", "))
+		}
+	}
+	return nil
+}
+
+// limited reports whether the policy caps the category per tick at all.
+func (cp CategoryPolicy) limited() bool {
+	return cp.MaxRules > 0 || cp.MaxCommands > 0
+}
+
+// spent reports whether a category that has run ran actions sending sent
+// commands this tick has reached its limits.
+func (cp CategoryPolicy) spent(ran, sent int) bool {
+	return (cp.MaxRules > 0 && ran >= cp.MaxRules) || (cp.MaxCommands > 0 && sent >= cp.MaxCommands)
+}
+
+// orderTies returns rules, sorted by descending priority, with each
+// category's equal-priority rules reordered by its tie-break strategy. A
+// category's rules keep the slots they held, so other categories' rules of
+// the same priority are unaffected. lastRan holds the tick each rule's
+// action last ran. rules itself is left untouched.
+func orderTies(rules []*Rule, policies CategoryPolicies, rng *rand.Rand, lastRan map[string]int) []*Rule {
+	out := slices.Clone(rules)
+	for start := 0; start < len(out); {
+		end := start + 1
+		for end < len(out) && out[end].Priority == out[start].Priority {
+			end++
+		}
+		if end-start > 1 {
+			reorderGroup(out[start:end], policies, rng, lastRan)
+		}
+		start = end
+	}
+	return out
+}
+
+// reorderGroup applies each category's tie-break within one run of
+// equal-priority rules.
+func reorderGroup(group []*Rule, policies CategoryPolicies, rng *rand.Rand, lastRan map[string]int) {
+	slots := make(map[string][]int)
+	for i, r := range group {
+		slots[r.Category] = append(slots[r.Category], i)
+	}
+	for cat, idx := range slots {
+		tb := policies[cat].TieBreak
+		if len(idx) < 2 || tb == "" || tb == TieBreakDeclared {
+			continue
+		}
+		members := make([]*Rule, len(idx))
+		for i, j := range idx {
+			members[i] = group[j]
+		}
+		switch tb {
+		case TieBreakName:
+			slices.SortStableFunc(members, func(a, b *Rule) int { return strings.Compare(a.Name, b.Name) })
+		case TieBreakLeastRecent:
+			// Rules that never ran sort first, as if they last ran at tick -1.
+			last := func(r *Rule) int {
+				if t, ok := lastRan[r.Name]; ok {
+					return t
+				}
+				return -1
+			}
+			slices.SortStableFunc(members, func(a, b *Rule) int { return last(a) - last(b) })
+		case TieBreakRandom:
+			if rng != nil {
+				rng.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+			}
+		}
+		for i, j := range idx {
+			group[j] = members[i]
+		}
+	}
+}
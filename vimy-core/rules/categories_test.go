@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCategoryPolicyLimits(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	var ran []string
+	rule := func(name string, priority, sends int) *Rule {
+		return &Rule{
+			Name: name, Priority: priority, Category: "micro", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				ran = append(ran, name)
+				for range sends {
+					if err := conn.Send(ipc.TypeMove, ipc.MoveCommand{}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+	}
+	engine, err := NewEngine([]*Rule{rule("a", 30, 2), rule("b", 20, 2), rule("c", 10, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.SetCategoryPolicies(CategoryPolicies{"micro": {MaxRules: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 1}, "soviet", conn); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 2 || ran[0] != "a" || ran[1] != "b" {
+		t.Errorf("max_rules 2 ran %v, want [a b]", ran)
+	}
+
+	ran = nil
+	if err := engine.SetCategoryPolicies(CategoryPolicies{"micro": {MaxCommands: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 2}, "soviet", conn); err != nil {
+		t.Fatal(err)
+	}
+	// a sends 2, b takes the category to 4; c is skipped.
+	if len(ran) != 2 {
+		t.Errorf("max_commands 3 ran %v, want [a b]", ran)
+	}
+
+	if err := engine.SetCategoryPolicies(CategoryPolicies{"micro": {TieBreak: "alphabetical"}}); err == nil {
+		t.Error("unknown tie_break accepted")
+	}
+}
+
+func TestCategoryTieBreakLeastRecent(t *testing.T) {
+	var ran []string
+	rule := func(name string) *Rule {
+		return &Rule{
+			Name: name, Priority: 10, Category: "combat", Exclusive: true, ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				ran = append(ran, name)
+				return nil
+			},
+		}
+	}
+	engine, err := NewEngine([]*Rule{rule("x"), rule("y"), rule("z")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.SetCategoryPolicies(CategoryPolicies{"combat": {TieBreak: TieBreakLeastRecent}}); err != nil {
+		t.Fatal(err)
+	}
+	for tick := 1; tick <= 4; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Exclusive equals take turns instead of the first starving the rest.
+	want := []string{"x", "y", "z", "x"}
+	for i := range want {
+		if i >= len(ran) || ran[i] != want[i] {
+			t.Fatalf("least_recent ran %v, want %v", ran, want)
+		}
+	}
+}
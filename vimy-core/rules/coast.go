@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Coastline analysis for naval yards. MapHasWater only says there is water
+// somewhere; a yard can only go on water the mod lets us build next to, so
+// with the only sea across the map the yard sits in the queue forever. A
+// naval yard is worth producing only when a water zone comes within
+// navalYardReach of one of our buildings, and its placement hint is the
+// nearest such water, so the mod searches the right shore.
+
+// navalYardReach is how far (in cells) from one of our buildings water must
+// be for a naval yard to be placed on it: the mod's build adjacency plus the
+// yard's own footprint.
+const navalYardReach = 8.0
+
+// nearestBuildableWater returns the water cell within navalYardReach of our
+// buildings that is closest to any of them. Each terrain zone is a rectangle
+// of map cells, so the candidate from a water zone is its point nearest the
+// building. ok is false with no buildings, no terrain or no water in reach.
+func (e RuleEnv) nearestBuildableWater() (x, y int, ok bool) {
+	g := e.Terrain
+	if g == nil || g.CellW <= 0 || g.CellH <= 0 || len(e.State.Buildings) == 0 {
+		return 0, 0, false
+	}
+	best := navalYardReach
+	for row := range g.Rows {
+		for col := range g.Cols {
+			if g.At(col, row) != model.Water {
+				continue
+			}
+			x0, y0 := col*g.CellW, row*g.CellH
+			x1, y1 := x0+g.CellW-1, y0+g.CellH-1
+			for _, b := range e.State.Buildings {
+				px, py := min(max(b.X, x0), x1), min(max(b.Y, y0), y1)
+				if d := math.Hypot(float64(px-b.X), float64(py-b.Y)); d <= best {
+					best, x, y, ok = d, px, py, true
+				}
+			}
+		}
+	}
+	return x, y, ok
+}
+
+// CoastInReach reports whether a naval yard has water it can be built on
+// near the base. Without terrain data it assumes so, as MapHasWater does.
+func (e RuleEnv) CoastInReach() bool {
+	if e.Terrain == nil {
+		return true
+	}
+	_, _, ok := e.nearestBuildableWater()
+	return ok
+}
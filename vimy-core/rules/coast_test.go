@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// coastTerrain is a 128x128 map in 8x8 zones of 16 cells with water only in
+// the given columns.
+func coastTerrain(waterCols ...int) *model.TerrainGrid {
+	g := &model.TerrainGrid{Cols: 8, Rows: 8, CellW: 16, CellH: 16, Grid: make([]model.TerrainType, 64)}
+	for row := range 8 {
+		for _, col := range waterCols {
+			g.Grid[row*8+col] = model.Water
+		}
+	}
+	return g
+}
+
+func TestCoastInReach(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 20, Y: 40},
+				{ID: 2, Type: "powr", X: 26, Y: 40},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	// The only sea is on the far side of the map.
+	env.Terrain = coastTerrain(7)
+	if env.CoastInReach() {
+		t.Error("water 90 cells away counted as in reach")
+	}
+	if _, _, ok := placementHint(env, "syrd"); ok {
+		t.Error("naval yard hinted with no water in reach")
+	}
+
+	// Water starts at x=32, six cells past the power plant.
+	env.Terrain = coastTerrain(2, 7)
+	if !env.CoastInReach() {
+		t.Fatal("shore six cells from the base not in reach")
+	}
+	x, y, ok := placementHint(env, "spen")
+	if !ok || x != 32 || y != 40 {
+		t.Errorf("sub pen hint = (%d, %d) ok %v, want the near shore (32, 40)", x, y, ok)
+	}
+
+	env.Terrain = nil
+	if !env.CoastInReach() {
+		t.Error("without terrain the coast should be assumed in reach")
+	}
+}
+
+func TestNavalYardRulesRequireCoast(t *testing.T) {
+	d := DefaultDoctrine()
+	d.NavalWeight = 0.9
+	for _, name := range []string{"build-naval-yard", "build-extra-naval-yard", "rebuild-naval-yard"} {
+		r := findRule(CompileDoctrine(d), name)
+		if r == nil {
+			t.Fatalf("missing %s", name)
+		}
+		if !containsConjunct(r.ConditionSrc, "CoastInReach()") {
+			t.Errorf("%s not gated on CoastInReach(): %s", name, r.ConditionSrc)
+		}
+	}
+}
+
+func containsConjunct(src, c string) bool {
+	for _, part := range conjuncts(src) {
+		if part == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Combined-arms strikes. On its own the air squad strikes whenever it is
+// ready, so the defenders it hits have rebuilt or repaired by the time the
+// ground assault arrives, and the ground squad walks into AA that was never
+// touched. With a combined-arms doctrine the air squad holds while a ground
+// attack squad exists and launches only once that squad's attack run has
+// closed to within the launch range of its target, striking the enemies
+// around the ground squad so both hit the same defenses together.
+const (
+	combinedMinLaunch    = 10.0 // cells out the tightest doctrine launches air
+	combinedMaxLaunch    = 24.0 // cells out the loosest doctrine launches air
+	combinedSupportRange = 14.0 // cells around the ground squad air strikes look for targets
+)
+
+// combinedLaunchRange returns how close (in cells) the ground squad must be
+// to its target before air launches. Aircraft cover ground several times
+// faster than tanks, so a stronger commitment waits until the ground squad
+// is nearly there.
+func (d Doctrine) combinedLaunchRange() float64 {
+	return lerpf(combinedMaxLaunch, combinedMinLaunch, d.CombinedArms)
+}
+
+// groundStrike returns the in-flight attack run of a ground squad, or nil.
+func (e RuleEnv) groundStrike(name string) *attackRun {
+	if getSquads(e.Memory)[name] == nil {
+		return nil
+	}
+	return getAttackRuns(e.Memory)[name]
+}
+
+// GroundStrikeWithin reports whether the named squad's attack run has
+// brought it within within cells of the target it was launched at.
+func (e RuleEnv) GroundStrikeWithin(name string, within float64) bool {
+	run := e.groundStrike(name)
+	if run == nil {
+		return false
+	}
+	return math.Hypot(float64(run.X-run.TargetX), float64(run.Y-run.TargetY)) <= within
+}
+
+// combinedStrikeTargets returns air strike targets around the ground squad's
+// fight, falling back to the usual air targets when none is in range of it.
+func (e RuleEnv) combinedStrikeTargets(ground string, strikeSize int) []*model.Enemy {
+	run := e.groundStrike(ground)
+	if run == nil {
+		return e.airStrikeTargets(strikeSize)
+	}
+	var primary *model.Enemy
+	bestScore := -1.0
+	for i := range e.State.Enemies {
+		en := &e.State.Enemies[i]
+		if en.MaxHP == 0 || e.airStrikeSizeFor(en) > strikeSize {
+			continue
+		}
+		if math.Hypot(float64(en.X-run.X), float64(en.Y-run.Y)) > combinedSupportRange {
+			continue
+		}
+		if score := airTargetScore(en, run.X, run.Y) * e.aaRiskFactor(en); score > bestScore {
+			bestScore, primary = score, en
+		}
+	}
+	if primary == nil {
+		return e.airStrikeTargets(strikeSize)
+	}
+	return e.targetsNear(primary, airStrikeSpreadRadius, airStrikeMaxTargets, func(en *model.Enemy) float64 {
+		if e.airStrikeSizeFor(en) > strikeSize {
+			return -1
+		}
+		return airTargetScore(en, run.X, run.Y) * e.aaRiskFactor(en)
+	})
+}
+
+// SquadCombinedStrike sends the air squad's idle aircraft against the
+// defenses the ground squad is closing on, split across targets as
+// SquadAirStrike does.
+func SquadCombinedStrike(air, ground string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		ids := squadIdleActorIDs(env, air)
+		if len(ids) == 0 {
+			return nil
+		}
+		targets := env.combinedStrikeTargets(ground, len(ids))
+		if len(targets) == 0 {
+			return nil
+		}
+		types := env.unitTypes(ids)
+		env.startAttackRun(air, targets[0].X, targets[0].Y)
+		alloc := allocateAirStrike(ids, types, targets)
+		for _, t := range targets {
+			for _, id := range alloc[t.ID] {
+				env.unitDebug(int(id), "combined air strike", "squad", air, "with", ground, "unit", id, "target", t.ID)
+				if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{
+					ActorID:  id,
+					TargetID: uint32(t.ID),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
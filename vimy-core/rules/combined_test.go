@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCombinedArmsHoldsAirForGround(t *testing.T) {
+	d := DefaultDoctrine()
+	d.AirWeight = 0.6
+	if r := findRule(CompileDoctrine(d), "squad-air-combined-strike"); r != nil {
+		t.Error("combined strike compiled without combined arms")
+	}
+
+	d.CombinedArms = 0.8
+	rules := CompileDoctrine(d)
+	if findRule(rules, "squad-air-combined-strike") == nil {
+		t.Fatal("missing squad-air-combined-strike")
+	}
+	for _, name := range []string{"squad-air-attack", "squad-air-reengage", "squad-air-attack-known-base"} {
+		r := findRule(rules, name)
+		if r == nil {
+			t.Fatalf("missing %s", name)
+		}
+		if !containsConjunct(r.ConditionSrc, `!SquadExists("ground-attack")`) {
+			t.Errorf("%s strikes on its own timer: %s", name, r.ConditionSrc)
+		}
+	}
+
+	tight, loose := d, d
+	loose.CombinedArms = 0.2
+	if tight.combinedLaunchRange() >= loose.combinedLaunchRange() {
+		t.Errorf("launch range %.1f at 0.8 should be tighter than %.1f at 0.2", tight.combinedLaunchRange(), loose.combinedLaunchRange())
+	}
+}
+
+func TestCombinedStrikeFollowsGroundSquad(t *testing.T) {
+	mem := map[string]any{
+		"squads": map[string]*Squad{
+			"ground-attack": {Name: "ground-attack", Domain: "ground", Role: "attack", UnitIDs: []int{1, 2}},
+			"air-attack":    {Name: "air-attack", Domain: "air", Role: "attack", UnitIDs: []int{10}},
+		},
+	}
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{{ID: 100, Type: "fact", X: 10, Y: 10}},
+			Units: []model.Unit{
+				{ID: 1, Type: "3tnk", X: 40, Y: 40},
+				{ID: 2, Type: "3tnk", X: 42, Y: 40},
+				{ID: 10, Type: "mig", X: 12, Y: 12, Idle: true},
+			},
+			Enemies: []model.Enemy{
+				{ID: 50, Type: "fact", X: 110, Y: 110, HP: 100, MaxHP: 100},
+				{ID: 51, Type: "pbox", X: 95, Y: 100, HP: 100, MaxHP: 100},
+			},
+		},
+		Memory: mem,
+	}
+	if env.GroundStrikeWithin("ground-attack", 18) {
+		t.Error("ground squad that hasn't launched counted as closing")
+	}
+	env.startAttackRun("ground-attack", 100, 100)
+	if env.GroundStrikeWithin("ground-attack", 18) {
+		t.Error("ground squad 85 cells out counted as within 18")
+	}
+
+	// The run's position follows the squad as it closes on the pillbox.
+	for i := range env.State.Units[:2] {
+		env.State.Units[i].X, env.State.Units[i].Y = 88+i, 96
+	}
+	updateAttackRuns(env)
+	if !env.GroundStrikeWithin("ground-attack", 18) {
+		t.Fatal("ground squad 12 cells out not within 18")
+	}
+	targets := env.combinedStrikeTargets("ground-attack", 1)
+	if len(targets) == 0 || targets[0].ID != 51 {
+		t.Errorf("combined strike targets %v, want the pillbox in front of the ground squad", targets)
+	}
+}
@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"cmp"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Command audit. Evaluate hands each rule's action a view of the connection
+// (ipc.Connection.From) that attributes its commands to the rule, its
+// condition and the tick; installed as the connection's auditor,
+// RecordCommand logs every such command and keeps per-rule counts and the
+// latest commands, so any order the mod received can be traced back to the
+// rule that issued it.
+
+// commandAuditRecent is how many of the latest commands the audit keeps.
+const commandAuditRecent = 200
+
+// reconcileOrigin is the rule name the audit gives queue reconciliation's
+// cancellations, which run outside any rule (see reconcileQueues).
+const reconcileOrigin = "reconcile-queues"
+
+// RuleCommandStat counts the commands one rule has sent this game.
+type RuleCommandStat struct {
+	Rule      string         `json:"rule"`
+	Condition string         `json:"condition"`
+	Commands  int            `json:"commands"`
+	ByType    map[string]int `json:"by_type"`
+	FirstTick int            `json:"first_tick"`
+	LastTick  int            `json:"last_tick"`
+}
+
+// CommandAudit is the engine's record of the commands it has sent this
+// game.
+type CommandAudit struct {
+	Rules  []RuleCommandStat   `json:"rules"`  // most commands first
+	Recent []ipc.CommandRecord `json:"recent"` // the latest commandAuditRecent, oldest first
+}
+
+// commandLog has its own lock: RecordCommand runs inside Evaluate, which
+// already holds memMu.
+type commandLog struct {
+	mu     sync.Mutex
+	rules  map[string]*RuleCommandStat
+	recent []ipc.CommandRecord // ring buffer; next is the oldest once full
+	next   int
+}
+
+func newCommandLog() *commandLog {
+	return &commandLog{rules: make(map[string]*RuleCommandStat)}
+}
+
+func (l *commandLog) reset() {
+	l.mu.Lock()
+	clear(l.rules)
+	l.recent = l.recent[:0]
+	l.next = 0
+	l.mu.Unlock()
+}
+
+// RecordCommand logs a command sent through an attributed view of the
+// connection and adds it to the audit. Install it with
+// ipc.Connection.SetAuditor.
+func (e *Engine) RecordCommand(rec ipc.CommandRecord) {
+	slog.Debug("command sent", "type", rec.Type, "rule", rec.Origin.Rule, "tick", rec.Origin.Tick, "data", string(rec.Data))
+
+	l := e.commands
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st, ok := l.rules[rec.Origin.Rule]
+	if !ok {
+		st = &RuleCommandStat{Rule: rec.Origin.Rule, ByType: make(map[string]int), FirstTick: rec.Origin.Tick}
+		l.rules[rec.Origin.Rule] = st
+	}
+	st.Condition = rec.Origin.Condition
+	st.Commands++
+	st.ByType[rec.Type]++
+	st.LastTick = rec.Origin.Tick
+
+	if len(l.recent) < commandAuditRecent {
+		l.recent = append(l.recent, rec)
+		return
+	}
+	l.recent[l.next] = rec
+	l.next = (l.next + 1) % commandAuditRecent
+}
+
+// CommandAudit returns the commands the engine has sent this game: counts
+// per rule and the latest commands with their origins.
+func (e *Engine) CommandAudit() CommandAudit {
+	l := e.commands
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	audit := CommandAudit{
+		Rules:  make([]RuleCommandStat, 0, len(l.rules)),
+		Recent: make([]ipc.CommandRecord, 0, len(l.recent)),
+	}
+	for _, st := range l.rules {
+		cp := *st
+		cp.ByType = maps.Clone(st.ByType)
+		audit.Rules = append(audit.Rules, cp)
+	}
+	slices.SortFunc(audit.Rules, func(a, b RuleCommandStat) int {
+		return cmp.Or(b.Commands-a.Commands, cmp.Compare(a.Rule, b.Rule))
+	})
+	audit.Recent = append(audit.Recent, l.recent[l.next:]...)
+	audit.Recent = append(audit.Recent, l.recent[:l.next]...)
+	return audit
+}
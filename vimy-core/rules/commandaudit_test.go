@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCommandAuditTracesCommandsToRules(t *testing.T) {
+	send := func(msgType string, n int) ActionFunc {
+		return func(env RuleEnv, conn *ipc.Connection) error {
+			for range n {
+				if err := conn.Send(msgType, struct{}{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	engine, err := NewEngine([]*Rule{
+		{Name: "rally", Priority: 20, Category: "a", ConditionSrc: "State.Tick % 2 == 0", Action: send(ipc.TypeSetRally, 1)},
+		{Name: "swarm", Priority: 10, Category: "b", ConditionSrc: "true", Action: send(ipc.TypeAttackMove, 2)},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	conn.SetAuditor(engine.RecordCommand)
+	for tick := 1; tick <= 4; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	audit := engine.CommandAudit()
+	if len(audit.Rules) != 2 {
+		t.Fatalf("audited rules = %+v, want rally and swarm", audit.Rules)
+	}
+	swarm, rally := audit.Rules[0], audit.Rules[1]
+	if swarm.Rule != "swarm" || swarm.Commands != 8 || swarm.ByType[ipc.TypeAttackMove] != 8 || swarm.FirstTick != 1 || swarm.LastTick != 4 {
+		t.Errorf("swarm = %+v, want 8 attack-moves over ticks 1-4", swarm)
+	}
+	if rally.Rule != "rally" || rally.Commands != 2 || rally.Condition != "State.Tick % 2 == 0" || rally.FirstTick != 2 {
+		t.Errorf("rally = %+v, want 2 commands from its condition starting at tick 2", rally)
+	}
+	if len(audit.Recent) != 10 {
+		t.Fatalf("recent = %d commands, want 10", len(audit.Recent))
+	}
+	if last := audit.Recent[9]; last.Origin.Rule != "swarm" || last.Origin.Tick != 4 || last.Type != ipc.TypeAttackMove {
+		t.Errorf("latest command = %+v, want swarm's attack-move at tick 4", last)
+	}
+
+	engine.ResetMemory()
+	if audit := engine.CommandAudit(); len(audit.Rules) != 0 || len(audit.Recent) != 0 {
+		t.Errorf("audit after ResetMemory = %+v, want empty", audit)
+	}
+}
+
+func TestCommandAuditKeepsLatest(t *testing.T) {
+	engine, err := NewEngine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tick := range commandAuditRecent + 5 {
+		engine.RecordCommand(ipc.CommandRecord{Type: ipc.TypeMove, Origin: ipc.Origin{Rule: "r", Tick: tick}})
+	}
+	recent := engine.CommandAudit().Recent
+	if len(recent) != commandAuditRecent || recent[0].Origin.Tick != 5 || recent[len(recent)-1].Origin.Tick != commandAuditRecent+4 {
+		t.Errorf("recent spans ticks %d-%d (%d), want the latest %d", recent[0].Origin.Tick, recent[len(recent)-1].Origin.Tick, len(recent), commandAuditRecent)
+	}
+}
@@ -2,6 +2,10 @@ package rules
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
 )
 
 // Doctrine gate thresholds control which rule blocks CompileDoctrine emits.
@@ -39,16 +43,48 @@ const (
 
 // Gameplay thresholds used inside expr condition strings (require fmt.Sprintf).
 const (
-	LowPowerHeadroom    = 50 // PowerExcess below this triggers advanced power
+	LowPowerHeadroom    = 50 // ProjectedPowerExcess below this triggers advanced power
 	IronCurtainMinUnits = 3  // minimum idle ground units to fire iron curtain
+	CashLookahead       = 20 // seconds of projected income counted toward expensive buildings
+)
+
+// Rule cooldowns (Rule.CooldownTicks) for actions whose orders take a while
+// to play out in game.
+const (
+	deployMCVCooldown   = 50  // the sidecar needs time to process a deploy order
+	factoryExitCooldown = 100 // let pushed units clear before re-checking exits
+	medicFollowCooldown = 75  // avoid re-pathing trailing medics every tick
+	nukeEvadeCooldown   = 100 // don't re-order spreading squads every tick
+	siloStrikeCooldown  = 100 // let the silo strike order play out
+)
+
+// Tick windows (Rule.ActiveFromTick / ActiveUntilTick). The game runs at
+// 25 ticks a second.
+const (
+	rushRulesUntilTick = 2 * rushWindowTicks // a rush raised late in the window still gets 5 minutes
+	midGameTick        = 15000               // 10 minutes
 )
 
+// superweaponFromTick is when superweapon building and the cash saved for
+// it start: mid game, or as early as halfway there for doctrines built
+// around superweapons.
+func (c *doctrineCompiler) superweaponFromTick() int {
+	return lerp(midGameTick, midGameTick/2, c.d.SuperweaponPriority)
+}
+
+// defenseHoldTicks (Rule.HoldTicks) keeps units sent to defend the base out
+// of scouting and attack rules for 10s, so they finish the fight instead of
+// flapping between orders each time they go idle.
+const defenseHoldTicks = 250
+
 // buildingSaving prevents unit production from consuming cash needed for
 // a high-value building (e.g. tech center at 1500 credits). Once the
-// building exists, the savings constraint is released.
+// building exists, the savings constraint is released; the deadlock
+// watchdog can also relax it by name (see SavingRelaxed).
 type buildingSaving struct {
 	existsExpr string // expr condition that the building already exists
 	cost       int    // cash needed to queue the building
+	name       string // what the cash is reserved for
 }
 
 // doctrineCompiler holds the shared state used across all rule-generation
@@ -56,6 +92,7 @@ type buildingSaving struct {
 // and savings slices.
 type doctrineCompiler struct {
 	d               Doctrine
+	mod             *Mod
 	rules           []*Rule
 	savings         []buildingSaving
 	infantrySavings []buildingSaving
@@ -73,7 +110,7 @@ type doctrineCompiler struct {
 func buildCashCondition(unitCost int, savings []buildingSaving) string {
 	cond := fmt.Sprintf("Cash() >= %d", unitCost)
 	for _, s := range savings {
-		cond += fmt.Sprintf(` && (%s || Cash() >= %d)`, s.existsExpr, unitCost+s.cost)
+		cond += fmt.Sprintf(` && (%s || SavingRelaxed(%q) || Cash() >= %d)`, s.existsExpr, s.name, unitCost+s.cost)
 	}
 	return cond
 }
@@ -101,18 +138,21 @@ func (c *doctrineCompiler) initSavings() {
 	// that prevent any army from being built in early/mid game.
 	if c.d.VehicleWeight > DoctrineModerate {
 		// War factory requires radar. Don't reserve 2000 until radar exists.
-		c.savings = append(c.savings, buildingSaving{`HasRole("war_factory") || !HasRole("radar")`, 2000})
+		c.savings = append(c.savings, buildingSaving{`HasRole("war_factory") || !HasRole("radar")`, roleCost("war_factory"), "war_factory"})
 	}
 	if c.d.TechPriority > DoctrineHigh {
 		// Tech center requires radar. Don't reserve 1500 until radar exists.
 		// Threshold matches build-tech-center rule (DoctrineHigh) so we never
 		// reserve cash for a tech center the doctrine won't actually build.
-		c.savings = append(c.savings, buildingSaving{`HasRole("tech_center") || !HasRole("radar")`, 1500})
+		c.savings = append(c.savings, buildingSaving{`HasRole("tech_center") || !HasRole("radar")`, roleCost("tech_center"), "tech_center"})
 	}
 	if c.d.SuperweaponPriority > DoctrineHigh {
-		// Superweapons require tech center. Don't reserve 2500 until it exists.
+		// Superweapons require tech center. Don't reserve 2500 until it exists,
+		// nor before the superweapon build rules become active.
 		c.savings = append(c.savings, buildingSaving{
-			`HasRole("missile_silo") || HasRole("iron_curtain") || !HasRole("tech_center")`, 2500,
+			fmt.Sprintf(`HasRole("missile_silo") || HasRole("iron_curtain") || !HasRole("tech_center") || State.Tick < %d`, c.superweaponFromTick()),
+			min(roleCost("missile_silo"), roleCost("iron_curtain")),
+			"superweapon",
 		})
 	}
 
@@ -124,7 +164,8 @@ func (c *doctrineCompiler) initSavings() {
 	if c.d.VehicleWeight > DoctrineEnabled {
 		c.infantrySavings = append(c.infantrySavings, buildingSaving{
 			existsExpr: `HasRole("war_factory")`,
-			cost:       2000,
+			cost:       roleCost("war_factory"),
+			name:       "war_factory",
 		})
 	}
 
@@ -136,6 +177,7 @@ func (c *doctrineCompiler) initSavings() {
 		c.infantrySavings = append(c.infantrySavings, buildingSaving{
 			existsExpr: fmt.Sprintf("CombatVehicleCount() >= %d", vehicleCapForSaving),
 			cost:       800,
+			name:       "vehicles",
 		})
 	}
 }
@@ -145,14 +187,54 @@ func (c *doctrineCompiler) initSavings() {
 // relative priorities, and the thresholds in their conditions.
 // All expr strings are constructed via fmt.Sprintf — never from user input.
 func CompileDoctrine(d Doctrine) []*Rule {
+	return CompileDoctrineForMod(d, RA)
+}
+
+// CompileDoctrineForMod compiles a doctrine for the given mod: the mod's
+// rifle infantry is mass-produced, and rules that need a role the mod lacks
+// (naval yards in TD, say) are left out.
+func CompileDoctrineForMod(d Doctrine, m *Mod) []*Rule {
+	return CompileDoctrineForMap(d, m, nil)
+}
+
+// CompileDoctrineForMap compiles a doctrine for the given mod and map. When
+// terrain shows no water the naval weight is zeroed before compiling — so no
+// naval production, yards or attack squads, and no bridge bonus for them —
+// and the rules gated on MapHasWater() that every doctrine carries are left
+// out instead of failing that check each tick. A nil terrain (no grid from
+// the mod) keeps everything, as MapHasWater does.
+func CompileDoctrineForMap(d Doctrine, m *Mod, terrain *model.TerrainGrid) []*Rule {
 	d.Validate()
-	c := &doctrineCompiler{d: d}
+	dry := terrain != nil && !terrain.HasWater()
+	if dry {
+		d.NavalWeight = 0
+	}
+	c := &doctrineCompiler{d: d, mod: m}
 	c.initSavings()
 	c.addCoreRules()
+	c.rules = append(c.rules, OpeningRules(d.Opening)...)
+	c.addRushRules()
 	c.addEconomyRules()
 	c.addBuildingRules()
 	c.addProductionRules()
 	c.addCombatRules()
 	c.addMicroRules()
-	return c.rules
+	rs := pruneForMod(c.rules, m)
+	if dry {
+		rs = pruneDryMap(rs)
+	}
+	return rs
+}
+
+// pruneDryMap drops the rules that require MapHasWater().
+func pruneDryMap(rs []*Rule) []*Rule {
+	out := rs[:0:0]
+	for _, r := range rs {
+		if slices.Contains(conjuncts(r.ConditionSrc), "MapHasWater()") {
+			slog.Debug("rule omitted on dry map", "rule", r.Name)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
 }
@@ -18,7 +18,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     710,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("radar") && !HasRole("radar") && !QueueProducingRole("radar") && (HasRole("barracks") || HasRole("war_factory")) && PowerExcess() >= 0 && Cash() >= 1000`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("radar") && !HasRole("radar") && !QueueProducingRole("radar") && AnyMilitaryProductionExists() && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("radar")),
 			Action:       ActionProduceRadar,
 		})
 	}
@@ -44,7 +44,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     barracksPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && PowerExcess() >= 0 && Cash() >= 300`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("barracks")),
 			Action:       ActionProduceBarracks,
 		})
 	}
@@ -63,7 +63,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     warFactoryPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("war_factory") && !HasRole("war_factory") && PowerExcess() >= 0 && Cash() >= %d`, wfCashThreshold),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("war_factory") && !HasRole("war_factory") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, wfCashThreshold),
 			Action:       ActionProduceWarFactory,
 		})
 	}
@@ -78,7 +78,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     600,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && PowerExcess() >= 0 && Cash() >= 300`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("barracks")),
 			Action:       ActionProduceBarracks,
 		})
 	}
@@ -90,7 +90,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     airfieldPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("airfield") && !HasRole("airfield") && PowerExcess() >= 0 && Cash() >= 500`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("airfield") && !HasRole("airfield") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("airfield")),
 			Action:       ActionProduceAirfield,
 		})
 	}
@@ -101,7 +101,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     570,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("service_depot") && !HasRole("service_depot") && HasRole("war_factory") && PowerExcess() >= 0 && Cash() >= 1200`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("service_depot") && !HasRole("service_depot") && HasRole("war_factory") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, roleCost("service_depot")),
 			Action:       ActionProduceServiceDepot,
 		})
 	}
@@ -113,7 +113,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     navalYardPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `MapHasWater() && !QueueBusy("Building") && CanBuildRole("naval_yard") && !HasRole("naval_yard") && PowerExcess() >= 0 && Cash() >= 500`,
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && CoastInReach() && !QueueBusy("Building") && CanBuildRole("naval_yard") && !HasRole("naval_yard") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("naval_yard")),
 			Action:       ActionProduceNavalYard,
 		})
 	}
@@ -129,7 +129,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     defensePriority,
 			Category:     "defense",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && PowerExcess() >= 0 && (CanBuildRole("pillbox") || CanBuildRole("camo_pillbox") || CanBuildRole("turret") || CanBuildRole("flame_tower") || CanBuildRole("tesla_coil")) && (RoleCount("pillbox") + RoleCount("camo_pillbox") + RoleCount("turret") + RoleCount("flame_tower") + RoleCount("tesla_coil")) < %d && Cash() >= %d`, defenseCap, defenseCash),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && ProjectedPowerExcess() >= 0 && CanBuildAnyGroundDefense() && GroundDefenseCount() < %d && Cash() >= %d`, defenseCap, defenseCash),
 			Action:       ActionProduceDefense,
 		})
 	}
@@ -145,11 +145,23 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     aaPriority,
 			Category:     "defense",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && PowerExcess() >= 0 && CanBuildRole("aa_defense") && RoleCount("aa_defense") < %d && Cash() >= %d`, aaCap, aaCash),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && ProjectedPowerExcess() >= 0 && CanBuildRole("aa_defense") && RoleCount("aa_defense") < %d && Cash() >= %d`, aaCap, aaCash),
 			Action:       ActionProduceAADefense,
 		})
 	}
 
+	// Enemy aircraft over the base get an AA answer whatever the doctrine's
+	// air defense weight: one gun above the standing cap, ahead of ground
+	// defenses, while the raid lasts.
+	c.rules = append(c.rules, &Rule{
+		Name:         "aa-threat-response",
+		Priority:     lerp(610, 660, c.d.AirDefensePriority),
+		Category:     "defense",
+		Exclusive:    true,
+		ConditionSrc: fmt.Sprintf(`AirBaseThreat() && !QueueBusy("Defense") && ProjectedPowerExcess() >= 0 && CanBuildRole("aa_defense") && RoleCount("aa_defense") < %d && Cash() >= %d`, lerp(1, 3, c.d.AirDefensePriority)+1, roleCost("aa_defense")),
+		Action:       ActionProduceAADefense,
+	})
+
 	// --- Gap generator (Allied strategic defense) ---
 
 	if c.d.GroundDefensePriority > DoctrineSignificant && c.d.TechPriority > DoctrineSignificant {
@@ -159,9 +171,20 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     lerp(400, 550, c.d.GroundDefensePriority),
 			Category:     "defense",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && PowerExcess() >= 0 && CanBuildRole("gap_generator") && HasRole("tech_center") && RoleCount("gap_generator") < %d && Cash() >= 800`, gapCap),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && ProjectedPowerExcess() >= 0 && CanBuildRole("gap_generator") && HasRole("tech_center") && RoleCount("gap_generator") < %d && Cash() >= %d`, gapCap, roleCost("gap_generator")),
 			Action:       ActionProduceGapGenerator,
 		})
+
+		// Sell a gap generator facing a stale threat direction; the rule
+		// above rebuilds it facing the current one.
+		c.rules = append(c.rules, &Rule{
+			Name:         "relocate-gap-generator",
+			Priority:     lerp(400, 550, c.d.GroundDefensePriority) - 1,
+			Category:     "defense",
+			Exclusive:    true,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && GapGeneratorMisplaced() && Cash() >= %d`, roleCost("gap_generator")/2),
+			Action:       ActionRelocateGapGenerator,
+		})
 	}
 
 	// --- Tech progression ---
@@ -173,7 +196,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     techCenterPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("tech_center") && !HasRole("tech_center") && HasRole("radar") && PowerExcess() >= 0 && Cash() >= 1500`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("tech_center") && !HasRole("tech_center") && HasRole("radar") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, roleCost("tech_center")),
 			Action:       ActionProduceTechCenter,
 		})
 	}
@@ -182,21 +205,23 @@ func (c *doctrineCompiler) addBuildingRules() {
 
 	if c.d.SuperweaponPriority > DoctrineSignificant {
 		c.rules = append(c.rules, &Rule{
-			Name:         "build-missile-silo",
-			Priority:     650,
-			Category:     "superweapon_build",
-			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Defense") && CanBuildRole("missile_silo") && !HasRole("missile_silo") && HasRole("tech_center") && PowerExcess() >= 0 && Cash() >= 2500`,
-			Action:       ActionProduceMissileSilo,
+			Name:           "build-missile-silo",
+			Priority:       650,
+			Category:       "superweapon_build",
+			Exclusive:      true,
+			ActiveFromTick: c.superweaponFromTick(),
+			ConditionSrc:   fmt.Sprintf(`!QueueBusy("Defense") && CanBuildRole("missile_silo") && !HasRole("missile_silo") && HasRole("tech_center") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, roleCost("missile_silo")),
+			Action:         ActionProduceMissileSilo,
 		})
 
 		c.rules = append(c.rules, &Rule{
-			Name:         "build-iron-curtain",
-			Priority:     640,
-			Category:     "superweapon_build",
-			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Defense") && CanBuildRole("iron_curtain") && !HasRole("iron_curtain") && HasRole("tech_center") && PowerExcess() >= 0 && Cash() >= 2500`,
-			Action:       ActionProduceIronCurtain,
+			Name:           "build-iron-curtain",
+			Priority:       640,
+			Category:       "superweapon_build",
+			Exclusive:      true,
+			ActiveFromTick: c.superweaponFromTick(),
+			ConditionSrc:   fmt.Sprintf(`!QueueBusy("Defense") && CanBuildRole("iron_curtain") && !HasRole("iron_curtain") && HasRole("tech_center") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, roleCost("iron_curtain")),
+			Action:         ActionProduceIronCurtain,
 		})
 	}
 
@@ -209,7 +234,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     500,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("barracks") && RoleCount("barracks") < %d && PowerExcess() >= 0 && Cash() >= 300`, extraBarracksCap),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("barracks") && RoleCount("barracks") < %d && ProjectedPowerExcess() >= 0 && Cash() >= %d`, extraBarracksCap, roleCost("barracks")),
 			Action:       ActionProduceBarracks,
 		})
 	}
@@ -221,7 +246,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     490,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("war_factory") && RoleCount("war_factory") < %d && PowerExcess() >= 0 && Cash() >= 2000`, extraWFCap),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("war_factory") && RoleCount("war_factory") < %d && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, extraWFCap, CashLookahead, roleCost("war_factory")),
 			Action:       ActionProduceWarFactory,
 		})
 	}
@@ -237,7 +262,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     480,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("airfield") && RoleCount("airfield") < %d && CombatAircraftCount() >= %d && PowerExcess() >= 0 && Cash() >= 500`, extraAirCap, airCapForGate-1),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("airfield") && RoleCount("airfield") < %d && CombatAircraftCount() >= %d && ProjectedPowerExcess() >= 0 && Cash() >= %d`, extraAirCap, airCapForGate-1, roleCost("airfield")),
 			Action:       ActionProduceAirfield,
 		})
 	}
@@ -251,7 +276,7 @@ func (c *doctrineCompiler) addBuildingRules() {
 			Priority:     470,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`MapHasWater() && !QueueBusy("Building") && CanBuildRole("naval_yard") && RoleCount("naval_yard") < %d && (RoleCount("submarine") + RoleCount("destroyer")) >= %d && PowerExcess() >= 0 && Cash() >= 500`, extraNavalCap, navalCapForGate-1),
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && CoastInReach() && !QueueBusy("Building") && CanBuildRole("naval_yard") && RoleCount("naval_yard") < %d && (RoleCount("submarine") + RoleCount("destroyer")) >= %d && ProjectedPowerExcess() >= 0 && Cash() >= %d`, extraNavalCap, navalCapForGate-1, roleCost("naval_yard")),
 			Action:       ActionProduceNavalYard,
 		})
 	}
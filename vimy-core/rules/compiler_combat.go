@@ -28,9 +28,21 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     defendPriority,
 			Category:     "combat",
 			Exclusive:    false,
-			ConditionSrc: `SquadExists("ground-defense") && SquadIdleCount("ground-defense") > 0 && BaseUnderAttack()`,
+			ConditionSrc: `SquadExists("ground-defense") && SquadIdleCount("ground-defense") > 0 && (BaseUnderAttack() || IncomingAttack())`,
 			Action:       SquadDefend("ground-defense"),
 		})
+
+		// Between attacks the squad holds the threat-facing perimeter
+		// rather than idling where it formed.
+		c.rules = append(c.rules, &Rule{
+			Name:          "station-defense-squad",
+			Priority:      defendPriority - 1,
+			Category:      "patrol",
+			Exclusive:     false,
+			CooldownTicks: stationCooldownTicks,
+			ConditionSrc:  `SquadExists("ground-defense") && !BaseUnderAttack() && !IncomingAttack() && SquadOffStation("ground-defense")`,
+			Action:        StationSquad("ground-defense"),
+		})
 	} else {
 		// Low defense: no reserved squad, just scramble all idle ground units.
 		defendMinUnits := lerp(3, 1, c.d.GroundDefensePriority)
@@ -39,7 +51,8 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     defendPriority,
 			Category:     "combat",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`BaseUnderAttack() && len(IdleGroundUnits()) >= %d`, defendMinUnits),
+			HoldTicks:    defenseHoldTicks,
+			ConditionSrc: fmt.Sprintf(`(BaseUnderAttack() || IncomingAttack()) && len(IdleGroundUnits()) >= %d`, defendMinUnits),
 			Action:       ActionDefendBase,
 		})
 	}
@@ -56,15 +69,18 @@ func (c *doctrineCompiler) addCombatRules() {
 
 	// --- Ground attack ---
 
-	c.attackPriority = lerp(200, 400, c.d.Aggression)
-	c.activationThreshold = lerpf(0.6, 1.0, 1.0-c.d.Aggression)
+	// Each domain's attack rules follow its own aggression, so a doctrine
+	// can harass by air while holding back on land.
+	groundAggression := c.d.groundAggression()
+	c.attackPriority = lerp(200, 400, groundAggression)
+	c.activationThreshold = activationThreshold(groundAggression)
 
 	c.rules = append(c.rules, &Rule{
 		Name:         "form-ground-attack",
 		Priority:     c.attackPriority + SquadFormBonus,
 		Category:     "squad_form",
 		Exclusive:    false,
-		ConditionSrc: fmt.Sprintf(`(!SquadExists("ground-attack") && len(UnassignedIdleGround()) >= %d) || (SquadNeedsReinforcement("ground-attack") && len(UnassignedIdleGround()) >= 1)`, c.d.GroundAttackGroupSize),
+		ConditionSrc: fmt.Sprintf(`(!SquadExists("ground-attack") && len(UnassignedIdleGround()) >= AttackGroupSize("ground-attack", %d)) || (SquadNeedsReinforcement("ground-attack") && len(UnassignedIdleGround()) >= 1)`, c.d.GroundAttackGroupSize),
 		Action:       FormSquad("ground-attack", "ground", c.d.GroundAttackGroupSize, "attack"),
 	})
 
@@ -73,7 +89,7 @@ func (c *doctrineCompiler) addCombatRules() {
 		Priority:     c.attackPriority,
 		Category:     "combat",
 		Exclusive:    false,
-		ConditionSrc: fmt.Sprintf(`SquadExists("ground-attack") && SquadReadyRatio("ground-attack") >= %.2f && (BestGroundTarget() != nil || NearestEnemy() != nil)`, c.activationThreshold),
+		ConditionSrc: fmt.Sprintf(`SquadExists("ground-attack") && SquadReadyRatio("ground-attack") >= AttackThreshold("ground-attack", %.2f) && (BestGroundTarget() != nil || NearestEnemy() != nil)`, c.activationThreshold),
 		Action:       SquadAttackMove("ground-attack"),
 	})
 
@@ -90,27 +106,160 @@ func (c *doctrineCompiler) addCombatRules() {
 		Action:       SquadAttackMove("ground-attack"),
 	})
 
+	// Enemy nuke about to fire: the whole attack squad goes for the silo,
+	// overriding normal target selection for the rest of the combat category.
+	c.rules = append(c.rules, &Rule{
+		Name:          "squad-strike-enemy-silo",
+		Priority:      c.attackPriority + 1,
+		Category:      "combat",
+		Exclusive:     true,
+		CooldownTicks: siloStrikeCooldown,
+		ConditionSrc:  `SquadExists("ground-attack") && EnemyNukeImminent() && HasKnownEnemySilo()`,
+		Action:        SquadStrikeSilo("ground-attack"),
+	})
+
+	// Enemy superweapon known: the attack squad goes after it, by last known
+	// position if need be, until it is seen destroyed.
+	c.rules = append(c.rules, &Rule{
+		Name:         "squad-deny-superweapon",
+		Priority:     c.attackPriority + 1,
+		Category:     "combat",
+		Exclusive:    true,
+		ConditionSrc: fmt.Sprintf(`SquadExists("ground-attack") && SquadIdleCount("ground-attack") > 0 && SquadReadyRatio("ground-attack") >= AttackThreshold("ground-attack", %.2f) && HasDenialTarget()`, c.activationThreshold),
+		Action:       SquadDenySuperweapon("ground-attack"),
+	})
+
 	// Fallback: attack last-known enemy base when fog of war hides all enemies.
+	// Fast doctrines go after the enemy economy first and only march on the
+	// base once there is no harvester or ore field left to hit.
+	knownBaseCond := fmt.Sprintf(`SquadExists("ground-attack") && SquadReadyRatio("ground-attack") >= AttackThreshold("ground-attack", %.2f) && !EnemiesVisible() && HasEnemyIntel()`, c.activationThreshold)
+	if groundAggression > DoctrineDominant {
+		c.rules = append(c.rules, &Rule{
+			Name:         "squad-harass-economy",
+			Priority:     c.attackPriority - KnownBaseDiscount + 1,
+			Category:     "combat",
+			Exclusive:    false,
+			ConditionSrc: fmt.Sprintf(`SquadExists("ground-attack") && SquadReadyRatio("ground-attack") >= AttackThreshold("ground-attack", %.2f) && !EnemiesVisible() && HasHarassTarget("ground-attack")`, c.activationThreshold),
+			Action:       SquadHarass("ground-attack"),
+		})
+		knownBaseCond += ` && !HasHarassTarget("ground-attack")`
+	}
 	c.rules = append(c.rules, &Rule{
 		Name:         "squad-attack-known-base",
 		Priority:     c.attackPriority - KnownBaseDiscount,
 		Category:     "combat",
 		Exclusive:    false,
-		ConditionSrc: fmt.Sprintf(`SquadExists("ground-attack") && SquadReadyRatio("ground-attack") >= %.2f && !EnemiesVisible() && HasEnemyIntel()`, c.activationThreshold),
-		Action:       SquadAttackKnownBase("ground-attack", c.d.Aggression),
+		ConditionSrc: knownBaseCond,
+		Action:       SquadAttackKnownBase("ground-attack", groundAggression),
 	})
 
+	// --- Raiders and siege ---
+	// Specialised squads form just ahead of the main force so they get first
+	// pick of the units they need; the main force takes the rest.
+
+	if groundAggression > DoctrineSignificant && c.d.VehicleWeight > DoctrineModerate && c.mod.hasAnyRole(raiderRoles) {
+		raiderSize := lerp(2, 4, groundAggression)
+		c.rules = append(c.rules, &Rule{
+			Name:         "form-raider-squad",
+			Priority:     c.attackPriority + SquadFormBonus + 1,
+			Category:     "squad_form",
+			Exclusive:    false,
+			ConditionSrc: fmt.Sprintf(`(!SquadExists("raiders") && len(UnassignedIdleRaiders()) >= %d) || (SquadNeedsReinforcement("raiders") && len(UnassignedIdleRaiders()) >= 1)`, raiderSize),
+			Action:       FormRoleSquad("raiders", "raider", raiderSize),
+		})
+
+		c.rules = append(c.rules, &Rule{
+			Name:         "squad-raid",
+			Priority:     c.attackPriority,
+			Category:     "combat",
+			Exclusive:    false,
+			ConditionSrc: `SquadExists("raiders") && SquadIdleCount("raiders") > 0 && HasHarassTarget("raiders")`,
+			Action:       SquadHarass("raiders"),
+		})
+	}
+
+	if groundAggression > DoctrineModerate && c.d.VehicleWeight > DoctrineModerate && c.mod.hasAnyRole(siegeRoles) {
+		siegeSize := lerp(3, 6, c.d.VehicleWeight)
+		c.rules = append(c.rules, &Rule{
+			Name:         "form-siege-squad",
+			Priority:     c.attackPriority + SquadFormBonus + 1,
+			Category:     "squad_form",
+			Exclusive:    false,
+			ConditionSrc: fmt.Sprintf(`(!SquadExists("siege") && len(UnassignedIdleArtillery()) >= %d && len(UnassignedIdleGround()) >= %d) || (SquadNeedsReinforcement("siege") && len(UnassignedIdleGround()) >= 1)`, siegeArtillery(siegeSize), siegeSize),
+			Action:       FormRoleSquad("siege", "siege", siegeSize),
+		})
+
+		c.rules = append(c.rules, &Rule{
+			Name:         "squad-siege",
+			Priority:     c.attackPriority,
+			Category:     "combat",
+			Exclusive:    false,
+			ConditionSrc: fmt.Sprintf(`SquadExists("siege") && SquadReadyRatio("siege") >= %.2f && HasSiegeTarget("siege")`, c.activationThreshold),
+			Action:       SquadSiege("siege"),
+		})
+	}
+
+	// --- Escorts ---
+	// Guard orders keep an escort on its charge without re-issuing attack-moves
+	// every tick. Escorts pick from the idle pool just ahead of squad formation;
+	// defensive doctrines commit more units to escort duty.
+	maxEscorts := lerp(1, 4, c.d.GroundDefensePriority)
+	harvesterEscortPct := 0.0
+	if c.d.EconomyPriority > DoctrineEnabled {
+		harvesterEscortPct = lerpf(0.05, 0.15, c.d.EconomyPriority)
+	}
+	c.rules = append(c.rules, &Rule{
+		Name:         "assign-escorts",
+		Priority:     c.attackPriority + SquadFormBonus + 1,
+		Category:     "escort",
+		Exclusive:    false,
+		ConditionSrc: fmt.Sprintf(`HasEscorts() || EscortsNeeded(%.2f) > 0`, harvesterEscortPct),
+		Action:       AssignEscorts(harvesterEscortPct, maxEscorts),
+	})
+
+	// --- Perimeter patrol ---
+	// One or two units loop the base perimeter to spot flanking attacks.
+	if c.d.GroundDefensePriority > DoctrineSignificant {
+		maxPatrols := lerp(1, 2, c.d.GroundDefensePriority)
+		c.rules = append(c.rules, &Rule{
+			Name:         "patrol-perimeter",
+			Priority:     c.attackPriority + SquadFormBonus + 1,
+			Category:     "patrol",
+			Exclusive:    false,
+			ConditionSrc: fmt.Sprintf(`HasPatrols() || (PatrolsNeeded(%d) > 0 && len(UnassignedIdleGround()) > 0)`, maxPatrols),
+			Action:       AssignPatrols(maxPatrols),
+		})
+	}
+
 	// --- Air attack ---
 
 	if c.d.AirWeight > DoctrineEnabled {
-		airAttackPriority := lerp(200, 400, c.d.Aggression) - AirDomainOffset
+		airAggression := c.d.airAggression()
+		airAttackPriority := lerp(200, 400, airAggression) - AirDomainOffset
+		airThreshold := activationThreshold(airAggression)
+
+		// Combined arms: the air squad doesn't strike on its own timer while
+		// a ground attack squad exists, but launches as that squad closes on
+		// its target and hits the defenses in its path.
+		airHold := ""
+		if c.d.CombinedArms > DoctrineEnabled {
+			airHold = ` && !SquadExists("ground-attack")`
+			c.rules = append(c.rules, &Rule{
+				Name:         "squad-air-combined-strike",
+				Priority:     airAttackPriority,
+				Category:     "air_combat",
+				Exclusive:    false,
+				ConditionSrc: fmt.Sprintf(`SquadExists("air-attack") && SquadIdleCount("air-attack") > 0 && SquadReadyRatio("air-attack") >= AttackThreshold("air-attack", %.2f) && GroundStrikeWithin("ground-attack", %.1f) && SquadAirTarget("air-attack") != nil`, airThreshold, c.d.combinedLaunchRange()),
+				Action:       SquadCombinedStrike("air-attack", "ground-attack"),
+			})
+		}
 
 		c.rules = append(c.rules, &Rule{
 			Name:         "form-air-attack",
 			Priority:     airAttackPriority + SquadFormBonus,
 			Category:     "squad_form",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`(!SquadExists("air-attack") && len(UnassignedIdleAir()) >= %d) || (SquadNeedsReinforcement("air-attack") && len(UnassignedIdleAir()) >= 1)`, c.d.AirAttackGroupSize),
+			ConditionSrc: fmt.Sprintf(`(!SquadExists("air-attack") && len(UnassignedIdleAir()) >= AttackGroupSize("air-attack", %d)) || (SquadNeedsReinforcement("air-attack") && len(UnassignedIdleAir()) >= 1)`, c.d.AirAttackGroupSize),
 			Action:       FormSquad("air-attack", "air", c.d.AirAttackGroupSize, "attack"),
 		})
 
@@ -119,7 +268,7 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     airAttackPriority,
 			Category:     "air_combat",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`SquadExists("air-attack") && SquadReadyRatio("air-attack") >= %.2f && BestAirTarget() != nil`, c.activationThreshold),
+			ConditionSrc: fmt.Sprintf(`SquadExists("air-attack") && SquadReadyRatio("air-attack") >= AttackThreshold("air-attack", %.2f) && SquadAirTarget("air-attack") != nil%s`, airThreshold, airHold),
 			Action:       SquadAirStrike("air-attack"),
 		})
 
@@ -128,7 +277,7 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     airAttackPriority - ReengageDiscount,
 			Category:     "air_combat",
 			Exclusive:    false,
-			ConditionSrc: `SquadExists("air-attack") && SquadIdleCount("air-attack") > 0 && BestAirTarget() != nil`,
+			ConditionSrc: `SquadExists("air-attack") && SquadIdleCount("air-attack") > 0 && SquadAirTarget("air-attack") != nil` + airHold,
 			Action:       SquadAirStrike("air-attack"),
 		})
 
@@ -137,22 +286,33 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     airAttackPriority - KnownBaseDiscount,
 			Category:     "air_combat",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`SquadExists("air-attack") && SquadReadyRatio("air-attack") >= %.2f && !EnemiesVisible() && HasEnemyIntel()`, c.activationThreshold),
-			Action:       SquadAttackKnownBase("air-attack", c.d.Aggression),
+			ConditionSrc: fmt.Sprintf(`SquadExists("air-attack") && SquadReadyRatio("air-attack") >= AttackThreshold("air-attack", %.2f) && !EnemiesVisible() && HasEnemyIntel()%s`, airThreshold, airHold),
+			Action:       SquadAttackKnownBase("air-attack", airAggression),
+		})
+
+		c.rules = append(c.rules, &Rule{
+			Name:         "squad-air-deny-superweapon",
+			Priority:     airAttackPriority + 1,
+			Category:     "air_combat",
+			Exclusive:    true,
+			ConditionSrc: `SquadExists("air-attack") && SquadIdleCount("air-attack") > 0 && HasDenialTarget()`,
+			Action:       SquadDenySuperweapon("air-attack"),
 		})
 	}
 
 	// --- Naval attack ---
 
 	if c.d.NavalWeight > DoctrineEnabled {
-		navalAttackPriority := lerp(200, 400, c.d.Aggression) - NavalDomainOffset
+		navalAggression := c.d.navalAggression()
+		navalAttackPriority := lerp(200, 400, navalAggression) - NavalDomainOffset
+		navalThreshold := activationThreshold(navalAggression)
 
 		c.rules = append(c.rules, &Rule{
 			Name:         "form-naval-attack",
 			Priority:     navalAttackPriority + SquadFormBonus,
 			Category:     "squad_form",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`MapHasWater() && ((!SquadExists("naval-attack") && len(UnassignedIdleNaval()) >= %d) || (SquadNeedsReinforcement("naval-attack") && len(UnassignedIdleNaval()) >= 1))`, c.d.NavalAttackGroupSize),
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && ((!SquadExists("naval-attack") && len(UnassignedIdleNaval()) >= AttackGroupSize("naval-attack", %d)) || (SquadNeedsReinforcement("naval-attack") && len(UnassignedIdleNaval()) >= 1))`, c.d.NavalAttackGroupSize),
 			Action:       FormSquad("naval-attack", "naval", c.d.NavalAttackGroupSize, "attack"),
 		})
 
@@ -161,7 +321,7 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     navalAttackPriority,
 			Category:     "naval_combat",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`MapHasWater() && SquadExists("naval-attack") && SquadReadyRatio("naval-attack") >= %.2f && NearestEnemy() != nil`, c.activationThreshold),
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && SquadExists("naval-attack") && SquadReadyRatio("naval-attack") >= AttackThreshold("naval-attack", %.2f) && NearestEnemy() != nil`, navalThreshold),
 			Action:       SquadAttackMove("naval-attack"),
 		})
 
@@ -180,11 +340,25 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     navalAttackPriority - KnownBaseDiscount,
 			Category:     "naval_combat",
 			Exclusive:    false,
-			ConditionSrc: fmt.Sprintf(`MapHasWater() && SquadExists("naval-attack") && SquadReadyRatio("naval-attack") >= %.2f && !EnemiesVisible() && HasEnemyIntel()`, c.activationThreshold),
-			Action:       SquadAttackKnownBase("naval-attack", c.d.Aggression),
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && SquadExists("naval-attack") && SquadReadyRatio("naval-attack") >= AttackThreshold("naval-attack", %.2f) && !EnemiesVisible() && HasEnemyIntel()`, navalThreshold),
+			Action:       SquadAttackKnownBase("naval-attack", navalAggression),
 		})
 	}
 
+	// --- Anti-submarine sweep ---
+	// Submerged subs can't be targeted by the naval attack rules, so
+	// destroyers and gunboats hunt remembered contacts whatever the naval
+	// weight. Ranked just above naval squad forming so hunters are claimed
+	// first.
+	c.rules = append(c.rules, &Rule{
+		Name:         "hunt-submarines",
+		Priority:     c.attackPriority - NavalDomainOffset + SquadFormBonus + 1,
+		Category:     "naval_combat",
+		Exclusive:    false,
+		ConditionSrc: `MapHasWater() && HasSubContacts() && (HasASWHunters() || len(UnassignedIdleASW()) > 0)`,
+		Action:       AssignASWHunters(aswMaxHunters),
+	})
+
 	// --- Superweapon fire ---
 
 	if c.d.SuperweaponPriority > DoctrineEnabled {
@@ -202,8 +376,8 @@ func (c *doctrineCompiler) addCombatRules() {
 			Priority:     870,
 			Category:     "superweapon",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`SupportPowerReady("GrantExternalConditionPowerInfoOrder") && len(IdleGroundUnits()) >= %d`, IronCurtainMinUnits),
-			Action:       ActionFireIronCurtain,
+			ConditionSrc: fmt.Sprintf(`SupportPowerReady("GrantExternalConditionPowerInfoOrder") && (len(AllIdleGroundUnits()) >= %d || (BaseUnderAttack() && KeyBuildingUnderFire()))`, IronCurtainMinUnits),
+			Action:       FireIronCurtain(c.d.Aggression),
 		})
 	}
 
@@ -237,6 +411,17 @@ func (c *doctrineCompiler) addCombatRules() {
 			Action:       ActionFireParatroopers,
 		})
 
+		// Landed paratroopers strike the nearest enemy structure rather
+		// than walking home; above the silo strike so they keep their task.
+		c.rules = append(c.rules, &Rule{
+			Name:         "paradrop-assault",
+			Priority:     c.attackPriority + 2,
+			Category:     "combat",
+			Exclusive:    false,
+			ConditionSrc: `SquadExists("paradrop") && SquadIdleCount("paradrop") > 0 && HasParadropTarget()`,
+			Action:       ActionParadropAssault,
+		})
+
 		c.rules = append(c.rules, &Rule{
 			Name:         "fire-parabombs",
 			Priority:     845,
@@ -247,3 +432,9 @@ func (c *doctrineCompiler) addCombatRules() {
 		})
 	}
 }
+
+// activationThreshold is the readiness an attack squad waits for before
+// launching: a full squad at no aggression, 60% at full aggression.
+func activationThreshold(aggression float64) float64 {
+	return lerpf(0.6, 1.0, 1.0-aggression)
+}
@@ -9,12 +9,13 @@ func (c *doctrineCompiler) addCoreRules() {
 	// --- Core rules (always present) ---
 
 	c.rules = append(c.rules, &Rule{
-		Name:         "deploy-mcv",
-		Priority:     1000,
-		Category:     "setup",
-		Exclusive:    true,
-		ConditionSrc: `HasUnit("mcv") && !HasRole("construction_yard")`,
-		Action:       ActionDeployMCV,
+		Name:          "deploy-mcv",
+		Priority:      1000,
+		Category:      "setup",
+		Exclusive:     true,
+		CooldownTicks: deployMCVCooldown,
+		ConditionSrc:  `HasUnit("mcv") && !HasRole("construction_yard")`,
+		Action:        ActionDeployMCV,
 	})
 
 	c.rules = append(c.rules, &Rule{
@@ -26,6 +27,32 @@ func (c *doctrineCompiler) addCoreRules() {
 		Action:       ActionProduceMCV,
 	})
 
+	// Base relocation: when the construction yard is nearly dead with no
+	// defenses left, move the base to the safest quadrant. Economy-minded
+	// doctrines bail out earlier to protect their investment.
+	relocateHP := lerpf(0.2, 0.4, c.d.EconomyPriority)
+
+	c.rules = append(c.rules, &Rule{
+		Name:         "relocate-base",
+		Priority:     975,
+		Category:     "setup",
+		Exclusive:    true,
+		ConditionSrc: fmt.Sprintf(`!IsRelocating() && HasRole("construction_yard") && ConstructionYardHPPct() < %.2f && GroundDefenseCount() == 0 && BaseUnderAttack()`, relocateHP),
+		Action:       ActionRelocateBase,
+	})
+
+	// A spare MCV sent ahead while the old yard still stands needs its own
+	// rule — deploy-mcv only fires once the yard is gone.
+	c.rules = append(c.rules, &Rule{
+		Name:          "advance-relocation",
+		Priority:      970,
+		Category:      "setup",
+		Exclusive:     true,
+		CooldownTicks: deployMCVCooldown,
+		ConditionSrc:  `IsRelocating() && HasUnit("mcv")`,
+		Action:        ActionDeployMCV,
+	})
+
 	c.rules = append(c.rules, &Rule{
 		Name:         "place-ready-building",
 		Priority:     900,
@@ -53,59 +80,101 @@ func (c *doctrineCompiler) addCoreRules() {
 		Action:       ActionCancelStuckAircraft,
 	})
 
+	c.rules = append(c.rules, &Rule{
+		Name:          "clear-factory-exit",
+		Priority:      890,
+		Category:      "unit_maintenance",
+		Exclusive:     false,
+		CooldownTicks: factoryExitCooldown,
+		ConditionSrc:  `len(UnitsStuckAtFactory()) > 0`,
+		Action:        ActionClearFactoryExits,
+	})
+
+	c.rules = append(c.rules, &Rule{
+		Name:         "recover-stuck-units",
+		Priority:     889,
+		Category:     "unit_maintenance",
+		Exclusive:    false,
+		ConditionSrc: `len(StuckUnits()) > 0`,
+		Action:       ActionRecoverStuckUnits,
+	})
+
 	// Engineer capture sequence: produce engineer → produce APC → load → deliver → capture.
 	// The capture-on-foot rule is a fallback for when no APC can be built (no war factory
 	// or APC not in buildable list). Without this gate, engineers walk on foot immediately
 	// and never wait for the APC.
 	// Gated by CapturePriority so pure-defense doctrines don't waste the Infantry queue.
+	// The chain is one rule group, so an operator can switch it off as a whole.
 
 	if c.d.CapturePriority > DoctrineEnabled {
 		engineerCap := lerp(1, 3, c.d.CapturePriority)
 
-		c.rules = append(c.rules, &Rule{
-			Name:         "capture-building",
-			Priority:     850,
-			Category:     "capture",
-			Exclusive:    false,
-			ConditionSrc: `CapturableCount() > 0 && len(IdleEngineers()) > 0 && (!CanBuildRole("apc") || EngineerNearCapturable())`,
-			Action:       ActionCaptureBuilding,
-		})
-
-		c.rules = append(c.rules, &Rule{
-			Name:         "produce-engineer",
-			Priority:     450,
-			Category:     CatProduceInfantry,
-			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`CapturableCount() > 0 && !QueueBusy("Infantry") && CanBuildRole("engineer") && RoleCount("engineer") < CapturableCount() && RoleCount("engineer") < %d && Cash() >= 500`, engineerCap),
-			Action:       ActionProduceEngineer,
-		})
-
-		c.rules = append(c.rules, &Rule{
-			Name:         "produce-apc",
-			Priority:     470,
-			Category:     CatProduceVehicle,
-			Exclusive:    true,
-			ConditionSrc: `CapturableCount() > 0 && RoleCount("engineer") > 0 && HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("apc") && RoleCount("apc") < 1 && Cash() >= 800`,
-			Action:       ActionProduceAPC,
-		})
-
-		c.rules = append(c.rules, &Rule{
-			Name:         "load-engineer-into-apc",
-			Priority:     845,
-			Category:     "capture",
-			Exclusive:    false,
-			ConditionSrc: `len(IdleEngineers()) > 0 && len(IdleEmptyAPCs()) > 0`,
-			Action:       ActionLoadEngineerIntoAPC,
-		})
+		c.addGroup(RuleGroup{Name: GroupEngineerCapture, Rules: []*Rule{
+			{
+				Name:         "capture-building",
+				Priority:     850,
+				Category:     "capture",
+				Exclusive:    false,
+				ConditionSrc: `CapturableCount() > 0 && len(IdleEngineers()) > 0 && (!CanBuildRole("apc") || EngineerNearCapturable())`,
+				Action:       ActionCaptureBuilding,
+			},
+			{
+				Name:         "produce-engineer",
+				Priority:     450,
+				Category:     CatProduceInfantry,
+				Exclusive:    true,
+				ConditionSrc: fmt.Sprintf(`CapturableCount() > 0 && !QueueBusy("Infantry") && CanBuildRole("engineer") && RoleCount("engineer") < CapturableCount() && RoleCount("engineer") < %d && Cash() >= %d`, engineerCap, roleCost("engineer")),
+				Action:       ActionProduceEngineer,
+			},
+			{
+				Name:         "produce-apc",
+				Priority:     470,
+				Category:     CatProduceVehicle,
+				Exclusive:    true,
+				ConditionSrc: fmt.Sprintf(`CapturableCount() > 0 && RoleCount("engineer") > 0 && HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("apc") && RoleCount("apc") < 1 && Cash() >= %d`, roleCost("apc")),
+				Action:       ActionProduceAPC,
+			},
+			{
+				Name:         "load-engineer-into-apc",
+				Priority:     845,
+				Category:     "capture",
+				Exclusive:    false,
+				ConditionSrc: `len(IdleEngineers()) > 0 && len(IdleEmptyAPCs()) > 0`,
+				Action:       ActionLoadEngineerIntoAPC,
+			},
+			{
+				Name:         "deliver-apc-to-target",
+				Priority:     847,
+				Category:     "capture",
+				Exclusive:    false,
+				ConditionSrc: `(CapturableCount() > 0 || EnemyCaptureTarget() != nil) && len(IdleLoadedAPCs()) > 0`,
+				Action:       ActionUnloadAPCNearTarget,
+			},
+		}})
+	}
 
-		c.rules = append(c.rules, &Rule{
-			Name:         "deliver-apc-to-target",
-			Priority:     847,
-			Category:     "capture",
-			Exclusive:    false,
-			ConditionSrc: `CapturableCount() > 0 && len(IdleLoadedAPCs()) > 0`,
-			Action:       ActionUnloadAPCNearTarget,
-		})
+	// Capture-heavy doctrines also steal damaged enemy production buildings
+	// mid-assault, once the attack squad is on top of them to cover the
+	// engineer. Squads hold fire on the target while the engineer is en route.
+	if c.d.CapturePriority > DoctrineDominant {
+		c.addGroup(RuleGroup{Name: GroupEngineerCapture, Rules: []*Rule{
+			{
+				Name:         "capture-enemy-production",
+				Priority:     852,
+				Category:     "capture",
+				Exclusive:    false,
+				ConditionSrc: `EnemyCaptureTarget() != nil && len(IdleEngineers()) > 0 && (!CanBuildRole("apc") || EngineerNearEnemyCaptureTarget())`,
+				Action:       ActionCaptureEnemyProduction,
+			},
+			{
+				Name:         "produce-assault-engineer",
+				Priority:     455,
+				Category:     CatProduceInfantry,
+				Exclusive:    true,
+				ConditionSrc: fmt.Sprintf(`EnemyCaptureTarget() != nil && !QueueBusy("Infantry") && CanBuildRole("engineer") && RoleCount("engineer") < 1 && Cash() >= %d`, roleCost("engineer")),
+				Action:       ActionProduceEngineer,
+			},
+		}})
 	}
 
 	// --- Transport assault ---
@@ -124,7 +193,7 @@ func (c *doctrineCompiler) addCoreRules() {
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
 			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("apc") && RoleCount("apc") < %d && %s`,
-				assaultAPCCap, buildCashCondition(800, c.savings)),
+				assaultAPCCap, buildCashCondition(roleCost("apc"), c.savings)),
 			Action: ActionProduceAPC,
 		})
 
@@ -160,7 +229,7 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     840,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `LostRole("power_plant") && PowerExcess() < 0 && !QueueBusy("Building") && CanBuildRole("power_plant") && Cash() >= 300`,
+		ConditionSrc: fmt.Sprintf(`LostRole("power_plant") && PowerExcess() < 0 && !QueueBusy("Building") && CanBuildRole("power_plant") && Cash() >= %d`, roleCost("power_plant")),
 		Action:       ActionProducePowerPlant,
 	})
 
@@ -169,7 +238,7 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     835,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `LostRole("advanced_power") && PowerExcess() < 0 && !QueueBusy("Building") && CanBuildRole("advanced_power") && Cash() >= 500`,
+		ConditionSrc: fmt.Sprintf(`LostRole("advanced_power") && PowerExcess() < 0 && !QueueBusy("Building") && CanBuildRole("advanced_power") && Cash() >= %d`, roleCost("advanced_power")),
 		Action:       ActionProduceAdvancedPower,
 	})
 
@@ -245,7 +314,7 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     800,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `MapHasWater() && LostRole("naval_yard") && !QueueBusy("Building") && CanBuildRole("naval_yard") && Cash() >= 300`,
+		ConditionSrc: `MapHasWater() && CoastInReach() && LostRole("naval_yard") && !QueueBusy("Building") && CanBuildRole("naval_yard") && Cash() >= 300`,
 		Action:       ActionProduceNavalYard,
 	})
 
@@ -263,7 +332,7 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     790,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `LostRole("missile_silo") && !QueueBusy("Defense") && CanBuildRole("missile_silo") && Cash() >= 2500`,
+		ConditionSrc: fmt.Sprintf(`LostRole("missile_silo") && !QueueBusy("Defense") && CanBuildRole("missile_silo") && Cash() >= %d`, roleCost("missile_silo")),
 		Action:       ActionProduceMissileSilo,
 	})
 
@@ -272,7 +341,7 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     785,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `LostRole("iron_curtain") && !QueueBusy("Defense") && CanBuildRole("iron_curtain") && Cash() >= 2500`,
+		ConditionSrc: fmt.Sprintf(`LostRole("iron_curtain") && !QueueBusy("Defense") && CanBuildRole("iron_curtain") && Cash() >= %d`, roleCost("iron_curtain")),
 		Action:       ActionProduceIronCurtain,
 	})
 
@@ -281,22 +350,37 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     780,
 		Category:     "rebuild",
 		Exclusive:    true,
-		ConditionSrc: `LostRole("kennel") && !QueueBusy("Building") && CanBuildRole("kennel") && Cash() >= 200`,
+		ConditionSrc: fmt.Sprintf(`LostRole("kennel") && !QueueBusy("Building") && CanBuildRole("kennel") && Cash() >= %d`, roleCost("kennel")),
 		Action:       ActionProduceKennel,
 	})
 
+	// Lost defenses go back where they stood — the spot an attack came
+	// through — rather than wherever defenseHint scores best.
+	if w := max(c.d.GroundDefensePriority, c.d.AirDefensePriority); w > DoctrineModerate {
+		c.rules = append(c.rules, &Rule{
+			Name:         "rebuild-lost-defense",
+			Priority:     lerp(450, 650, w),
+			Category:     "rebuild",
+			Exclusive:    true,
+			ConditionSrc: fmt.Sprintf(`LostDefense() && !QueueBusy("Defense") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, lerp(1200, 400, w)),
+			Action:       ActionRebuildDefense,
+		})
+	}
+
 	// Scramble defense: any idle ground unit responds to a base attack,
 	// regardless of squad assignment. The dedicated squad-defend-base and
 	// defend-base rules handle their own pools; this catches idle attack-
 	// squad members, unassigned units, and any other idle stragglers that
-	// would otherwise sit at the base while it's being destroyed.
+	// would otherwise sit at the base while it's being destroyed. It is the
+	// one idle-unit rule that overrides task ownership.
 	c.rules = append(c.rules, &Rule{
 		Name:         "scramble-base-defense",
 		Priority:     350,
 		Category:     "combat",
 		Exclusive:    false,
-		ConditionSrc: `BaseUnderAttack() && len(IdleGroundUnits()) > 0`,
-		Action:       ActionDefendBase,
+		HoldTicks:    defenseHoldTicks,
+		ConditionSrc: `BaseUnderAttack() && len(AllIdleGroundUnits()) > 0`,
+		Action:       ActionScrambleBaseDefense,
 	})
 
 	// Emergency recall: when the base is under attack and no idle ground
@@ -309,10 +393,23 @@ func (c *doctrineCompiler) addCoreRules() {
 		Priority:     349,
 		Category:     "emergency_defense",
 		Exclusive:    false,
-		ConditionSrc: `BaseUnderAttack() && len(IdleGroundUnits()) == 0 && len(NearBaseGroundUnits()) > 0`,
+		HoldTicks:    defenseHoldTicks,
+		ConditionSrc: `BaseUnderAttack() && len(AllIdleGroundUnits()) == 0 && len(NearBaseGroundUnits()) > 0`,
 		Action:       ActionEmergencyDefendBase,
 	})
 
+	// Enemy ships in range of the shore outrank the general naval scramble:
+	// go for the ships rather than the nearest enemy, which may be on land.
+	c.rules = append(c.rules, &Rule{
+		Name:         "defend-coast",
+		Priority:     360,
+		Category:     "naval_combat",
+		Exclusive:    false,
+		HoldTicks:    defenseHoldTicks,
+		ConditionSrc: `MapHasWater() && NavalBaseThreat() && len(IdleNavalUnits()) > 0`,
+		Action:       ActionDefendCoast,
+	})
+
 	c.rules = append(c.rules, &Rule{
 		Name:         "scramble-naval-defense",
 		Priority:     350,
@@ -340,3 +437,45 @@ func (c *doctrineCompiler) addCoreRules() {
 		Action:       ActionSendIdleHarvesters,
 	})
 }
+
+// addRushRules emits the emergency block that runs while a rush alert is
+// raised: expansion on the Building queue is held, a cheap static defense
+// goes up, and the Infantry queue switches to rifle infantry. Every rule is
+// gated on RushDetected() so the block is inert the rest of the game, and
+// active only until rushRulesUntilTick so late games don't evaluate it.
+func (c *doctrineCompiler) addRushRules() {
+	rushDefenseCap := lerp(1, 3, c.d.GroundDefensePriority)
+
+	// Hold sits just below building placement (and above any opening book)
+	// so tech and extra economy wait until the rush is beaten. It steps
+	// aside without a refinery or when power is short — those still matter.
+	c.rules = append(c.rules, &Rule{
+		Name:            "rush-hold-expansion",
+		Priority:        885,
+		Category:        "economy",
+		Exclusive:       true,
+		ActiveUntilTick: rushRulesUntilTick,
+		ConditionSrc:    `RushDetected() && HasRole("refinery") && PowerExcess() >= 0`,
+		Action:          ActionHoldExpansion,
+	})
+
+	c.rules = append(c.rules, &Rule{
+		Name:            "rush-build-defense",
+		Priority:        880,
+		Category:        "defense",
+		Exclusive:       true,
+		ActiveUntilTick: rushRulesUntilTick,
+		ConditionSrc:    fmt.Sprintf(`RushDetected() && !QueueBusy("Defense") && GroundDefenseCount() < %d && Cash() >= 400`, rushDefenseCap),
+		Action:          ActionProduceRushDefense,
+	})
+
+	c.rules = append(c.rules, &Rule{
+		Name:            "rush-produce-defenders",
+		Priority:        600,
+		Category:        CatProduceInfantry,
+		Exclusive:       true,
+		ActiveUntilTick: rushRulesUntilTick,
+		ConditionSrc:    fmt.Sprintf(`RushDetected() && HasRole("barracks") && !QueueBusy("Infantry") && CanBuild("Infantry",%q) && Cash() >= %d`, c.mod.basicInfantry(), costOf(RifleInfantry)),
+		Action:          ActionProduceInfantry,
+	})
+}
@@ -3,7 +3,7 @@ package rules
 import "fmt"
 
 // addEconomyRules emits rules for power plants, refineries, advanced power,
-// ore silos, and extra harvester production.
+// ore silos, and harvester/refinery balancing.
 func (c *doctrineCompiler) addEconomyRules() {
 	// --- Economy ---
 
@@ -13,7 +13,7 @@ func (c *doctrineCompiler) addEconomyRules() {
 		Priority:     800,
 		Category:     "economy",
 		Exclusive:    true,
-		ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("power_plant") && (PowerExcess() < 0 || RoleCount("power_plant") == 0) && Cash() >= %d`, powerCashThreshold),
+		ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("power_plant") && (ProjectedPowerExcess() < 0 || RoleCount("power_plant") == 0) && Cash() >= %d`, powerCashThreshold),
 		Action:       ActionProducePowerPlant,
 	})
 
@@ -41,7 +41,7 @@ func (c *doctrineCompiler) addEconomyRules() {
 			Priority:     secondRefPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("refinery") && RoleCount("refinery") == 1 && (HasRole("barracks") || HasRole("war_factory")) && Cash() >= %d`, secondRefCash),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("refinery") && RoleCount("refinery") == 1 && AnyMilitaryProductionExists() && Cash() >= %d`, secondRefCash),
 			Action:       ActionProduceRefinery,
 		})
 	}
@@ -55,7 +55,7 @@ func (c *doctrineCompiler) addEconomyRules() {
 			Priority:     extraRefPriority,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("refinery") && RoleCount("refinery") >= 2 && RoleCount("refinery") < %d && (HasRole("barracks") || HasRole("war_factory")) && Cash() >= %d`, refineryMax, refineryCashThreshold),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("refinery") && RoleCount("refinery") >= 2 && RoleCount("refinery") < %d && AnyMilitaryProductionExists() && Cash() >= %d`, refineryMax, refineryCashThreshold),
 			Action:       ActionProduceRefinery,
 		})
 	}
@@ -68,7 +68,7 @@ func (c *doctrineCompiler) addEconomyRules() {
 			Priority:     790,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("advanced_power") && PowerExcess() < %d && Cash() >= 500`, LowPowerHeadroom),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("advanced_power") && ProjectedPowerExcess() < %d && Cash() >= %d`, LowPowerHeadroom, roleCost("advanced_power")),
 			Action:       ActionProduceAdvancedPower,
 		})
 	}
@@ -80,19 +80,30 @@ func (c *doctrineCompiler) addEconomyRules() {
 			Priority:     300,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("ore_silo") && ResourcesNearCap() && RoleCount("ore_silo") < %d && Cash() >= 150`, siloCap),
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("ore_silo") && ResourcesNearCap() && RoleCount("ore_silo") < %d && Cash() >= %d`, siloCap, roleCost("ore_silo")),
 			Action:       ActionProduceOreSilo,
 		})
 	}
 
+	// Harvester/refinery balancing: trip tracking tells us whether income is
+	// capped by too few harvesters or by refinery docks and haul distance.
 	if c.d.EconomyPriority > DoctrineDominant {
 		c.rules = append(c.rules, &Rule{
 			Name:         "produce-extra-harvester",
 			Priority:     420,
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("refinery") && HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("harvester") && RoleCount("harvester") < RoleCount("refinery") + 1 && %s`, buildCashCondition(1400, c.savings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("refinery") && HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("harvester") && (RoleCount("harvester") < RoleCount("refinery") + 1 || HarvesterBottleneck() == "harvesters") && RoleCount("harvester") < RoleCount("refinery") * %d && %s`, harvestersPerRefinery+1, buildCashCondition(1400, c.savings)),
 			Action:       ActionProduceHarvester,
 		})
+
+		c.rules = append(c.rules, &Rule{
+			Name:         "build-closer-refinery",
+			Priority:     lerp(530, 660, c.d.EconomyPriority),
+			Category:     "economy",
+			Exclusive:    true,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("refinery") && !QueueProducingRole("refinery") && HarvesterBottleneck() == "refinery" && RoleCount("refinery") < %d && Cash() >= %d`, refineryMax+1, refineryCashThreshold),
+			Action:       ActionProduceRefinery,
+		})
 	}
 }
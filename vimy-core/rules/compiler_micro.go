@@ -3,7 +3,7 @@ package rules
 import "fmt"
 
 // addMicroRules emits rules for unit micro-management: retreat, chase leash,
-// engagement quality, focus fire, harvester flee, and recon scouting.
+// engagement quality, focus fire, kiting, harvester flee, and recon scouting.
 // Must be called after addCombatRules (uses c.attackPriority and
 // c.activationThreshold).
 func (c *doctrineCompiler) addMicroRules() {
@@ -33,6 +33,28 @@ func (c *doctrineCompiler) addMicroRules() {
 		Action:       ClearHealedUnits(retreatThreshold),
 	})
 
+	// Feed queued vehicles to the service depot a couple at a time.
+	c.rules = append(c.rules, &Rule{
+		Name:         "dispatch-repair-queue",
+		Priority:     retreatPriority - 1,
+		Category:     "micro",
+		Exclusive:    false,
+		ConditionSrc: "HasRepairQueue()",
+		Action:       ActionDispatchRepairQueue,
+	})
+
+	// Spread clustered squads when an enemy nuke is about to be ready or
+	// has just been launched.
+	c.rules = append(c.rules, &Rule{
+		Name:          "evade-enemy-nuke",
+		Priority:      retreatPriority + 5,
+		Category:      "micro",
+		Exclusive:     false,
+		CooldownTicks: nukeEvadeCooldown,
+		ConditionSrc:  `(EnemyNukeImminent() || EnemyNukeLaunched()) && len(ClusteredSquads()) > 0`,
+		Action:        ActionEvadeEnemyNuke,
+	})
+
 	// Chase leash — recall overextended squad members that wandered off after kills.
 	// Leash distance scales with aggression — aggressive doctrines let units roam further.
 	leashPct := lerpf(0.25, 0.50, c.d.Aggression)
@@ -66,7 +88,7 @@ func (c *doctrineCompiler) addMicroRules() {
 	}
 
 	// Focus fire on weakest visible enemy — aggressive doctrines only.
-	if c.d.Aggression > DoctrineModerate {
+	if c.d.groundAggression() > DoctrineModerate {
 		c.rules = append(c.rules, &Rule{
 			Name:         "squad-focus-fire",
 			Priority:     c.attackPriority + 1,
@@ -77,6 +99,64 @@ func (c *doctrineCompiler) addMicroRules() {
 		})
 	}
 
+	// Kite with ranged units: step back when enemies close in, then re-attack.
+	// Aggressive doctrines let enemies closer and give up less ground.
+	kiteDangerPct := lerpf(0.6, 0.3, c.d.Aggression)
+	kiteStep := lerp(4, 2, c.d.Aggression)
+	c.rules = append(c.rules, &Rule{
+		Name:         "kite-ranged-units",
+		Priority:     retreatPriority - 15,
+		Category:     "micro",
+		Exclusive:    false,
+		ConditionSrc: fmt.Sprintf(`HasKitingUnits() || len(UnitsToKite(%.2f)) > 0`, kiteDangerPct),
+		Action:       KiteRangedUnits(kiteDangerPct, kiteStep),
+	})
+
+	// Siege vehicles shoot through tree lines and walls blocking the direct
+	// route rather than taking the long way round.
+	c.rules = append(c.rules, &Rule{
+		Name:         "clear-blocking-obstacles",
+		Priority:     retreatPriority - 20,
+		Category:     "micro",
+		Exclusive:    false,
+		ConditionSrc: `len(BlockedSiegeUnits()) > 0`,
+		Action:       ActionClearObstacles,
+	})
+
+	// A turtling doctrine cuts the land route to its base by knocking down
+	// the bridge the enemy would cross.
+	if c.d.groundAggression() < DoctrineSignificant && c.d.GroundDefensePriority > DoctrineDominant {
+		c.rules = append(c.rules, &Rule{
+			Name:          "demolish-bridge",
+			Priority:      retreatPriority - 20,
+			Category:      "micro",
+			Exclusive:     false,
+			CooldownTicks: obstacleFireTicks,
+			ConditionSrc:  `HasBridgeToDemolish() && len(UnassignedIdleGround()) > 0`,
+			Action:        ActionDemolishBridge,
+		})
+	}
+
+	// Medics ride along with infantry squads as support, trailing the squad
+	// centroid instead of charging in with the attack-move.
+	c.rules = append(c.rules, &Rule{
+		Name:         "attach-medics",
+		Priority:     c.attackPriority + SquadFormBonus,
+		Category:     "micro",
+		Exclusive:    false,
+		ConditionSrc: `len(IdleUnattachedMedics()) > 0 && HasMedicSlot()`,
+		Action:       ActionAttachMedics,
+	})
+	c.rules = append(c.rules, &Rule{
+		Name:          "medics-follow-squads",
+		Priority:      c.attackPriority - 1,
+		Category:      "micro",
+		Exclusive:     false,
+		CooldownTicks: medicFollowCooldown,
+		ConditionSrc:  `HasSquadSupport()`,
+		Action:        ActionMedicsFollowSquads,
+	})
+
 	// Flee harvesters from danger — economy-focused doctrines.
 	if c.d.EconomyPriority > DoctrineEnabled {
 		dangerPct := lerpf(0.05, 0.15, c.d.EconomyPriority)
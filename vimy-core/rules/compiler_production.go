@@ -44,7 +44,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     555,
 			Category:     "defense",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Defense") && CanBuildRole("flame_tower") && !HasRole("flame_tower") && HasRole("barracks") && PowerExcess() >= 0 && Cash() >= 600`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Defense") && CanBuildRole("flame_tower") && !HasRole("flame_tower") && HasRole("barracks") && ProjectedPowerExcess() >= 0 && Cash() >= %d`, roleCost("flame_tower")),
 			Action:       ActionProduceFlameTower,
 		})
 	}
@@ -60,7 +60,7 @@ func (c *doctrineCompiler) addProductionRules() {
 				Priority:     560,
 				Category:     "economy",
 				Exclusive:    true,
-				ConditionSrc: `!QueueBusy("Building") && CanBuildRole("tech_center") && !HasRole("tech_center") && HasRole("radar") && PowerExcess() >= 0 && Cash() >= 1500`,
+				ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("tech_center") && !HasRole("tech_center") && HasRole("radar") && ProjectedPowerExcess() >= 0 && ProjectedCash(%d) >= %d`, CashLookahead, roleCost("tech_center")),
 				Action:       ActionProduceTechCenter,
 			})
 		}
@@ -71,7 +71,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     555,
 			Category:     "defense",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Defense") && CanBuildRole("tesla_coil") && !HasRole("tesla_coil") && HasRole("barracks") && PowerExcess() >= 0 && Cash() >= 800`,
+			ConditionSrc: `!QueueBusy("Defense") && CanBuildRole("tesla_coil") && !HasRole("tesla_coil") && HasRole("barracks") && ProjectedPowerExcess() >= 0 && Cash() >= 800`,
 			Action:       ActionProduceTeslaCoil,
 		})
 	}
@@ -90,13 +90,15 @@ func (c *doctrineCompiler) addProductionRules() {
 
 	if c.d.InfantryWeight > DoctrineEnabled {
 		infantryCap := lerp(8, 20, c.d.InfantryWeight)
+		// Infantry-heavy doctrines queue rifles in batches when cash allows.
+		infantryBatch := lerp(1, 5, c.d.InfantryWeight)
 		c.rules = append(c.rules, &Rule{
 			Name:         "produce-infantry",
 			Priority:     infantryBasePri,
 			Category:     CatProduceInfantry,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuild("Infantry","e1") && UnitCount("e1") < %d && %s`, infantryCap, buildCashCondition(100, c.infantrySavings)),
-			Action:       ActionProduceInfantry,
+			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuild("Infantry",%q) && UnitCount(%q) < %d && %s`, c.mod.basicInfantry(), c.mod.basicInfantry(), infantryCap, buildCashCondition(costOf(RifleInfantry), c.infantrySavings)),
+			Action:       ProduceInfantryBatch(infantryBatch, infantryCap, c.infantrySavings),
 		})
 
 		// Bridge infantry: produce extra e1 while doctrine-desired production
@@ -124,8 +126,8 @@ func (c *doctrineCompiler) addProductionRules() {
 				Priority:     infantryBasePri - 5,
 				Category:     CatProduceInfantry,
 				Exclusive:    true,
-				ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuild("Infantry","e1") && %s && UnitCount("e1") >= %d && UnitCount("e1") < %d && %s`, missingCond, infantryCap, bridgeCap, buildCashCondition(100, c.infantrySavings)),
-				Action:       ActionProduceInfantry,
+				ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuild("Infantry",%q) && %s && UnitCount(%q) >= %d && UnitCount(%q) < %d && %s`, c.mod.basicInfantry(), missingCond, c.mod.basicInfantry(), infantryCap, c.mod.basicInfantry(), bridgeCap, buildCashCondition(costOf(RifleInfantry), c.infantrySavings)),
+				Action:       ProduceInfantryBatch(infantryBatch, bridgeCap, c.infantrySavings),
 			})
 		}
 	}
@@ -138,7 +140,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     infantryBasePri - 2,
 			Category:     CatProduceInfantry,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuildRole("grenadier") && RoleCount("grenadier") < %d && %s`, grenadierCap, buildCashCondition(160, c.infantrySavings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuildRole("grenadier") && RoleCount("grenadier") < %d && %s`, grenadierCap, buildCashCondition(roleCost("grenadier"), c.infantrySavings)),
 			Action:       ActionProduceGrenadier,
 		})
 	}
@@ -151,7 +153,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     550,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("kennel") && !HasRole("kennel") && PowerExcess() >= 0 && Cash() >= 300`,
+			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("kennel") && !HasRole("kennel") && ProjectedPowerExcess() >= 0 && Cash() >= 300`,
 			Action:       ActionProduceKennel,
 		})
 
@@ -161,7 +163,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     infantryBasePri - 8,
 			Category:     CatProduceInfantry,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("kennel") && !QueueBusy("Infantry") && CanBuildRole("attack_dog") && RoleCount("attack_dog") < %d && %s`, dogCap, buildCashCondition(200, c.infantrySavings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("kennel") && !QueueBusy("Infantry") && CanBuildRole("attack_dog") && RoleCount("attack_dog") < %d && %s`, dogCap, buildCashCondition(roleCost("attack_dog"), c.infantrySavings)),
 			Action:       ActionProduceAttackDog,
 		})
 	}
@@ -173,7 +175,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     440,
 			Category:     CatProduceInfantry,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && HasRole("radar") && !QueueBusy("Infantry") && CanBuildRole("spy") && RoleCount("spy") < 1 && %s`, buildCashCondition(500, c.infantrySavings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && HasRole("radar") && !QueueBusy("Infantry") && CanBuildRole("spy") && RoleCount("spy") < 1 && %s`, buildCashCondition(roleCost("spy"), c.infantrySavings)),
 			Action:       ActionProduceSpy,
 		})
 	}
@@ -221,7 +223,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     435,
 			Category:     CatProduceShip,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`MapHasWater() && HasRole("naval_yard") && !QueueBusy("Ship") && CanBuildRole("gunboat") && RoleCount("gunboat") < %d && %s`, gunboatCap, buildCashCondition(500, c.savings)),
+			ConditionSrc: fmt.Sprintf(`MapHasWater() && HasRole("naval_yard") && !QueueBusy("Ship") && CanBuildRole("gunboat") && RoleCount("gunboat") < %d && %s`, gunboatCap, buildCashCondition(roleCost("gunboat"), c.savings)),
 			Action:       ActionProduceGunboat,
 		})
 	}
@@ -235,7 +237,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     495,
 			Category:     CatProduceInfantry,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuildRole("rocket_soldier") && RoleCount("rocket_soldier") < %d && %s`, rocketCap, buildCashCondition(300, c.infantrySavings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && CanBuildRole("rocket_soldier") && RoleCount("rocket_soldier") < %d && %s`, rocketCap, buildCashCondition(roleCost("rocket_soldier"), c.infantrySavings)),
 			Action:       ActionProduceRocketSoldier,
 		})
 	}
@@ -258,7 +260,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     465,
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`!HasEnemyIntel() && HasRole("war_factory") && !QueueBusy("Vehicle") && (CanBuildRole("ranger") || CanBuildRole("light_tank")) && !HasRole("ranger") && !HasScout() && %s`, buildCashCondition(500, c.savings)),
+			ConditionSrc: fmt.Sprintf(`!HasEnemyIntel() && HasRole("war_factory") && !QueueBusy("Vehicle") && (CanBuildRole("ranger") || CanBuildRole("light_tank")) && !HasRole("ranger") && !HasScout() && %s`, buildCashCondition(roleCost("ranger"), c.savings)),
 			Action:       ActionProduceScoutVehicle,
 		})
 	}
@@ -282,7 +284,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     470,
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("flak_truck") && RoleCount("flak_truck") < %d && %s`, flakCap, buildCashCondition(600, c.savings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && !QueueBusy("Vehicle") && CanBuildRole("flak_truck") && RoleCount("flak_truck") < %d && %s`, flakCap, buildCashCondition(roleCost("flak_truck"), c.savings)),
 			Action:       ActionProduceFlakTruck,
 		})
 	}
@@ -296,7 +298,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     455,
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && HasRole("tech_center") && !QueueBusy("Vehicle") && CanBuildRole("mad_tank") && RoleCount("mad_tank") < %d && %s`, madCap, buildCashCondition(2000, c.savings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && HasRole("tech_center") && !QueueBusy("Vehicle") && CanBuildRole("mad_tank") && RoleCount("mad_tank") < %d && %s`, madCap, buildCashCondition(roleCost("mad_tank"), c.savings)),
 			Action:       ActionProduceMADTank,
 		})
 	}
@@ -309,7 +311,7 @@ func (c *doctrineCompiler) addProductionRules() {
 			Priority:     450,
 			Category:     CatProduceVehicle,
 			Exclusive:    true,
-			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && HasRole("service_depot") && !QueueBusy("Vehicle") && CanBuildRole("minelayer") && RoleCount("minelayer") < %d && %s`, mineCap, buildCashCondition(800, c.savings)),
+			ConditionSrc: fmt.Sprintf(`HasRole("war_factory") && HasRole("service_depot") && !QueueBusy("Vehicle") && CanBuildRole("minelayer") && RoleCount("minelayer") < %d && %s`, mineCap, buildCashCondition(roleCost("minelayer"), c.savings)),
 			Action:       ActionProduceMinelayer,
 		})
 
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -666,23 +667,23 @@ func TestBuildCashCondition(t *testing.T) {
 		{
 			name:     "tech center only",
 			unitCost: 100,
-			savings:  []buildingSaving{{`HasRole("tech_center")`, 1500}},
-			want:     `Cash() >= 100 && (HasRole("tech_center") || Cash() >= 1600)`,
+			savings:  []buildingSaving{{`HasRole("tech_center")`, 1500, "tech_center"}},
+			want:     `Cash() >= 100 && (HasRole("tech_center") || SavingRelaxed("tech_center") || Cash() >= 1600)`,
 		},
 		{
 			name:     "tech center and superweapon",
 			unitCost: 100,
 			savings: []buildingSaving{
-				{`HasRole("tech_center")`, 1500},
-				{`HasRole("missile_silo") || HasRole("iron_curtain")`, 2500},
+				{`HasRole("tech_center")`, 1500, "tech_center"},
+				{`HasRole("missile_silo") || HasRole("iron_curtain")`, 2500, "superweapon"},
 			},
-			want: `Cash() >= 100 && (HasRole("tech_center") || Cash() >= 1600) && (HasRole("missile_silo") || HasRole("iron_curtain") || Cash() >= 2600)`,
+			want: `Cash() >= 100 && (HasRole("tech_center") || SavingRelaxed("tech_center") || Cash() >= 1600) && (HasRole("missile_silo") || HasRole("iron_curtain") || SavingRelaxed("superweapon") || Cash() >= 2600)`,
 		},
 		{
 			name:     "expensive unit with savings",
 			unitCost: 800,
-			savings:  []buildingSaving{{`HasRole("tech_center")`, 1500}},
-			want:     `Cash() >= 800 && (HasRole("tech_center") || Cash() >= 2300)`,
+			savings:  []buildingSaving{{`HasRole("tech_center")`, 1500, "tech_center"}},
+			want:     `Cash() >= 800 && (HasRole("tech_center") || SavingRelaxed("tech_center") || Cash() >= 2300)`,
 		},
 	}
 	for _, tt := range tests {
@@ -1187,25 +1188,25 @@ func TestCompileDoctrineAirStrikeRules(t *testing.T) {
 		byName[r.Name] = r
 	}
 
-	// squad-air-attack should use BestAirTarget, not NearestEnemy
+	// squad-air-attack should use SquadAirTarget, not NearestEnemy
 	airAttack := byName["squad-air-attack"]
 	if airAttack == nil {
 		t.Fatal("expected squad-air-attack rule")
 	}
-	if !strings.Contains(airAttack.ConditionSrc, "BestAirTarget()") {
-		t.Errorf("squad-air-attack condition should contain BestAirTarget(), got: %s", airAttack.ConditionSrc)
+	if !strings.Contains(airAttack.ConditionSrc, `SquadAirTarget("air-attack")`) {
+		t.Errorf("squad-air-attack condition should contain SquadAirTarget(), got: %s", airAttack.ConditionSrc)
 	}
 	if strings.Contains(airAttack.ConditionSrc, "NearestEnemy()") {
 		t.Errorf("squad-air-attack condition should NOT contain NearestEnemy(), got: %s", airAttack.ConditionSrc)
 	}
 
-	// squad-air-reengage should use BestAirTarget, not NearestEnemy
+	// squad-air-reengage should use SquadAirTarget, not NearestEnemy
 	airReengage := byName["squad-air-reengage"]
 	if airReengage == nil {
 		t.Fatal("expected squad-air-reengage rule")
 	}
-	if !strings.Contains(airReengage.ConditionSrc, "BestAirTarget()") {
-		t.Errorf("squad-air-reengage condition should contain BestAirTarget(), got: %s", airReengage.ConditionSrc)
+	if !strings.Contains(airReengage.ConditionSrc, `SquadAirTarget("air-attack")`) {
+		t.Errorf("squad-air-reengage condition should contain SquadAirTarget(), got: %s", airReengage.ConditionSrc)
 	}
 	if strings.Contains(airReengage.ConditionSrc, "NearestEnemy()") {
 		t.Errorf("squad-air-reengage condition should NOT contain NearestEnemy(), got: %s", airReengage.ConditionSrc)
@@ -1745,3 +1746,108 @@ func TestCompileDoctrineBridgeInfantry(t *testing.T) {
 		t.Errorf("heavy tank bridge infantry should check for missing war_factory, got: %s", tankBridge.ConditionSrc)
 	}
 }
+
+func TestDiffDoctrines(t *testing.T) {
+	oldD := DefaultDoctrine()
+	newD := DefaultDoctrine()
+	newD.Aggression = 0.9
+	newD.AirWeight = 0.6
+
+	d := Diff(CompileDoctrine(oldD), CompileDoctrine(newD))
+	if d.Empty() {
+		t.Fatal("changing aggression and air weight should change the rules")
+	}
+	if !slices.Contains(d.Added, "form-air-attack") {
+		t.Errorf("expected form-air-attack added, got %v", d.Added)
+	}
+	var attack *RuleChange
+	for i := range d.Changed {
+		if d.Changed[i].Name == "squad-attack" {
+			attack = &d.Changed[i]
+		}
+	}
+	if attack == nil || attack.NewPriority <= attack.OldPriority {
+		t.Errorf("expected squad-attack re-prioritized upward, got %+v", attack)
+	}
+	if !strings.Contains(d.String(), "+form-air-attack") {
+		t.Errorf("String() missing added rule:\n%s", d)
+	}
+
+	if same := Diff(CompileDoctrine(oldD), CompileDoctrine(oldD)); !same.Empty() {
+		t.Errorf("identical doctrines diffed: %s", same)
+	}
+}
+
+func TestCompileDoctrineHasNoAnalysisFindings(t *testing.T) {
+	extreme := Doctrine{
+		EconomyPriority: 1, Aggression: 1, GroundDefensePriority: 1, AirDefensePriority: 1, TechPriority: 1,
+		InfantryWeight: 1, VehicleWeight: 1, AirWeight: 1, NavalWeight: 1,
+		GroundAttackGroupSize: 12, AirAttackGroupSize: 6, NavalAttackGroupSize: 6,
+		ScoutPriority: 1, SpecializedInfantryWeight: 1, SuperweaponPriority: 1, CapturePriority: 1,
+		TransportAssault: 1, Opening: "rush",
+	}
+	turtle := DefaultDoctrine()
+	turtle.Aggression, turtle.GroundDefensePriority, turtle.EconomyPriority = 0, 1, 0.9
+
+	for name, d := range map[string]Doctrine{"default": DefaultDoctrine(), "extreme": extreme, "turtle": turtle, "zero": {}} {
+		for _, f := range Analyze(CompileDoctrine(d)) {
+			t.Errorf("%s doctrine: %s", name, f)
+		}
+	}
+	for _, f := range Analyze(DefaultRules()) {
+		t.Errorf("default rules: %s", f)
+	}
+}
+
+func TestCompileDoctrineTickWindows(t *testing.T) {
+	d := DefaultDoctrine()
+	d.SuperweaponPriority = 1.0
+	byName := make(map[string]*Rule)
+	for _, r := range CompileDoctrine(d) {
+		byName[r.Name] = r
+	}
+	for _, name := range []string{"rush-hold-expansion", "rush-build-defense", "rush-produce-defenders"} {
+		if r := byName[name]; r == nil || r.ActiveUntilTick != rushRulesUntilTick {
+			t.Errorf("%s should stop at tick %d", name, rushRulesUntilTick)
+		}
+	}
+	silo := byName["build-missile-silo"]
+	if silo == nil || silo.ActiveFromTick != midGameTick/2 {
+		t.Fatalf("build-missile-silo should start at tick %d for a superweapon doctrine", midGameTick/2)
+	}
+	// The cash saved for the silo is released until the silo rule is live.
+	want := fmt.Sprintf("State.Tick < %d", silo.ActiveFromTick)
+	saving := false
+	for _, r := range byName {
+		saving = saving || strings.Contains(r.ConditionSrc, want)
+	}
+	if !saving {
+		t.Errorf("no production rule releases superweapon savings before tick %d", silo.ActiveFromTick)
+	}
+}
+
+func TestCompileDoctrineForMap_DryMapOmitsNaval(t *testing.T) {
+	d := DefaultDoctrine()
+	d.NavalWeight = 0.9
+	dry := &model.TerrainGrid{Cols: 4, Rows: 4, CellW: 8, CellH: 8, Grid: make([]model.TerrainType, 16)}
+	wet := &model.TerrainGrid{Cols: 4, Rows: 4, CellW: 8, CellH: 8, Grid: make([]model.TerrainType, 16)}
+	wet.Grid[5] = model.Water
+
+	for _, r := range CompileDoctrineForMap(d, RA, dry) {
+		if strings.Contains(r.ConditionSrc, "MapHasWater()") {
+			t.Errorf("dry map: rule %s still gated on MapHasWater()", r.Name)
+		}
+		if strings.Contains(r.ConditionSrc, "naval_yard") {
+			t.Errorf("dry map: rule %s still refers to naval yards: %s", r.Name, r.ConditionSrc)
+		}
+	}
+
+	for _, terrain := range []*model.TerrainGrid{wet, nil} {
+		rs := CompileDoctrineForMap(d, RA, terrain)
+		for _, name := range []string{"build-naval-yard", "form-naval-attack", "rebuild-naval-yard", "scramble-naval-defense"} {
+			if findRule(rs, name) == nil {
+				t.Errorf("water %v: missing %s", terrain != nil, name)
+			}
+		}
+	}
+}
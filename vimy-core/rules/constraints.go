@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// doctrineParam reads and writes one numeric doctrine field by the name
+// DoctrineParam and doctrine constraints use for it.
+type doctrineParam struct {
+	get func(Doctrine) float64
+	set func(*Doctrine, float64) // group sizes round to the nearest integer
+	// own reports whether the doctrine sets the value itself rather than
+	// inheriting it from another parameter; nil means always.
+	own func(Doctrine) bool
+}
+
+func floatParam(f func(*Doctrine) *float64) doctrineParam {
+	return doctrineParam{
+		get: func(d Doctrine) float64 { return *f(&d) },
+		set: func(d *Doctrine, v float64) { *f(d) = v },
+	}
+}
+
+func intParam(f func(*Doctrine) *int) doctrineParam {
+	return doctrineParam{
+		get: func(d Doctrine) float64 { return float64(*f(&d)) },
+		set: func(d *Doctrine, v float64) { *f(d) = int(math.Round(v)) },
+	}
+}
+
+// aggressionParam reads a per-domain aggression override, falling back to
+// Aggression when unset; setting it always sets the override.
+func aggressionParam(f func(*Doctrine) **float64) doctrineParam {
+	return doctrineParam{
+		get: func(d Doctrine) float64 { return d.domainAggression(*f(&d)) },
+		set: func(d *Doctrine, v float64) { *f(d) = &v },
+		own: func(d Doctrine) bool { return *f(&d) != nil },
+	}
+}
+
+// doctrineParams maps each numeric doctrine field to its JSON name.
+var doctrineParams = map[string]doctrineParam{
+	"economy_priority":            floatParam(func(d *Doctrine) *float64 { return &d.EconomyPriority }),
+	"aggression":                  floatParam(func(d *Doctrine) *float64 { return &d.Aggression }),
+	"ground_defense_priority":     floatParam(func(d *Doctrine) *float64 { return &d.GroundDefensePriority }),
+	"air_defense_priority":        floatParam(func(d *Doctrine) *float64 { return &d.AirDefensePriority }),
+	"tech_priority":               floatParam(func(d *Doctrine) *float64 { return &d.TechPriority }),
+	"infantry_weight":             floatParam(func(d *Doctrine) *float64 { return &d.InfantryWeight }),
+	"vehicle_weight":              floatParam(func(d *Doctrine) *float64 { return &d.VehicleWeight }),
+	"air_weight":                  floatParam(func(d *Doctrine) *float64 { return &d.AirWeight }),
+	"naval_weight":                floatParam(func(d *Doctrine) *float64 { return &d.NavalWeight }),
+	"ground_attack_group_size":    intParam(func(d *Doctrine) *int { return &d.GroundAttackGroupSize }),
+	"air_attack_group_size":       intParam(func(d *Doctrine) *int { return &d.AirAttackGroupSize }),
+	"naval_attack_group_size":     intParam(func(d *Doctrine) *int { return &d.NavalAttackGroupSize }),
+	"scout_priority":              floatParam(func(d *Doctrine) *float64 { return &d.ScoutPriority }),
+	"specialized_infantry_weight": floatParam(func(d *Doctrine) *float64 { return &d.SpecializedInfantryWeight }),
+	"superweapon_priority":        floatParam(func(d *Doctrine) *float64 { return &d.SuperweaponPriority }),
+	"capture_priority":            floatParam(func(d *Doctrine) *float64 { return &d.CapturePriority }),
+	"transport_assault":           floatParam(func(d *Doctrine) *float64 { return &d.TransportAssault }),
+	"forwardness":                 floatParam(func(d *Doctrine) *float64 { return &d.Forwardness }),
+	"defense_margin":              floatParam(func(d *Doctrine) *float64 { return &d.DefenseMargin }),
+	"combined_arms":               floatParam(func(d *Doctrine) *float64 { return &d.CombinedArms }),
+	"ground_aggression":           aggressionParam(func(d *Doctrine) **float64 { return &d.GroundAggression }),
+	"air_aggression":              aggressionParam(func(d *Doctrine) **float64 { return &d.AirAggression }),
+	"naval_aggression":            aggressionParam(func(d *Doctrine) **float64 { return &d.NavalAggression }),
+}
+
+// SetParam sets one numeric doctrine parameter by its DoctrineParam name
+// (e.g. "aggression") and re-validates the doctrine, so out-of-range values
+// are clamped as an LLM's would be.
+func (d *Doctrine) SetParam(name string, v float64) error {
+	p, ok := doctrineParams[name]
+	if !ok {
+		known := slices.Sorted(maps.Keys(doctrineParams))
+		return fmt.Errorf("unknown doctrine parameter %q (known: %s)", name, strings.Join(known, ", "))
+	}
+	p.set(d, v)
+	d.Validate()
+	return nil
+}
+
+// ParamChange is a doctrine parameter that differs between two doctrines.
+type ParamChange struct {
+	Param string  `json:"param"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+}
+
+// DiffParams lists the numeric parameters, by DoctrineParam name, that
+// differ from prev to next. A parameter both inherit (a domain aggression
+// following Aggression) is left to the one it follows.
+func DiffParams(prev, next Doctrine) []ParamChange {
+	var out []ParamChange
+	for _, name := range slices.Sorted(maps.Keys(doctrineParams)) {
+		p := doctrineParams[name]
+		if p.own != nil && !p.own(prev) && !p.own(next) {
+			continue
+		}
+		if from, to := p.get(prev), p.get(next); from != to {
+			out = append(out, ParamChange{Param: name, From: from, To: to})
+		}
+	}
+	return out
+}
+
+// DoctrineConstraint bounds one doctrine parameter the operator doesn't
+// leave to the LLM. Pin fixes the value; Min and Max clamp it.
+type DoctrineConstraint struct {
+	Pin *float64 `json:"pin,omitempty"`
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// DoctrineConstraints is keyed by doctrine parameter name, as in
+// DoctrineParam (e.g. "naval_weight", "superweapon_priority").
+type DoctrineConstraints map[string]DoctrineConstraint
+
+// LoadDoctrineConstraints reads a JSON constraints file, e.g.
+//
+//	{"naval_weight": {"pin": 0},
+//	 "superweapon_priority": {"max": 0.3},
+//	 "ground_attack_group_size": {"min": 6}}
+func LoadDoctrineConstraints(path string) (DoctrineConstraints, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read doctrine constraints: %w", err)
+	}
+	var c DoctrineConstraints
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unmarshal doctrine constraints: %w", err)
+	}
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Check rejects unknown parameter names and empty ranges.
+func (c DoctrineConstraints) Check() error {
+	for name, dc := range c {
+		if _, ok := doctrineParams[name]; !ok {
+			known := slices.Sorted(maps.Keys(doctrineParams))
+			return fmt.Errorf("doctrine constraint on unknown parameter %q (known: %s)", name, strings.Join(known, ", "))
+		}
+		if dc.Min != nil && dc.Max != nil && *dc.Min > *dc.Max {
+			return fmt.Errorf("doctrine constraint %q: min %v above max %v", name, *dc.Min, *dc.Max)
+		}
+	}
+	return nil
+}
+
+// Apply clamps d to the constraints and then to Validate's ranges, so a
+// constraint can't push a parameter somewhere the compiler doesn't expect.
+// It returns the names of the parameters it changed, sorted.
+func (c DoctrineConstraints) Apply(d *Doctrine) []string {
+	var changed []string
+	for name, dc := range c {
+		p, ok := doctrineParams[name]
+		if !ok {
+			continue // Check reports these
+		}
+		v := p.get(*d)
+		nv := v
+		if dc.Min != nil {
+			nv = max(nv, *dc.Min)
+		}
+		if dc.Max != nil {
+			nv = min(nv, *dc.Max)
+		}
+		if dc.Pin != nil {
+			nv = *dc.Pin
+		}
+		if nv != v {
+			p.set(d, nv)
+			changed = append(changed, name)
+		}
+	}
+	d.Validate()
+	slices.Sort(changed)
+	return changed
+}
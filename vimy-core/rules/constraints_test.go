@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestDoctrineConstraintsApply(t *testing.T) {
+	c := DoctrineConstraints{
+		"naval_weight":             {Pin: floatPtr(0)},
+		"superweapon_priority":     {Max: floatPtr(0.3)},
+		"ground_attack_group_size": {Min: floatPtr(7.6)},
+		"aggression":               {Min: floatPtr(0.2), Max: floatPtr(0.8)},
+	}
+	d := DefaultDoctrine()
+	d.NavalWeight = 0.9
+	d.SuperweaponPriority = 0.7
+	d.GroundAttackGroupSize = 4
+	d.Aggression = 0.5
+
+	changed := c.Apply(&d)
+
+	if d.NavalWeight != 0 || d.SuperweaponPriority != 0.3 || d.GroundAttackGroupSize != 8 || d.Aggression != 0.5 {
+		t.Errorf("doctrine = naval %v, superweapon %v, group %d, aggression %v", d.NavalWeight, d.SuperweaponPriority, d.GroundAttackGroupSize, d.Aggression)
+	}
+	want := []string{"ground_attack_group_size", "naval_weight", "superweapon_priority"}
+	if !slices.Equal(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
+
+func TestDoctrineConstraintsStayInValidRange(t *testing.T) {
+	d := DefaultDoctrine()
+	DoctrineConstraints{"air_attack_group_size": {Pin: floatPtr(40)}, "tech_priority": {Pin: floatPtr(2)}}.Apply(&d)
+	if d.AirAttackGroupSize != 8 || d.TechPriority != 1 {
+		t.Errorf("air group %d, tech %v; want Validate's limits 8 and 1", d.AirAttackGroupSize, d.TechPriority)
+	}
+}
+
+func TestDoctrineConstraintsApplyReachesDoctrineParam(t *testing.T) {
+	d := DefaultDoctrine()
+	DoctrineConstraints{"capture_priority": {Pin: floatPtr(0.25)}}.Apply(&d)
+	if got := (RuleEnv{Doctrine: d}).DoctrineParam("capture_priority"); got != 0.25 {
+		t.Errorf("DoctrineParam = %v, want 0.25", got)
+	}
+}
+
+func TestLoadDoctrineConstraints(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	c, err := LoadDoctrineConstraints(write("ok.json", `{"naval_weight": {"pin": 0}, "superweapon_priority": {"max": 0.3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 2 || *c["naval_weight"].Pin != 0 || *c["superweapon_priority"].Max != 0.3 {
+		t.Errorf("loaded %+v", c)
+	}
+
+	if _, err := LoadDoctrineConstraints(write("unknown.json", `{"navy_weight": {"pin": 0}}`)); err == nil {
+		t.Error("unknown parameter accepted")
+	}
+	if _, err := LoadDoctrineConstraints(write("empty.json", `{"aggression": {"min": 0.8, "max": 0.2}}`)); err == nil {
+		t.Error("empty range accepted")
+	}
+}
+
+func TestDoctrineConstraintsDomainAggression(t *testing.T) {
+	d := DefaultDoctrine()
+	c := DoctrineConstraints{"naval_aggression": {Max: floatPtr(0.2)}, "air_aggression": {Min: floatPtr(0.1)}}
+	if changed := c.Apply(&d); !slices.Equal(changed, []string{"naval_aggression"}) {
+		t.Errorf("changed = %v, want only naval_aggression", changed)
+	}
+	if d.NavalAggression == nil || *d.NavalAggression != 0.2 {
+		t.Errorf("naval aggression = %v, want a 0.2 override", d.NavalAggression)
+	}
+	if d.AirAggression != nil {
+		t.Error("a constraint the inherited aggression already met set an override")
+	}
+}
+
+func TestDoctrineSetParam(t *testing.T) {
+	d := DefaultDoctrine()
+	if err := d.SetParam("air_weight", 1.7); err != nil {
+		t.Fatal(err)
+	}
+	if d.AirWeight != 1 {
+		t.Errorf("air weight = %v, want 1 (validated)", d.AirWeight)
+	}
+	if err := d.SetParam("airweight", 0.5); err == nil {
+		t.Error("unknown parameter accepted")
+	}
+}
+
+func TestDiffParams(t *testing.T) {
+	prev := DefaultDoctrine()
+	next := prev
+	next.AirWeight = 0.9
+	next.GroundAttackGroupSize = prev.GroundAttackGroupSize + 2
+	next.Aggression = 0.8
+	next.NavalAggression = floatPtr(0.1)
+	next.Rationale = "not a parameter"
+	got := DiffParams(prev, next)
+	// Ground and air aggression follow Aggression, so only it is listed.
+	want := []ParamChange{
+		{Param: "aggression", From: prev.Aggression, To: 0.8},
+		{Param: "air_weight", From: prev.AirWeight, To: 0.9},
+		{Param: "ground_attack_group_size", From: float64(prev.GroundAttackGroupSize), To: float64(next.GroundAttackGroupSize)},
+		{Param: "naval_aggression", From: prev.Aggression, To: 0.1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DiffParams = %+v, want %+v", got, want)
+	}
+	if d := DiffParams(prev, prev); len(d) != 0 {
+		t.Errorf("DiffParams of a doctrine with itself = %+v, want none", d)
+	}
+}
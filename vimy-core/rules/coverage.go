@@ -0,0 +1,261 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Scout coverage tracking. The map is divided into zones (the terrain grid
+// when the sidecar sends one, otherwise a coverageFallbackGrid square) and
+// each zone remembers the last tick one of our actors could see it.
+const (
+	coverageFallbackGrid = 16   // zones per side without a terrain grid
+	coverageSightRadius  = 6    // cells — roughly ground unit/building vision
+	coverageFreshTicks   = 3000 // 2 minutes; older sightings count as stale
+	scoutArriveRadius    = 3.0  // within this of the zone centre counts as arrived
+	scoutSettleTicks     = 50   // grace before an idle scout counts as blocked
+	scoutGiveUpTicks     = 1500 // en route this long means the zone is unreachable
+	spawnBaseRadius      = 16   // cells — a known base this close to a spawn started there
+)
+
+// coverageMap holds per-zone last-seen ticks. Zones scouts can't walk to
+// (water, cliffs) are tracked but excluded from coverage and targeting.
+type coverageMap struct {
+	Cols, Rows   int
+	CellW, CellH int
+	Seen         []int  // tick each zone was last observed; 0 = never
+	Passable     []bool // ground-reachable zones
+}
+
+// scoutTask is a scout's current destination. A scout with no task is free
+// to be sent to the stalest zone; the task ends when the scout arrives, dies,
+// or stalls (the zone is then marked as visited so we stop retrying it).
+type scoutTask struct {
+	Col, Row int
+	X, Y     int
+	Since    int
+}
+
+func getCoverage(memory map[string]any) *coverageMap {
+	if v, ok := coverageMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+func getScoutTasks(memory map[string]any) map[int]*scoutTask {
+	if v, ok := scoutTasksMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*scoutTask)
+}
+
+func newCoverageMap(mapW, mapH int, terrain *model.TerrainGrid) *coverageMap {
+	c := &coverageMap{Cols: coverageFallbackGrid, Rows: coverageFallbackGrid}
+	if terrain != nil && terrain.Cols > 0 && terrain.Rows > 0 && terrain.CellW > 0 && terrain.CellH > 0 {
+		c.Cols, c.Rows, c.CellW, c.CellH = terrain.Cols, terrain.Rows, terrain.CellW, terrain.CellH
+	} else {
+		c.CellW = max(1, (mapW+c.Cols-1)/c.Cols)
+		c.CellH = max(1, (mapH+c.Rows-1)/c.Rows)
+	}
+	c.Seen = make([]int, c.Cols*c.Rows)
+	c.Passable = make([]bool, c.Cols*c.Rows)
+	for row := range c.Rows {
+		for col := range c.Cols {
+			t := model.Land
+			if terrain != nil {
+				t = terrain.At(col, row)
+			}
+			c.Passable[row*c.Cols+col] = t == model.Land || t == model.Bridge
+		}
+	}
+	return c
+}
+
+// zoneCenter returns the map position of a zone's centre, clamped to the map.
+func (c *coverageMap) zoneCenter(col, row, mapW, mapH int) (int, int) {
+	x := min(col*c.CellW+c.CellW/2, mapW-1)
+	y := min(row*c.CellH+c.CellH/2, mapH-1)
+	return x, y
+}
+
+// markSeen stamps every zone within coverageSightRadius of (x, y).
+func (c *coverageMap) markSeen(x, y, tick int) {
+	minCol := max(0, (x-coverageSightRadius)/c.CellW)
+	maxCol := min(c.Cols-1, (x+coverageSightRadius)/c.CellW)
+	minRow := max(0, (y-coverageSightRadius)/c.CellH)
+	maxRow := min(c.Rows-1, (y+coverageSightRadius)/c.CellH)
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			c.Seen[row*c.Cols+col] = tick
+		}
+	}
+}
+
+// updateCoverage marks zones our units and buildings can see and advances
+// each scout's task: arrived and dead scouts are released, and scouts that
+// stall short of their zone mark it visited so the next pick goes elsewhere.
+func updateCoverage(env RuleEnv) {
+	if env.State.MapWidth == 0 || env.State.MapHeight == 0 {
+		return
+	}
+	cov := getCoverage(env.Memory)
+	if cov == nil {
+		cov = newCoverageMap(env.State.MapWidth, env.State.MapHeight, env.Terrain)
+		coverageMemory.set(env.Memory, cov)
+	}
+
+	tick := env.State.Tick
+	for _, u := range env.State.Units {
+		cov.markSeen(u.X, u.Y, tick)
+	}
+	for _, b := range env.State.Buildings {
+		cov.markSeen(b.X, b.Y, tick)
+	}
+
+	tasks := getScoutTasks(env.Memory)
+	pos := env.units().ByID()
+	for id, t := range tasks {
+		u, alive := pos[id]
+		switch {
+		case !alive:
+			delete(tasks, id)
+		case math.Hypot(float64(u.X-t.X), float64(u.Y-t.Y)) <= scoutArriveRadius:
+			delete(tasks, id)
+		case (u.Idle && tick-t.Since > scoutSettleTicks) || tick-t.Since > scoutGiveUpTicks:
+			cov.Seen[t.Row*cov.Cols+t.Col] = tick
+			delete(tasks, id)
+			env.unitDebug(id, "scout could not reach zone", "id", id, "x", t.X, "y", t.Y)
+		}
+	}
+	scoutTasksMemory.set(env.Memory, tasks)
+}
+
+// nextScoutZone picks the stalest passable zone not already targeted by
+// another scout, breaking ties by distance from (x, y). Unscouted enemy
+// spawn points come first: that is where the enemy base almost always is.
+// ok is false before coverage has been built.
+func (e RuleEnv) nextScoutZone(x, y int) (t scoutTask, ok bool) {
+	cov := getCoverage(e.Memory)
+	if cov == nil {
+		return scoutTask{}, false
+	}
+	taken := make(map[[2]int]bool)
+	for _, st := range getScoutTasks(e.Memory) {
+		taken[[2]int{st.Col, st.Row}] = true
+	}
+	if t, ok := e.nextSpawnZone(cov, taken, x, y); ok {
+		return t, true
+	}
+
+	bestSeen := math.MaxInt
+	bestDist := math.MaxFloat64
+	for row := range cov.Rows {
+		for col := range cov.Cols {
+			i := row*cov.Cols + col
+			if !cov.Passable[i] || taken[[2]int{col, row}] || cov.Seen[i] > bestSeen {
+				continue
+			}
+			zx, zy := cov.zoneCenter(col, row, e.State.MapWidth, e.State.MapHeight)
+			d := math.Hypot(float64(zx-x), float64(zy-y))
+			if cov.Seen[i] == bestSeen && d >= bestDist {
+				continue
+			}
+			bestSeen, bestDist = cov.Seen[i], d
+			t = scoutTask{Col: col, Row: row, X: zx, Y: zy, Since: e.State.Tick}
+			ok = true
+		}
+	}
+	return t, ok
+}
+
+// nextSpawnZone picks the nearest enemy spawn point to (x, y) whose zone
+// has never been seen and isn't claimed by another scout. Spawns beside a
+// base we already know of need no scouting.
+func (e RuleEnv) nextSpawnZone(cov *coverageMap, taken map[[2]int]bool, x, y int) (t scoutTask, ok bool) {
+	bestDist := math.MaxFloat64
+	for _, sp := range e.enemySpawns() {
+		col := min(max(sp.X/cov.CellW, 0), cov.Cols-1)
+		row := min(max(sp.Y/cov.CellH, 0), cov.Rows-1)
+		if cov.Seen[row*cov.Cols+col] > 0 || taken[[2]int{col, row}] {
+			continue
+		}
+		if d := math.Hypot(float64(sp.X-x), float64(sp.Y-y)); d < bestDist {
+			bestDist = d
+			t = scoutTask{Col: col, Row: row, X: sp.X, Y: sp.Y, Since: e.State.Tick}
+			ok = true
+		}
+	}
+	return t, ok
+}
+
+// enemySpawns returns the spawn points other than ours that aren't already
+// covered by known enemy base intel.
+func (e RuleEnv) enemySpawns() []model.SpawnPoint {
+	bases := GetEnemyBases(e.Memory)
+	var out []model.SpawnPoint
+	for _, sp := range e.State.SpawnPoints {
+		if sp.Own {
+			continue
+		}
+		known := false
+		for _, b := range bases {
+			if b.FromBuildings && math.Hypot(float64(sp.X-b.X), float64(sp.Y-b.Y)) <= spawnBaseRadius {
+				known = true
+				break
+			}
+		}
+		if !known {
+			out = append(out, sp)
+		}
+	}
+	return out
+}
+
+// assignScoutTask records that unit id is heading for t.
+func assignScoutTask(memory map[string]any, id int, t scoutTask) {
+	tasks := getScoutTasks(memory)
+	tasks[id] = &t
+	scoutTasksMemory.set(memory, tasks)
+}
+
+// MapCoverage reports the share of passable zones seen within the last
+// coverageFreshTicks (0-100) and how many ticks ago the stalest passable
+// zone was seen. ok is false before coverage has been built. Public so the
+// strategist can report scouting state alongside the rules.
+func MapCoverage(memory map[string]any, tick int) (pct float64, staleAge int, ok bool) {
+	cov := getCoverage(memory)
+	if cov == nil {
+		return 0, 0, false
+	}
+	passable, fresh := 0, 0
+	oldest := tick
+	for i, seen := range cov.Seen {
+		if !cov.Passable[i] {
+			continue
+		}
+		passable++
+		if seen > 0 && tick-seen <= coverageFreshTicks {
+			fresh++
+		}
+		oldest = min(oldest, seen)
+	}
+	if passable == 0 {
+		return 0, 0, false
+	}
+	return 100 * float64(fresh) / float64(passable), tick - oldest, true
+}
+
+// MapCoveragePercent is the share of reachable map zones seen recently (0-100).
+func (e RuleEnv) MapCoveragePercent() float64 {
+	pct, _, _ := MapCoverage(e.Memory, e.State.Tick)
+	return pct
+}
+
+// StaleIntelAge is how many ticks ago the least recently seen reachable zone
+// was observed. Zones never seen count from the start of the game.
+func (e RuleEnv) StaleIntelAge() int {
+	_, age, _ := MapCoverage(e.Memory, e.State.Tick)
+	return age
+}
@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCoverageTracksSeenZones(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      1000,
+			MapWidth:  64,
+			MapHeight: 64,
+			Units:     []model.Unit{{ID: 1, Type: "e1", X: 2, Y: 2}},
+		},
+		Memory: mem,
+	}
+	updateCoverage(env)
+
+	pct := env.MapCoveragePercent()
+	if pct <= 0 || pct >= 10 {
+		t.Errorf("MapCoveragePercent = %.1f, want a small non-zero share", pct)
+	}
+	if got := env.StaleIntelAge(); got != 1000 {
+		t.Errorf("StaleIntelAge = %d, want 1000 (unseen zones count from game start)", got)
+	}
+
+	// Once the sighting ages past the freshness window it no longer counts.
+	env.State.Tick = 1000 + coverageFreshTicks + 1
+	env.State.Units = nil
+	if got := env.MapCoveragePercent(); got != 0 {
+		t.Errorf("MapCoveragePercent after expiry = %.1f, want 0", got)
+	}
+}
+
+func TestNextScoutZoneSkipsImpassableAndClaimed(t *testing.T) {
+	terrain := &model.TerrainGrid{
+		Cols: 2, Rows: 2, CellW: 32, CellH: 32,
+		Grid: []model.TerrainType{model.Land, model.Water, model.Land, model.Land},
+	}
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      500,
+			MapWidth:  64,
+			MapHeight: 64,
+			Units:     []model.Unit{{ID: 1, Type: "jeep", X: 10, Y: 10, Idle: true}},
+		},
+		Memory:  mem,
+		Terrain: terrain,
+	}
+	updateCoverage(env)
+
+	// Zone (0,0) is seen and (1,0) is water; of the two unseen land zones
+	// the nearer one — (0,1) and (1,1) tie on staleness — wins.
+	first, ok := env.nextScoutZone(10, 10)
+	if !ok || first.Col != 0 || first.Row != 1 {
+		t.Fatalf("first zone = %+v (ok=%v), want col 0 row 1", first, ok)
+	}
+	assignScoutTask(mem, 1, first)
+
+	second, ok := env.nextScoutZone(10, 10)
+	if !ok || second.Col != 1 || second.Row != 1 {
+		t.Errorf("second zone = %+v (ok=%v), want col 1 row 1", second, ok)
+	}
+}
+
+func TestScoutTaskStallMarksZoneVisited(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			MapWidth:  64,
+			MapHeight: 64,
+			Units:     []model.Unit{{ID: 1, Type: "jeep", X: 5, Y: 5, Idle: true}},
+		},
+		Memory: mem,
+	}
+	updateCoverage(env)
+	task, _ := env.nextScoutZone(5, 5)
+	assignScoutTask(mem, 1, task)
+
+	env.State.Tick = 100 + scoutSettleTicks + 1
+	updateCoverage(env)
+
+	if _, ok := getScoutTasks(mem)[1]; ok {
+		t.Fatal("stalled scout kept its task")
+	}
+	cov := getCoverage(mem)
+	if seen := cov.Seen[task.Row*cov.Cols+task.Col]; seen != env.State.Tick {
+		t.Errorf("unreachable zone seen tick = %d, want %d", seen, env.State.Tick)
+	}
+}
+
+func TestNextScoutZonePrefersEnemySpawns(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			MapWidth:  128,
+			MapHeight: 128,
+			Units:     []model.Unit{{ID: 1, Type: "jeep", X: 10, Y: 10, Idle: true}},
+			SpawnPoints: []model.SpawnPoint{
+				{X: 10, Y: 10, Own: true},
+				{X: 118, Y: 118},
+				{X: 118, Y: 10},
+			},
+		},
+		Memory: mem,
+	}
+	updateCoverage(env)
+
+	// The nearer enemy spawn first, though closer unseen zones exist.
+	first, ok := env.nextScoutZone(10, 10)
+	if !ok || first.X != 118 || first.Y != 10 {
+		t.Fatalf("first target = %+v (ok=%v), want spawn (118,10)", first, ok)
+	}
+	assignScoutTask(mem, 1, first)
+	second, _ := env.nextScoutZone(10, 10)
+	if second.X != 118 || second.Y != 118 {
+		t.Errorf("second target = %+v, want spawn (118,118)", second)
+	}
+
+	// A base found at one spawn takes it off the list; once the other has
+	// been seen, scouting falls back to stale zones.
+	delete(getScoutTasks(mem), 1)
+	enemyBasesMemory.set(mem, map[string]EnemyBaseIntel{
+		"enemy": {Owner: "enemy", X: 112, Y: 14, FromBuildings: true},
+	})
+	env.State.Units = append(env.State.Units, model.Unit{ID: 2, Type: "jeep", X: 118, Y: 118})
+	updateCoverage(env)
+	next, _ := env.nextScoutZone(10, 10)
+	for _, sp := range env.State.SpawnPoints {
+		if next.X == sp.X && next.Y == sp.Y {
+			t.Errorf("next target = %+v, want a zone rather than a scouted spawn", next)
+		}
+	}
+	if wps := env.scoutWaypoints(); len(wps) != 10 || wps[0] != [2]int{118, 118} {
+		t.Errorf("scoutWaypoints = %v, want the unknown spawn ahead of the 9-point pattern", wps)
+	}
+}
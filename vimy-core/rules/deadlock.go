@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Build order deadlock watchdog. Savings reservations hold cash back from
+// unit production until an expensive building is queued, but when that
+// building can't be queued — its prerequisite is blocked on power, say, or
+// the rule that would build it never fires — the reservation holds forever
+// and nothing gets built at all. The watchdog notices the Building queue
+// sitting idle with cash in the bank while reservations are active, and
+// relaxes those reservations for a while so production can move again.
+const (
+	deadlockIdleTicks  = 1500 // Building queue idle this long with cash is a deadlock
+	deadlockMinCash    = 800  // below this the queue is waiting for money, not stuck
+	deadlockRelaxTicks = 1500 // how long the blocking reservations stay relaxed
+)
+
+// deadlockWatch tracks Building queue activity and any relaxed savings.
+type deadlockWatch struct {
+	LastActive   int      // last tick the Building queue had something in it
+	Buildings    int      // building count at the last update
+	Relaxed      []string // savings names currently relaxed
+	RelaxedUntil int
+}
+
+func getDeadlockWatch(memory map[string]any) *deadlockWatch {
+	if v, ok := deadlockWatchMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// doctrineSavings returns every savings reservation the doctrine compiles
+// into its production rules. A name can appear more than once: infantry
+// rules save for the war factory under a looser gate than vehicle rules.
+func doctrineSavings(d Doctrine) []buildingSaving {
+	c := &doctrineCompiler{d: d}
+	c.initSavings()
+	return append(c.savings, c.infantrySavings...)
+}
+
+// savingPrograms caches compiled savings conditions; there are only a
+// handful of distinct ones per doctrine.
+var savingPrograms sync.Map // existsExpr → *vm.Program
+
+// savingActive reports whether the reservation is holding cash back: the
+// building it saves for doesn't exist yet.
+func savingActive(env RuleEnv, s buildingSaving) bool {
+	p, ok := savingPrograms.Load(s.existsExpr)
+	if !ok {
+		prog, err := expr.Compile(s.existsExpr, expr.Env(RuleEnv{}), expr.AsBool())
+		if err != nil {
+			slog.Warn("compile savings condition failed", "saving", s.name, "error", err)
+			return false
+		}
+		p, _ = savingPrograms.LoadOrStore(s.existsExpr, prog)
+	}
+	out, err := vm.Run(p.(*vm.Program), env)
+	if err != nil {
+		return false
+	}
+	exists, _ := out.(bool)
+	return !exists
+}
+
+// updateDeadlock watches the Building queue and relaxes the active savings
+// reservations once it has been idle for deadlockIdleTicks with at least
+// deadlockMinCash in the bank.
+func updateDeadlock(env RuleEnv) {
+	tick := env.State.Tick
+	w := getDeadlockWatch(env.Memory)
+	if w == nil {
+		w = &deadlockWatch{LastActive: tick}
+	}
+	defer deadlockWatchMemory.set(env.Memory, w)
+
+	busy := len(env.State.Buildings) > w.Buildings
+	w.Buildings = len(env.State.Buildings)
+	for _, pq := range env.State.ProductionQueues {
+		if strings.EqualFold(pq.Type, QueueBuilding) && pq.CurrentItem != "" {
+			busy = true
+		}
+	}
+	if busy || env.Cash() < deadlockMinCash {
+		w.LastActive = tick
+	}
+
+	if len(w.Relaxed) > 0 && tick >= w.RelaxedUntil {
+		slog.Info("build order savings restored", "savings", w.Relaxed, "tick", tick)
+		w.Relaxed = nil
+		w.LastActive = tick
+	}
+	if len(w.Relaxed) > 0 || tick-w.LastActive < deadlockIdleTicks {
+		return
+	}
+
+	var blocking []string
+	for _, s := range doctrineSavings(env.Doctrine) {
+		if !slices.Contains(blocking, s.name) && savingActive(env, s) {
+			blocking = append(blocking, s.name)
+		}
+	}
+	if len(blocking) == 0 {
+		return
+	}
+	slog.Warn("build order deadlock: relaxing savings",
+		"savings", blocking, "idle_ticks", tick-w.LastActive, "cash", env.Cash(), "tick", tick)
+	w.Relaxed = blocking
+	w.RelaxedUntil = tick + deadlockRelaxTicks
+}
+
+// SavingRelaxed reports whether the deadlock watchdog has relaxed the named
+// savings reservation (see buildCashCondition).
+func (e RuleEnv) SavingRelaxed(name string) bool {
+	w := getDeadlockWatch(e.Memory)
+	return w != nil && e.State.Tick < w.RelaxedUntil && slices.Contains(w.Relaxed, name)
+}
@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestDeadlockRelaxesBlockingSavings(t *testing.T) {
+	mem := map[string]any{}
+	doctrine := Doctrine{VehicleWeight: 0.6, InfantryWeight: 0.4}
+	env := func(tick, cash int, building string) RuleEnv {
+		return RuleEnv{
+			State: model.GameState{
+				Tick:      tick,
+				Player:    model.Player{Cash: cash},
+				Buildings: []model.Building{{ID: 1, Type: "fact"}, {ID: 2, Type: "powr"}},
+				ProductionQueues: []model.ProductionQueue{
+					{Type: "Building", Buildable: []string{"powr"}, CurrentItem: building},
+				},
+			},
+			Memory:   mem,
+			Doctrine: doctrine,
+		}
+	}
+	step := func(from, to, cash int, building string) {
+		for tick := from; tick <= to; tick += 50 {
+			updateDeadlock(env(tick, cash, building))
+		}
+	}
+
+	// A busy Building queue, then an idle one while broke: no deadlock.
+	step(0, 1000, 5000, "powr")
+	step(1050, 1000+2*deadlockIdleTicks, 300, "")
+	if env(3000, 5000, "").SavingRelaxed("war_factory") {
+		t.Fatal("savings relaxed while the Building queue was waiting for money")
+	}
+
+	// Idle with cash and no war factory: the war factory reservation is the
+	// one holding everything back.
+	start := 1050 + 2*deadlockIdleTicks
+	step(start, start+deadlockIdleTicks, 5000, "")
+	tick := start + deadlockIdleTicks
+	if !env(tick, 5000, "").SavingRelaxed("war_factory") {
+		t.Fatalf("war_factory saving not relaxed after %d idle ticks with cash", deadlockIdleTicks)
+	}
+
+	// The relaxation is temporary.
+	if env(tick+deadlockRelaxTicks, 5000, "").SavingRelaxed("war_factory") {
+		t.Error("war_factory saving still relaxed after deadlockRelaxTicks")
+	}
+	step(tick+50, tick+deadlockRelaxTicks, 5000, "")
+	if w := getDeadlockWatch(mem); len(w.Relaxed) != 0 {
+		t.Errorf("Relaxed = %v after expiry, want none", w.Relaxed)
+	}
+}
+
+func TestDeadlockIgnoresSatisfiedSavings(t *testing.T) {
+	mem := map[string]any{}
+	doctrine := Doctrine{VehicleWeight: 0.6, InfantryWeight: 0.4}
+	for tick := 0; tick <= 2*deadlockIdleTicks; tick += 50 {
+		updateDeadlock(RuleEnv{
+			State: model.GameState{
+				Tick:      tick,
+				Player:    model.Player{Cash: 5000},
+				Buildings: []model.Building{{ID: 1, Type: "fact"}, {ID: 2, Type: "dome"}, {ID: 3, Type: "weap"}},
+			},
+			Memory:   mem,
+			Doctrine: doctrine,
+		})
+	}
+	if w := getDeadlockWatch(mem); len(w.Relaxed) != 0 {
+		t.Errorf("Relaxed = %v with every saved-for building built, want none", w.Relaxed)
+	}
+}
@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Defense rebuilding. A pillbox or SAM site that dies marks the way an
+// attack came in, but the general defense rules would put its replacement
+// wherever defenseHint happens to score best. Lost defenses are remembered
+// with their position instead, and rebuild-lost-defense queues each one
+// again and places it back where it stood.
+
+const (
+	lostDefenseTTL           = 3000 // ticks a lost defense stays worth rebuilding
+	lostDefenseRequeueTicks  = 1000 // before a queued rebuild that never appeared is queued again
+	lostDefenseRebuiltRadius = 3    // a defense of the role this close to the spot replaces it
+	maxLostDefenses          = 8
+)
+
+// rebuildDefenseRoles are the defense roles rebuilt where they fell.
+var rebuildDefenseRoles = append(slices.Clone(defenseRoles), "aa_defense")
+
+// lostDefense is one of our defenses: while alive in defenseWatch.Seen,
+// once destroyed in defenseWatch.Lost.
+type lostDefense struct {
+	Role   string
+	X, Y   int
+	Tick   int // when it was lost
+	Queued int // tick its rebuild was last queued; 0 if never
+}
+
+// defenseWatch tracks our defenses between updates to notice losses.
+type defenseWatch struct {
+	Seen map[int]lostDefense // by actor ID
+	Lost []lostDefense       // oldest first
+}
+
+// defenseRole returns the rebuildDefenseRoles role t belongs to, or "".
+func (e RuleEnv) defenseRole(t string) string {
+	for _, name := range rebuildDefenseRoles {
+		r, ok := e.Mod.role(name)
+		if ok && slices.ContainsFunc(r.types, func(rt string) bool { return matchesType(t, rt) }) {
+			return name
+		}
+	}
+	return ""
+}
+
+// updateLostDefenses records defenses that vanished since the last update
+// and forgets lost ones that have been rebuilt or gone stale.
+func updateLostDefenses(env RuleEnv) {
+	tick := env.State.Tick
+	seen := make(map[int]lostDefense)
+	for _, b := range env.State.Buildings {
+		if role := env.defenseRole(b.Type); role != "" {
+			seen[b.ID] = lostDefense{Role: role, X: b.X, Y: b.Y}
+		}
+	}
+
+	w, _ := defenseWatchMemory.get(env.Memory)
+	if w == nil {
+		defenseWatchMemory.set(env.Memory, &defenseWatch{Seen: seen})
+		return
+	}
+	for id, d := range w.Seen {
+		if _, alive := seen[id]; !alive {
+			d.Tick = tick
+			w.Lost = append(w.Lost, d)
+			slog.Info("defense lost, marked for rebuild", "role", d.Role, "x", d.X, "y", d.Y)
+		}
+	}
+	w.Seen = seen
+
+	w.Lost = slices.DeleteFunc(w.Lost, func(l lostDefense) bool {
+		if tick-l.Tick > lostDefenseTTL {
+			return true
+		}
+		for _, d := range seen {
+			if d.Role == l.Role && chebyshev(d.X, d.Y, l.X, l.Y) <= lostDefenseRebuiltRadius {
+				return true
+			}
+		}
+		return false
+	})
+	if n := len(w.Lost) - maxLostDefenses; n > 0 {
+		w.Lost = w.Lost[n:]
+	}
+}
+
+func chebyshev(x1, y1, x2, y2 int) int {
+	return max(abs(x1-x2), abs(y1-y2))
+}
+
+// nextLostDefense returns the oldest lost defense we can build now and
+// haven't just queued, or nil.
+func (e RuleEnv) nextLostDefense() *lostDefense {
+	w, _ := defenseWatchMemory.get(e.Memory)
+	if w == nil {
+		return nil
+	}
+	for i := range w.Lost {
+		l := &w.Lost[i]
+		if l.Queued > 0 && e.State.Tick-l.Queued < lostDefenseRequeueTicks {
+			continue
+		}
+		if e.BuildableType(l.Role) != "" {
+			return l
+		}
+	}
+	return nil
+}
+
+// LostDefense reports whether a destroyed defense is waiting to be rebuilt
+// and can be.
+func (e RuleEnv) LostDefense() bool { return e.nextLostDefense() != nil }
+
+// ActionRebuildDefense queues a replacement for the oldest lost defense;
+// ActionPlaceDefense then puts it on the lost one's spot.
+func ActionRebuildDefense(env RuleEnv, conn *ipc.Connection) error {
+	l := env.nextLostDefense()
+	if l == nil {
+		return nil
+	}
+	item := env.BuildableType(l.Role)
+	l.Queued = env.State.Tick
+	slog.Info("rebuilding lost defense", "role", l.Role, "item", item, "x", l.X, "y", l.Y)
+	return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{Queue: QueueDefense, Item: item, Count: 1})
+}
+
+// lostDefenseHint returns where to place item if it is a queued rebuild of
+// a lost defense: the spot the oldest such defense stood on.
+func lostDefenseHint(env RuleEnv, item string) (x, y int, ok bool) {
+	w, _ := defenseWatchMemory.get(env.Memory)
+	if w == nil {
+		return 0, 0, false
+	}
+	role := env.defenseRole(item)
+	for _, l := range w.Lost {
+		if l.Queued > 0 && l.Role == role {
+			return l.X, l.Y, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestLostDefenseRebuiltInPlace(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	mem := make(map[string]any)
+	base := []model.Building{{ID: 1, Type: "fact", X: 50, Y: 50}}
+	env := RuleEnv{
+		State: model.GameState{
+			Tick: 100,
+			Buildings: append(base,
+				model.Building{ID: 2, Type: "pbox", X: 60, Y: 44},
+				model.Building{ID: 3, Type: "agun", X: 40, Y: 56},
+			),
+			ProductionQueues: []model.ProductionQueue{{Type: "Defense", Buildable: []string{"pbox", "agun"}}},
+		},
+		Memory: mem,
+	}
+	updateLostDefenses(env)
+	if env.LostDefense() {
+		t.Fatal("LostDefense before anything was lost")
+	}
+
+	// The pillbox dies.
+	env.State.Tick = 200
+	env.State.Buildings = append(base, model.Building{ID: 3, Type: "agun", X: 40, Y: 56})
+	updateLostDefenses(env)
+	if !env.LostDefense() {
+		t.Fatal("LostDefense = false after the pillbox died")
+	}
+
+	if err := ActionRebuildDefense(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if env.LostDefense() {
+		t.Error("rebuild queued twice in a row")
+	}
+	if x, y, ok := lostDefenseHint(env, "pbox"); !ok || x != 60 || y != 44 {
+		t.Errorf("lostDefenseHint(pbox) = %d,%d,%v; want the lost spot 60,44", x, y, ok)
+	}
+	if _, _, ok := lostDefenseHint(env, "agun"); ok {
+		t.Error("AA gun placed on the pillbox's spot")
+	}
+
+	// Still not rebuilt long after: queue it again.
+	env.State.Tick = 200 + lostDefenseRequeueTicks
+	if !env.LostDefense() {
+		t.Error("unplaced rebuild never requeued")
+	}
+
+	// A pillbox appears next to the spot: the loss is settled.
+	env.State.Buildings = append(env.State.Buildings, model.Building{ID: 9, Type: "pbox", X: 61, Y: 45})
+	updateLostDefenses(env)
+	if env.LostDefense() {
+		t.Error("LostDefense still true after the pillbox was rebuilt")
+	}
+}
+
+func TestLostDefenseExpires(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:             100,
+			Buildings:        []model.Building{{ID: 2, Type: "tsla", X: 60, Y: 44}},
+			ProductionQueues: []model.ProductionQueue{{Type: "Defense", Buildable: []string{"tsla"}}},
+		},
+		Memory: mem,
+	}
+	updateLostDefenses(env)
+	env.State.Buildings = nil
+	env.State.Tick = 150
+	updateLostDefenses(env)
+	if !env.LostDefense() {
+		t.Fatal("tesla coil loss not recorded")
+	}
+	env.State.Tick = 150 + lostDefenseTTL + 1
+	updateLostDefenses(env)
+	if env.LostDefense() {
+		t.Error("stale loss still up for rebuild")
+	}
+}
@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"log/slog"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Superweapon denial. Every enemy missile silo or iron curtain we learn of
+// is pinned as a priority target for as long as it stands, not just while
+// its power is about to fire. In sight, the pin already steers ground and
+// air targeting to it; out of sight, the denial rules send the attack
+// squads to where it was last seen, and our own nukes and parabombs are
+// spent on it before anything else.
+
+const superweaponDenialReason = "superweapon denial"
+
+// deniedSuperweapons are the enemy structures pinned for denial.
+var deniedSuperweapons = []string{MissileSilo, IronCurtain}
+
+func isDeniedSuperweapon(t string) bool {
+	for _, sw := range deniedSuperweapons {
+		if matchesType(t, sw) {
+			return true
+		}
+	}
+	return false
+}
+
+// pinSuperweapons pins every remembered standing enemy superweapon.
+func pinSuperweapons(env RuleEnv) {
+	for id, s := range GetEnemyStructures(env.Memory) {
+		if s.Destroyed || !isDeniedSuperweapon(s.Type) {
+			continue
+		}
+		if addPriorityTarget(env.Memory, PriorityTarget{EnemyID: id, Type: s.Type, Reason: superweaponDenialReason, Tick: env.State.Tick, LastX: s.X, LastY: s.Y}) {
+			slog.Info("priority target set", "enemy", id, "type", s.Type, "reason", superweaponDenialReason)
+		}
+	}
+}
+
+// denialTarget returns the first pinned enemy superweapon, by ID when it
+// is in sight and by last known position when not; nil with none pinned.
+func (e RuleEnv) denialTarget() *squadTarget {
+	for _, t := range getPriorityTargets(e.Memory) {
+		if t.EnemyID == 0 || !isDeniedSuperweapon(t.Type) {
+			continue
+		}
+		for _, en := range e.State.Enemies {
+			if en.ID == t.EnemyID {
+				return &squadTarget{ID: en.ID, X: en.X, Y: en.Y}
+			}
+		}
+		return &squadTarget{X: t.LastX, Y: t.LastY}
+	}
+	return nil
+}
+
+// HasDenialTarget reports whether an enemy superweapon is pinned.
+func (e RuleEnv) HasDenialTarget() bool { return e.denialTarget() != nil }
+
+// SquadDenySuperweapon sends the named squad's idle members at the pinned
+// enemy superweapon.
+func SquadDenySuperweapon(name string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		t := env.denialTarget()
+		ids := squadIdleActorIDs(env, name)
+		if t == nil || len(ids) == 0 {
+			return nil
+		}
+		slog.Info("squad striking enemy superweapon", "squad", name, "count", len(ids), "target", t.ID, "x", t.X, "y", t.Y)
+		env.startAttackRun(name, t.X, t.Y)
+		return sendAtTarget(conn, ids, t)
+	}
+}
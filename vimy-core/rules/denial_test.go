@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestSuperweaponDenial(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Units:     []model.Unit{{ID: 2, Type: "1tnk", X: 12, Y: 12, Idle: true}},
+		},
+		Memory: make(map[string]any),
+	}
+	enemyStructuresMemory.set(env.Memory, map[int]*EnemyStructure{
+		50: {ID: 50, Type: "powr", X: 70, Y: 70},
+		51: {ID: 51, Type: "iron", X: 80, Y: 80},
+	})
+	squadsMemory.set(env.Memory, map[string]*Squad{
+		"ground-attack": {Name: "ground-attack", Domain: "ground", Role: "attack", UnitIDs: []int{2}, TargetSize: 1},
+	})
+
+	updatePriorityTargets(env)
+	if !env.HasDenialTarget() {
+		t.Fatal("remembered iron curtain was not pinned")
+	}
+	if tgt := env.denialTarget(); tgt.ID != 0 || tgt.X != 80 || tgt.Y != 80 {
+		t.Errorf("denialTarget = %+v, want last known position 80,80", tgt)
+	}
+	if err := SquadDenySuperweapon("ground-attack")(env, conn); err != nil {
+		t.Fatal(err)
+	}
+
+	// In sight, the pin targets the structure itself and overrides scoring.
+	env.State.Enemies = []model.Enemy{
+		{ID: 51, Type: "iron", X: 80, Y: 80},
+		{ID: 60, Type: "3tnk", X: 14, Y: 14},
+	}
+	updatePriorityTargets(env)
+	if tgt := env.denialTarget(); tgt == nil || tgt.ID != 51 {
+		t.Errorf("denialTarget = %+v, want enemy 51", tgt)
+	}
+	if best := env.BestGroundTarget(); best == nil || best.ID != 51 {
+		t.Errorf("BestGroundTarget = %+v, want the iron curtain", best)
+	}
+
+	// Confirmed destroyed: the site is in sight and the structure is gone.
+	env.State.Enemies = nil
+	env.State.Units[0].X, env.State.Units[0].Y = 78, 78
+	updateEnemyStructures(env)
+	updatePriorityTargets(env)
+	if env.HasDenialTarget() {
+		t.Error("denial target kept after the iron curtain was destroyed")
+	}
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RuleChange describes how one rule present in both rule sets differs.
+type RuleChange struct {
+	Name             string `json:"name"`
+	OldPriority      int    `json:"old_priority"`
+	NewPriority      int    `json:"new_priority"`
+	CategoryChanged  bool   `json:"category_changed,omitempty"`
+	ExclusiveChanged bool   `json:"exclusive_changed,omitempty"`
+	CooldownChanged  bool   `json:"cooldown_changed,omitempty"`
+	ConditionChanged bool   `json:"condition_changed,omitempty"`
+}
+
+// RuleDiff is the difference between two rule sets, matched by rule name.
+// Each list is sorted by name.
+type RuleDiff struct {
+	Added   []string     `json:"added,omitempty"`
+	Removed []string     `json:"removed,omitempty"`
+	Changed []RuleChange `json:"changed,omitempty"`
+}
+
+// Diff compares two rule sets by name: rules only in newRules are added,
+// rules only in oldRules are removed, and rules in both whose priority,
+// category, exclusivity, cooldown or condition differ are changed.
+func Diff(oldRules, newRules []*Rule) RuleDiff {
+	oldByName := make(map[string]*Rule, len(oldRules))
+	for _, r := range oldRules {
+		oldByName[r.Name] = r
+	}
+	newByName := make(map[string]*Rule, len(newRules))
+	for _, r := range newRules {
+		newByName[r.Name] = r
+	}
+
+	var d RuleDiff
+	for name, nr := range newByName {
+		or, ok := oldByName[name]
+		if !ok {
+			d.Added = append(d.Added, name)
+			continue
+		}
+		c := RuleChange{
+			Name:             name,
+			OldPriority:      or.Priority,
+			NewPriority:      nr.Priority,
+			CategoryChanged:  or.Category != nr.Category,
+			ExclusiveChanged: or.Exclusive != nr.Exclusive,
+			CooldownChanged:  or.CooldownTicks != nr.CooldownTicks,
+			ConditionChanged: or.ConditionSrc != nr.ConditionSrc,
+		}
+		if c.OldPriority != c.NewPriority || c.CategoryChanged || c.ExclusiveChanged || c.CooldownChanged || c.ConditionChanged {
+			d.Changed = append(d.Changed, c)
+		}
+	}
+	for name := range oldByName {
+		if _, ok := newByName[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.SortFunc(d.Changed, func(a, b RuleChange) int { return strings.Compare(a.Name, b.Name) })
+	return d
+}
+
+// Empty reports whether the two rule sets were equivalent.
+func (d RuleDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String renders each change as a line: "+name", "-name", or
+// "~name priority 500→620 condition".
+func (d RuleDiff) String() string {
+	var b strings.Builder
+	for _, n := range d.Added {
+		fmt.Fprintf(&b, "+%s\n", n)
+	}
+	for _, n := range d.Removed {
+		fmt.Fprintf(&b, "-%s\n", n)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~%s", c.Name)
+		if c.OldPriority != c.NewPriority {
+			fmt.Fprintf(&b, " priority %d→%d", c.OldPriority, c.NewPriority)
+		}
+		if c.CategoryChanged {
+			b.WriteString(" category")
+		}
+		if c.ExclusiveChanged {
+			b.WriteString(" exclusive")
+		}
+		if c.CooldownChanged {
+			b.WriteString(" cooldown")
+		}
+		if c.ConditionChanged {
+			b.WriteString(" condition")
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
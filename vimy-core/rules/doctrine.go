@@ -5,31 +5,67 @@ import "math"
 // Doctrine is the LLM's output — a strategic posture expressed as continuous
 // 0–1 weights. CompileDoctrine translates these into discrete rule sets.
 type Doctrine struct {
-	Name                  string  `json:"name"`
-	Rationale             string  `json:"rationale"`
-	EconomyPriority       float64 `json:"economy_priority"`
-	Aggression            float64 `json:"aggression"`
-	GroundDefensePriority float64 `json:"ground_defense_priority"`
-	AirDefensePriority    float64 `json:"air_defense_priority"`
-	TechPriority          float64 `json:"tech_priority"`
-	InfantryWeight        float64 `json:"infantry_weight"`
-	VehicleWeight         float64 `json:"vehicle_weight"`
-	AirWeight             float64 `json:"air_weight"`
-	NavalWeight           float64 `json:"naval_weight"`
-	GroundAttackGroupSize int     `json:"ground_attack_group_size"`
-	AirAttackGroupSize    int     `json:"air_attack_group_size"`
-	NavalAttackGroupSize  int     `json:"naval_attack_group_size"`
-	ScoutPriority              float64 `json:"scout_priority"`
-	SpecializedInfantryWeight  float64 `json:"specialized_infantry_weight"`
-	SuperweaponPriority        float64 `json:"superweapon_priority"`
-	CapturePriority            float64  `json:"capture_priority"`
-	PreferredInfantry          []string `json:"preferred_infantry,omitempty"`
-	PreferredVehicle           []string `json:"preferred_vehicle,omitempty"`
-	PreferredAircraft          []string `json:"preferred_aircraft,omitempty"`
-	PreferredNaval             []string `json:"preferred_naval,omitempty"`
-	TransportAssault           float64  `json:"transport_assault,omitempty"`
+	Name                  string  `json:"name" yaml:"name"`
+	Rationale             string  `json:"rationale" yaml:"rationale"`
+	EconomyPriority       float64 `json:"economy_priority" yaml:"economy_priority"`
+	Aggression            float64 `json:"aggression" yaml:"aggression"`
+	GroundDefensePriority float64 `json:"ground_defense_priority" yaml:"ground_defense_priority"`
+	AirDefensePriority    float64 `json:"air_defense_priority" yaml:"air_defense_priority"`
+	TechPriority          float64 `json:"tech_priority" yaml:"tech_priority"`
+	InfantryWeight        float64 `json:"infantry_weight" yaml:"infantry_weight"`
+	VehicleWeight         float64 `json:"vehicle_weight" yaml:"vehicle_weight"`
+	AirWeight             float64 `json:"air_weight" yaml:"air_weight"`
+	NavalWeight           float64 `json:"naval_weight" yaml:"naval_weight"`
+	GroundAttackGroupSize int     `json:"ground_attack_group_size" yaml:"ground_attack_group_size"`
+	AirAttackGroupSize    int     `json:"air_attack_group_size" yaml:"air_attack_group_size"`
+	NavalAttackGroupSize  int     `json:"naval_attack_group_size" yaml:"naval_attack_group_size"`
+	ScoutPriority              float64 `json:"scout_priority" yaml:"scout_priority"`
+	SpecializedInfantryWeight  float64 `json:"specialized_infantry_weight" yaml:"specialized_infantry_weight"`
+	SuperweaponPriority        float64 `json:"superweapon_priority" yaml:"superweapon_priority"`
+	CapturePriority            float64  `json:"capture_priority" yaml:"capture_priority"`
+	PreferredInfantry          []string `json:"preferred_infantry,omitempty" yaml:"preferred_infantry,omitempty"`
+	PreferredVehicle           []string `json:"preferred_vehicle,omitempty" yaml:"preferred_vehicle,omitempty"`
+	PreferredAircraft          []string `json:"preferred_aircraft,omitempty" yaml:"preferred_aircraft,omitempty"`
+	PreferredNaval             []string `json:"preferred_naval,omitempty" yaml:"preferred_naval,omitempty"`
+	TransportAssault           float64  `json:"transport_assault,omitempty" yaml:"transport_assault,omitempty"`
+	Forwardness                float64  `json:"forwardness,omitempty" yaml:"forwardness,omitempty"` // -1 tucks barracks and war factories behind the base, 1 pushes them toward the enemy; 0 is the standard layout
+	Opening                    string   `json:"opening,omitempty" yaml:"opening,omitempty"` // scripted opening book name; empty leaves the opening to the rules
+	DefenseMargin              float64  `json:"defense_margin,omitempty" yaml:"defense_margin,omitempty"` // cells beyond our buildings that count as the base; 0 is DefaultDefenseMargin
+	CombinedArms               float64  `json:"combined_arms,omitempty" yaml:"combined_arms,omitempty"` // 0–1: hold air strikes for the ground assault's arrival; higher waits until the ground squad is closer
+	GroundAggression           *float64 `json:"ground_aggression,omitempty" yaml:"ground_aggression,omitempty"` // per-domain overrides of Aggression for attack squads; nil follows Aggression
+	AirAggression              *float64 `json:"air_aggression,omitempty" yaml:"air_aggression,omitempty"`
+	NavalAggression            *float64 `json:"naval_aggression,omitempty" yaml:"naval_aggression,omitempty"`
 }
 
+// Defense margin bounds, in cells. The low end still covers a tesla coil's
+// reach; the high end keeps a sprawling base from claiming the whole map.
+const (
+	MinDefenseMargin     = 10.0
+	MaxDefenseMargin     = 30.0
+	DefaultDefenseMargin = 18.0
+)
+
+// defenseMargin returns the margin, falling back to the default when unset.
+func (d Doctrine) defenseMargin() float64 {
+	if d.DefenseMargin == 0 {
+		return DefaultDefenseMargin
+	}
+	return d.DefenseMargin
+}
+
+// domainAggression returns a per-domain aggression override, or Aggression
+// when the doctrine leaves that domain alone.
+func (d Doctrine) domainAggression(override *float64) float64 {
+	if override == nil {
+		return d.Aggression
+	}
+	return *override
+}
+
+func (d Doctrine) groundAggression() float64 { return d.domainAggression(d.GroundAggression) }
+func (d Doctrine) airAggression() float64    { return d.domainAggression(d.AirAggression) }
+func (d Doctrine) navalAggression() float64  { return d.domainAggression(d.NavalAggression) }
+
 // DefaultDoctrine is used when no LLM strategist is configured.
 func DefaultDoctrine() Doctrine {
 	return Doctrine{
@@ -67,6 +103,18 @@ func (d *Doctrine) Validate() {
 	d.SuperweaponPriority = clamp(d.SuperweaponPriority, 0, 1)
 	d.CapturePriority = clamp(d.CapturePriority, 0, 1)
 	d.TransportAssault = clamp(d.TransportAssault, 0, 1)
+	d.Forwardness = clamp(d.Forwardness, -1, 1)
+	d.CombinedArms = clamp(d.CombinedArms, 0, 1)
+	// Overrides get fresh pointers: a copied Doctrine shares them.
+	for _, p := range []**float64{&d.GroundAggression, &d.AirAggression, &d.NavalAggression} {
+		if *p != nil {
+			v := clamp(**p, 0, 1)
+			*p = &v
+		}
+	}
+	if d.DefenseMargin != 0 {
+		d.DefenseMargin = clamp(d.DefenseMargin, MinDefenseMargin, MaxDefenseMargin)
+	}
 	d.GroundAttackGroupSize = clampInt(d.GroundAttackGroupSize, 3, 15)
 	d.AirAttackGroupSize = clampInt(d.AirAttackGroupSize, 1, 8)
 	d.NavalAttackGroupSize = clampInt(d.NavalAttackGroupSize, 2, 10)
@@ -105,6 +105,15 @@ func TestValidate(t *testing.T) {
 		t.Errorf("SpecializedInfantryWeight = %f, want 0.0 (clamped from unset)", d.SpecializedInfantryWeight)
 	}
 
+	if d.DefenseMargin != 0 {
+		t.Errorf("DefenseMargin = %f, want 0 (unset keeps the default)", d.DefenseMargin)
+	}
+	wide := Doctrine{DefenseMargin: 100}
+	wide.Validate()
+	if wide.DefenseMargin != MaxDefenseMargin {
+		t.Errorf("DefenseMargin = %f, want %f (clamped)", wide.DefenseMargin, MaxDefenseMargin)
+	}
+
 	d3 := Doctrine{SpecializedInfantryWeight: 1.5}
 	d3.Validate()
 	if d3.SpecializedInfantryWeight != 1.0 {
@@ -145,3 +154,40 @@ func TestValidate(t *testing.T) {
 		t.Errorf("NavalAttackGroupSize = %d, want 10 (clamped)", d2.NavalAttackGroupSize)
 	}
 }
+
+func TestDomainAggression(t *testing.T) {
+	d := DefaultDoctrine()
+	d.AirWeight = 0.5
+	base := CompileDoctrine(d)
+
+	// Harass by air, turtle on land.
+	shared := 1.4
+	d.AirAggression = &shared
+	d.GroundAggression = floatPtr(0.1)
+	copied := d
+	d.Validate()
+	if *d.AirAggression != 1 || shared != 1.4 || *copied.AirAggression != 1.4 {
+		t.Errorf("air aggression %v (copy %v, shared %v): Validate should clamp a fresh override", *d.AirAggression, *copied.AirAggression, shared)
+	}
+	if d.navalAggression() != d.Aggression {
+		t.Errorf("unset naval aggression = %v, want Aggression %v", d.navalAggression(), d.Aggression)
+	}
+
+	split := CompileDoctrine(d)
+	priority := func(rules []*Rule, name string) int {
+		r := findRule(rules, name)
+		if r == nil {
+			t.Fatalf("missing %s", name)
+		}
+		return r.Priority
+	}
+	if priority(split, "squad-air-attack") <= priority(base, "squad-air-attack") {
+		t.Error("air aggression override didn't raise the air strike priority")
+	}
+	if priority(split, "squad-attack") >= priority(base, "squad-attack") {
+		t.Error("ground aggression override didn't lower the ground attack priority")
+	}
+	if r := findRule(split, "squad-air-attack"); !containsSubstring(r.ConditionSrc, `AttackThreshold("air-attack", 0.60)`) {
+		t.Errorf("full air aggression should launch at 60%% readiness: %s", r.ConditionSrc)
+	}
+}
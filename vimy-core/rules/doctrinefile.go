@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Doctrine files. A doctrine round-trips through JSON and YAML so one the
+// strategist produced can be saved from the dashboard, edited by hand and
+// fed back to the offline tools' -doctrine flag. Both forms carry
+// DoctrineSchemaVersion: a file without one is read as version 1, one from a
+// newer schema is refused rather than half-read. Decoding rejects unknown
+// keys — a misspelt weight would otherwise read as 0 — and runs Validate.
+
+// DoctrineSchemaVersion is bumped whenever a doctrine field is renamed,
+// removed or changes meaning; adding an optional field doesn't need it.
+const DoctrineSchemaVersion = 1
+
+// doctrineFields has Doctrine's fields without its methods, so encoding one
+// doesn't recurse into MarshalJSON/UnmarshalJSON.
+type doctrineFields Doctrine
+
+type versionedDoctrine struct {
+	SchemaVersion  int `json:"schema_version" yaml:"schema_version"`
+	doctrineFields `yaml:",inline"`
+}
+
+// MarshalJSON writes the doctrine with its schema version.
+func (d Doctrine) MarshalJSON() ([]byte, error) {
+	return json.Marshal(versionedDoctrine{SchemaVersion: DoctrineSchemaVersion, doctrineFields: doctrineFields(d)})
+}
+
+// UnmarshalJSON reads a doctrine written by MarshalJSON or by hand, checking
+// its schema version and field names, then validates it.
+func (d *Doctrine) UnmarshalJSON(data []byte) error {
+	var v versionedDoctrine
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return v.doctrine(d)
+}
+
+// doctrine checks v's schema version and stores it, validated, in d.
+func (v versionedDoctrine) doctrine(d *Doctrine) error {
+	if v.SchemaVersion < 0 || v.SchemaVersion > DoctrineSchemaVersion {
+		return fmt.Errorf("doctrine schema version %d not supported (this build reads up to %d)", v.SchemaVersion, DoctrineSchemaVersion)
+	}
+	*d = Doctrine(v.doctrineFields)
+	d.Validate()
+	return nil
+}
+
+// MarshalDoctrineYAML writes d as YAML with its schema version, fields in
+// declaration order.
+func MarshalDoctrineYAML(d Doctrine) ([]byte, error) {
+	return yaml.Marshal(versionedDoctrine{SchemaVersion: DoctrineSchemaVersion, doctrineFields: doctrineFields(d)})
+}
+
+// UnmarshalDoctrineYAML reads a doctrine from a YAML document, checking its
+// schema version and field names like UnmarshalJSON, then validates it.
+func UnmarshalDoctrineYAML(data []byte, d *Doctrine) error {
+	var v versionedDoctrine
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err := dec.Decode(new(yaml.Node)); !errors.Is(err, io.EOF) {
+		return errors.New("a doctrine file holds one YAML document")
+	}
+	return v.doctrine(d)
+}
+
+// isYAMLPath reports whether path names a YAML file by its extension.
+func isYAMLPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// LoadDoctrine reads a doctrine file: YAML for a .yaml or .yml path, JSON
+// otherwise.
+func LoadDoctrine(path string) (Doctrine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Doctrine{}, fmt.Errorf("read doctrine: %w", err)
+	}
+	var d Doctrine
+	if isYAMLPath(path) {
+		err = UnmarshalDoctrineYAML(data, &d)
+	} else {
+		err = json.Unmarshal(data, &d)
+	}
+	if err != nil {
+		return Doctrine{}, fmt.Errorf("unmarshal doctrine %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// SaveDoctrine writes d to path in the format LoadDoctrine reads back.
+func SaveDoctrine(path string, d Doctrine) error {
+	var data []byte
+	var err error
+	if isYAMLPath(path) {
+		data, err = MarshalDoctrineYAML(d)
+	} else {
+		data, err = json.MarshalIndent(d, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("marshal doctrine: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write doctrine: %w", err)
+	}
+	return nil
+}
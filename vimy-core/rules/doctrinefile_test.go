@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleDoctrine() Doctrine {
+	d := DefaultDoctrine()
+	d.Name = "Sky Raiders"
+	d.Rationale = `Harass by air: "hit 'em where it hurts"`
+	d.AirWeight = 0.7
+	d.AirAggression = floatPtr(0.9)
+	d.GroundAggression = floatPtr(0)
+	d.PreferredAircraft = []string{"advanced_aircraft", "basic_aircraft"}
+	d.Forwardness = -0.4
+	d.Validate()
+	return d
+}
+
+func TestDoctrineFileRoundTrip(t *testing.T) {
+	d := sampleDoctrine()
+	dir := t.TempDir()
+	for _, name := range []string{"doctrine.json", "doctrine.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := SaveDoctrine(path, d); err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadDoctrine(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, d) {
+			t.Errorf("%s round trip:\n got %+v\nwant %+v", name, got, d)
+		}
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"schema_version":1`) {
+		t.Errorf("JSON lacks the schema version: %s", data)
+	}
+}
+
+func TestDoctrineYAMLHandEdited(t *testing.T) {
+	src := `# turtle on land, raid by air
+name: Sky Raiders   # inline comment
+rationale: >-
+  it's a
+  'plan'
+aggression: 0.4
+air_aggression: 1.5
+air_weight: 0.6
+preferred_aircraft:
+  - advanced_aircraft
+  - "basic_aircraft"
+preferred_vehicle: [heavy_tank, 'medium_tank']  # flow list
+"tech_priority": 0.3
+ground_attack_group_size: 6
+`
+	var d Doctrine
+	if err := UnmarshalDoctrineYAML([]byte(src), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Sky Raiders" || d.Rationale != "it's a 'plan'" || d.GroundAttackGroupSize != 6 {
+		t.Errorf("scalars = %q, %q, %d", d.Name, d.Rationale, d.GroundAttackGroupSize)
+	}
+	if d.AirAggression == nil || *d.AirAggression != 1 {
+		t.Errorf("air aggression = %v, want 1 (validated)", d.AirAggression)
+	}
+	if d.GroundAggression != nil {
+		t.Error("unset ground aggression read as an override")
+	}
+	if !reflect.DeepEqual(d.PreferredAircraft, []string{"advanced_aircraft", "basic_aircraft"}) ||
+		!reflect.DeepEqual(d.PreferredVehicle, []string{"heavy_tank", "medium_tank"}) {
+		t.Errorf("lists = %v, %v", d.PreferredAircraft, d.PreferredVehicle)
+	}
+	if d.TechPriority != 0.3 {
+		t.Errorf("quoted key: tech priority = %v, want 0.3", d.TechPriority)
+	}
+	if d.AirAttackGroupSize != 1 {
+		t.Errorf("missing air group size = %d, want Validate's minimum 1", d.AirAttackGroupSize)
+	}
+}
+
+func TestDoctrineFileRejects(t *testing.T) {
+	for name, src := range map[string]string{
+		"misspelt field": "agression: 0.9\n",
+		"newer schema":   "schema_version: 99\naggression: 0.5\n",
+		"nested mapping": "aggression:\n  ground: 0.5\n",
+		"not a number":   "aggression: high\n",
+		"two documents":  "aggression: 0.5\n---\naggression: 0.6\n",
+		"duplicate":      "aggression: 0.5\naggression: 0.6\n",
+	} {
+		var d Doctrine
+		if err := UnmarshalDoctrineYAML([]byte(src), &d); err == nil {
+			t.Errorf("%s: accepted %q", name, src)
+		}
+	}
+	var d Doctrine
+	if err := json.Unmarshal([]byte(`{"agression": 0.9}`), &d); err == nil {
+		t.Error("JSON with a misspelt field accepted")
+	}
+	if err := json.Unmarshal([]byte(`{"schema_version": 2}`), &d); err == nil {
+		t.Error("JSON from a newer schema accepted")
+	}
+	if err := json.Unmarshal([]byte(`{"name": "legacy", "aggression": 0.7}`), &d); err != nil || d.Aggression != 0.7 {
+		t.Errorf("unversioned JSON: %v, aggression %v", err, d.Aggression)
+	}
+}
@@ -0,0 +1,195 @@
+package rules
+
+import "math"
+
+// Harvester economy model, from RA mod defaults. A full harvester carries
+// 20 bales of ore at 25 credits each; travel is at normal game speed.
+const (
+	harvesterLoadValue   = 500  // credits per full load
+	harvesterCellsPerSec = 2.0  // average travel speed, cells/second
+	harvesterDockSeconds = 12.0 // harvesting + unloading time per trip
+	minOreDistance       = 6.0  // ore fields never sit on top of the refinery
+	ticksPerSecond       = 25   // game ticks per second at normal speed
+)
+
+// IncomeRate estimates credits per second from our harvesters. Each
+// harvester's trip length is its measured round-trip time once one has been
+// observed, otherwise approximated from its current distance to the nearest
+// refinery (floored at minOreDistance), so a base mining a distant field
+// correctly reports slower income than one mining next door.
+func (e RuleEnv) IncomeRate() float64 {
+	var refineries [][2]int
+	for _, b := range e.State.Buildings {
+		if matchesType(b.Type, Refinery) {
+			refineries = append(refineries, [2]int{b.X, b.Y})
+		}
+	}
+	if len(refineries) == 0 {
+		return 0
+	}
+
+	tracks := getHarvesterTracks(e.Memory)
+	rate := 0.0
+	for _, u := range e.units().OfType(Harvester) {
+		if t := tracks[u.ID]; t != nil && t.TripTicks > 0 {
+			rate += harvesterLoadValue / (t.TripTicks / ticksPerSecond)
+			continue
+		}
+		nearest := math.MaxFloat64
+		for _, r := range refineries {
+			nearest = math.Min(nearest, math.Hypot(float64(u.X-r[0]), float64(u.Y-r[1])))
+		}
+		dist := math.Max(nearest, minOreDistance)
+		trip := 2*dist/harvesterCellsPerSec + harvesterDockSeconds
+		rate += harvesterLoadValue / trip
+	}
+	return rate
+}
+
+// ProjectedCash estimates spendable cash the given number of seconds from
+// now at the current income rate. OpenRA deducts build cost progressively,
+// so an expensive building can be queued once projected cash covers it.
+func (e RuleEnv) ProjectedCash(seconds int) int {
+	return e.Cash() + int(e.IncomeRate()*float64(seconds))
+}
+
+// Harvester trip tracking thresholds (cells / game ticks).
+const (
+	dockRadius            = 3.0  // within this of a refinery counts as docked
+	dockWaitTicks         = 150  // longer than a normal unload — queueing for the dock
+	farOreDistance        = 20.0 // ore this far out is worth a closer refinery
+	harvestersPerRefinery = 2    // target saturation before refinery dock congests
+)
+
+// harvesterTrack follows one harvester across refinery round trips so we can
+// tell a harvester shortage (short trips, free docks) from a refinery
+// shortage (long hauls or harvesters queueing to unload).
+type harvesterTrack struct {
+	Docked    bool
+	Idle      bool
+	DockTick  int // tick the harvester last arrived at a refinery
+	TripStart int // tick the current trip left the refinery
+	FarX      int // farthest point this trip — approximates the ore field
+	FarY      int
+	FarDist   float64
+	TripTicks float64 // smoothed round-trip time of completed trips
+	OreX      int     // ore field estimate from the last completed trip
+	OreY      int
+	OreDist   float64 // refinery-to-ore distance on the last completed trip
+}
+
+func getHarvesterTracks(memory map[string]any) map[int]*harvesterTrack {
+	if v, ok := harvesterTracksMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*harvesterTrack)
+}
+
+// updateHarvesterTracks records dock arrivals/departures and the farthest
+// point of each trip. Dead harvesters are dropped.
+func updateHarvesterTracks(env RuleEnv) {
+	tracks := getHarvesterTracks(env.Memory)
+	tick := env.State.Tick
+	alive := make(map[int]bool)
+
+	for _, u := range env.units().OfType(Harvester) {
+		alive[u.ID] = true
+		t := tracks[u.ID]
+		if t == nil {
+			t = &harvesterTrack{TripStart: tick}
+			tracks[u.ID] = t
+		}
+
+		t.Idle = u.Idle
+		dist := env.nearestRefineryDist(u.X, u.Y)
+		switch {
+		case dist <= dockRadius && !t.Docked:
+			// Arrived: close out the trip if it actually went somewhere.
+			t.Docked = true
+			t.DockTick = tick
+			if t.FarDist > dockRadius {
+				trip := float64(tick - t.TripStart)
+				if t.TripTicks == 0 {
+					t.TripTicks = trip
+				} else {
+					t.TripTicks = 0.7*t.TripTicks + 0.3*trip
+				}
+				t.OreX, t.OreY, t.OreDist = t.FarX, t.FarY, t.FarDist
+			}
+		case dist > dockRadius && t.Docked:
+			t.Docked = false
+			t.TripStart = tick
+			t.FarDist = 0
+		}
+		if !t.Docked && dist > t.FarDist {
+			t.FarX, t.FarY, t.FarDist = u.X, u.Y, dist
+		}
+	}
+
+	for id := range tracks {
+		if !alive[id] {
+			delete(tracks, id)
+		}
+	}
+	harvesterTracksMemory.set(env.Memory, tracks)
+}
+
+func (e RuleEnv) nearestRefineryDist(x, y int) float64 {
+	nearest := math.MaxFloat64
+	for _, b := range e.State.Buildings {
+		if matchesType(b.Type, Refinery) {
+			nearest = math.Min(nearest, math.Hypot(float64(x-b.X), float64(y-b.Y)))
+		}
+	}
+	return nearest
+}
+
+// HarvesterBottleneck reports what limits income: "refinery" when harvesters
+// queue at the docks or haul from distant ore, "harvesters" when refineries
+// are under-saturated, or "" when balanced (or there is no refinery).
+func (e RuleEnv) HarvesterBottleneck() string {
+	refineries := e.RoleCount("refinery")
+	if refineries == 0 {
+		return ""
+	}
+
+	waiting, hauls := 0, 0
+	oreDist := 0.0
+	for _, t := range getHarvesterTracks(e.Memory) {
+		// Idle harvesters parked by the refinery aren't queueing to unload.
+		if t.Docked && !t.Idle && e.State.Tick-t.DockTick > dockWaitTicks {
+			waiting++
+		}
+		if t.OreDist > 0 {
+			oreDist += t.OreDist
+			hauls++
+		}
+	}
+	if waiting >= refineries {
+		return "refinery"
+	}
+	if hauls > 0 && oreDist/float64(hauls) > farOreDistance {
+		return "refinery"
+	}
+	if e.RoleCount("harvester") < refineries*harvestersPerRefinery {
+		return "harvesters"
+	}
+	return ""
+}
+
+// OreFieldEstimate averages where harvesters turned around on their last
+// trips — a proxy for the ore field being mined. ok is false with no data.
+func (e RuleEnv) OreFieldEstimate() (x, y int, ok bool) {
+	sumX, sumY, n := 0, 0, 0
+	for _, t := range getHarvesterTracks(e.Memory) {
+		if t.OreDist > 0 {
+			sumX += t.OreX
+			sumY += t.OreY
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	return sumX / n, sumY / n, true
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// harvesterEnv builds a state with one refinery at (50,50) and the given harvesters.
+func harvesterEnv(tick int, memory map[string]any, harvesters ...model.Unit) RuleEnv {
+	return RuleEnv{
+		State: model.GameState{
+			Tick:      tick,
+			Buildings: []model.Building{{ID: 1, Type: "proc", X: 50, Y: 50}},
+			Units:     harvesters,
+		},
+		Memory: memory,
+	}
+}
+
+func TestHarvesterTripTracking(t *testing.T) {
+	mem := make(map[string]any)
+	// Leave the refinery, reach ore 30 cells out, come back.
+	path := []struct{ tick, x, y int }{
+		{0, 51, 50}, {10, 60, 50}, {50, 80, 50}, {100, 60, 50}, {150, 51, 50},
+	}
+	for _, p := range path {
+		updateHarvesterTracks(harvesterEnv(p.tick, mem, model.Unit{ID: 7, Type: "harv", X: p.x, Y: p.y}))
+	}
+
+	tr := getHarvesterTracks(mem)[7]
+	if tr == nil || !tr.Docked {
+		t.Fatalf("expected docked track for harvester 7, got %+v", tr)
+	}
+	if tr.OreX != 80 || tr.OreDist != 30 {
+		t.Errorf("ore estimate = (%d, %.0f), want x=80 dist=30", tr.OreX, tr.OreDist)
+	}
+	if tr.TripTicks != 140 {
+		t.Errorf("TripTicks = %.0f, want 140", tr.TripTicks)
+	}
+
+	env := harvesterEnv(150, mem, model.Unit{ID: 7, Type: "harv", X: 51, Y: 50})
+	if got := env.HarvesterBottleneck(); got != "refinery" {
+		t.Errorf("distant ore: HarvesterBottleneck() = %q, want refinery", got)
+	}
+	if x, y, ok := env.OreFieldEstimate(); !ok || x != 80 || y != 50 {
+		t.Errorf("OreFieldEstimate() = (%d,%d,%v), want (80,50,true)", x, y, ok)
+	}
+}
+
+func TestHarvesterBottleneckUnderSaturated(t *testing.T) {
+	mem := make(map[string]any)
+	env := harvesterEnv(0, mem, model.Unit{ID: 7, Type: "harv", X: 60, Y: 50})
+	updateHarvesterTracks(env)
+	if got := env.HarvesterBottleneck(); got != "harvesters" {
+		t.Errorf("one harvester per refinery: HarvesterBottleneck() = %q, want harvesters", got)
+	}
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Enemy AA awareness for air strikes. A target ringed by SAMs costs
+// aircraft to reach, so its score is discounted by the AA covering it, and
+// a heavily defended target is only struck by a squad big enough to
+// absorb the losses. AA sites are remembered once seen, so a strike isn't
+// flown into a SAM nest just because the fog has closed over it.
+const (
+	aaCoverRadius       = 8.0 // cells — roughly SAM site range
+	aaDensityPenalty    = 0.5 // score divisor per covering AA, on top of 1
+	aaHeavyDefense      = 3   // covering AA at which a target needs a larger strike
+	aaHeavyStrikeSize   = 4   // aircraft needed against aaHeavyDefense AA
+	aaStrikeSizePerSite = 1   // extra aircraft per AA beyond aaHeavyDefense
+)
+
+// enemyAASite remembers a static enemy AA defense's last known position.
+type enemyAASite struct {
+	Type     string
+	X, Y     int
+	LastSeen int
+}
+
+func getEnemyAA(memory map[string]any) map[int]*enemyAASite {
+	if v, ok := enemyAAMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*enemyAASite)
+}
+
+// isStaticAA reports whether t is an AA defense building.
+func isStaticAA(t string) bool {
+	return matchesType(t, AAGun) || matchesType(t, SAMSite)
+}
+
+// updateEnemyAA records visible enemy AA sites and forgets ones whose site
+// we can see but which are no longer there.
+func updateEnemyAA(env RuleEnv) {
+	sites := getEnemyAA(env.Memory)
+	visible := make(map[int]bool)
+	for _, en := range env.State.Enemies {
+		if !isStaticAA(en.Type) {
+			continue
+		}
+		visible[en.ID] = true
+		sites[en.ID] = &enemyAASite{Type: en.Type, X: en.X, Y: en.Y, LastSeen: env.State.Tick}
+	}
+
+	for id, s := range sites {
+		if !visible[id] && siteObserved(env, s.X, s.Y) {
+			delete(sites, id)
+		}
+	}
+	if len(sites) == 0 {
+		enemyAAMemory.clear(env.Memory)
+		return
+	}
+	enemyAAMemory.set(env.Memory, sites)
+}
+
+// aaCoverage counts the enemy AA within aaCoverRadius of target, other than
+// target itself: visible AA sites and flak trucks, plus remembered AA
+// sites out of sight.
+func (e RuleEnv) aaCoverage(target *model.Enemy) int {
+	near := func(x, y int) bool {
+		return math.Hypot(float64(x-target.X), float64(y-target.Y)) <= aaCoverRadius
+	}
+	seen := make(map[int]bool)
+	n := 0
+	for _, en := range e.State.Enemies {
+		if en.ID == target.ID || !(isStaticAA(en.Type) || matchesType(en.Type, FlakTruck)) {
+			continue
+		}
+		seen[en.ID] = true
+		if near(en.X, en.Y) {
+			n++
+		}
+	}
+	for id, s := range getEnemyAA(e.Memory) {
+		if id != target.ID && !seen[id] && near(s.X, s.Y) {
+			n++
+		}
+	}
+	return n
+}
+
+// aaRiskFactor scales an air target's score down by the AA covering it.
+func (e RuleEnv) aaRiskFactor(target *model.Enemy) float64 {
+	return 1 / (1 + aaDensityPenalty*float64(e.aaCoverage(target)))
+}
+
+// airStrikeSizeFor returns the fewest aircraft that should strike target:
+// any number for lightly defended targets, more the heavier its AA.
+func (e RuleEnv) airStrikeSizeFor(target *model.Enemy) int {
+	aa := e.aaCoverage(target)
+	if aa < aaHeavyDefense {
+		return 1
+	}
+	return aaHeavyStrikeSize + aaStrikeSizePerSite*(aa-aaHeavyDefense)
+}
+
+// SquadAirTarget returns the best air target the squad's idle aircraft are
+// enough to strike, or nil. Targets under heavy AA are skipped until the
+// squad is large enough for them.
+func (e RuleEnv) SquadAirTarget(name string) *model.Enemy {
+	return e.bestAirTarget(e.SquadIdleCount(name))
+}
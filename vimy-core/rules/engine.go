@@ -3,49 +3,120 @@ package rules
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"math/rand"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/expr-lang/expr"
-	"github.com/expr-lang/expr/vm"
 	"github.com/nstehr/vimy/vimy-core/ipc"
 	"github.com/nstehr/vimy/vimy-core/model"
 )
 
 // RuleSummary is a read-only DTO for exposing compiled rules to the dashboard.
 type RuleSummary struct {
-	Name         string
-	Priority     int
-	Category     string
-	Exclusive    bool
-	ConditionSrc string
+	Name            string
+	Priority        int
+	Category        string
+	Group           string
+	Exclusive       bool
+	CooldownTicks   int
+	ActiveFromTick  int
+	ActiveUntilTick int
+	ConditionSrc    string
+	Quarantined     bool
+	EvalErrors      int // condition errors since the rule set was swapped in
 }
 
+// quarantineThreshold is how many panics a rule may raise before the engine
+// stops evaluating it. Counts reset when a new rule set is swapped in.
+const quarantineThreshold = 3
+
 // Engine runs compiled rules against game state each tick.
 // Rules fire in priority order; exclusive rules block lower-priority rules
 // in the same category, preventing conflicting orders on the same queue.
+// Category policies further cap each category's rules and commands per tick,
+// and a tick budget sheds low-priority categories when a tick runs long.
 type Engine struct {
-	mu      sync.RWMutex
-	rules   []*Rule
-	Memory  map[string]any
-	memMu   sync.Mutex // guards all reads/writes to Memory
-	Terrain *model.TerrainGrid
-	prefs   UnitPreferences
+	mu        sync.RWMutex
+	rules     []*Rule
+	base      []*Rule // rule set as compiled/swapped in, before overrides
+	overrides RuleOverrides
+	policies  CategoryPolicies
+	disabled  map[string]bool // rule groups switched off; replaced, never mutated, so Evaluate can hold it unlocked
+	budget    time.Duration   // per-tick evaluation deadline; 0 is none (see SetTickBudget)
+	workers   int             // condition evaluation pool size; 1 or less evaluates serially (see SetConditionWorkers)
+	Memory    MemoryStore
+	memMu     sync.Mutex // guards all reads/writes to Memory
+	units     *UnitStore // guarded by memMu; updated from each tick's state
+	Terrain   *model.TerrainGrid
+	prefs     UnitPreferences
+	factions  FactionPreferences // per-faction preference overrides
+	factPrefs UnitPreferences    // the current game's faction defaults, under prefs
+	doctrine  Doctrine
+	mod       *Mod
+	seed      int64
+	rng       *rand.Rand      // guarded by memMu; reseeded from seed on ResetMemory
+	unitLogs  *unitLogSampler // guarded by memMu; samples per-unit debug lines
+	commands  *commandLog     // the command audit; locked separately (see RecordCommand)
+
+	// Panic and error accounting, guarded by memMu (only touched during
+	// Evaluate and Swap).
+	panics      map[string]int            // rule name → panics since last swap
+	quarantined map[string]bool           // rule name → skipped until next swap
+	evalErrors  map[string]*RuleEvalError // rule name → condition errors since last swap
+
+	// Tick budget accounting, guarded by memMu.
+	overran       bool // the last tick ran past the budget
+	degraded      bool // the last tick shed low-priority rules
+	degradedTicks int
 }
 
 // NewEngine compiles all rule conditions into expr bytecode and sorts by priority.
 func NewEngine(rules []*Rule) (*Engine, error) {
-	compiled, err := compileRules(rules)
+	compiled, err := compileRules(applyOverrides(rules, nil))
 	if err != nil {
 		return nil, err
 	}
+	seed := time.Now().UnixNano()
 	return &Engine{
-		rules:  compiled,
-		Memory: make(map[string]any),
+		rules:       compiled,
+		base:        rules,
+		Memory:      make(MemoryStore),
+		units:       NewUnitStore(RA),
+		doctrine:    DefaultDoctrine(),
+		mod:         RA,
+		seed:        seed,
+		rng:         rand.New(rand.NewSource(seed)),
+		unitLogs:    newUnitLogSampler(),
+		commands:    newCommandLog(),
+		panics:      make(map[string]int),
+		quarantined: make(map[string]bool),
+		evalErrors:  make(map[string]*RuleEvalError),
 	}, nil
 }
 
+// SetSeed reseeds the engine's random source. Randomised choices (defense
+// placement) then repeat exactly for identical game states, and every game
+// started after ResetMemory replays the same sequence.
+func (e *Engine) SetSeed(seed int64) {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	e.seed = seed
+	e.rng = rand.New(rand.NewSource(seed))
+}
+
+// Seed returns the seed of the engine's random source. Engines not given one
+// via SetSeed are seeded from the clock; log this to reproduce a game.
+func (e *Engine) Seed() int64 {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	return e.seed
+}
+
 // Evaluate runs all rules against the current game state.
 func (e *Engine) Evaluate(gs model.GameState, faction string, conn *ipc.Connection) error {
 	e.mu.RLock()
@@ -55,38 +126,110 @@ func (e *Engine) Evaluate(gs model.GameState, faction string, conn *ipc.Connecti
 	e.memMu.Lock()
 	defer e.memMu.Unlock()
 
-	env := RuleEnv{State: gs, Faction: faction, Memory: e.Memory, Terrain: e.Terrain, Preferences: e.prefs}
+	e.mu.RLock()
+	doctrine, mod, policies, disabled, budget, workers := e.doctrine, e.mod, e.policies, e.disabled, e.budget, e.workers
+	prefs := e.prefs.orElse(e.factPrefs)
+	e.mu.RUnlock()
+	clock := newTickClock(budget, e.overran)
+
+	e.units.Update(gs.Units, getSquads(e.Memory), mod)
+	env := RuleEnv{State: gs, Faction: faction, Memory: e.Memory, Terrain: e.Terrain, Preferences: prefs, Doctrine: doctrine, Mod: mod, Units: e.units, rng: e.rng, unitLogs: e.unitLogs}
+	updateEnemyStructures(env)
+	updateEnemyHarvesters(env)
 	updateIntel(env)
+	updateEnemyTracks(env)
+	updateIncomingAttack(env)
+	updateRushAlert(env)
+	updateSiege(env)
+	updateThreatHeat(env)
+	updateCombatLedger(env)
+	updateEnemySilos(env)
+	updateEnemyNukeLaunch(env)
+	updateEnemyAA(env)
+	updatePriorityTargets(env)
 	updateBuiltRoles(env)
+	updateLostDefenses(env)
+	updateKeyBuildingHits(env)
+	updateGapWatch(env)
+	updateQueueWatch(env)
+	updateDeadlock(env)
+	updateHarvesterTracks(env)
+	updateFactoryExits(env)
+	updateUnitHistory(env)
+	updateParadrops(env)
 	updateSquads(env)
+	updateAttackRuns(env)
+	updateEscorts(env)
+	updatePatrols(env)
+	updateASW(env)
+	updateUnitHolds(env)
 	updateMinelayers(env)
 	designateScout(env)
+	updateCoverage(env)
 	logMilitaryDiagnostics(env)
 	logProductionDiagnostics(env)
+	reconcileQueues(env, conn.From(ipc.Origin{Rule: reconcileOrigin, Tick: gs.Tick}))
 	fired := make(map[string]bool) // category → exclusive rule already fired
+	ran := make(map[string]int)    // category → actions run this tick
+	sent := make(map[string]int)   // category → commands sent this tick
+	cooldowns := getRuleCooldowns(e.Memory)
+	lastRan := getRuleLastRan(e.Memory)
+	if len(policies) > 0 {
+		rules = orderTies(rules, policies, e.rng, lastRan)
+	}
+
+	var pre []conditionResult
+	if workers > 1 {
+		// Capped and sheddable categories are left to the loop, which
+		// stops evaluating them once they are spent or shed.
+		pre = precomputeConditions(rules, env, workers, func(r *Rule) bool {
+			return !r.Exclusive && !e.quarantined[r.Name] && !disabled[r.Group] && r.activeAt(gs.Tick) &&
+				!policies[r.Category].limited() && !clock.sheddable(r.Category)
+		})
+	}
 
 	anyFired := false
-	for _, r := range rules {
-		if fired[r.Category] {
+	for i, r := range rules {
+		if fired[r.Category] || e.quarantined[r.Name] || disabled[r.Group] || !r.activeAt(gs.Tick) {
 			continue
 		}
-
-		result, err := vm.Run(r.program, env)
+		if policies[r.Category].spent(ran[r.Category], sent[r.Category]) || clock.shed(r.Category) {
+			continue
+		}
+		env := env
+		env.rule = r
+
+		var match bool
+		var err error
+		if pre != nil && pre[i].done {
+			match, err = e.settleCondition(r, pre[i])
+		} else {
+			match, err = e.runCondition(r, env)
+		}
 		if err != nil {
-			slog.Warn("rule condition error", "rule", r.Name, "error", err)
+			e.recordEvalError(r, env, err)
 			continue
 		}
-
-		match, ok := result.(bool)
-		if !ok || !match {
+		if !match {
 			continue
 		}
 
 		anyFired = true
-		slog.Debug("rule fired", "rule", r.Name, "priority", r.Priority, "category", r.Category)
-
-		if err := r.Action(env, conn); err != nil {
-			slog.Error("rule action error", "rule", r.Name, "error", err)
+		if last, ok := cooldowns[r.Name]; ok && r.CooldownTicks > 0 && gs.Tick-last < r.CooldownTicks {
+			slog.Debug("rule cooling down", "rule", r.Name, "ticks_left", r.CooldownTicks-(gs.Tick-last))
+		} else {
+			slog.Debug("rule fired", "rule", r.Name, "priority", r.Priority, "category", r.Category)
+			before := conn.Sent()
+			origin := ipc.Origin{Rule: r.Name, Condition: r.ConditionSrc, Tick: gs.Tick}
+			if err := e.runAction(r, env, conn.From(origin)); err != nil {
+				slog.Error("rule action error", "rule", r.Name, "error", err)
+			}
+			ran[r.Category]++
+			sent[r.Category] += int(conn.Sent() - before)
+			lastRan[r.Name] = gs.Tick
+			if r.CooldownTicks > 0 {
+				cooldowns[r.Name] = gs.Tick
+			}
 		}
 
 		if r.Exclusive {
@@ -94,19 +237,94 @@ func (e *Engine) Evaluate(gs model.GameState, faction string, conn *ipc.Connecti
 		}
 	}
 
+	if len(cooldowns) > 0 {
+		ruleCooldownsMemory.set(e.Memory, cooldowns)
+	}
+	if len(lastRan) > 0 {
+		ruleLastRanMemory.set(e.Memory, lastRan)
+	}
+
 	if !anyFired {
 		logIdleDiagnostics(gs)
 	}
+	e.unitLogs.flush(gs.Tick)
+	e.finishTick(clock, gs.Tick)
 
 	return nil
 }
 
+// getRuleCooldowns returns the tick each cooldown rule last ran its action,
+// keyed by rule name. Kept in Memory so ResetMemory clears it between games.
+func getRuleCooldowns(memory map[string]any) map[string]int {
+	if v, ok := ruleCooldownsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]int)
+}
+
+// getRuleLastRan returns the tick each rule last ran its action, keyed by
+// rule name.
+func getRuleLastRan(memory map[string]any) map[string]int {
+	if v, ok := ruleLastRanMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]int)
+}
+
+// runCondition evaluates a rule's compiled condition, converting a panic in
+// any RuleEnv helper into an error so one bad rule can't kill the connection.
+func (e *Engine) runCondition(r *Rule, env RuleEnv) (bool, error) {
+	return e.settleCondition(r, evalCondition(r, env))
+}
+
+// runAction invokes a rule's action with the same panic protection as runCondition.
+func (e *Engine) runAction(r *Rule, env RuleEnv, conn *ipc.Connection) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = e.recordPanic(r, "action", p)
+		}
+	}()
+	return r.Action(env, conn)
+}
+
+// recordPanic counts a recovered panic against the rule and quarantines it
+// once it reaches quarantineThreshold. Caller must hold memMu.
+func (e *Engine) recordPanic(r *Rule, stage string, p any) error {
+	e.panics[r.Name]++
+	n := e.panics[r.Name]
+	if n >= quarantineThreshold && !e.quarantined[r.Name] {
+		e.quarantined[r.Name] = true
+		slog.Error("rule quarantined after repeated panics", "rule", r.Name, "panics", n, "quarantined", len(e.quarantined))
+	}
+	return fmt.Errorf("%s panic (%d/%d): %v", stage, n, quarantineThreshold, p)
+}
+
+// Quarantined returns the names of rules currently skipped due to repeated panics.
+func (e *Engine) Quarantined() []string {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	names := make([]string, 0, len(e.quarantined))
+	for name := range e.quarantined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Swap atomically replaces the rule set (called by the strategist when the LLM
-// generates a new doctrine). Compiles first; if compilation fails the old rules
-// remain active. Squads are cleared because the new rules may define different
-// squad names and sizes.
+// generates a new doctrine). Operator overrides are re-applied on top of the
+// new rules. Compiles first; if compilation fails the old rules remain active.
+// Squads are cleared (see MemoryStore.swapDoctrine) because the new rules may
+// define different squad names and sizes; panic counts, quarantines and condition errors are cleared
+// because the conditions and actions behind each rule name have been rebuilt.
+// The next Evaluate cancels queued items the new doctrine no longer wants
+// (see reconcileQueues).
 func (e *Engine) Swap(newRules []*Rule) error {
-	compiled, err := compileRules(newRules)
+	e.mu.RLock()
+	overrides := e.overrides
+	e.mu.RUnlock()
+
+	compiled, err := compileRules(applyOverrides(newRules, overrides))
 	if err != nil {
 		return err
 	}
@@ -116,15 +334,152 @@ func (e *Engine) Swap(newRules []*Rule) error {
 	}
 	e.mu.Lock()
 	e.rules = compiled
+	e.base = newRules
 	e.mu.Unlock()
 
 	e.memMu.Lock()
-	delete(e.Memory, "squads")
+	e.Memory.swapDoctrine()
+	queueReconcileMemory.set(e.Memory, true)
+	clear(e.panics)
+	clear(e.quarantined)
+	clear(e.evalErrors)
 	e.memMu.Unlock()
 	slog.Info("rule set swapped", "count", len(compiled), "rules", names)
 	return nil
 }
 
+// BaseRules returns the rule set last passed to NewEngine or Swap, before
+// operator overrides.
+func (e *Engine) BaseRules() []*Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.base
+}
+
+// SetOverrides replaces the operator rule overrides and re-derives the active
+// rule set from the last swapped-in rules. If an overridden condition fails to
+// compile, the previous overrides remain in effect. Squads are kept — the
+// underlying doctrine hasn't changed.
+func (e *Engine) SetOverrides(o RuleOverrides) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	compiled, err := compileRules(applyOverrides(e.base, o))
+	if err != nil {
+		return err
+	}
+	e.rules = compiled
+	e.overrides = o
+	slog.Info("rule overrides applied", "overrides", len(o), "rules", len(compiled))
+	return nil
+}
+
+// SetCategoryPolicies replaces the per-category limits and tie-breaks the
+// selection loop applies. They survive every Swap.
+func (e *Engine) SetCategoryPolicies(p CategoryPolicies) error {
+	if err := p.Check(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.policies = p
+	slog.Info("category policies applied", "categories", len(p))
+	return nil
+}
+
+// SetGroupEnabled switches a rule group on or off. A disabled group's rules
+// are skipped until it is enabled again, across Swaps, and the memory its
+// rules own is cleared so it starts afresh when re-enabled.
+func (e *Engine) SetGroupEnabled(name string, enabled bool) error {
+	if !slices.Contains(ruleGroups, name) {
+		return fmt.Errorf("unknown rule group %q (known: %s)", name, strings.Join(ruleGroups, ", "))
+	}
+	e.mu.Lock()
+	disabled := maps.Clone(e.disabled)
+	if disabled == nil {
+		disabled = make(map[string]bool)
+	}
+	if enabled {
+		delete(disabled, name)
+	} else {
+		disabled[name] = true
+	}
+	e.disabled = disabled
+	e.mu.Unlock()
+
+	if !enabled {
+		e.memMu.Lock()
+		for _, key := range groupMemory(name) {
+			delete(e.Memory, key)
+		}
+		e.memMu.Unlock()
+	}
+	slog.Info("rule group toggled", "group", name, "enabled", enabled)
+	return nil
+}
+
+// Groups returns every rule group, with how many of its rules the active
+// rule set holds and whether it is enabled.
+func (e *Engine) Groups() []GroupSummary {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := make([]GroupSummary, len(ruleGroups))
+	for i, name := range ruleGroups {
+		out[i] = GroupSummary{Name: name, Rules: len(RulesInGroup(e.rules, name)), Enabled: !e.disabled[name]}
+	}
+	return out
+}
+
+// CategoryPolicies returns the active per-category policies.
+func (e *Engine) CategoryPolicies() CategoryPolicies {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.policies
+}
+
+// Overrides returns the active operator rule overrides.
+func (e *Engine) Overrides() RuleOverrides {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.overrides
+}
+
+// ResetMemory discards all per-game memory (squads, intel, cooldowns) and
+// the command audit, and reseeds the random source. Called when a new game session starts on the
+// shared engine.
+func (e *Engine) ResetMemory() {
+	e.memMu.Lock()
+	clear(e.Memory)
+	e.units.reset()
+	e.rng = rand.New(rand.NewSource(e.seed))
+	e.unitLogs.reset()
+	e.memMu.Unlock()
+	e.commands.reset()
+}
+
+// SetPriorityTarget pins an enemy (by actor ID or type) as the preferred
+// attack target, e.g. from an operator directive.
+func (e *Engine) SetPriorityTarget(t PriorityTarget) {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	if addPriorityTarget(e.Memory, t) {
+		slog.Info("priority target set", "enemy", t.EnemyID, "type", t.Type, "reason", t.Reason)
+	}
+}
+
+// ClearPriorityTargets removes all priority targets.
+func (e *Engine) ClearPriorityTargets() {
+	e.memMu.Lock()
+	priorityTargetsMemory.clear(e.Memory)
+	e.memMu.Unlock()
+}
+
+// PriorityTargets returns a copy of the current priority targets.
+func (e *Engine) PriorityTargets() []PriorityTarget {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	return slices.Clone(getPriorityTargets(e.Memory))
+}
+
 // LockMemory acquires the memory mutex. Callers must pair with UnlockMemory.
 // Used by the strategist to safely read Memory from a background goroutine.
 func (e *Engine) LockMemory()   { e.memMu.Lock() }
@@ -136,14 +491,28 @@ func (e *Engine) Rules() []RuleSummary {
 	rules := e.rules
 	e.mu.RUnlock()
 
+	e.memMu.Lock()
+	quarantined := maps.Clone(e.quarantined)
+	evalErrors := make(map[string]int, len(e.evalErrors))
+	for name, rec := range e.evalErrors {
+		evalErrors[name] = rec.Count
+	}
+	e.memMu.Unlock()
+
 	out := make([]RuleSummary, len(rules))
 	for i, r := range rules {
 		out[i] = RuleSummary{
-			Name:         r.Name,
-			Priority:     r.Priority,
-			Category:     r.Category,
-			Exclusive:    r.Exclusive,
-			ConditionSrc: r.ConditionSrc,
+			Name:            r.Name,
+			Priority:        r.Priority,
+			Category:        r.Category,
+			Group:           r.Group,
+			Exclusive:       r.Exclusive,
+			CooldownTicks:   r.CooldownTicks,
+			ActiveFromTick:  r.ActiveFromTick,
+			ActiveUntilTick: r.ActiveUntilTick,
+			ConditionSrc:    r.ConditionSrc,
+			Quarantined:     quarantined[r.Name],
+			EvalErrors:      evalErrors[r.Name],
 		}
 	}
 	return out
@@ -157,6 +526,29 @@ func (e *Engine) SetTerrain(grid *model.TerrainGrid) {
 	slog.Info("terrain grid set", "cols", grid.Cols, "rows", grid.Rows, "cellW", grid.CellW, "cellH", grid.CellH)
 }
 
+// TerrainGrid returns the terrain grid from the hello handshake, or nil.
+func (e *Engine) TerrainGrid() *model.TerrainGrid {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Terrain
+}
+
+// SetMod selects the role table for the mod reported in the hello handshake.
+// Rule sets compiled afterwards should use CompileDoctrineForMod with Mod().
+func (e *Engine) SetMod(m *Mod) {
+	e.mu.Lock()
+	e.mod = m
+	e.mu.Unlock()
+	slog.Info("mod set", "mod", m.Name)
+}
+
+// Mod returns the mod the engine is playing.
+func (e *Engine) Mod() *Mod {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mod
+}
+
 // SetPreferences stores per-unit-type preferences from the LLM doctrine.
 func (e *Engine) SetPreferences(p UnitPreferences) {
 	e.mu.Lock()
@@ -164,6 +556,34 @@ func (e *Engine) SetPreferences(p UnitPreferences) {
 	e.mu.Unlock()
 }
 
+// SetFactionPreferences replaces the per-faction preference overrides used
+// by SetFaction.
+func (e *Engine) SetFactionPreferences(fp FactionPreferences) {
+	e.mu.Lock()
+	e.factions = fp
+	e.mu.Unlock()
+}
+
+// SetFaction seeds unit preferences for the faction reported in the hello
+// handshake. Doctrine preferences set via SetPreferences take precedence
+// category by category; categories the doctrine leaves empty use these.
+func (e *Engine) SetFaction(faction string) {
+	e.mu.Lock()
+	e.factPrefs = e.factions.For(faction)
+	p := e.factPrefs
+	e.mu.Unlock()
+	slog.Info("faction preferences set", "faction", faction, "side", FactionSide(faction),
+		"vehicle", p.Vehicle, "infantry", p.Infantry, "aircraft", p.Aircraft, "naval", p.Naval)
+}
+
+// SetDoctrine stores the active doctrine so conditions can read its weights
+// at evaluation time via DoctrineParam.
+func (e *Engine) SetDoctrine(d Doctrine) {
+	e.mu.Lock()
+	e.doctrine = d
+	e.mu.Unlock()
+}
+
 // logIdleDiagnostics helps debug "why isn't the AI doing anything?" —
 // dumps queue state when zero rules fire. Throttled to avoid log spam.
 var lastDiagTick int
@@ -253,7 +673,7 @@ func compileRules(rules []*Rule) ([]*Rule, error) {
 		}
 		r.program = prog
 	}
-	sort.Slice(rules, func(i, j int) bool {
+	sort.SliceStable(rules, func(i, j int) bool {
 		return rules[i].Priority > rules[j].Priority
 	})
 	return rules, nil
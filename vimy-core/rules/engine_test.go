@@ -1,8 +1,12 @@
 package rules
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/nstehr/vimy/vimy-core/fixtures"
+	"github.com/nstehr/vimy/vimy-core/ipc"
 	"github.com/nstehr/vimy/vimy-core/model"
 )
 
@@ -259,3 +263,302 @@ func TestBuildableType(t *testing.T) {
 		t.Errorf("BuildableType(nonexistent) = %q, want %q", got, "")
 	}
 }
+
+func TestRuleOverridesSurviveSwap(t *testing.T) {
+	engine, err := NewEngine(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	prio := 50
+	err = engine.SetOverrides(RuleOverrides{
+		"scout-with-idle-units": {Disabled: true},
+		"build-power":           {Priority: &prio, Thresholds: map[string]float64{"300": 150}},
+	})
+	if err != nil {
+		t.Fatalf("SetOverrides failed: %v", err)
+	}
+
+	check := func(stage string) {
+		t.Helper()
+		var power *RuleSummary
+		for _, r := range engine.Rules() {
+			if r.Name == "scout-with-idle-units" {
+				t.Errorf("%s: disabled rule still active", stage)
+			}
+			if r.Name == "build-power" {
+				r := r
+				power = &r
+			}
+		}
+		if power == nil {
+			t.Fatalf("%s: build-power missing", stage)
+		}
+		if power.Priority != 50 {
+			t.Errorf("%s: build-power priority = %d, want 50", stage, power.Priority)
+		}
+		if !strings.Contains(power.ConditionSrc, "Cash() >= 150") {
+			t.Errorf("%s: threshold not replaced: %s", stage, power.ConditionSrc)
+		}
+		if !strings.Contains(power.ConditionSrc, "PowerExcess() < 100") {
+			t.Errorf("%s: unrelated literal changed: %s", stage, power.ConditionSrc)
+		}
+	}
+	check("after SetOverrides")
+
+	if err := engine.Swap(DefaultRules()); err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	check("after Swap")
+
+	// Clearing overrides restores the swapped-in rules untouched.
+	if err := engine.SetOverrides(nil); err != nil {
+		t.Fatalf("SetOverrides(nil) failed: %v", err)
+	}
+	if got := len(engine.Rules()); got != 13 {
+		t.Errorf("expected 13 rules after clearing overrides, got %d", got)
+	}
+}
+
+func TestPanickingRuleIsQuarantined(t *testing.T) {
+	calls := 0
+	rules := []*Rule{
+		{
+			Name: "boom", Priority: 100, Category: "test", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				calls++
+				panic("boom")
+			},
+		},
+		{
+			Name: "steady", Priority: 50, Category: "other", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error { return nil },
+		},
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	for tick := 0; tick < quarantineThreshold+2; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	if calls != quarantineThreshold {
+		t.Errorf("panicking action ran %d times, want %d before quarantine", calls, quarantineThreshold)
+	}
+	if q := engine.Quarantined(); len(q) != 1 || q[0] != "boom" {
+		t.Errorf("Quarantined() = %v, want [boom]", q)
+	}
+
+	if err := engine.Swap(rules); err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if q := engine.Quarantined(); len(q) != 0 {
+		t.Errorf("quarantine should clear on swap, got %v", q)
+	}
+}
+
+func TestCheckOverridesFlagsNoOps(t *testing.T) {
+	problems := CheckOverrides(DefaultRules(), RuleOverrides{
+		"build-power":  {Thresholds: map[string]float64{"300": 150, "12345": 1}},
+		"no-such-rule": {Disabled: true},
+	})
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+	if !strings.Contains(problems[0], "12345") || !strings.Contains(problems[1], "no-such-rule") {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+}
+
+func TestSeededEngineReproducesDefenseHints(t *testing.T) {
+	gs := model.GameState{
+		Buildings: []model.Building{
+			{ID: 1, Type: "fact", X: 100, Y: 100},
+			{ID: 2, Type: "powr", X: 80, Y: 120},
+			{ID: 3, Type: "proc", X: 120, Y: 80},
+		},
+	}
+	hints := func(e *Engine) [][2]int {
+		env := RuleEnv{State: gs, Memory: e.Memory, rng: e.rng}
+		var out [][2]int
+		for range 10 {
+			x, y := defenseHint(env)
+			out = append(out, [2]int{x, y})
+		}
+		return out
+	}
+
+	a, err := NewEngine(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	b, err := NewEngine(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	a.SetSeed(42)
+	b.SetSeed(42)
+
+	first := hints(a)
+	if got := hints(b); !slices.Equal(got, first) {
+		t.Errorf("same seed gave different hints:\n%v\n%v", first, got)
+	}
+	a.ResetMemory()
+	if got := hints(a); !slices.Equal(got, first) {
+		t.Errorf("hints after ResetMemory = %v, want the seed's sequence %v", got, first)
+	}
+	if a.Seed() != 42 {
+		t.Errorf("Seed() = %d, want 42", a.Seed())
+	}
+}
+
+func TestCooldownThrottlesActionButHoldsCategory(t *testing.T) {
+	var throttled, blocked []int
+	var tick int
+	rules := []*Rule{
+		{
+			Name: "throttled", Priority: 100, Category: "test", Exclusive: true, CooldownTicks: 10, ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				throttled = append(throttled, tick)
+				return nil
+			},
+		},
+		{
+			Name: "below", Priority: 50, Category: "test", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				blocked = append(blocked, tick)
+				return nil
+			},
+		},
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	for tick = 0; tick < 25; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	if !slices.Equal(throttled, []int{0, 10, 20}) {
+		t.Errorf("throttled action ran at ticks %v, want [0 10 20]", throttled)
+	}
+	if len(blocked) != 0 {
+		t.Errorf("lower-priority rule ran at ticks %v while the exclusive rule cooled down", blocked)
+	}
+
+	// Operators can retune the cooldown; a new game starts it fresh.
+	cd := 2
+	if err := engine.SetOverrides(RuleOverrides{"throttled": {CooldownTicks: &cd}}); err != nil {
+		t.Fatalf("SetOverrides failed: %v", err)
+	}
+	engine.ResetMemory()
+	throttled = nil
+	for tick = 0; tick < 5; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	if !slices.Equal(throttled, []int{0, 2, 4}) {
+		t.Errorf("overridden cooldown ran at ticks %v, want [0 2 4]", throttled)
+	}
+}
+
+func TestRuleTickWindow(t *testing.T) {
+	var ran []int
+	var tick int
+	rules := []*Rule{{
+		Name: "windowed", Priority: 100, Category: "test", ConditionSrc: "true",
+		ActiveFromTick: 3, ActiveUntilTick: 5,
+		Action: func(env RuleEnv, conn *ipc.Connection) error {
+			ran = append(ran, tick)
+			return nil
+		},
+	}}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	for tick = 0; tick < 8; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	if !slices.Equal(ran, []int{3, 4, 5}) {
+		t.Errorf("windowed rule ran at ticks %v, want [3 4 5]", ran)
+	}
+}
+
+func TestConditionErrorsAreRecorded(t *testing.T) {
+	rules := []*Rule{{
+		Name: "bad-index", Priority: 100, Category: "test",
+		ConditionSrc: "State.Enemies[0].HP > 0",
+		Action:       func(env RuleEnv, conn *ipc.Connection) error { return nil },
+	}}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	for tick := 10; tick < 13; tick++ {
+		gs := model.GameState{Tick: tick, Player: model.Player{Cash: 500}}
+		if err := engine.Evaluate(gs, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	errs := engine.EvalErrors()
+	if len(errs) != 1 {
+		t.Fatalf("EvalErrors() = %+v, want one record", errs)
+	}
+	rec := errs[0]
+	if rec.Rule != "bad-index" || rec.Count != 3 || rec.FirstTick != 10 || rec.LastTick != 12 {
+		t.Errorf("record = %+v, want bad-index failing 3 times over ticks 10-12", rec)
+	}
+	if rec.Condition != rules[0].ConditionSrc || rec.LastError == "" || rec.Snapshot.Cash != 500 {
+		t.Errorf("record missing context: %+v", rec)
+	}
+	if got := engine.Rules()[0].EvalErrors; got != 3 {
+		t.Errorf("RuleSummary.EvalErrors = %d, want 3", got)
+	}
+
+	if err := engine.Swap(rules); err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if errs := engine.EvalErrors(); len(errs) != 0 {
+		t.Errorf("Swap should clear condition errors, got %+v", errs)
+	}
+}
+
+func TestDefaultRulesEvaluateCleanlyOnFixtureBattlefield(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	engine, err := NewEngine(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	engine.SetTerrain(fixtures.LandTerrain(fixtures.DefaultMapSize, fixtures.DefaultMapSize))
+	states := fixtures.NewStateBuilder().
+		WithBase().
+		WithArmy(10, "3tnk").
+		WithEnemyBase("enemy", 100, 20).
+		WithEnemyArmy("enemy", 4, "1tnk", 60, 20).
+		WithQueue("Building", "powr", "proc", "tsla").
+		WithQueue("Vehicle", "3tnk", "harv").
+		Ticks(20)
+	for _, gs := range states {
+		if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", gs.Tick, err)
+		}
+	}
+	if conn.Sent() == 0 {
+		t.Error("no commands sent for a base with an army facing an enemy")
+	}
+	for _, rec := range engine.EvalErrors() {
+		t.Errorf("rule %s condition failed %d times: %s", rec.Rule, rec.Count, rec.LastError)
+	}
+}
@@ -3,19 +3,21 @@ package rules
 import (
 	"log/slog"
 	"math"
+	"math/rand"
 	"slices"
 	"strings"
 
 	"github.com/nstehr/vimy/vimy-core/model"
 )
 
-// UnitPreferences holds per-category ordered role lists set by the LLM.
+// UnitPreferences holds per-category ordered role lists set by the LLM,
+// with gaps filled from the faction's defaults (see FactionPreferences).
 // BestBuildable* functions check these before falling back to hardcoded priority.
 type UnitPreferences struct {
-	Infantry []string
-	Vehicle  []string
-	Aircraft []string
-	Naval    []string
+	Infantry []string `json:"infantry,omitempty"`
+	Vehicle  []string `json:"vehicle,omitempty"`
+	Aircraft []string `json:"aircraft,omitempty"`
+	Naval    []string `json:"naval,omitempty"`
 }
 
 // RuleEnv is the expression evaluation context. All exported methods are
@@ -23,16 +25,53 @@ type UnitPreferences struct {
 type RuleEnv struct {
 	State       model.GameState
 	Faction     string
-	Memory      map[string]any
+	Memory      MemoryStore
 	Terrain     *model.TerrainGrid
 	Preferences UnitPreferences
+	Doctrine    Doctrine
+	Mod         *Mod       // role table for the mod being played; nil is RA
+	Units       *UnitStore // our units indexed by type, role, domain, idle state and squad; nil outside Evaluate
+
+	rng      *rand.Rand      // the engine's seeded source; unexported so conditions can't draw from it
+	rule     *Rule           // the rule being evaluated, for unit holds; nil outside Evaluate
+	unitLogs *unitLogSampler // samples per-unit debug lines (see unitDebug); nil outside Evaluate
+}
+
+// random returns the engine's seeded random source, or a throwaway source
+// seeded from the global one for envs built outside Evaluate (tests).
+func (e RuleEnv) random() *rand.Rand {
+	if e.rng != nil {
+		return e.rng
+	}
+	return rand.New(rand.NewSource(rand.Int63()))
+}
+
+// units returns the engine's unit store, or one indexed from State for envs
+// built outside Evaluate (tests).
+func (e RuleEnv) units() *UnitStore {
+	if e.Units != nil {
+		return e.Units
+	}
+	s := NewUnitStore(e.Mod)
+	s.Update(e.State.Units, getSquads(e.Memory), e.Mod)
+	return s
 }
 
-func (e RuleEnv) HasUnit(t string) bool      { return containsType(e.State.Units, t) }
+func (e RuleEnv) HasUnit(t string) bool      { return e.units().TypeCount(t) > 0 }
 func (e RuleEnv) HasBuilding(t string) bool   { return containsType(e.State.Buildings, t) }
-func (e RuleEnv) UnitCount(t string) int      { return countType(e.State.Units, t) }
+func (e RuleEnv) UnitCount(t string) int      { return e.units().TypeCount(t) }
 func (e RuleEnv) BuildingCount(t string) int  { return countType(e.State.Buildings, t) }
 
+// DoctrineParam returns the active doctrine's value for the named parameter,
+// using the same snake_case names as the doctrine JSON (e.g. "aggression",
+// "ground_attack_group_size"). Unknown names return 0.
+func (e RuleEnv) DoctrineParam(name string) float64 {
+	if p, ok := doctrineParams[name]; ok {
+		return p.get(e.Doctrine)
+	}
+	return 0
+}
+
 func (e RuleEnv) QueueBusy(q string) bool {
 	found := false
 	for _, pq := range e.State.ProductionQueues {
@@ -62,7 +101,7 @@ func (e RuleEnv) QueueReady(q string) bool {
 // where QueueBusy returns false (e.g. item at 100%, or a second queue is free)
 // but the role is already in production.
 func (e RuleEnv) QueueProducingRole(name string) bool {
-	r, ok := roles[name]
+	r, ok := e.Mod.role(name)
 	if !ok {
 		return false
 	}
@@ -107,10 +146,36 @@ func (e RuleEnv) PowerExcess() int {
 	return e.State.Player.PowerProvided - e.State.Player.PowerDrained
 }
 
+// ProjectedPowerExcess is PowerExcess once every queued building and defense
+// finishes (including ones ready but not yet placed). Gating on this keeps
+// the queues from stacking power-hungry structures that black out the base
+// the moment they land, and lets power plants take priority instead.
+func (e RuleEnv) ProjectedPowerExcess() int {
+	excess := e.PowerExcess()
+	for _, pq := range e.State.ProductionQueues {
+		if !strings.EqualFold(pq.Type, QueueBuilding) && !strings.EqualFold(pq.Type, QueueDefense) {
+			continue
+		}
+		items := pq.Items
+		if len(items) == 0 && pq.CurrentItem != "" {
+			items = []string{pq.CurrentItem}
+		}
+		for _, item := range items {
+			excess += powerOf(item)
+		}
+	}
+	return excess
+}
+
+// PowerAfter returns the projected power excess if item were also built.
+func (e RuleEnv) PowerAfter(item string) int {
+	return e.ProjectedPowerExcess() + powerOf(item)
+}
+
 func (e RuleEnv) IdleHarvesters() []model.Unit {
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, Harvester) {
+	for _, u := range e.idleUnits() {
+		if matchesType(u.Type, Harvester) {
 			out = append(out, u)
 		}
 	}
@@ -185,27 +250,45 @@ func (e RuleEnv) MapHasWater() bool {
 
 func (e RuleEnv) EnemiesVisible() bool { return len(e.State.Enemies) > 0 }
 
+// Veterancy adjustments. Elite units are worth pulling back sooner, and enemy
+// veterans are worth killing first.
+const (
+	veteranRetreatBonus = 0.10 // retreat HP threshold raised per veterancy level
+	veteranTargetBonus  = 0.25 // target value multiplier added per enemy veterancy level
+	maxRetreatThreshold = 0.90
+)
+
+// unitRetreatThreshold scales a base retreat threshold by the unit's veterancy.
+func unitRetreatThreshold(u model.Unit, base float64) float64 {
+	return math.Min(base+veteranRetreatBonus*float64(u.Veterancy), maxRetreatThreshold)
+}
+
+// veterancyValue scales an enemy's target value by its veterancy level.
+func veterancyValue(en *model.Enemy, val float64) float64 {
+	return val * (1 + veteranTargetBonus*float64(en.Veterancy))
+}
+
 // DamagedSquadUnits returns idle squad members below the given HP threshold.
 // Used by retreat rules to pull wounded units out of the fight.
 func (e RuleEnv) DamagedSquadUnits(hpThreshold float64) []model.Unit {
 	squadIDs := squadUnitIDSet(e.Memory)
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle || u.MaxHP == 0 {
+	for _, u := range e.idleUnits() {
+		if u.MaxHP == 0 {
 			continue
 		}
 		if !squadIDs[u.ID] {
 			continue
 		}
-		if float64(u.HP)/float64(u.MaxHP) < hpThreshold {
+		if float64(u.HP)/float64(u.MaxHP) < unitRetreatThreshold(u, hpThreshold) {
 			out = append(out, u)
 		}
 	}
 	return out
 }
 
-// DamagedCombatUnits returns all combat units below the HP threshold,
-// regardless of idle/squad status. Excludes harvesters, MCVs, rangers,
+// DamagedCombatUnits returns all combat units below the HP threshold
+// (raised for veterans), regardless of idle/squad status. Excludes harvesters, MCVs, rangers,
 // engineers, APCs, designated scouts (non-combat/utility). Skips units
 // already retreating.
 func (e RuleEnv) DamagedCombatUnits(hpThreshold float64) []model.Unit {
@@ -227,7 +310,7 @@ func (e RuleEnv) DamagedCombatUnits(hpThreshold float64) []model.Unit {
 		if _, isRetreating := retreating[u.ID]; isRetreating {
 			continue
 		}
-		if float64(u.HP)/float64(u.MaxHP) < hpThreshold {
+		if float64(u.HP)/float64(u.MaxHP) < unitRetreatThreshold(u, hpThreshold) {
 			out = append(out, u)
 		}
 	}
@@ -237,12 +320,51 @@ func (e RuleEnv) DamagedCombatUnits(hpThreshold float64) []model.Unit {
 // getRetreatingUnits returns the set of unit IDs currently retreating.
 // Values are the tick when the retreat started (used for timeout).
 func getRetreatingUnits(memory map[string]any) map[int]int {
-	if v, ok := memory["retreatingUnits"].(map[int]int); ok {
+	if v, ok := retreatingMemory.get(memory); ok {
 		return v
 	}
 	return nil
 }
 
+// doNotTask returns the units rules must not give new orders to: units
+// retreating for repair, which only the retreat and repair actions order
+// until ClearHealedUnits releases them, and units another rule is holding
+// (see holdUnits).
+func (e RuleEnv) doNotTask() map[int]bool {
+	retreating := getRetreatingUnits(e.Memory)
+	out := make(map[int]bool, len(retreating))
+	for id := range retreating {
+		out[id] = true
+	}
+	holds := getUnitHolds(e.Memory)
+	for id := range holds {
+		if e.heldElsewhere(holds, id) {
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// idleUnits returns idle units outside doNotTask — the pool every idle-unit
+// selector draws from, so wounded units aren't pulled back into a fight.
+func (e RuleEnv) idleUnits() []model.Unit {
+	return e.idleOf(e.units().Idle())
+}
+
+// idleOf returns those of units that are idle and outside doNotTask, in
+// the order given.
+func (e RuleEnv) idleOf(units []model.Unit) []model.Unit {
+	s := e.units()
+	skip := e.doNotTask()
+	var out []model.Unit
+	for _, u := range units {
+		if s.IsIdle(u.ID) && !skip[u.ID] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
 // HasRetreatingUnits returns true if any units are currently retreating.
 func (e RuleEnv) HasRetreatingUnits() bool {
 	return len(getRetreatingUnits(e.Memory)) > 0
@@ -326,14 +448,8 @@ func (e RuleEnv) OverextendedSquadMembers(name string, leashPct float64) []model
 	leashDist := math.Sqrt(mw*mw+mh*mh) * leashPct
 	leashSq := leashDist * leashDist
 
-	idleSet := make(map[int]bool)
-	unitMap := make(map[int]model.Unit)
-	for _, u := range e.State.Units {
-		unitMap[u.ID] = u
-		if u.Idle {
-			idleSet[u.ID] = true
-		}
-	}
+	idleSet := makeUnitIDSet(e.idleUnits())
+	unitMap := e.units().ByID()
 
 	var out []model.Unit
 	for _, id := range sq.UnitIDs {
@@ -359,10 +475,7 @@ func (e RuleEnv) SquadThreatRatio(name string, radiusPct float64) float64 {
 	if !ok || len(sq.UnitIDs) == 0 {
 		return 0
 	}
-	unitMap := make(map[int]model.Unit)
-	for _, u := range e.State.Units {
-		unitMap[u.ID] = u
-	}
+	unitMap := e.units().ByID()
 	sumX, sumY, squadHP, n := 0, 0, 0, 0
 	for _, id := range sq.UnitIDs {
 		if u, ok := unitMap[id]; ok {
@@ -440,10 +553,7 @@ func (e RuleEnv) HarvestersInDanger(dangerPct float64) []model.Unit {
 	threshSq := threshold * threshold
 
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !matchesType(u.Type, Harvester) {
-			continue
-		}
+	for _, u := range e.units().OfType(Harvester) {
 		for _, en := range e.State.Enemies {
 			dx := float64(u.X - en.X)
 			dy := float64(u.Y - en.Y)
@@ -480,20 +590,31 @@ func isNaval(u model.Unit) bool {
 	return false
 }
 
-// IdleGroundUnits returns idle land combat units — excludes economic units
-// (harvesters, MCVs) and other domains (aircraft, naval).
+// IdleGroundUnits returns idle land combat units no squad, scout, escort,
+// capture or patrol task owns — excludes economic units (harvesters, MCVs) and other
+// domains (aircraft, naval).
 func (e RuleEnv) IdleGroundUnits() []model.Unit {
-	scoutID := getScoutID(e.Memory)
+	return e.idleGroundUnits(e.unitOwners())
+}
+
+// AllIdleGroundUnits returns idle land combat units whatever task owns
+// them. Only base-defense emergencies and own-army support powers use it.
+func (e RuleEnv) AllIdleGroundUnits() []model.Unit {
+	return e.idleGroundUnits(nil)
+}
+
+// idleGroundUnits returns idle land combat units not in owners.
+func (e RuleEnv) idleGroundUnits(owners map[int]string) []model.Unit {
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle {
-			continue
-		}
+	for _, u := range e.idleUnits() {
 		if matchesType(u.Type, Harvester) || matchesType(u.Type, MCV) || matchesType(u.Type, Ranger) || matchesType(u.Type, Engineer) || matchesType(u.Type, APC) || matchesType(u.Type, Minelayer) {
 			continue
 		}
-		if scoutID != 0 && u.ID == scoutID {
-			continue // designated scout — handled by scouting rules
+		if matchesType(u.Type, Medic) {
+			continue // support unit — attached to squads separately
+		}
+		if owners[u.ID] != "" {
+			continue // tasked — only its owner gives it orders
 		}
 		if isAircraft(u) || isNaval(u) {
 			continue
@@ -506,22 +627,21 @@ func (e RuleEnv) IdleGroundUnits() []model.Unit {
 // NearBaseGroundUnits returns ground combat units near any building, regardless
 // of idle status. Used for emergency base defense so units with active orders
 // (e.g. en route to an attack) are recalled when the base is under attack.
-// Uses the same 20% map-diagonal threshold as BaseUnderAttack.
+// Uses the same base radius as BaseUnderAttack.
 func (e RuleEnv) NearBaseGroundUnits() []model.Unit {
 	if len(e.State.Buildings) == 0 {
 		return nil
 	}
-	mw := float64(e.State.MapWidth)
-	mh := float64(e.State.MapHeight)
-	threshold := math.Sqrt(mw*mw+mh*mh) * 0.20
+	threshold := e.baseThreatRadius()
 	threshSq := threshold * threshold
 
+	skip := e.doNotTask()
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if matchesType(u.Type, Harvester) || matchesType(u.Type, MCV) || matchesType(u.Type, Engineer) || matchesType(u.Type, APC) {
-			continue
+	for _, u := range e.units().InDomain(domainGround) {
+		if skip[u.ID] {
+			continue // wounded and retreating — don't drag back into the fight
 		}
-		if isAircraft(u) || isNaval(u) {
+		if matchesType(u.Type, Harvester) || matchesType(u.Type, MCV) || matchesType(u.Type, Engineer) || matchesType(u.Type, APC) {
 			continue
 		}
 		for j := range e.State.Buildings {
@@ -537,52 +657,28 @@ func (e RuleEnv) NearBaseGroundUnits() []model.Unit {
 }
 
 func (e RuleEnv) IdleNavalUnits() []model.Unit {
-	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle {
-			continue
-		}
-		if isNaval(u) {
-			out = append(out, u)
-		}
-	}
-	return out
+	return e.idleOf(e.units().InDomain(domainNaval))
 }
 
 func (e RuleEnv) IdleCombatAircraft() []model.Unit {
-	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle {
-			continue
-		}
-		for _, r := range combatAircraftRoles {
-			role := roles[r]
-			for _, t := range role.types {
-				if matchesType(u.Type, t) {
-					out = append(out, u)
-					goto next
-				}
-			}
+	s := e.units()
+	ids := make(map[int]bool)
+	for _, r := range combatAircraftRoles {
+		for id := range s.byRole[r] {
+			ids[id] = true
 		}
-	next:
 	}
-	return out
+	return e.idleOf(s.list("combat-aircraft", ids))
 }
 
 func (e RuleEnv) IdleAPCs() []model.Unit {
-	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, APC) {
-			out = append(out, u)
-		}
-	}
-	return out
+	return e.idleOf(e.units().OfType(APC))
 }
 
 func (e RuleEnv) IdleLoadedAPCs() []model.Unit {
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, APC) && u.CargoCount > 0 {
+	for _, u := range e.IdleAPCs() {
+		if u.CargoCount > 0 {
 			out = append(out, u)
 		}
 	}
@@ -591,8 +687,8 @@ func (e RuleEnv) IdleLoadedAPCs() []model.Unit {
 
 func (e RuleEnv) IdleEmptyAPCs() []model.Unit {
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, APC) && u.CargoCount == 0 {
+	for _, u := range e.IdleAPCs() {
+		if u.CargoCount == 0 {
 			out = append(out, u)
 		}
 	}
@@ -600,18 +696,12 @@ func (e RuleEnv) IdleEmptyAPCs() []model.Unit {
 }
 
 func (e RuleEnv) IdleRangers() []model.Unit {
-	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, Ranger) {
-			out = append(out, u)
-		}
-	}
-	return out
+	return e.idleOf(e.units().OfType(Ranger))
 }
 
 // getScoutID returns the designated scout light tank ID (0 = none).
 func getScoutID(memory map[string]any) int {
-	if v, ok := memory["scoutUnitID"].(int); ok {
+	if v, ok := scoutIDMemory.get(memory); ok {
 		return v
 	}
 	return 0
@@ -619,22 +709,14 @@ func getScoutID(memory map[string]any) int {
 
 // HasScout returns true if a scout unit (ranger or designated light tank) exists.
 func (e RuleEnv) HasScout() bool {
-	for _, u := range e.State.Units {
-		if matchesType(u.Type, Ranger) {
-			return true
-		}
-	}
-	return getScoutID(e.Memory) != 0
+	return e.units().TypeCount(Ranger) > 0 || getScoutID(e.Memory) != 0
 }
 
 // IdleScouts returns idle rangers plus the designated scout light tank (if idle).
 func (e RuleEnv) IdleScouts() []model.Unit {
 	scoutID := getScoutID(e.Memory)
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle {
-			continue
-		}
+	for _, u := range e.idleUnits() {
 		if matchesType(u.Type, Ranger) {
 			out = append(out, u)
 		} else if scoutID != 0 && u.ID == scoutID {
@@ -649,8 +731,8 @@ func (e RuleEnv) IdleScouts() []model.Unit {
 func (e RuleEnv) IdleMinelayers() []model.Unit {
 	assigned := getMinelayerAssignments(e.Memory)
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, Minelayer) && !assigned[u.ID] {
+	for _, u := range e.idleUnits() {
+		if matchesType(u.Type, Minelayer) && !assigned[u.ID] {
 			out = append(out, u)
 		}
 	}
@@ -658,7 +740,7 @@ func (e RuleEnv) IdleMinelayers() []model.Unit {
 }
 
 func getMinelayerAssignments(memory map[string]any) map[int]bool {
-	if v, ok := memory["minelayerAssigned"].(map[int]bool); ok {
+	if v, ok := minelayersMemory.get(memory); ok {
 		return v
 	}
 	return make(map[int]bool)
@@ -671,26 +753,22 @@ func designateScout(env RuleEnv) {
 	scoutID := getScoutID(env.Memory)
 	if scoutID != 0 {
 		// Check if the designated scout is still alive.
-		for _, u := range env.State.Units {
-			if u.ID == scoutID {
-				return // still alive, keep designation
-			}
+		if env.units().Has(scoutID) {
+			return // still alive, keep designation
 		}
 		// Scout died — clear designation.
-		delete(env.Memory, "scoutUnitID")
+		scoutIDMemory.clear(env.Memory)
 		scoutID = 0
 	}
 	// If we have rangers, no need for a scout light tank.
-	for _, u := range env.State.Units {
-		if matchesType(u.Type, Ranger) {
-			return
-		}
-	}
-	// Designate the first idle, unassigned light tank.
-	assigned := squadUnitIDSet(env.Memory)
-	for _, u := range env.State.Units {
-		if u.Idle && matchesType(u.Type, LightTank) && !assigned[u.ID] {
-			env.Memory["scoutUnitID"] = u.ID
+	if env.units().TypeCount(Ranger) > 0 {
+		return
+	}
+	// Designate the first idle, unowned light tank.
+	owners := env.unitOwners()
+	for _, u := range env.units().OfType(LightTank) {
+		if u.Idle && owners[u.ID] == "" {
+			scoutIDMemory.set(env.Memory, u.ID)
 			slog.Debug("designated scout light tank", "id", u.ID)
 			return
 		}
@@ -799,46 +877,35 @@ var airTargetValue = map[string]float64{
 const airTargetValueDefault = 1.0 // mobile units / unknown types
 
 // BestAirTarget picks the highest-value enemy for air strikes, using distance
-// as a decay factor. Scoring: val * hpBonus / sqrt(dist).
+// as a decay factor. A visible priority target overrides scoring.
+// Scoring: val * hpBonus / sqrt(dist) * aaRisk.
 // val = type value from airTargetValue (dominant factor)
 // hpBonus = 2.0 - hpRatio — gentle tiebreaker favoring damaged targets
 // 1/sqrt(dist) = inverse distance to own base
+// aaRisk = 1 / (1 + 0.5 × enemy AA covering the target)
+// Use SquadAirTarget to also skip targets too well defended for a squad.
 func (e RuleEnv) BestAirTarget() *model.Enemy {
+	return e.bestAirTarget(math.MaxInt)
+}
+
+// bestAirTarget is BestAirTarget restricted to targets a strike of
+// strikeSize aircraft may take on (see airStrikeSizeFor).
+func (e RuleEnv) bestAirTarget(strikeSize int) *model.Enemy {
 	if len(e.State.Enemies) == 0 {
 		return nil
 	}
-	bx, by := 0, 0
-	if len(e.State.Buildings) > 0 {
-		bx = e.State.Buildings[0].X
-		by = e.State.Buildings[0].Y
+	if target := e.priorityTargetOverride(); target != nil {
+		return target
 	}
+	bx, by := e.targetOrigin()
 	var best *model.Enemy
 	bestScore := -1.0
 	for i := range e.State.Enemies {
 		en := &e.State.Enemies[i]
-		if en.MaxHP == 0 {
+		if en.MaxHP == 0 || e.airStrikeSizeFor(en) > strikeSize {
 			continue
 		}
-		// Strip faction suffix (e.g. "afld.ukraine" → "afld").
-		base := strings.ToLower(en.Type)
-		if idx := strings.IndexByte(base, '.'); idx >= 0 {
-			base = base[:idx]
-		}
-		val := airTargetValue[base]
-		if val == 0 {
-			val = airTargetValueDefault
-		}
-		hpRatio := float64(en.HP) / float64(en.MaxHP)
-		hpBonus := 2.0 - hpRatio // 1.0 (full HP) to 2.0 (near-death)
-
-		dx := float64(en.X - bx)
-		dy := float64(en.Y - by)
-		dist := math.Sqrt(dx*dx + dy*dy)
-		if dist < 1 {
-			dist = 1
-		}
-		score := val * hpBonus / math.Sqrt(dist)
-		if score > bestScore {
+		if score := airTargetScore(en, bx, by) * e.aaRiskFactor(en); score > bestScore {
 			bestScore = score
 			best = en
 		}
@@ -846,6 +913,39 @@ func (e RuleEnv) BestAirTarget() *model.Enemy {
 	return best
 }
 
+// targetOrigin is the point attack target distances are measured from: our
+// first building, or the map origin with no base.
+func (e RuleEnv) targetOrigin() (int, int) {
+	if len(e.State.Buildings) > 0 {
+		return e.State.Buildings[0].X, e.State.Buildings[0].Y
+	}
+	return 0, 0
+}
+
+// airTargetScore scores an enemy for BestAirTarget, measured from (bx, by).
+func airTargetScore(en *model.Enemy, bx, by int) float64 {
+	// Strip faction suffix (e.g. "afld.ukraine" → "afld").
+	base := strings.ToLower(en.Type)
+	if idx := strings.IndexByte(base, '.'); idx >= 0 {
+		base = base[:idx]
+	}
+	val := airTargetValue[base]
+	if val == 0 {
+		val = airTargetValueDefault
+	}
+	val = veterancyValue(en, val)
+	hpRatio := float64(en.HP) / float64(en.MaxHP)
+	hpBonus := 2.0 - hpRatio // 1.0 (full HP) to 2.0 (near-death)
+
+	dx := float64(en.X - bx)
+	dy := float64(en.Y - by)
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist < 1 {
+		dist = 1
+	}
+	return val * hpBonus / math.Sqrt(dist)
+}
+
 // groundTargetValue assigns a strategic value to enemy types for ground attacks.
 // Active base defenses score highest because they're actively killing our ground
 // units. AA defenses and naval buildings score low — not threatening to ground forces.
@@ -869,7 +969,8 @@ var groundTargetValue = map[string]float64{
 const groundTargetValueDefault = 1.0 // mobile units / unknown types
 
 // BestGroundTarget picks the highest-value enemy for ground attacks, using distance
-// as a decay factor. Scoring: val * hpBonus / dist.
+// as a decay factor. A visible priority target overrides scoring; a building an
+// engineer is on its way to capture is skipped. Scoring: val * hpBonus / dist.
 // val = type value from groundTargetValue (dominant factor)
 // hpBonus = 2.0 - hpRatio — gentle tiebreaker favoring damaged targets
 // 1/dist = stronger distance decay than air (ground units travel slowly)
@@ -877,38 +978,18 @@ func (e RuleEnv) BestGroundTarget() *model.Enemy {
 	if len(e.State.Enemies) == 0 {
 		return nil
 	}
-	bx, by := 0, 0
-	if len(e.State.Buildings) > 0 {
-		bx = e.State.Buildings[0].X
-		by = e.State.Buildings[0].Y
+	if target := e.priorityTargetOverride(); target != nil {
+		return target
 	}
+	bx, by := e.targetOrigin()
 	var best *model.Enemy
 	bestScore := -1.0
 	for i := range e.State.Enemies {
 		en := &e.State.Enemies[i]
-		if en.MaxHP == 0 {
+		if en.MaxHP == 0 || e.heldForCapture(en.ID) {
 			continue
 		}
-		// Strip faction suffix (e.g. "afld.ukraine" → "afld").
-		base := strings.ToLower(en.Type)
-		if idx := strings.IndexByte(base, '.'); idx >= 0 {
-			base = base[:idx]
-		}
-		val := groundTargetValue[base]
-		if val == 0 {
-			val = groundTargetValueDefault
-		}
-		hpRatio := float64(en.HP) / float64(en.MaxHP)
-		hpBonus := 2.0 - hpRatio // 1.0 (full HP) to 2.0 (near-death)
-
-		dx := float64(en.X - bx)
-		dy := float64(en.Y - by)
-		dist := math.Sqrt(dx*dx + dy*dy)
-		if dist < 1 {
-			dist = 1
-		}
-		score := val * hpBonus / dist
-		if score > bestScore {
+		if score := groundTargetScore(en, bx, by); score > bestScore {
 			bestScore = score
 			best = en
 		}
@@ -916,14 +997,36 @@ func (e RuleEnv) BestGroundTarget() *model.Enemy {
 	return best
 }
 
+// groundTargetScore scores an enemy for BestGroundTarget, measured from
+// (bx, by).
+func groundTargetScore(en *model.Enemy, bx, by int) float64 {
+	// Strip faction suffix (e.g. "afld.ukraine" → "afld").
+	base := strings.ToLower(en.Type)
+	if idx := strings.IndexByte(base, '.'); idx >= 0 {
+		base = base[:idx]
+	}
+	val := groundTargetValue[base]
+	if val == 0 {
+		val = groundTargetValueDefault
+	}
+	val = veterancyValue(en, val)
+	hpRatio := float64(en.HP) / float64(en.MaxHP)
+	hpBonus := 2.0 - hpRatio // 1.0 (full HP) to 2.0 (near-death)
+
+	dx := float64(en.X - bx)
+	dy := float64(en.Y - by)
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist < 1 {
+		dist = 1
+	}
+	return val * hpBonus / dist
+}
+
 // IdleCombatInfantry returns idle infantry excluding engineers (which have
 // their own capture workflow). Used for transport assault loading.
 func (e RuleEnv) IdleCombatInfantry() []model.Unit {
 	var out []model.Unit
-	for _, u := range e.State.Units {
-		if !u.Idle {
-			continue
-		}
+	for _, u := range e.idleUnits() {
 		if isInfantry(u) && !matchesType(u.Type, Engineer) {
 			out = append(out, u)
 		}
@@ -932,13 +1035,7 @@ func (e RuleEnv) IdleCombatInfantry() []model.Unit {
 }
 
 func (e RuleEnv) IdleEngineers() []model.Unit {
-	var out []model.Unit
-	for _, u := range e.State.Units {
-		if u.Idle && matchesType(u.Type, Engineer) {
-			out = append(out, u)
-		}
-	}
-	return out
+	return e.idleOf(e.units().OfType(Engineer))
 }
 
 func (e RuleEnv) SupportPowerReady(key string) bool {
@@ -959,10 +1056,10 @@ func (e RuleEnv) HasSupportPower(key string) bool {
 	return false
 }
 
-// GroundUnitCentroid returns where idle ground units are clustered.
-// Used to target iron curtain on our own forces.
+// GroundUnitCentroid returns where idle ground units, squads included, are
+// clustered. Used to target iron curtain on our own forces.
 func (e RuleEnv) GroundUnitCentroid() (int, int) {
-	idle := e.IdleGroundUnits()
+	idle := e.AllIdleGroundUnits()
 	if len(idle) > 0 {
 		sumX, sumY := 0, 0
 		for _, u := range idle {
@@ -1024,16 +1121,11 @@ func (e RuleEnv) SquadReadyRatio(name string) float64 {
 	if !ok || len(sq.UnitIDs) == 0 {
 		return 0
 	}
-	idleSet := make(map[int]bool)
-	for _, u := range e.State.Units {
-		if u.Idle {
-			idleSet[u.ID] = true
-		}
-	}
-	retreating := getRetreatingUnits(e.Memory)
+	idleSet := makeUnitIDSet(e.idleUnits())
+	skip := e.doNotTask()
 	idle, available := 0, 0
 	for _, id := range sq.UnitIDs {
-		if _, isRetreating := retreating[id]; isRetreating {
+		if skip[id] {
 			continue
 		}
 		available++
@@ -1053,17 +1145,10 @@ func (e RuleEnv) SquadIdleCount(name string) int {
 	if !ok {
 		return 0
 	}
-	idleSet := make(map[int]bool)
-	for _, u := range e.State.Units {
-		if u.Idle {
-			idleSet[u.ID] = true
-		}
-	}
-	retreating := getRetreatingUnits(e.Memory)
+	idleSet := makeUnitIDSet(e.idleUnits())
 	n := 0
 	for _, id := range sq.UnitIDs {
-		_, isRetreating := retreating[id]
-		if idleSet[id] && !isRetreating {
+		if idleSet[id] {
 			n++
 		}
 	}
@@ -1079,10 +1164,7 @@ func (e RuleEnv) SquadAwayFromBase(name string, radiusPct float64) bool {
 	if !ok || len(sq.UnitIDs) == 0 {
 		return false
 	}
-	unitMap := make(map[int]model.Unit)
-	for _, u := range e.State.Units {
-		unitMap[u.ID] = u
-	}
+	unitMap := e.units().ByID()
 	sumX, sumY, n := 0, 0, 0
 	for _, id := range sq.UnitIDs {
 		if u, ok := unitMap[id]; ok {
@@ -1104,15 +1186,10 @@ func (e RuleEnv) SquadAwayFromBase(name string, radiusPct float64) bool {
 	return dx*dx+dy*dy > radius*radius
 }
 
+// UnassignedIdleGround is IdleGroundUnits, which already leaves out squad
+// members; squad formation rules use this name.
 func (e RuleEnv) UnassignedIdleGround() []model.Unit {
-	assigned := squadUnitIDSet(e.Memory)
-	var out []model.Unit
-	for _, u := range e.IdleGroundUnits() {
-		if !assigned[u.ID] {
-			out = append(out, u)
-		}
-	}
-	return out
+	return e.IdleGroundUnits()
 }
 
 func (e RuleEnv) UnassignedIdleAir() []model.Unit {
@@ -1127,10 +1204,10 @@ func (e RuleEnv) UnassignedIdleAir() []model.Unit {
 }
 
 func (e RuleEnv) UnassignedIdleNaval() []model.Unit {
-	assigned := squadUnitIDSet(e.Memory)
+	owners := e.unitOwners()
 	var out []model.Unit
 	for _, u := range e.IdleNavalUnits() {
-		if !assigned[u.ID] {
+		if _, owned := owners[u.ID]; !owned {
 			out = append(out, u)
 		}
 	}
@@ -1139,17 +1216,17 @@ func (e RuleEnv) UnassignedIdleNaval() []model.Unit {
 
 // recordSuperweaponFire tracks launches so the strategist LLM can see fire history.
 func recordSuperweaponFire(env RuleEnv, key string) {
-	fires, _ := env.Memory["superweaponFires"].(map[string]int)
+	fires, _ := superweaponFiresMemory.get(env.Memory)
 	if fires == nil {
 		fires = make(map[string]int)
 	}
 	fires[key]++
-	env.Memory["superweaponFires"] = fires
+	superweaponFiresMemory.set(env.Memory, fires)
 }
 
 // GetSuperweaponFires returns cumulative fire counts (used by strategist summarizer).
 func GetSuperweaponFires(memory map[string]any) map[string]int {
-	if v, ok := memory["superweaponFires"].(map[string]int); ok {
+	if v, ok := superweaponFiresMemory.get(memory); ok {
 		return v
 	}
 	return nil
@@ -1167,7 +1244,7 @@ var rebuildableRoles = []string{
 // destruction. Without this, the AI wouldn't know to rebuild something
 // it once had.
 func updateBuiltRoles(env RuleEnv) {
-	builtRoles, _ := env.Memory["builtRoles"].(map[string]bool)
+	builtRoles, _ := builtRolesMemory.get(env.Memory)
 	if builtRoles == nil {
 		builtRoles = make(map[string]bool)
 	}
@@ -1176,12 +1253,12 @@ func updateBuiltRoles(env RuleEnv) {
 			builtRoles[name] = true
 		}
 	}
-	env.Memory["builtRoles"] = builtRoles
+	builtRolesMemory.set(env.Memory, builtRoles)
 }
 
 // LostRole detects destruction: true if we had this building before but don't now.
 func (e RuleEnv) LostRole(name string) bool {
-	builtRoles, _ := e.Memory["builtRoles"].(map[string]bool)
+	builtRoles, _ := builtRolesMemory.get(e.Memory)
 	return builtRoles[name] && !e.HasRole(name)
 }
 
@@ -1221,6 +1298,82 @@ func IsKnownBuildingType(t string) bool {
 	return knownBuildingTypes[base]
 }
 
+// Intel expiry. Base intel is trusted only so long: a base nobody has
+// seen in intelExpiryTicks is dropped (intelUnitExpiryTicks for intel
+// seeded from passing units), and one our units reach and find empty is
+// dropped at once, so the army scouts for the enemy again instead of
+// cycling the hunt ring over ruins.
+const (
+	intelClearRadius     = 10   // cells
+	intelMinAge          = 300  // ticks before intel is eligible for clearing
+	intelExpiryTicks     = 6000 // ticks without a building sighting before intel expires
+	intelUnitExpiryTicks = 1500 // same, for intel seeded from unit sightings
+)
+
+// expireIntel drops stale and disproven entries from bases. Intel whose
+// position our units have reached with no enemy in sight is cleared, unless
+// we remember standing buildings of that owner elsewhere, in which case it
+// moves to them.
+func expireIntel(env RuleEnv, bases map[string]EnemyBaseIntel) {
+	intelClearRadiusSq := intelClearRadius * intelClearRadius
+	for owner, intel := range bases {
+		age := env.State.Tick - intel.Tick
+		expiry := intelExpiryTicks
+		if !intel.FromBuildings {
+			expiry = intelUnitExpiryTicks
+		}
+		if age >= expiry {
+			slog.Info("expiring stale enemy base intel", "owner", owner, "x", intel.X, "y", intel.Y, "age", age)
+			delete(bases, owner)
+			continue
+		}
+		if age < intelMinAge {
+			continue // too fresh — could be behind fog of war
+		}
+		// Check: any of our units near this intel position?
+		ourUnitNearby := false
+		for _, u := range env.State.Units {
+			dx := u.X - intel.X
+			dy := u.Y - intel.Y
+			if dx*dx+dy*dy < intelClearRadiusSq {
+				ourUnitNearby = true
+				break
+			}
+		}
+		if !ourUnitNearby {
+			continue
+		}
+		// Our units are there — check if any enemies visible nearby.
+		enemyNearby := false
+		for _, e := range env.State.Enemies {
+			dx := e.X - intel.X
+			dy := e.Y - intel.Y
+			if dx*dx+dy*dy < intelClearRadiusSq {
+				enemyNearby = true
+				break
+			}
+		}
+		if enemyNearby {
+			continue
+		}
+		if standing := env.KnownEnemyStructures(owner); len(standing) > 0 {
+			sx, sy, last := 0, 0, 0
+			for _, st := range standing {
+				sx += st.X
+				sy += st.Y
+				last = max(last, st.LastSeen)
+			}
+			intel.X, intel.Y = sx/len(standing), sy/len(standing)
+			intel.Tick, intel.FromBuildings = last, true
+			slog.Info("moving enemy base intel to remembered buildings", "owner", owner, "x", intel.X, "y", intel.Y, "buildings", len(standing))
+			bases[owner] = intel
+			continue
+		}
+		slog.Info("clearing stale enemy base intel", "owner", owner, "x", intel.X, "y", intel.Y)
+		delete(bases, owner)
+	}
+}
+
 // updateIntel maintains a map of known enemy base positions. Building sightings
 // always update (high confidence); unit sightings only seed initial intel to
 // avoid overwriting a known base location with a roaming attack force.
@@ -1253,7 +1406,7 @@ func updateIntel(env RuleEnv) {
 		}
 	}
 
-	bases := getEnemyBases(env.Memory)
+	bases := GetEnemyBases(env.Memory)
 
 	// Building sightings always overwrite — structures don't move.
 	for owner, a := range buildingsByOwner {
@@ -1281,51 +1434,8 @@ func updateIntel(env RuleEnv) {
 		}
 	}
 
-	// Clear stale intel: when our units are near a known enemy base position
-	// and no enemies are visible nearby, the base has been scouted and cleared.
-	// Only consider intel older than 300 ticks to avoid clearing fresh sightings
-	// that might just be behind fog of war momentarily.
-	const intelClearRadius = 10  // cells
-	const intelMinAge      = 300 // ticks before intel is eligible for clearing
-	intelClearRadiusSq := intelClearRadius * intelClearRadius
-
-	for owner, intel := range bases {
-		if !intel.FromBuildings {
-			continue // only clear high-confidence intel
-		}
-		if env.State.Tick-intel.Tick < intelMinAge {
-			continue // too fresh — could be behind fog of war
-		}
-		// Check: any of our units near this intel position?
-		ourUnitNearby := false
-		for _, u := range env.State.Units {
-			dx := u.X - intel.X
-			dy := u.Y - intel.Y
-			if dx*dx+dy*dy < intelClearRadiusSq {
-				ourUnitNearby = true
-				break
-			}
-		}
-		if !ourUnitNearby {
-			continue
-		}
-		// Our units are there — check if any enemies visible nearby.
-		enemyNearby := false
-		for _, e := range env.State.Enemies {
-			dx := e.X - intel.X
-			dy := e.Y - intel.Y
-			if dx*dx+dy*dy < intelClearRadiusSq {
-				enemyNearby = true
-				break
-			}
-		}
-		if !enemyNearby {
-			slog.Info("clearing stale enemy base intel", "owner", owner, "x", intel.X, "y", intel.Y)
-			delete(bases, owner)
-		}
-	}
-
-	env.Memory["enemyBases"] = bases
+	expireIntel(env, bases)
+	enemyBasesMemory.set(env.Memory, bases)
 
 	// Accumulate historical enemy sightings.
 	// Units: deduplicate by ID — each unit is unique.
@@ -1352,14 +1462,14 @@ func updateIntel(env RuleEnv) {
 			buildingsSeen[t] = c
 		}
 	}
-	env.Memory["enemySeenIDs"] = seenIDs
-	env.Memory["enemyUnitsSeen"] = unitsSeen
-	env.Memory["enemyBuildingsSeen"] = buildingsSeen
+	enemySeenIDsMemory.set(env.Memory, seenIDs)
+	enemyUnitsSeenMemory.set(env.Memory, unitsSeen)
+	enemyBuildingsSeenMemory.set(env.Memory, buildingsSeen)
 }
 
 // GetEnemyUnitsSeen returns the cumulative count of enemy units observed by type.
 func GetEnemyUnitsSeen(memory map[string]any) map[string]int {
-	if v, ok := memory["enemyUnitsSeen"].(map[string]int); ok {
+	if v, ok := enemyUnitsSeenMemory.get(memory); ok {
 		return v
 	}
 	return make(map[string]int)
@@ -1367,21 +1477,22 @@ func GetEnemyUnitsSeen(memory map[string]any) map[string]int {
 
 // GetEnemyBuildingsSeen returns the cumulative count of enemy buildings observed by type.
 func GetEnemyBuildingsSeen(memory map[string]any) map[string]int {
-	if v, ok := memory["enemyBuildingsSeen"].(map[string]int); ok {
+	if v, ok := enemyBuildingsSeenMemory.get(memory); ok {
 		return v
 	}
 	return make(map[string]int)
 }
 
 func getEnemySeenIDs(memory map[string]any) map[int]bool {
-	if v, ok := memory["enemySeenIDs"].(map[int]bool); ok {
+	if v, ok := enemySeenIDsMemory.get(memory); ok {
 		return v
 	}
 	return make(map[int]bool)
 }
 
-func getEnemyBases(memory map[string]any) map[string]EnemyBaseIntel {
-	if v, ok := memory["enemyBases"].(map[string]EnemyBaseIntel); ok {
+// GetEnemyBases returns the known enemy bases keyed by owner.
+func GetEnemyBases(memory map[string]any) map[string]EnemyBaseIntel {
+	if v, ok := enemyBasesMemory.get(memory); ok {
 		return v
 	}
 	return make(map[string]EnemyBaseIntel)
@@ -1390,7 +1501,7 @@ func getEnemyBases(memory map[string]any) map[string]EnemyBaseIntel {
 // HasEnemyIntel requires building-based intel. Unit-only sightings don't
 // count — scouting should continue until we find the actual base.
 func (e RuleEnv) HasEnemyIntel() bool {
-	for _, base := range getEnemyBases(e.Memory) {
+	for _, base := range GetEnemyBases(e.Memory) {
 		if base.FromBuildings {
 			return true
 		}
@@ -1400,7 +1511,7 @@ func (e RuleEnv) HasEnemyIntel() bool {
 
 // NearestEnemyBase returns the closest remembered enemy base for fog-of-war attacks.
 func (e RuleEnv) NearestEnemyBase() *EnemyBaseIntel {
-	bases := getEnemyBases(e.Memory)
+	bases := GetEnemyBases(e.Memory)
 	if len(bases) == 0 {
 		return nil
 	}
@@ -1427,19 +1538,18 @@ func (e RuleEnv) NearestEnemyBase() *EnemyBaseIntel {
 }
 
 func (e RuleEnv) EnemyBaseCount() int {
-	return len(getEnemyBases(e.Memory))
+	return len(GetEnemyBases(e.Memory))
 }
 
-// BaseUnderAttack uses a 20% map-diagonal proximity threshold. This avoids
-// false positives from distant enemies while catching attacks that haven't
-// reached buildings yet.
+// BaseUnderAttack reports an enemy within the doctrine's defense margin of
+// any of our buildings (see baseThreatRadius). This avoids false positives
+// from distant enemies while catching attacks that haven't reached buildings
+// yet.
 func (e RuleEnv) BaseUnderAttack() bool {
 	if len(e.State.Buildings) == 0 || len(e.State.Enemies) == 0 {
 		return false
 	}
-	mw := float64(e.State.MapWidth)
-	mh := float64(e.State.MapHeight)
-	threshold := math.Sqrt(mw*mw+mh*mh) * 0.20
+	threshold := e.baseThreatRadius()
 	threshSq := threshold * threshold
 
 	for i := range e.State.Enemies {
@@ -1454,6 +1564,104 @@ func (e RuleEnv) BaseUnderAttack() bool {
 	return false
 }
 
+// ConstructionYardHPPct returns the HP fraction of our healthiest
+// construction yard, or 0 if we have none.
+func (e RuleEnv) ConstructionYardHPPct() float64 {
+	best := 0.0
+	for _, b := range e.State.Buildings {
+		if matchesType(b.Type, ConstructionYard) && b.MaxHP > 0 {
+			best = math.Max(best, float64(b.HP)/float64(b.MaxHP))
+		}
+	}
+	return best
+}
+
+// GroundDefenseCount counts standing ground defenses (pillboxes, turrets,
+// towers).
+func (e RuleEnv) GroundDefenseCount() int {
+	n := 0
+	for _, role := range defenseRoles {
+		n += e.RoleCount(role)
+	}
+	return n
+}
+
+// CanBuildAnyGroundDefense reports whether any ground defense role is
+// buildable — what ActionProduceDefense picks from.
+func (e RuleEnv) CanBuildAnyGroundDefense() bool {
+	return slices.ContainsFunc(defenseRoles, e.CanBuildRole)
+}
+
+// militaryProductionRoles are the ground producers the rest of the tech
+// tree waits on: radar and further refineries hold until one stands.
+var militaryProductionRoles = []string{"barracks", "war_factory"}
+
+// AnyMilitaryProductionExists reports whether a barracks or war factory
+// stands.
+func (e RuleEnv) AnyMilitaryProductionExists() bool {
+	return slices.ContainsFunc(militaryProductionRoles, e.HasRole)
+}
+
+// relocation tracks an in-progress base move: the MCV heads for (X, Y) and
+// deploys there. Tick is when the move began, for timeout.
+type relocation struct {
+	X, Y int
+	Tick int
+}
+
+func getRelocation(memory map[string]any) *relocation {
+	if v, ok := relocationMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// IsRelocating is true while an MCV is en route to a new base site. A move
+// that outlives relocationTimeout (e.g. undeploy was refused) no longer counts.
+func (e RuleEnv) IsRelocating() bool {
+	rel := getRelocation(e.Memory)
+	return rel != nil && e.State.Tick-rel.Tick <= relocationTimeout
+}
+
+// SafestQuadrant picks the centre of the map quadrant with the least threat:
+// visible enemies count once, remembered enemy bases count heavily. Water
+// quadrants are skipped when terrain is known; ties go to the quadrant
+// closest to our current base so the MCV spends less time exposed.
+func (e RuleEnv) SafestQuadrant() (int, int) {
+	mw, mh := e.State.MapWidth, e.State.MapHeight
+	bx, by := e.BuildingCentroid()
+	bases := GetEnemyBases(e.Memory)
+
+	bestX, bestY := bx, by
+	bestScore, bestDist := math.MaxFloat64, math.MaxFloat64
+	for qy := 0; qy < 2; qy++ {
+		for qx := 0; qx < 2; qx++ {
+			cx, cy := mw/4+qx*mw/2, mh/4+qy*mh/2
+			if e.Terrain != nil && !e.IsLandAt(cx, cy) {
+				continue
+			}
+			inQuad := func(x, y int) bool { return (x >= mw/2) == (qx == 1) && (y >= mh/2) == (qy == 1) }
+
+			score := 0.0
+			for _, en := range e.State.Enemies {
+				if inQuad(en.X, en.Y) {
+					score++
+				}
+			}
+			for _, base := range bases {
+				if inQuad(base.X, base.Y) {
+					score += 10
+				}
+			}
+			dist := math.Hypot(float64(cx-bx), float64(cy-by))
+			if score < bestScore || (score == bestScore && dist < bestDist) {
+				bestX, bestY, bestScore, bestDist = cx, cy, score, dist
+			}
+		}
+	}
+	return bestX, bestY
+}
+
 func (e RuleEnv) CanBuildAnyCombatVehicle() bool {
 	for _, r := range combatVehicleRoles {
 		if e.CanBuildRole(r) {
@@ -1590,23 +1798,23 @@ func (e RuleEnv) BestBuildableNaval() string {
 
 // HasRole abstracts over faction-specific types (e.g. "barracks" matches both "barr" and "tent").
 func (e RuleEnv) HasRole(name string) bool {
-	r, ok := roles[name]
+	r, ok := e.Mod.role(name)
 	if !ok {
 		return false
 	}
-	return containsAnyType(e.State.Buildings, r.types) || containsAnyType(e.State.Units, r.types)
+	return containsAnyType(e.State.Buildings, r.types) || e.units().RoleCount(name) > 0
 }
 
 func (e RuleEnv) RoleCount(name string) int {
-	r, ok := roles[name]
+	r, ok := e.Mod.role(name)
 	if !ok {
 		return 0
 	}
-	return countAnyType(e.State.Buildings, r.types) + countAnyType(e.State.Units, r.types)
+	return countAnyType(e.State.Buildings, r.types) + e.units().RoleCount(name)
 }
 
 func (e RuleEnv) CanBuildRole(name string) bool {
-	r, ok := roles[name]
+	r, ok := e.Mod.role(name)
 	if !ok {
 		return false
 	}
@@ -1628,7 +1836,7 @@ func (e RuleEnv) CanBuildRole(name string) bool {
 // BuildableType resolves a role to its actual buildable name for the current faction
 // (e.g. "barracks" → "tent" for Allies, "barr" for Soviets).
 func (e RuleEnv) BuildableType(name string) string {
-	r, ok := roles[name]
+	r, ok := e.Mod.role(name)
 	if !ok {
 		return ""
 	}
@@ -1,8 +1,10 @@
 package rules
 
 import (
+	"math"
 	"testing"
 
+	"github.com/expr-lang/expr/vm"
 	"github.com/nstehr/vimy/vimy-core/model"
 )
 
@@ -480,7 +482,7 @@ func TestUpdateIntel_ClearsStaleIntel(t *testing.T) {
 
 	updateIntel(env)
 
-	bases := getEnemyBases(env.Memory)
+	bases := GetEnemyBases(env.Memory)
 	if _, exists := bases["Enemy1"]; exists {
 		t.Error("expected stale intel for Enemy1 to be cleared")
 	}
@@ -506,7 +508,7 @@ func TestUpdateIntel_KeepsFreshIntel(t *testing.T) {
 
 	updateIntel(env)
 
-	bases := getEnemyBases(env.Memory)
+	bases := GetEnemyBases(env.Memory)
 	if _, exists := bases["Enemy1"]; !exists {
 		t.Error("expected fresh intel for Enemy1 to be kept (age 100 < 300 threshold)")
 	}
@@ -532,7 +534,7 @@ func TestUpdateIntel_KeepsIntelWhenEnemiesNearby(t *testing.T) {
 
 	updateIntel(env)
 
-	bases := getEnemyBases(env.Memory)
+	bases := GetEnemyBases(env.Memory)
 	if _, exists := bases["Enemy1"]; !exists {
 		t.Error("expected intel for Enemy1 to be kept when enemies are nearby")
 	}
@@ -556,7 +558,7 @@ func TestUpdateIntel_KeepsIntelWhenNoOwnUnitsNearby(t *testing.T) {
 
 	updateIntel(env)
 
-	bases := getEnemyBases(env.Memory)
+	bases := GetEnemyBases(env.Memory)
 	if _, exists := bases["Enemy1"]; !exists {
 		t.Error("expected intel for Enemy1 to be kept when no own units nearby")
 	}
@@ -592,3 +594,248 @@ func TestServiceDepot_None(t *testing.T) {
 		t.Error("expected nil when no service depot")
 	}
 }
+
+func TestDoctrineParam(t *testing.T) {
+	d := DefaultDoctrine()
+	d.Aggression = 0.8
+	d.GroundAttackGroupSize = 7
+	env := RuleEnv{Doctrine: d, Memory: make(map[string]any)}
+
+	if got := env.DoctrineParam("aggression"); got != 0.8 {
+		t.Errorf("aggression: got %v, want 0.8", got)
+	}
+	if got := env.DoctrineParam("ground_attack_group_size"); got != 7 {
+		t.Errorf("ground_attack_group_size: got %v, want 7", got)
+	}
+	if got := env.DoctrineParam("nonexistent"); got != 0 {
+		t.Errorf("unknown param: got %v, want 0", got)
+	}
+
+	r := &Rule{Name: "aggressive", Category: "test", ConditionSrc: `DoctrineParam("aggression") > 0.5`}
+	compiled, err := compileRules([]*Rule{r})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	result, err := vm.Run(compiled[0].program, env)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result != true {
+		t.Errorf("condition: got %v, want true", result)
+	}
+}
+
+func TestSafestQuadrant(t *testing.T) {
+	// Base in the north-west under attack, enemy base in the south-east.
+	// The north-east quadrant is empty and closer than the south-west.
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 200,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+			Enemies: []model.Enemy{
+				{ID: 10, Type: "3tnk", X: 25, Y: 22},
+				{ID: 11, Type: "3tnk", X: 30, Y: 18},
+			},
+		},
+		Memory: map[string]any{
+			"enemyBases": map[string]EnemyBaseIntel{
+				"Enemy": {Owner: "Enemy", X: 110, Y: 185, FromBuildings: true},
+			},
+		},
+	}
+
+	x, y := env.SafestQuadrant()
+	if x != 96 || y != 50 {
+		t.Errorf("SafestQuadrant() = (%d,%d), want (96,50)", x, y)
+	}
+}
+
+func TestIsRelocatingExpires(t *testing.T) {
+	env := RuleEnv{
+		State:  model.GameState{Tick: 100},
+		Memory: map[string]any{"relocation": &relocation{X: 50, Y: 50, Tick: 90}},
+	}
+	if !env.IsRelocating() {
+		t.Error("fresh relocation should be active")
+	}
+	env.State.Tick = 90 + relocationTimeout + 1
+	if env.IsRelocating() {
+		t.Error("relocation past timeout should not be active")
+	}
+}
+
+func TestProjectedPowerExcess(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Player: model.Player{PowerProvided: 200, PowerDrained: 150},
+			ProductionQueues: []model.ProductionQueue{
+				{Type: "Building", CurrentItem: "dome", Items: []string{"dome"}},
+				{Type: "Defense", CurrentItem: "tsla", CurrentProgress: 100, Items: []string{"tsla"}},
+				{Type: "Vehicle", CurrentItem: "3tnk", Items: []string{"3tnk"}},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	// 50 excess - 40 (dome) - 100 (tesla coil, ready but unplaced); tank ignored.
+	if got := env.ProjectedPowerExcess(); got != -90 {
+		t.Errorf("ProjectedPowerExcess() = %d, want -90", got)
+	}
+	if got := env.PowerAfter("powr"); got != 10 {
+		t.Errorf("PowerAfter(powr) = %d, want 10", got)
+	}
+}
+
+func TestProjectedCash(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Player:    model.Player{Cash: 1000},
+			Buildings: []model.Building{{ID: 1, Type: "proc", X: 50, Y: 50}},
+			Units: []model.Unit{
+				{ID: 2, Type: "harv", X: 51, Y: 50}, // floored to minOreDistance: 6 cells
+				{ID: 3, Type: "harv", X: 50, Y: 74}, // 24 cells away
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	// Trips: 2*6/2+12 = 18s and 2*24/2+12 = 36s → 500/18 + 500/36 ≈ 41.67 credits/s.
+	if got := env.IncomeRate(); math.Abs(got-41.67) > 0.01 {
+		t.Errorf("IncomeRate() = %.2f, want 41.67", got)
+	}
+	if got := env.ProjectedCash(30); got != 2250 {
+		t.Errorf("ProjectedCash(30) = %d, want 2250", got)
+	}
+
+	env.State.Buildings = nil
+	if got := env.ProjectedCash(30); got != 1000 {
+		t.Errorf("without a refinery ProjectedCash(30) = %d, want 1000", got)
+	}
+}
+
+func TestVeteransRetreatEarlier(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "3tnk", HP: 40, MaxHP: 100},               // rookie above threshold
+				{ID: 2, Type: "3tnk", HP: 40, MaxHP: 100, Veterancy: 2}, // elite: threshold 0.30+0.20
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	got := env.DamagedCombatUnits(0.30)
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("expected only the veteran (ID 2) to retreat, got %v", got)
+	}
+}
+
+func TestBestGroundTargetPrefersVeterans(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 0, Y: 0}},
+			Enemies: []model.Enemy{
+				{ID: 10, Type: "3tnk", X: 10, Y: 0, HP: 100, MaxHP: 100},
+				{ID: 11, Type: "3tnk", X: 12, Y: 0, HP: 100, MaxHP: 100, Veterancy: 3},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	if got := env.BestGroundTarget(); got == nil || got.ID != 11 {
+		t.Errorf("expected veteran enemy 11 to be targeted, got %+v", got)
+	}
+}
+
+func TestGroundDefenseHelpers(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{
+				{ID: 1, Type: "pbox"},
+				{ID: 2, Type: "gun"},
+				{ID: 3, Type: "agun"},
+				{ID: 4, Type: "powr"},
+			},
+			ProductionQueues: []model.ProductionQueue{
+				{Type: "Defense", Buildable: []string{"agun", "tsla"}},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	if got := env.GroundDefenseCount(); got != 2 {
+		t.Errorf("GroundDefenseCount = %d, want 2 (AA excluded)", got)
+	}
+	if !env.CanBuildAnyGroundDefense() {
+		t.Error("CanBuildAnyGroundDefense should see the buildable tesla coil")
+	}
+	env.State.ProductionQueues[0].Buildable = []string{"agun"}
+	if env.CanBuildAnyGroundDefense() {
+		t.Error("an AA gun is not a ground defense")
+	}
+	if env.AnyMilitaryProductionExists() {
+		t.Error("no barracks or war factory yet")
+	}
+	env.State.Buildings = append(env.State.Buildings, model.Building{ID: 5, Type: "weap"})
+	if !env.AnyMilitaryProductionExists() {
+		t.Error("war factory should count as military production")
+	}
+}
+
+func TestUpdateIntel_ExpiresOldIntel(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{Tick: 200 + intelUnitExpiryTicks},
+		Memory: map[string]any{
+			"enemyBases": map[string]EnemyBaseIntel{
+				"Enemy1": {Owner: "Enemy1", X: 100, Y: 100, Tick: 200, FromBuildings: true},
+				"Enemy2": {Owner: "Enemy2", X: 300, Y: 300, Tick: 200, FromBuildings: false},
+			},
+		},
+	}
+
+	updateIntel(env)
+	bases := GetEnemyBases(env.Memory)
+	if _, exists := bases["Enemy2"]; exists {
+		t.Error("expected unit-seeded intel to expire")
+	}
+	if _, exists := bases["Enemy1"]; !exists {
+		t.Fatal("expected building intel to outlast unit-seeded intel")
+	}
+
+	env.State.Tick = 200 + intelExpiryTicks
+	updateIntel(env)
+	if _, exists := GetEnemyBases(env.Memory)["Enemy1"]; exists {
+		t.Error("expected building intel unseen for intelExpiryTicks to expire")
+	}
+}
+
+func TestUpdateIntel_MovesToRememberedBuildings(t *testing.T) {
+	// Our tank reaches the recorded centroid and finds nothing, but we
+	// remember a war factory further out that nobody has checked.
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:  600,
+			Units: []model.Unit{{ID: 1, Type: "3tnk", X: 100, Y: 100}},
+		},
+		Memory: map[string]any{
+			"enemyBases": map[string]EnemyBaseIntel{
+				"Enemy1": {Owner: "Enemy1", X: 105, Y: 105, Tick: 200, FromBuildings: true},
+			},
+			"enemyStructures": map[int]*EnemyStructure{
+				10: {ID: 10, Owner: "Enemy1", Type: "powr", X: 104, Y: 104, LastSeen: 200},
+				11: {ID: 11, Owner: "Enemy1", Type: "weap", X: 140, Y: 120, LastSeen: 250},
+			},
+		},
+	}
+
+	updateEnemyStructures(env)
+	updateIntel(env)
+
+	intel, exists := GetEnemyBases(env.Memory)["Enemy1"]
+	if !exists {
+		t.Fatal("expected intel to be kept while a remembered building is unchecked")
+	}
+	if intel.X != 140 || intel.Y != 120 || intel.Tick != 250 {
+		t.Errorf("intel = %+v, want it moved to the war factory at (140,120) as of tick 250", intel)
+	}
+}
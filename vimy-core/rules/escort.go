@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// escortHoldTicks is how long a harvester escort stays on after the danger
+// that triggered it has passed (30s), so it isn't recalled mid-skirmish.
+const escortHoldTicks = 750
+
+// Escort kinds — what the guarded unit is and why it needs protection.
+const (
+	escortHarvester = "harvester" // harvester with enemies nearby
+	escortAPC       = "apc"       // loaded APC on a capture or assault run
+	escortSiege     = "siege"     // artillery / V2, helpless up close
+)
+
+// escort is one guard assignment. Escorts are issued a single guard order
+// and left to follow their charge; the engine only tracks them so they
+// aren't reassigned and can be released when no longer needed.
+type escort struct {
+	Charge int    // guarded unit ID
+	Kind   string // escortHarvester, escortAPC or escortSiege
+	Tick   int    // tick the charge last needed protection
+}
+
+func getEscorts(memory map[string]any) map[int]*escort {
+	if v, ok := escortsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*escort)
+}
+
+// updateEscorts drops assignments whose escort or charge has died.
+func updateEscorts(env RuleEnv) {
+	escorts := getEscorts(env.Memory)
+	if len(escorts) == 0 {
+		return
+	}
+	units := env.units()
+	for id, es := range escorts {
+		if !units.Has(id) || !units.Has(es.Charge) {
+			delete(escorts, id)
+		}
+	}
+	if len(escorts) == 0 {
+		escortsMemory.clear(env.Memory)
+		return
+	}
+	escortsMemory.set(env.Memory, escorts)
+}
+
+// escortCharge is a unit that should have an escort.
+type escortCharge struct {
+	Unit model.Unit
+	Kind string
+}
+
+// escortCharges lists units currently needing protection: loaded APCs,
+// siege vehicles, and (when harvesterDangerPct > 0) harvesters with
+// enemies within that fraction of the map diagonal.
+func (e RuleEnv) escortCharges(harvesterDangerPct float64) []escortCharge {
+	var out []escortCharge
+	for _, u := range e.State.Units {
+		switch {
+		case matchesType(u.Type, APC) && u.CargoCount > 0:
+			out = append(out, escortCharge{u, escortAPC})
+		case matchesType(u.Type, Artillery) || matchesType(u.Type, V2Launcher):
+			out = append(out, escortCharge{u, escortSiege})
+		}
+	}
+	if harvesterDangerPct > 0 {
+		for _, u := range e.HarvestersInDanger(harvesterDangerPct) {
+			out = append(out, escortCharge{u, escortHarvester})
+		}
+	}
+	return out
+}
+
+// EscortsNeeded counts charges that need protection but have no escort.
+func (e RuleEnv) EscortsNeeded(harvesterDangerPct float64) int {
+	guarded := make(map[int]bool)
+	for _, es := range getEscorts(e.Memory) {
+		guarded[es.Charge] = true
+	}
+	n := 0
+	for _, c := range e.escortCharges(harvesterDangerPct) {
+		if !guarded[c.Unit.ID] {
+			n++
+		}
+	}
+	return n
+}
+
+// HasEscorts reports whether any escort assignments are active.
+func (e RuleEnv) HasEscorts() bool { return len(getEscorts(e.Memory)) > 0 }
+
+// isEscort reports whether the unit is assigned to guard another unit.
+func isEscort(memory map[string]any, id int) bool {
+	_, ok := getEscorts(memory)[id]
+	return ok
+}
+
+// escortPool returns idle, unassigned ground units suitable for escort duty,
+// nearest to (x, y) first. Ranged units are skipped — they're the ones
+// that need protecting.
+func (e RuleEnv) escortPool(x, y int) []model.Unit {
+	escorts := getEscorts(e.Memory)
+	var out []model.Unit
+	for _, u := range e.UnassignedIdleGround() {
+		if _, ok := escorts[u.ID]; ok || rangeOf(u.Type) > 0 {
+			continue
+		}
+		out = append(out, u)
+	}
+	slices.SortFunc(out, func(a, b model.Unit) int {
+		da := math.Hypot(float64(a.X-x), float64(a.Y-y))
+		db := math.Hypot(float64(b.X-x), float64(b.Y-y))
+		switch {
+		case da < db:
+			return -1
+		case da > db:
+			return 1
+		}
+		return a.ID - b.ID
+	})
+	return out
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestAssignEscorts(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 50, Type: "fact", X: 10, Y: 10}},
+			Units: []model.Unit{
+				{ID: 1, Type: "apc", X: 40, Y: 40, CargoCount: 1}, // loaded APC
+				{ID: 2, Type: "arty", X: 60, Y: 60, Idle: true},   // siege vehicle
+				{ID: 3, Type: "2tnk", X: 42, Y: 40, Idle: true},   // nearest the APC
+				{ID: 4, Type: "2tnk", X: 58, Y: 60, Idle: true},   // nearest the artillery
+				{ID: 5, Type: "2tnk", X: 90, Y: 90, Idle: true},
+			},
+		},
+		Memory: mem,
+	}
+
+	if got := env.EscortsNeeded(0); got != 2 {
+		t.Fatalf("EscortsNeeded = %d, want 2", got)
+	}
+	if err := AssignEscorts(0, 4)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	escorts := getEscorts(mem)
+	if escorts[3] == nil || escorts[3].Charge != 1 || escorts[3].Kind != escortAPC {
+		t.Errorf("unit 3 should escort APC 1, got %+v", escorts[3])
+	}
+	if escorts[4] == nil || escorts[4].Charge != 2 || escorts[4].Kind != escortSiege {
+		t.Errorf("unit 4 should escort artillery 2, got %+v", escorts[4])
+	}
+	if escorts[2] != nil {
+		t.Error("artillery must not be used as an escort")
+	}
+	if got := env.EscortsNeeded(0); got != 0 {
+		t.Errorf("EscortsNeeded after assignment = %d, want 0", got)
+	}
+
+	// The APC unloads: its escort is released.
+	env.State.Units[0].CargoCount = 0
+	if err := AssignEscorts(0, 4)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := getEscorts(mem)[3]; ok {
+		t.Error("escort of unloaded APC should be released")
+	}
+}
+
+func TestAssignEscortsRespectsCap(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "arty", X: 10, Y: 10},
+				{ID: 2, Type: "v2rl", X: 20, Y: 20},
+				{ID: 3, Type: "1tnk", X: 11, Y: 10, Idle: true},
+				{ID: 4, Type: "1tnk", X: 21, Y: 20, Idle: true},
+			},
+		},
+		Memory: mem,
+	}
+	if err := AssignEscorts(0, 1)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(getEscorts(mem)); n != 1 {
+		t.Errorf("escorts = %d, want cap of 1", n)
+	}
+}
+
+func TestUpdateEscortsDropsDead(t *testing.T) {
+	mem := map[string]any{"escorts": map[int]*escort{
+		3: {Charge: 1, Kind: escortSiege},
+		4: {Charge: 9, Kind: escortSiege}, // charge dead
+	}}
+	env := RuleEnv{
+		State:  model.GameState{Units: []model.Unit{{ID: 1}, {ID: 3}, {ID: 4}}},
+		Memory: mem,
+	}
+	updateEscorts(env)
+	escorts := getEscorts(mem)
+	if len(escorts) != 1 || escorts[3] == nil {
+		t.Errorf("escorts after update = %v, want only 3", escorts)
+	}
+}
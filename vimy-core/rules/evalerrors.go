@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// evalErrorLogInterval is how many ticks a failing rule stays quiet in the
+// log after reporting. A broken condition fails every tick; the first error
+// is logged in full, then one line per interval with the number suppressed.
+const evalErrorLogInterval = 250
+
+// EvalSnapshot summarises the game state a condition failed against —
+// enough to tell an empty early-game state from a mid-fight one without
+// keeping the whole state.
+type EvalSnapshot struct {
+	Tick      int    `json:"tick"`
+	Faction   string `json:"faction"`
+	Cash      int    `json:"cash"`
+	Buildings int    `json:"buildings"`
+	Units     int    `json:"units"`
+	Enemies   int    `json:"enemies"`
+}
+
+func snapshotOf(env RuleEnv) EvalSnapshot {
+	return EvalSnapshot{
+		Tick:      env.State.Tick,
+		Faction:   env.Faction,
+		Cash:      env.State.Player.Cash + env.State.Player.Resources,
+		Buildings: len(env.State.Buildings),
+		Units:     len(env.State.Units),
+		Enemies:   len(env.State.Enemies),
+	}
+}
+
+// RuleEvalError is the runtime error record for one rule's condition: how
+// often it has failed since the rule set was swapped in, the latest error,
+// and the state it failed against.
+type RuleEvalError struct {
+	Rule      string       `json:"rule"`
+	Condition string       `json:"condition"`
+	Count     int          `json:"count"`
+	FirstTick int          `json:"first_tick"`
+	LastTick  int          `json:"last_tick"`
+	LastError string       `json:"last_error"`
+	Snapshot  EvalSnapshot `json:"snapshot"`
+
+	loggedTick int // tick of the last log line
+	suppressed int // errors since the last log line
+}
+
+// recordEvalError captures a condition error against the rule and logs it,
+// rate-limited per rule. Caller must hold memMu.
+func (e *Engine) recordEvalError(r *Rule, env RuleEnv, err error) {
+	rec, ok := e.evalErrors[r.Name]
+	if !ok {
+		rec = &RuleEvalError{Rule: r.Name, FirstTick: env.State.Tick}
+		e.evalErrors[r.Name] = rec
+	}
+	rec.Condition = r.ConditionSrc
+	rec.Count++
+	rec.LastTick = env.State.Tick
+	rec.LastError = err.Error()
+	rec.Snapshot = snapshotOf(env)
+
+	switch {
+	case rec.Count == 1:
+		rec.loggedTick = env.State.Tick
+		slog.Warn("rule condition error", "rule", r.Name, "error", err,
+			"condition", r.ConditionSrc, "snapshot", rec.Snapshot)
+	case env.State.Tick-rec.loggedTick >= evalErrorLogInterval:
+		slog.Warn("rule condition still failing", "rule", r.Name, "error", err,
+			"count", rec.Count, "suppressed", rec.suppressed)
+		rec.loggedTick = env.State.Tick
+		rec.suppressed = 0
+	default:
+		rec.suppressed++
+	}
+}
+
+// EvalErrors returns the condition error records of every rule that has
+// failed since the last Swap, sorted by rule name.
+func (e *Engine) EvalErrors() []RuleEvalError {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	out := make([]RuleEvalError, 0, len(e.evalErrors))
+	for _, name := range slices.Sorted(maps.Keys(e.evalErrors)) {
+		out = append(out, *e.evalErrors[name])
+	}
+	return out
+}
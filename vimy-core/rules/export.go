@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"maps"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RuleExport is one active rule as data, for dashboards outside vimy that
+// draw the priority ladder. Unlike RuleSummary it carries JSON names and
+// identifies the rule's action.
+type RuleExport struct {
+	Name            string `json:"name"`
+	Priority        int    `json:"priority"`
+	Category        string `json:"category"`
+	Group           string `json:"group,omitempty"`
+	Exclusive       bool   `json:"exclusive"`
+	CooldownTicks   int    `json:"cooldown_ticks,omitempty"`
+	HoldTicks       int    `json:"hold_ticks,omitempty"`
+	ActiveFromTick  int    `json:"active_from_tick,omitempty"`
+	ActiveUntilTick int    `json:"active_until_tick,omitempty"`
+	Condition       string `json:"condition"`
+	Action          string `json:"action"`
+	Quarantined     bool   `json:"quarantined,omitempty"`
+}
+
+// ExportRules returns the active rule set, overrides applied, in evaluation
+// order (highest priority first).
+func (e *Engine) ExportRules() []RuleExport {
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	e.memMu.Lock()
+	quarantined := maps.Clone(e.quarantined)
+	e.memMu.Unlock()
+
+	out := make([]RuleExport, len(rules))
+	for i, r := range rules {
+		out[i] = RuleExport{
+			Name:            r.Name,
+			Priority:        r.Priority,
+			Category:        r.Category,
+			Group:           r.Group,
+			Exclusive:       r.Exclusive,
+			CooldownTicks:   r.CooldownTicks,
+			HoldTicks:       r.HoldTicks,
+			ActiveFromTick:  r.ActiveFromTick,
+			ActiveUntilTick: r.ActiveUntilTick,
+			Condition:       r.ConditionSrc,
+			Action:          actionName(r.Action),
+			Quarantined:     quarantined[r.Name],
+		}
+	}
+	return out
+}
+
+// actionName identifies an action by the function that made it: the
+// closure SquadAirStrike("air-attack") returns reads "SquadAirStrike", and
+// a plain action like ActionDefendBase its own name. The squad or item it
+// was built for is in the rule's name and condition.
+func actionName(fn ActionFunc) string {
+	if fn == nil {
+		return ""
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	// e.g. "github.com/nstehr/vimy/vimy-core/rules.SquadAirStrike.func1"
+	name := f.Name()
+	name = name[strings.LastIndexByte(name, '/')+1:]
+	parts := strings.Split(name, ".")[1:] // drop the package
+	for i, p := range parts {
+		if isClosureSuffix(p) {
+			parts = parts[:i]
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// isClosureSuffix reports whether p is a compiler-generated closure name
+// element: "func1", "gowrap2" or a bare nesting index.
+func isClosureSuffix(p string) bool {
+	for _, prefix := range []string{"func", "gowrap", ""} {
+		if rest, ok := strings.CutPrefix(p, prefix); ok && rest != "" && strings.Trim(rest, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportRules(t *testing.T) {
+	d := DefaultDoctrine()
+	d.AirWeight = 0.5
+	d.GroundDefensePriority = 0.2
+	engine, err := NewEngine(CompileDoctrine(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := engine.ExportRules()
+	if len(export) == 0 {
+		t.Fatal("no rules exported")
+	}
+	byName := make(map[string]RuleExport, len(export))
+	for i, r := range export {
+		byName[r.Name] = r
+		if i > 0 && r.Priority > export[i-1].Priority {
+			t.Errorf("%s (%d) listed after %s (%d): want evaluation order", r.Name, r.Priority, export[i-1].Name, export[i-1].Priority)
+		}
+		if r.Action == "" || strings.ContainsAny(r.Action, "/") {
+			t.Errorf("%s action = %q", r.Name, r.Action)
+		}
+	}
+	for name, action := range map[string]string{
+		"squad-air-attack": "SquadAirStrike",
+		"defend-base":      "ActionDefendBase",
+	} {
+		if got := byName[name].Action; got != action {
+			t.Errorf("%s action = %q, want %q", name, got, action)
+		}
+	}
+	if r := byName["squad-air-attack"]; r.Category != "air_combat" || !strings.Contains(r.Condition, `SquadExists("air-attack")`) {
+		t.Errorf("squad-air-attack exported as %+v", r)
+	}
+
+	data, err := json.Marshal(export[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"name"`, `"priority"`, `"condition"`, `"action"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("export JSON %s lacks %s", data, key)
+		}
+	}
+}
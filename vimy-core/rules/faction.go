@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Sides group RA's playable countries.
+const (
+	SideSoviet = "soviet"
+	SideAllies = "allies"
+)
+
+// factionSides maps the faction the mod reports in Hello to its side.
+var factionSides = map[string]string{
+	"soviet": SideSoviet, "russia": SideSoviet, "ukraine": SideSoviet,
+	"allies": SideAllies, "england": SideAllies, "france": SideAllies, "germany": SideAllies,
+}
+
+// FactionSide returns the side a faction plays ("soviet" or "allies"), or
+// "" for factions outside RA.
+func FactionSide(faction string) string {
+	return factionSides[strings.ToLower(faction)]
+}
+
+// FactionPreferences maps a faction ("ukraine") or side ("soviet") to the
+// unit preferences production starts with before any doctrine names its own.
+type FactionPreferences map[string]UnitPreferences
+
+// DefaultFactionPreferences lean each side towards its strengths: Soviet
+// armour, rockets and tesla; Allied medium tanks, artillery and Longbows.
+var DefaultFactionPreferences = FactionPreferences{
+	SideSoviet: {
+		Infantry: []string{"shock_trooper", "flamethrower"},
+		Vehicle:  []string{"heavy_tank", "medium_tank", "v2_launcher", "tesla_tank"},
+		Aircraft: []string{"advanced_aircraft", "basic_aircraft"},
+		Naval:    []string{"missile_sub", "submarine"},
+	},
+	SideAllies: {
+		Infantry: []string{"tanya", "medic"},
+		Vehicle:  []string{"medium_tank", "artillery", "light_tank", "ranger"},
+		Aircraft: []string{"advanced_aircraft", "basic_aircraft"},
+		Naval:    []string{"cruiser", "destroyer", "gunboat"},
+	},
+}
+
+// LoadFactionPreferences reads a JSON file of per-faction preference
+// overrides, e.g.
+//
+//	{"soviet": {"vehicle": ["tesla_tank", "heavy_tank"]},
+//	 "england": {"aircraft": ["basic_aircraft"]}}
+//
+// Categories an entry leaves out keep the built-in defaults.
+func LoadFactionPreferences(path string) (FactionPreferences, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read faction preferences: %w", err)
+	}
+	var fp FactionPreferences
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return nil, fmt.Errorf("unmarshal faction preferences: %w", err)
+	}
+	for faction, p := range fp {
+		for _, list := range [][]string{p.Infantry, p.Vehicle, p.Aircraft, p.Naval} {
+			for _, r := range list {
+				if _, ok := roles[r]; !ok {
+					return nil, fmt.Errorf("faction preferences %q: unknown role %q", faction, r)
+				}
+			}
+		}
+	}
+	return fp, nil
+}
+
+// For resolves a faction's preferences category by category: an entry for
+// the exact faction wins, then one for its side, then the built-in default
+// for its side.
+func (fp FactionPreferences) For(faction string) UnitPreferences {
+	faction = strings.ToLower(faction)
+	side := FactionSide(faction)
+	return fp[faction].orElse(fp[side]).orElse(DefaultFactionPreferences[side])
+}
+
+// orElse fills each category p leaves empty from fallback.
+func (p UnitPreferences) orElse(fallback UnitPreferences) UnitPreferences {
+	if len(p.Infantry) == 0 {
+		p.Infantry = fallback.Infantry
+	}
+	if len(p.Vehicle) == 0 {
+		p.Vehicle = fallback.Vehicle
+	}
+	if len(p.Aircraft) == 0 {
+		p.Aircraft = fallback.Aircraft
+	}
+	if len(p.Naval) == 0 {
+		p.Naval = fallback.Naval
+	}
+	return p
+}
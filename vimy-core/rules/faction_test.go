@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestFactionPreferencesFor(t *testing.T) {
+	fp := FactionPreferences{
+		SideSoviet: {Vehicle: []string{"tesla_tank"}},
+		"ukraine":  {Aircraft: []string{"basic_aircraft"}},
+	}
+
+	ukr := fp.For("Ukraine")
+	if !slices.Equal(ukr.Aircraft, []string{"basic_aircraft"}) {
+		t.Errorf("ukraine aircraft = %v, want the faction override", ukr.Aircraft)
+	}
+	if !slices.Equal(ukr.Vehicle, []string{"tesla_tank"}) {
+		t.Errorf("ukraine vehicle = %v, want the soviet side override", ukr.Vehicle)
+	}
+	if !slices.Equal(ukr.Infantry, DefaultFactionPreferences[SideSoviet].Infantry) {
+		t.Errorf("ukraine infantry = %v, want the soviet default", ukr.Infantry)
+	}
+
+	if got := FactionPreferences(nil).For("england"); !slices.Equal(got.Vehicle, DefaultFactionPreferences[SideAllies].Vehicle) {
+		t.Errorf("england vehicle = %v, want the allied default", got.Vehicle)
+	}
+	if got := FactionPreferences(nil).For("gdi"); len(got.Vehicle) != 0 {
+		t.Errorf("non-RA faction got preferences %v", got)
+	}
+}
+
+func TestLoadFactionPreferencesRejectsUnknownRoles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"soviet": {"vehicle": ["tesla_tank"]}}`), 0o644)
+	if _, err := LoadFactionPreferences(good); err != nil {
+		t.Errorf("LoadFactionPreferences(good): %v", err)
+	}
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"soviet": {"vehicle": ["hover_tank"]}}`), 0o644)
+	if _, err := LoadFactionPreferences(bad); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestEngineSeedsPreferencesFromFaction(t *testing.T) {
+	var got UnitPreferences
+	e, err := NewEngine([]*Rule{{
+		Name:         "capture-prefs",
+		ConditionSrc: "true",
+		Action: func(env RuleEnv, conn *ipc.Connection) error {
+			got = env.Preferences
+			return nil
+		},
+	}})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	e.SetFaction("russia")
+	e.SetPreferences(UnitPreferences{Aircraft: []string{"basic_aircraft"}})
+	if err := e.Evaluate(model.GameState{}, "russia", nil); err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if !slices.Equal(got.Vehicle, DefaultFactionPreferences[SideSoviet].Vehicle) {
+		t.Errorf("vehicle prefs = %v, want the soviet defaults", got.Vehicle)
+	}
+	if !slices.Equal(got.Aircraft, []string{"basic_aircraft"}) {
+		t.Errorf("aircraft prefs = %v, want the doctrine's", got.Aircraft)
+	}
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Ground focus fire budgeting. Like an air strike (see allocateAirStrike), a
+// squad's idle units are budgeted against their targets' remaining health:
+// a damaged turret gets only the tanks it takes to finish it and the rest of
+// the squad moves on to the next targets around it.
+const (
+	focusFireMaxTargets   = 3    // targets one volley is split across
+	focusFireSpreadRadius = 6.0  // cells from the primary target a secondary may be
+	focusFireOverkill     = 1.5  // damage budgeted per target, as a multiple of its remaining health
+	focusFireRefCost      = 850  // cost of the reference attacker (a medium tank)
+	focusFireWeightMin    = 0.25 // weight floor, so cheap infantry still count
+	focusFireWeightMax    = 2.0
+)
+
+// focusFireShare is the fraction of a target's max HP the reference attacker
+// removes before the next re-target, by the target's armor class (see
+// UnitInfo.Armor). Unlisted types use focusFireShareDefault.
+var focusFireShare = map[string]float64{
+	"none":     0.5,
+	"light":    0.35,
+	"heavy":    0.2,
+	"wood":     0.15,
+	"concrete": 0.1,
+}
+
+const focusFireShareDefault = 0.2
+
+// focusFireDamage estimates the fraction of target's max HP one attacker
+// of type attacker removes, scaling focusFireShare by the attacker's cost
+// relative to focusFireRefCost. Unlisted attackers count at the floor.
+func focusFireDamage(attacker string, target *model.Enemy) float64 {
+	share := focusFireShareDefault
+	if u, ok := LookupUnit(target.Type); ok {
+		if s, ok := focusFireShare[u.Armor]; ok {
+			share = s
+		}
+	}
+	weight := focusFireWeightMin
+	if u, ok := LookupUnit(attacker); ok && u.Range > 0 {
+		weight = min(max(float64(u.Cost)/focusFireRefCost, focusFireWeightMin), focusFireWeightMax)
+	}
+	return share * weight
+}
+
+// focusFireTargets returns up to focusFireMaxTargets enemies for a squad's
+// focus fire, best first: BestGroundTarget, then the highest-scoring enemies
+// within focusFireSpreadRadius of it. A priority target is attacked alone,
+// and enemies held for capture are never picked.
+func (e RuleEnv) focusFireTargets() []*model.Enemy {
+	primary := e.BestGroundTarget()
+	if primary == nil {
+		return nil
+	}
+	if e.priorityTargetOverride() != nil {
+		return []*model.Enemy{primary}
+	}
+	bx, by := e.targetOrigin()
+	return e.targetsNear(primary, focusFireSpreadRadius, focusFireMaxTargets, func(en *model.Enemy) float64 {
+		if e.heldForCapture(en.ID) {
+			return -1
+		}
+		return groundTargetScore(en, bx, by)
+	})
+}
+
+// unitTypes maps each of our actor IDs in ids to its type, as
+// allocateFocusFire and allocateAirStrike take them.
+func (e RuleEnv) unitTypes(ids []uint32) map[uint32]string {
+	units := e.units()
+	types := make(map[uint32]string, len(ids))
+	for _, id := range ids {
+		if u, ok := units.Get(int(id)); ok {
+			types[id] = u.Type
+		}
+	}
+	return types
+}
+
+// allocateFocusFire assigns each squad unit to one of targets (best first)
+// so each gets enough damage to kill it with focusFireOverkill to spare.
+// Units left over join the first target. units maps actor ID to type.
+func allocateFocusFire(ids []uint32, units map[uint32]string, targets []*model.Enemy) map[int][]uint32 {
+	return allocateDamage(ids, units, targets, focusFireOverkill, focusFireDamage)
+}
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestAllocateFocusFireSplitsByHealth(t *testing.T) {
+	ids := []uint32{1, 2, 3, 4, 5, 6}
+	types := map[uint32]string{1: "2tnk", 2: "2tnk", 3: "2tnk", 4: "2tnk", 5: "2tnk", 6: "2tnk"}
+	tank := &model.Enemy{ID: 10, Type: "1tnk", HP: 20, MaxHP: 100}
+	rifle := &model.Enemy{ID: 11, Type: "e1", HP: 50, MaxHP: 50}
+
+	alloc := allocateFocusFire(ids, types, []*model.Enemy{tank, rifle})
+	// Damaged light tank: 1.5 × 0.2 = 0.3 needed at 0.2 a tank → 2, plus the
+	// one left after the rifleman's 3.
+	if got := len(alloc[tank.ID]); got != 3 {
+		t.Errorf("light tank got %d units, want 3", got)
+	}
+	if got := len(alloc[rifle.ID]); got != 3 {
+		t.Errorf("rifleman got %d units, want 3", got)
+	}
+}
+
+func TestFocusFireDamageScalesWithAttacker(t *testing.T) {
+	pbox := &model.Enemy{ID: 1, Type: "pbox", HP: 100, MaxHP: 100}
+	if rifle, tank := focusFireDamage("e1", pbox), focusFireDamage("4tnk", pbox); rifle >= tank {
+		t.Errorf("rifleman damage %.3f should be below mammoth tank %.3f", rifle, tank)
+	}
+	if got := focusFireDamage("medi", pbox); got != focusFireShare["concrete"]*focusFireWeightMin {
+		t.Errorf("unarmed medic damage = %.3f, want the floor", got)
+	}
+}
+
+func TestFocusFireTargetsStayNearPrimary(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Enemies: []model.Enemy{
+				{ID: 20, Type: "gun", X: 40, Y: 40, HP: 100, MaxHP: 100},
+				{ID: 21, Type: "e1", X: 43, Y: 40, HP: 50, MaxHP: 50},
+				{ID: 22, Type: "1tnk", X: 80, Y: 80, HP: 100, MaxHP: 100},
+			},
+		},
+		Memory: map[string]any{},
+	}
+	targets := env.focusFireTargets()
+	if len(targets) != 2 || targets[0].ID != 20 || targets[1].ID != 21 {
+		ids := []int{}
+		for _, t := range targets {
+			ids = append(ids, t.ID)
+		}
+		t.Errorf("targets = %v, want [20 21] (the far tank excluded)", ids)
+	}
+}
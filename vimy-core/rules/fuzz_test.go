@@ -0,0 +1,257 @@
+package rules
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Fuzz targets. Both compile a doctrine, replay a few random game states
+// through it, and fail on anything that isn't a clean evaluation: a
+// condition error, a recovered panic in a condition or action, or a panic
+// that escapes Evaluate (the memory updates run unprotected). The seed
+// corpus runs with go test; go test -fuzz=FuzzX ./rules explores further.
+
+// fuzzTicks is how many states each fuzz input replays, enough for memory
+// built on one tick (squads, intel, cooldowns) to be read on the next.
+const fuzzTicks = 4
+
+// fuzzTypes mixes real RA actor types of every kind with names no rule
+// knows, so type lookups see both.
+var fuzzTypes = []string{
+	"fact", "powr", "apwr", "proc", "silo", "barr", "tent", "kenn", "weap", "dome", "afld", "hpad",
+	"spen", "syrd", "fix", "atek", "stek", "mslo", "iron", "pdox", "gap", "tsla", "ftur", "gun",
+	"pbox", "hbox", "agun", "sam", "mcv", "harv", "3tnk", "1tnk", "2tnk", "4tnk", "v2rl", "ttnk",
+	"arty", "apc", "jeep", "mnly", "truk", "e1", "e2", "e3", "e4", "e6", "dog", "spy", "medi",
+	"shok", "mig", "yak", "heli", "hind", "mh60", "ss", "msub", "dd", "ca", "pt", "lst",
+	"bridge1", "br2", "v19", "t01", "", "nonesuch",
+}
+
+var fuzzQueueTypes = []string{"Building", "Defense", "Infantry", "Vehicle", "Aircraft", "Ship", ""}
+
+var fuzzPowerKeys = []string{
+	"NukePowerInfoOrder", "GrantExternalConditionPowerInfoOrder", "SovietParatroopers",
+	"SovietSpyPlane", "UkraineParabombs", "",
+}
+
+// fuzzValue returns a small value biased toward the edges the rules trip
+// on: zero, negative, and just past a limit.
+func fuzzValue(rng *rand.Rand, limit int) int {
+	limit = max(limit, 1)
+	switch rng.Intn(8) {
+	case 0:
+		return 0
+	case 1:
+		return -rng.Intn(limit + 1)
+	case 2:
+		return limit + rng.Intn(4)
+	default:
+		return rng.Intn(limit + 1)
+	}
+}
+
+func fuzzType(rng *rand.Rand) string { return fuzzTypes[rng.Intn(len(fuzzTypes))] }
+
+func fuzzTypeList(rng *rand.Rand) []string {
+	out := make([]string, rng.Intn(6))
+	for i := range out {
+		out[i] = fuzzType(rng)
+	}
+	return out
+}
+
+// randomState generates a game state on a map up to 128 cells square. IDs
+// are drawn from a small range so actors collide across lists and ticks,
+// HP may be zero, negative or above MaxHP, and positions may lie off the map.
+func randomState(rng *rand.Rand, tick int) model.GameState {
+	w, h := fuzzValue(rng, 128), fuzzValue(rng, 128)
+	id := func() int { return fuzzValue(rng, 64) }
+	x := func() int { return fuzzValue(rng, w) }
+	y := func() int { return fuzzValue(rng, h) }
+	hp := func() (int, int) { return fuzzValue(rng, 100), fuzzValue(rng, 100) }
+
+	gs := model.GameState{
+		Tick: tick,
+		Player: model.Player{
+			Cash:             fuzzValue(rng, 10000),
+			Resources:        fuzzValue(rng, 2000),
+			ResourceCapacity: fuzzValue(rng, 2000),
+			PowerProvided:    fuzzValue(rng, 300),
+			PowerDrained:     fuzzValue(rng, 300),
+			PowerState:       []string{"Normal", "Low", "Critical", ""}[rng.Intn(4)],
+		},
+		MapWidth:  w,
+		MapHeight: h,
+	}
+	for range rng.Intn(12) {
+		b := model.Building{ID: id(), Type: fuzzType(rng), X: x(), Y: y()}
+		b.HP, b.MaxHP = hp()
+		gs.Buildings = append(gs.Buildings, b)
+	}
+	for range rng.Intn(24) {
+		u := model.Unit{ID: id(), Type: fuzzType(rng), X: x(), Y: y(), Idle: rng.Intn(2) == 0, CargoCount: fuzzValue(rng, 5), Veterancy: fuzzValue(rng, 3)}
+		u.HP, u.MaxHP = hp()
+		gs.Units = append(gs.Units, u)
+	}
+	for range rng.Intn(24) {
+		en := model.Enemy{ID: id(), Owner: []string{"enemy", "other", ""}[rng.Intn(3)], Type: fuzzType(rng), X: x(), Y: y(), Veterancy: fuzzValue(rng, 3)}
+		en.HP, en.MaxHP = hp()
+		gs.Enemies = append(gs.Enemies, en)
+	}
+	for range rng.Intn(3) {
+		c := model.Enemy{ID: id(), Type: fuzzType(rng), X: x(), Y: y()}
+		c.HP, c.MaxHP = hp()
+		gs.Capturables = append(gs.Capturables, c)
+	}
+	for range rng.Intn(4) {
+		o := model.Obstacle{ID: id(), Type: fuzzType(rng), X: x(), Y: y()}
+		o.HP, o.MaxHP = hp()
+		gs.Obstacles = append(gs.Obstacles, o)
+	}
+	for range rng.Intn(5) {
+		q := model.ProductionQueue{
+			ActorID:         id(),
+			Type:            fuzzQueueTypes[rng.Intn(len(fuzzQueueTypes))],
+			Items:           fuzzTypeList(rng),
+			Buildable:       fuzzTypeList(rng),
+			CurrentProgress: fuzzValue(rng, 100),
+		}
+		if rng.Intn(2) == 0 {
+			q.CurrentItem = fuzzType(rng)
+		}
+		gs.ProductionQueues = append(gs.ProductionQueues, q)
+	}
+	for range rng.Intn(3) {
+		gs.SupportPowers = append(gs.SupportPowers, model.SupportPower{
+			Key: fuzzPowerKeys[rng.Intn(len(fuzzPowerKeys))], Ready: rng.Intn(2) == 0,
+			RemainingTicks: fuzzValue(rng, 1000), TotalTicks: fuzzValue(rng, 1000),
+		})
+	}
+	for range rng.Intn(2) {
+		gs.EnemySupportPowers = append(gs.EnemySupportPowers, model.EnemySupportPower{
+			Owner: "enemy", Key: fuzzPowerKeys[rng.Intn(len(fuzzPowerKeys))], Ready: rng.Intn(2) == 0,
+			RemainingTicks: fuzzValue(rng, 1000), TotalTicks: fuzzValue(rng, 1000),
+		})
+	}
+	for i := range rng.Intn(4) {
+		gs.SpawnPoints = append(gs.SpawnPoints, model.SpawnPoint{X: x(), Y: y(), Own: i == 0})
+	}
+	return gs
+}
+
+// randomTerrain returns nil or a grid of random terrain.
+func randomTerrain(rng *rand.Rand) *model.TerrainGrid {
+	if rng.Intn(3) == 0 {
+		return nil
+	}
+	g := &model.TerrainGrid{Cols: 32, Rows: 32, CellW: fuzzValue(rng, 4), CellH: fuzzValue(rng, 4), Grid: make([]model.TerrainType, 32*32)}
+	for i := range g.Grid {
+		g.Grid[i] = model.TerrainType(rng.Intn(4))
+	}
+	return g
+}
+
+// fuzzDoctrine decodes a doctrine from raw bytes. Weights land slightly
+// outside [0, 1] (and Forwardness outside [-1, 1]) so Validate's clamping
+// is exercised; missing bytes read as zero.
+func fuzzDoctrine(data []byte, opening string) Doctrine {
+	at := func(i int) byte {
+		if i < len(data) {
+			return data[i]
+		}
+		return 0
+	}
+	w := func(i int) float64 { return float64(at(i))/200 - 0.1 }
+	n := func(i int) int { return int(int8(at(i))) }
+	d := Doctrine{
+		Name:                      "fuzz",
+		EconomyPriority:           w(0),
+		Aggression:                w(1),
+		GroundDefensePriority:     w(2),
+		AirDefensePriority:        w(3),
+		TechPriority:              w(4),
+		InfantryWeight:            w(5),
+		VehicleWeight:             w(6),
+		AirWeight:                 w(7),
+		NavalWeight:               w(8),
+		ScoutPriority:             w(9),
+		SpecializedInfantryWeight: w(10),
+		SuperweaponPriority:       w(11),
+		CapturePriority:           w(12),
+		TransportAssault:          w(13),
+		Forwardness:               2*w(14) - 1,
+		GroundAttackGroupSize:     n(15),
+		AirAttackGroupSize:        n(16),
+		NavalAttackGroupSize:      n(17),
+		Opening:                   opening,
+	}
+	if at(18)%2 == 1 {
+		d.PreferredVehicle = []string{fuzzTypes[int(at(19))%len(fuzzTypes)]}
+	}
+	return d
+}
+
+// checkEvaluate runs states through an engine compiled from d and fails on
+// anything but a clean evaluation.
+func checkEvaluate(t *testing.T, d Doctrine, terrain *model.TerrainGrid, states []model.GameState) {
+	t.Helper()
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	engine, err := NewEngine(CompileDoctrineForMap(d, RA, terrain))
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	engine.SetSeed(1)
+	engine.SetDoctrine(d)
+	if terrain != nil {
+		engine.SetTerrain(terrain)
+	}
+	for _, gs := range states {
+		if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", gs.Tick, err)
+		}
+	}
+	for _, rec := range engine.EvalErrors() {
+		t.Errorf("rule %s: condition %q failed: %s", rec.Rule, rec.Condition, rec.LastError)
+	}
+	for name, n := range engine.panics {
+		t.Errorf("rule %s panicked %d times", name, n)
+	}
+}
+
+func FuzzDoctrine(f *testing.F) {
+	f.Add([]byte{}, "", int64(0))
+	f.Add([]byte{100, 100, 100, 60, 100, 100, 100, 20, 20, 100, 20, 20, 20, 20, 100, 5, 2, 3}, "", int64(1))
+	f.Add([]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 127, 127, 127, 1, 7}, "", int64(2))
+	f.Add([]byte{0, 255, 0, 0, 0, 0, 255, 0, 0, 0, 0, 0, 0, 255, 255, 128, 128, 128}, "", int64(3))
+	for i, name := range OpeningNames() {
+		f.Add([]byte{20, 200, 20, 20, 20, 200, 200, 100, 100, 20, 100, 200, 200, 100, 0}, name, int64(10+i))
+	}
+	f.Add([]byte{20, 200}, "no-such-opening", int64(4))
+
+	f.Fuzz(func(t *testing.T, data []byte, opening string, seed int64) {
+		rng := rand.New(rand.NewSource(seed))
+		states := make([]model.GameState, fuzzTicks)
+		for i := range states {
+			states[i] = randomState(rng, 1+i*rng.Intn(50))
+		}
+		checkEvaluate(t, fuzzDoctrine(data, opening), randomTerrain(rng), states)
+	})
+}
+
+func FuzzGameState(f *testing.F) {
+	for seed := range int64(16) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		rng := rand.New(rand.NewSource(seed))
+		states := make([]model.GameState, fuzzTicks)
+		for i := range states {
+			states[i] = randomState(rng, 1+i*rng.Intn(50))
+		}
+		checkEvaluate(t, DefaultDoctrine(), randomTerrain(rng), states)
+	})
+}
@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Gap generator placement. A gap generator shrouds the cells around it from
+// the enemy, so the useful spot is between our production core and the
+// direction the enemy looks from: the core stays inside the shroud and the
+// shroud reaches out toward the enemy. When the threat direction swings
+// round (a new enemy base found, say), a gap generator facing the old way
+// is sold and rebuilt facing the new one.
+
+const (
+	gapShroudRadius     = 6.0         // cells shrouded, RA's CreatesShroud range
+	gapReplaceAngle     = math.Pi / 3 // threat swing that makes a gap generator worth moving
+	gapRelocateCooldown = 3000        // ticks between relocations, so a wavering axis can't churn
+)
+
+// gapSpread offsets further gap generators to either side of the threat
+// axis so their shrouds overlap less.
+var gapSpread = []float64{0, math.Pi / 4, -math.Pi / 4, math.Pi / 2, -math.Pi / 2}
+
+// gapWatch remembers the threat direction each gap generator was built
+// facing.
+type gapWatch struct {
+	Facing    map[int]float64 // by actor ID, radians
+	Relocated int             // tick of the last sale; 0 if never
+}
+
+// productionCore returns the centroid of the construction yard and
+// production buildings, or of the whole base when there are none.
+func (e RuleEnv) productionCore() (x, y int) {
+	n := 0
+	for _, b := range e.State.Buildings {
+		if !matchesType(b.Type, ConstructionYard) && !isProductionBuilding(b.Type) {
+			continue
+		}
+		x += b.X
+		y += b.Y
+		n++
+	}
+	if n == 0 {
+		return e.BuildingCentroid()
+	}
+	return x / n, y / n
+}
+
+// isProductionBuilding reports whether t belongs in the production zone.
+func isProductionBuilding(t string) bool {
+	for k, z := range zoneForType {
+		if z == zoneProduction && matchesType(t, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// threatFacing returns the angle of the threat axis from the production
+// core; ok is false when there is no direction to face.
+func (e RuleEnv) threatFacing() (angle float64, ok bool) {
+	cx, cy := e.productionCore()
+	ax, ay := e.threatAxis(cx, cy)
+	if ax == 0 && ay == 0 {
+		return 0, false
+	}
+	return math.Atan2(ay, ax), true
+}
+
+func (e RuleEnv) gapGenerators() []model.Building {
+	var out []model.Building
+	for _, b := range e.State.Buildings {
+		if e.inRoles(b.Type, []string{"gap_generator"}) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// gapHint returns where to place a gap generator: half its shroud radius
+// out from the production core toward the threat. With that spot already
+// under another gap generator's shroud, it swings to the side and out to
+// the full radius.
+func gapHint(env RuleEnv) (x, y int, ok bool) {
+	if len(env.State.Buildings) == 0 {
+		return 0, 0, false
+	}
+	facing, ok := env.threatFacing()
+	if !ok {
+		return 0, 0, false
+	}
+	cx, cy := env.productionCore()
+	gaps := env.gapGenerators()
+	for _, r := range []float64{gapShroudRadius / 2, gapShroudRadius} {
+		for _, off := range gapSpread {
+			a := facing + off
+			px := cx + int(math.Round(r*math.Cos(a)))
+			py := cy + int(math.Round(r*math.Sin(a)))
+			if !env.aaPlaceable(px, py) {
+				continue
+			}
+			shrouded := false
+			for _, g := range gaps {
+				if math.Hypot(float64(g.X-px), float64(g.Y-py)) < gapShroudRadius {
+					shrouded = true
+					break
+				}
+			}
+			if !shrouded {
+				return px, py, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// angleBetween returns the absolute difference of two angles, in [0, π].
+func angleBetween(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 2*math.Pi)
+	if d > math.Pi {
+		d = 2*math.Pi - d
+	}
+	return d
+}
+
+// updateGapWatch records the facing of new gap generators and forgets
+// gone ones.
+func updateGapWatch(env RuleEnv) {
+	w, _ := gapWatchMemory.get(env.Memory)
+	if w == nil {
+		w = &gapWatch{}
+	}
+	facing, ok := env.threatFacing()
+	seen := make(map[int]float64)
+	for _, g := range env.gapGenerators() {
+		if f, known := w.Facing[g.ID]; known {
+			seen[g.ID] = f
+		} else if ok {
+			seen[g.ID] = facing
+		}
+	}
+	w.Facing = seen
+	gapWatchMemory.set(env.Memory, w)
+}
+
+// misplacedGap returns the gap generator facing furthest from the current
+// threat, if it is off by more than gapReplaceAngle and no relocation
+// happened within gapRelocateCooldown.
+func (e RuleEnv) misplacedGap() *model.Building {
+	w, _ := gapWatchMemory.get(e.Memory)
+	if w == nil || (w.Relocated > 0 && e.State.Tick-w.Relocated < gapRelocateCooldown) {
+		return nil
+	}
+	facing, ok := e.threatFacing()
+	if !ok {
+		return nil
+	}
+	var worst *model.Building
+	worstOff := gapReplaceAngle
+	for _, g := range e.gapGenerators() {
+		f, known := w.Facing[g.ID]
+		if !known {
+			continue
+		}
+		if off := angleBetween(f, facing); off > worstOff {
+			worstOff = off
+			worst = &g
+		}
+	}
+	return worst
+}
+
+// GapGeneratorMisplaced reports whether a gap generator faces away from
+// where the threat now comes from.
+func (e RuleEnv) GapGeneratorMisplaced() bool { return e.misplacedGap() != nil }
+
+// ActionRelocateGapGenerator sells the worst-facing gap generator; the
+// build rule then replaces it and gapHint places it facing the threat.
+func ActionRelocateGapGenerator(env RuleEnv, conn *ipc.Connection) error {
+	g := env.misplacedGap()
+	if g == nil {
+		return nil
+	}
+	w, _ := gapWatchMemory.get(env.Memory)
+	w.Relocated = env.State.Tick
+	slog.Info("selling gap generator facing the old threat direction", "id", g.ID, "x", g.X, "y", g.Y)
+	return conn.Send(ipc.TypeSellBuilding, ipc.SellBuildingCommand{ActorID: uint32(g.ID)})
+}
@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestGapHintFacesThreat(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth: 128, MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 30, Y: 28},
+				{ID: 2, Type: "weap", X: 34, Y: 32},
+				{ID: 3, Type: "powr", X: 20, Y: 30},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	// Enemy due east of the production core (32,30).
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"red": {Owner: "red", X: 100, Y: 30}})
+
+	x, y, ok := gapHint(env)
+	if !ok || x != 32+int(gapShroudRadius/2) || y != 30 {
+		t.Fatalf("gapHint = %d,%d,%v; want 3 cells east of the core at 35,30", x, y, ok)
+	}
+
+	// A second gap generator swings to the side of the first.
+	env.State.Buildings = append(env.State.Buildings, model.Building{ID: 4, Type: "gap", X: x, Y: y})
+	x2, y2, ok := gapHint(env)
+	if !ok || math.Hypot(float64(x2-x), float64(y2-y)) < gapShroudRadius {
+		t.Errorf("second gapHint = %d,%d,%v; want outside the first one's shroud", x2, y2, ok)
+	}
+}
+
+func TestGapGeneratorRelocatedWhenThreatSwings(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := RuleEnv{
+		State: model.GameState{
+			Tick: 100, MapWidth: 128, MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 30, Y: 30},
+				{ID: 4, Type: "gap", X: 33, Y: 30},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"red": {Owner: "red", X: 100, Y: 30}})
+	updateGapWatch(env)
+	if env.GapGeneratorMisplaced() {
+		t.Fatal("gap generator misplaced right after being built")
+	}
+
+	// The enemy turns out to be south instead.
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"red": {Owner: "red", X: 30, Y: 100}})
+	env.State.Tick = 200
+	updateGapWatch(env)
+	if !env.GapGeneratorMisplaced() {
+		t.Fatal("gap generator facing east not flagged after the threat moved south")
+	}
+	if err := ActionRelocateGapGenerator(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if env.GapGeneratorMisplaced() {
+		t.Error("relocation repeated within the cooldown")
+	}
+}
+
+func TestAngleBetween(t *testing.T) {
+	if d := angleBetween(3, -3); math.Abs(d-(2*math.Pi-6)) > 1e-9 {
+		t.Errorf("angleBetween(3, -3) = %v, want the short way round", d)
+	}
+}
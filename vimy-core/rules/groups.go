@@ -0,0 +1,62 @@
+package rules
+
+import "slices"
+
+// Rule groups. Related rules — the engineer capture chain, say — are tagged
+// with a group so an operator can switch the whole subsystem off and on at
+// runtime, and a test can run it in an engine on its own. A group owns the
+// memory sections only its rules use (see ownedBy); disabling the group
+// clears them, so re-enabling it starts the subsystem afresh rather than
+// from whatever it was doing when it was switched off.
+
+// Rule group names.
+const (
+	GroupEngineerCapture = "engineer-capture" // engineers, their APCs, and the captures they make
+)
+
+// ruleGroups lists every group the compiler can emit.
+var ruleGroups = []string{GroupEngineerCapture}
+
+// RuleGroup is a named set of related rules compiled as a unit.
+type RuleGroup struct {
+	Name  string
+	Rules []*Rule
+}
+
+// GroupSummary is a read-only DTO describing a rule group for the dashboard.
+type GroupSummary struct {
+	Name    string
+	Rules   int // rules of the group in the active rule set
+	Enabled bool
+}
+
+// addGroup tags g's rules with the group and adds them to the rule set.
+func (c *doctrineCompiler) addGroup(g RuleGroup) {
+	for _, r := range g.Rules {
+		r.Group = g.Name
+	}
+	c.rules = append(c.rules, g.Rules...)
+}
+
+// RulesInGroup returns the rules of rs that belong to the named group.
+func RulesInGroup(rs []*Rule, name string) []*Rule {
+	var out []*Rule
+	for _, r := range rs {
+		if r.Group == name {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// groupMemory returns the keys of the memory sections the group owns.
+func groupMemory(name string) []string {
+	var keys []string
+	for key, s := range memorySections {
+		if s.group == name {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
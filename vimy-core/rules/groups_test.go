@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCaptureChainCompiledAsGroup(t *testing.T) {
+	d := DefaultDoctrine()
+	d.CapturePriority = 0.8
+	group := RulesInGroup(CompileDoctrine(d), GroupEngineerCapture)
+	want := []string{
+		"capture-building", "produce-engineer", "produce-apc", "load-engineer-into-apc",
+		"deliver-apc-to-target", "capture-enemy-production", "produce-assault-engineer",
+	}
+	if len(group) != len(want) {
+		t.Fatalf("engineer-capture group has %d rules, want %d", len(group), len(want))
+	}
+	for _, name := range want {
+		if findRule(group, name) == nil {
+			t.Errorf("engineer-capture group missing %q", name)
+		}
+	}
+
+	// The group runs in an engine of its own.
+	if _, err := NewEngine(group); err != nil {
+		t.Fatalf("NewEngine(group): %v", err)
+	}
+}
+
+func TestDisableRuleGroup(t *testing.T) {
+	calls := map[string]int{}
+	rule := func(name, group string) *Rule {
+		return &Rule{
+			Name: name, Priority: 10, Category: name, Group: group, ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				calls[name]++
+				return nil
+			},
+		}
+	}
+	engine, err := NewEngine([]*Rule{rule("capture", GroupEngineerCapture), rule("other", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureHoldMemory.set(engine.Memory, &captureHold{EnemyID: 5})
+
+	if err := engine.SetGroupEnabled(GroupEngineerCapture, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := captureHoldMemory.get(engine.Memory); ok {
+		t.Error("disabling the group kept its memory")
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 1}, "soviet", nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls["capture"] != 0 || calls["other"] != 1 {
+		t.Errorf("with the group disabled ran %v, want only other", calls)
+	}
+	if g := engine.Groups(); len(g) != 1 || g[0].Enabled || g[0].Rules != 1 {
+		t.Errorf("Groups() = %+v, want engineer-capture disabled with 1 rule", g)
+	}
+
+	// Disabling survives a swap; enabling brings the rules back.
+	if err := engine.Swap([]*Rule{rule("capture", GroupEngineerCapture), rule("other", "")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 2}, "soviet", nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls["capture"] != 0 {
+		t.Error("disabled group ran after a swap")
+	}
+	if err := engine.SetGroupEnabled(GroupEngineerCapture, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 3}, "soviet", nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls["capture"] != 1 {
+		t.Errorf("re-enabled group ran %d times, want 1", calls["capture"])
+	}
+
+	if err := engine.SetGroupEnabled("no-such-group", false); err == nil {
+		t.Error("unknown group accepted")
+	}
+}
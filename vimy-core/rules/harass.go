@@ -0,0 +1,191 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Harvester harassment. Harvesters spend most of their time out of sight on
+// ore fields, so a raid needs somewhere to go when none is visible: the
+// last places harvesters were seen, then the ore field each enemy refinery
+// is presumably mining. harassTarget ranks those; raider squads always use
+// it, and aggressive doctrines send the main force after it too instead of
+// marching on a base it can't see.
+
+const (
+	harvesterSightingTTL = 1500 // ticks a harvester sighting stays worth checking
+	harassOreOffset      = 5    // cells past a refinery, away from its base, to look for ore
+	maxHarvesterSighting = 16
+)
+
+// harvesterSighting is where an enemy harvester was last seen.
+type harvesterSighting struct {
+	X, Y int
+	Tick int
+}
+
+// harvesterIntel is what we know of the enemy harvesters' whereabouts.
+type harvesterIntel struct {
+	Sightings map[int]*harvesterSighting // by actor ID
+	// Checked holds predicted ore fields we have looked at and found
+	// empty, with the tick; they are skipped until harvesterSightingTTL
+	// passes, so a squad doesn't keep returning to a dry guess. Keyed by
+	// oreFieldKey, so the intel survives a checkpoint.
+	Checked map[string]int
+}
+
+// oreFieldKey is a predicted ore field's key in harvesterIntel.Checked.
+func oreFieldKey(f [2]int) string {
+	return strconv.Itoa(f[0]) + "," + strconv.Itoa(f[1])
+}
+
+func getHarvesterIntel(memory map[string]any) *harvesterIntel {
+	if v, ok := enemyHarvestersMemory.get(memory); ok && v != nil {
+		return v
+	}
+	return &harvesterIntel{Sightings: make(map[int]*harvesterSighting), Checked: make(map[string]int)}
+}
+
+// updateEnemyHarvesters remembers visible enemy harvesters, forgets
+// sightings that have gone stale or whose spot we can see is empty, and
+// notes predicted ore fields we can see with no harvester on them.
+func updateEnemyHarvesters(env RuleEnv) {
+	intel := getHarvesterIntel(env.Memory)
+	seen := intel.Sightings
+	visible := make(map[int]bool)
+	var harvesters []model.Enemy
+	for _, en := range env.State.Enemies {
+		if matchesType(en.Type, Harvester) {
+			visible[en.ID] = true
+			harvesters = append(harvesters, en)
+			seen[en.ID] = &harvesterSighting{X: en.X, Y: en.Y, Tick: env.State.Tick}
+		}
+	}
+	for id, s := range seen {
+		if visible[id] {
+			continue
+		}
+		if env.State.Tick-s.Tick > harvesterSightingTTL || siteObserved(env, s.X, s.Y) {
+			delete(seen, id)
+		}
+	}
+	// Keep the freshest sightings only.
+	for len(seen) > maxHarvesterSighting {
+		oldest, oldestTick := 0, math.MaxInt
+		for id, s := range seen {
+			if s.Tick < oldestTick {
+				oldest, oldestTick = id, s.Tick
+			}
+		}
+		delete(seen, oldest)
+	}
+
+	for f, tick := range intel.Checked {
+		if env.State.Tick-tick > harvesterSightingTTL {
+			delete(intel.Checked, f)
+		}
+	}
+	for _, f := range env.predictedOreFields() {
+		if !siteObserved(env, f[0], f[1]) {
+			continue
+		}
+		occupied := slices.ContainsFunc(harvesters, func(h model.Enemy) bool {
+			return math.Hypot(float64(h.X-f[0]), float64(h.Y-f[1])) <= siloSightRadius
+		})
+		if !occupied {
+			intel.Checked[oreFieldKey(f)] = env.State.Tick
+		}
+	}
+	enemyHarvestersMemory.set(env.Memory, intel)
+}
+
+// predictedOreFields guesses where each known enemy refinery's ore lies:
+// refineries are built beside the ore, on the side away from the base.
+// Fields recently checked and found empty are left out.
+func (e RuleEnv) predictedOreFields() [][2]int {
+	bases := GetEnemyBases(e.Memory)
+	var out [][2]int
+	for _, s := range GetEnemyStructures(e.Memory) {
+		if s.Destroyed || !matchesType(s.Type, Refinery) {
+			continue
+		}
+		x, y := s.X, s.Y
+		if base, ok := bases[s.Owner]; ok {
+			dx, dy := float64(s.X-base.X), float64(s.Y-base.Y)
+			if d := math.Hypot(dx, dy); d > 0 {
+				x += int(math.Round(dx / d * harassOreOffset))
+				y += int(math.Round(dy / d * harassOreOffset))
+			}
+		}
+		if e.State.MapWidth > 0 && e.State.MapHeight > 0 {
+			x = max(0, min(x, e.State.MapWidth-1))
+			y = max(0, min(y, e.State.MapHeight-1))
+		}
+		out = append(out, [2]int{x, y})
+	}
+	if intel, ok := enemyHarvestersMemory.get(e.Memory); ok && intel != nil {
+		out = slices.DeleteFunc(out, func(f [2]int) bool {
+			_, checked := intel.Checked[oreFieldKey(f)]
+			return checked
+		})
+	}
+	return out
+}
+
+// harassTarget returns the enemy harvester nearest (x, y) if one is in
+// sight; else the nearest remembered sighting; else the nearest predicted
+// ore field. nil when the enemy economy hasn't been found.
+func (e RuleEnv) harassTarget(x, y int) *squadTarget {
+	isHarvester := func(t string) bool { return matchesType(t, Harvester) }
+	if t := e.nearestTarget(x, y, isHarvester, func(string) bool { return false }); t != nil {
+		return t
+	}
+
+	var best *squadTarget
+	bestDist := math.MaxFloat64
+	consider := func(tx, ty int) {
+		if d := math.Hypot(float64(tx-x), float64(ty-y)); d < bestDist {
+			bestDist = d
+			best = &squadTarget{X: tx, Y: ty}
+		}
+	}
+	for _, s := range getHarvesterIntel(e.Memory).Sightings {
+		consider(s.X, s.Y)
+	}
+	if best != nil {
+		return best
+	}
+	for _, f := range e.predictedOreFields() {
+		consider(f[0], f[1])
+	}
+	return best
+}
+
+// HasHarassTarget reports whether the named squad has an enemy harvester,
+// sighting or ore field to go after.
+func (e RuleEnv) HasHarassTarget(name string) bool {
+	x, y, ok := e.squadPosition(name)
+	return ok && e.harassTarget(x, y) != nil
+}
+
+// SquadHarass sends the named squad's idle members at the enemy economy.
+func SquadHarass(name string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		x, y, ok := env.squadPosition(name)
+		if !ok {
+			return nil
+		}
+		t := env.harassTarget(x, y)
+		ids := squadIdleActorIDs(env, name)
+		if t == nil || len(ids) == 0 {
+			return nil
+		}
+		slog.Debug("squad harassing enemy economy", "squad", name, "count", len(ids), "target", t.ID, "x", t.X, "y", t.Y)
+		return sendAtTarget(conn, ids, t)
+	}
+}
@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestHarassTargetFallbacks(t *testing.T) {
+	env := RuleEnv{
+		State:  model.GameState{Tick: 100, MapWidth: 128, MapHeight: 128},
+		Memory: make(map[string]any),
+	}
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"red": {Owner: "red", X: 100, Y: 100}})
+	enemyStructuresMemory.set(env.Memory, map[int]*EnemyStructure{
+		50: {ID: 50, Owner: "red", Type: "proc", X: 90, Y: 100},
+	})
+	updateEnemyHarvesters(env)
+
+	// Only the refinery is known: its ore lies on the far side from the base.
+	if tgt := env.harassTarget(10, 10); tgt == nil || tgt.X != 90-harassOreOffset || tgt.Y != 100 {
+		t.Fatalf("harassTarget = %+v, want the predicted field at %d,100", tgt, 90-harassOreOffset)
+	}
+
+	// A harvester is spotted, then drops out of sight: the sighting wins.
+	env.State.Enemies = []model.Enemy{{ID: 71, Owner: "red", Type: "harv", X: 70, Y: 60}}
+	updateEnemyHarvesters(env)
+	if tgt := env.harassTarget(10, 10); tgt == nil || tgt.ID != 71 {
+		t.Fatalf("harassTarget = %+v, want the visible harvester", tgt)
+	}
+	env.State.Enemies = nil
+	env.State.Tick = 200
+	updateEnemyHarvesters(env)
+	if tgt := env.harassTarget(10, 10); tgt == nil || tgt.ID != 0 || tgt.X != 70 || tgt.Y != 60 {
+		t.Fatalf("harassTarget = %+v, want the sighting at 70,60", tgt)
+	}
+
+	// We look at the sighting and the predicted field: both are empty.
+	env.State.Units = []model.Unit{
+		{ID: 1, Type: "1tnk", X: 70, Y: 60},
+		{ID: 2, Type: "1tnk", X: 85, Y: 100},
+	}
+	updateEnemyHarvesters(env)
+	if tgt := env.harassTarget(10, 10); tgt != nil {
+		t.Errorf("harassTarget = %+v after checking every lead, want nil", tgt)
+	}
+
+	// Long after, the field is worth another look.
+	env.State.Units = nil
+	env.State.Tick = 200 + harvesterSightingTTL + 1
+	updateEnemyHarvesters(env)
+	if env.harassTarget(10, 10) == nil {
+		t.Error("checked field never reconsidered")
+	}
+}
+
+func TestCompileHarassEconomy(t *testing.T) {
+	d := Doctrine{
+		Name: "Raid", EconomyPriority: 0.4, Aggression: 0.8, InfantryWeight: 0.6,
+		GroundAttackGroupSize: 5, AirAttackGroupSize: 2, NavalAttackGroupSize: 3,
+	}
+	rules := CompileDoctrine(d)
+	if findRule(rules, "squad-harass-economy") == nil {
+		t.Fatal("expected squad-harass-economy for an aggressive doctrine")
+	}
+	if r := findRule(rules, "squad-attack-known-base"); r == nil || !strings.Contains(r.ConditionSrc, "!HasHarassTarget") {
+		t.Error("known-base attack should yield to economic harassment")
+	}
+
+	d.Aggression = 0.4
+	if findRule(CompileDoctrine(d), "squad-harass-economy") != nil {
+		t.Error("unexpected squad-harass-economy for a measured doctrine")
+	}
+}
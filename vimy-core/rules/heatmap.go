@@ -0,0 +1,270 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Threat heatmap tuning. Heat accumulates where enemy combat units and
+// defenses are seen and where our units die, and halves every
+// threatHalfLifeTicks so old fights fade but repeat hotspots stay hot.
+const (
+	threatGrid           = 32   // zones per side without a terrain grid
+	threatHalfLifeTicks  = 3000 // 2 minutes
+	threatSightingWeight = 1.0  // per enemy unit per second in view
+	threatDefenseWeight  = 2.0  // per enemy defense per second in view
+	threatLossWeight     = 5.0  // per unit of ours lost
+	threatHotLevel       = 5.0  // heat at which a zone counts as dangerous
+	threatMaxStepTicks   = 50   // cap on one update's accumulation after a stall
+	threatDetourFactor   = 0.5  // a detour must carry under this share of the direct route's heat
+)
+
+// threatMap is the decaying heat grid. Tick is the last update, used to
+// apply decay and scale accumulation by elapsed time.
+type threatMap struct {
+	Cols, Rows   int
+	CellW, CellH int
+	Heat         []float64
+	Tick         int
+}
+
+func getThreatMap(memory map[string]any) *threatMap {
+	if v, ok := threatHeatMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+func getLastUnitPositions(memory map[string]any) map[int][2]int {
+	if v, ok := threatUnitPosMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int][2]int)
+}
+
+func newThreatMap(mapW, mapH int, terrain *model.TerrainGrid) *threatMap {
+	m := &threatMap{Cols: threatGrid, Rows: threatGrid}
+	if terrain != nil && terrain.Cols > 0 && terrain.Rows > 0 && terrain.CellW > 0 && terrain.CellH > 0 {
+		m.Cols, m.Rows, m.CellW, m.CellH = terrain.Cols, terrain.Rows, terrain.CellW, terrain.CellH
+	} else {
+		m.CellW = max(1, (mapW+m.Cols-1)/m.Cols)
+		m.CellH = max(1, (mapH+m.Rows-1)/m.Rows)
+	}
+	m.Heat = make([]float64, m.Cols*m.Rows)
+	return m
+}
+
+func (m *threatMap) index(x, y int) (int, bool) {
+	col, row := x/m.CellW, y/m.CellH
+	if x < 0 || y < 0 || col >= m.Cols || row >= m.Rows {
+		return 0, false
+	}
+	return row*m.Cols + col, true
+}
+
+func (m *threatMap) add(x, y int, heat float64) {
+	if i, ok := m.index(x, y); ok {
+		m.Heat[i] += heat
+	}
+}
+
+func (m *threatMap) at(x, y int) float64 {
+	if i, ok := m.index(x, y); ok {
+		return m.Heat[i]
+	}
+	return 0
+}
+
+// updateThreatHeat decays the heatmap, adds heat for visible enemy combat
+// units and defenses, and adds a spike where any of our units vanished since
+// the last update.
+func updateThreatHeat(env RuleEnv) {
+	if env.State.MapWidth == 0 || env.State.MapHeight == 0 {
+		return
+	}
+	m := getThreatMap(env.Memory)
+	if m == nil {
+		m = newThreatMap(env.State.MapWidth, env.State.MapHeight, env.Terrain)
+		m.Tick = env.State.Tick
+		threatHeatMemory.set(env.Memory, m)
+	}
+
+	dt := env.State.Tick - m.Tick
+	if dt > 0 {
+		decay := math.Pow(0.5, float64(dt)/threatHalfLifeTicks)
+		for i := range m.Heat {
+			m.Heat[i] *= decay
+		}
+	}
+	m.Tick = env.State.Tick
+
+	secs := float64(min(max(dt, 0), threatMaxStepTicks)) / ticksPerSecond
+	for _, en := range env.State.Enemies {
+		switch {
+		case isDefenseType(en.Type):
+			m.add(en.X, en.Y, threatDefenseWeight*secs)
+		case IsKnownBuildingType(en.Type) || matchesType(en.Type, Harvester) || matchesType(en.Type, MCV):
+			// non-combat: no threat
+		default:
+			m.add(en.X, en.Y, threatSightingWeight*secs)
+		}
+	}
+
+	last := getLastUnitPositions(env.Memory)
+	cur := make(map[int][2]int, len(env.State.Units))
+	for _, u := range env.State.Units {
+		cur[u.ID] = [2]int{u.X, u.Y}
+	}
+	for id, p := range last {
+		if _, alive := cur[id]; !alive {
+			m.add(p[0], p[1], threatLossWeight)
+		}
+	}
+	threatUnitPosMemory.set(env.Memory, cur)
+}
+
+// isDefenseType reports whether an enemy type is a static ground defense.
+func isDefenseType(t string) bool {
+	for _, role := range defenseRoles {
+		for _, dt := range roles[role].types {
+			if matchesType(t, dt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ThreatAt returns the accumulated threat heat at (x, y).
+func (e RuleEnv) ThreatAt(x, y int) float64 {
+	if m := getThreatMap(e.Memory); m != nil {
+		return m.at(x, y)
+	}
+	return 0
+}
+
+// IsDangerous reports whether (x, y) lies in a zone hot enough to avoid.
+func (e RuleEnv) IsDangerous(x, y int) bool { return e.ThreatAt(x, y) >= threatHotLevel }
+
+// routeHeat sums heat along the straight line from (x0, y0) to (x1, y1),
+// counting each heat zone crossed once. Samples are half a zone apart so a
+// diagonal can't step over a zone.
+func (m *threatMap) routeHeat(x0, y0, x1, y1 int) float64 {
+	dist := math.Hypot(float64(x1-x0), float64(y1-y0))
+	step := math.Max(0.5, float64(min(m.CellW, m.CellH))/2)
+	n := max(1, int(math.Ceil(dist/step)))
+	total := 0.0
+	last := -1
+	for i := 0; i <= n; i++ {
+		f := float64(i) / float64(n)
+		idx, ok := m.index(x0+int(math.Round(f*float64(x1-x0))), y0+int(math.Round(f*float64(y1-y0))))
+		if ok && idx != last {
+			total += m.Heat[idx]
+			last = idx
+		}
+	}
+	return total
+}
+
+// detourWaypoint returns an intermediate point that steers a trip from
+// (x0, y0) to (x1, y1) around hot zones. ok is false when the direct route
+// is cool enough or no sideways detour is meaningfully cooler. Candidates
+// sit off the midpoint, perpendicular to the route, at a third and a half of
+// the trip length on either side.
+func (e RuleEnv) detourWaypoint(x0, y0, x1, y1 int) (wx, wy int, ok bool) {
+	m := getThreatMap(e.Memory)
+	if m == nil {
+		return 0, 0, false
+	}
+	direct := m.routeHeat(x0, y0, x1, y1)
+	if direct < threatHotLevel {
+		return 0, 0, false
+	}
+	dx, dy := float64(x1-x0), float64(y1-y0)
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		return 0, 0, false
+	}
+	px, py := -dy/dist, dx/dist
+	mx, my := float64(x0+x1)/2, float64(y0+y1)/2
+
+	best := direct * threatDetourFactor
+	for _, side := range []float64{1, -1} {
+		for _, frac := range []float64{1.0 / 3, 0.5} {
+			cx := int(math.Round(mx + side*px*dist*frac))
+			cy := int(math.Round(my + side*py*dist*frac))
+			if cx < 0 || cy < 0 || cx >= e.State.MapWidth || cy >= e.State.MapHeight {
+				continue
+			}
+			if e.Terrain != nil {
+				if t := e.Terrain.AtMapPos(cx, cy); t != model.Land && t != model.Bridge {
+					continue
+				}
+			}
+			if h := m.routeHeat(x0, y0, cx, cy) + m.routeHeat(cx, cy, x1, y1); h < best {
+				best, wx, wy, ok = h, cx, cy, true
+			}
+		}
+	}
+	return wx, wy, ok
+}
+
+// coolerSpot returns (x, y), or when that point is dangerous, the coolest of
+// its rotations about (cx, cy) — so a building zone that keeps getting hit
+// swings round the base instead of being rebuilt into the same fire.
+func (e RuleEnv) coolerSpot(cx, cy, x, y int) (int, int) {
+	m := getThreatMap(e.Memory)
+	if m == nil || m.at(x, y) < threatHotLevel {
+		return x, y
+	}
+	ox, oy := float64(x-cx), float64(y-cy)
+	bx, by, best := x, y, m.at(x, y)
+	for _, deg := range []float64{45, -45, 90, -90, 180} {
+		rad := deg * math.Pi / 180
+		rx := cx + int(math.Round(ox*math.Cos(rad)-oy*math.Sin(rad)))
+		ry := cy + int(math.Round(ox*math.Sin(rad)+oy*math.Cos(rad)))
+		if rx < 0 || ry < 0 || rx >= e.State.MapWidth || ry >= e.State.MapHeight {
+			continue
+		}
+		if h := m.at(rx, ry); h < best {
+			bx, by, best = rx, ry, h
+		}
+	}
+	return bx, by
+}
+
+// actorCentroid returns the mean position of our units among ids and how
+// many were found.
+func (e RuleEnv) actorCentroid(ids []uint32) (x, y, n int) {
+	units := e.units()
+	for _, id := range ids {
+		if u, ok := units.Get(int(id)); ok {
+			x += u.X
+			y += u.Y
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return x / n, y / n, n
+}
+
+// routedAttackMove attack-moves the given actors to (x, y), going by way of
+// a detour waypoint when their direct route crosses known hot zones. The
+// final leg is queued behind the detour so the group doesn't stop halfway.
+func routedAttackMove(env RuleEnv, conn *ipc.Connection, ids []uint32, x, y int) error {
+	if sx, sy, n := env.actorCentroid(ids); n > 0 {
+		if wx, wy, ok := env.detourWaypoint(sx, sy, x, y); ok {
+			slog.Debug("routing attack around threat", "count", n, "via_x", wx, "via_y", wy, "x", x, "y", y)
+			if err := conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{ActorIDs: ids, X: wx, Y: wy}); err != nil {
+				return err
+			}
+			return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{ActorIDs: ids, X: x, Y: y, Queued: true})
+		}
+	}
+	return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{ActorIDs: ids, X: x, Y: y})
+}
@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestThreatHeatAccumulatesAndDecays(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      0,
+			MapWidth:  64,
+			MapHeight: 64,
+			Units:     []model.Unit{{ID: 1, Type: "1tnk", X: 10, Y: 10}},
+		},
+		Memory: mem,
+	}
+	updateThreatHeat(env)
+
+	// One second of a tank in view plus our tank dying.
+	env.State.Tick = ticksPerSecond
+	env.State.Units = nil
+	env.State.Enemies = []model.Enemy{{ID: 50, Type: "3tnk", X: 40, Y: 40}}
+	updateThreatHeat(env)
+
+	if got := env.ThreatAt(10, 10); got != threatLossWeight {
+		t.Errorf("heat at loss site = %.2f, want %.2f", got, threatLossWeight)
+	}
+	if got := env.ThreatAt(40, 40); math.Abs(got-threatSightingWeight) > 1e-9 {
+		t.Errorf("heat at sighting = %.2f, want %.2f", got, threatSightingWeight)
+	}
+	if !env.IsDangerous(10, 10) {
+		t.Error("loss site should be dangerous")
+	}
+
+	env.State.Tick += threatHalfLifeTicks
+	env.State.Enemies = nil
+	updateThreatHeat(env)
+	if got := env.ThreatAt(10, 10); math.Abs(got-threatLossWeight/2) > 1e-9 {
+		t.Errorf("heat after one half-life = %.2f, want %.2f", got, threatLossWeight/2)
+	}
+}
+
+func TestDetourWaypointAvoidsHotZone(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State:  model.GameState{MapWidth: 64, MapHeight: 64},
+		Memory: mem,
+	}
+	m := newThreatMap(64, 64, nil)
+	m.add(32, 32, 10*threatHotLevel)
+	mem["threatHeat"] = m
+
+	wx, wy, ok := env.detourWaypoint(5, 32, 60, 32)
+	if !ok {
+		t.Fatal("expected a detour around the hot centre")
+	}
+	if wy == 32 || wx < 5 || wx > 60 {
+		t.Errorf("detour (%d, %d) doesn't leave the direct line", wx, wy)
+	}
+
+	if _, _, ok := env.detourWaypoint(5, 5, 60, 5); ok {
+		t.Error("cool route should not detour")
+	}
+}
+
+func TestCoolerSpotRotatesAwayFromHeat(t *testing.T) {
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State:  model.GameState{MapWidth: 64, MapHeight: 64},
+		Memory: mem,
+	}
+	m := newThreatMap(64, 64, nil)
+	m.add(40, 30, 2*threatHotLevel)
+	mem["threatHeat"] = m
+
+	x, y := env.coolerSpot(30, 30, 40, 30)
+	if env.IsDangerous(x, y) {
+		t.Errorf("coolerSpot returned hot point (%d, %d)", x, y)
+	}
+	if x, y := env.coolerSpot(30, 30, 20, 30); x != 20 || y != 30 {
+		t.Errorf("cool point moved to (%d, %d)", x, y)
+	}
+}
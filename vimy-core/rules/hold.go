@@ -0,0 +1,63 @@
+package rules
+
+// Decision hysteresis. A rule with HoldTicks keeps the units its action
+// orders for that long: every other rule's idle-unit selectors skip them
+// (see doNotTask), so a unit scrambled to defend the base isn't sent off to
+// scout or attack the first tick it goes idle, then called back the next.
+// The holding rule itself may keep re-ordering them.
+
+// unitHold is one unit's hold: the rule that ordered it and the tick the
+// hold lapses.
+type unitHold struct {
+	Rule  string
+	Until int
+}
+
+func getUnitHolds(memory map[string]any) map[int]unitHold {
+	if v, ok := unitHoldsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]unitHold)
+}
+
+// holdUnits holds ids for the running rule's HoldTicks. It does nothing
+// outside Evaluate or for rules without a hold.
+func (e RuleEnv) holdUnits(ids []uint32) {
+	if e.rule == nil || e.rule.HoldTicks <= 0 || len(ids) == 0 {
+		return
+	}
+	holds := getUnitHolds(e.Memory)
+	for _, id := range ids {
+		holds[int(id)] = unitHold{Rule: e.rule.Name, Until: e.State.Tick + e.rule.HoldTicks}
+	}
+	unitHoldsMemory.set(e.Memory, holds)
+}
+
+// heldElsewhere reports whether a rule other than the running one holds
+// the unit.
+func (e RuleEnv) heldElsewhere(holds map[int]unitHold, id int) bool {
+	h, ok := holds[id]
+	if !ok || h.Until <= e.State.Tick {
+		return false
+	}
+	return e.rule == nil || h.Rule != e.rule.Name
+}
+
+// updateUnitHolds drops lapsed holds and holds on dead units.
+func updateUnitHolds(env RuleEnv) {
+	holds := getUnitHolds(env.Memory)
+	if len(holds) == 0 {
+		return
+	}
+	units := env.units()
+	for id, h := range holds {
+		if !units.Has(id) || h.Until <= env.State.Tick {
+			delete(holds, id)
+		}
+	}
+	if len(holds) == 0 {
+		unitHoldsMemory.clear(env.Memory)
+		return
+	}
+	unitHoldsMemory.set(env.Memory, holds)
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestHeldUnitsSkippedByOtherRules(t *testing.T) {
+	var holderSaw, otherSaw []int
+	rules := []*Rule{
+		{
+			Name: "holder", Priority: 100, Category: "a", HoldTicks: 10, ConditionSrc: "State.Tick in [0, 5]",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				var ids []uint32
+				for _, u := range env.IdleGroundUnits() {
+					ids = append(ids, uint32(u.ID))
+				}
+				holderSaw = append(holderSaw, len(ids))
+				env.holdUnits(ids)
+				return nil
+			},
+		},
+		{
+			Name: "other", Priority: 50, Category: "b", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				otherSaw = append(otherSaw, len(env.IdleGroundUnits()))
+				return nil
+			},
+		},
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	units := []model.Unit{{ID: 1, Type: "2tnk", Idle: true}, {ID: 2, Type: "e1", Idle: true}}
+
+	// The holder grabs both units at tick 0 and may re-order them at tick
+	// 5, renewing the hold to tick 15; until then no other rule sees them.
+	for tick := 0; tick <= 15; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick, Units: units}, "soviet", nil); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", tick, err)
+		}
+	}
+	if len(holderSaw) != 2 || holderSaw[1] != 2 {
+		t.Errorf("holder saw %v units, want [2 2] — its own holds don't block it", holderSaw)
+	}
+	for tick, n := range otherSaw[:15] {
+		if n != 0 {
+			t.Errorf("tick %d: other rule saw %d held units", tick, n)
+		}
+	}
+	if otherSaw[15] != 2 {
+		t.Errorf("tick 15: other rule saw %d units, want both back after the hold lapsed", otherSaw[15])
+	}
+}
@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Idle queue detection. A unit queue that sits empty most of the time while
+// we have cash and the doctrine wants that kind of unit usually means a
+// rule condition can never be satisfied — a role we can't build, a cap
+// already reached, a prerequisite we'll never get. Nothing fails loudly in
+// that case, so the queue is watched and flagged stalled for the
+// strategist and the dashboard.
+const (
+	QueueWatchWindowTicks = 1000 // trailing window the idle fraction is taken over
+	queueStallFraction    = 0.8  // idle at least this much of the window is a stall
+	queueResumeFraction   = 0.5  // a stalled queue recovers once idle less than this
+	queueStallMinCash     = 800  // below this an idle queue is just waiting for money
+	queueStallMinWeight   = 0.2  // doctrine weight below which the queue is expected to idle
+)
+
+// queueWeights maps each watched queue to the doctrine weight that says we
+// want it busy. Building and defense queues idle legitimately and aren't
+// watched.
+var queueWeights = map[string]func(Doctrine) float64{
+	QueueInfantry: func(d Doctrine) float64 { return d.InfantryWeight },
+	QueueVehicle:  func(d Doctrine) float64 { return d.VehicleWeight },
+	QueueAircraft: func(d Doctrine) float64 { return d.AirWeight },
+	QueueShip:     func(d Doctrine) float64 { return d.NavalWeight },
+}
+
+// queueWatch is one queue's recent idle history.
+type queueWatch struct {
+	Samples []queueSample // within QueueWatchWindowTicks, oldest first
+	Stalled bool
+	Since   int // tick the current stall began
+}
+
+// queueSample is one update's observation: whether the queue sat idle with
+// cash in hand.
+type queueSample struct {
+	Tick int
+	Idle bool
+}
+
+// idleFraction is the share of samples that were idle, and whether the
+// samples span enough of the window to judge.
+func (w *queueWatch) idleFraction(tick int) (float64, bool) {
+	if len(w.Samples) == 0 || tick-w.Samples[0].Tick < QueueWatchWindowTicks*3/4 {
+		return 0, false
+	}
+	idle := 0
+	for _, s := range w.Samples {
+		if s.Idle {
+			idle++
+		}
+	}
+	return float64(idle) / float64(len(w.Samples)), true
+}
+
+func getQueueWatches(memory map[string]any) map[string]*queueWatch {
+	if v, ok := queueWatchMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]*queueWatch)
+}
+
+// queueIdle reports whether we have a queue of type q that can build
+// something and every queue of that type is empty.
+func (e RuleEnv) queueIdle(q string) (idle, present bool) {
+	idle = true
+	for _, pq := range e.State.ProductionQueues {
+		if !strings.EqualFold(pq.Type, q) || len(pq.Buildable) == 0 {
+			continue
+		}
+		present = true
+		if pq.CurrentItem != "" {
+			idle = false
+		}
+	}
+	return idle && present, present
+}
+
+// updateQueueWatch samples each watched queue and raises or clears its
+// stall flag. A queue we don't have, or that the doctrine doesn't want,
+// drops its history.
+func updateQueueWatch(env RuleEnv) {
+	tick := env.State.Tick
+	cash := env.State.Player.Cash + env.State.Player.Resources
+	watches := getQueueWatches(env.Memory)
+	for q, weight := range queueWeights {
+		idle, present := env.queueIdle(q)
+		if !present || weight(env.Doctrine) < queueStallMinWeight {
+			delete(watches, q)
+			continue
+		}
+		w := watches[q]
+		if w == nil {
+			w = &queueWatch{}
+			watches[q] = w
+		}
+		w.Samples = append(w.Samples, queueSample{Tick: tick, Idle: idle && cash >= queueStallMinCash})
+		cut := 0
+		for cut < len(w.Samples) && tick-w.Samples[cut].Tick > QueueWatchWindowTicks {
+			cut++
+		}
+		w.Samples = w.Samples[cut:]
+
+		frac, ok := w.idleFraction(tick)
+		switch {
+		case ok && !w.Stalled && frac >= queueStallFraction:
+			w.Stalled, w.Since = true, tick
+			slog.Warn("production queue stalled", "queue", q, "idle", frac, "cash", cash, "tick", tick)
+		case w.Stalled && frac < queueResumeFraction:
+			w.Stalled = false
+			slog.Info("production queue resumed", "queue", q, "stalled_for", tick-w.Since, "tick", tick)
+		}
+	}
+	if len(watches) == 0 {
+		queueWatchMemory.clear(env.Memory)
+		return
+	}
+	queueWatchMemory.set(env.Memory, watches)
+}
+
+// StalledQueues returns the production queues currently flagged as
+// chronically idle, sorted.
+func StalledQueues(memory map[string]any) []string {
+	var out []string
+	for q, w := range getQueueWatches(memory) {
+		if w.Stalled {
+			out = append(out, q)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// QueueIdleStat is a watched queue's idle metric, for the dashboard.
+type QueueIdleStat struct {
+	Queue        string  `json:"queue"`
+	IdleFraction float64 `json:"idle_fraction"` // over the trailing window; 0 until the window fills
+	Stalled      bool    `json:"stalled"`
+	StalledSince int     `json:"stalled_since,omitempty"`
+}
+
+// QueueIdleStats returns the idle metric of every watched production queue.
+func (e *Engine) QueueIdleStats() []QueueIdleStat {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	watches := getQueueWatches(e.Memory)
+	out := make([]QueueIdleStat, 0, len(watches))
+	for _, q := range slices.Sorted(maps.Keys(watches)) {
+		w := watches[q]
+		stat := QueueIdleStat{Queue: q, Stalled: w.Stalled}
+		if n := len(w.Samples); n > 0 {
+			stat.IdleFraction, _ = w.idleFraction(w.Samples[n-1].Tick)
+		}
+		if w.Stalled {
+			stat.StalledSince = w.Since
+		}
+		out = append(out, stat)
+	}
+	return out
+}
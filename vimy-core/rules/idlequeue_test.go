@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestQueueWatchFlagsStalledQueue(t *testing.T) {
+	mem := map[string]any{}
+	doctrine := Doctrine{VehicleWeight: 0.6, InfantryWeight: 0.1}
+	step := func(tick, cash int, vehicleItem string) {
+		updateQueueWatch(RuleEnv{
+			State: model.GameState{
+				Tick:   tick,
+				Player: model.Player{Cash: cash},
+				ProductionQueues: []model.ProductionQueue{
+					{Type: "Vehicle", Buildable: []string{"2tnk"}, CurrentItem: vehicleItem},
+					{Type: "Infantry", Buildable: []string{"e1"}},
+				},
+			},
+			Memory:   mem,
+			Doctrine: doctrine,
+		})
+	}
+
+	// Broke: an idle queue is only waiting for money.
+	for tick := 0; tick <= QueueWatchWindowTicks; tick += 50 {
+		step(tick, 100, "")
+	}
+	if got := StalledQueues(mem); len(got) != 0 {
+		t.Fatalf("StalledQueues while broke = %v, want none", got)
+	}
+
+	// Rich and still idle: stalled. Infantry is idle too but the doctrine
+	// barely wants it.
+	for tick := QueueWatchWindowTicks + 50; tick <= 3*QueueWatchWindowTicks; tick += 50 {
+		step(tick, 5000, "")
+	}
+	if got := StalledQueues(mem); len(got) != 1 || got[0] != QueueVehicle {
+		t.Fatalf("StalledQueues = %v, want [Vehicle]", got)
+	}
+
+	// Production picks up again.
+	for tick := 3*QueueWatchWindowTicks + 50; tick <= 4*QueueWatchWindowTicks; tick += 50 {
+		step(tick, 5000, "2tnk")
+	}
+	if got := StalledQueues(mem); len(got) != 0 {
+		t.Errorf("StalledQueues after production resumed = %v, want none", got)
+	}
+}
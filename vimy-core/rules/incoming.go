@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Incoming attack prediction. BaseUnderAttack only fires once enemies are
+// inside the base radius; by then the fight is at our buildings. With enemy
+// velocities tracked we can see a force heading for the base while it is
+// still outside the radius, and raise the warning as soon as enough of it is
+// due to arrive within incomingHorizonTicks.
+const (
+	incomingMinEnemies   = 4   // inbound ground combat units to call it an attack
+	incomingHorizonTicks = 750 // 30s — a force further out than this is not yet incoming
+	incomingGapTicks     = 125 // 5s without an inbound force lowers the warning
+)
+
+// incomingAttack is the active warning. Target is the building the earliest
+// arriving attacker is heading for.
+type incomingAttack struct {
+	Since    int
+	LastSeen int
+	Units    int // inbound units on the latest sighting
+	ETA      int // ticks until the first of them crosses the base radius, as of LastSeen
+	TargetID int
+	TargetX  int
+	TargetY  int
+}
+
+func getIncomingAttack(memory map[string]any) *incomingAttack {
+	if v, ok := incomingAttackMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// inboundEnemies counts the visible enemy ground combat units outside the
+// base radius that are moving toward one of our buildings and will cross the
+// radius within incomingHorizonTicks. eta is when the first crosses it and
+// target the building it is heading for.
+func (e RuleEnv) inboundEnemies() (n, eta int, target model.Building) {
+	if len(e.State.Buildings) == 0 {
+		return 0, 0, model.Building{}
+	}
+	radius := e.baseThreatRadius()
+	eta = math.MaxInt
+	for _, en := range e.State.Enemies {
+		if !isGroundCombatEnemy(en.Type) || e.nearOwnBase(en.X, en.Y, radius) {
+			continue
+		}
+		vx, vy, ok := e.EnemyVelocity(en.ID)
+		speed := math.Hypot(vx, vy)
+		if !ok || speed < movingSpeed {
+			continue
+		}
+		dest, ok := e.forceDestination(en.X, en.Y, vx, vy)
+		if !ok {
+			continue
+		}
+		arrive := int((math.Hypot(float64(dest.X-en.X), float64(dest.Y-en.Y)) - radius) / speed)
+		if arrive > incomingHorizonTicks {
+			continue
+		}
+		n++
+		if arrive < eta {
+			eta, target = max(arrive, 0), dest
+		}
+	}
+	if n == 0 {
+		return 0, 0, model.Building{}
+	}
+	return n, eta, target
+}
+
+// updateIncomingAttack raises the warning when enough enemies are inbound and
+// lowers it once none have been for incomingGapTicks — they turned away, died,
+// or arrived and BaseUnderAttack took over.
+func updateIncomingAttack(env RuleEnv) {
+	tick := env.State.Tick
+	n, eta, target := env.inboundEnemies()
+	alert := getIncomingAttack(env.Memory)
+
+	if n < incomingMinEnemies {
+		if alert != nil && tick-alert.LastSeen >= incomingGapTicks {
+			slog.Info("incoming attack over", "since", alert.Since, "tick", tick)
+			incomingAttackMemory.clear(env.Memory)
+		}
+		return
+	}
+	if alert == nil {
+		alert = &incomingAttack{Since: tick}
+		incomingAttackMemory.set(env.Memory, alert)
+		slog.Warn("incoming attack", "enemies", n, "eta", eta, "target", target.Type, "tick", tick)
+	}
+	alert.LastSeen, alert.Units, alert.ETA = tick, n, eta
+	alert.TargetID, alert.TargetX, alert.TargetY = target.ID, target.X, target.Y
+}
+
+// IncomingAttackActive reports whether an enemy force is on its way to our
+// base. Public so the strategist's event detector can raise
+// EventIncomingAttack.
+func IncomingAttackActive(memory map[string]any) bool { return getIncomingAttack(memory) != nil }
+
+// IncomingAttackers returns how many enemy units were last seen inbound, or 0
+// when no attack is incoming.
+func IncomingAttackers(memory map[string]any) int {
+	if a := getIncomingAttack(memory); a != nil {
+		return a.Units
+	}
+	return 0
+}
+
+// IncomingAttackETA returns the ticks until the incoming force reaches the
+// base radius, as of its last sighting, or 0 when no attack is incoming.
+func IncomingAttackETA(memory map[string]any) int {
+	if a := getIncomingAttack(memory); a != nil {
+		return a.ETA
+	}
+	return 0
+}
+
+// IncomingAttack reports whether an enemy force is heading for our base but
+// has not reached it yet.
+func (e RuleEnv) IncomingAttack() bool { return IncomingAttackActive(e.Memory) }
+
+// incomingRally returns where defenders should meet an incoming attack that
+// hasn't arrived: the building it is heading for, so the fight happens under
+// our defenses. ok is false with no warning up or once the base is already
+// under attack.
+func (e RuleEnv) incomingRally() (x, y int, ok bool) {
+	a := getIncomingAttack(e.Memory)
+	if a == nil || e.BaseUnderAttack() {
+		return 0, 0, false
+	}
+	return a.TargetX, a.TargetY, true
+}
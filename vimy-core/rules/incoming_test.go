@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// incomingEnv is a base at (20, 20) on a 128x128 map — base radius about
+// 36 cells — with a refinery east of it and five tanks further east.
+func incomingEnv() *RuleEnv {
+	env := &RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 100, Type: "fact", X: 20, Y: 20},
+				{ID: 101, Type: "proc", X: 30, Y: 20},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	for i := range 5 {
+		env.State.Enemies = append(env.State.Enemies, model.Enemy{ID: 50 + i, Type: "3tnk", X: 100 + i, Y: 20})
+	}
+	return env
+}
+
+func stepIncoming(env *RuleEnv, dt, dx int) {
+	env.State.Tick += dt
+	for i := range env.State.Enemies {
+		env.State.Enemies[i].X += dx
+	}
+	updateEnemyTracks(*env)
+	updateIncomingAttack(*env)
+}
+
+func TestIncomingAttackWarnsBeforeArrival(t *testing.T) {
+	env := incomingEnv()
+	stepIncoming(env, 0, 0)
+	for range 3 {
+		stepIncoming(env, 10, -1)
+	}
+	if env.BaseUnderAttack() {
+		t.Fatal("setup: force already inside the base radius")
+	}
+	if !env.IncomingAttack() {
+		t.Fatal("expected an incoming attack warning")
+	}
+	if n := IncomingAttackers(env.Memory); n != 5 {
+		t.Errorf("attackers = %d, want 5", n)
+	}
+	if eta := IncomingAttackETA(env.Memory); eta <= 0 || eta > incomingHorizonTicks {
+		t.Errorf("eta = %d, want within the horizon", eta)
+	}
+	if x, y, ok := env.incomingRally(); !ok || x != 30 || y != 20 {
+		t.Errorf("rally = (%d, %d) ok %v, want the refinery (30, 20)", x, y, ok)
+	}
+
+	// They turn back: the warning holds through the gap, then lowers.
+	stepIncoming(env, 10, 2)
+	if !env.IncomingAttack() {
+		t.Error("warning dropped before the gap")
+	}
+	stepIncoming(env, incomingGapTicks, 0)
+	if env.IncomingAttack() {
+		t.Error("warning kept after the force turned away")
+	}
+}
+
+func TestIncomingAttackIgnoresSmallOrStillForces(t *testing.T) {
+	still := incomingEnv()
+	stepIncoming(still, 0, 0)
+	for range 3 {
+		stepIncoming(still, 10, 0)
+	}
+	if still.IncomingAttack() {
+		t.Error("warning for a force standing still")
+	}
+
+	small := incomingEnv()
+	small.State.Enemies = small.State.Enemies[:incomingMinEnemies-1]
+	stepIncoming(small, 0, 0)
+	for range 3 {
+		stepIncoming(small, 10, -1)
+	}
+	if small.IncomingAttack() {
+		t.Error("warning for a force under incomingMinEnemies")
+	}
+
+	// Moving fast but from too far: not due within the horizon.
+	far := incomingEnv()
+	far.State.Buildings = far.State.Buildings[:1]
+	far.State.MapWidth, far.State.MapHeight = 1024, 64
+	for i := range far.State.Enemies {
+		far.State.Enemies[i].X += 800
+	}
+	stepIncoming(far, 0, 0)
+	for range 3 {
+		stepIncoming(far, 10, -1)
+	}
+	if far.IncomingAttack() {
+		t.Error("warning for a force beyond the horizon")
+	}
+}
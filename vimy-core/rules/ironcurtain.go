@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"log/slog"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Defensive iron curtain. Offensively the curtain makes an idle army
+// invulnerable for a push; defensively it can save the construction yard
+// or a refinery that is going down under an attack. FireIronCurtain weighs
+// the two each time the power is ready: what the building under fire is
+// worth and how badly it is hurt, against how much of an army there is to
+// protect and how keen the doctrine is to attack with it.
+
+const (
+	curtainHitWindow    = 75  // ticks after an HP drop a building still counts as under fire
+	curtainDefenseHPPct = 0.7 // a key building must be below this HP fraction to curtain
+)
+
+// curtainValue is what saving each key building is worth, against an
+// offensive curtain's score of at most 1.
+var curtainValue = map[string]float64{
+	ConstructionYard: 1.5,
+	Refinery:         0.75,
+}
+
+// keyBuildingHit is a curtain-worthy building's HP at the last update and
+// the tick it last lost HP.
+type keyBuildingHit struct {
+	HP      int
+	LastHit int // 0 if never
+}
+
+func curtainKey(t string) string {
+	for k := range curtainValue {
+		if matchesType(t, k) {
+			return k
+		}
+	}
+	return ""
+}
+
+// updateKeyBuildingHits notes which construction yards and refineries lost
+// HP since the last update.
+func updateKeyBuildingHits(env RuleEnv) {
+	prev, _ := keyBuildingHitsMemory.get(env.Memory)
+	hits := make(map[int]keyBuildingHit)
+	for _, b := range env.State.Buildings {
+		if curtainKey(b.Type) == "" {
+			continue
+		}
+		h := keyBuildingHit{HP: b.HP}
+		if p, ok := prev[b.ID]; ok {
+			h.LastHit = p.LastHit
+			if b.HP < p.HP {
+				h.LastHit = env.State.Tick
+			}
+		}
+		hits[b.ID] = h
+	}
+	keyBuildingHitsMemory.set(env.Memory, hits)
+}
+
+// curtainDefenseTarget returns the index in State.Buildings of the key
+// building most worth curtaining and its score, value times damage taken;
+// idx is -1 when no key building is both under fire and badly hurt.
+func (e RuleEnv) curtainDefenseTarget() (idx int, score float64) {
+	hits, _ := keyBuildingHitsMemory.get(e.Memory)
+	idx = -1
+	for i, b := range e.State.Buildings {
+		h, ok := hits[b.ID]
+		if !ok || h.LastHit == 0 || e.State.Tick-h.LastHit > curtainHitWindow || b.MaxHP <= 0 {
+			continue
+		}
+		pct := float64(b.HP) / float64(b.MaxHP)
+		if pct >= curtainDefenseHPPct {
+			continue
+		}
+		if s := curtainValue[curtainKey(b.Type)] * (1 - pct); s > score {
+			idx, score = i, s
+		}
+	}
+	return idx, score
+}
+
+// KeyBuildingUnderFire reports whether the construction yard or a refinery
+// is losing HP and already badly damaged.
+func (e RuleEnv) KeyBuildingUnderFire() bool {
+	idx, _ := e.curtainDefenseTarget()
+	return idx >= 0
+}
+
+// curtainOffenseScore rates curtaining the idle army: aggression, scaled
+// down for an army smaller than twice IronCurtainMinUnits. It is 0 below
+// IronCurtainMinUnits.
+func (e RuleEnv) curtainOffenseScore(aggression float64) float64 {
+	n := len(e.AllIdleGroundUnits())
+	if n < IronCurtainMinUnits {
+		return 0
+	}
+	return aggression * min(1, float64(n)/float64(2*IronCurtainMinUnits))
+}
+
+// FireIronCurtain returns an action that curtains whichever use scores
+// higher: a key building under fire during a base attack, or the idle
+// army.
+func FireIronCurtain(aggression float64) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		offense := env.curtainOffenseScore(aggression)
+		if !env.BaseUnderAttack() {
+			return ActionFireIronCurtain(env, conn)
+		}
+		idx, defense := env.curtainDefenseTarget()
+		if idx < 0 || defense <= offense {
+			return ActionFireIronCurtain(env, conn)
+		}
+		b := env.State.Buildings[idx]
+		recordSuperweaponFire(env, "iron_curtain")
+		slog.Info("firing iron curtain on building under fire", "type", b.Type, "hp", b.HP, "max_hp", b.MaxHP,
+			"defense_score", defense, "offense_score", offense)
+		return conn.Send(ipc.TypeSupportPower, ipc.SupportPowerCommand{
+			PowerKey: "GrantExternalConditionPowerInfoOrder",
+			X:        b.X,
+			Y:        b.Y,
+		})
+	}
+}
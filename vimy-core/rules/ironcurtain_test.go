@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCurtainDefenseTarget(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Tick: 100,
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 50, Y: 50, HP: 1000, MaxHP: 1000},
+				{ID: 2, Type: "proc", X: 56, Y: 50, HP: 900, MaxHP: 900},
+				{ID: 3, Type: "powr", X: 44, Y: 50, HP: 400, MaxHP: 400},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	updateKeyBuildingHits(env)
+	if env.KeyBuildingUnderFire() {
+		t.Fatal("KeyBuildingUnderFire with nothing hit")
+	}
+
+	// The refinery and power plant take heavy damage; the yard a scratch.
+	env.State.Tick = 130
+	env.State.Buildings[0].HP = 950
+	env.State.Buildings[1].HP = 300
+	env.State.Buildings[2].HP = 50
+	updateKeyBuildingHits(env)
+	idx, score := env.curtainDefenseTarget()
+	if idx != 1 {
+		t.Fatalf("target = %d, want the refinery (1)", idx)
+	}
+
+	// The yard drops too and outweighs the refinery.
+	env.State.Tick = 160
+	env.State.Buildings[0].HP = 400
+	updateKeyBuildingHits(env)
+	if idx, s := env.curtainDefenseTarget(); idx != 0 || s <= score {
+		t.Errorf("target = %d (score %.2f), want the construction yard above %.2f", idx, s, score)
+	}
+
+	// Fire stops: after the window the buildings no longer count.
+	env.State.Tick = 160 + curtainHitWindow + 1
+	updateKeyBuildingHits(env)
+	if env.KeyBuildingUnderFire() {
+		t.Error("KeyBuildingUnderFire after the attack stopped")
+	}
+}
+
+func TestCurtainOffenseScore(t *testing.T) {
+	env := RuleEnv{State: model.GameState{}, Memory: make(map[string]any)}
+	for i := range IronCurtainMinUnits - 1 {
+		env.State.Units = append(env.State.Units, model.Unit{ID: i + 1, Type: "3tnk", Idle: true})
+	}
+	if s := env.curtainOffenseScore(1); s != 0 {
+		t.Errorf("score %.2f with too few units, want 0", s)
+	}
+	for i := range IronCurtainMinUnits + 1 {
+		env.State.Units = append(env.State.Units, model.Unit{ID: 100 + i, Type: "3tnk", Idle: true})
+	}
+	if s := env.curtainOffenseScore(0.8); s != 0.8 {
+		t.Errorf("score %.2f with a full army, want the aggression 0.8", s)
+	}
+	// At 40% HP the yard outweighs a full army under a balanced doctrine;
+	// the refinery doesn't outweigh an all-in one.
+	if def := curtainValue[ConstructionYard] * 0.6; def <= env.curtainOffenseScore(0.5) {
+		t.Errorf("yard score %.2f should beat a balanced offense", def)
+	}
+	if def := curtainValue[Refinery] * 0.6; def >= env.curtainOffenseScore(1) {
+		t.Errorf("refinery score %.2f should lose to an all-in offense", def)
+	}
+}
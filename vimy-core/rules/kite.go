@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Kiting timing: a kite is a short step back, then a re-attack once the step
+// has had time to play out.
+const kiteStepTicks = 50
+
+func getKitingUnits(memory map[string]any) map[int]int {
+	if v, ok := kitingMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]int)
+}
+
+// nearestMobileEnemy returns the closest visible enemy unit to (x, y) that
+// can close distance — buildings and aircraft are skipped, since stepping
+// back from neither helps.
+func (e RuleEnv) nearestMobileEnemy(x, y int) (*model.Enemy, float64) {
+	var best *model.Enemy
+	bestDist := math.MaxFloat64
+	for i := range e.State.Enemies {
+		en := &e.State.Enemies[i]
+		if IsKnownBuildingType(en.Type) || isAircraft(model.Unit{Type: en.Type}) {
+			continue
+		}
+		if d := math.Hypot(float64(en.X-x), float64(en.Y-y)); d < bestDist {
+			bestDist = d
+			best = en
+		}
+	}
+	return best, bestDist
+}
+
+// UnitsToKite returns ranged units with a mobile enemy inside dangerPct of
+// their weapon range. Units retreating or mid-kite are excluded.
+func (e RuleEnv) UnitsToKite(dangerPct float64) []model.Unit {
+	if len(e.State.Enemies) == 0 {
+		return nil
+	}
+	skip := e.doNotTask()
+	kiting := getKitingUnits(e.Memory)
+	var out []model.Unit
+	for _, u := range e.State.Units {
+		r := rangeOf(u.Type)
+		if r == 0 {
+			continue
+		}
+		if skip[u.ID] {
+			continue
+		}
+		if _, ok := kiting[u.ID]; ok {
+			continue
+		}
+		if _, d := e.nearestMobileEnemy(u.X, u.Y); d <= r*dangerPct {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// HasKitingUnits reports whether any units are mid-kite and awaiting re-attack.
+func (e RuleEnv) HasKitingUnits() bool { return len(getKitingUnits(e.Memory)) > 0 }
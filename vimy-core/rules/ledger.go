@@ -0,0 +1,264 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Combat ledger. Tallies our losses and the enemy's, by type and domain,
+// and keeps the last ledgerWindowTicks of them so rules and the strategist
+// can react to attrition trends — "we've lost six vehicles this minute" —
+// rather than to whatever one snapshot shows. Our losses are exact: a unit
+// of ours that drops out of the state died. Enemy kills are approximate:
+// an enemy that vanishes while one of ours stands right next to where it
+// was is counted as killed, one that vanishes into the fog is not.
+const (
+	ledgerWindowTicks = 3000 // trailing window for LossRate and KillRate
+	ledgerKillRadius  = 4    // cells — an enemy vanishing this close to us was killed
+)
+
+// ledgerSpentTypes leave the state by doing their job — an MCV deploying,
+// an engineer capturing, a spy infiltrating — so their disappearance isn't
+// a loss.
+var ledgerSpentTypes = []string{MCV, Engineer, Spy}
+
+// Ledger domains.
+const (
+	ledgerInfantry = "infantry"
+	ledgerVehicle  = "vehicle"
+	ledgerAircraft = "aircraft"
+	ledgerNaval    = "naval"
+	ledgerBuilding = "building"
+)
+
+// CombatLedger is the running tally of losses on both sides.
+type CombatLedger struct {
+	Lost           map[string]int // our units lost, by type
+	Killed         map[string]int // enemy units and buildings destroyed, by type
+	LostByDomain   map[string]int
+	KilledByDomain map[string]int
+	Recent         []LedgerEntry // within ledgerWindowTicks, oldest first
+}
+
+// LedgerEntry is one loss, ours or the enemy's.
+type LedgerEntry struct {
+	Tick   int
+	Type   string
+	Domain string
+	Enemy  bool
+}
+
+// ledgerSightings is what the ledger saw last update, to diff against.
+// Transient: after a restart the first update only re-seeds it.
+type ledgerSightings struct {
+	own     map[int]string
+	cargo   int // passengers aboard our transports
+	enemies map[int]model.Enemy
+}
+
+// GetCombatLedger returns the combat ledger, or nil before any losses.
+func GetCombatLedger(memory map[string]any) *CombatLedger {
+	l, _ := combatLedgerMemory.get(memory)
+	return l
+}
+
+// ledgerDomain classifies an actor type for the ledger.
+func ledgerDomain(t string) string {
+	if u, ok := LookupUnit(t); ok {
+		switch u.Target {
+		case "infantry":
+			return ledgerInfantry
+		case "air":
+			return ledgerAircraft
+		case "ship":
+			return ledgerNaval
+		case "structure":
+			return ledgerBuilding
+		}
+	}
+	unit := model.Unit{Type: t}
+	switch {
+	case IsKnownBuildingType(t):
+		return ledgerBuilding
+	case isInfantry(unit):
+		return ledgerInfantry
+	case isAircraft(unit):
+		return ledgerAircraft
+	case isNaval(unit):
+		return ledgerNaval
+	}
+	return ledgerVehicle
+}
+
+func (l *CombatLedger) record(tick int, t string, enemy bool) {
+	d := ledgerDomain(t)
+	if enemy {
+		l.Killed[t]++
+		l.KilledByDomain[d]++
+	} else {
+		l.Lost[t]++
+		l.LostByDomain[d]++
+	}
+	l.Recent = append(l.Recent, LedgerEntry{Tick: tick, Type: t, Domain: d, Enemy: enemy})
+}
+
+// updateCombatLedger diffs this update's units and enemies against the
+// last one's and records what was lost.
+func updateCombatLedger(env RuleEnv) {
+	tick := env.State.Tick
+	own := make(map[int]string, len(env.State.Units))
+	cargo := 0
+	for _, u := range env.State.Units {
+		own[u.ID] = u.Type
+		cargo += u.CargoCount
+	}
+	enemies := make(map[int]model.Enemy, len(env.State.Enemies))
+	for _, en := range env.State.Enemies {
+		enemies[en.ID] = en
+	}
+
+	prev, ok := ledgerSightingsMemory.get(env.Memory)
+	ledgerSightingsMemory.set(env.Memory, &ledgerSightings{own: own, cargo: cargo, enemies: enemies})
+	if !ok {
+		return
+	}
+
+	ledger := GetCombatLedger(env.Memory)
+	if ledger == nil {
+		ledger = &CombatLedger{
+			Lost: make(map[string]int), Killed: make(map[string]int),
+			LostByDomain: make(map[string]int), KilledByDomain: make(map[string]int),
+		}
+	}
+	// Infantry that boarded a transport drop out of the state too; as many
+	// vanished infantry as there are new passengers are taken to have.
+	boarded := cargo - prev.cargo
+	for id, t := range prev.own {
+		if _, alive := own[id]; alive || slices.ContainsFunc(ledgerSpentTypes, func(s string) bool { return matchesType(t, s) }) {
+			continue
+		}
+		if boarded > 0 && ledgerDomain(t) == ledgerInfantry {
+			boarded--
+			continue
+		}
+		ledger.record(tick, t, false)
+	}
+	for id, en := range prev.enemies {
+		if _, alive := enemies[id]; !alive && env.sawVanish(en) {
+			ledger.record(tick, en.Type, true)
+		}
+	}
+
+	// Drop entries that have aged out of the window.
+	cut := 0
+	for cut < len(ledger.Recent) && tick-ledger.Recent[cut].Tick > ledgerWindowTicks {
+		cut++
+	}
+	ledger.Recent = ledger.Recent[cut:]
+	if len(ledger.Lost) > 0 || len(ledger.Killed) > 0 {
+		combatLedgerMemory.set(env.Memory, ledger)
+	}
+}
+
+// sawVanish reports whether an enemy that has just disappeared was close
+// enough to one of our units or buildings that it died rather than slipped
+// into the fog. Buildings don't move, so for them anything in sight range
+// counts.
+func (e RuleEnv) sawVanish(en model.Enemy) bool {
+	radius := float64(ledgerKillRadius)
+	if IsKnownBuildingType(en.Type) {
+		radius = siloSightRadius
+	}
+	for _, u := range e.State.Units {
+		if math.Hypot(float64(u.X-en.X), float64(u.Y-en.Y)) <= radius {
+			return true
+		}
+	}
+	for _, b := range e.State.Buildings {
+		if math.Hypot(float64(b.X-en.X), float64(b.Y-en.Y)) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// RecentByDomain counts the window's losses on one side by domain, as of
+// tick.
+func (l *CombatLedger) RecentByDomain(tick int, enemy bool) map[string]int {
+	out := make(map[string]int)
+	for _, en := range l.Recent {
+		if en.Enemy == enemy && tick-en.Tick <= ledgerWindowTicks {
+			out[en.Domain]++
+		}
+	}
+	return out
+}
+
+// recentCount counts the window's entries on one side, in domain ("" for
+// all domains).
+func (e RuleEnv) recentCount(domain string, enemy bool) int {
+	l := GetCombatLedger(e.Memory)
+	if l == nil {
+		return 0
+	}
+	n := 0
+	for _, en := range l.Recent {
+		if en.Enemy == enemy && e.State.Tick-en.Tick <= ledgerWindowTicks && (domain == "" || en.Domain == domain) {
+			n++
+		}
+	}
+	return n
+}
+
+// perMinute converts a count over ledgerWindowTicks to a rate per game
+// minute.
+func perMinute(n int) float64 {
+	return float64(n) * 60 * ticksPerSecond / ledgerWindowTicks
+}
+
+// LossRate returns our losses per game minute in domain ("infantry",
+// "vehicle", "aircraft", "naval"; "" for all) over the last two minutes.
+func (e RuleEnv) LossRate(domain string) float64 {
+	return perMinute(e.recentCount(domain, false))
+}
+
+// KillRate returns approximate enemy losses per game minute in domain
+// ("building" included) over the last two minutes.
+func (e RuleEnv) KillRate(domain string) float64 {
+	return perMinute(e.recentCount(domain, true))
+}
+
+// TotalLost returns our losses in domain ("" for all) this game.
+func (e RuleEnv) TotalLost(domain string) int {
+	l := GetCombatLedger(e.Memory)
+	if l == nil {
+		return 0
+	}
+	if domain == "" {
+		return sumCounts(l.LostByDomain)
+	}
+	return l.LostByDomain[domain]
+}
+
+// TotalKilled returns approximate enemy losses in domain ("" for all) this
+// game.
+func (e RuleEnv) TotalKilled(domain string) int {
+	l := GetCombatLedger(e.Memory)
+	if l == nil {
+		return 0
+	}
+	if domain == "" {
+		return sumCounts(l.KilledByDomain)
+	}
+	return l.KilledByDomain[domain]
+}
+
+func sumCounts(m map[string]int) int {
+	n := 0
+	for _, c := range m {
+		n += c
+	}
+	return n
+}
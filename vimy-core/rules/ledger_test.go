@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestCombatLedgerRecordsLossesAndKills(t *testing.T) {
+	mem := map[string]any{}
+	update := func(tick int, units []model.Unit, enemies []model.Enemy) {
+		updateCombatLedger(RuleEnv{State: model.GameState{Tick: tick, Units: units, Enemies: enemies}, Memory: mem})
+	}
+	tank := model.Unit{ID: 1, Type: "2tnk", X: 50, Y: 50}
+	rifle := model.Unit{ID: 2, Type: "e1", X: 20, Y: 20}
+	apc := model.Unit{ID: 3, Type: "apc", X: 20, Y: 21}
+	mcv := model.Unit{ID: 4, Type: "mcv", X: 10, Y: 10}
+	near := model.Enemy{ID: 10, Type: "3tnk", X: 52, Y: 50}
+	far := model.Enemy{ID: 11, Type: "3tnk", X: 90, Y: 90}
+
+	update(100, []model.Unit{tank, rifle, apc, mcv}, []model.Enemy{near, far})
+	if GetCombatLedger(mem) != nil {
+		t.Fatal("the first update should only seed the ledger")
+	}
+
+	// The rifleman boards the APC, the MCV deploys, and both enemy tanks
+	// vanish: one next to our tank, one out in the fog.
+	apc.CargoCount = 1
+	update(200, []model.Unit{tank, apc}, nil)
+	l := GetCombatLedger(mem)
+	if l == nil {
+		t.Fatal("expected a ledger after the enemy tank died")
+	}
+	if len(l.Lost) != 0 {
+		t.Errorf("boarding and deploying are not losses, got %v", l.Lost)
+	}
+	if l.Killed["3tnk"] != 1 {
+		t.Errorf("Killed = %v, want one heavy tank (the other slipped into the fog)", l.Killed)
+	}
+
+	// Now the tank dies.
+	update(300, []model.Unit{apc}, nil)
+	env := RuleEnv{State: model.GameState{Tick: 300}, Memory: mem}
+	if got := env.TotalLost(ledgerVehicle); got != 1 {
+		t.Errorf("TotalLost(vehicle) = %d, want 1", got)
+	}
+	if got, want := env.LossRate(ledgerVehicle), perMinute(1); got != want {
+		t.Errorf("LossRate(vehicle) = %v, want %v", got, want)
+	}
+	if got := env.LossRate(ledgerInfantry); got != 0 {
+		t.Errorf("LossRate(infantry) = %v, want 0", got)
+	}
+
+	// Once the window has passed the rate drops but the totals stay.
+	update(300+ledgerWindowTicks+1, []model.Unit{apc}, nil)
+	env.State.Tick = 300 + ledgerWindowTicks + 1
+	if got := env.LossRate(""); got != 0 {
+		t.Errorf("LossRate after the window = %v, want 0", got)
+	}
+	if got := env.TotalLost(""); got != 1 {
+		t.Errorf("TotalLost = %d, want 1", got)
+	}
+	if got := env.TotalKilled(""); got != 1 {
+		t.Errorf("TotalKilled = %d, want 1", got)
+	}
+}
@@ -0,0 +1,234 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// MemoryStore is the engine's per-game memory: squads, intel, cooldowns and
+// the rest of the state rules carry between ticks. Each key is a registered
+// section (see memorySections) read and written through a typed memSection
+// handle, so the type assertion for a key lives in one place. Unregistered
+// keys (e.g. ones the strategist keeps) are allowed but not persisted.
+type MemoryStore map[string]any
+
+// memoryVersion is the persisted format version. Bump it when a section
+// changes shape incompatibly; older checkpoints are then refused rather
+// than half-decoded.
+const memoryVersion = 1
+
+// memorySection is the registration of one Memory key.
+type memorySection struct {
+	decode     func(json.RawMessage) (any, error)
+	transient  bool   // not persisted: can't round-trip through JSON or isn't worth it
+	dropOnSwap bool   // discarded when a new doctrine's rules are swapped in
+	group      string // rule group whose rules alone use it; cleared when the group is disabled
+}
+
+// memorySections maps each registered Memory key to its section.
+var memorySections = map[string]*memorySection{}
+
+// memoryOption adjusts a section at registration.
+type memoryOption func(*memorySection)
+
+// dropOnSwap discards the section when a new rule set is swapped in, for
+// state keyed by things the new doctrine redefines (squad names, rules).
+func dropOnSwap(s *memorySection) { s.dropOnSwap = true }
+
+// transient keeps the section out of persisted memory.
+func transient(s *memorySection) { s.transient = true }
+
+// ownedBy marks the section as the state of one rule group.
+func ownedBy(group string) memoryOption {
+	return func(s *memorySection) { s.group = group }
+}
+
+// memSection is a typed handle on one Memory key.
+type memSection[T any] struct{ key string }
+
+// registerMemory registers a Memory key holding a T. Called from package
+// variable initialisers; registering a key twice panics.
+func registerMemory[T any](key string, opts ...memoryOption) memSection[T] {
+	if _, dup := memorySections[key]; dup {
+		panic(fmt.Sprintf("memory section %q registered twice", key))
+	}
+	s := &memorySection{decode: func(raw json.RawMessage) (any, error) {
+		var v T
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	memorySections[key] = s
+	return memSection[T]{key: key}
+}
+
+// get returns the section's value and whether it is set.
+func (s memSection[T]) get(m MemoryStore) (T, bool) {
+	v, ok := m[s.key].(T)
+	return v, ok
+}
+
+func (s memSection[T]) set(m MemoryStore, v T) { m[s.key] = v }
+
+func (s memSection[T]) clear(m MemoryStore) { delete(m, s.key) }
+
+// Memory sections. Keys are the Memory map keys, unchanged from when they
+// were written directly, so tests can still seed Memory with literals.
+var (
+	// Engine bookkeeping.
+	ruleCooldownsMemory = registerMemory[map[string]int]("ruleCooldowns")
+	ruleLastRanMemory   = registerMemory[map[string]int]("ruleLastRan") // for the least_recent tie-break
+	unitHoldsMemory     = registerMemory[map[int]unitHold]("unitHolds")
+	openingStepMemory   = registerMemory[int]("openingProgress")
+	openingDoneMemory   = registerMemory[bool]("openingDone")
+
+	// Intel.
+	enemyBasesMemory         = registerMemory[map[string]EnemyBaseIntel]("enemyBases")
+	enemyStructuresMemory    = registerMemory[map[int]*EnemyStructure]("enemyStructures")
+	enemySeenIDsMemory       = registerMemory[map[int]bool]("enemySeenIDs")
+	enemyUnitsSeenMemory     = registerMemory[map[string]int]("enemyUnitsSeen")
+	enemyBuildingsSeenMemory = registerMemory[map[string]int]("enemyBuildingsSeen")
+	enemySilosMemory         = registerMemory[map[int]*enemySilo]("enemySilos")
+	enemyAAMemory            = registerMemory[map[int]*enemyAASite]("enemyAA")
+	superweaponFiresMemory   = registerMemory[map[string]int]("superweaponFires")
+	builtRolesMemory         = registerMemory[map[string]bool]("builtRoles")
+	defenseWatchMemory       = registerMemory[*defenseWatch]("defenseWatch")
+	keyBuildingHitsMemory    = registerMemory[map[int]keyBuildingHit]("keyBuildingHits")
+	enemyNukeWatchMemory     = registerMemory[*enemyNukeWatch]("enemyNukeWatch")
+	enemyHarvestersMemory    = registerMemory[*harvesterIntel]("enemyHarvesters")
+	gapWatchMemory           = registerMemory[*gapWatch]("gapWatch")
+	paradropWatchMemory      = registerMemory[*paradropWatch]("paradropWatch")
+	bridgeWatchMemory        = registerMemory[*bridgeWatch]("bridgeWatch")
+	queueWatchMemory         = registerMemory[map[string]*queueWatch]("queueWatch")
+	deadlockWatchMemory      = registerMemory[*deadlockWatch]("deadlockWatch")
+	queueReconcileMemory     = registerMemory[bool]("queueReconcile")
+	rushAlertMemory          = registerMemory[*rushAlert]("rushAlert")
+	siegeWatchMemory         = registerMemory[*siegeWatch]("siegeWatch")
+	incomingAttackMemory     = registerMemory[*incomingAttack]("incomingAttack")
+	threatHeatMemory         = registerMemory[*threatMap]("threatHeat")
+	threatUnitPosMemory      = registerMemory[map[int][2]int]("threatUnitPos")
+	coverageMemory           = registerMemory[*coverageMap]("coverage")
+	priorityTargetsMemory    = registerMemory[[]PriorityTarget]("priorityTargets")
+	combatLedgerMemory       = registerMemory[*CombatLedger]("combatLedger")
+	ledgerSightingsMemory    = registerMemory[*ledgerSightings]("ledgerSightings", transient)
+	enemyTracksMemory        = registerMemory[map[int]*enemyTrack]("enemyTracks")
+
+	// Squads and attacks. Squad names and sizes come from the doctrine, so
+	// squads and the per-squad hunt state go with it; attack runs stay so
+	// the outcome of a run cut short by the swap is still recorded.
+	squadsMemory        = registerMemory[map[string]*Squad]("squads", dropOnSwap)
+	huntBaseMemory      = registerMemory[map[string]*huntBaseState]("huntBase", dropOnSwap)
+	attackRunsMemory    = registerMemory[map[string]*attackRun]("attackRuns")
+	attackAdjustMemory  = registerMemory[map[string]*attackAdjust]("attackAdjust")
+	attackHistoryMemory = registerMemory[[]AttackOutcome]("attackHistory")
+
+	// Unit tasks.
+	retreatingMemory       = registerMemory[map[int]int]("retreatingUnits")
+	repairQueueMemory      = registerMemory[*repairQueue]("repairQueue")
+	escortsMemory          = registerMemory[map[int]*escort]("escorts")
+	patrolsMemory          = registerMemory[map[int]*patrol]("patrols", dropOnSwap)
+	defenseStationsMemory  = registerMemory[map[string]*defenseStation]("defenseStations", dropOnSwap)
+	subContactsMemory      = registerMemory[map[int]*subContact]("subContacts")
+	aswHuntersMemory       = registerMemory[map[int]*aswHunter]("aswHunters", dropOnSwap)
+	kitingMemory           = registerMemory[map[int]int]("kitingUnits")
+	scoutIDMemory          = registerMemory[int]("scoutUnitID")
+	scoutTasksMemory       = registerMemory[map[int]*scoutTask]("scoutTasks")
+	scoutWaypointMemory    = registerMemory[int]("scoutWaypointIdx")
+	rangerScoutMemory      = registerMemory[int]("rangerScoutIdx")
+	minelayersMemory       = registerMemory[map[int]bool]("minelayerAssigned")
+	captureHoldMemory      = registerMemory[*captureHold]("captureHold", ownedBy(GroupEngineerCapture))
+	relocationMemory       = registerMemory[*relocation]("relocation")
+	harvesterTracksMemory  = registerMemory[map[int]*harvesterTrack]("harvesterTracks")
+	factoryExitMemory      = registerMemory[map[int]*exitWatch]("factoryExitWatch")
+	unitPositionsMemory    = registerMemory[map[int]*unitHistory]("unitPositions")
+	obstacleFireTickMemory = registerMemory[map[int]int]("obstacleFireTick")
+	stuckSpotsMemory       = registerMemory[map[string]int]("stuckSpots")
+)
+
+// swapDoctrine migrates memory across a rule set swap: sections registered
+// dropOnSwap are discarded, the rest carry over unchanged.
+func (m MemoryStore) swapDoctrine() {
+	for key, s := range memorySections {
+		if s.dropOnSwap {
+			delete(m, key)
+		}
+	}
+}
+
+// persistedMemory is the JSON form of a MemoryStore.
+type persistedMemory struct {
+	Version  int                        `json:"version"`
+	Sections map[string]json.RawMessage `json:"sections"`
+}
+
+// MarshalJSON encodes the registered, non-transient sections.
+func (m MemoryStore) MarshalJSON() ([]byte, error) {
+	out := persistedMemory{Version: memoryVersion, Sections: make(map[string]json.RawMessage)}
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		s, ok := memorySections[key]
+		if !ok || s.transient {
+			continue
+		}
+		raw, err := json.Marshal(m[key])
+		if err != nil {
+			return nil, fmt.Errorf("marshal memory section %s: %w", key, err)
+		}
+		out.Sections[key] = raw
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON replaces the store's contents with the decoded sections.
+// Sections that are no longer registered or fail to decode are skipped
+// with a warning: losing one piece of memory beats losing all of it.
+func (m *MemoryStore) UnmarshalJSON(data []byte) error {
+	var in persistedMemory
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("unmarshal memory: %w", err)
+	}
+	if in.Version != memoryVersion {
+		return fmt.Errorf("memory version %d, want %d", in.Version, memoryVersion)
+	}
+	out := make(MemoryStore, len(in.Sections))
+	for key, raw := range in.Sections {
+		s, ok := memorySections[key]
+		if !ok || s.transient {
+			slog.Warn("skipping unknown memory section", "section", key)
+			continue
+		}
+		v, err := s.decode(raw)
+		if err != nil {
+			slog.Warn("skipping undecodable memory section", "section", key, "error", err)
+			continue
+		}
+		out[key] = v
+	}
+	*m = out
+	return nil
+}
+
+// MarshalMemory encodes the engine's memory for a checkpoint or replay.
+func (e *Engine) MarshalMemory() ([]byte, error) {
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	return json.Marshal(e.Memory)
+}
+
+// RestoreMemory replaces the engine's memory with one encoded by
+// MarshalMemory. On error the current memory is kept.
+func (e *Engine) RestoreMemory(data []byte) error {
+	var m MemoryStore
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	e.memMu.Lock()
+	defer e.memMu.Unlock()
+	clear(e.Memory)
+	maps.Copy(e.Memory, m)
+	return nil
+}
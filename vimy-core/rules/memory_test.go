@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMemoryRoundTrip(t *testing.T) {
+	m := MemoryStore{}
+	squadsMemory.set(m, map[string]*Squad{"attack": {Name: "attack", Domain: "ground", UnitIDs: []int{3, 4}}})
+	retreatingMemory.set(m, map[int]int{7: 120})
+	rushAlertMemory.set(m, &rushAlert{Since: 100, LastSeen: 140, Peak: 5})
+	scoutIDMemory.set(m, 9)
+	stuckSpotsMemory.set(m, map[string]int{stuckSpotKey(10, 10): chronicStuckCount})
+	ledgerSightingsMemory.set(m, &ledgerSightings{cargo: 2})
+	enemyHarvestersMemory.set(m, &harvesterIntel{Checked: map[string]int{oreFieldKey([2]int{5, 6}): 7}})
+	m["strategistScratch"] = "unregistered"
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got MemoryStore
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if sq := getSquads(got)["attack"]; sq == nil || len(sq.UnitIDs) != 2 || sq.UnitIDs[1] != 4 {
+		t.Errorf("squads did not round-trip: %+v", sq)
+	}
+	if r := getRetreatingUnits(got); r[7] != 120 {
+		t.Errorf("retreating units did not round-trip: %v", r)
+	}
+	if a := getRushAlert(got); a == nil || a.Peak != 5 {
+		t.Errorf("rush alert did not round-trip: %+v", a)
+	}
+	if id := getScoutID(got); id != 9 {
+		t.Errorf("scout ID = %d, want 9", id)
+	}
+	if h := getHarvesterIntel(got); h.Checked["5,6"] != 7 {
+		t.Errorf("harvester intel did not round-trip: %+v", h)
+	}
+	if s := (RuleEnv{Memory: got}).ChronicStuckSpots(); len(s) != 1 || s[0] != [2]int{42, 42} {
+		t.Errorf("stuck spots did not round-trip: %v", s)
+	}
+	if _, ok := got["ledgerSightings"]; ok {
+		t.Error("transient section should not be persisted")
+	}
+	if _, ok := got["strategistScratch"]; ok {
+		t.Error("unregistered key should not be persisted")
+	}
+}
+
+func TestMemoryRejectsOtherVersion(t *testing.T) {
+	var m MemoryStore
+	if err := json.Unmarshal([]byte(`{"version":99,"sections":{}}`), &m); err == nil {
+		t.Error("expected an error for a checkpoint from another memory version")
+	}
+}
+
+func TestSwapDropsDoctrineMemory(t *testing.T) {
+	engine, err := NewEngine(nil)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	squadsMemory.set(engine.Memory, map[string]*Squad{"attack": {Name: "attack"}})
+	huntBaseMemory.set(engine.Memory, map[string]*huntBaseState{"attack": {Step: 2}})
+	enemyBasesMemory.set(engine.Memory, map[string]EnemyBaseIntel{"Enemy1": {Owner: "Enemy1", X: 10, Y: 10}})
+
+	if err := engine.Swap(nil); err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if _, ok := squadsMemory.get(engine.Memory); ok {
+		t.Error("squads should be dropped on swap")
+	}
+	if _, ok := huntBaseMemory.get(engine.Memory); ok {
+		t.Error("hunt state should be dropped on swap")
+	}
+	if len(GetEnemyBases(engine.Memory)) != 1 {
+		t.Error("intel should survive a swap")
+	}
+}
+
+func TestRestoreMemory(t *testing.T) {
+	src, _ := NewEngine(nil)
+	builtRolesMemory.set(src.Memory, map[string]bool{"war_factory": true})
+	data, err := src.MarshalMemory()
+	if err != nil {
+		t.Fatalf("MarshalMemory: %v", err)
+	}
+
+	dst, _ := NewEngine(nil)
+	scoutIDMemory.set(dst.Memory, 4)
+	if err := dst.RestoreMemory(data); err != nil {
+		t.Fatalf("RestoreMemory: %v", err)
+	}
+	if built, _ := builtRolesMemory.get(dst.Memory); !built["war_factory"] {
+		t.Error("built roles were not restored")
+	}
+	if _, ok := dst.Memory["scoutUnitID"]; ok {
+		t.Error("restore should replace memory, not merge into it")
+	}
+	if err := dst.RestoreMemory([]byte("not json")); err == nil {
+		t.Error("expected an error for a corrupt checkpoint")
+	}
+	if built, _ := builtRolesMemory.get(dst.Memory); !built["war_factory"] {
+		t.Error("a failed restore should keep the current memory")
+	}
+}
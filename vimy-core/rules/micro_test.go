@@ -427,3 +427,68 @@ func TestCompileDoctrineMicroRulesGating(t *testing.T) {
 		t.Error("unexpected flee-harvesters with EconomyPriority=0.05")
 	}
 }
+
+func TestUnitsToKite(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "arty", X: 50, Y: 50}, // range 10, enemy ~4.5 away — inside 0.5
+				{ID: 2, Type: "e3", X: 80, Y: 80},   // range 5, enemy at 4 — outside 0.5
+				{ID: 3, Type: "2tnk", X: 50, Y: 52}, // not a ranged unit
+				{ID: 4, Type: "v2rl", X: 20, Y: 20}, // only a building nearby
+				{ID: 5, Type: "arty", X: 54, Y: 50}, // retreating
+			},
+			Enemies: []model.Enemy{
+				{ID: 100, Type: "1tnk", X: 54, Y: 52},
+				{ID: 101, Type: "e1", X: 84, Y: 80},
+				{ID: 102, Type: "pbox", X: 21, Y: 20},
+			},
+		},
+		Memory: map[string]any{"retreatingUnits": map[int]int{5: 0}},
+	}
+
+	got := env.UnitsToKite(0.5)
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("UnitsToKite(0.5) = %v, want only unit 1", got)
+	}
+
+	env.Memory["kitingUnits"] = map[int]int{1: 0}
+	if got := env.UnitsToKite(0.5); len(got) != 0 {
+		t.Errorf("unit mid-kite should not be kited again, got %v", got)
+	}
+	if !env.HasKitingUnits() {
+		t.Error("HasKitingUnits = false, want true")
+	}
+}
+
+func TestRetreatingUnitsNotRetasked(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 100, Type: "fact", X: 10, Y: 10}},
+			Units: []model.Unit{
+				{ID: 1, Type: "3tnk", X: 12, Y: 12, HP: 30, MaxHP: 100, Idle: true}, // retreating, parked at base
+				{ID: 2, Type: "3tnk", X: 12, Y: 12, HP: 100, MaxHP: 100, Idle: true},
+				{ID: 3, Type: "mnly", X: 12, Y: 12, HP: 20, MaxHP: 100, Idle: true}, // retreating
+			},
+		},
+		Memory: map[string]any{
+			"retreatingUnits": map[int]int{1: 0, 3: 0},
+		},
+	}
+	for name, units := range map[string][]model.Unit{
+		"IdleGroundUnits":     env.IdleGroundUnits(),
+		"NearBaseGroundUnits": env.NearBaseGroundUnits(),
+		"IdleMinelayers":      env.IdleMinelayers(),
+	} {
+		for _, u := range units {
+			if u.ID == 1 || u.ID == 3 {
+				t.Errorf("%s returned retreating unit %d", name, u.ID)
+			}
+		}
+	}
+	if got := env.IdleGroundUnits(); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("IdleGroundUnits = %v, want only unit 2", got)
+	}
+}
@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mod describes an OpenRA mod's actors in terms of the roles the rules
+// speak. The rules and compiler are written against roles ("barracks",
+// "war_factory"), so pointing them at another mod's role table is enough to
+// drive it. Unit stats and helpers that classify units without a RuleEnv
+// (isAircraft, isNaval, isDefenseType) still use the RA tables.
+type Mod struct {
+	Name          string
+	BasicInfantry string // the cheap rifle infantry the compiler mass-produces
+	roles         map[string]role
+}
+
+// RA is Red Alert, the mod vimy was built for and the default.
+var RA = &Mod{Name: "ra", BasicInfantry: RifleInfantry, roles: roles}
+
+// TD is Tiberian Dawn (OpenRA's "cnc" mod).
+var TD = &Mod{
+	Name:          "cnc",
+	BasicInfantry: "e1",
+	roles: map[string]role{
+		"construction_yard": {queue: QueueBuilding, types: []string{"fact"}},
+		"power_plant":       {queue: QueueBuilding, types: []string{"nuke"}},
+		"advanced_power":    {queue: QueueBuilding, types: []string{"nuk2"}},
+		"refinery":          {queue: QueueBuilding, types: []string{"proc"}},
+		"ore_silo":          {queue: QueueBuilding, types: []string{"silo"}},
+		"barracks":          {queue: QueueBuilding, types: []string{"pyle", "hand"}},
+		"war_factory":       {queue: QueueBuilding, types: []string{"weap", "afld"}},
+		"radar":             {queue: QueueBuilding, types: []string{"hq"}},
+		"tech_center":       {queue: QueueBuilding, types: []string{"eye", "tmpl"}},
+		"airfield":          {queue: QueueBuilding, types: []string{"hpad"}},
+		"service_depot":     {queue: QueueBuilding, types: []string{"fix"}},
+		"harvester":         {queue: QueueVehicle, types: []string{"harv"}},
+		"rocket_soldier":    {queue: QueueInfantry, types: []string{"e3"}},
+		"grenadier":         {queue: QueueInfantry, types: []string{"e2"}},
+		"flamethrower":      {queue: QueueInfantry, types: []string{"e4", "e5"}},
+		"engineer":          {queue: QueueInfantry, types: []string{"e6"}},
+		"tanya":             {queue: QueueInfantry, types: []string{"rmbo"}},
+		"ranger":            {queue: QueueVehicle, types: []string{"jeep", "bggy", "bike"}},
+		"light_tank":        {queue: QueueVehicle, types: []string{"ltnk"}},
+		"medium_tank":       {queue: QueueVehicle, types: []string{"mtnk"}},
+		"heavy_tank":        {queue: QueueVehicle, types: []string{"htnk"}},
+		"artillery":         {queue: QueueVehicle, types: []string{"arty", "msam", "mlrs"}},
+		"apc":               {queue: QueueVehicle, types: []string{"apc"}},
+		"advanced_aircraft": {queue: QueueAircraft, types: []string{"orca", "heli"}},
+		"pillbox":           {queue: QueueDefense, types: []string{"gtwr"}},
+		"turret":            {queue: QueueDefense, types: []string{"gun"}},
+		"tesla_coil":        {queue: QueueDefense, types: []string{"atwr", "obli"}},
+		"aa_defense":        {queue: QueueDefense, types: []string{"sam"}},
+	},
+}
+
+// D2K is Dune 2000. Its heavy vehicles come from a separate "Armor" queue.
+var D2K = &Mod{
+	Name:          "d2k",
+	BasicInfantry: "light_inf",
+	roles: map[string]role{
+		"construction_yard": {queue: QueueBuilding, types: []string{"construction_yard"}},
+		"power_plant":       {queue: QueueBuilding, types: []string{"wind_trap"}},
+		"refinery":          {queue: QueueBuilding, types: []string{"refinery"}},
+		"ore_silo":          {queue: QueueBuilding, types: []string{"silo"}},
+		"barracks":          {queue: QueueBuilding, types: []string{"barracks"}},
+		"war_factory":       {queue: QueueBuilding, types: []string{"light_factory", "heavy_factory"}},
+		"radar":             {queue: QueueBuilding, types: []string{"outpost"}},
+		"tech_center":       {queue: QueueBuilding, types: []string{"research_centre"}},
+		"airfield":          {queue: QueueBuilding, types: []string{"high_tech_factory"}},
+		"service_depot":     {queue: QueueBuilding, types: []string{"repair_pad"}},
+		"harvester":         {queue: d2kQueueArmor, types: []string{"harvester"}},
+		"rocket_soldier":    {queue: QueueInfantry, types: []string{"trooper"}},
+		"engineer":          {queue: QueueInfantry, types: []string{"engineer"}},
+		"shock_trooper":     {queue: QueueInfantry, types: []string{"fremen", "sardaukar"}},
+		"ranger":            {queue: QueueVehicle, types: []string{"trike", "raider", "quad"}},
+		"medium_tank":       {queue: d2kQueueArmor, types: []string{"combat_tank_a", "combat_tank_h", "combat_tank_o"}},
+		"heavy_tank":        {queue: d2kQueueArmor, types: []string{"devastator"}},
+		"artillery":         {queue: d2kQueueArmor, types: []string{"siege_tank"}},
+		"v2_launcher":       {queue: d2kQueueArmor, types: []string{"missile_tank"}},
+		"advanced_aircraft": {queue: QueueAircraft, types: []string{"ornithopter"}},
+		"turret":            {queue: QueueBuilding, types: []string{"medium_gun_turret"}},
+		"aa_defense":        {queue: QueueBuilding, types: []string{"large_gun_turret"}},
+	},
+}
+
+const d2kQueueArmor = "Armor"
+
+// mods maps the mod id the sidecar reports in Hello to its role table.
+// "vimy" is this repo's own RA-based mod.
+var mods = map[string]*Mod{
+	"ra":   RA,
+	"vimy": RA,
+	"cnc":  TD,
+	"d2k":  D2K,
+}
+
+// LookupMod returns the Mod for a Hello mod id.
+func LookupMod(id string) (*Mod, bool) {
+	m, ok := mods[strings.ToLower(id)]
+	return m, ok
+}
+
+// ModNames returns the known mod ids, sorted.
+func ModNames() []string {
+	names := make([]string, 0, len(mods))
+	for n := range mods {
+		names = append(names, n)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// role looks up a role in the mod's table. A nil Mod is RA.
+func (m *Mod) role(name string) (role, bool) {
+	if m == nil {
+		m = RA
+	}
+	r, ok := m.roles[name]
+	return r, ok
+}
+
+// basicInfantry returns the mod's rifle infantry type. A nil Mod is RA.
+func (m *Mod) basicInfantry() string {
+	if m == nil {
+		m = RA
+	}
+	return m.BasicInfantry
+}
+
+// HasRole reports whether the mod has any actor filling the role.
+func (m *Mod) HasRole(name string) bool {
+	_, ok := m.role(name)
+	return ok
+}
+
+// roleConjunct matches a top-level conjunct that requires a role to exist.
+var roleConjunct = regexp.MustCompile(`^(?:HasRole|CanBuildRole)\("([a-z_]+)"\)$`)
+
+// pruneForMod drops rules that can never match in m: those with a top-level
+// HasRole or CanBuildRole conjunct naming a role the mod doesn't have.
+func pruneForMod(rs []*Rule, m *Mod) []*Rule {
+	if m == nil || m == RA {
+		return rs
+	}
+	out := rs[:0:0]
+	for _, r := range rs {
+		if !needsMissingRole(r, m) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func needsMissingRole(r *Rule, m *Mod) bool {
+	for _, c := range conjuncts(r.ConditionSrc) {
+		if sub := roleConjunct.FindStringSubmatch(c); sub != nil && !m.HasRole(sub[1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestLookupMod(t *testing.T) {
+	for id, want := range map[string]*Mod{"ra": RA, "vimy": RA, "CNC": TD, "d2k": D2K} {
+		if m, ok := LookupMod(id); !ok || m != want {
+			t.Errorf("LookupMod(%q) = %v, %v; want %s", id, m, ok, want.Name)
+		}
+	}
+	if _, ok := LookupMod("ts"); ok {
+		t.Error("expected unknown mod to be missing")
+	}
+}
+
+func TestEnvRolesFollowMod(t *testing.T) {
+	state := model.GameState{
+		Buildings: []model.Building{{ID: 1, Type: "pyle"}},
+		ProductionQueues: []model.ProductionQueue{
+			{Type: QueueVehicle, Buildable: []string{"mtnk"}},
+		},
+	}
+	td := RuleEnv{State: state, Mod: TD, Memory: map[string]any{}}
+	if !td.HasRole("barracks") || !td.CanBuildRole("medium_tank") {
+		t.Error("TD env should see the pyle as barracks and mtnk as a medium tank")
+	}
+	ra := RuleEnv{State: state, Memory: map[string]any{}}
+	if ra.HasRole("barracks") || ra.CanBuildRole("medium_tank") {
+		t.Error("RA env should not recognise TD actors")
+	}
+}
+
+func TestCompileDoctrineForMod(t *testing.T) {
+	d := DefaultDoctrine()
+	d.NavalWeight = 0.8
+	d.SuperweaponPriority = 0.9
+
+	ra := CompileDoctrine(d)
+	if got := CompileDoctrineForMod(d, RA); len(got) != len(ra) {
+		t.Errorf("RA compile has %d rules, CompileDoctrine %d", len(got), len(ra))
+	}
+
+	td := CompileDoctrineForMod(d, TD)
+	if len(td) >= len(ra) {
+		t.Errorf("TD compile kept %d of %d rules; expected RA-only rules pruned", len(td), len(ra))
+	}
+	for _, r := range td {
+		if strings.Contains(r.ConditionSrc, `CanBuildRole("naval_yard")`) || strings.Contains(r.ConditionSrc, `CanBuildRole("missile_silo")`) {
+			t.Errorf("TD rule %s needs a role TD lacks: %s", r.Name, r.ConditionSrc)
+		}
+	}
+	if _, err := NewEngine(td); err != nil {
+		t.Fatalf("TD rules don't compile: %v", err)
+	}
+
+	var found bool
+	for _, r := range CompileDoctrineForMod(d, D2K) {
+		if r.Name == "produce-infantry" {
+			found = true
+			if !strings.Contains(r.ConditionSrc, `CanBuild("Infantry","light_inf")`) {
+				t.Errorf("D2K infantry rule should build light_inf: %s", r.ConditionSrc)
+			}
+		}
+	}
+	if !found {
+		t.Error("D2K compile has no produce-infantry rule")
+	}
+}
@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Parallel production. With per-building queues every extra barracks or war
+// factory is a queue of its own, but a bare Produce command only ever
+// reaches the first free one. The general unit production actions instead
+// address each free queue of the type by its actor, so all of them work in
+// the same tick — as many as the cash on hand pays for.
+
+// freeQueues returns the queues of type q that are free (as QueueBusy
+// counts them) and can build item, one per queue actor.
+func (e RuleEnv) freeQueues(q, item string) []model.ProductionQueue {
+	var out []model.ProductionQueue
+	for _, pq := range e.State.ProductionQueues {
+		if !strings.EqualFold(pq.Type, q) || (pq.CurrentItem != "" && pq.CurrentProgress < 100) {
+			continue
+		}
+		if len(pq.Buildable) > 0 && !slices.Contains(pq.Buildable, item) {
+			continue
+		}
+		if pq.ActorID != 0 && slices.ContainsFunc(out, func(o model.ProductionQueue) bool { return o.ActorID == pq.ActorID }) {
+			continue // shared per-player queue reported once per type
+		}
+		out = append(out, pq)
+	}
+	return out
+}
+
+// produceCommands returns the Produce commands that start item on every
+// free queue of type q we can afford. A single free queue, or queues the
+// sidecar didn't identify, get the plain untargeted command.
+func (e RuleEnv) produceCommands(q, item string) []ipc.ProduceCommand {
+	single := []ipc.ProduceCommand{{Queue: q, Item: item, Count: 1}}
+	free := e.freeQueues(q, item)
+	if len(free) <= 1 || slices.ContainsFunc(free, func(pq model.ProductionQueue) bool { return pq.ActorID == 0 }) {
+		return single
+	}
+	n := len(free)
+	if cost := costOf(item); cost > 0 {
+		n = min(n, max(1, e.Cash()/cost))
+	}
+	if n == 1 {
+		return single
+	}
+	cmds := make([]ipc.ProduceCommand, n)
+	for i, pq := range free[:n] {
+		cmds[i] = ipc.ProduceCommand{Queue: q, Item: item, Count: 1, QueueActor: uint32(pq.ActorID)}
+	}
+	return cmds
+}
+
+// produceParallel sends produceCommands(q, item).
+func produceParallel(env RuleEnv, conn *ipc.Connection, q, item string) error {
+	cmds := env.produceCommands(q, item)
+	if len(cmds) > 1 {
+		slog.Debug("producing in parallel", "queue", q, "item", item, "queues", len(cmds))
+	}
+	for _, cmd := range cmds {
+		if err := conn.Send(ipc.TypeProduce, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
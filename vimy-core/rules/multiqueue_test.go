@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestProduceCommandsUsesEveryFreeQueue(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Player: model.Player{Cash: 5000},
+			ProductionQueues: []model.ProductionQueue{
+				{ActorID: 10, Type: "Infantry", Buildable: []string{"e1", "e3"}},
+				{ActorID: 11, Type: "Infantry", Buildable: []string{"e1"}, CurrentItem: "e1", CurrentProgress: 40}, // busy
+				{ActorID: 12, Type: "Infantry", Buildable: []string{"e1", "e3"}},
+				{ActorID: 13, Type: "Infantry", Buildable: []string{"e1"}}, // can't build e3
+				{ActorID: 20, Type: "Vehicle", Buildable: []string{"2tnk"}},
+			},
+		},
+	}
+
+	cmds := env.produceCommands(QueueInfantry, "e3")
+	if len(cmds) != 2 || cmds[0].QueueActor != 10 || cmds[1].QueueActor != 12 {
+		t.Fatalf("produceCommands(e3) = %+v, want targeted commands for queues 10 and 12", cmds)
+	}
+
+	// Only as many as we can pay for.
+	env.State.Player.Cash = costOf("e1") * 2
+	if cmds := env.produceCommands(QueueInfantry, "e1"); len(cmds) != 2 {
+		t.Errorf("produceCommands(e1) with cash for two = %d commands, want 2", len(cmds))
+	}
+
+	// A lone free queue gets the plain untargeted command.
+	cmds = env.produceCommands(QueueVehicle, "2tnk")
+	if len(cmds) != 1 || cmds[0].QueueActor != 0 {
+		t.Errorf("produceCommands(2tnk) = %+v, want one untargeted command", cmds)
+	}
+}
+
+func TestProduceCommandsSharedPlayerQueue(t *testing.T) {
+	// Per-player queues all live on the player actor: one queue per type.
+	env := RuleEnv{
+		State: model.GameState{
+			Player: model.Player{Cash: 5000},
+			ProductionQueues: []model.ProductionQueue{
+				{ActorID: 1, Type: "Infantry", Buildable: []string{"e1"}},
+				{ActorID: 1, Type: "Vehicle", Buildable: []string{"2tnk"}},
+			},
+		},
+	}
+	cmds := env.produceCommands(QueueInfantry, "e1")
+	if len(cmds) != 1 || cmds[0].QueueActor != 0 {
+		t.Errorf("produceCommands = %+v, want one untargeted command", cmds)
+	}
+}
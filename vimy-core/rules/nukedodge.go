@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Nuke dodging. Once the enemy is known to have a missile silo, a tight
+// base hands it the construction yard, war factory and refinery in one
+// strike, so new high-value buildings are spaced out of each other's blast
+// radius. The enemy's launch itself shows up as its nuke timer resetting;
+// squads spread the moment that happens instead of waiting out a warning
+// window that has already passed.
+
+const (
+	nukeSpacingSearch = 8   // cells a high-value building's hint may move to get clear
+	nukeFlightTicks   = 400 // launch to impact, NukePower's default FlightDelay
+)
+
+// enemyNukeWatch tracks the enemy nuke timers between updates to notice
+// launches.
+type enemyNukeWatch struct {
+	Timers     map[string]model.EnemySupportPower // by owner and key
+	LaunchTick int                                // last launch seen; 0 if none
+}
+
+func isNukePower(key string) bool {
+	return strings.Contains(strings.ToLower(key), "nuke")
+}
+
+// updateEnemyNukeLaunch records an enemy launch: a nuke timer that was
+// ready and no longer is, or one that started counting down again.
+func updateEnemyNukeLaunch(env RuleEnv) {
+	w, _ := enemyNukeWatchMemory.get(env.Memory)
+	if w == nil {
+		w = &enemyNukeWatch{}
+		enemyNukeWatchMemory.set(env.Memory, w)
+	}
+	timers := make(map[string]model.EnemySupportPower)
+	for _, sp := range env.State.EnemySupportPowers {
+		if !isNukePower(sp.Key) {
+			continue
+		}
+		k := sp.Owner + "/" + sp.Key
+		timers[k] = sp
+		prev, ok := w.Timers[k]
+		if !ok {
+			continue
+		}
+		if (prev.Ready && !sp.Ready) || sp.RemainingTicks > prev.RemainingTicks {
+			w.LaunchTick = env.State.Tick
+			slog.Warn("enemy nuke launched", "owner", sp.Owner, "tick", env.State.Tick)
+		}
+	}
+	w.Timers = timers
+}
+
+// EnemyNukeLaunched reports whether an enemy nuke was launched recently
+// enough to still be in the air.
+func (e RuleEnv) EnemyNukeLaunched() bool {
+	w, _ := enemyNukeWatchMemory.get(e.Memory)
+	return w != nil && w.LaunchTick > 0 && e.State.Tick-w.LaunchTick <= nukeFlightTicks
+}
+
+// enemyHasNuke reports whether any enemy missile silo is known, seen or by
+// its timer.
+func (e RuleEnv) enemyHasNuke() bool {
+	if e.HasKnownEnemySilo() {
+		return true
+	}
+	for _, sp := range e.State.EnemySupportPowers {
+		if isNukePower(sp.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// nukeSpaced moves a high-value building's hint to the nearest land at
+// least nukeSpreadRadius from every other high-value building, while the
+// enemy has a nuke. The hint is returned unchanged otherwise, or when
+// nothing within nukeSpacingSearch is clear.
+func (e RuleEnv) nukeSpaced(item string, x, y int) (int, int) {
+	if !isHighValueBuilding(item) || !e.enemyHasNuke() {
+		return x, y
+	}
+	var hv []model.Building
+	for _, b := range e.State.Buildings {
+		if isHighValueBuilding(b.Type) {
+			hv = append(hv, b)
+		}
+	}
+	clear := func(px, py int) bool {
+		if e.State.MapWidth > 0 && (px < 0 || py < 0 || px >= e.State.MapWidth || py >= e.State.MapHeight) {
+			return false
+		}
+		if e.Terrain != nil && e.Terrain.AtMapPos(px, py) != model.Land {
+			return false
+		}
+		for _, b := range hv {
+			dx, dy := px-b.X, py-b.Y
+			if dx*dx+dy*dy < nukeSpreadRadius*nukeSpreadRadius {
+				return false
+			}
+		}
+		return true
+	}
+	for r := 0; r <= nukeSpacingSearch; r++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if max(abs(dx), abs(dy)) == r && clear(x+dx, y+dy) {
+					return x + dx, y + dy
+				}
+			}
+		}
+	}
+	return x, y
+}
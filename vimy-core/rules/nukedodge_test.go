@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestEnemyNukeLaunchDetected(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:               100,
+			EnemySupportPowers: []model.EnemySupportPower{{Owner: "red", Key: "NukePowerInfoOrder", RemainingTicks: 50, TotalTicks: 13500}},
+		},
+		Memory: make(map[string]any),
+	}
+	updateEnemyNukeLaunch(env)
+	env.State.Tick = 150
+	env.State.EnemySupportPowers[0] = model.EnemySupportPower{Owner: "red", Key: "NukePowerInfoOrder", Ready: true, TotalTicks: 13500}
+	updateEnemyNukeLaunch(env)
+	if env.EnemyNukeLaunched() {
+		t.Fatal("EnemyNukeLaunched while the nuke is only charged")
+	}
+
+	// Fired: the timer starts over.
+	env.State.Tick = 200
+	env.State.EnemySupportPowers[0] = model.EnemySupportPower{Owner: "red", Key: "NukePowerInfoOrder", RemainingTicks: 13490, TotalTicks: 13500}
+	updateEnemyNukeLaunch(env)
+	if !env.EnemyNukeLaunched() {
+		t.Fatal("launch not detected when the timer reset")
+	}
+
+	env.State.Tick = 200 + nukeFlightTicks + 1
+	env.State.EnemySupportPowers[0].RemainingTicks = 13000
+	updateEnemyNukeLaunch(env)
+	if env.EnemyNukeLaunched() {
+		t.Error("EnemyNukeLaunched after the missile must have landed")
+	}
+}
+
+func TestNukeSpacedHighValueBuildings(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 50, Y: 50},
+				{ID: 2, Type: "powr", X: 53, Y: 50},
+			},
+			MapWidth:  128,
+			MapHeight: 128,
+		},
+		Memory: make(map[string]any),
+	}
+	if x, y := env.nukeSpaced("weap", 52, 50); x != 52 || y != 50 {
+		t.Errorf("hint moved to %d,%d with no enemy nuke known", x, y)
+	}
+
+	enemySilosMemory.set(env.Memory, map[int]*enemySilo{9: {X: 100, Y: 100}})
+	x, y := env.nukeSpaced("weap", 52, 50)
+	if dx, dy := x-50, y-50; dx*dx+dy*dy < nukeSpreadRadius*nukeSpreadRadius {
+		t.Errorf("war factory hinted at %d,%d, inside the yard's blast radius", x, y)
+	}
+	if x, y := env.nukeSpaced("powr", 52, 50); x != 52 || y != 50 {
+		t.Errorf("power plant moved to %d,%d; only high-value buildings are spaced", x, y)
+	}
+}
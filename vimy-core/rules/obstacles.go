@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Obstacle clearing thresholds.
+const (
+	obstacleRouteWidth = 1.5 // cells either side of the direct line that count as "on the route"
+	obstacleFireTicks  = 150 // re-issue force-fire this often while an obstacle stands
+	obstacleWallRadius = 2.0 // obstacles this close together form a wall, not a lone tree
+)
+
+// routeTarget is where a unit is trying to go: the current best ground
+// target, else the nearest visible enemy, else the nearest known enemy base.
+func (e RuleEnv) routeTarget() (x, y int, ok bool) {
+	if t := e.BestGroundTarget(); t != nil {
+		return t.X, t.Y, true
+	}
+	if en := e.NearestEnemy(); en != nil {
+		return en.X, en.Y, true
+	}
+	if base := e.NearestEnemyBase(); base != nil {
+		return base.X, base.Y, true
+	}
+	return 0, 0, false
+}
+
+// obstacleOnRoute returns the destructible obstacle nearest (x0, y0) that
+// lies within obstacleRouteWidth of the line to (x1, y1) and within reach
+// cells of the start, or nil.
+func (e RuleEnv) obstacleOnRoute(x0, y0, x1, y1 int, reach float64) *model.Obstacle {
+	dx, dy := float64(x1-x0), float64(y1-y0)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return nil
+	}
+	var best *model.Obstacle
+	bestAlong := math.MaxFloat64
+	for i := range e.State.Obstacles {
+		o := &e.State.Obstacles[i]
+		if isBridge(o.Type) {
+			continue // the route may run over it
+		}
+		ox, oy := float64(o.X-x0), float64(o.Y-y0)
+		along := (ox*dx + oy*dy) / length
+		if along < 0 || along > length || along > reach {
+			continue
+		}
+		across := math.Abs(ox*dy-oy*dx) / length
+		if across <= obstacleRouteWidth && along < bestAlong {
+			bestAlong = along
+			best = o
+		}
+	}
+	return best
+}
+
+// obstacleWall reports whether o has another obstacle within
+// obstacleWallRadius — a tree line or wall units can't simply drive around.
+func (e RuleEnv) obstacleWall(o *model.Obstacle) bool {
+	for _, other := range e.State.Obstacles {
+		if other.ID != o.ID && math.Hypot(float64(other.X-o.X), float64(other.Y-o.Y)) <= obstacleWallRadius {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockingObstacle returns the obstacle u should force-fire to clear its
+// direct route to its target: the nearest one on the route within weapon
+// range that is part of a wall, or any such obstacle if u is already
+// flagged stuck by the watchdog. Nil if the route is clear.
+func (e RuleEnv) BlockingObstacle(u model.Unit) *model.Obstacle {
+	tx, ty, ok := e.routeTarget()
+	if !ok {
+		return nil
+	}
+	o := e.obstacleOnRoute(u.X, u.Y, tx, ty, rangeOf(u.Type))
+	if o == nil {
+		return nil
+	}
+	if e.obstacleWall(o) || slices.Contains(e.StuckUnits(), u.ID) {
+		return o
+	}
+	return nil
+}
+
+// BlockedSiegeUnits returns siege vehicles whose direct route to their
+// target is blocked by a destructible obstacle in weapon range — cheaper to
+// shoot through than to path around.
+func (e RuleEnv) BlockedSiegeUnits() []model.Unit {
+	if len(e.State.Obstacles) == 0 {
+		return nil
+	}
+	var out []model.Unit
+	for _, u := range e.State.Units {
+		if !matchesType(u.Type, Artillery) && !matchesType(u.Type, V2Launcher) {
+			continue
+		}
+		if e.BlockingObstacle(u) != nil {
+			out = append(out, u)
+		}
+	}
+	return out
+}
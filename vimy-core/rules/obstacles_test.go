@@ -0,0 +1,40 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestBlockedSiegeUnits(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "arty", X: 10, Y: 50}, // tree line 5 cells ahead
+				{ID: 2, Type: "arty", X: 10, Y: 80}, // lone tree ahead
+				{ID: 3, Type: "2tnk", X: 10, Y: 50}, // not a siege unit
+			},
+			Enemies: []model.Enemy{{ID: 99, Type: "fact", X: 60, Y: 50}},
+			Obstacles: []model.Obstacle{
+				{ID: 20, Type: "t01", X: 15, Y: 50},
+				{ID: 21, Type: "t02", X: 15, Y: 51},
+				{ID: 22, Type: "t03", X: 15, Y: 78},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	got := env.BlockedSiegeUnits()
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("BlockedSiegeUnits = %v, want only unit 1", got)
+	}
+	if o := env.BlockingObstacle(got[0]); o == nil || o.ID != 20 {
+		t.Errorf("BlockingObstacle = %+v, want obstacle 20", o)
+	}
+
+	// An obstacle beyond weapon range is left alone.
+	env.State.Units[0].X = -5
+	if got := env.BlockedSiegeUnits(); len(got) != 0 {
+		t.Errorf("obstacle out of range should not block, got %v", got)
+	}
+}
@@ -0,0 +1,145 @@
+package rules
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// OpeningBook is a scripted early build order. Steps are building roles
+// queued strictly in order; once every step stands, the tick limit passes,
+// or a step can't be built, the book hands the Building queue back to the
+// doctrine's rules for the rest of the game.
+type OpeningBook struct {
+	Name     string
+	Steps    []string
+	MaxTicks int
+}
+
+// openingBooks are the selectable openings, keyed by Doctrine.Opening.
+var openingBooks = map[string]OpeningBook{
+	"standard": {
+		Name:     "standard",
+		Steps:    []string{"power_plant", "barracks", "refinery", "power_plant", "war_factory"},
+		MaxTicks: 6000,
+	},
+	"rush": {
+		Name:     "rush",
+		Steps:    []string{"power_plant", "barracks", "barracks", "refinery"},
+		MaxTicks: 4500,
+	},
+	"greedy": {
+		Name:     "greedy",
+		Steps:    []string{"power_plant", "refinery", "refinery", "power_plant", "barracks", "war_factory"},
+		MaxTicks: 7500,
+	},
+}
+
+// LookupOpening returns the named opening book.
+func LookupOpening(name string) (OpeningBook, bool) {
+	b, ok := openingBooks[name]
+	return b, ok
+}
+
+// OpeningNames lists the available opening books, sorted.
+func OpeningNames() []string {
+	names := make([]string, 0, len(openingBooks))
+	for n := range openingBooks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openingProgress returns how many leading steps of the book are built. A
+// step counts as built when we own as many of its role as the book has
+// asked for so far, so repeated steps (a second power plant) need a second
+// building. Progress never moves backwards: losing a building mid-opening
+// is the rebuild rules' problem, not the book's.
+func (e RuleEnv) openingProgress(b OpeningBook) int {
+	done, _ := openingStepMemory.get(e.Memory)
+	for done < len(b.Steps) {
+		role := b.Steps[done]
+		want := 0
+		for _, s := range b.Steps[:done+1] {
+			if s == role {
+				want++
+			}
+		}
+		if e.RoleCount(role) < want {
+			break
+		}
+		done++
+	}
+	return done
+}
+
+// OpeningActive reports whether the named opening book still owns the
+// Building queue.
+func (e RuleEnv) OpeningActive(name string) bool {
+	b, ok := LookupOpening(name)
+	if !ok {
+		return false
+	}
+	if done, _ := openingDoneMemory.get(e.Memory); done {
+		return false
+	}
+	return e.State.Tick <= b.MaxTicks
+}
+
+// FollowOpening queues the next step of the book once the Building queue is
+// free, and retires the book when it completes or stalls on a step we can't
+// build.
+func FollowOpening(b OpeningBook) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		step := env.openingProgress(b)
+		openingStepMemory.set(env.Memory, step)
+		if step >= len(b.Steps) {
+			openingDoneMemory.set(env.Memory, true)
+			slog.Info("opening complete", "opening", b.Name, "tick", env.State.Tick)
+			return nil
+		}
+		if env.QueueBusy(QueueBuilding) {
+			return nil
+		}
+		role := b.Steps[step]
+		item := env.BuildableType(role)
+		if item == "" {
+			openingDoneMemory.set(env.Memory, true)
+			slog.Info("opening abandoned, step not buildable", "opening", b.Name, "step", step, "role", role)
+			return nil
+		}
+		slog.Debug("opening step", "opening", b.Name, "step", step, "item", item)
+		return conn.Send(ipc.TypeProduce, ipc.ProduceCommand{
+			Queue: QueueBuilding,
+			Item:  item,
+			Count: 1,
+		})
+	}
+}
+
+// OpeningRules returns the rule that runs the named opening book, or nil for
+// an empty or unknown name. It sits just below building placement and holds
+// the exclusive economy category while active, so no doctrine build rule
+// can jump the scripted order.
+func OpeningRules(name string) []*Rule {
+	if name == "" {
+		return nil
+	}
+	b, ok := LookupOpening(name)
+	if !ok {
+		slog.Warn("unknown opening book, skipping", "opening", name, "available", OpeningNames())
+		return nil
+	}
+	return []*Rule{{
+		Name:            "opening-book",
+		Priority:        880,
+		Category:        "economy",
+		Exclusive:       true,
+		ActiveUntilTick: b.MaxTicks,
+		ConditionSrc:    fmt.Sprintf(`HasRole("construction_yard") && OpeningActive(%q)`, b.Name),
+		Action:          FollowOpening(b),
+	}}
+}
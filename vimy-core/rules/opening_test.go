@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestOpeningProgressCountsRepeatedSteps(t *testing.T) {
+	b, _ := LookupOpening("standard")
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact"},
+				{ID: 2, Type: "powr"},
+				{ID: 3, Type: "tent"},
+				{ID: 4, Type: "proc"},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	// The second power plant step needs a second powr.
+	if got := env.openingProgress(b); got != 3 {
+		t.Fatalf("progress = %d, want 3", got)
+	}
+	env.State.Buildings = append(env.State.Buildings, model.Building{ID: 5, Type: "powr"})
+	if got := env.openingProgress(b); got != 4 {
+		t.Errorf("progress with second power plant = %d, want 4", got)
+	}
+}
+
+func TestFollowOpeningRetiresBook(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	b, _ := LookupOpening("rush")
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:             100,
+			Buildings:        []model.Building{{ID: 1, Type: "fact"}, {ID: 2, Type: "powr"}},
+			ProductionQueues: []model.ProductionQueue{{Type: "Building", Buildable: []string{"powr", "proc"}}},
+		},
+		Memory: mem,
+	}
+	if !env.OpeningActive("rush") {
+		t.Fatal("opening should be active at tick 100")
+	}
+
+	// Barracks isn't buildable: the book hands over instead of stalling.
+	if err := FollowOpening(b)(env, conn); err != nil {
+		t.Fatalf("FollowOpening: %v", err)
+	}
+	if env.OpeningActive("rush") {
+		t.Error("opening still active after an unbuildable step")
+	}
+
+	delete(mem, "openingDone")
+	env.State.Tick = b.MaxTicks + 1
+	if env.OpeningActive("rush") {
+		t.Error("opening still active past its tick limit")
+	}
+}
+
+func TestCompileDoctrineOpening(t *testing.T) {
+	has := func(rules []*Rule) bool {
+		for _, r := range rules {
+			if r.Name == "opening-book" {
+				return true
+			}
+		}
+		return false
+	}
+	d := DefaultDoctrine()
+	if has(CompileDoctrine(d)) {
+		t.Error("opening-book compiled without an opening")
+	}
+	d.Opening = "greedy"
+	if !has(CompileDoctrine(d)) {
+		t.Error("opening-book missing for greedy opening")
+	}
+	if _, err := NewEngine(CompileDoctrine(d)); err != nil {
+		t.Errorf("opening rule failed to compile: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// RuleOverride adjusts a single rule by name without touching the doctrine.
+// Lets an operator silence a misbehaving rule or retune it mid-match while
+// the LLM keeps producing doctrines — overrides survive every Swap.
+type RuleOverride struct {
+	Disabled      bool               `json:"disabled,omitempty"`
+	Priority      *int               `json:"priority,omitempty"`
+	CooldownTicks *int               `json:"cooldown_ticks,omitempty"`
+	Thresholds    map[string]float64 `json:"thresholds,omitempty"` // numeric literal in the condition → replacement value
+}
+
+// RuleOverrides is keyed by rule name (e.g. "build-power", "squad-attack").
+type RuleOverrides map[string]RuleOverride
+
+// LoadOverrides reads a JSON overrides file, e.g.
+//
+//	{"scout-with-idle-units": {"disabled": true},
+//	 "build-power": {"priority": 820, "thresholds": {"500": 300}},
+//	 "medics-follow-squads": {"cooldown_ticks": 150}}
+func LoadOverrides(path string) (RuleOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read overrides: %w", err)
+	}
+	var o RuleOverrides
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("unmarshal overrides: %w", err)
+	}
+	return o, nil
+}
+
+// numericLiteral matches standalone numbers in an expr condition. Word
+// boundaries keep it from matching digits inside type codes like "e1" or "3tnk".
+var numericLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+
+// applyOverrides returns copies of rules with overrides applied. The input
+// rules are left untouched so the engine can re-derive the effective set
+// whenever the overrides change. Disabled rules are dropped entirely.
+func applyOverrides(rules []*Rule, overrides RuleOverrides) []*Rule {
+	out := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		cp := *r
+		o, ok := overrides[r.Name]
+		if !ok {
+			out = append(out, &cp)
+			continue
+		}
+		if o.Disabled {
+			slog.Debug("rule disabled by override", "rule", r.Name)
+			continue
+		}
+		if o.Priority != nil {
+			cp.Priority = *o.Priority
+		}
+		if o.CooldownTicks != nil {
+			cp.CooldownTicks = *o.CooldownTicks
+		}
+		if len(o.Thresholds) > 0 {
+			cp.ConditionSrc = numericLiteral.ReplaceAllStringFunc(cp.ConditionSrc, func(lit string) string {
+				if v, ok := o.Thresholds[lit]; ok {
+					return strconv.FormatFloat(v, 'f', -1, 64)
+				}
+				return lit
+			})
+		}
+		out = append(out, &cp)
+	}
+	return out
+}
+
+// CheckOverrides reports override entries that would silently do nothing
+// against rs: rule names that don't exist and threshold literals that don't
+// appear in the rule's condition. Conditions that no longer compile are
+// reported by Engine.SetOverrides.
+func CheckOverrides(rs []*Rule, overrides RuleOverrides) []string {
+	byName := make(map[string]*Rule, len(rs))
+	for _, r := range rs {
+		byName[r.Name] = r
+	}
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		r, ok := byName[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: no such rule", name))
+			continue
+		}
+		present := make(map[string]bool)
+		for _, lit := range numericLiteral.FindAllString(r.ConditionSrc, -1) {
+			present[lit] = true
+		}
+		for _, lit := range slices.Sorted(maps.Keys(overrides[name].Thresholds)) {
+			if !present[lit] {
+				problems = append(problems, fmt.Sprintf("%s: threshold %s not in condition %q", name, lit, r.ConditionSrc))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,56 @@
+package rules
+
+// Task owners. Every unit has at most one; idle-unit selectors hand out
+// only units nobody owns, so a scramble or scout rule can't pull a squad
+// member out of its fight. Base-defense emergencies override this (see
+// AllIdleGroundUnits).
+const (
+	ownerSquad   = "squad"
+	ownerEscort  = "escort"
+	ownerScout   = "scout"
+	ownerCapture = "capture"
+	ownerPatrol  = "patrol"
+	ownerASW     = "asw"
+)
+
+// unitOwners is the assignment ledger: the task owner of every owned unit.
+// It is assembled from the state each system already keeps (squad rosters,
+// escort assignments, the scout designation and scout tasks, the capture
+// hold, perimeter patrols, sub hunters) rather than stored separately, so it can't drift from them. Should
+// two systems claim a unit, the first in the order above keeps it.
+func (e RuleEnv) unitOwners() map[int]string {
+	owners := make(map[int]string)
+	claim := func(id int, owner string) {
+		if _, ok := owners[id]; !ok {
+			owners[id] = owner
+		}
+	}
+	for id := range squadUnitIDSet(e.Memory) {
+		claim(id, ownerSquad)
+	}
+	for id := range getEscorts(e.Memory) {
+		claim(id, ownerEscort)
+	}
+	if id := getScoutID(e.Memory); id != 0 {
+		claim(id, ownerScout)
+	}
+	for id := range getScoutTasks(e.Memory) {
+		claim(id, ownerScout)
+	}
+	if h := getCaptureHold(e.Memory); h != nil && h.Engineer != 0 && e.State.Tick-h.Tick < captureHoldTicks {
+		claim(h.Engineer, ownerCapture)
+	}
+	for id := range getPatrols(e.Memory) {
+		claim(id, ownerPatrol)
+	}
+	for id := range getASWHunters(e.Memory) {
+		claim(id, ownerASW)
+	}
+	return owners
+}
+
+// UnitOwner returns the task owning the unit ("squad", "escort", "scout",
+// "capture", "patrol" or "asw"), or "" if it is free.
+func (e RuleEnv) UnitOwner(id int) string {
+	return e.unitOwners()[id]
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Paratrooper follow-up. Dropped infantry land idle deep in enemy
+// territory, where the idle-unit rules would march them home to join a
+// squad. New infantry that appears far from any of our buildings shortly
+// after we fire paratroopers can only have come by air, so it is gathered
+// into an ad-hoc "paradrop" squad and sent at the nearest enemy structure
+// we know of.
+
+const (
+	paradropSquad       = "paradrop"
+	paradropMinDistance = 15.0 // cells from our nearest building for new infantry to count as dropped
+	paradropWindow      = 750  // ticks after firing that the drop can still land
+)
+
+// paradropWatch tracks every unit ID we have had, so new arrivals can be
+// told apart from passengers reappearing out of a transport, and when
+// paratroopers were last fired.
+type paradropWatch struct {
+	Known map[int]bool
+	Fired int // tick; 0 if never
+}
+
+// recordParadrop notes that paratroopers are on their way.
+func recordParadrop(env RuleEnv) {
+	w, _ := paradropWatchMemory.get(env.Memory)
+	if w == nil {
+		w = &paradropWatch{Known: make(map[int]bool)}
+		paradropWatchMemory.set(env.Memory, w)
+	}
+	w.Fired = env.State.Tick
+}
+
+// nearestOwnBuilding returns the distance from (x, y) to our closest
+// building, or +Inf with none.
+func (e RuleEnv) nearestOwnBuilding(x, y int) float64 {
+	best := math.Inf(1)
+	for _, b := range e.State.Buildings {
+		best = min(best, math.Hypot(float64(b.X-x), float64(b.Y-y)))
+	}
+	return best
+}
+
+// updateParadrops adds infantry that appeared far from our base during a
+// drop to the paradrop squad, forming it if needed.
+func updateParadrops(env RuleEnv) {
+	w, _ := paradropWatchMemory.get(env.Memory)
+	if w == nil {
+		w = &paradropWatch{Known: make(map[int]bool)}
+		paradropWatchMemory.set(env.Memory, w)
+	}
+	dropping := w.Fired > 0 && env.State.Tick-w.Fired <= paradropWindow && len(env.State.Buildings) > 0
+
+	var dropped []int
+	for _, u := range env.State.Units {
+		if w.Known[u.ID] {
+			continue
+		}
+		w.Known[u.ID] = true
+		if dropping && isInfantry(u) && env.nearestOwnBuilding(u.X, u.Y) > paradropMinDistance {
+			dropped = append(dropped, u.ID)
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	squads := getSquads(env.Memory)
+	sq, ok := squads[paradropSquad]
+	if !ok {
+		sq = &Squad{Name: paradropSquad, Domain: "ground", Role: "paradrop"}
+		squads[paradropSquad] = sq
+	}
+	sq.UnitIDs = append(sq.UnitIDs, dropped...)
+	sq.TargetSize = len(sq.UnitIDs)
+	env.setSquads(squads)
+	slog.Info("paratroopers landed, squad formed", "squad", paradropSquad, "added", len(dropped), "size", len(sq.UnitIDs))
+}
+
+// paradropTarget returns the enemy structure nearest the paradrop squad:
+// a visible one, or failing that the nearest remembered standing one.
+func (e RuleEnv) paradropTarget() *squadTarget {
+	x, y, ok := e.squadPosition(paradropSquad)
+	if !ok {
+		return nil
+	}
+	return e.nearestTarget(x, y, IsKnownBuildingType, func(string) bool { return true })
+}
+
+// HasParadropTarget reports whether landed paratroopers have an enemy
+// structure to go for.
+func (e RuleEnv) HasParadropTarget() bool { return e.paradropTarget() != nil }
+
+// ActionParadropAssault sends the paradrop squad's idle members at the
+// nearest enemy structure.
+func ActionParadropAssault(env RuleEnv, conn *ipc.Connection) error {
+	t := env.paradropTarget()
+	ids := squadIdleActorIDs(env, paradropSquad)
+	if t == nil || len(ids) == 0 {
+		return nil
+	}
+	slog.Debug("paratroopers assaulting", "count", len(ids), "target", t.ID, "x", t.X, "y", t.Y)
+	return sendAtTarget(conn, ids, t)
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestParadropSquadFormed(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Units:     []model.Unit{{ID: 2, Type: "e1", X: 60, Y: 60, Idle: true}}, // already out in the field
+		},
+		Memory: make(map[string]any),
+	}
+	updateParadrops(env)
+	if env.SquadExists(paradropSquad) {
+		t.Fatal("paradrop squad formed without a drop")
+	}
+
+	env.State.Tick = 200
+	recordParadrop(env)
+	env.State.Tick = 400
+	env.State.Units = append(env.State.Units,
+		model.Unit{ID: 3, Type: "e1", X: 80, Y: 80, Idle: true},
+		model.Unit{ID: 4, Type: "e1", X: 81, Y: 80, Idle: true},
+		model.Unit{ID: 5, Type: "e1", X: 12, Y: 12, Idle: true}, // trained at home meanwhile
+	)
+	updateParadrops(env)
+	if got := env.SquadSize(paradropSquad); got != 2 {
+		t.Fatalf("paradrop squad size = %d, want the 2 dropped infantry", got)
+	}
+
+	enemyStructuresMemory.set(env.Memory, map[int]*EnemyStructure{
+		50: {ID: 50, Type: "powr", X: 90, Y: 90},
+		51: {ID: 51, Type: "fact", X: 120, Y: 120},
+	})
+	if tgt := env.paradropTarget(); tgt == nil || tgt.X != 90 {
+		t.Fatalf("paradropTarget = %+v, want the power plant at 90,90", tgt)
+	}
+	if err := ActionParadropAssault(env, conn); err != nil {
+		t.Fatal(err)
+	}
+
+	// Infantry appearing long after the drop is not a paratrooper.
+	env.State.Tick = 200 + paradropWindow + 1
+	env.State.Units = append(env.State.Units, model.Unit{ID: 6, Type: "e1", X: 70, Y: 70, Idle: true})
+	updateParadrops(env)
+	if got := env.SquadSize(paradropSquad); got != 2 {
+		t.Errorf("paradrop squad size = %d after the window, want 2", got)
+	}
+}
@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/expr-lang/expr/vm"
+)
+
+// Concurrent condition evaluation. Late-game rule sets run hundreds of
+// conditions a tick over states with hundreds of actors, and conditions only
+// read RuleEnv, so with workers set the engine evaluates the conditions of
+// the non-exclusive rules across a pool before running any action. Actions
+// still run one at a time in priority order. Exclusive rules, whose firing
+// gates the rest of their category, are evaluated in the loop as before, as
+// are the rules of categories a policy caps or a tick budget may shed:
+// their conditions would mostly be thrown away, so the loop evaluates them
+// only until the category is spent or shed.
+//
+// A precomputed condition sees memory as it stood before the tick's
+// actions, so a non-exclusive rule no longer observes what a higher-priority
+// action did earlier in the same tick; it sees it on the next tick instead.
+
+// conditionResult is one evaluated condition. A recovered panic is held
+// rather than recorded, since the panic accounting is not safe to touch
+// from the pool.
+type conditionResult struct {
+	done  bool
+	match bool
+	err   error
+	panic any
+}
+
+// evalCondition runs a rule's compiled condition, recovering a panic in any
+// RuleEnv helper. It touches nothing but env, so it is safe to run
+// concurrently.
+func evalCondition(r *Rule, env RuleEnv) (res conditionResult) {
+	defer func() {
+		if p := recover(); p != nil {
+			res = conditionResult{done: true, panic: p}
+		}
+	}()
+	out, err := vm.Run(r.program, env)
+	if err != nil {
+		return conditionResult{done: true, err: err}
+	}
+	match, _ := out.(bool)
+	return conditionResult{done: true, match: match}
+}
+
+// settleCondition turns a condition result into the loop's match or error,
+// recording a panic. Caller must hold memMu.
+func (e *Engine) settleCondition(r *Rule, res conditionResult) (bool, error) {
+	if res.panic != nil {
+		return false, e.recordPanic(r, "condition", res.panic)
+	}
+	return res.match, res.err
+}
+
+// precomputeConditions evaluates the conditions of the rules eligible for
+// it across workers goroutines. Results are indexed like rules; ineligible
+// rules are left not done.
+func precomputeConditions(rules []*Rule, env RuleEnv, workers int, eligible func(*Rule) bool) []conditionResult {
+	results := make([]conditionResult, len(rules))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(rules)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(rules) {
+					return
+				}
+				if r := rules[i]; eligible(r) {
+					env := env
+					env.rule = r
+					results[i] = evalCondition(r, env)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// SetConditionWorkers sets how many goroutines evaluate non-exclusive rule
+// conditions each tick; 1 or less evaluates every condition in the loop.
+func (e *Engine) SetConditionWorkers(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.workers = max(n, 1)
+	slog.Info("condition workers set", "workers", e.workers)
+}
+
+// ConditionWorkers returns the condition evaluation pool size.
+func (e *Engine) ConditionWorkers() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return max(e.workers, 1)
+}
@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nstehr/vimy/vimy-core/fixtures"
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestConcurrentConditionsKeepActionOrder(t *testing.T) {
+	var ran []string
+	record := func(name string) ActionFunc {
+		return func(env RuleEnv, conn *ipc.Connection) error {
+			ran = append(ran, name)
+			return nil
+		}
+	}
+	var rules []*Rule
+	for i := range 20 {
+		name := fmt.Sprintf("r%02d", i)
+		rules = append(rules, &Rule{Name: name, Priority: 100 - i, Category: fmt.Sprintf("c%d", i%3), ConditionSrc: fmt.Sprintf("State.Tick %% %d != 0", i%4+2), Action: record(name)})
+	}
+	rules = append(rules,
+		&Rule{Name: "gate", Priority: 90, Category: "c0", Exclusive: true, ConditionSrc: "State.Tick > 2", Action: record("gate")},
+		&Rule{Name: "boom", Priority: 50, Category: "c1", ConditionSrc: "State.Enemies[0].HP > 0", Action: record("boom")},
+	)
+
+	run := func(workers int) ([][]string, []RuleEvalError) {
+		engine, err := NewEngine(rules)
+		if err != nil {
+			t.Fatalf("NewEngine failed: %v", err)
+		}
+		engine.SetConditionWorkers(workers)
+		var ticks [][]string
+		for tick := range 6 {
+			ran = nil
+			if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", nil); err != nil {
+				t.Fatalf("Evaluate tick %d: %v", tick, err)
+			}
+			ticks = append(ticks, ran)
+		}
+		return ticks, engine.EvalErrors()
+	}
+
+	serial, _ := run(1)
+	concurrent, errs := run(4)
+	if !slices.EqualFunc(serial, concurrent, slices.Equal) {
+		t.Errorf("concurrent conditions ran\n%v\nserial ran\n%v", concurrent, serial)
+	}
+	if len(errs) != 1 || errs[0].Rule != "boom" || errs[0].Count != 6 {
+		t.Errorf("EvalErrors() = %+v, want boom failing on all 6 ticks", errs)
+	}
+}
+
+// lateGameState is a big late-game battle: a sprawling base, a large army
+// and a larger enemy force, with every queue busy.
+func lateGameState() model.GameState {
+	b := fixtures.NewStateBuilder().
+		AtTick(30000).
+		WithCash(20000).
+		WithPower(600, 450).
+		WithBase().
+		WithArmy(40, "3tnk").
+		WithArmyAt(30, "e1", 40, 20).
+		WithArmyAt(10, "v2rl", 40, 30).
+		WithArmyAt(8, "mig", 10, 40).
+		WithArmyAt(4, "harv", 50, 50).
+		WithEnemyBase("enemy", 100, 100).
+		WithEnemyArmy("enemy", 60, "2tnk", 70, 70).
+		WithEnemyArmy("enemy", 40, "e3", 80, 60).
+		WithQueue("Building", "powr", "apwr", "proc", "barr", "weap", "dome", "afld", "stek", "mslo", "iron").
+		WithQueue("Defense", "tsla", "ftur", "sam", "agun").
+		WithQueue("Infantry", "e1", "e2", "e3", "e4", "shok").
+		WithQueue("Vehicle", "3tnk", "4tnk", "v2rl", "ttnk", "harv").
+		WithQueue("Aircraft", "mig", "yak", "hind").
+		WithSupportPower("NukePowerInfoOrder", false)
+	for i, t := range []string{"tsla", "tsla", "ftur", "sam", "sam", "dome", "afld", "afld", "stek", "apwr", "apwr", "silo", "fix"} {
+		b.WithBuilding(t, 10+i*3, 10)
+	}
+	return b.Build()
+}
+
+func TestConcurrentConditionsOnLateGame(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	d := DefaultDoctrine()
+	d.AirWeight, d.TechPriority, d.SuperweaponPriority = 0.5, 0.8, 0.8
+	engine, err := NewEngine(CompileDoctrine(d))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	engine.SetDoctrine(d)
+	engine.SetConditionWorkers(8)
+	gs := lateGameState()
+	for range 5 {
+		if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+			t.Fatalf("Evaluate tick %d: %v", gs.Tick, err)
+		}
+		gs.Tick++
+	}
+	for _, rec := range engine.EvalErrors() {
+		t.Errorf("rule %s condition failed: %s", rec.Rule, rec.LastError)
+	}
+	if q := engine.Quarantined(); len(q) > 0 {
+		t.Errorf("rules quarantined: %v", q)
+	}
+}
+
+func BenchmarkEvaluateLateGame(b *testing.B) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	d := DefaultDoctrine()
+	d.AirWeight, d.TechPriority, d.SuperweaponPriority = 0.5, 0.8, 0.8
+	rules := CompileDoctrine(d)
+	gs := lateGameState()
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			conn, cleanup := testConn(b)
+			defer cleanup()
+			engine, err := NewEngine(rules)
+			if err != nil {
+				b.Fatalf("NewEngine failed: %v", err)
+			}
+			engine.SetDoctrine(d)
+			engine.SetConditionWorkers(workers)
+			b.ResetTimer()
+			for i := range b.N {
+				gs.Tick = 30000 + i
+				if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentConditionsLeaveCappedAndSheddableToTheLoop(t *testing.T) {
+	// A condition evaluated in the loop sees what a higher-priority action
+	// did earlier in the tick; a precomputed one doesn't until the next.
+	flag := func(env RuleEnv, conn *ipc.Connection) error {
+		env.Memory["flag"] = true
+		return nil
+	}
+	noop := func(env RuleEnv, conn *ipc.Connection) error { return nil }
+	build := func(category string) *Engine {
+		engine, err := NewEngine([]*Rule{
+			{Name: "flag", Priority: 10, Category: "setup", ConditionSrc: "true", Action: flag},
+			{Name: "follow", Priority: 5, Category: category, ConditionSrc: `Memory["flag"] == true`, Action: noop},
+		})
+		if err != nil {
+			t.Fatalf("NewEngine failed: %v", err)
+		}
+		engine.SetConditionWorkers(4)
+		return engine
+	}
+	followed := func(engine *Engine) bool {
+		if err := engine.Evaluate(model.GameState{Tick: 1}, "soviet", nil); err != nil {
+			t.Fatal(err)
+		}
+		_, ok := getRuleLastRan(engine.Memory)["follow"]
+		return ok
+	}
+
+	if followed(build("economy")) {
+		t.Fatal("precomputed condition saw the tick's own action; the test can't tell the paths apart")
+	}
+	capped := build("economy")
+	if err := capped.SetCategoryPolicies(CategoryPolicies{"economy": {MaxRules: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if !followed(capped) {
+		t.Error("capped category's condition was precomputed")
+	}
+	budgeted := build("micro")
+	budgeted.SetTickBudget(time.Hour)
+	if !followed(budgeted) {
+		t.Error("sheddable category's condition was precomputed under a tick budget")
+	}
+}
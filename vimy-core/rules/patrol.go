@@ -0,0 +1,141 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Perimeter patrols. A unit or two loops the ring just outside the base —
+// the same annulus defenseHint places defenses in — so an attack coming
+// around the flank is spotted before it reaches the buildings. Patrollers
+// are owned (see unitOwners) and aren't pulled into squads or escorts.
+const (
+	patrolWaypoints  = 8    // points around the ring
+	patrolRingFactor = 1.25 // ring radius as a multiple of the base radius
+)
+
+// patrol is one patroller's place on the route.
+type patrol struct {
+	Waypoint int // index of the waypoint it was last sent to
+}
+
+func getPatrols(memory map[string]any) map[int]*patrol {
+	if v, ok := patrolsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*patrol)
+}
+
+// HasPatrols reports whether any units are assigned to patrol.
+func (e RuleEnv) HasPatrols() bool { return len(getPatrols(e.Memory)) > 0 }
+
+// PatrolsNeeded returns how many more patrollers there is room for under
+// maxPatrols.
+func (e RuleEnv) PatrolsNeeded(maxPatrols int) int {
+	if len(e.State.Buildings) == 0 {
+		return 0
+	}
+	return max(0, maxPatrols-len(getPatrols(e.Memory)))
+}
+
+// updatePatrols drops patrollers that have died.
+func updatePatrols(env RuleEnv) {
+	patrols := getPatrols(env.Memory)
+	if len(patrols) == 0 {
+		return
+	}
+	units := env.units()
+	for id := range patrols {
+		if !units.Has(id) {
+			delete(patrols, id)
+		}
+	}
+	if len(patrols) == 0 {
+		patrolsMemory.clear(env.Memory)
+		return
+	}
+	patrolsMemory.set(env.Memory, patrols)
+}
+
+// patrolRoute returns the patrol waypoints clockwise around the base
+// perimeter, skipping ones off the map or off land. The ring follows the
+// base as it grows.
+func (e RuleEnv) patrolRoute() [][2]int {
+	if len(e.State.Buildings) == 0 {
+		return nil
+	}
+	cx, cy, radius := baseRing(e.State.Buildings)
+	r := radius * patrolRingFactor
+	var out [][2]int
+	for i := range patrolWaypoints {
+		angle := float64(i) * 2 * math.Pi / patrolWaypoints
+		x := cx + int(r*math.Cos(angle))
+		y := cy + int(r*math.Sin(angle))
+		if x < 0 || y < 0 || (e.State.MapWidth > 0 && x >= e.State.MapWidth) || (e.State.MapHeight > 0 && y >= e.State.MapHeight) {
+			continue
+		}
+		if e.IsLandAt(x, y) {
+			out = append(out, [2]int{x, y})
+		}
+	}
+	return out
+}
+
+// nearestWaypoint returns the index of the route point closest to (x, y).
+func nearestWaypoint(route [][2]int, x, y int) int {
+	best, bestDist := 0, math.MaxFloat64
+	for i, wp := range route {
+		if d := math.Hypot(float64(wp[0]-x), float64(wp[1]-y)); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// AssignPatrols keeps up to maxPatrols units looping the base perimeter.
+// New patrollers come from the escort pool nearest the base and join the
+// route at its closest point; a patroller that has gone idle has reached
+// its waypoint and is attack-moved on to the next.
+func AssignPatrols(maxPatrols int) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		route := env.patrolRoute()
+		if len(route) == 0 {
+			return nil
+		}
+		patrols := getPatrols(env.Memory)
+		bx, by := env.BuildingCentroid()
+		for _, u := range env.escortPool(bx, by) {
+			if len(patrols) >= maxPatrols {
+				break
+			}
+			slog.Info("assigning patroller", "id", u.ID, "type", u.Type)
+			// Start one short of the nearest point so the first leg goes there.
+			patrols[u.ID] = &patrol{Waypoint: nearestWaypoint(route, u.X, u.Y) - 1}
+		}
+
+		for _, u := range env.units().Idle() {
+			p, ok := patrols[u.ID]
+			if !ok {
+				continue
+			}
+			p.Waypoint = (p.Waypoint + 1 + len(route)) % len(route)
+			wp := route[p.Waypoint]
+			if err := conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+				ActorIDs: []uint32{uint32(u.ID)},
+				X:        wp[0],
+				Y:        wp[1],
+			}); err != nil {
+				return err
+			}
+		}
+
+		if len(patrols) == 0 {
+			patrolsMemory.clear(env.Memory)
+			return nil
+		}
+		patrolsMemory.set(env.Memory, patrols)
+		return nil
+	}
+}
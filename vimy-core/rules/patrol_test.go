@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestAssignPatrols(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      100,
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{
+				{ID: 50, Type: "fact", X: 40, Y: 50},
+				{ID: 51, Type: "powr", X: 60, Y: 50},
+			},
+			Units: []model.Unit{
+				{ID: 1, Type: "1tnk", X: 52, Y: 50, Idle: true},
+				{ID: 2, Type: "2tnk", X: 80, Y: 80, Idle: true},
+				{ID: 3, Type: "arty", X: 50, Y: 52, Idle: true}, // ranged: not a patroller
+			},
+		},
+		Memory: mem,
+	}
+
+	// Base radius 10, ring radius 12.5 around (50, 50).
+	route := env.patrolRoute()
+	if len(route) != patrolWaypoints || route[0] != [2]int{62, 50} {
+		t.Fatalf("patrolRoute = %v, want %d points starting at (62,50)", route, patrolWaypoints)
+	}
+
+	if got := env.PatrolsNeeded(1); got != 1 {
+		t.Fatalf("PatrolsNeeded = %d, want 1", got)
+	}
+	if err := AssignPatrols(1)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	patrols := getPatrols(mem)
+	if len(patrols) != 1 || patrols[1] == nil {
+		t.Fatalf("patrols = %v, want the tank nearest the base", patrols)
+	}
+	if got := env.UnitOwner(1); got != ownerPatrol {
+		t.Errorf("UnitOwner(1) = %q, want %q", got, ownerPatrol)
+	}
+	for _, u := range env.IdleGroundUnits() {
+		if u.ID == 1 {
+			t.Error("patroller still offered as an idle ground unit")
+		}
+	}
+	first := patrols[1].Waypoint
+
+	// Idle again at its waypoint: on to the next one.
+	if err := AssignPatrols(1)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := getPatrols(mem)[1].Waypoint, (first+1)%len(route); got != want {
+		t.Errorf("waypoint after arriving = %d, want %d", got, want)
+	}
+
+	// Still moving: left alone.
+	env.State.Units[0].Idle = false
+	if err := AssignPatrols(1)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := getPatrols(mem)[1].Waypoint, (first+1)%len(route); got != want {
+		t.Errorf("waypoint while en route = %d, want %d", got, want)
+	}
+
+	// The patroller dies and is dropped.
+	env.State.Units = env.State.Units[1:]
+	updatePatrols(env)
+	if env.HasPatrols() {
+		t.Error("dead patroller still assigned")
+	}
+}
@@ -0,0 +1,281 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// placementZone is a region of the base layout. Zones are laid out along the
+// threat axis — from the base centroid toward the nearest known enemy base,
+// or toward the map centre before we've found one.
+type placementZone int
+
+const (
+	zoneCore       placementZone = iota // economy core: at the centroid
+	zoneProduction                      // production ring, facing the enemy
+	zonePowerFarm                       // behind the base, away from the enemy
+	zoneOre                             // refineries hug the ore being mined
+)
+
+// Zone offsets along the threat axis, as multiples of the base radius.
+const (
+	productionZoneOffset = 0.6
+	powerFarmZoneOffset  = -0.8
+	minBaseRadius        = 3.0
+)
+
+// Doctrine.Forwardness moves ground production along the threat axis from
+// productionZoneOffset: at 1 it sits this far further out toward the enemy,
+// shortening reinforcement paths; at -1 it sits this far further back.
+const (
+	productionForwardReach = 0.9
+	productionBackReach    = 0.8
+)
+
+// forwardProduction are the production buildings Forwardness moves. Air
+// and naval production gain nothing from a shorter ground path.
+var forwardProduction = []string{WarFactory, AlliedBarracks, SovietBarracks, Kennel}
+
+// zoneForType assigns building types to layout zones. Unlisted types go in
+// the core. Defenses are placed by defenseHint; naval yards go on the
+// nearest water in reach (see nearestBuildableWater).
+var zoneForType = map[string]placementZone{
+	Refinery: zoneOre,
+
+	PowerPlant: zonePowerFarm, AdvancedPower: zonePowerFarm, OreSilo: zonePowerFarm,
+	AlliedTechCenter: zonePowerFarm, SovietTechCenter: zonePowerFarm,
+
+	WarFactory: zoneProduction, AlliedBarracks: zoneProduction, SovietBarracks: zoneProduction,
+	Airfield: zoneProduction, Helipad: zoneProduction, Kennel: zoneProduction,
+}
+
+// placementHint picks a HintX/HintY for a building based on its layout zone.
+// ok is false when the mod should choose on its own (no base yet, a naval
+// building with no known water in reach, or the zone point isn't buildable
+// land).
+func placementHint(env RuleEnv, item string) (x, y int, ok bool) {
+	if len(env.State.Buildings) == 0 {
+		return 0, 0, false
+	}
+	if matchesType(item, NavalYard) || matchesType(item, SubPen) {
+		return env.nearestBuildableWater()
+	}
+
+	zone := zoneCore
+	for t, z := range zoneForType {
+		if matchesType(item, t) {
+			zone = z
+			break
+		}
+	}
+
+	cx, cy := env.BuildingCentroid()
+	switch zone {
+	case zoneOre:
+		if ox, oy, found := env.OreFieldEstimate(); found {
+			x, y = ox, oy
+		} else {
+			x, y = cx, cy
+		}
+	case zoneProduction, zonePowerFarm:
+		offset := productionZoneOffset
+		if zone == zonePowerFarm {
+			offset = powerFarmZoneOffset
+		} else if slices.ContainsFunc(forwardProduction, func(t string) bool { return matchesType(item, t) }) {
+			offset += productionForward(env.Doctrine.Forwardness)
+		}
+		tx, ty := env.threatAxis(cx, cy)
+		r := baseRadius(env.State.Buildings, cx, cy)
+		x = cx + int(math.Round(tx*r*offset))
+		y = cy + int(math.Round(ty*r*offset))
+		x, y = env.coolerSpot(cx, cy, x, y)
+	default:
+		x, y = cx, cy
+	}
+
+	if env.State.MapWidth > 0 && env.State.MapHeight > 0 {
+		x = max(0, min(x, env.State.MapWidth-1))
+		y = max(0, min(y, env.State.MapHeight-1))
+	}
+	if env.Terrain != nil {
+		if t := env.Terrain.AtMapPos(x, y); t != model.Land {
+			x, y = cx, cy
+		}
+	}
+	x, y = env.nukeSpaced(item, x, y)
+	x, y = env.clearOfExits(item, x, y)
+	return x, y, true
+}
+
+// productionForward is the zone offset change for a doctrine forwardness.
+func productionForward(f float64) float64 {
+	if f < 0 {
+		return f * productionBackReach
+	}
+	return f * productionForwardReach
+}
+
+// exitClearSearch is how far (in cells) a hint may be nudged to keep
+// production exits clear.
+const exitClearSearch = 6
+
+// clearOfExits returns the nearest spot to (x, y) where item neither sits on
+// an existing building's exit apron nor has its own apron blocked, and
+// doesn't cover a chronic stuck spot. Falls back to (x, y) if nothing within
+// exitClearSearch works — the mod enforces the exit rule itself when it
+// searches from the hint.
+func (e RuleEnv) clearOfExits(item string, x, y int) (int, int) {
+	for r := 0; r <= exitClearSearch; r++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if max(abs(dx), abs(dy)) != r {
+					continue
+				}
+				if !e.blocksExit(item, x+dx, y+dy) && !e.onStuckSpot(item, x+dx, y+dy) {
+					return x + dx, y + dy
+				}
+			}
+		}
+	}
+	return x, y
+}
+
+// blocksExit reports whether placing item with its top-left at (x, y) would
+// cover an existing building's exit apron, or put its own apron under an
+// existing building.
+func (e RuleEnv) blocksExit(item string, x, y int) bool {
+	w, h := footprintOf(item)
+	apron := exitApronOf(item)
+	for _, b := range e.State.Buildings {
+		bw, bh := footprintOf(b.Type)
+		for _, c := range exitApronOf(b.Type) {
+			ax, ay := b.X+c[0], b.Y+c[1]
+			if ax >= x && ax < x+w && ay >= y && ay < y+h {
+				return true
+			}
+		}
+		for _, c := range apron {
+			ax, ay := x+c[0], y+c[1]
+			if ax >= b.X && ax < b.X+bw && ay >= b.Y && ay < b.Y+bh {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// threatAxis returns the unit vector from (cx, cy) toward the nearest known
+// enemy base, falling back to the map centre. Zero if neither is usable.
+func (e RuleEnv) threatAxis(cx, cy int) (float64, float64) {
+	tx, ty := e.State.MapWidth/2, e.State.MapHeight/2
+	if base := e.NearestEnemyBase(); base != nil {
+		tx, ty = base.X, base.Y
+	}
+	dx, dy := float64(tx-cx), float64(ty-cy)
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return 0, 0
+	}
+	return dx / d, dy / d
+}
+
+// baseRadius is the distance from the centroid to the furthest building,
+// floored so a lone construction yard still gets distinct zones.
+func baseRadius(buildings []model.Building, cx, cy int) float64 {
+	r := minBaseRadius
+	for _, b := range buildings {
+		r = math.Max(r, math.Hypot(float64(b.X-cx), float64(b.Y-cy)))
+	}
+	return r
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Factory exit watchdog thresholds.
+const (
+	factoryExitRadius    = 4.0 // cells from a factory's apron that count as "at the exit"
+	factoryStuckTicks    = 375 // 15s without moving despite having orders
+	factoryClearDistance = 8   // how far out to push stuck units
+)
+
+// exitWatch follows a unit sitting near a factory exit.
+type exitWatch struct {
+	X, Y    int
+	Since   int // tick the unit last moved
+	Factory int // building ID of the factory it's stuck at
+}
+
+func getExitWatches(memory map[string]any) map[int]*exitWatch {
+	if v, ok := factoryExitMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*exitWatch)
+}
+
+// updateFactoryExits tracks ground units loitering at production exits.
+// A unit that moves, goes idle, or leaves the exit area stops being watched.
+func updateFactoryExits(env RuleEnv) {
+	watches := getExitWatches(env.Memory)
+	seen := make(map[int]bool)
+	for _, u := range env.units().InDomain(domainGround) {
+		if u.Idle {
+			continue
+		}
+		factory := env.factoryExitNear(u.X, u.Y)
+		if factory == 0 {
+			continue
+		}
+		seen[u.ID] = true
+		w := watches[u.ID]
+		if w == nil || w.X != u.X || w.Y != u.Y {
+			watches[u.ID] = &exitWatch{X: u.X, Y: u.Y, Since: env.State.Tick, Factory: factory}
+		}
+	}
+	for id := range watches {
+		if !seen[id] {
+			delete(watches, id)
+		}
+	}
+	if len(watches) == 0 {
+		factoryExitMemory.clear(env.Memory)
+		return
+	}
+	factoryExitMemory.set(env.Memory, watches)
+}
+
+// factoryExitNear returns the ID of the land production building whose exit
+// apron is within factoryExitRadius of (x, y), or 0.
+func (e RuleEnv) factoryExitNear(x, y int) int {
+	for _, b := range e.State.Buildings {
+		if matchesType(b.Type, NavalYard) || matchesType(b.Type, SubPen) {
+			continue
+		}
+		for _, c := range exitApronOf(b.Type) {
+			if math.Hypot(float64(x-b.X-c[0]), float64(y-b.Y-c[1])) <= factoryExitRadius {
+				return b.ID
+			}
+		}
+	}
+	return 0
+}
+
+// UnitsStuckAtFactory returns IDs of units that have had orders but haven't
+// moved off a factory exit for factoryStuckTicks — usually boxed in by
+// buildings or a traffic jam of freshly produced units.
+func (e RuleEnv) UnitsStuckAtFactory() []int {
+	var out []int
+	for id, w := range getExitWatches(e.Memory) {
+		if e.State.Tick-w.Since >= factoryStuckTicks {
+			out = append(out, id)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
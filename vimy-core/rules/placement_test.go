@@ -0,0 +1,154 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestPlacementHintZones(t *testing.T) {
+	// Base at (20,50) with radius 10; no enemy intel, so the threat axis
+	// points at the map centre (+x).
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{
+				{ID: 1, Type: "fact", X: 20, Y: 50},
+				{ID: 2, Type: "powr", X: 10, Y: 50},
+				{ID: 3, Type: "tent", X: 30, Y: 50},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+
+	tests := []struct {
+		item  string
+		wantX int
+	}{
+		{"weap", 26}, // production ring, toward the enemy
+		{"powr", 12}, // power farm, behind the base
+		{"dome", 20}, // economy core
+	}
+	for _, tt := range tests {
+		x, y, ok := placementHint(env, tt.item)
+		if !ok || x != tt.wantX || y != 50 {
+			t.Errorf("placementHint(%q) = (%d, %d, %v), want (%d, 50, true)", tt.item, x, y, ok, tt.wantX)
+		}
+	}
+
+	if _, _, ok := placementHint(env, "syrd"); ok {
+		t.Error("naval yard should get no hint")
+	}
+
+	// Refineries follow the ore harvesters are mining.
+	env.Memory["harvesterTracks"] = map[int]*harvesterTrack{7: {OreX: 40, OreY: 70, OreDist: 25}}
+	if x, y, ok := placementHint(env, "proc"); !ok || x != 40 || y != 70 {
+		t.Errorf("refinery hint = (%d, %d, %v), want (40, 70, true)", x, y, ok)
+	}
+}
+
+func TestPlacementHintFacesEnemyBase(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 50, Y: 50}},
+		},
+		Memory: map[string]any{"enemyBases": map[string]EnemyBaseIntel{"enemy": {X: 50, Y: 90}}},
+	}
+	// Radius floors at minBaseRadius (3): war factory 2 cells toward the
+	// enemy (south), power plant 2 cells away (north).
+	if x, y, _ := placementHint(env, "weap"); x != 50 || y != 52 {
+		t.Errorf("war factory hint = (%d, %d), want (50, 52)", x, y)
+	}
+	if x, y, _ := placementHint(env, "apwr"); x != 50 || y != 48 {
+		t.Errorf("advanced power hint = (%d, %d), want (50, 48)", x, y)
+	}
+}
+
+func TestPlacementHintForwardness(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 50, Y: 50}},
+		},
+		Memory: map[string]any{"enemyBases": map[string]EnemyBaseIntel{"enemy": {X: 50, Y: 90}}},
+	}
+	hintY := func(item string, forwardness float64) int {
+		env.Doctrine.Forwardness = forwardness
+		_, y, _ := placementHint(env, item)
+		return y
+	}
+	// The enemy is south: forward is larger Y.
+	if fwd, std, back := hintY("barr", 1), hintY("barr", 0), hintY("barr", -1); !(fwd > std && std > back) {
+		t.Errorf("barracks hint Y forward/standard/back = %d/%d/%d, want decreasing", fwd, std, back)
+	}
+	if back := hintY("barr", -1); back >= 50 {
+		t.Errorf("defensive barracks hint Y = %d, want behind the centroid", back)
+	}
+	if fwd, std := hintY("afld", 1), hintY("afld", 0); fwd != std {
+		t.Errorf("airfield hint Y moved with forwardness: %d vs %d", fwd, std)
+	}
+}
+
+func TestPlacementHintKeepsFactoryExitClear(t *testing.T) {
+	// War factory at (20,20): its apron is (20..22, 23) and (21, 24).
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "weap", X: 20, Y: 20}},
+		},
+		Memory: make(map[string]any),
+	}
+	if !env.blocksExit("powr", 21, 23) {
+		t.Error("power plant on the war factory apron should block its exit")
+	}
+	if env.blocksExit("powr", 24, 20) {
+		t.Error("power plant beside the war factory should not block its exit")
+	}
+	// A new war factory directly above ours would have its apron under it.
+	if !env.blocksExit("weap", 20, 17) {
+		t.Error("war factory with its apron under a building should be blocked")
+	}
+
+	x, y := env.clearOfExits("powr", 21, 23)
+	if env.blocksExit("powr", x, y) {
+		t.Errorf("clearOfExits returned blocked spot (%d, %d)", x, y)
+	}
+}
+
+func TestUnitsStuckAtFactory(t *testing.T) {
+	mem := make(map[string]any)
+	env := func(tick, x int) RuleEnv {
+		return RuleEnv{
+			State: model.GameState{
+				Tick:      tick,
+				Buildings: []model.Building{{ID: 1, Type: "weap", X: 20, Y: 20}},
+				Units: []model.Unit{
+					{ID: 5, Type: "2tnk", X: x, Y: 23},
+					{ID: 6, Type: "1tnk", X: 22, Y: 24, Idle: true}, // parked, not stuck
+				},
+			},
+			Memory: mem,
+		}
+	}
+
+	updateFactoryExits(env(0, 21))
+	updateFactoryExits(env(factoryStuckTicks-1, 21))
+	if got := env(factoryStuckTicks-1, 21).UnitsStuckAtFactory(); len(got) != 0 {
+		t.Fatalf("stuck too early: %v", got)
+	}
+	updateFactoryExits(env(factoryStuckTicks, 21))
+	if got := env(factoryStuckTicks, 21).UnitsStuckAtFactory(); len(got) != 1 || got[0] != 5 {
+		t.Fatalf("UnitsStuckAtFactory = %v, want [5]", got)
+	}
+
+	// Moving resets the watch.
+	updateFactoryExits(env(factoryStuckTicks+1, 22))
+	if got := env(factoryStuckTicks+1, 22).UnitsStuckAtFactory(); len(got) != 0 {
+		t.Errorf("moved unit still reported stuck: %v", got)
+	}
+}
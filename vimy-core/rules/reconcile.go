@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Queue reconciliation after a doctrine swap. Items queued under the old
+// doctrine keep building after a swap, so a switch from air to ground still
+// finishes the half-built MiGs. The first evaluation after Swap cancels what
+// is queued in unit queues whose domain the new doctrine has dropped; the
+// game refunds what was spent, and the new doctrine's rules spend it.
+
+// reconcileWeights maps each unit queue to the doctrine weight that keeps
+// its items wanted. The Infantry queue also serves specialist infantry.
+var reconcileWeights = map[string]func(Doctrine) float64{
+	QueueInfantry: func(d Doctrine) float64 { return max(d.InfantryWeight, d.SpecializedInfantryWeight) },
+	QueueVehicle:  func(d Doctrine) float64 { return d.VehicleWeight },
+	QueueAircraft: func(d Doctrine) float64 { return d.AirWeight },
+	QueueShip:     func(d Doctrine) float64 { return d.NavalWeight },
+}
+
+// reconcileKeepTypes are built for the economy, capture, scouting or mine
+// laying whatever the domain weights say, so they are never cancelled.
+var reconcileKeepTypes = []string{Harvester, MCV, Engineer, APC, Minelayer, Ranger}
+
+// obsoleteQueueItems returns cancel commands for everything queued in unit
+// queues whose doctrine weight is below DoctrineEnabled — the level at
+// which the compiler drops that domain's production rules.
+func obsoleteQueueItems(env RuleEnv) []ipc.CancelProductionCommand {
+	counts := make(map[[2]string]int)
+	for _, pq := range env.State.ProductionQueues {
+		var q string
+		for name := range reconcileWeights {
+			if strings.EqualFold(pq.Type, name) {
+				q = name
+			}
+		}
+		if q == "" || reconcileWeights[q](env.Doctrine) >= DoctrineEnabled {
+			continue
+		}
+		items := pq.Items
+		if len(items) == 0 && pq.CurrentItem != "" {
+			items = []string{pq.CurrentItem}
+		}
+		for _, item := range items {
+			if slices.ContainsFunc(reconcileKeepTypes, func(t string) bool { return matchesType(item, t) }) {
+				continue
+			}
+			counts[[2]string{q, item}]++
+		}
+	}
+	var cmds []ipc.CancelProductionCommand
+	for k, n := range counts {
+		cmds = append(cmds, ipc.CancelProductionCommand{Queue: k[0], Item: k[1], Count: n})
+	}
+	slices.SortFunc(cmds, func(a, b ipc.CancelProductionCommand) int {
+		return strings.Compare(a.Queue+"/"+a.Item, b.Queue+"/"+b.Item)
+	})
+	return cmds
+}
+
+// reconcileQueues runs once after each Swap (see queueReconcileMemory) and
+// cancels the queued items the new doctrine no longer wants.
+func reconcileQueues(env RuleEnv, conn *ipc.Connection) {
+	if pending, _ := queueReconcileMemory.get(env.Memory); !pending {
+		return
+	}
+	queueReconcileMemory.clear(env.Memory)
+	for _, cmd := range obsoleteQueueItems(env) {
+		slog.Info("cancelling production obsoleted by doctrine swap", "queue", cmd.Queue, "item", cmd.Item, "count", cmd.Count)
+		if conn == nil {
+			continue
+		}
+		if err := conn.Send(ipc.TypeCancelProduction, cmd); err != nil {
+			slog.Error("cancel obsolete production failed", "queue", cmd.Queue, "item", cmd.Item, "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestObsoleteQueueItems(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			ProductionQueues: []model.ProductionQueue{
+				{Type: "Aircraft", CurrentItem: "mig", Items: []string{"mig", "mig", "yak"}},
+				{Type: "Vehicle", CurrentItem: "harv", Items: []string{"harv", "3tnk"}},
+				{Type: "Infantry", CurrentItem: "e1", Items: []string{"e1"}},
+				{Type: "Ship", CurrentItem: "ss"},
+			},
+		},
+		Doctrine: Doctrine{InfantryWeight: 0.5, VehicleWeight: 0.05, AirWeight: 0, NavalWeight: 0.02},
+	}
+	got := obsoleteQueueItems(env)
+	want := []ipc.CancelProductionCommand{
+		{Queue: QueueAircraft, Item: "mig", Count: 2},
+		{Queue: QueueAircraft, Item: "yak", Count: 1},
+		{Queue: QueueShip, Item: "ss", Count: 1},
+		{Queue: QueueVehicle, Item: "3tnk", Count: 1}, // the harvester is kept
+	}
+	if len(got) != len(want) {
+		t.Fatalf("obsoleteQueueItems = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cmd %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSwapSchedulesOneReconciliation(t *testing.T) {
+	engine, err := NewEngine(nil)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if err := engine.Swap(nil); err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if pending, _ := queueReconcileMemory.get(engine.Memory); !pending {
+		t.Fatal("Swap did not schedule queue reconciliation")
+	}
+	if err := engine.Evaluate(model.GameState{Tick: 1}, "soviet", nil); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := queueReconcileMemory.get(engine.Memory); pending {
+		t.Error("reconciliation still pending after Evaluate")
+	}
+}
@@ -6,6 +6,9 @@ package rules
 var ActionRegistry = map[string]ActionFunc{
 	"produce_mcv":          ActionProduceMCV,
 	"deploy_mcv":           ActionDeployMCV,
+	"relocate_base":        ActionRelocateBase,
+	"clear_factory_exits":  ActionClearFactoryExits,
+	"recover_stuck_units":  ActionRecoverStuckUnits,
 	"produce_power_plant":  ActionProducePowerPlant,
 	"produce_refinery":     ActionProduceRefinery,
 	"produce_barracks":     ActionProduceBarracks,
@@ -22,6 +25,8 @@ var ActionRegistry = map[string]ActionFunc{
 	"place_defense":            ActionPlaceDefense,
 	"produce_defense":          ActionProduceDefense,
 	"produce_aa_defense":       ActionProduceAADefense,
+	"produce_rush_defense":     ActionProduceRushDefense,
+	"hold_expansion":           ActionHoldExpansion,
 	"produce_tech_center":      ActionProduceTechCenter,
 	"produce_heavy_vehicle":    ActionProduceHeavyVehicle,
 	"produce_rocket_soldier":        ActionProduceRocketSoldier,
@@ -32,12 +37,15 @@ var ActionRegistry = map[string]ActionFunc{
 	"produce_advanced_ship":    ActionProduceAdvancedShip,
 	"defend_base":              ActionDefendBase,
 	"emergency_defend_base":    ActionEmergencyDefendBase,
+	"scramble_base_defense":    ActionScrambleBaseDefense,
 	"air_defend_base":          ActionAirDefendBase,
+	"defend_coast":             ActionDefendCoast,
 	"repair_buildings":     ActionRepairDamagedBuildings,
 	"scout":                ActionScoutWithIdleUnits,
 	"send_harvesters":      ActionSendIdleHarvesters,
 	"produce_engineer":     ActionProduceEngineer,
 	"capture_building":     ActionCaptureBuilding,
+	"capture_enemy_production": ActionCaptureEnemyProduction,
 	"produce_harvester":          ActionProduceHarvester,
 	"attack_move_ground":         ActionAttackMoveIdleGroundUnits,
 	"attack_known_base_ground":   ActionAttackKnownBaseGround,
@@ -60,4 +68,9 @@ var ActionRegistry = map[string]ActionFunc{
 	"lay_mines":                  ActionLayMines,
 	"produce_flame_tower":        ActionProduceFlameTower,
 	"produce_tesla_coil":         ActionProduceTeslaCoil,
+	"evade_enemy_nuke":           ActionEvadeEnemyNuke,
+	"clear_obstacles":            ActionClearObstacles,
+	"dispatch_repair_queue":      ActionDispatchRepairQueue,
+	"attach_medics":              ActionAttachMedics,
+	"medics_follow_squads":       ActionMedicsFollowSquads,
 }
@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Service depot queueing. The depot repairs one vehicle at a time; sending
+// every damaged vehicle at once just has them mill around the pad.
+const (
+	depotSlots      = 2 // vehicles dispatched to the depot at once (one on the pad, one next up)
+	depotHoldOffset = 5 // cells from the depot, toward the base core, where the rest wait
+)
+
+// repairQueue tracks vehicles waiting for and using the service depot.
+type repairQueue struct {
+	Waiting []int       // unit IDs holding at the rally point, in arrival order
+	Active  map[int]int // unit ID → tick dispatched to the depot
+}
+
+func getRepairQueue(memory map[string]any) *repairQueue {
+	if v, ok := repairQueueMemory.get(memory); ok {
+		return v
+	}
+	return &repairQueue{Active: make(map[int]int)}
+}
+
+// enqueue adds id to the waiting line unless it's already queued or active.
+func (q *repairQueue) enqueue(id int) {
+	if _, ok := q.Active[id]; ok || slices.Contains(q.Waiting, id) {
+		return
+	}
+	q.Waiting = append(q.Waiting, id)
+}
+
+// remove drops id from the queue wherever it is.
+func (q *repairQueue) remove(id int) {
+	delete(q.Active, id)
+	q.Waiting = slices.DeleteFunc(q.Waiting, func(w int) bool { return w == id })
+}
+
+func (q *repairQueue) empty() bool { return len(q.Waiting) == 0 && len(q.Active) == 0 }
+
+// HasRepairQueue reports whether any vehicles are waiting for or using the depot.
+func (e RuleEnv) HasRepairQueue() bool { return !getRepairQueue(e.Memory).empty() }
+
+// DepotOccupancy returns how many vehicles are currently dispatched to the depot.
+func (e RuleEnv) DepotOccupancy() int { return len(getRepairQueue(e.Memory).Active) }
+
+// depotHoldPoint is where queued vehicles wait: a few cells from the depot
+// toward the base core, out of the way of the pad but still covered.
+func (e RuleEnv) depotHoldPoint(depot *model.Building) (int, int) {
+	cx, cy := e.BuildingCentroid()
+	dx, dy := float64(cx-depot.X), float64(cy-depot.Y)
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return depot.X + depotHoldOffset, depot.Y
+	}
+	return depot.X + int(math.Round(dx/d*depotHoldOffset)), depot.Y + int(math.Round(dy/d*depotHoldOffset))
+}
@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestRepairQueueDispatchesInSlots(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	mem := make(map[string]any)
+	env := RuleEnv{
+		State: model.GameState{
+			Tick: 10,
+			Buildings: []model.Building{
+				{ID: 100, Type: "fact", X: 20, Y: 20},
+				{ID: 101, Type: "fix", X: 30, Y: 20},
+			},
+			Units: []model.Unit{
+				{ID: 1, Type: "2tnk", X: 60, Y: 60, HP: 20, MaxHP: 100},
+				{ID: 2, Type: "2tnk", X: 61, Y: 60, HP: 20, MaxHP: 100},
+				{ID: 3, Type: "2tnk", X: 62, Y: 60, HP: 20, MaxHP: 100},
+			},
+		},
+		Memory: mem,
+	}
+
+	if err := RetreatDamagedUnits(0.5)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	q := getRepairQueue(mem)
+	if len(q.Waiting) != 3 || len(q.Active) != 0 {
+		t.Fatalf("after retreat: waiting=%v active=%v, want 3 waiting", q.Waiting, q.Active)
+	}
+
+	if err := ActionDispatchRepairQueue(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	q = getRepairQueue(mem)
+	if env.DepotOccupancy() != depotSlots || len(q.Waiting) != 3-depotSlots {
+		t.Fatalf("after dispatch: occupancy=%d waiting=%v", env.DepotOccupancy(), q.Waiting)
+	}
+
+	// Waiting units don't hit the retreat timeout while in line.
+	env.State.Tick = 1000
+	if err := ClearHealedUnits(0.5)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := getRetreatingUnits(mem)[3]; !ok {
+		t.Error("queued unit 3 was released by the retreat timeout")
+	}
+
+	// Unit 1 finishes repair: its slot goes to unit 3.
+	env.State.Units[0].HP = 100
+	if err := ClearHealedUnits(0.5)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := ActionDispatchRepairQueue(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	q = getRepairQueue(mem)
+	if _, ok := q.Active[3]; !ok || len(q.Waiting) != 0 {
+		t.Errorf("unit 3 should be dispatched after unit 1 healed: active=%v waiting=%v", q.Active, q.Waiting)
+	}
+}
+
+func TestDepotHoldPoint(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{Buildings: []model.Building{
+			{ID: 1, Type: "fact", X: 10, Y: 20},
+			{ID: 2, Type: "fix", X: 30, Y: 20},
+		}},
+	}
+	depot := env.ServiceDepot()
+	// Centroid is (20,20): hold point is 5 cells from the depot toward it.
+	if x, y := env.depotHoldPoint(depot); x != 25 || y != 20 {
+		t.Errorf("depotHoldPoint = (%d, %d), want (25, 20)", x, y)
+	}
+}
@@ -259,6 +259,93 @@ var roles = map[string]role{
 	"kennel":            {queue: QueueBuilding, types: []string{Kennel}},
 }
 
+// buildingPower is each structure's power contribution from the RA mod
+// rules: positive provides, negative drains. Used to project power once
+// queued buildings finish. Unlisted types are treated as power-neutral.
+var buildingPower = map[string]int{
+	PowerPlant: 100, AdvancedPower: 200,
+	Refinery: -30, OreSilo: -10, WarFactory: -30,
+	AlliedBarracks: -20, SovietBarracks: -20, Kennel: -10,
+	RadarDome: -40, Airfield: -20, Helipad: -10,
+	NavalYard: -30, SubPen: -30, ServiceDepot: -30,
+	AlliedTechCenter: -200, SovietTechCenter: -100,
+	MissileSilo: -150, IronCurtain: -200, GapGenerator: -60,
+	Pillbox: -15, CamoPillbox: -15, Turret: -40, TeslaCoil: -100,
+	AAGun: -50, SAMSite: -40, FlameTower: -20,
+}
+
+// powerOf returns the power contribution of a building type, honouring
+// faction-suffixed variants.
+func powerOf(t string) int {
+	for k, v := range buildingPower {
+		if matchesType(t, k) {
+			return v
+		}
+	}
+	return 0
+}
+
+// unitRange is the primary weapon range (cells) of ranged units from the RA
+// mod rules. Only units outranging typical direct-fire threats are listed;
+// they're the ones worth kiting.
+var unitRange = map[string]float64{
+	Artillery: 10, V2Launcher: 10, RocketSoldier: 5,
+}
+
+// rangeOf returns a unit type's weapon range, or 0 if unlisted.
+func rangeOf(t string) float64 {
+	for k, v := range unitRange {
+		if matchesType(t, k) {
+			return v
+		}
+	}
+	return 0
+}
+
+// buildingFootprint is each structure's footprint in cells (width, height)
+// from the RA mod rules. Unlisted types are treated as 2x2.
+var buildingFootprint = map[string][2]int{
+	ConstructionYard: {3, 3}, WarFactory: {3, 3}, Refinery: {3, 3},
+	AdvancedPower: {3, 3}, ServiceDepot: {3, 3}, NavalYard: {3, 3}, SubPen: {3, 3},
+	Airfield: {3, 2}, MissileSilo: {2, 1},
+	OreSilo: {1, 1}, Kennel: {1, 1}, GapGenerator: {1, 1},
+	Pillbox: {1, 1}, CamoPillbox: {1, 1}, Turret: {1, 1}, TeslaCoil: {1, 1},
+	AAGun: {1, 1}, SAMSite: {2, 1}, FlameTower: {1, 1},
+}
+
+// footprintOf returns the footprint of a building type, honouring
+// faction-suffixed variants.
+func footprintOf(t string) (w, h int) {
+	for k, v := range buildingFootprint {
+		if matchesType(t, k) {
+			return v[0], v[1]
+		}
+	}
+	return 2, 2
+}
+
+// exitAprons lists cells, relative to a production building's top-left
+// location, that produced units need clear to leave: the mod's exit cells
+// plus the first cell beyond them.
+var exitAprons = map[string][][2]int{
+	WarFactory:     {{0, 3}, {1, 3}, {2, 3}, {1, 4}},
+	AlliedBarracks: {{0, 2}, {1, 2}, {0, 3}, {1, 3}},
+	SovietBarracks: {{0, 2}, {1, 2}, {0, 3}, {1, 3}},
+	NavalYard:      {{-1, 1}, {3, 1}, {1, -1}, {1, 3}},
+	SubPen:         {{-1, 1}, {3, 1}, {1, -1}, {1, 3}},
+}
+
+// exitApronOf returns the exit apron of a building type, or nil if it
+// produces nothing that has to drive out.
+func exitApronOf(t string) [][2]int {
+	for k, v := range exitAprons {
+		if matchesType(t, k) {
+			return v
+		}
+	}
+	return nil
+}
+
 // combatVehicleRoles determines production priority — first buildable role wins.
 // Order: heaviest armor first, then support vehicles.
 // APC is excluded — it's a transport with dedicated capture/assault production rules.
@@ -11,12 +11,31 @@ type ActionFunc func(env RuleEnv, conn *ipc.Connection) error
 // Rule is the atomic unit of AI behavior: a condition → action pair.
 // The engine evaluates rules by priority and uses Category + Exclusive
 // to prevent conflicting actions on the same production queue.
+// CooldownTicks throttles the action: after it runs, the engine skips it
+// for that many ticks. A cooling-down rule whose condition matches still
+// blocks its category if Exclusive, so throttling a rule doesn't let the
+// lower-priority rules it overrides slip through in between.
+// HoldTicks is hysteresis for unit orders: units the action orders are
+// off limits to every other rule for that many ticks (see holdUnits).
+// ActiveFromTick and ActiveUntilTick limit a rule to part of the game; the
+// engine doesn't evaluate it outside that window. Group names the rule
+// group it was compiled in, if any (see RuleGroup).
 type Rule struct {
-	Name         string       // human-readable identifier
-	Priority     int          // higher = evaluated first
-	Category     string       // grouping for exclusive semantics
-	Exclusive    bool         // if true, blocks lower-priority rules in same category
-	ConditionSrc string       // expr source (preserved for serialization)
-	program      *vm.Program  // compiled bytecode
-	Action       ActionFunc
+	Name            string      // human-readable identifier
+	Priority        int         // higher = evaluated first
+	Category        string      // grouping for exclusive semantics
+	Exclusive       bool        // if true, blocks lower-priority rules in same category
+	CooldownTicks   int         // minimum ticks between action runs; 0 = every tick
+	HoldTicks       int         // ticks other rules leave the action's units alone
+	ActiveFromTick  int         // first tick the rule is evaluated; 0 = from the start
+	ActiveUntilTick int         // last tick the rule is evaluated; 0 = to the end
+	Group           string      // rule group; "" for none
+	ConditionSrc    string      // expr source (preserved for serialization)
+	program         *vm.Program // compiled bytecode
+	Action          ActionFunc
+}
+
+// activeAt reports whether tick falls in the rule's active window.
+func (r *Rule) activeAt(tick int) bool {
+	return tick >= r.ActiveFromTick && (r.ActiveUntilTick == 0 || tick <= r.ActiveUntilTick)
 }
@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Rush detection thresholds. A rush is enemy combat units at our base early,
+// while our own army is too small to simply absorb them.
+const (
+	rushWindowTicks = 7500 // 5 minutes — later pushes are ordinary attacks
+	rushMinEnemies  = 3    // enemy combat units near base to call it a rush
+	rushArmyRatio   = 1.5  // our army must be under this multiple of theirs
+	rushClearTicks  = 250  // 10s with no attackers near base ends the alert
+)
+
+// rushAlert is the active rush, kept until the base has been clear of enemy
+// combat units for rushClearTicks.
+type rushAlert struct {
+	Since    int
+	LastSeen int
+	Peak     int // most attackers seen near base at once
+}
+
+func getRushAlert(memory map[string]any) *rushAlert {
+	if v, ok := rushAlertMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// RushActive reports whether a rush alert is raised. Public so the
+// strategist's event detector can raise EventRushDetected.
+func RushActive(memory map[string]any) bool { return getRushAlert(memory) != nil }
+
+// enemyCombatNearBase counts visible enemy ground combat units within the
+// base radius of any of our buildings.
+func (e RuleEnv) enemyCombatNearBase() int {
+	if len(e.State.Buildings) == 0 {
+		return 0
+	}
+	radius := e.baseThreatRadius()
+	n := 0
+	for _, en := range e.State.Enemies {
+		if !isGroundCombatEnemy(en.Type) {
+			continue
+		}
+		for _, b := range e.State.Buildings {
+			if math.Hypot(float64(en.X-b.X), float64(en.Y-b.Y)) < radius {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// isGroundCombatEnemy reports whether an enemy type is a mobile ground
+// fighter — not a building, harvester, MCV, aircraft or ship.
+func isGroundCombatEnemy(t string) bool {
+	return !IsKnownBuildingType(t) && !matchesType(t, Harvester) && !matchesType(t, MCV) &&
+		!isAircraft(model.Unit{Type: t}) && !isNaval(model.Unit{Type: t})
+}
+
+// groundArmySize counts our ground combat units anywhere on the map.
+func (e RuleEnv) groundArmySize() int {
+	n := 0
+	for _, u := range e.units().InDomain(domainGround) {
+		if matchesType(u.Type, Harvester) || matchesType(u.Type, MCV) || matchesType(u.Type, Engineer) ||
+			matchesType(u.Type, APC) {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// updateRushAlert raises the rush alert when enough enemy combat units reach
+// our base inside the rush window and our army is outmatched, and lowers it
+// once the base has been clear for a while.
+func updateRushAlert(env RuleEnv) {
+	tick := env.State.Tick
+	near := env.enemyCombatNearBase()
+	alert := getRushAlert(env.Memory)
+
+	if alert != nil {
+		if near > 0 {
+			alert.LastSeen = tick
+			alert.Peak = max(alert.Peak, near)
+		} else if tick-alert.LastSeen >= rushClearTicks {
+			slog.Info("rush repelled", "since", alert.Since, "peak", alert.Peak, "tick", tick)
+			rushAlertMemory.clear(env.Memory)
+		}
+		return
+	}
+
+	if tick > rushWindowTicks || near < rushMinEnemies {
+		return
+	}
+	if army := env.groundArmySize(); float64(army) >= float64(near)*rushArmyRatio {
+		return
+	}
+	rushAlertMemory.set(env.Memory, &rushAlert{Since: tick, LastSeen: tick, Peak: near})
+	slog.Warn("rush detected", "enemies", near, "army", env.groundArmySize(), "tick", tick)
+}
+
+// RushDetected reports whether we are currently defending an early rush.
+func (e RuleEnv) RushDetected() bool { return RushActive(e.Memory) }
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestRushAlertRaisedAndCleared(t *testing.T) {
+	mem := make(map[string]any)
+	rushers := []model.Enemy{
+		{ID: 100, Type: "e1", X: 22, Y: 20},
+		{ID: 101, Type: "e1", X: 23, Y: 21},
+		{ID: 102, Type: "dog", X: 21, Y: 22},
+	}
+	env := RuleEnv{
+		State: model.GameState{
+			Tick:      1500,
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+			Units:     []model.Unit{{ID: 2, Type: "e1", X: 20, Y: 22}, {ID: 3, Type: "harv", X: 30, Y: 30}},
+			Enemies:   rushers,
+		},
+		Memory: mem,
+	}
+
+	updateRushAlert(env)
+	if !env.RushDetected() {
+		t.Fatal("three attackers against one rifleman should raise a rush alert")
+	}
+
+	// The alert holds while the base is briefly clear...
+	env.State.Enemies = nil
+	env.State.Tick = 1500 + rushClearTicks - 1
+	updateRushAlert(env)
+	if !env.RushDetected() {
+		t.Error("rush alert dropped before the clear window elapsed")
+	}
+
+	// ...and lowers once it has stayed clear.
+	env.State.Tick = 1500 + rushClearTicks
+	updateRushAlert(env)
+	if env.RushDetected() {
+		t.Error("rush alert still raised after the base stayed clear")
+	}
+}
+
+func TestRushAlertIgnoresLateOrOutmatchedAttacks(t *testing.T) {
+	enemies := []model.Enemy{
+		{ID: 100, Type: "e1", X: 22, Y: 20},
+		{ID: 101, Type: "e1", X: 23, Y: 21},
+		{ID: 102, Type: "e1", X: 21, Y: 22},
+	}
+	base := model.GameState{
+		MapWidth:  100,
+		MapHeight: 100,
+		Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+		Enemies:   enemies,
+	}
+
+	late := RuleEnv{State: base, Memory: make(map[string]any)}
+	late.State.Tick = rushWindowTicks + 1
+	updateRushAlert(late)
+	if late.RushDetected() {
+		t.Error("attack after the rush window raised a rush alert")
+	}
+
+	strong := RuleEnv{State: base, Memory: make(map[string]any)}
+	strong.State.Tick = 1500
+	for i := range 5 {
+		strong.State.Units = append(strong.State.Units, model.Unit{ID: 10 + i, Type: "2tnk", X: 20, Y: 25})
+	}
+	updateRushAlert(strong)
+	if strong.RushDetected() {
+		t.Error("attack into a larger army raised a rush alert")
+	}
+}
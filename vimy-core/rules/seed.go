@@ -1,16 +1,19 @@
 package rules
 
+import "fmt"
+
 // DefaultRules returns a hardcoded rule set for play without an LLM strategist.
 // Superseded by CompileDoctrine when a strategist is active.
 func DefaultRules() []*Rule {
 	return []*Rule{
 		{
-			Name:         "deploy-mcv",
-			Priority:     1000,
-			Category:     "setup",
-			Exclusive:    true,
-			ConditionSrc: `HasUnit("mcv") && !HasBuilding("fact")`,
-			Action:       ActionDeployMCV,
+			Name:          "deploy-mcv",
+			Priority:      1000,
+			Category:      "setup",
+			Exclusive:     true,
+			CooldownTicks: deployMCVCooldown,
+			ConditionSrc:  `HasUnit("mcv") && !HasBuilding("fact")`,
+			Action:        ActionDeployMCV,
 		},
 		{
 			Name:         "place-ready-building",
@@ -25,7 +28,7 @@ func DefaultRules() []*Rule {
 			Priority:     800,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuild("Building","powr") && (PowerExcess() < 100 || BuildingCount("powr") == 0) && Cash() >= 300`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuild("Building","powr") && (PowerExcess() < 100 || BuildingCount("powr") == 0) && Cash() >= %d`, costOf(PowerPlant)),
 			Action:       ActionProducePowerPlant,
 		},
 		{
@@ -41,7 +44,7 @@ func DefaultRules() []*Rule {
 			Priority:     700,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && PowerExcess() >= 0 && Cash() >= 300`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuildRole("barracks") && !HasRole("barracks") && PowerExcess() >= 0 && Cash() >= %d`, roleCost("barracks")),
 			Action:       ActionProduceBarracks,
 		},
 		{
@@ -49,7 +52,7 @@ func DefaultRules() []*Rule {
 			Priority:     650,
 			Category:     "economy",
 			Exclusive:    true,
-			ConditionSrc: `!QueueBusy("Building") && CanBuild("Building","weap") && !HasBuilding("weap") && PowerExcess() >= 0 && Cash() >= 2000`,
+			ConditionSrc: fmt.Sprintf(`!QueueBusy("Building") && CanBuild("Building","weap") && !HasBuilding("weap") && PowerExcess() >= 0 && Cash() >= %d`, costOf(WarFactory)),
 			Action:       ActionProduceWarFactory,
 		},
 		{
@@ -57,7 +60,7 @@ func DefaultRules() []*Rule {
 			Priority:     500,
 			Category:     "production",
 			Exclusive:    false,
-			ConditionSrc: `HasRole("barracks") && !QueueBusy("Infantry") && UnitCount("e1") < 10 && Cash() >= 100`,
+			ConditionSrc: fmt.Sprintf(`HasRole("barracks") && !QueueBusy("Infantry") && UnitCount("e1") < 10 && Cash() >= %d`, costOf(RifleInfantry)),
 			Action:       ActionProduceInfantry,
 		},
 		{
@@ -65,6 +68,7 @@ func DefaultRules() []*Rule {
 			Priority:     400,
 			Category:     "combat",
 			Exclusive:    false,
+			HoldTicks:    defenseHoldTicks,
 			ConditionSrc: `BaseUnderAttack() && len(IdleGroundUnits()) >= 2`,
 			Action:       ActionDefendBase,
 		},
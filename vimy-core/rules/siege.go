@@ -0,0 +1,138 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+)
+
+// Siege and containment thresholds. A siege is enemy ground units camped in
+// a ring just outside our base for a long time without coming in; we are
+// contained when our attack squads keep getting wiped out before they get
+// far from home.
+// Both radii are multiples of the base radius (see baseThreatRadius), inside
+// which the enemy is attacking rather than camping.
+const (
+	siegeOuterRadiusScale = 1.75 // the siege ring reaches this multiple of the base radius
+	siegeMinEnemies       = 4    // campers needed in the ring
+	siegeHoldTicks        = 2250 // 90s of camping before it counts as a siege
+	siegeGapTicks         = 500  // 20s with the ring empty breaks the siege
+	containRadiusScale    = 1.5  // a squad wiped within this multiple of the base radius died at home
+	containWindowTicks    = 6000 // 4 minutes of attack history considered
+	containWipes          = 2    // wiped squads near base within the window
+)
+
+// siegeWatch tracks enemies camped in the siege ring. Since is when the
+// current stretch of camping began; it only becomes a siege once it has
+// lasted siegeHoldTicks.
+type siegeWatch struct {
+	Since    int
+	LastSeen int
+	Peak     int // most campers seen in the ring at once
+}
+
+func getSiegeWatch(memory map[string]any) *siegeWatch {
+	if v, ok := siegeWatchMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// nearOwnBase reports whether (x, y) lies within radius cells of any of our
+// buildings.
+func (e RuleEnv) nearOwnBase(x, y int, radius float64) bool {
+	for _, b := range e.State.Buildings {
+		if math.Hypot(float64(x-b.X), float64(y-b.Y)) < radius {
+			return true
+		}
+	}
+	return false
+}
+
+// enemyCampers counts visible enemy ground combat units inside the siege
+// ring: near our base but not close enough to be attacking it.
+func (e RuleEnv) enemyCampers() int {
+	if len(e.State.Buildings) == 0 {
+		return 0
+	}
+	inner := e.baseThreatRadius()
+	n := 0
+	for _, en := range e.State.Enemies {
+		if !isGroundCombatEnemy(en.Type) {
+			continue
+		}
+		if e.nearOwnBase(en.X, en.Y, inner*siegeOuterRadiusScale) && !e.nearOwnBase(en.X, en.Y, inner) {
+			n++
+		}
+	}
+	return n
+}
+
+// updateSiege follows enemies camped in the siege ring, dropping the watch
+// once the ring has been empty for siegeGapTicks.
+func updateSiege(env RuleEnv) {
+	tick := env.State.Tick
+	campers := env.enemyCampers()
+	w := getSiegeWatch(env.Memory)
+
+	if campers < siegeMinEnemies {
+		if w != nil && tick-w.LastSeen >= siegeGapTicks {
+			if w.LastSeen-w.Since >= siegeHoldTicks {
+				slog.Info("siege lifted", "since", w.Since, "peak", w.Peak, "tick", tick)
+			}
+			siegeWatchMemory.clear(env.Memory)
+		}
+		return
+	}
+	if w == nil {
+		siegeWatchMemory.set(env.Memory, &siegeWatch{Since: tick, LastSeen: tick, Peak: campers})
+		return
+	}
+	wasSieged := w.LastSeen-w.Since >= siegeHoldTicks
+	w.LastSeen = tick
+	w.Peak = max(w.Peak, campers)
+	if !wasSieged && w.LastSeen-w.Since >= siegeHoldTicks {
+		slog.Warn("base sieged", "campers", campers, "since", w.Since, "tick", tick)
+	}
+}
+
+// BaseSieged reports whether enemies have been camped outside our base for
+// at least siegeHoldTicks. Public so the strategist's event detector can
+// raise EventBaseSieged.
+func BaseSieged(memory map[string]any) bool {
+	w := getSiegeWatch(memory)
+	return w != nil && w.LastSeen-w.Since >= siegeHoldTicks
+}
+
+// SiegeCampers returns the most enemy units seen camped outside our base
+// during the current siege, or 0 when there is none.
+func SiegeCampers(memory map[string]any) int {
+	if !BaseSieged(memory) {
+		return 0
+	}
+	return getSiegeWatch(memory).Peak
+}
+
+// ContainedWipes counts attack squads wiped out near our own base within the
+// last containWindowTicks.
+func ContainedWipes(memory map[string]any, tick int) int {
+	n := 0
+	for _, o := range GetAttackHistory(memory) {
+		if o.Wiped && o.NearBase && tick-o.End <= containWindowTicks {
+			n++
+		}
+	}
+	return n
+}
+
+// BaseContained reports whether our attack squads keep dying at home — at
+// least containWipes wiped near base within the window. Public so the
+// strategist's event detector can raise EventContained.
+func BaseContained(memory map[string]any, tick int) bool {
+	return ContainedWipes(memory, tick) >= containWipes
+}
+
+// Sieged reports whether the enemy is camped outside our base.
+func (e RuleEnv) Sieged() bool { return BaseSieged(e.Memory) }
+
+// Contained reports whether our attacks keep being destroyed near home.
+func (e RuleEnv) Contained() bool { return BaseContained(e.Memory, e.State.Tick) }
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestSiegeRaisedAfterCampingAndLifted(t *testing.T) {
+	// Default defense margin: the ring runs 18 to 31.5 cells out.
+	campers := []model.Enemy{
+		{ID: 100, Type: "3tnk", X: 45, Y: 20},
+		{ID: 101, Type: "3tnk", X: 46, Y: 22},
+		{ID: 102, Type: "e1", X: 44, Y: 24},
+		{ID: 103, Type: "v2rl", X: 47, Y: 20},
+	}
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+			Enemies:   campers,
+		},
+		Memory: make(map[string]any),
+	}
+
+	updateSiege(env)
+	if env.Sieged() {
+		t.Fatal("siege raised on first sighting")
+	}
+	env.State.Tick = siegeHoldTicks
+	updateSiege(env)
+	if !env.Sieged() {
+		t.Fatal("campers held the ring for siegeHoldTicks without raising a siege")
+	}
+	if got := SiegeCampers(env.Memory); got != len(campers) {
+		t.Errorf("SiegeCampers = %d, want %d", got, len(campers))
+	}
+
+	env.State.Enemies = nil
+	env.State.Tick = siegeHoldTicks + siegeGapTicks
+	updateSiege(env)
+	if env.Sieged() {
+		t.Error("siege still raised after the ring stayed empty")
+	}
+}
+
+func TestSiegeIgnoresAttackers(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 20, Y: 20}},
+			Enemies: []model.Enemy{
+				{ID: 100, Type: "3tnk", X: 25, Y: 20},
+				{ID: 101, Type: "3tnk", X: 26, Y: 22},
+				{ID: 102, Type: "e1", X: 24, Y: 24},
+				{ID: 103, Type: "v2rl", X: 22, Y: 20},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	if n := env.enemyCampers(); n != 0 {
+		t.Errorf("enemies inside the base counted as %d campers", n)
+	}
+}
+
+func TestContainedAfterRepeatedWipesNearBase(t *testing.T) {
+	mem := map[string]any{"attackHistory": []AttackOutcome{
+		{Squad: "ground-attack", End: 1000, Wiped: true, NearBase: true},
+		{Squad: "ground-attack", End: 2000, Wiped: true, NearBase: false},
+		{Squad: "ground-attack", End: 3000, Wiped: false, NearBase: true},
+	}}
+	if BaseContained(mem, 3000) {
+		t.Fatal("one wipe near base should not count as contained")
+	}
+
+	mem["attackHistory"] = append(GetAttackHistory(mem), AttackOutcome{Squad: "ground-attack", End: 4000, Wiped: true, NearBase: true})
+	if !BaseContained(mem, 4000) {
+		t.Error("two wipes near base within the window should count as contained")
+	}
+	if BaseContained(mem, 1000+containWindowTicks+1) {
+		t.Error("old wipe still counted after leaving the window")
+	}
+}
@@ -9,12 +9,13 @@ type Squad struct {
 	Name       string // "attack-1", "defense", "scout"
 	Domain     string // "ground", "air", "naval"
 	UnitIDs    []int  // persistent unit roster
-	Role       string // "attack", "defend", "scout" — informational for LLM summary
+	Role       string // "attack", "defend", "scout", "raider", "siege" — informational for LLM summary
 	TargetSize int    // intended formation size; reinforcement tops up to this
+	SupportIDs []int  // medics trailing the squad; not counted toward TargetSize
 }
 
 func getSquads(memory map[string]any) map[string]*Squad {
-	if v, ok := memory["squads"].(map[string]*Squad); ok {
+	if v, ok := squadsMemory.get(memory); ok {
 		return v
 	}
 	return make(map[string]*Squad)
@@ -29,23 +30,32 @@ func GetSquads(memory map[string]any) map[string]*Squad {
 // are dissolved so formation rules can create fresh ones.
 func updateSquads(env RuleEnv) {
 	squads := getSquads(env.Memory)
-	aliveIDs := makeUnitIDSet(env.State.Units)
+	units := env.units()
 
 	for name, sq := range squads {
 		alive := sq.UnitIDs[:0]
 		for _, id := range sq.UnitIDs {
-			if aliveIDs[id] {
+			if units.Has(id) {
 				alive = append(alive, id)
 			}
 		}
 		sq.UnitIDs = alive
 
+		support := sq.SupportIDs[:0]
+		for _, id := range sq.SupportIDs {
+			if units.Has(id) {
+				support = append(support, id)
+			}
+		}
+		sq.SupportIDs = support
+
+		// Support units are released with the squad they were attached to.
 		if len(sq.UnitIDs) == 0 {
 			delete(squads, name)
-			delete(env.Memory, "huntBase:"+name)
+			delete(getHuntBase(env.Memory), name)
 		}
 	}
-	env.Memory["squads"] = squads
+	env.setSquads(squads)
 }
 
 func makeUnitIDSet(units []model.Unit) map[int]bool {
@@ -64,6 +74,83 @@ func squadUnitIDSet(memory map[string]any) map[int]bool {
 		for _, id := range sq.UnitIDs {
 			s[id] = true
 		}
+		for _, id := range sq.SupportIDs {
+			s[id] = true
+		}
 	}
 	return s
 }
+
+// Medic support slots: one medic per medicInfantryRatio infantry, up to
+// maxMedicsPerSquad, and only for squads that are mostly infantry.
+const (
+	medicInfantryRatio = 4
+	maxMedicsPerSquad  = 2
+	medicMinInfantry   = 0.5 // infantry share of a squad before it gets medics
+	medicTrailDistance = 3   // cells behind the squad centroid, away from the target
+)
+
+// IdleUnattachedMedics returns idle medics not supporting any squad.
+func (e RuleEnv) IdleUnattachedMedics() []model.Unit {
+	assigned := squadUnitIDSet(e.Memory)
+	var out []model.Unit
+	for _, u := range e.idleUnits() {
+		if matchesType(u.Type, Medic) && !assigned[u.ID] {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// medicSlots returns how many medics the squad should carry.
+func medicSlots(sq *Squad, units map[int]model.Unit) int {
+	infantry, present := 0, 0
+	for _, id := range sq.UnitIDs {
+		if u, ok := units[id]; ok {
+			present++
+			if isInfantry(u) {
+				infantry++
+			}
+		}
+	}
+	if present == 0 || float64(infantry)/float64(present) < medicMinInfantry {
+		return 0
+	}
+	return min(maxMedicsPerSquad, max(1, infantry/medicInfantryRatio))
+}
+
+// medicSquad returns the ground squad most in need of a medic: the one with
+// the most infantry among those with a free support slot, or nil.
+func (e RuleEnv) medicSquad() *Squad {
+	units := e.units().ByID()
+	var best *Squad
+	bestInfantry := 0
+	for _, sq := range getSquads(e.Memory) {
+		if sq.Domain != "ground" || len(sq.SupportIDs) >= medicSlots(sq, units) {
+			continue
+		}
+		infantry := 0
+		for _, id := range sq.UnitIDs {
+			if isInfantry(units[id]) {
+				infantry++
+			}
+		}
+		if infantry > bestInfantry || (infantry == bestInfantry && best != nil && sq.Name < best.Name) {
+			best, bestInfantry = sq, infantry
+		}
+	}
+	return best
+}
+
+// HasMedicSlot reports whether any infantry squad has room for a medic.
+func (e RuleEnv) HasMedicSlot() bool { return e.medicSquad() != nil }
+
+// HasSquadSupport reports whether any squad has support units attached.
+func (e RuleEnv) HasSquadSupport() bool {
+	for _, sq := range getSquads(e.Memory) {
+		if len(sq.SupportIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/nstehr/vimy/vimy-core/model"
@@ -370,7 +371,9 @@ func TestHuntStateCleanupOnDissolve(t *testing.T) {
 				TargetSize: 5,
 			},
 		},
-		"huntBase:doomed": &huntBaseState{BaseX: 100, BaseY: 200, Step: 3},
+		"huntBase": map[string]*huntBaseState{
+			"doomed": {BaseX: 100, BaseY: 200, Step: 3},
+		},
 	}
 	env := RuleEnv{
 		State: model.GameState{
@@ -382,8 +385,8 @@ func TestHuntStateCleanupOnDissolve(t *testing.T) {
 
 	updateSquads(env)
 
-	if _, ok := memory["huntBase:doomed"]; ok {
-		t.Error("expected huntBase:doomed to be cleaned up on dissolution")
+	if _, ok := getHuntBase(memory)["doomed"]; ok {
+		t.Error("expected the doomed squad's hunt state to be cleaned up on dissolution")
 	}
 }
 
@@ -441,3 +444,77 @@ func TestSwapClearsSquads(t *testing.T) {
 		t.Error("expected squads to be cleared after Swap")
 	}
 }
+
+func TestMedicsAttachToInfantrySquads(t *testing.T) {
+	mem := map[string]any{"squads": map[string]*Squad{
+		"infantry": {Name: "infantry", Domain: "ground", UnitIDs: []int{1, 2, 3, 4}},
+		"armor":    {Name: "armor", Domain: "ground", UnitIDs: []int{5, 6}},
+	}}
+	env := RuleEnv{
+		State: model.GameState{Units: []model.Unit{
+			{ID: 1, Type: "e1"}, {ID: 2, Type: "e1"}, {ID: 3, Type: "e3"}, {ID: 4, Type: "e1"},
+			{ID: 5, Type: "2tnk"}, {ID: 6, Type: "2tnk"},
+			{ID: 10, Type: "medi", Idle: true},
+			{ID: 11, Type: "medi", Idle: true},
+		}},
+		Memory: mem,
+	}
+
+	for _, u := range env.IdleGroundUnits() {
+		if u.Type == "medi" {
+			t.Fatal("medics must not enter the combat pool")
+		}
+	}
+	if err := ActionAttachMedics(env, nil); err != nil {
+		t.Fatal(err)
+	}
+	sq := getSquads(mem)["infantry"]
+	if len(sq.SupportIDs) != 1 {
+		t.Fatalf("infantry squad support = %v, want one medic (4 infantry)", sq.SupportIDs)
+	}
+	if len(getSquads(mem)["armor"].SupportIDs) != 0 {
+		t.Error("armor squad should not get a medic")
+	}
+	if got := env.IdleUnattachedMedics(); len(got) != 1 {
+		t.Errorf("IdleUnattachedMedics = %v, want the spare medic", got)
+	}
+
+	// Attached medic dies: the slot frees up.
+	env.State.Units = slices.DeleteFunc(env.State.Units, func(u model.Unit) bool { return u.ID == sq.SupportIDs[0] })
+	updateSquads(env)
+	if len(getSquads(mem)["infantry"].SupportIDs) != 0 || !env.HasMedicSlot() {
+		t.Error("dead medic should be pruned and its slot reopened")
+	}
+}
+
+func TestIdleGroundUnitsSkipOwnedUnits(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Tick: 100,
+			Units: []model.Unit{
+				{ID: 1, Type: "2tnk", Idle: true}, // squad
+				{ID: 2, Type: "e1", Idle: true},   // escort
+				{ID: 3, Type: "3tnk", Idle: true}, // on a scout task
+				{ID: 4, Type: "e6", Idle: true},   // capturing
+				{ID: 5, Type: "2tnk", Idle: true}, // free
+			},
+		},
+		Memory: map[string]any{
+			"squads":      map[string]*Squad{"ground-attack": {Name: "ground-attack", Domain: "ground", UnitIDs: []int{1}}},
+			"escorts":     map[int]*escort{2: {Charge: 50, Kind: escortHarvester}},
+			"scoutTasks":  map[int]*scoutTask{3: {X: 10, Y: 10}},
+			"captureHold": &captureHold{EnemyID: 60, Engineer: 4, Tick: 90},
+		},
+	}
+	for id, want := range map[int]string{1: ownerSquad, 2: ownerEscort, 3: ownerScout, 4: ownerCapture, 5: ""} {
+		if got := env.UnitOwner(id); got != want {
+			t.Errorf("UnitOwner(%d) = %q, want %q", id, got, want)
+		}
+	}
+	if got := env.IdleGroundUnits(); len(got) != 1 || got[0].ID != 5 {
+		t.Errorf("IdleGroundUnits = %v, want only the free unit 5", got)
+	}
+	if got := env.AllIdleGroundUnits(); len(got) != 4 {
+		t.Errorf("AllIdleGroundUnits returned %d units, want 4 (all but the engineer)", len(got))
+	}
+}
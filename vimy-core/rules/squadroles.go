@@ -0,0 +1,255 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Squad roles beyond attack and defend. The main force ("ground-attack")
+// takes whatever is idle; two specialised squads are carved out of the
+// pool first when the army has the vehicles for them:
+//
+//   - "raiders": fast light vehicles that go for the enemy economy (see
+//     harassTarget) instead of the front line.
+//   - "siege": artillery with an escort, sent against enemy defenses it
+//     outranges. The escort guards the guns rather than charging ahead.
+
+var (
+	raiderRoles = []string{"light_tank", "ranger"}
+	siegeRoles  = []string{"artillery", "v2_launcher"}
+)
+
+// hasAnyRole reports whether the mod defines any of names.
+func (m *Mod) hasAnyRole(names []string) bool {
+	return slices.ContainsFunc(names, func(n string) bool {
+		_, ok := m.role(n)
+		return ok
+	})
+}
+
+// inRoles reports whether unit type t belongs to any of the named roles.
+func (e RuleEnv) inRoles(t string, names []string) bool {
+	for _, name := range names {
+		r, ok := e.Mod.role(name)
+		if ok && slices.ContainsFunc(r.types, func(rt string) bool { return matchesType(t, rt) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnassignedIdleRaiders returns idle unassigned ground units fit to raid.
+func (e RuleEnv) UnassignedIdleRaiders() []model.Unit {
+	var out []model.Unit
+	for _, u := range e.UnassignedIdleGround() {
+		if e.inRoles(u.Type, raiderRoles) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// UnassignedIdleArtillery returns idle unassigned long-range ground units.
+func (e RuleEnv) UnassignedIdleArtillery() []model.Unit {
+	var out []model.Unit
+	for _, u := range e.UnassignedIdleGround() {
+		if e.inRoles(u.Type, siegeRoles) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// siegeArtillery is how many guns a siege squad of size carries; the rest
+// is escort.
+func siegeArtillery(size int) int { return max(1, (size+1)/2) }
+
+// FormRoleSquad is FormSquad for the "raider" and "siege" roles: it fills
+// the squad from the units that role calls for.
+func FormRoleSquad(name, role string, size int) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		squads := getSquads(env.Memory)
+		sq, exists := squads[name]
+		if exists && len(sq.UnitIDs) == 0 {
+			exists = false
+		}
+		have := 0
+		if exists {
+			if len(sq.UnitIDs) >= sq.TargetSize {
+				return nil
+			}
+			have = len(sq.UnitIDs)
+		}
+
+		var picked []int
+		switch role {
+		case "raider":
+			for _, u := range env.UnassignedIdleRaiders() {
+				picked = append(picked, u.ID)
+			}
+		case "siege":
+			units := env.units().ByID()
+			guns := 0
+			if exists {
+				for _, id := range sq.UnitIDs {
+					if env.inRoles(units[id].Type, siegeRoles) {
+						guns++
+					}
+				}
+			}
+			// Guns first, up to the squad's share; escorts fill the rest.
+			for _, u := range env.UnassignedIdleArtillery() {
+				if guns >= siegeArtillery(size) {
+					break
+				}
+				picked = append(picked, u.ID)
+				guns++
+			}
+			if guns < siegeArtillery(size) && !exists {
+				return nil
+			}
+			for _, u := range env.UnassignedIdleGround() {
+				if !env.inRoles(u.Type, siegeRoles) && !env.inRoles(u.Type, raiderRoles) {
+					picked = append(picked, u.ID)
+				}
+			}
+		}
+		picked = picked[:min(len(picked), size-have)]
+
+		if exists {
+			if len(picked) == 0 {
+				return nil
+			}
+			sq.UnitIDs = append(sq.UnitIDs, picked...)
+			env.setSquads(squads)
+			slog.Info("squad reinforced", "name", name, "added", len(picked), "size", len(sq.UnitIDs), "target", sq.TargetSize)
+			return nil
+		}
+		if len(picked) < size {
+			return nil
+		}
+		squads[name] = &Squad{
+			Name:       name,
+			Domain:     "ground",
+			UnitIDs:    picked,
+			Role:       role,
+			TargetSize: size,
+		}
+		env.setSquads(squads)
+		slog.Info("squad formed", "name", name, "domain", "ground", "role", role, "size", size)
+		return nil
+	}
+}
+
+// squadPosition returns the centroid of the squad's living members; ok is
+// false when none are on the map.
+func (e RuleEnv) squadPosition(name string) (x, y int, ok bool) {
+	sq, found := getSquads(e.Memory)[name]
+	if !found {
+		return 0, 0, false
+	}
+	x, y, n := squadCentroid(sq, e.units().ByID())
+	return x, y, n > 0
+}
+
+// squadTarget is where a role squad is sent. ID is the enemy actor to
+// attack when it is in sight, 0 to attack-move onto a remembered position.
+type squadTarget struct {
+	ID   int
+	X, Y int
+}
+
+// nearestTarget returns the visible enemy matching visible nearest (x, y),
+// falling back to the nearest remembered standing structure matching
+// remembered.
+func (e RuleEnv) nearestTarget(x, y int, visible, remembered func(t string) bool) *squadTarget {
+	var best *squadTarget
+	bestDist := math.MaxFloat64
+	consider := func(id, tx, ty int) {
+		if d := math.Hypot(float64(tx-x), float64(ty-y)); d < bestDist {
+			bestDist = d
+			best = &squadTarget{ID: id, X: tx, Y: ty}
+		}
+	}
+	for _, en := range e.State.Enemies {
+		if visible(en.Type) {
+			consider(en.ID, en.X, en.Y)
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for _, s := range GetEnemyStructures(e.Memory) {
+		if !s.Destroyed && remembered(s.Type) {
+			consider(0, s.X, s.Y)
+		}
+	}
+	return best
+}
+
+// siegeTarget returns the enemy defense nearest the siege squad.
+func (e RuleEnv) siegeTarget(name string) *squadTarget {
+	x, y, ok := e.squadPosition(name)
+	if !ok {
+		return nil
+	}
+	return e.nearestTarget(x, y, isDefenseType, isDefenseType)
+}
+
+// HasSiegeTarget reports whether the named siege squad has a defense to shell.
+func (e RuleEnv) HasSiegeTarget(name string) bool { return e.siegeTarget(name) != nil }
+
+// sendAtTarget attacks t with each of ids when it is in sight, and
+// attack-moves them onto its position otherwise.
+func sendAtTarget(conn *ipc.Connection, ids []uint32, t *squadTarget) error {
+	if t.ID == 0 {
+		return conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{ActorIDs: ids, X: t.X, Y: t.Y})
+	}
+	for _, id := range ids {
+		if err := conn.Send(ipc.TypeAttack, ipc.AttackCommand{ActorID: id, TargetID: uint32(t.ID)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SquadSiege sends the named siege squad's idle guns at the nearest enemy
+// defense and has its idle escorts guard the guns.
+func SquadSiege(name string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		t := env.siegeTarget(name)
+		if t == nil {
+			return nil
+		}
+		sq := getSquads(env.Memory)[name]
+		units := env.units()
+		var guns, escorts []uint32
+		for _, id := range squadIdleActorIDs(env, name) {
+			if u, _ := units.Get(int(id)); env.inRoles(u.Type, siegeRoles) {
+				guns = append(guns, id)
+			} else {
+				escorts = append(escorts, id)
+			}
+		}
+		if len(guns) > 0 {
+			slog.Debug("siege squad shelling", "squad", name, "guns", len(guns), "target", t.ID, "x", t.X, "y", t.Y)
+			if err := sendAtTarget(conn, guns, t); err != nil {
+				return err
+			}
+		}
+		if len(escorts) == 0 {
+			return nil
+		}
+		// Escorts guard the first gun still alive, idle or not.
+		for _, id := range sq.UnitIDs {
+			if u, _ := units.Get(id); env.inRoles(u.Type, siegeRoles) {
+				return conn.Send(ipc.TypeGuard, ipc.GuardCommand{ActorIDs: escorts, TargetID: uint32(id)})
+			}
+		}
+		return sendAtTarget(conn, escorts, t)
+	}
+}
@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestFormRoleSquadSiege(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "arty", Idle: true},
+				{ID: 2, Type: "arty", Idle: true},
+				{ID: 3, Type: "arty", Idle: true},
+				{ID: 4, Type: "1tnk", Idle: true},
+				{ID: 5, Type: "2tnk", Idle: true},
+				{ID: 6, Type: "3tnk", Idle: true},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	if err := FormRoleSquad("siege", "siege", 4)(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	sq := getSquads(env.Memory)["siege"]
+	if sq == nil || sq.Role != "siege" || len(sq.UnitIDs) != 4 {
+		t.Fatalf("siege squad = %+v, want 4 units in role siege", sq)
+	}
+	guns := 0
+	for _, id := range sq.UnitIDs {
+		if id == 4 {
+			t.Error("light tank taken as escort; raiders need it")
+		}
+		if id <= 3 {
+			guns++
+		}
+	}
+	if guns != siegeArtillery(4) {
+		t.Errorf("squad has %d guns, want %d", guns, siegeArtillery(4))
+	}
+}
+
+func TestFormRoleSquadNeedsGuns(t *testing.T) {
+	conn, cleanup := testConn(t)
+	defer cleanup()
+
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, Type: "arty", Idle: true},
+				{ID: 2, Type: "3tnk", Idle: true},
+				{ID: 3, Type: "3tnk", Idle: true},
+				{ID: 4, Type: "3tnk", Idle: true},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	FormRoleSquad("siege", "siege", 4)(env, conn)
+	if env.SquadExists("siege") {
+		t.Error("siege squad formed with one gun of two")
+	}
+}
+
+func TestCompileRoleSquads(t *testing.T) {
+	d := Doctrine{
+		Name: "Armour", EconomyPriority: 0.5, Aggression: 0.8, VehicleWeight: 0.8,
+		GroundAttackGroupSize: 6, AirAttackGroupSize: 2, NavalAttackGroupSize: 3,
+	}
+	rules := CompileDoctrine(d)
+	for _, name := range []string{"form-raider-squad", "squad-raid", "form-siege-squad", "squad-siege"} {
+		if findRule(rules, name) == nil {
+			t.Errorf("expected %s for an aggressive vehicle doctrine", name)
+		}
+	}
+
+	d.VehicleWeight = 0.1
+	d.InfantryWeight = 0.8
+	for _, r := range CompileDoctrine(d) {
+		if r.Name == "form-raider-squad" || r.Name == "form-siege-squad" {
+			t.Errorf("unexpected %s without vehicles", r.Name)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+)
+
+// Defense squad stations. Between attacks a defense squad's idle members hold
+// posts on the base perimeter facing the threat — the direction defenseHint
+// faces new defenses — spread in a line across it, rather than standing
+// wherever the squad formed. When the threat direction swings by more than
+// stationRotateAngle the posts rotate to the new front.
+const (
+	stationRingFactor    = patrolRingFactor // posts sit on the patrol ring
+	stationSpacing       = 2.0              // cells between neighbouring posts
+	stationSlack         = 3.0              // a member this close to its post is on station
+	stationRotateAngle   = math.Pi / 8      // threat swing that moves the posts
+	stationCooldownTicks = 50               // 2s, so a post a member can't reach isn't re-ordered every tick
+)
+
+// defenseStation is the front a squad's posts face, kept so small wobbles in
+// the threat direction don't shuffle the squad.
+type defenseStation struct {
+	Angle float64 // radians from the base centre
+}
+
+func getDefenseStations(memory map[string]any) map[string]*defenseStation {
+	if v, ok := defenseStationsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]*defenseStation)
+}
+
+// stationAngle returns the front the named squad should face: the current
+// threat direction, or the squad's stored front while the threat stays
+// within stationRotateAngle of it. ok is false with no threat on record.
+func (e RuleEnv) stationAngle(name string, cx, cy int) (angle float64, ok bool) {
+	tx, ty, ok := threatDirection(e, cx, cy)
+	if !ok {
+		return 0, false
+	}
+	angle = math.Atan2(ty, tx)
+	if st := getDefenseStations(e.Memory)[name]; st != nil {
+		if math.Abs(math.Remainder(angle-st.Angle, 2*math.Pi)) < stationRotateAngle {
+			return st.Angle, true
+		}
+	}
+	return angle, true
+}
+
+// stationPosts returns a post for each member of the named squad facing the
+// given front: a line across it centred on the perimeter ring, members in
+// squad order. A post off the map or off land falls back to the line's
+// centre.
+func (e RuleEnv) stationPosts(name string, angle float64) map[int][2]int {
+	members := e.units().InSquad(name)
+	if len(members) == 0 || len(e.State.Buildings) == 0 {
+		return nil
+	}
+	cx, cy, radius := baseRing(e.State.Buildings)
+	tx, ty := math.Cos(angle), math.Sin(angle)
+	r := radius * stationRingFactor
+	px, py := float64(cx)+tx*r, float64(cy)+ty*r
+	centre := [2]int{int(math.Round(px)), int(math.Round(py))}
+
+	posts := make(map[int][2]int, len(members))
+	for i, u := range members {
+		off := (float64(i) - float64(len(members)-1)/2) * stationSpacing
+		x, y := int(math.Round(px-ty*off)), int(math.Round(py+tx*off))
+		post := [2]int{x, y}
+		if x < 0 || y < 0 || (e.State.MapWidth > 0 && x >= e.State.MapWidth) || (e.State.MapHeight > 0 && y >= e.State.MapHeight) || !e.IsLandAt(x, y) {
+			post = centre
+		}
+		posts[u.ID] = post
+	}
+	return posts
+}
+
+// SquadOffStation reports whether an idle member of the named squad is away
+// from its perimeter post, or the threat has swung enough to move the posts.
+func (e RuleEnv) SquadOffStation(name string) bool {
+	if len(e.State.Buildings) == 0 {
+		return false
+	}
+	cx, cy, _ := baseRing(e.State.Buildings)
+	angle, ok := e.stationAngle(name, cx, cy)
+	if !ok {
+		return false
+	}
+	posts := e.stationPosts(name, angle)
+	for _, u := range e.units().InSquad(name) {
+		post, ok := posts[u.ID]
+		if ok && u.Idle && math.Hypot(float64(u.X-post[0]), float64(u.Y-post[1])) > stationSlack {
+			return true
+		}
+	}
+	return false
+}
+
+// StationSquad attack-moves each idle member of the named squad that is off
+// station to its post on the threat-facing perimeter.
+func StationSquad(name string) ActionFunc {
+	return func(env RuleEnv, conn *ipc.Connection) error {
+		if len(env.State.Buildings) == 0 {
+			return nil
+		}
+		cx, cy, _ := baseRing(env.State.Buildings)
+		angle, ok := env.stationAngle(name, cx, cy)
+		if !ok {
+			return nil
+		}
+		stations := getDefenseStations(env.Memory)
+		if st := stations[name]; st == nil || st.Angle != angle {
+			slog.Info("defense squad taking station", "squad", name, "facing", math.Round(angle*180/math.Pi))
+			stations[name] = &defenseStation{Angle: angle}
+			defenseStationsMemory.set(env.Memory, stations)
+		}
+
+		posts := env.stationPosts(name, angle)
+		for _, u := range env.units().InSquad(name) {
+			post, ok := posts[u.ID]
+			if !ok || !u.Idle || math.Hypot(float64(u.X-post[0]), float64(u.Y-post[1])) <= stationSlack {
+				continue
+			}
+			if err := conn.Send(ipc.TypeAttackMove, ipc.AttackMoveCommand{
+				ActorIDs: []uint32{uint32(u.ID)},
+				X:        post[0],
+				Y:        post[1],
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// stationEnv is a base centred on (20, 20) with three idle defense squad
+// members at the war factory and an enemy base due east.
+func stationEnv() RuleEnv {
+	mem := map[string]any{
+		"squads": map[string]*Squad{
+			"ground-defense": {Name: "ground-defense", Domain: "ground", Role: "defend", UnitIDs: []int{1, 2, 3}},
+		},
+	}
+	enemyBasesMemory.set(mem, map[string]EnemyBaseIntel{"enemy": {Owner: "enemy", X: 100, Y: 20, FromBuildings: true}})
+	return RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 100, Type: "fact", X: 20, Y: 20},
+				{ID: 101, Type: "weap", X: 12, Y: 20},
+				{ID: 102, Type: "proc", X: 28, Y: 20},
+			},
+			Units: []model.Unit{
+				{ID: 1, Type: "3tnk", X: 12, Y: 22, Idle: true},
+				{ID: 2, Type: "3tnk", X: 13, Y: 22, Idle: true},
+				{ID: 3, Type: "3tnk", X: 14, Y: 22, Idle: true},
+			},
+		},
+		Memory: mem,
+	}
+}
+
+func TestDefenseSquadStationsFacingThreat(t *testing.T) {
+	env := stationEnv()
+	if !env.SquadOffStation("ground-defense") {
+		t.Fatal("squad at the war factory should be off station")
+	}
+
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	if err := StationSquad("ground-defense")(env, conn); err != nil {
+		t.Fatal(err)
+	}
+
+	cx, cy, _ := baseRing(env.State.Buildings)
+	angle, _ := env.stationAngle("ground-defense", cx, cy)
+	posts := env.stationPosts("ground-defense", angle)
+	for id, p := range posts {
+		if p[0] <= 28 {
+			t.Errorf("member %d posted at %v, want east of the base, facing the enemy", id, p)
+		}
+	}
+	if posts[1] == posts[2] || posts[2] == posts[3] {
+		t.Errorf("posts %v should spread the members", posts)
+	}
+
+	// On their posts, the squad is on station.
+	for i := range env.State.Units {
+		p := posts[env.State.Units[i].ID]
+		env.State.Units[i].X, env.State.Units[i].Y = p[0], p[1]
+	}
+	if env.SquadOffStation("ground-defense") {
+		t.Error("squad on its posts reported off station")
+	}
+
+	// A small swing in the threat leaves them; a large one rotates the posts.
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"enemy": {Owner: "enemy", X: 100, Y: 30, FromBuildings: true}})
+	if env.SquadOffStation("ground-defense") {
+		t.Error("a small swing in the threat moved the posts")
+	}
+	enemyBasesMemory.set(env.Memory, map[string]EnemyBaseIntel{"enemy": {Owner: "enemy", X: 20, Y: 120, FromBuildings: true}})
+	if !env.SquadOffStation("ground-defense") {
+		t.Fatal("the threat moved south but the squad stayed on the east perimeter")
+	}
+	if err := StationSquad("ground-defense")(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	angle, _ = env.stationAngle("ground-defense", cx, cy)
+	for id, p := range env.stationPosts("ground-defense", angle) {
+		if p[1] <= 20 {
+			t.Errorf("member %d posted at %v after the swing, want south of the base", id, p)
+		}
+	}
+}
+
+func TestStationNeedsKnownThreat(t *testing.T) {
+	env := stationEnv()
+	enemyBasesMemory.clear(env.Memory)
+	if env.SquadOffStation("ground-defense") {
+		t.Error("no enemy base on record, yet the squad was sent to station")
+	}
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"log/slog"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// EnemyStructure is one remembered enemy building. EnemyBaseIntel keeps
+// only a per-owner centroid; this keeps every building we've seen, so
+// known-base attacks can go for a specific structure instead of sweeping
+// a ring around the centroid. Destroyed buildings stay on record, flagged,
+// once we've seen their site empty.
+type EnemyStructure struct {
+	ID        int
+	Owner     string
+	Type      string
+	X, Y      int
+	FirstSeen int
+	LastSeen  int
+	Destroyed bool
+}
+
+// GetEnemyStructures returns remembered enemy buildings by actor ID,
+// including confirmed-destroyed ones.
+func GetEnemyStructures(memory map[string]any) map[int]*EnemyStructure {
+	if v, ok := enemyStructuresMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*EnemyStructure)
+}
+
+// updateEnemyStructures records visible enemy buildings and marks ones
+// destroyed whose site we can see but which are no longer there.
+func updateEnemyStructures(env RuleEnv) {
+	structures := GetEnemyStructures(env.Memory)
+	visible := make(map[int]bool)
+	for _, en := range env.State.Enemies {
+		if !IsKnownBuildingType(en.Type) {
+			continue
+		}
+		visible[en.ID] = true
+		if s, ok := structures[en.ID]; ok {
+			s.X, s.Y, s.LastSeen = en.X, en.Y, env.State.Tick
+			s.Destroyed = false
+			continue
+		}
+		structures[en.ID] = &EnemyStructure{
+			ID: en.ID, Owner: en.Owner, Type: en.Type, X: en.X, Y: en.Y,
+			FirstSeen: env.State.Tick, LastSeen: env.State.Tick,
+		}
+	}
+
+	for id, s := range structures {
+		if s.Destroyed || visible[id] || !siteObserved(env, s.X, s.Y) {
+			continue
+		}
+		slog.Debug("enemy structure confirmed destroyed", "id", id, "owner", s.Owner, "type", s.Type, "x", s.X, "y", s.Y)
+		s.Destroyed = true
+	}
+	if len(structures) > 0 {
+		enemyStructuresMemory.set(env.Memory, structures)
+	}
+}
+
+// KnownEnemyStructures returns the owner's remembered buildings not known
+// to be destroyed.
+func (e RuleEnv) KnownEnemyStructures(owner string) []*EnemyStructure {
+	var out []*EnemyStructure
+	for _, s := range GetEnemyStructures(e.Memory) {
+		if s.Owner == owner && !s.Destroyed {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// knownStructureTarget returns the owner's standing remembered building
+// that score (groundTargetScore or airTargetScore, measured from where the
+// attackers are) rates highest, or nil if we know of none. Ties go to the
+// lower ID so repeated calls agree.
+func (e RuleEnv) knownStructureTarget(owner string, score func(*model.Enemy) float64) *EnemyStructure {
+	var best *EnemyStructure
+	bestScore := -1.0
+	for _, s := range e.KnownEnemyStructures(owner) {
+		// Remembered buildings have no current health; score them as full.
+		sc := score(&model.Enemy{ID: s.ID, Owner: s.Owner, Type: s.Type, X: s.X, Y: s.Y, HP: 1, MaxHP: 1})
+		if sc > bestScore || (sc == bestScore && best != nil && s.ID < best.ID) {
+			best, bestScore = s, sc
+		}
+	}
+	return best
+}
+
+// knownBaseStructure picks the remembered building of owner that the
+// attackers among ids should go for, scored from their position (our base
+// if none are found), or nil when we know the base only by its centroid.
+func (e RuleEnv) knownBaseStructure(owner string, ids []uint32, air bool) *EnemyStructure {
+	x, y, n := e.actorCentroid(ids)
+	if n == 0 {
+		x, y = e.targetOrigin()
+	}
+	if air {
+		return e.knownStructureTarget(owner, func(en *model.Enemy) float64 { return airTargetScore(en, x, y) })
+	}
+	return e.knownStructureTarget(owner, func(en *model.Enemy) float64 { return groundTargetScore(en, x, y) })
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestUpdateEnemyStructures(t *testing.T) {
+	mem := map[string]any{}
+	updateEnemyStructures(RuleEnv{State: model.GameState{Tick: 100, Enemies: []model.Enemy{
+		{ID: 10, Owner: "Enemy", Type: "powr", X: 80, Y: 80, HP: 100, MaxHP: 100},
+		{ID: 11, Owner: "Enemy", Type: "weap", X: 90, Y: 80, HP: 100, MaxHP: 100},
+		{ID: 12, Owner: "Enemy", Type: "3tnk", X: 40, Y: 40, HP: 100, MaxHP: 100},
+	}}, Memory: mem})
+
+	structures := GetEnemyStructures(mem)
+	if len(structures) != 2 {
+		t.Fatalf("remembered %d structures, want 2 (units are not structures)", len(structures))
+	}
+
+	// Our tank reaches the power plant and finds it gone; the war factory
+	// is still out of sight.
+	updateEnemyStructures(RuleEnv{State: model.GameState{Tick: 400, Units: []model.Unit{{ID: 1, Type: "2tnk", X: 81, Y: 80}}}, Memory: mem})
+	if !structures[10].Destroyed {
+		t.Error("power plant should be confirmed destroyed")
+	}
+	if structures[11].Destroyed || structures[11].LastSeen != 100 {
+		t.Errorf("war factory should be remembered as last seen at 100, got %+v", structures[11])
+	}
+	known := (RuleEnv{Memory: mem}).KnownEnemyStructures("Enemy")
+	if len(known) != 1 || known[0].ID != 11 {
+		t.Errorf("KnownEnemyStructures = %v, want only the war factory", known)
+	}
+}
+
+func TestKnownBaseStructurePrefersValuableTargets(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{{ID: 1, Type: "2tnk", X: 50, Y: 50}},
+		},
+		Memory: map[string]any{},
+	}
+	enemyStructuresMemory.set(env.Memory, map[int]*EnemyStructure{
+		20: {ID: 20, Owner: "Enemy", Type: "silo", X: 60, Y: 50},
+		21: {ID: 21, Owner: "Enemy", Type: "tsla", X: 64, Y: 50},
+		22: {ID: 22, Owner: "Enemy", Type: "mslo", X: 62, Y: 52, Destroyed: true},
+		23: {ID: 23, Owner: "Other", Type: "tsla", X: 55, Y: 50},
+	})
+
+	s := env.knownBaseStructure("Enemy", []uint32{1}, false)
+	if s == nil || s.ID != 21 {
+		t.Errorf("knownBaseStructure = %+v, want the tesla coil 21", s)
+	}
+	if s := env.knownBaseStructure("Nobody", []uint32{1}, false); s != nil {
+		t.Errorf("knownBaseStructure for an unknown owner = %+v, want nil", s)
+	}
+}
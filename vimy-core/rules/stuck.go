@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Stuck-unit watchdog thresholds.
+const (
+	stuckTicks         = 250 // 10s without moving despite having orders
+	stuckEngageRadius  = 8.0 // units this close to an enemy are fighting, not stuck
+	stuckNudgeDistance = 3   // cells to sidestep on a nudge
+	stuckRepathAfter   = 2   // nudges before giving up and re-pathing home
+	stuckSpotGrid      = 4   // cells per stuck-spot bucket
+	chronicStuckCount  = 3   // stuck events in one bucket before it's avoided
+)
+
+// unitHistory is the last known position of a unit with orders, and how
+// long it has been there.
+type unitHistory struct {
+	X, Y   int
+	Since  int // tick the unit last moved
+	Nudges int // recovery attempts since it last moved freely
+}
+
+func getUnitHistory(memory map[string]any) map[int]*unitHistory {
+	if v, ok := unitPositionsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*unitHistory)
+}
+
+// getStuckSpots returns stuck-event counts per bucket, keyed by
+// stuckSpotKey so the counts survive a checkpoint.
+func getStuckSpots(memory map[string]any) map[string]int {
+	if v, ok := stuckSpotsMemory.get(memory); ok {
+		return v
+	}
+	return make(map[string]int)
+}
+
+// stuckSpotKey is a stuck-spot bucket's key in stuckSpotsMemory.
+func stuckSpotKey(bx, by int) string {
+	return strconv.Itoa(bx) + "," + strconv.Itoa(by)
+}
+
+// updateUnitHistory records where each ground unit with orders is. Idle
+// units, aircraft, harvesters (tracked separately), units jammed at a
+// factory exit (handled by the exit watchdog) and escorts (which stand
+// still whenever their charge does) are not watched.
+func updateUnitHistory(env RuleEnv) {
+	hist := getUnitHistory(env.Memory)
+	exits := getExitWatches(env.Memory)
+	seen := make(map[int]bool)
+	for _, u := range env.State.Units {
+		if u.Idle || isAircraft(u) || matchesType(u.Type, Harvester) || exits[u.ID] != nil || isEscort(env.Memory, u.ID) {
+			continue
+		}
+		seen[u.ID] = true
+		h := hist[u.ID]
+		if h == nil {
+			hist[u.ID] = &unitHistory{X: u.X, Y: u.Y, Since: env.State.Tick}
+			continue
+		}
+		if h.X != u.X || h.Y != u.Y {
+			// Moving freely again: any earlier nudges worked.
+			if math.Hypot(float64(u.X-h.X), float64(u.Y-h.Y)) > stuckNudgeDistance {
+				h.Nudges = 0
+			}
+			h.X, h.Y, h.Since = u.X, u.Y, env.State.Tick
+		}
+	}
+	for id := range hist {
+		if !seen[id] {
+			delete(hist, id)
+		}
+	}
+	unitPositionsMemory.set(env.Memory, hist)
+}
+
+// StuckUnits returns IDs of units that have had orders but not moved for
+// stuckTicks, excluding ones engaging a nearby enemy (firing in place).
+func (e RuleEnv) StuckUnits() []int {
+	var out []int
+	for id, h := range getUnitHistory(e.Memory) {
+		if e.State.Tick-h.Since < stuckTicks || e.enemyWithin(h.X, h.Y, stuckEngageRadius) {
+			continue
+		}
+		out = append(out, id)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (e RuleEnv) enemyWithin(x, y int, radius float64) bool {
+	for _, en := range e.State.Enemies {
+		if math.Hypot(float64(en.X-x), float64(en.Y-y)) <= radius {
+			return true
+		}
+	}
+	return false
+}
+
+// recordStuckSpot counts a stuck event in the bucket containing (x, y).
+func recordStuckSpot(memory map[string]any, x, y int) {
+	spots := getStuckSpots(memory)
+	spots[stuckSpotKey(x/stuckSpotGrid, y/stuckSpotGrid)]++
+	stuckSpotsMemory.set(memory, spots)
+}
+
+// ChronicStuckSpots returns the centre cells of buckets where units have
+// repeatedly got stuck — chokepoints that building placement should avoid.
+func (e RuleEnv) ChronicStuckSpots() [][2]int {
+	var out [][2]int
+	for k, n := range getStuckSpots(e.Memory) {
+		if n < chronicStuckCount {
+			continue
+		}
+		xs, ys, _ := strings.Cut(k, ",")
+		bx, errX := strconv.Atoi(xs)
+		by, errY := strconv.Atoi(ys)
+		if errX != nil || errY != nil {
+			continue
+		}
+		out = append(out, [2]int{bx*stuckSpotGrid + stuckSpotGrid/2, by*stuckSpotGrid + stuckSpotGrid/2})
+	}
+	slices.SortFunc(out, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	return out
+}
+
+// onStuckSpot reports whether a building of type item with its top-left at
+// (x, y) would cover a chronic stuck spot.
+func (e RuleEnv) onStuckSpot(item string, x, y int) bool {
+	w, h := footprintOf(item)
+	for _, s := range e.ChronicStuckSpots() {
+		if s[0] >= x-1 && s[0] <= x+w && s[1] >= y-1 && s[1] <= y+h {
+			return true
+		}
+	}
+	return false
+}
+
+// stuckRecoveryTarget picks where to send a stuck unit: a short sidestep
+// that rotates direction with each attempt, or — after repeated failures —
+// a re-path back to the base centroid, which is known to be reachable.
+func (e RuleEnv) stuckRecoveryTarget(u model.Unit, h *unitHistory) (x, y int, repath bool) {
+	if h.Nudges >= stuckRepathAfter && len(e.State.Buildings) > 0 {
+		x, y = e.BuildingCentroid()
+		return x, y, true
+	}
+	angle := float64(u.ID+h.Nudges) * math.Pi / 4
+	x = u.X + int(math.Round(stuckNudgeDistance*math.Cos(angle)))
+	y = u.Y + int(math.Round(stuckNudgeDistance*math.Sin(angle)))
+	if e.State.MapWidth > 0 && e.State.MapHeight > 0 {
+		x = max(0, min(x, e.State.MapWidth-1))
+		y = max(0, min(y, e.State.MapHeight-1))
+	}
+	return x, y, false
+}
@@ -0,0 +1,72 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestStuckUnitsDetection(t *testing.T) {
+	mem := make(map[string]any)
+	env := func(tick int, enemies ...model.Enemy) RuleEnv {
+		return RuleEnv{
+			State: model.GameState{
+				Tick: tick,
+				Units: []model.Unit{
+					{ID: 1, Type: "2tnk", X: 40, Y: 40},
+					{ID: 2, Type: "1tnk", X: 60, Y: 60, Idle: true},
+				},
+				Enemies: enemies,
+			},
+			Memory: mem,
+		}
+	}
+
+	updateUnitHistory(env(0))
+	updateUnitHistory(env(stuckTicks))
+	if got := env(stuckTicks).StuckUnits(); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("StuckUnits = %v, want [1]", got)
+	}
+
+	// A unit standing still next to an enemy is fighting, not stuck.
+	fighting := env(stuckTicks, model.Enemy{ID: 99, Type: "e1", X: 43, Y: 40})
+	if got := fighting.StuckUnits(); len(got) != 0 {
+		t.Errorf("engaged unit reported stuck: %v", got)
+	}
+}
+
+func TestStuckRecoveryEscalatesAndMarksSpot(t *testing.T) {
+	mem := make(map[string]any)
+	e := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 10, Type: "fact", X: 10, Y: 10}},
+		},
+		Memory: mem,
+	}
+	u := model.Unit{ID: 1, Type: "2tnk", X: 40, Y: 40}
+	h := &unitHistory{X: 40, Y: 40}
+
+	if x, y, repath := e.stuckRecoveryTarget(u, h); repath || (x == 40 && y == 40) {
+		t.Errorf("first recovery = (%d, %d, repath=%v), want a sidestep", x, y, repath)
+	}
+	h.Nudges = stuckRepathAfter
+	if x, y, repath := e.stuckRecoveryTarget(u, h); !repath || x != 10 || y != 10 {
+		t.Errorf("escalated recovery = (%d, %d, repath=%v), want re-path to (10, 10)", x, y, repath)
+	}
+
+	for range chronicStuckCount {
+		recordStuckSpot(mem, 41, 42)
+	}
+	spots := e.ChronicStuckSpots()
+	if len(spots) != 1 || spots[0] != [2]int{42, 42} {
+		t.Fatalf("ChronicStuckSpots = %v, want [[42 42]]", spots)
+	}
+	if !e.onStuckSpot("powr", 41, 41) {
+		t.Error("power plant over a chronic stuck spot should be avoided")
+	}
+	if x, y := e.clearOfExits("powr", 41, 41); e.onStuckSpot("powr", x, y) {
+		t.Errorf("clearOfExits returned stuck spot (%d, %d)", x, y)
+	}
+}
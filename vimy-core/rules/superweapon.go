@@ -0,0 +1,150 @@
+package rules
+
+import (
+	"math"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Enemy nuke timing, from RA mod defaults. Used only when the sidecar can't
+// see the enemy's support power timer and we must infer it from silo sightings.
+const (
+	nukeChargeTicks  = 13500 // missile silo charge interval
+	nukeWarningTicks = 750   // start evading this long before estimated readiness
+	nukeSpreadRadius = 6     // cells — roughly the nuke's lethal radius
+	siloSightRadius  = 6     // cells — a unit this close would see a standing building
+)
+
+// enemySilo remembers an enemy missile silo sighting. FirstSeen anchors the
+// inferred charge cycle; the real cycle may have started earlier, so the
+// estimate errs toward warning late rather than never.
+type enemySilo struct {
+	Owner     string
+	X, Y      int
+	FirstSeen int
+	LastSeen  int
+}
+
+func getEnemySilos(memory map[string]any) map[int]*enemySilo {
+	if v, ok := enemySilosMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*enemySilo)
+}
+
+// updateEnemySilos records visible enemy missile silos and forgets ones whose
+// site we can see but which are no longer there (destroyed or sold).
+func updateEnemySilos(env RuleEnv) {
+	silos := getEnemySilos(env.Memory)
+	visible := make(map[int]bool)
+	for _, en := range env.State.Enemies {
+		if !matchesType(en.Type, MissileSilo) {
+			continue
+		}
+		visible[en.ID] = true
+		if s, ok := silos[en.ID]; ok {
+			s.LastSeen = env.State.Tick
+			continue
+		}
+		silos[en.ID] = &enemySilo{Owner: en.Owner, X: en.X, Y: en.Y, FirstSeen: env.State.Tick, LastSeen: env.State.Tick}
+	}
+
+	for id, s := range silos {
+		if !visible[id] && siteObserved(env, s.X, s.Y) {
+			delete(silos, id)
+		}
+	}
+	enemySilosMemory.set(env.Memory, silos)
+}
+
+// EnemyNukeTicks estimates ticks until an enemy nuke is ready: exact when
+// the sidecar exposes the enemy timer, otherwise inferred from when we first
+// saw each silo. Returns -1 when no enemy nuke is known.
+func (e RuleEnv) EnemyNukeTicks() int {
+	best := -1
+	for _, sp := range e.State.EnemySupportPowers {
+		if !strings.Contains(strings.ToLower(sp.Key), "nuke") {
+			continue
+		}
+		if best < 0 || sp.RemainingTicks < best {
+			best = sp.RemainingTicks
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+
+	for _, s := range getEnemySilos(e.Memory) {
+		elapsed := e.State.Tick - s.FirstSeen
+		remaining := nukeChargeTicks - elapsed%nukeChargeTicks
+		if best < 0 || remaining < best {
+			best = remaining
+		}
+	}
+	return best
+}
+
+// EnemyNukeImminent is true when an enemy nuke is (estimated) ready or
+// within the warning window.
+func (e RuleEnv) EnemyNukeImminent() bool {
+	t := e.EnemyNukeTicks()
+	return t >= 0 && t <= nukeWarningTicks
+}
+
+// KnownEnemySilo returns the remembered enemy silo nearest our base, or nil.
+func (e RuleEnv) KnownEnemySilo() *enemySilo {
+	bx, by := e.BuildingCentroid()
+	var nearest *enemySilo
+	bestDist := math.MaxFloat64
+	for _, s := range getEnemySilos(e.Memory) {
+		if d := math.Hypot(float64(s.X-bx), float64(s.Y-by)); d < bestDist {
+			bestDist = d
+			nearest = s
+		}
+	}
+	return nearest
+}
+
+// HasKnownEnemySilo reports whether any enemy missile silo is remembered.
+func (e RuleEnv) HasKnownEnemySilo() bool { return len(getEnemySilos(e.Memory)) > 0 }
+
+// ClusteredSquads returns squads whose members sit close enough together for
+// one nuke to take them all out.
+func (e RuleEnv) ClusteredSquads() []*Squad {
+	pos := e.units().ByID()
+	var out []*Squad
+	for _, sq := range getSquads(e.Memory) {
+		if len(sq.UnitIDs) < 2 {
+			continue
+		}
+		cx, cy, n := squadCentroid(sq, pos)
+		if n < 2 {
+			continue
+		}
+		spread := 0.0
+		for _, id := range sq.UnitIDs {
+			if u, ok := pos[id]; ok {
+				spread += math.Hypot(float64(u.X-cx), float64(u.Y-cy))
+			}
+		}
+		if spread/float64(n) < nukeSpreadRadius/2 {
+			out = append(out, sq)
+		}
+	}
+	return out
+}
+
+func squadCentroid(sq *Squad, pos map[int]model.Unit) (x, y, n int) {
+	for _, id := range sq.UnitIDs {
+		if u, ok := pos[id]; ok {
+			x += u.X
+			y += u.Y
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return x / n, y / n, n
+}
@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestEnemyNukeInferredFromSilo(t *testing.T) {
+	mem := make(map[string]any)
+	silo := model.Enemy{ID: 50, Owner: "Enemy", Type: "mslo", X: 100, Y: 100, HP: 100, MaxHP: 100}
+	updateEnemySilos(RuleEnv{State: model.GameState{Tick: 1000, Enemies: []model.Enemy{silo}}, Memory: mem})
+
+	env := RuleEnv{State: model.GameState{Tick: 1000 + nukeChargeTicks - nukeWarningTicks - 1}, Memory: mem}
+	if env.EnemyNukeImminent() {
+		t.Error("nuke should not be imminent before the warning window")
+	}
+	env.State.Tick += 2
+	if !env.EnemyNukeImminent() {
+		t.Errorf("nuke should be imminent inside the warning window (eta %d)", env.EnemyNukeTicks())
+	}
+
+	// A unit standing on the silo site without seeing it means it's gone.
+	updateEnemySilos(RuleEnv{State: model.GameState{Tick: 2000, Units: []model.Unit{{ID: 1, Type: "3tnk", X: 101, Y: 100}}}, Memory: mem})
+	if got := (RuleEnv{Memory: mem}).EnemyNukeTicks(); got != -1 {
+		t.Errorf("EnemyNukeTicks() after silo cleared = %d, want -1", got)
+	}
+}
+
+func TestEnemyNukePrefersVisibleTimer(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			EnemySupportPowers: []model.EnemySupportPower{{Owner: "Enemy", Key: "NukePowerInfoOrder", RemainingTicks: 300}},
+		},
+		Memory: make(map[string]any),
+	}
+	if got := env.EnemyNukeTicks(); got != 300 {
+		t.Errorf("EnemyNukeTicks() = %d, want 300", got)
+	}
+}
+
+func TestSiloOverridesGroundTarget(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 0, Y: 0}},
+			Enemies: []model.Enemy{
+				{ID: 10, Type: "tsla", X: 5, Y: 0, HP: 100, MaxHP: 100},
+				{ID: 11, Type: "mslo", X: 40, Y: 0, HP: 100, MaxHP: 100},
+			},
+			EnemySupportPowers: []model.EnemySupportPower{{Key: "NukePowerInfoOrder", RemainingTicks: 100}},
+		},
+		Memory: make(map[string]any),
+	}
+	updateEnemySilos(env)
+	updatePriorityTargets(env)
+	if got := env.BestGroundTarget(); got == nil || got.ID != 11 {
+		t.Errorf("expected silo 11 to override targeting, got %+v", got)
+	}
+}
+
+func TestClusteredSquads(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Units: []model.Unit{
+				{ID: 1, X: 10, Y: 10}, {ID: 2, X: 11, Y: 10}, {ID: 3, X: 10, Y: 11},
+				{ID: 4, X: 50, Y: 50}, {ID: 5, X: 70, Y: 50},
+			},
+		},
+		Memory: map[string]any{
+			"squads": map[string]*Squad{
+				"tight": {Name: "tight", UnitIDs: []int{1, 2, 3}},
+				"loose": {Name: "loose", UnitIDs: []int{4, 5}},
+			},
+		},
+	}
+	got := env.ClusteredSquads()
+	if len(got) != 1 || got[0].Name != "tight" {
+		t.Errorf("expected only the tight squad, got %v", got)
+	}
+}
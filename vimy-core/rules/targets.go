@@ -0,0 +1,148 @@
+package rules
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// forwardYardRadiusPct is how close (as a fraction of the map diagonal) an
+// enemy construction yard must be to our base to count as a forward base.
+const forwardYardRadiusPct = 0.30
+
+// PriorityTarget pins an enemy as the preferred target for ground and air
+// attacks, overriding BestGroundTarget/BestAirTarget scoring. A target with
+// EnemyID tracks one actor and is dropped once it's destroyed; a target with
+// only Type is a standing order matching any visible enemy of that type and
+// stays until cleared.
+type PriorityTarget struct {
+	EnemyID int    `json:"enemy_id,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Reason  string `json:"reason"`
+	Tick    int    `json:"tick"`
+	LastX   int    `json:"last_x,omitempty"` // last known position of an EnemyID target
+	LastY   int    `json:"last_y,omitempty"`
+}
+
+func getPriorityTargets(memory map[string]any) []PriorityTarget {
+	if v, ok := priorityTargetsMemory.get(memory); ok {
+		return v
+	}
+	return nil
+}
+
+// GetPriorityTargets is the public accessor (used by the dashboard).
+func GetPriorityTargets(memory map[string]any) []PriorityTarget {
+	return getPriorityTargets(memory)
+}
+
+// addPriorityTarget appends t unless an equivalent target is already set.
+func addPriorityTarget(memory map[string]any, t PriorityTarget) bool {
+	targets := getPriorityTargets(memory)
+	for _, existing := range targets {
+		if existing.EnemyID == t.EnemyID && existing.Type == t.Type {
+			return false
+		}
+	}
+	priorityTargetsMemory.set(memory, append(targets, t))
+	return true
+}
+
+// updatePriorityTargets refreshes positions of pinned actors, drops ones we
+// can see are gone, and pins intel-driven targets: enemy silos when their
+// nuke is imminent, and enemy construction yards planted near our base.
+func updatePriorityTargets(env RuleEnv) {
+	visible := make(map[int]*model.Enemy, len(env.State.Enemies))
+	for i := range env.State.Enemies {
+		visible[env.State.Enemies[i].ID] = &env.State.Enemies[i]
+	}
+
+	if env.EnemyNukeImminent() {
+		for id, s := range getEnemySilos(env.Memory) {
+			if addPriorityTarget(env.Memory, PriorityTarget{EnemyID: id, Type: MissileSilo, Reason: "enemy nuke imminent", Tick: env.State.Tick, LastX: s.X, LastY: s.Y}) {
+				slog.Info("priority target set", "enemy", id, "reason", "enemy nuke imminent")
+			}
+		}
+	}
+
+	pinSuperweapons(env)
+
+	if len(env.State.Buildings) > 0 {
+		bx, by := env.BuildingCentroid()
+		mw, mh := float64(env.State.MapWidth), float64(env.State.MapHeight)
+		radius := math.Sqrt(mw*mw+mh*mh) * forwardYardRadiusPct
+		for _, en := range env.State.Enemies {
+			if !matchesType(en.Type, ConstructionYard) || math.Hypot(float64(en.X-bx), float64(en.Y-by)) > radius {
+				continue
+			}
+			if addPriorityTarget(env.Memory, PriorityTarget{EnemyID: en.ID, Type: ConstructionYard, Reason: "forward construction yard", Tick: env.State.Tick, LastX: en.X, LastY: en.Y}) {
+				slog.Info("priority target set", "enemy", en.ID, "reason", "forward construction yard")
+			}
+		}
+	}
+
+	targets := getPriorityTargets(env.Memory)
+	kept := targets[:0]
+	for _, t := range targets {
+		if t.EnemyID != 0 {
+			if en, ok := visible[t.EnemyID]; ok {
+				t.LastX, t.LastY = en.X, en.Y
+			} else if siteObserved(env, t.LastX, t.LastY) {
+				slog.Info("priority target cleared", "enemy", t.EnemyID, "reason", t.Reason)
+				continue
+			}
+		}
+		kept = append(kept, t)
+	}
+	if len(kept) == 0 {
+		priorityTargetsMemory.clear(env.Memory)
+		return
+	}
+	priorityTargetsMemory.set(env.Memory, kept)
+}
+
+// siteObserved reports whether one of our units is close enough to (x, y)
+// that a building standing there would be visible.
+func siteObserved(env RuleEnv, x, y int) bool {
+	for _, u := range env.State.Units {
+		if math.Hypot(float64(u.X-x), float64(u.Y-y)) <= siloSightRadius {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityTargetOverride returns the first pinned target that is currently
+// visible, in the order targets were set. Type-based targets pick the
+// matching enemy nearest our base.
+func (e RuleEnv) priorityTargetOverride() *model.Enemy {
+	bx, by := e.BuildingCentroid()
+	for _, t := range getPriorityTargets(e.Memory) {
+		var best *model.Enemy
+		bestDist := math.MaxFloat64
+		for i := range e.State.Enemies {
+			en := &e.State.Enemies[i]
+			if t.EnemyID != 0 {
+				if en.ID == t.EnemyID {
+					return en
+				}
+				continue
+			}
+			if !matchesType(en.Type, t.Type) {
+				continue
+			}
+			if d := math.Hypot(float64(en.X-bx), float64(en.Y-by)); d < bestDist {
+				bestDist = d
+				best = en
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// HasPriorityTarget reports whether any priority target is set.
+func (e RuleEnv) HasPriorityTarget() bool { return len(getPriorityTargets(e.Memory)) > 0 }
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestPriorityTargetByType(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 0, Y: 0}},
+			Enemies: []model.Enemy{
+				{ID: 10, Type: "tsla", X: 5, Y: 0, HP: 100, MaxHP: 100},
+				{ID: 11, Type: "dome", X: 60, Y: 0, HP: 100, MaxHP: 100},
+				{ID: 12, Type: "dome", X: 40, Y: 0, HP: 100, MaxHP: 100},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	if got := env.BestGroundTarget(); got == nil || got.ID != 10 {
+		t.Fatalf("without override expected tesla coil 10, got %+v", got)
+	}
+
+	addPriorityTarget(env.Memory, PriorityTarget{Type: "dome", Reason: "test"})
+	if got := env.BestGroundTarget(); got == nil || got.ID != 12 {
+		t.Errorf("expected nearest dome 12, got %+v", got)
+	}
+	if got := env.BestAirTarget(); got == nil || got.ID != 12 {
+		t.Errorf("air: expected nearest dome 12, got %+v", got)
+	}
+}
+
+func TestPriorityTargetClearedWhenDestroyed(t *testing.T) {
+	mem := make(map[string]any)
+	addPriorityTarget(mem, PriorityTarget{EnemyID: 50, Reason: "test", LastX: 100, LastY: 100})
+
+	// Not visible and nobody nearby — keep it.
+	updatePriorityTargets(RuleEnv{State: model.GameState{Units: []model.Unit{{ID: 1, X: 10, Y: 10}}}, Memory: mem})
+	if len(getPriorityTargets(mem)) != 1 {
+		t.Fatal("target should persist while its site is unobserved")
+	}
+
+	// Our unit is on the site and it's gone — destroyed.
+	updatePriorityTargets(RuleEnv{State: model.GameState{Units: []model.Unit{{ID: 1, X: 100, Y: 101}}}, Memory: mem})
+	if len(getPriorityTargets(mem)) != 0 {
+		t.Error("target should be cleared once its site is observed empty")
+	}
+}
+
+func TestForwardConstructionYardPinned(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  100,
+			MapHeight: 100,
+			Buildings: []model.Building{{ID: 1, Type: "fact", X: 10, Y: 10}},
+			Enemies: []model.Enemy{
+				{ID: 20, Type: "fact", X: 25, Y: 25, HP: 100, MaxHP: 100}, // forward
+				{ID: 21, Type: "fact", X: 90, Y: 90, HP: 100, MaxHP: 100}, // home base
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	updatePriorityTargets(env)
+	targets := getPriorityTargets(env.Memory)
+	if len(targets) != 1 || targets[0].EnemyID != 20 {
+		t.Errorf("expected forward yard 20 pinned, got %+v", targets)
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 // testConn creates a *ipc.Connection backed by a pipe. The returned cleanup
 // function closes both ends. Sent messages are consumed by the reader goroutine.
-func testConn(t *testing.T) (*ipc.Connection, func()) {
+func testConn(t testing.TB) (*ipc.Connection, func()) {
 	t.Helper()
 	server, client := net.Pipe()
 	conn := ipc.NewConnection(server, nil)
@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// Per-unit debug lines. Actions like retreat, harvester dispatch and focus
+// fire can log a line for every unit they touch, and many of them touch
+// the same units tick after tick; in a big game that buries everything else
+// at debug level. unitDebug samples them: a line goes out the first time a
+// rule logs a message for an actor, then at most once every
+// unitLogInterval ticks, and what was held back is summed per rule and
+// message and logged once an interval.
+
+// Sampling tuning.
+const (
+	unitLogInterval = 250                 // ticks between lines for one rule, message and actor; also the summary period
+	unitLogForget   = 4 * unitLogInterval // an actor not logged for this long is forgotten
+)
+
+type unitLogKey struct {
+	rule  string
+	msg   string
+	actor int
+}
+
+type unitLogEntry struct {
+	last       int // tick of the last line logged
+	seen       int // tick of the last line logged or held back
+	suppressed int // lines held back since the last summary
+}
+
+// unitLogSampler holds the sampling state for one engine. Guarded by the
+// engine's memMu: it is only touched during Evaluate and ResetMemory.
+type unitLogSampler struct {
+	entries   map[unitLogKey]*unitLogEntry
+	lastFlush int
+}
+
+func newUnitLogSampler() *unitLogSampler {
+	return &unitLogSampler{entries: make(map[unitLogKey]*unitLogEntry)}
+}
+
+// allow reports whether the line for key should be logged at tick, and
+// counts it towards the next summary if not.
+func (s *unitLogSampler) allow(key unitLogKey, tick int) bool {
+	en, ok := s.entries[key]
+	if !ok {
+		s.entries[key] = &unitLogEntry{last: tick, seen: tick}
+		return true
+	}
+	en.seen = tick
+	if tick-en.last >= unitLogInterval || tick < en.last {
+		en.last = tick
+		return true
+	}
+	en.suppressed++
+	return false
+}
+
+// flush logs one summary per rule and message for lines held back since the
+// last one, and forgets actors that have gone quiet. It runs at most once
+// every unitLogInterval ticks.
+func (s *unitLogSampler) flush(tick int) {
+	if tick < s.lastFlush {
+		s.reset() // a new game
+	}
+	if tick-s.lastFlush < unitLogInterval {
+		return
+	}
+	s.lastFlush = tick
+
+	type summary struct {
+		rule, msg string
+	}
+	held := make(map[summary][2]int) // lines, actors
+	for key, en := range s.entries {
+		if en.suppressed > 0 {
+			sum := summary{key.rule, key.msg}
+			h := held[sum]
+			held[sum] = [2]int{h[0] + en.suppressed, h[1] + 1}
+			en.suppressed = 0
+		}
+		if tick-en.seen >= unitLogForget {
+			delete(s.entries, key)
+		}
+	}
+	sums := slices.SortedFunc(maps.Keys(held), func(a, b summary) int {
+		return cmp.Or(cmp.Compare(a.rule, b.rule), cmp.Compare(a.msg, b.msg))
+	})
+	for _, sum := range sums {
+		h := held[sum]
+		slog.Debug("unit log lines sampled", "rule", sum.rule, "msg", sum.msg, "suppressed", h[0], "actors", h[1], "tick", tick)
+	}
+}
+
+func (s *unitLogSampler) reset() {
+	clear(s.entries)
+	s.lastFlush = 0
+}
+
+// unitDebug logs a per-unit debug line for actor, sampled by the rule
+// being run and msg. Outside Evaluate (tests) every line is logged.
+func (e RuleEnv) unitDebug(actor int, msg string, args ...any) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	if e.unitLogs != nil {
+		rule := ""
+		if e.rule != nil {
+			rule = e.rule.Name
+		}
+		if !e.unitLogs.allow(unitLogKey{rule: rule, msg: msg, actor: actor}, e.State.Tick) {
+			return
+		}
+	}
+	slog.Debug(msg, args...)
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestUnitDebugSamplesPerRuleAndActor(t *testing.T) {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	engine, err := NewEngine([]*Rule{{Name: "nudge", Priority: 1, Category: "test", ConditionSrc: "true", Action: func(env RuleEnv, conn *ipc.Connection) error {
+		env.unitDebug(1, "nudging unit", "id", 1)
+		env.unitDebug(2, "nudging unit", "id", 2)
+		return nil
+	}}})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	for tick := 1; tick <= 300; tick++ {
+		if err := engine.Evaluate(model.GameState{Tick: tick}, "soviet", conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lines, summaries []string
+	for _, l := range strings.Split(buf.String(), "\n") {
+		switch {
+		case strings.Contains(l, "unit log lines sampled"):
+			summaries = append(summaries, l)
+		case strings.Contains(l, `msg="nudging unit"`):
+			lines = append(lines, l)
+		}
+	}
+	// Each actor logs at tick 1 and again at tick 251.
+	if len(lines) != 4 {
+		t.Errorf("logged %d lines, want 4:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	// Ticks 2-250 were held back for both actors and summed at tick 250.
+	if len(summaries) != 1 || !strings.Contains(summaries[0], "rule=nudge") || !strings.Contains(summaries[0], "suppressed=498 actors=2 tick=250") {
+		t.Errorf("summaries = %q, want one for nudge with 498 lines from 2 actors", summaries)
+	}
+}
+
+func TestUnitLogSamplerForgetsQuietActorsAndNewGames(t *testing.T) {
+	s := newUnitLogSampler()
+	key := unitLogKey{rule: "r", msg: "m", actor: 7}
+	if !s.allow(key, 100) || s.allow(key, 101) {
+		t.Fatal("want the first line logged and the next held back")
+	}
+	s.flush(101 + unitLogForget)
+	if len(s.entries) != 0 {
+		t.Errorf("entries = %d after %d quiet ticks, want the actor forgotten", len(s.entries), unitLogForget)
+	}
+	s.allow(key, 5000)
+	s.flush(10) // the tick went backwards: a new game
+	if len(s.entries) != 0 || s.lastFlush != 0 {
+		t.Errorf("sampler kept %d entries and lastFlush %d across games", len(s.entries), s.lastFlush)
+	}
+	if !s.allow(key, 20) {
+		t.Error("first line of a new game held back")
+	}
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// UnitInfo is one actor's stats from the RA mod rules.
+type UnitInfo struct {
+	Cost      int     `json:"cost"`       // credits
+	BuildTime int     `json:"build_time"` // ticks; 0 derives it from Cost
+	Speed     int     `json:"speed"`      // OpenRA movement speed units; 0 for structures
+	Range     float64 `json:"range"`      // primary weapon range in cells; 0 if unarmed
+	Armor     string  `json:"armor"`      // none, light, heavy, wood or concrete
+	Target    string  `json:"target"`     // infantry, vehicle, air, ship or structure
+}
+
+// buildDurationModifier is OpenRA's default Buildable.BuildDurationModifier:
+// an actor without an explicit build time takes 60% of its cost in ticks.
+const buildDurationModifier = 60
+
+//go:embed unitdata.json
+var unitDataJSON []byte
+
+// unitData maps actor type to its stats, loaded from the embedded table.
+var unitData = mustLoadUnitData(unitDataJSON)
+
+func mustLoadUnitData(data []byte) map[string]UnitInfo {
+	var m map[string]UnitInfo
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic(fmt.Sprintf("rules: bad embedded unit data: %v", err))
+	}
+	for t, u := range m {
+		if u.BuildTime == 0 {
+			u.BuildTime = u.Cost * buildDurationModifier / 100
+			m[t] = u
+		}
+	}
+	return m
+}
+
+// LookupUnit returns the stats for an actor type, honouring faction-suffixed
+// variants ("afld.ukraine" finds "afld").
+func LookupUnit(t string) (UnitInfo, bool) {
+	t = strings.ToLower(t)
+	if u, ok := unitData[t]; ok {
+		return u, true
+	}
+	if dot := strings.IndexByte(t, '.'); dot > 0 {
+		u, ok := unitData[t[:dot]]
+		return u, ok
+	}
+	return UnitInfo{}, false
+}
+
+// costOf returns an actor type's cost, or 0 if unlisted.
+func costOf(t string) int {
+	u, _ := LookupUnit(t)
+	return u.Cost
+}
+
+// roleUnit returns the stats of a role's cheapest listed variant — the one
+// a cash check has to cover at minimum.
+func roleUnit(role string) (UnitInfo, bool) {
+	var best UnitInfo
+	found := false
+	for _, t := range roles[role].types {
+		if u, ok := LookupUnit(t); ok && (!found || u.Cost < best.Cost) {
+			best, found = u, true
+		}
+	}
+	return best, found
+}
+
+// roleCost returns the cost of a role's cheapest variant, or 0 if none of
+// its types are listed.
+func roleCost(role string) int {
+	u, _ := roleUnit(role)
+	return u.Cost
+}
+
+// RoleCost returns what the given role costs to build, e.g. RoleCost("tech_center").
+func (e RuleEnv) RoleCost(role string) int { return roleCost(role) }
+
+// TimeToBuild estimates the seconds until a unit of the given role could be
+// finished if queued now. OpenRA deducts cost progressively, so that's its
+// build time or the time to earn any shortfall at the current income rate,
+// whichever is longer. Returns -1 for unknown roles, or when we can't afford
+// it and have no income.
+func (e RuleEnv) TimeToBuild(role string) int {
+	u, ok := roleUnit(role)
+	if !ok {
+		return -1
+	}
+	secs := float64(u.BuildTime) / ticksPerSecond
+	if short := u.Cost - e.Cash(); short > 0 {
+		rate := e.IncomeRate()
+		if rate <= 0 {
+			return -1
+		}
+		secs = math.Max(secs, float64(short)/rate)
+	}
+	return int(math.Ceil(secs))
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func TestUnitDataCoversEveryRole(t *testing.T) {
+	for name, r := range roles {
+		for _, typ := range r.types {
+			u, ok := LookupUnit(typ)
+			if !ok {
+				t.Errorf("role %q type %q has no unit data", name, typ)
+				continue
+			}
+			if u.Cost <= 0 || u.BuildTime <= 0 {
+				t.Errorf("%s: cost %d, build time %d; want both positive", typ, u.Cost, u.BuildTime)
+			}
+		}
+	}
+}
+
+func TestLookupUnit(t *testing.T) {
+	u, ok := LookupUnit("AFLD.ukraine")
+	if !ok || u.Cost != 500 || u.Target != "structure" {
+		t.Errorf("LookupUnit(faction variant) = %+v, %v", u, ok)
+	}
+	if u.BuildTime != 500*buildDurationModifier/100 {
+		t.Errorf("derived build time = %d, want %d", u.BuildTime, 500*buildDurationModifier/100)
+	}
+	if _, ok := LookupUnit("nosuchunit"); ok {
+		t.Error("expected unknown type to be missing")
+	}
+	// A role costs its cheapest variant.
+	if got := roleCost("medium_tank"); got != costOf(MediumTank) {
+		t.Errorf("roleCost(medium_tank) = %d, want the cheaper 2tnk's %d", got, costOf(MediumTank))
+	}
+}
+
+func TestTimeToBuild(t *testing.T) {
+	tech, _ := roleUnit("tech_center")
+	buildSecs := (tech.BuildTime + ticksPerSecond - 1) / ticksPerSecond
+
+	rich := RuleEnv{State: model.GameState{Player: model.Player{Cash: 5000}}, Memory: map[string]any{}}
+	if got := rich.TimeToBuild("tech_center"); got != buildSecs {
+		t.Errorf("affordable TimeToBuild = %d, want build time %d", got, buildSecs)
+	}
+
+	broke := RuleEnv{State: model.GameState{}, Memory: map[string]any{}}
+	if got := broke.TimeToBuild("tech_center"); got != -1 {
+		t.Errorf("TimeToBuild with no cash or income = %d, want -1", got)
+	}
+	if got := rich.TimeToBuild("no_such_role"); got != -1 {
+		t.Errorf("TimeToBuild(unknown role) = %d, want -1", got)
+	}
+
+	// Broke but mining: the shortfall takes longer to earn than the build.
+	mining := harvesterEnv(0, map[string]any{}, model.Unit{ID: 7, Type: "harv", X: 56, Y: 50})
+	rate := mining.IncomeRate()
+	if got, earn := mining.TimeToBuild("tech_center"), int(float64(tech.Cost)/rate); got < earn || got < buildSecs {
+		t.Errorf("TimeToBuild while mining = %d, want at least %d (income) and %d (build)", got, earn, buildSecs)
+	}
+}
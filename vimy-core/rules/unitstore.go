@@ -0,0 +1,299 @@
+package rules
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// UnitStore indexes our units by ID, type, role, domain, idle state and
+// squad, so rules look them up rather than scanning State.Units each time.
+// The engine keeps one across ticks: the mod sends whole states, and Update
+// diffs each against the store so only units that appeared, died, or
+// changed type or idle state touch the indexes. Squad membership follows
+// squad memory: Update indexes it at the start of the tick and setSquads
+// re-indexes it whenever a rule changes the squads.
+//
+// Scans that need every unit anyway, such as proximity checks and per-tick
+// snapshots, still range over State.Units.
+//
+// Lists are returned in State.Units order, as the scans they replace did,
+// so seeded choices over them stay reproducible. Each is sorted once a tick
+// and shared by every rule that asks for it, so callers must not modify
+// them.
+type UnitStore struct {
+	mod   *Mod
+	units map[int]*storedUnit
+	byID  map[int]model.Unit // this tick's units, for callers that look up many IDs
+
+	listMu sync.Mutex              // conditions may run on several workers at once
+	lists  map[string][]model.Unit // sorted lists, by index and key; cleared by Update
+
+	byType   map[string]map[int]bool // lower-cased type and each dotted prefix of it, as matchesType matches
+	byRole   map[string]map[int]bool
+	byDomain map[string]map[int]bool
+	idle     map[int]bool
+	bySquad  map[string][]int
+	squadOf  map[int]string
+}
+
+// storedUnit is a unit with what the indexes derived from it.
+type storedUnit struct {
+	model.Unit
+	pos    int // index in the last State.Units
+	keys   []string
+	roles  []string
+	domain string
+}
+
+// NewUnitStore returns an empty store for the mod's roles; nil is RA.
+func NewUnitStore(m *Mod) *UnitStore {
+	s := &UnitStore{mod: m}
+	s.reset()
+	return s
+}
+
+func (s *UnitStore) reset() {
+	s.units = make(map[int]*storedUnit)
+	s.byID = make(map[int]model.Unit)
+	s.lists = make(map[string][]model.Unit)
+	s.byType = make(map[string]map[int]bool)
+	s.byRole = make(map[string]map[int]bool)
+	s.byDomain = make(map[string]map[int]bool)
+	s.idle = make(map[int]bool)
+	s.bySquad = make(map[string][]int)
+	s.squadOf = make(map[int]string)
+}
+
+// Update brings the store in line with units, the State.Units of a new
+// tick, and squads, the squad memory. A change of mod re-derives every
+// unit's roles.
+func (s *UnitStore) Update(units []model.Unit, squads map[string]*Squad, m *Mod) {
+	if m != s.mod {
+		s.mod = m
+		s.reset()
+	}
+	clear(s.lists)
+	clear(s.byID)
+	for i, u := range units {
+		s.byID[u.ID] = u
+		old, ok := s.units[u.ID]
+		switch {
+		case !ok:
+			s.add(u, i)
+		case old.Type != u.Type:
+			s.remove(old)
+			s.add(u, i)
+		default:
+			if old.Idle != u.Idle {
+				setMember(s.idle, u.ID, u.Idle)
+			}
+			old.Unit, old.pos = u, i
+		}
+	}
+	for id, su := range s.units {
+		if _, ok := s.byID[id]; !ok {
+			s.remove(su)
+		}
+	}
+	s.indexSquads(squads)
+}
+
+func (s *UnitStore) add(u model.Unit, pos int) {
+	su := &storedUnit{Unit: u, pos: pos, keys: typeKeys(u.Type), domain: unitDomain(u)}
+	mod := s.mod
+	if mod == nil {
+		mod = RA
+	}
+	for name, r := range mod.roles {
+		if slices.ContainsFunc(r.types, func(t string) bool { return matchesType(u.Type, t) }) {
+			su.roles = append(su.roles, name)
+		}
+	}
+	s.units[u.ID] = su
+	for _, k := range su.keys {
+		addMember(s.byType, k, u.ID)
+	}
+	for _, r := range su.roles {
+		addMember(s.byRole, r, u.ID)
+	}
+	addMember(s.byDomain, su.domain, u.ID)
+	setMember(s.idle, u.ID, u.Idle)
+}
+
+func (s *UnitStore) remove(su *storedUnit) {
+	for _, k := range su.keys {
+		removeMember(s.byType, k, su.ID)
+	}
+	for _, r := range su.roles {
+		removeMember(s.byRole, r, su.ID)
+	}
+	removeMember(s.byDomain, su.domain, su.ID)
+	delete(s.idle, su.ID)
+	delete(s.units, su.ID)
+}
+
+// indexSquads rebuilds the squad index from squad memory, keeping only
+// members the store holds.
+func (s *UnitStore) indexSquads(squads map[string]*Squad) {
+	clear(s.bySquad)
+	clear(s.squadOf)
+	for name, sq := range squads {
+		for _, id := range sq.UnitIDs {
+			if _, ok := s.units[id]; ok {
+				s.bySquad[name] = append(s.bySquad[name], id)
+				s.squadOf[id] = name
+			}
+		}
+	}
+}
+
+// setSquads stores the squads in memory and re-indexes the unit store's
+// squad membership, so lookups later in the tick see the change.
+func (e RuleEnv) setSquads(squads map[string]*Squad) {
+	squadsMemory.set(e.Memory, squads)
+	if e.Units != nil {
+		e.Units.indexSquads(squads)
+	}
+}
+
+// typeKeys returns the index keys of a unit type: the lower-cased name and
+// each prefix ending before a dot, so "3tnk.soviet" is found as "3tnk".
+func typeKeys(t string) []string {
+	t = strings.ToLower(t)
+	keys := []string{t}
+	for i := range len(t) {
+		if t[i] == '.' {
+			keys = append(keys, t[:i])
+		}
+	}
+	return keys
+}
+
+// unitDomain classifies one of our units as ground, air or naval.
+func unitDomain(u model.Unit) string {
+	switch {
+	case isAircraft(u):
+		return domainAir
+	case isNaval(u):
+		return domainNaval
+	}
+	return domainGround
+}
+
+func addMember(index map[string]map[int]bool, key string, id int) {
+	set, ok := index[key]
+	if !ok {
+		set = make(map[int]bool)
+		index[key] = set
+	}
+	set[id] = true
+}
+
+func removeMember(index map[string]map[int]bool, key string, id int) {
+	delete(index[key], id)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+func setMember(set map[int]bool, id int, in bool) {
+	if in {
+		set[id] = true
+	} else {
+		delete(set, id)
+	}
+}
+
+// list returns the units with the given IDs in State.Units order, sorting
+// them the first time key is asked for this tick.
+func (s *UnitStore) list(key string, ids map[int]bool) []model.Unit {
+	if len(ids) == 0 {
+		return nil
+	}
+	s.listMu.Lock()
+	defer s.listMu.Unlock()
+	if out, ok := s.lists[key]; ok {
+		return out
+	}
+	found := make([]*storedUnit, 0, len(ids))
+	for id := range ids {
+		found = append(found, s.units[id])
+	}
+	slices.SortFunc(found, func(a, b *storedUnit) int { return a.pos - b.pos })
+	out := make([]model.Unit, len(found))
+	for i, su := range found {
+		out[i] = su.Unit
+	}
+	s.lists[key] = slices.Clip(out)
+	return s.lists[key]
+}
+
+// Len returns how many units we have.
+func (s *UnitStore) Len() int { return len(s.units) }
+
+// Get returns our unit with the given ID.
+func (s *UnitStore) Get(id int) (model.Unit, bool) {
+	if su, ok := s.units[id]; ok {
+		return su.Unit, true
+	}
+	return model.Unit{}, false
+}
+
+// ByID returns our units by ID. The map is shared; don't modify it.
+func (s *UnitStore) ByID() map[int]model.Unit { return s.byID }
+
+// Has reports whether we have a unit with the given ID.
+func (s *UnitStore) Has(id int) bool {
+	_, ok := s.units[id]
+	return ok
+}
+
+// OfType returns our units of type t, faction variants included.
+func (s *UnitStore) OfType(t string) []model.Unit {
+	return s.list("type:"+strings.ToLower(t), s.byType[strings.ToLower(t)])
+}
+
+// TypeCount counts our units of type t, faction variants included.
+func (s *UnitStore) TypeCount(t string) int { return len(s.byType[strings.ToLower(t)]) }
+
+// InRole returns our units filling the named role.
+func (s *UnitStore) InRole(role string) []model.Unit { return s.list("role:"+role, s.byRole[role]) }
+
+// RoleCount counts our units filling the named role.
+func (s *UnitStore) RoleCount(role string) int { return len(s.byRole[role]) }
+
+// InDomain returns our "ground", "air" or "naval" units.
+func (s *UnitStore) InDomain(domain string) []model.Unit {
+	return s.list("domain:"+domain, s.byDomain[domain])
+}
+
+// DomainCount counts our "ground", "air" or "naval" units.
+func (s *UnitStore) DomainCount(domain string) int { return len(s.byDomain[domain]) }
+
+// Idle returns our idle units.
+func (s *UnitStore) Idle() []model.Unit { return s.list("idle", s.idle) }
+
+// IdleCount counts our idle units.
+func (s *UnitStore) IdleCount() int { return len(s.idle) }
+
+// IsIdle reports whether our unit with the given ID is idle.
+func (s *UnitStore) IsIdle(id int) bool { return s.idle[id] }
+
+// InSquad returns the living members of the named squad, in squad order.
+func (s *UnitStore) InSquad(name string) []model.Unit {
+	ids := s.bySquad[name]
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]model.Unit, len(ids))
+	for i, id := range ids {
+		out[i] = s.units[id].Unit
+	}
+	return out
+}
+
+// SquadOf returns the squad our unit belongs to, or "".
+func (s *UnitStore) SquadOf(id int) string { return s.squadOf[id] }
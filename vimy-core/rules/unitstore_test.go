@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+func unitIDs(units []model.Unit) []int {
+	ids := make([]int, len(units))
+	for i, u := range units {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestUnitStoreUpdatesIncrementally(t *testing.T) {
+	s := NewUnitStore(nil)
+	squads := map[string]*Squad{"ground-attack": {Name: "ground-attack", UnitIDs: []int{2, 1, 99}}}
+	s.Update([]model.Unit{
+		{ID: 1, Type: "3tnk", Idle: true},
+		{ID: 2, Type: "3tnk.soviet", Idle: false},
+		{ID: 3, Type: "mig", Idle: true},
+		{ID: 4, Type: "e6", Idle: true},
+	}, squads, nil)
+
+	if s.Len() != 4 || !s.Has(3) || s.Has(99) {
+		t.Fatalf("store holds %d units, want 1-4", s.Len())
+	}
+	if got := unitIDs(s.OfType("3tnk")); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("OfType(3tnk) = %v, want faction variant included [1 2]", got)
+	}
+	if s.TypeCount("3TNK.SOVIET") != 1 {
+		t.Errorf("TypeCount(3TNK.SOVIET) = %d, want 1", s.TypeCount("3TNK.SOVIET"))
+	}
+	if got := unitIDs(s.InRole("medium_tank")); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("InRole(medium_tank) = %v, want [1 2]", got)
+	}
+	if s.DomainCount(domainAir) != 1 || s.DomainCount(domainGround) != 3 {
+		t.Errorf("domains air=%d ground=%d, want 1 and 3", s.DomainCount(domainAir), s.DomainCount(domainGround))
+	}
+	if got := unitIDs(s.Idle()); !slices.Equal(got, []int{1, 3, 4}) {
+		t.Errorf("Idle() = %v, want [1 3 4]", got)
+	}
+	if got := unitIDs(s.InSquad("ground-attack")); !slices.Equal(got, []int{2, 1}) || s.SquadOf(1) != "ground-attack" {
+		t.Errorf("InSquad = %v, SquadOf(1) = %q; want living members in squad order", got, s.SquadOf(1))
+	}
+
+	// Next tick: 1 dies, 2 goes idle, the engineer is replaced by a rifleman
+	// reusing its ID, and a new unit arrives ahead of the rest.
+	s.Update([]model.Unit{
+		{ID: 5, Type: "1tnk", Idle: true},
+		{ID: 2, Type: "3tnk.soviet", Idle: true, X: 10},
+		{ID: 3, Type: "mig", Idle: true},
+		{ID: 4, Type: "e1", Idle: true},
+	}, squads, nil)
+
+	if s.Has(1) || s.TypeCount("3tnk") != 1 || s.RoleCount("engineer") != 0 || s.TypeCount("e1") != 1 {
+		t.Errorf("store not updated: has 1=%v, 3tnk=%d, engineers=%d, e1=%d", s.Has(1), s.TypeCount("3tnk"), s.RoleCount("engineer"), s.TypeCount("e1"))
+	}
+	if u, _ := s.Get(2); u.X != 10 {
+		t.Errorf("unit 2 X = %d, want the new position 10", u.X)
+	}
+	if got := unitIDs(s.Idle()); !slices.Equal(got, []int{5, 2, 3, 4}) {
+		t.Errorf("Idle() = %v, want state order [5 2 3 4]", got)
+	}
+	if a, b := s.Idle(), s.Idle(); &a[0] != &b[0] {
+		t.Error("Idle() sorted twice in one tick, want the list cached")
+	}
+	if tanks := s.OfType("3tnk"); len(tanks) != 1 || tanks[0].X != 10 || s.ByID()[2].X != 10 {
+		t.Errorf("OfType(3tnk) = %+v after the update, want this tick's unit 2", tanks)
+	}
+	if got := unitIDs(s.InSquad("ground-attack")); !slices.Equal(got, []int{2}) {
+		t.Errorf("InSquad = %v, want the survivor [2]", got)
+	}
+}
+
+func TestUnitStoreFollowsSquadChangesWithinTick(t *testing.T) {
+	var seen []int
+	rules := []*Rule{
+		{Name: "form", Priority: 100, Category: "squad_form", ConditionSrc: "true", Action: FormSquad("ground-attack", "ground", 3, "attack")},
+		{
+			Name: "look", Priority: 50, Category: "test", ConditionSrc: "true",
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				seen = unitIDs(env.Units.InSquad("ground-attack"))
+				return nil
+			},
+		},
+		{
+			Name: "count", Priority: 10, Category: "test2", ConditionSrc: `Units.RoleCount("medium_tank") == 3 && Units.IdleCount() == 4`,
+			Action: func(env RuleEnv, conn *ipc.Connection) error {
+				seen = append(seen, -1)
+				return nil
+			},
+		},
+	}
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	gs := model.GameState{
+		Tick:      1,
+		Buildings: []model.Building{{ID: 10, Type: "fact", X: 20, Y: 20}},
+		Units: []model.Unit{
+			{ID: 1, Type: "3tnk", X: 22, Y: 22, HP: 100, MaxHP: 100, Idle: true},
+			{ID: 2, Type: "3tnk", X: 23, Y: 22, HP: 100, MaxHP: 100, Idle: true},
+			{ID: 3, Type: "3tnk", X: 24, Y: 22, HP: 100, MaxHP: 100, Idle: true},
+			{ID: 4, Type: "e1", X: 25, Y: 22, HP: 100, MaxHP: 100, Idle: true},
+		},
+	}
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	if err := engine.Evaluate(gs, "soviet", conn); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(seen, []int{1, 2, 3, -1}) {
+		t.Errorf("saw %v, want the squad formed earlier in the tick [1 2 3] and the count rule firing", seen)
+	}
+}
@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Enemy movement. Each visible enemy unit is followed by ID from sighting to
+// sighting, and its velocity is a smoothed estimate of the displacement
+// between them. A squad sent at a moving force aims where the force will be
+// by the time the squad gets there, or at the building of ours it is
+// heading for, rather than at where it was last seen.
+
+const (
+	trackStaleTicks      = 100         // a track unseen this long is dropped, and a sighting after such a gap restarts it
+	trackSmoothing       = 0.4         // weight of the newest displacement in the velocity estimate
+	movingSpeed          = 0.02        // cells per tick under which a force counts as standing still
+	interceptForceRadius = 8.0         // enemies this close to the target move with it as one force
+	headingCone          = math.Pi / 6 // a building within this angle of a force's heading is where it is going
+	maxLeadTicks         = 750         // 30s; past this a prediction is a guess
+	wdistPerCell         = 1024.0      // UnitInfo.Speed is in WDist per tick
+	defaultSquadSpeed    = 0.07        // cells per tick, a heavy tank, when no member's speed is known
+)
+
+// enemyTrack is an enemy unit's last sighting and its estimated velocity in
+// cells per tick.
+type enemyTrack struct {
+	X, Y    int
+	Tick    int
+	VX, VY  float64
+	Samples int // displacements folded into VX/VY
+}
+
+func getEnemyTracks(memory map[string]any) map[int]*enemyTrack {
+	if v, ok := enemyTracksMemory.get(memory); ok {
+		return v
+	}
+	return make(map[int]*enemyTrack)
+}
+
+// updateEnemyTracks folds this tick's enemy sightings into their tracks.
+// Structures don't move and are not tracked.
+func updateEnemyTracks(env RuleEnv) {
+	tracks := getEnemyTracks(env.Memory)
+	tick := env.State.Tick
+
+	for _, en := range env.State.Enemies {
+		if IsKnownBuildingType(en.Type) || isDefenseType(en.Type) {
+			continue
+		}
+		t := tracks[en.ID]
+		switch {
+		case t != nil && t.Tick == tick:
+			continue
+		case t == nil || tick < t.Tick || tick-t.Tick > trackStaleTicks:
+			// New, or seen again after a gap or a rewind: nothing to
+			// measure against.
+			tracks[en.ID] = &enemyTrack{X: en.X, Y: en.Y, Tick: tick}
+			continue
+		}
+		dt := float64(tick - t.Tick)
+		vx, vy := float64(en.X-t.X)/dt, float64(en.Y-t.Y)/dt
+		if t.Samples == 0 {
+			t.VX, t.VY = vx, vy
+		} else {
+			t.VX = (1-trackSmoothing)*t.VX + trackSmoothing*vx
+			t.VY = (1-trackSmoothing)*t.VY + trackSmoothing*vy
+		}
+		t.Samples++
+		t.X, t.Y, t.Tick = en.X, en.Y, tick
+	}
+
+	for id, t := range tracks {
+		if tick-t.Tick > trackStaleTicks || tick < t.Tick {
+			delete(tracks, id)
+		}
+	}
+	enemyTracksMemory.set(env.Memory, tracks)
+}
+
+// EnemyVelocity returns the estimated velocity, in cells per tick, of the
+// enemy unit with the given ID. ok is false until the unit has been seen on
+// two ticks, and once it drops out of sight.
+func (e RuleEnv) EnemyVelocity(id int) (vx, vy float64, ok bool) {
+	t := getEnemyTracks(e.Memory)[id]
+	if t == nil || t.Samples == 0 || t.Tick != e.State.Tick {
+		return 0, 0, false
+	}
+	return t.VX, t.VY, true
+}
+
+// forceVelocity returns the mean velocity of the tracked enemies within
+// interceptForceRadius of (x, y): the force a target at (x, y) moves with.
+func (e RuleEnv) forceVelocity(x, y int) (vx, vy float64, ok bool) {
+	n := 0
+	for _, en := range e.State.Enemies {
+		if math.Hypot(float64(en.X-x), float64(en.Y-y)) > interceptForceRadius {
+			continue
+		}
+		if evx, evy, tracked := e.EnemyVelocity(en.ID); tracked {
+			vx += evx
+			vy += evy
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	return vx / float64(n), vy / float64(n), true
+}
+
+// forceDestination returns the nearest of our buildings within headingCone
+// of a force at (x, y) moving along (vx, vy).
+func (e RuleEnv) forceDestination(x, y int, vx, vy float64) (model.Building, bool) {
+	heading := math.Atan2(vy, vx)
+	var best model.Building
+	bestDist := math.MaxFloat64
+	for _, b := range e.State.Buildings {
+		dx, dy := float64(b.X-x), float64(b.Y-y)
+		d := math.Hypot(dx, dy)
+		if d == 0 || d >= bestDist {
+			continue
+		}
+		off := math.Abs(math.Remainder(math.Atan2(dy, dx)-heading, 2*math.Pi))
+		if off <= headingCone {
+			best, bestDist = b, d
+		}
+	}
+	return best, bestDist < math.MaxFloat64
+}
+
+// squadSpeed returns the speed of the squad's slowest member in cells per
+// tick, which is the speed the squad arrives at.
+func (e RuleEnv) squadSpeed(name string) float64 {
+	speed := math.MaxFloat64
+	for _, u := range e.units().InSquad(name) {
+		if info, ok := LookupUnit(u.Type); ok && info.Speed > 0 {
+			speed = math.Min(speed, float64(info.Speed)/wdistPerCell)
+		}
+	}
+	if speed == math.MaxFloat64 {
+		return defaultSquadSpeed
+	}
+	return speed
+}
+
+// interceptPoint returns where a squad at (fromX, fromY) moving at speed
+// cells per tick should head to meet the enemy at target. A target whose
+// force stands still, or can't be tracked, is met where it is. A moving one
+// is led by the squad's travel time, refined over a few passes since the
+// lead changes the distance; if the lead carries it past the building of
+// ours it is heading for, the squad meets it at that building instead.
+func (e RuleEnv) interceptPoint(target model.Enemy, fromX, fromY int, speed float64) (x, y int) {
+	vx, vy, ok := e.forceVelocity(target.X, target.Y)
+	if !ok || math.Hypot(vx, vy) < movingSpeed || speed <= 0 {
+		return target.X, target.Y
+	}
+	px, py := float64(target.X), float64(target.Y)
+	for range 3 {
+		lead := math.Min(math.Hypot(px-float64(fromX), py-float64(fromY))/speed, maxLeadTicks)
+		px, py = float64(target.X)+vx*lead, float64(target.Y)+vy*lead
+	}
+	if dest, ok := e.forceDestination(target.X, target.Y, vx, vy); ok {
+		toDest := math.Hypot(float64(dest.X-target.X), float64(dest.Y-target.Y))
+		if math.Hypot(px-float64(target.X), py-float64(target.Y)) >= toDest {
+			return dest.X, dest.Y
+		}
+	}
+	x, y = int(math.Round(px)), int(math.Round(py))
+	if w, h := e.State.MapWidth, e.State.MapHeight; w > 0 && h > 0 {
+		x, y = min(max(x, 0), w-1), min(max(y, 0), h-1)
+	}
+	return x, y
+}
+
+// squadIntercept returns where the named squad should attack-move to meet
+// target: its intercept point from the squad's position, or the target's
+// position when the squad has none.
+func (e RuleEnv) squadIntercept(name string, target model.Enemy) (x, y int) {
+	sx, sy, ok := e.squadPosition(name)
+	if !ok {
+		return target.X, target.Y
+	}
+	return e.interceptPoint(target, sx, sy, e.squadSpeed(name))
+}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// advance moves each enemy by (dx, dy) cells, steps the tick by dt and
+// folds the sighting into the tracks.
+func advance(env *RuleEnv, dt, dx, dy int) {
+	env.State.Tick += dt
+	for i := range env.State.Enemies {
+		env.State.Enemies[i].X += dx
+		env.State.Enemies[i].Y += dy
+	}
+	updateEnemyTracks(*env)
+}
+
+func TestEnemyVelocityFromSightings(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			Enemies: []model.Enemy{
+				{ID: 1, Type: "3tnk", X: 10, Y: 10},
+				{ID: 2, Type: "powr", X: 50, Y: 50},
+			},
+		},
+		Memory: make(map[string]any),
+	}
+	updateEnemyTracks(env)
+	if _, _, ok := env.EnemyVelocity(1); ok {
+		t.Fatal("velocity known from a single sighting")
+	}
+
+	for range 5 {
+		advance(&env, 10, 1, 0)
+	}
+	vx, vy, ok := env.EnemyVelocity(1)
+	if !ok || math.Abs(vx-0.1) > 1e-9 || vy != 0 {
+		t.Errorf("velocity = (%.3f, %.3f) ok %v, want (0.1, 0)", vx, vy, ok)
+	}
+	if _, ok := getEnemyTracks(env.Memory)[2]; ok {
+		t.Error("structure tracked")
+	}
+
+	// Out of sight: no estimate now, and the track is dropped once stale.
+	env.State.Enemies = nil
+	advance(&env, 10, 0, 0)
+	if _, _, ok := env.EnemyVelocity(1); ok {
+		t.Error("velocity reported for an enemy out of sight")
+	}
+	advance(&env, trackStaleTicks, 0, 0)
+	if len(getEnemyTracks(env.Memory)) != 0 {
+		t.Error("stale track kept")
+	}
+}
+
+func TestSquadAttackMoveInterceptsMovingForce(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{
+				{ID: 100, Type: "fact", X: 20, Y: 20},
+				{ID: 101, Type: "proc", X: 60, Y: 20},
+			},
+			Units: []model.Unit{
+				{ID: 1, Type: "3tnk", X: 20, Y: 40, Idle: true},
+				{ID: 2, Type: "3tnk", X: 21, Y: 40, Idle: true},
+			},
+			Enemies: []model.Enemy{
+				{ID: 50, Type: "2tnk", X: 100, Y: 20},
+				{ID: 51, Type: "2tnk", X: 101, Y: 21},
+			},
+		},
+		Memory: map[string]any{
+			"squads": map[string]*Squad{
+				"attack": {Name: "attack", Domain: "ground", Role: "attack", UnitIDs: []int{1, 2}},
+			},
+		},
+	}
+	updateEnemyTracks(env)
+	for range 3 {
+		advance(&env, 10, -1, 0) // heading west along y=20, toward the refinery
+	}
+
+	conn, cleanup := testConn(t)
+	defer cleanup()
+	if err := SquadAttackMove("attack")(env, conn); err != nil {
+		t.Fatal(err)
+	}
+	run := getAttackRuns(env.Memory)["attack"]
+	if run == nil {
+		t.Fatal("no attack run started")
+	}
+	if lead := env.State.Enemies[0].X; run.TargetX >= lead {
+		t.Errorf("aimed at x=%d, want ahead of the force at x=%d", run.TargetX, lead)
+	}
+	if run.TargetX < 60 || math.Abs(float64(run.TargetY-20)) > 2 {
+		t.Errorf("aimed at (%d, %d), want on the force's path no further than the refinery", run.TargetX, run.TargetY)
+	}
+}
+
+func TestInterceptPoint(t *testing.T) {
+	env := RuleEnv{
+		State: model.GameState{
+			MapWidth:  128,
+			MapHeight: 128,
+			Buildings: []model.Building{{ID: 100, Type: "proc", X: 30, Y: 20}},
+			Enemies:   []model.Enemy{{ID: 50, Type: "3tnk", X: 100, Y: 20}},
+		},
+		Memory: make(map[string]any),
+	}
+	updateEnemyTracks(env)
+
+	// Tracked but standing still: meet it where it is.
+	advance(&env, 10, 0, 0)
+	if x, y := env.interceptPoint(env.State.Enemies[0], 20, 60, defaultSquadSpeed); x != 100 || y != 20 {
+		t.Errorf("stationary target intercepted at (%d, %d), want (100, 20)", x, y)
+	}
+
+	// Moving fast toward the refinery from far away: the lead overshoots
+	// it, so the squad meets the force at the refinery.
+	for range 3 {
+		advance(&env, 10, -3, 0)
+	}
+	if x, y := env.interceptPoint(env.State.Enemies[0], 20, 120, defaultSquadSpeed); x != 30 || y != 20 {
+		t.Errorf("intercepted at (%d, %d), want the refinery (30, 20)", x, y)
+	}
+}
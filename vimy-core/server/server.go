@@ -7,19 +7,23 @@ import (
 
 	"github.com/a-h/templ"
 	"github.com/nstehr/vimy/vimy-core/agent"
+	"github.com/nstehr/vimy/vimy-core/ipc"
 	"github.com/nstehr/vimy/vimy-core/rules"
 	"github.com/nstehr/vimy/vimy-core/server/views"
 )
 
 // Server serves the doctrine dashboard over HTTP.
 type Server struct {
+	engine     *rules.Engine
 	strategist *agent.Strategist
 	mux        *http.ServeMux
 }
 
-// New creates a dashboard server backed by the given strategist.
-func New(strategist *agent.Strategist) *Server {
-	s := &Server{strategist: strategist}
+// New creates a dashboard server backed by the given rule engine and
+// strategist. The strategist may be nil when serving rules only; overrides,
+// groups and priority targets go straight to the engine and work either way.
+func New(engine *rules.Engine, strategist *agent.Strategist) *Server {
+	s := &Server{engine: engine, strategist: strategist}
 	s.mux = http.NewServeMux()
 	s.routes()
 	return s
@@ -36,8 +40,25 @@ func (s *Server) routes() {
 	s.mux.HandleFunc("PUT /api/directive", s.handleSetDirective)
 	s.mux.HandleFunc("GET /api/doctrine/current", s.handleCurrentDoctrine)
 	s.mux.HandleFunc("GET /api/doctrine/history", s.handleDoctrineHistory)
+	s.mux.HandleFunc("GET /api/doctrine/export", s.handleExportDoctrine)
+	s.mux.HandleFunc("GET /api/doctrine/tweaks", s.handleGetTweaks)
+	s.mux.HandleFunc("GET /api/report", s.handleReport)
+	s.mux.HandleFunc("PATCH /api/doctrine", s.handleTuneDoctrine)
 	s.mux.HandleFunc("GET /api/rules", s.handleRules)
+	s.mux.HandleFunc("GET /api/rules/errors", s.handleRuleErrors)
+	s.mux.HandleFunc("GET /api/rules/export", s.handleExportRules)
+	s.mux.HandleFunc("GET /api/production/idle", s.handleQueueIdle)
+	s.mux.HandleFunc("GET /api/commands", s.handleCommands)
+	s.mux.HandleFunc("GET /api/link", s.handleLink)
+	s.mux.HandleFunc("GET /api/budget", s.handleBudget)
 	s.mux.HandleFunc("GET /api/battlefield", s.handleBattlefield)
+	s.mux.HandleFunc("GET /api/overrides", s.handleGetOverrides)
+	s.mux.HandleFunc("PUT /api/overrides", s.handleSetOverrides)
+	s.mux.HandleFunc("GET /api/groups", s.handleGetGroups)
+	s.mux.HandleFunc("PUT /api/groups/{name}", s.handleSetGroup)
+	s.mux.HandleFunc("GET /api/targets", s.handleGetTargets)
+	s.mux.HandleFunc("POST /api/targets", s.handleAddTarget)
+	s.mux.HandleFunc("DELETE /api/targets", s.handleClearTargets)
 }
 
 func (s *Server) currentDirective() string {
@@ -84,6 +105,90 @@ func (s *Server) handleCurrentDoctrine(w http.ResponseWriter, r *http.Request) {
 	views.DoctrineCard(rec).Render(r.Context(), w)
 }
 
+// handleExportDoctrine downloads the current doctrine as a file the offline
+// tools' -doctrine flag reads back: JSON, or YAML with ?format=yaml.
+func (s *Server) handleExportDoctrine(w http.ResponseWriter, r *http.Request) {
+	if s.strategist == nil {
+		http.Error(w, "no strategist configured", http.StatusBadRequest)
+		return
+	}
+	rec := s.strategist.GetCurrentDoctrine()
+	if rec == nil {
+		http.Error(w, "no doctrine yet", http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("format") == "yaml" {
+		data, err := rules.MarshalDoctrineYAML(rec.Doctrine)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", `attachment; filename="doctrine.yaml"`)
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="doctrine.json"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(rec.Doctrine)
+}
+
+// handleReport serves the match report for the current game: the doctrine
+// timeline as JSON, or rendered as plain text with ?format=text.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if s.strategist == nil {
+		http.Error(w, "no strategist configured", http.StatusBadRequest)
+		return
+	}
+	report := s.strategist.Report()
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		report.WriteText(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (s *Server) handleGetTweaks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	tweaks := []agent.DoctrineTweak{}
+	if s.strategist != nil {
+		tweaks = s.strategist.GetTweaks()
+	}
+	json.NewEncoder(w).Encode(tweaks)
+}
+
+// handleTuneDoctrine adjusts doctrine weights live, e.g.
+// {"params": {"aggression": 0.9}, "hold_ticks": 1500}, and returns the
+// doctrine now in play.
+func (s *Server) handleTuneDoctrine(w http.ResponseWriter, r *http.Request) {
+	if s.strategist == nil {
+		http.Error(w, "no strategist configured", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Params    map[string]float64 `json:"params"`
+		HoldTicks int                `json:"hold_ticks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	doctrine, err := s.strategist.TuneDoctrine(req.Params, req.HoldTicks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	slog.Info("doctrine tuned via dashboard", "params", req.Params)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doctrine)
+}
+
 func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
 	var summaries []rules.RuleSummary
 	if s.strategist != nil {
@@ -92,6 +197,70 @@ func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
 	views.RulesPanel(summaries).Render(r.Context(), w)
 }
 
+// handleExportRules serves the active rule set as JSON for external
+// dashboards: one entry per rule, highest priority first.
+func (s *Server) handleExportRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	export := []rules.RuleExport{}
+	if s.strategist != nil {
+		export = s.strategist.ExportRules()
+	}
+	json.NewEncoder(w).Encode(export)
+}
+
+func (s *Server) handleRuleErrors(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	errs := []rules.RuleEvalError{}
+	if s.strategist != nil {
+		errs = s.strategist.GetEvalErrors()
+	}
+	json.NewEncoder(w).Encode(errs)
+}
+
+// handleLink reports the game connection's heartbeat: round-trip times,
+// command lag and whether the mod has gone silent. null before a game
+// connects.
+func (s *Server) handleLink(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var stats *ipc.HeartbeatStats
+	if s.strategist != nil {
+		stats = s.strategist.GetLinkStats()
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// handleBudget reports the strategist's LLM spend this game against its
+// caps. null without a strategist.
+func (s *Server) handleBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var status *agent.BudgetStatus
+	if s.strategist != nil {
+		b := s.strategist.GetBudget()
+		status = &b
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+func (s *Server) handleQueueIdle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	stats := []rules.QueueIdleStat{}
+	if s.strategist != nil {
+		stats = s.strategist.GetQueueIdleStats()
+	}
+	json.NewEncoder(w).Encode(stats)
+}
+
+// handleCommands serves the command audit: per-rule command counts and the
+// latest commands, each with the rule, condition and tick that issued it.
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	audit := rules.CommandAudit{Rules: []rules.RuleCommandStat{}, Recent: []ipc.CommandRecord{}}
+	if s.strategist != nil {
+		audit = s.strategist.GetCommandAudit()
+	}
+	json.NewEncoder(w).Encode(audit)
+}
+
 func (s *Server) handleBattlefield(w http.ResponseWriter, r *http.Request) {
 	var status *agent.BattlefieldStatus
 	if s.strategist != nil {
@@ -100,6 +269,89 @@ func (s *Server) handleBattlefield(w http.ResponseWriter, r *http.Request) {
 	views.BattlefieldPanel(status).Render(r.Context(), w)
 }
 
+func (s *Server) handleGetOverrides(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	overrides := rules.RuleOverrides{}
+	if o := s.engine.Overrides(); o != nil {
+		overrides = o
+	}
+	json.NewEncoder(w).Encode(overrides)
+}
+
+func (s *Server) handleSetOverrides(w http.ResponseWriter, r *http.Request) {
+	var overrides rules.RuleOverrides
+	if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.engine.SetOverrides(overrides); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	slog.Info("rule overrides updated via dashboard", "count", len(overrides))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
+
+func (s *Server) handleGetGroups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.engine.Groups())
+}
+
+func (s *Server) handleSetGroup(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := r.PathValue("name")
+	if err := s.engine.SetGroupEnabled(name, req.Enabled); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	slog.Info("rule group updated via dashboard", "group", name, "enabled", req.Enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.engine.Groups())
+}
+
+func (s *Server) handleGetTargets(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	targets := []rules.PriorityTarget{}
+	if t := s.engine.PriorityTargets(); t != nil {
+		targets = t
+	}
+	json.NewEncoder(w).Encode(targets)
+}
+
+func (s *Server) handleAddTarget(w http.ResponseWriter, r *http.Request) {
+	var target rules.PriorityTarget
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if target.EnemyID == 0 && target.Type == "" {
+		http.Error(w, "enemy_id or type is required", http.StatusBadRequest)
+		return
+	}
+	if target.Reason == "" {
+		target.Reason = "dashboard directive"
+	}
+	s.engine.SetPriorityTarget(target)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.engine.PriorityTargets())
+}
+
+func (s *Server) handleClearTargets(w http.ResponseWriter, r *http.Request) {
+	s.engine.ClearPriorityTargets()
+	slog.Info("priority targets cleared via dashboard")
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type historyPoint struct {
 	Tick                      int      `json:"tick"`
 	EconomyPriority           float64  `json:"economy_priority"`
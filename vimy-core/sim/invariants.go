@@ -0,0 +1,142 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+)
+
+// Invariant is a property every command must hold against the state it was
+// issued on. Check returns nil when cmd respects it. Invariants catch the
+// rule compiler and RuleEnv helpers disagreeing about the game: a rule that
+// fires on a condition its action doesn't re-check, or memory that outlives
+// the actors it names.
+type Invariant struct {
+	Name  string
+	Check func(gs model.GameState, cmd ipc.Envelope) error
+}
+
+// Violation is a command that broke an invariant.
+type Violation struct {
+	Tick      int
+	Invariant string
+	Command   string // message type
+	Detail    string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("tick %d: %s %s: %s", v.Tick, v.Command, v.Invariant, v.Detail)
+}
+
+// Invariants are the properties checked by default.
+var Invariants = []Invariant{ProduceBuildable, LiveActors}
+
+// ProduceBuildable requires a produce command to name an item a queue of
+// its type can currently build — the queue it picks, if it picks one.
+var ProduceBuildable = Invariant{
+	Name: "produce-buildable",
+	Check: func(gs model.GameState, cmd ipc.Envelope) error {
+		if cmd.Type != ipc.TypeProduce {
+			return nil
+		}
+		var p ipc.ProduceCommand
+		if err := json.Unmarshal(cmd.Data, &p); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+		for _, q := range gs.ProductionQueues {
+			if q.Type != p.Queue || (p.QueueActor != 0 && uint32(q.ActorID) != p.QueueActor) {
+				continue
+			}
+			if slices.Contains(q.Buildable, p.Item) {
+				return nil
+			}
+		}
+		if p.QueueActor != 0 {
+			return fmt.Errorf("%s queue %d cannot build %q", p.Queue, p.QueueActor, p.Item)
+		}
+		return fmt.Errorf("no %s queue can build %q", p.Queue, p.Item)
+	},
+}
+
+// actorRefs is every actor ID field a command may carry.
+type actorRefs struct {
+	ActorID          uint32   `json:"actor_id"`
+	ActorIDs         []uint32 `json:"actor_ids"`
+	TargetID         uint32   `json:"target_id"`
+	TransportID      uint32   `json:"transport_id"`
+	RepairBuildingID uint32   `json:"repair_building_id"`
+	QueueActor       uint32   `json:"queue_actor"`
+}
+
+func (r actorRefs) ids() []uint32 {
+	ids := append([]uint32{r.ActorID, r.TargetID, r.TransportID, r.RepairBuildingID, r.QueueActor}, r.ActorIDs...)
+	return slices.DeleteFunc(ids, func(id uint32) bool { return id == 0 })
+}
+
+// LiveActors requires every actor a command names to be in the state it
+// was issued on: ours, a visible enemy, a capturable or an obstacle. An ID
+// that isn't is dead or out of sight, and the game drops the order.
+var LiveActors = Invariant{
+	Name: "live-actors",
+	Check: func(gs model.GameState, cmd ipc.Envelope) error {
+		var refs actorRefs
+		if err := json.Unmarshal(cmd.Data, &refs); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+		var dead []uint32
+		for _, id := range refs.ids() {
+			if !liveActor(gs, int(id)) {
+				dead = append(dead, id)
+			}
+		}
+		if len(dead) > 0 {
+			return fmt.Errorf("actors %v are not in the game state", dead)
+		}
+		return nil
+	},
+}
+
+func liveActor(gs model.GameState, id int) bool {
+	for _, b := range gs.Buildings {
+		if b.ID == id {
+			return true
+		}
+	}
+	for _, u := range gs.Units {
+		if u.ID == id {
+			return true
+		}
+	}
+	for _, q := range gs.ProductionQueues {
+		if q.ActorID == id {
+			return true
+		}
+	}
+	for _, lists := range [][]model.Enemy{gs.Enemies, gs.Capturables} {
+		for _, en := range lists {
+			if en.ID == id {
+				return true
+			}
+		}
+	}
+	for _, o := range gs.Obstacles {
+		if o.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// check runs each invariant on a command issued at gs.
+func check(invariants []Invariant, gs model.GameState, cmd ipc.Envelope) []Violation {
+	var out []Violation
+	for _, inv := range invariants {
+		if err := inv.Check(gs, cmd); err != nil {
+			out = append(out, Violation{Tick: gs.Tick, Invariant: inv.Name, Command: cmd.Type, Detail: err.Error()})
+		}
+	}
+	return out
+}
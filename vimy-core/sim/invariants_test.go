@@ -0,0 +1,155 @@
+package sim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/fixtures"
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func envelope(t *testing.T, msgType string, data any) ipc.Envelope {
+	t.Helper()
+	env, err := ipc.NewEnvelope(msgType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+func TestProduceBuildable(t *testing.T) {
+	gs := fixtures.NewStateBuilder().
+		WithQueue("Vehicle", "3tnk", "harv").
+		WithQueue("Vehicle", "v2rl").
+		Build()
+	v2 := uint32(gs.ProductionQueues[1].ActorID)
+
+	for _, tc := range []struct {
+		cmd  ipc.ProduceCommand
+		want bool
+	}{
+		{ipc.ProduceCommand{Queue: "Vehicle", Item: "3tnk"}, true},
+		{ipc.ProduceCommand{Queue: "Vehicle", Item: "v2rl"}, true},
+		{ipc.ProduceCommand{Queue: "Vehicle", Item: "v2rl", QueueActor: v2}, true},
+		{ipc.ProduceCommand{Queue: "Vehicle", Item: "3tnk", QueueActor: v2}, false},
+		{ipc.ProduceCommand{Queue: "Vehicle", Item: "4tnk"}, false},
+		{ipc.ProduceCommand{Queue: "Infantry", Item: "3tnk"}, false},
+	} {
+		err := ProduceBuildable.Check(gs, envelope(t, ipc.TypeProduce, tc.cmd))
+		if (err == nil) != tc.want {
+			t.Errorf("%+v: err = %v, want ok %v", tc.cmd, err, tc.want)
+		}
+	}
+	if err := ProduceBuildable.Check(gs, envelope(t, ipc.TypeMove, ipc.MoveCommand{ActorID: 9})); err != nil {
+		t.Errorf("non-produce command checked: %v", err)
+	}
+}
+
+func TestLiveActors(t *testing.T) {
+	gs := fixtures.NewStateBuilder().
+		WithBuilding("fact", 10, 10).
+		WithUnit("e6", 12, 12, true).
+		WithEnemy("enemy", "3tnk", 40, 40).
+		Build()
+	fact, eng, enemy := uint32(gs.Buildings[0].ID), uint32(gs.Units[0].ID), uint32(gs.Enemies[0].ID)
+
+	for _, tc := range []struct {
+		name string
+		cmd  ipc.Envelope
+		want bool
+	}{
+		{"attack live enemy", envelope(t, ipc.TypeAttack, ipc.AttackCommand{ActorID: eng, TargetID: enemy}), true},
+		{"attack dead enemy", envelope(t, ipc.TypeAttack, ipc.AttackCommand{ActorID: eng, TargetID: 99}), false},
+		{"dead unit in group", envelope(t, ipc.TypeAttackMove, ipc.AttackMoveCommand{ActorIDs: []uint32{eng, 98}, X: 1, Y: 1}), false},
+		{"repair at our building", envelope(t, ipc.TypeRepairUnit, ipc.RepairUnitCommand{ActorID: eng, RepairBuildingID: fact}), true},
+		{"no actors", envelope(t, ipc.TypeProduce, ipc.ProduceCommand{Queue: "Building", Item: "powr"}), true},
+	} {
+		err := LiveActors.Check(gs, tc.cmd)
+		if (err == nil) != tc.want {
+			t.Errorf("%s: err = %v, want ok %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// invariantScenarios are replays whose states exercise production, combat
+// and losses: a fresh MCV, a standing army facing an enemy base, and the
+// same fight with units and enemies dying between ticks.
+func invariantScenarios() map[string][]model.GameState {
+	mcv := fixtures.NewStateBuilder().
+		WithUnit("mcv", 20, 20, true).
+		Ticks(3)
+
+	battle := func() *fixtures.StateBuilder {
+		return fixtures.NewStateBuilder().
+			WithBase().
+			WithCash(8000).
+			WithArmy(12, "3tnk").
+			WithArmyAt(6, "e1", 26, 20).
+			WithUnit("harv", 30, 30, true).
+			WithEnemyBase("enemy", 100, 100).
+			WithEnemyArmy("enemy", 6, "2tnk", 40, 40).
+			WithQueue("Building", "powr", "proc", "barr", "weap", "dome", "tsla", "ftur").
+			WithQueue("Defense", "tsla", "ftur", "sam").
+			WithQueue("Infantry", "e1", "e2", "e3").
+			WithQueue("Vehicle", "3tnk", "v2rl", "harv")
+	}
+	standing := battle().Ticks(8)
+
+	losses := battle().Ticks(8)
+	for i := range losses {
+		// Each tick a unit and an enemy fall, as in a running fight.
+		n := min(i, len(losses[i].Units))
+		losses[i].Units = losses[i].Units[n:]
+		m := min(i, len(losses[i].Enemies))
+		losses[i].Enemies = losses[i].Enemies[m:]
+	}
+
+	return map[string][]model.GameState{"mcv": mcv, "standing": standing, "losses": losses}
+}
+
+// TestRulesHoldInvariants replays the scenarios through the default rules
+// and a spread of compiled doctrines and fails on any invariant breach.
+func TestRulesHoldInvariants(t *testing.T) {
+	rush := rules.DefaultDoctrine()
+	rush.Name, rush.Aggression, rush.EconomyPriority, rush.GroundAttackGroupSize = "rush", 0.9, 0.2, 3
+	turtle := rules.DefaultDoctrine()
+	turtle.Name, turtle.Aggression, turtle.GroundDefensePriority, turtle.AirDefensePriority = "turtle", 0.1, 0.9, 0.7
+	tech := rules.DefaultDoctrine()
+	tech.Name, tech.TechPriority, tech.SuperweaponPriority, tech.SpecializedInfantryWeight, tech.CapturePriority = "tech", 0.9, 0.8, 0.6, 0.6
+
+	rulesets := map[string][]*rules.Rule{"default": rules.DefaultRules()}
+	for _, d := range []rules.Doctrine{rules.DefaultDoctrine(), rush, turtle, tech} {
+		rulesets[d.Name] = rules.CompileDoctrine(d)
+	}
+
+	for rsName, rs := range rulesets {
+		for scName, states := range invariantScenarios() {
+			t.Run(rsName+"/"+scName, func(t *testing.T) {
+				engine, err := rules.NewEngine(rs)
+				if err != nil {
+					t.Fatalf("NewEngine: %v", err)
+				}
+				engine.SetSeed(1)
+				report, err := Run(engine, "soviet", states, Invariants...)
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+				for _, v := range report.Violations {
+					t.Error(v)
+				}
+			})
+		}
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	v := Violation{Tick: 3, Invariant: "live-actors", Command: ipc.TypeAttack, Detail: "actors [9] are not in the game state"}
+	if got, want := v.String(), "tick 3: attack live-actors: actors [9] are not in the game state"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if _, err := json.Marshal(v); err != nil {
+		t.Errorf("Violation does not marshal: %v", err)
+	}
+}
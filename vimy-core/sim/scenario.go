@@ -0,0 +1,371 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/nstehr/vimy/vimy-core/fixtures"
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// Scenarios are end-to-end behavioral tests written as YAML: a synthetic
+// game built up step by step with fixtures.StateBuilder, and the commands
+// the engine must (or must not) send in answer. For example:
+//
+//	name: rush answered with a pillbox
+//	doctrine: {ground_defense_priority: 0.6}
+//	setup:
+//	  - base
+//	  - queue: {type: Defense, buildable: [pbox]}
+//	steps:
+//	  - tick: 900
+//	    do:
+//	      - enemy_army: {count: 6, type: e1, x: 24, y: 16}
+//	    expect:
+//	      - command: produce
+//	        with: {queue: Defense, item: pbox}
+//	        within: 5
+//
+// Each step sets the builder's tick, applies its actions on top of
+// everything before it, and has the engine evaluate consecutive ticks from
+// there — enough to cover its longest expectation, or Ticks of them.
+
+// Scenario is a scripted game and what the agent must do in it.
+type Scenario struct {
+	Name     string             `json:"name"`
+	Faction  string             `json:"faction,omitempty"` // default "soviet"
+	Seed     int64              `json:"seed,omitempty"`
+	Doctrine map[string]float64 `json:"doctrine,omitempty"` // DoctrineParam names over DefaultDoctrine
+	Setup    []ScenarioAction   `json:"setup,omitempty"`
+	Steps    []ScenarioStep     `json:"steps"`
+}
+
+// ScenarioStep is a change to the game at a tick and what must follow it.
+type ScenarioStep struct {
+	Tick   int                   `json:"tick"`
+	Ticks  int                   `json:"ticks,omitempty"` // states to evaluate; default covers every expectation
+	Do     []ScenarioAction      `json:"do,omitempty"`
+	Expect []ScenarioExpectation `json:"expect,omitempty"`
+}
+
+// ScenarioExpectation is a command the engine must send within Within
+// ticks of its step's tick (0: on that tick), or with Never, must not send
+// in that window. With lists fields the command's JSON must carry, e.g.
+// {item: pbox}.
+type ScenarioExpectation struct {
+	Command string         `json:"command"`
+	With    map[string]any `json:"with,omitempty"`
+	Within  int            `json:"within,omitempty"`
+	Never   bool           `json:"never,omitempty"`
+}
+
+func (x ScenarioExpectation) String() string {
+	s := x.Command
+	if len(x.With) > 0 {
+		parts := make([]string, 0, len(x.With))
+		for _, k := range slices.Sorted(maps.Keys(x.With)) {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, x.With[k]))
+		}
+		s += " {" + strings.Join(parts, " ") + "}"
+	}
+	return s
+}
+
+// ScenarioAction is one StateBuilder call. In YAML it is either a bare
+// action name ("base", "clear_enemies") or a mapping of the name to its
+// arguments ("unit: {type: e1, x: 10, y: 10}", "cash: 300").
+type ScenarioAction struct {
+	Kind string
+	Args ScenarioArgs
+}
+
+// ScenarioArgs are the arguments an action may take; each action reads
+// the ones it needs. Value holds a bare scalar argument, as in "cash: 300".
+type ScenarioArgs struct {
+	Type      string   `json:"type,omitempty"`
+	Owner     string   `json:"owner,omitempty"` // default "enemy"
+	X         int      `json:"x,omitempty"`
+	Y         int      `json:"y,omitempty"`
+	Count     int      `json:"count,omitempty"`
+	Idle      bool     `json:"idle,omitempty"`
+	Buildable []string `json:"buildable,omitempty"`
+	Width     int      `json:"width,omitempty"`
+	Height    int      `json:"height,omitempty"`
+	Provided  int      `json:"provided,omitempty"`
+	Drained   int      `json:"drained,omitempty"`
+	Key       string   `json:"key,omitempty"`
+	Ready     bool     `json:"ready,omitempty"`
+	Value     float64  `json:"-"`
+}
+
+// scenarioActions applies each action kind to a builder.
+var scenarioActions = map[string]func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder{
+	"base": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		if a.X != 0 || a.Y != 0 {
+			b.BaseAt(a.X, a.Y)
+		}
+		return b.WithBase()
+	},
+	"map": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithMap(a.Width, a.Height)
+	},
+	"cash": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder { return b.WithCash(int(a.Value)) },
+	"power": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithPower(a.Provided, a.Drained)
+	},
+	"building": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithBuilding(a.Type, a.X, a.Y)
+	},
+	"unit": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithUnit(a.Type, a.X, a.Y, a.Idle)
+	},
+	"army": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		if a.X == 0 && a.Y == 0 {
+			return b.WithArmy(a.Count, a.Type)
+		}
+		return b.WithArmyAt(a.Count, a.Type, a.X, a.Y)
+	},
+	"enemy_base": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithEnemyBase(a.Owner, a.X, a.Y)
+	},
+	"enemy": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithEnemy(a.Owner, a.Type, a.X, a.Y)
+	},
+	"enemy_army": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithEnemyArmy(a.Owner, a.Count, a.Type, a.X, a.Y)
+	},
+	"clear_enemies": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder { return b.WithoutEnemies() },
+	"queue": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithQueue(a.Type, a.Buildable...)
+	},
+	"support_power": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder {
+		return b.WithSupportPower(a.Key, a.Ready)
+	},
+	"damaged": func(b *fixtures.StateBuilder, a ScenarioArgs) *fixtures.StateBuilder { return b.Damaged(a.Value) },
+}
+
+// UnmarshalJSON reads an action as a bare name or a one-entry mapping.
+func (a *ScenarioAction) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*a = ScenarioAction{Kind: name}
+		return a.check()
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil || len(m) != 1 {
+		return fmt.Errorf("scenario action %s: want a name or a single \"name: arguments\" entry", data)
+	}
+	for kind, raw := range m {
+		*a = ScenarioAction{Kind: kind}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] != '{' {
+			if err := json.Unmarshal(raw, &a.Args.Value); err != nil {
+				return fmt.Errorf("scenario action %s: %w", kind, err)
+			}
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&a.Args); err != nil {
+			return fmt.Errorf("scenario action %s: %w", kind, err)
+		}
+	}
+	return a.check()
+}
+
+func (a *ScenarioAction) check() error {
+	if _, ok := scenarioActions[a.Kind]; !ok {
+		return fmt.Errorf("unknown scenario action %q (available: %s)", a.Kind, strings.Join(slices.Sorted(maps.Keys(scenarioActions)), ", "))
+	}
+	if a.Args.Owner == "" {
+		a.Args.Owner = "enemy"
+	}
+	return nil
+}
+
+func (a ScenarioAction) apply(b *fixtures.StateBuilder) {
+	scenarioActions[a.Kind](b, a.Args)
+}
+
+// window is how many ticks from the step's tick are evaluated.
+func (s ScenarioStep) window() int {
+	if s.Ticks > 0 {
+		return s.Ticks
+	}
+	n := 1
+	for _, x := range s.Expect {
+		n = max(n, x.Within+1)
+	}
+	return n
+}
+
+// ParseScenario reads a scenario from YAML (or JSON, which is YAML too)
+// and checks it is well formed.
+func ParseScenario(data []byte) (Scenario, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return Scenario{}, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return Scenario{}, err
+	}
+	var sc Scenario
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sc); err != nil {
+		return Scenario{}, err
+	}
+	if sc.Faction == "" {
+		sc.Faction = "soviet"
+	}
+	if len(sc.Steps) == 0 {
+		return Scenario{}, fmt.Errorf("scenario %q has no steps", sc.Name)
+	}
+	next := 1
+	for i, step := range sc.Steps {
+		if step.Tick < next {
+			return Scenario{}, fmt.Errorf("step %d at tick %d: ticks must follow the previous step's window (from tick %d)", i+1, step.Tick, next)
+		}
+		next = step.Tick + step.window()
+		for _, x := range step.Expect {
+			if x.Command == "" {
+				return Scenario{}, fmt.Errorf("step %d: expectation without a command", i+1)
+			}
+			if step.Ticks > 0 && x.Within >= step.Ticks {
+				return Scenario{}, fmt.Errorf("step %d: %s expected within %d ticks, but only %d are evaluated", i+1, x, x.Within, step.Ticks)
+			}
+		}
+	}
+	return sc, nil
+}
+
+// LoadScenario reads a scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("read scenario: %w", err)
+	}
+	sc, err := ParseScenario(data)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("parse scenario %s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// ScenarioFailure is an expectation the engine didn't meet.
+type ScenarioFailure struct {
+	Step   int // 1-based
+	Tick   int // the step's tick
+	Expect ScenarioExpectation
+	Detail string
+}
+
+func (f ScenarioFailure) String() string {
+	return fmt.Sprintf("step %d (tick %d): %s: %s", f.Step, f.Tick, f.Expect, f.Detail)
+}
+
+// RunScenario plays a scenario through an engine compiled from its
+// doctrine, checking every command against the default invariants, and
+// returns the replay report and the expectations that failed.
+func RunScenario(sc Scenario) (Report, []ScenarioFailure, error) {
+	d := rules.DefaultDoctrine()
+	for _, name := range slices.Sorted(maps.Keys(sc.Doctrine)) {
+		if err := d.SetParam(name, sc.Doctrine[name]); err != nil {
+			return Report{}, nil, err
+		}
+	}
+	engine, err := rules.NewEngine(rules.CompileDoctrine(d))
+	if err != nil {
+		return Report{}, nil, err
+	}
+	engine.SetSeed(sc.Seed)
+	engine.SetFaction(sc.Faction)
+
+	b := fixtures.NewStateBuilder()
+	for _, a := range sc.Setup {
+		a.apply(b)
+	}
+	gs := b.Build()
+	engine.SetTerrain(fixtures.LandTerrain(gs.MapWidth, gs.MapHeight))
+
+	var states []model.GameState
+	for _, step := range sc.Steps {
+		b.AtTick(step.Tick)
+		for _, a := range step.Do {
+			a.apply(b)
+		}
+		states = append(states, b.Ticks(step.window())...)
+	}
+	report, err := Run(engine, sc.Faction, states, Invariants...)
+	if err != nil {
+		return report, nil, err
+	}
+
+	var failures []ScenarioFailure
+	for i, step := range sc.Steps {
+		for _, x := range step.Expect {
+			if detail := x.check(report.Ticks, step.Tick); detail != "" {
+				failures = append(failures, ScenarioFailure{Step: i + 1, Tick: step.Tick, Expect: x, Detail: detail})
+			}
+		}
+	}
+	return report, failures, nil
+}
+
+// check looks for the expected command in the ticks from tick through
+// tick+Within, returning why the expectation failed or "" if it held.
+func (x ScenarioExpectation) check(ticks []TickResult, tick int) string {
+	var others []string
+	for _, t := range ticks {
+		if t.Tick < tick || t.Tick > tick+x.Within {
+			continue
+		}
+		for _, env := range t.Sent {
+			if env.Type != x.Command {
+				continue
+			}
+			if x.matches(env) {
+				if x.Never {
+					return fmt.Sprintf("sent at tick %d: %s", t.Tick, env.Data)
+				}
+				return ""
+			}
+			others = append(others, fmt.Sprintf("tick %d: %s", t.Tick, env.Data))
+		}
+	}
+	if x.Never {
+		return ""
+	}
+	detail := fmt.Sprintf("not sent in ticks %d-%d", tick, tick+x.Within)
+	if len(others) > 0 {
+		detail += "; sent instead: " + strings.Join(others, ", ")
+	}
+	return detail
+}
+
+// matches reports whether the command carries every field in With. Both
+// sides are compared as decoded JSON, so numbers are float64 on each.
+func (x ScenarioExpectation) matches(env ipc.Envelope) bool {
+	if len(x.With) == 0 {
+		return true
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(env.Data, &fields); err != nil {
+		return false
+	}
+	for k, want := range x.With {
+		if !reflect.DeepEqual(fields[k], want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestScenarios runs every scenario under testdata/scenarios.
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no scenarios found")
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".yaml"), func(t *testing.T) {
+			sc, err := LoadScenario(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, failures, err := RunScenario(sc)
+			if err != nil {
+				t.Fatalf("RunScenario: %v", err)
+			}
+			for _, f := range failures {
+				t.Error(f)
+			}
+			for _, v := range report.Violations {
+				t.Error("violation:", v)
+			}
+		})
+	}
+}
+
+func TestParseScenario(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+name: "parse: everything"   # quoted colon
+seed: 7
+doctrine:
+  aggression: 0.8
+setup:
+- base: {x: 30, y: 30}
+- cash: 250
+- army:
+    count: 4
+    type: 3tnk
+steps:
+  - tick: 100
+    ticks: 10
+    expect:
+      - {command: attack_move, within: 9}
+      - command: produce
+        with: {item: e1, count: 1}
+        never: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Name != "parse: everything" || sc.Seed != 7 || sc.Faction != "soviet" || sc.Doctrine["aggression"] != 0.8 {
+		t.Errorf("header = %q seed %d faction %q doctrine %v", sc.Name, sc.Seed, sc.Faction, sc.Doctrine)
+	}
+	if len(sc.Setup) != 3 {
+		t.Fatalf("setup has %d actions, want 3", len(sc.Setup))
+	}
+	if a := sc.Setup[0]; a.Kind != "base" || a.Args.X != 30 || a.Args.Y != 30 {
+		t.Errorf("base action = %+v", a)
+	}
+	if a := sc.Setup[1]; a.Kind != "cash" || a.Args.Value != 250 {
+		t.Errorf("cash action = %+v", a)
+	}
+	if a := sc.Setup[2]; a.Kind != "army" || a.Args.Count != 4 || a.Args.Type != "3tnk" {
+		t.Errorf("army action = %+v", a)
+	}
+	step := sc.Steps[0]
+	if step.Tick != 100 || step.window() != 10 || len(step.Expect) != 2 {
+		t.Fatalf("step = %+v", step)
+	}
+	if x := step.Expect[1]; !x.Never || x.With["item"] != "e1" || x.With["count"] != 1.0 {
+		t.Errorf("expectation = %+v", x)
+	}
+}
+
+func TestParseScenarioYAMLSyntax(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+"name": >
+  folded
+  name
+setup: [base, {cash: 300}]
+steps:
+  - tick: 5
+    expect:
+      - command: produce
+        with: {"item": 'e1'}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Name != "folded name\n" {
+		t.Errorf("name = %q, want the folded scalar", sc.Name)
+	}
+	if len(sc.Setup) != 2 || sc.Setup[0].Kind != "base" || sc.Setup[1].Args.Value != 300 {
+		t.Errorf("setup = %+v", sc.Setup)
+	}
+	if x := sc.Steps[0].Expect[0]; x.With["item"] != "e1" {
+		t.Errorf("expectation = %+v", x)
+	}
+}
+
+func TestParseScenarioRejects(t *testing.T) {
+	for name, src := range map[string]string{
+		"no steps":       "name: empty\n",
+		"unknown key":    "name: x\nstpes: []\n",
+		"unknown action": "steps:\n  - tick: 1\n    do: [teleport]\n",
+		"unknown arg":    "steps:\n  - tick: 1\n    do:\n      - unit: {kind: e1}\n",
+		"no command":     "steps:\n  - tick: 1\n    expect:\n      - within: 3\n",
+		"overlap":        "steps:\n  - tick: 10\n    ticks: 5\n  - tick: 12\n",
+		"short window":   "steps:\n  - tick: 1\n    ticks: 2\n    expect: [{command: deploy, within: 5}]\n",
+		"bad indent":     "name: x\n  steps: []\n",
+		"duplicate key":  "name: a\nname: b\n",
+		"alias":          "setup: &s [base]\nsteps:\n  - tick: 1\n    do: *s\n",
+		"tag":            "name: !!binary aGk=\nsteps:\n  - tick: 1\n",
+		"two documents":  "steps:\n  - tick: 1\n---\nsteps:\n  - tick: 2\n",
+		"non-string key": "steps:\n  - tick: 1\n    do:\n      - unit: {1: e1}\n",
+	} {
+		if _, err := ParseScenario([]byte(src)); err == nil {
+			t.Errorf("%s: accepted %q", name, src)
+		}
+	}
+}
+
+func TestScenarioExpectationFailure(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+name: camo pillbox that can't be built
+faction: england
+setup:
+  - base
+  - queue: {type: Defense, buildable: [pbox]}
+steps:
+  - tick: 900
+    do:
+      - enemy_army: {count: 6, type: e1, x: 22, y: 16}
+    expect:
+      - {command: produce, with: {item: hbox}, within: 3}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, failures, err := RunScenario(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0].Detail, "not sent in ticks 900-903") ||
+		!strings.Contains(failures[0].Detail, `"item":"pbox"`) {
+		t.Errorf("failures = %v, want the camo pillbox missing and the pillbox sent instead", failures)
+	}
+}
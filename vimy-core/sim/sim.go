@@ -0,0 +1,117 @@
+// Package sim replays recorded game states through the rule engine offline
+// and records the commands it would have sent to the game, so rule changes
+// can be checked without launching OpenRA.
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/model"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+// TickResult is the commands issued for one replayed state: counted by
+// message type, and in full in the order they were sent.
+type TickResult struct {
+	Tick     int
+	Commands map[string]int
+	Sent     []ipc.Envelope
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	Ticks      []TickResult
+	Totals     map[string]int
+	Violations []Violation
+}
+
+// barrierType is sent after each evaluation so the replay knows every
+// command for that tick has been read back.
+const barrierType = "sim_barrier"
+
+// ReadStates decodes a stream of JSON game states — newline-delimited or
+// simply concatenated, as captured from the mod's game_state envelopes.
+func ReadStates(r io.Reader) ([]model.GameState, error) {
+	dec := json.NewDecoder(r)
+	var states []model.GameState
+	for {
+		var gs model.GameState
+		err := dec.Decode(&gs)
+		if errors.Is(err, io.EOF) {
+			return states, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode state %d: %w", len(states)+1, err)
+		}
+		states = append(states, gs)
+	}
+}
+
+// Run evaluates each state on engine in order, as the agent would on
+// consecutive game_state messages, and tallies the commands sent. Each
+// command is checked against the given invariants on the state it was
+// issued for; breaches are reported, not returned as errors.
+func Run(engine *rules.Engine, faction string, states []model.GameState, invariants ...Invariant) (Report, error) {
+	server, client := net.Pipe()
+	defer server.Close()
+	conn := ipc.NewConnection(server, nil)
+
+	type read struct {
+		env ipc.Envelope
+		err error
+	}
+	envs := make(chan read)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer client.Close()
+		for {
+			env, err := ipc.ReadEnvelope(client)
+			select {
+			case envs <- read{env, err}:
+			case <-stop:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	report := Report{Totals: make(map[string]int)}
+	for _, gs := range states {
+		res := TickResult{Tick: gs.Tick, Commands: make(map[string]int)}
+		evalErr := make(chan error, 1)
+		go func() {
+			err := engine.Evaluate(gs, faction, conn)
+			if sendErr := conn.Send(barrierType, struct{}{}); err == nil {
+				err = sendErr
+			}
+			evalErr <- err
+		}()
+		for done := false; !done; {
+			r := <-envs
+			if r.err != nil {
+				return report, fmt.Errorf("read command at tick %d: %w", gs.Tick, r.err)
+			}
+			if r.env.Type == barrierType {
+				done = true
+				continue
+			}
+			res.Commands[r.env.Type]++
+			res.Sent = append(res.Sent, r.env)
+			report.Totals[r.env.Type]++
+			report.Violations = append(report.Violations, check(invariants, gs, r.env)...)
+		}
+		if err := <-evalErr; err != nil {
+			return report, fmt.Errorf("evaluate tick %d: %w", gs.Tick, err)
+		}
+		report.Ticks = append(report.Ticks, res)
+	}
+	return report, nil
+}
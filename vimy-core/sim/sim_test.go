@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nstehr/vimy/vimy-core/fixtures"
+	"github.com/nstehr/vimy/vimy-core/ipc"
+	"github.com/nstehr/vimy/vimy-core/rules"
+)
+
+func TestRunReplaysStates(t *testing.T) {
+	states, err := ReadStates(strings.NewReader(`
+{"tick": 1, "mapWidth": 64, "mapHeight": 64, "units": [{"id": 1, "type": "mcv", "x": 10, "y": 10, "idle": true}]}
+{"tick": 2, "mapWidth": 64, "mapHeight": 64, "buildings": [{"id": 2, "type": "fact", "x": 10, "y": 10}]}
+`))
+	if err != nil {
+		t.Fatalf("ReadStates: %v", err)
+	}
+	if len(states) != 2 {
+		t.Fatalf("read %d states, want 2", len(states))
+	}
+
+	engine, err := rules.NewEngine(rules.DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	report, err := Run(engine, "soviet", states)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Ticks) != 2 {
+		t.Fatalf("report has %d ticks, want 2", len(report.Ticks))
+	}
+	if got := report.Ticks[0].Commands[ipc.TypeDeploy]; got != 1 {
+		t.Errorf("tick 1 deploy commands = %d, want 1", got)
+	}
+	if got := report.Ticks[1].Commands[ipc.TypeDeploy]; got != 0 {
+		t.Errorf("tick 2 deploy commands = %d, want 0", got)
+	}
+	if report.Totals[ipc.TypeDeploy] != 1 {
+		t.Errorf("total deploys = %d, want 1", report.Totals[ipc.TypeDeploy])
+	}
+}
+
+func TestReadStatesRejectsGarbage(t *testing.T) {
+	if _, err := ReadStates(strings.NewReader(`{"tick": 1} not-json`)); err == nil {
+		t.Error("expected a decode error")
+	}
+}
+
+func TestRunFixtureScenario(t *testing.T) {
+	states := fixtures.NewStateBuilder().
+		WithUnit("mcv", 10, 10, true).
+		Ticks(3)
+
+	engine, err := rules.NewEngine(rules.DefaultRules())
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	report, err := Run(engine, "soviet", states)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(report.Ticks) != 3 {
+		t.Fatalf("report has %d ticks, want 3", len(report.Ticks))
+	}
+	if got := report.Ticks[0].Commands[ipc.TypeDeploy]; got != 1 {
+		t.Errorf("tick 1 deploy commands = %d, want 1", got)
+	}
+}
@@ -0,0 +1,63 @@
+package sim
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario files are YAML, read with yaml.v3 and turned into a Scenario by
+// round-tripping the document through JSON, so the struct tags and
+// ScenarioAction's decoding are shared with JSON scenarios. Anchors,
+// aliases, explicit tags and multiple documents have no JSON equivalent
+// and are rejected rather than flattened.
+
+// parseYAML reads one YAML document into what encoding/json would give:
+// map[string]any, []any, numbers, bool, string or nil.
+func parseYAML(data []byte) (any, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var next yaml.Node
+	if err := dec.Decode(&next); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("line %d: a scenario file holds one YAML document", next.Line)
+	}
+	if err := checkYAMLNode(&doc); err != nil {
+		return nil, err
+	}
+	var v any
+	if err := doc.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// checkYAMLNode rejects the YAML a JSON document can't express.
+func checkYAMLNode(n *yaml.Node) error {
+	switch {
+	case n.Kind == yaml.AliasNode || n.Anchor != "":
+		return fmt.Errorf("line %d: anchors and aliases aren't supported in scenarios", n.Line)
+	case n.Tag != "" && n.Style&yaml.TaggedStyle != 0:
+		return fmt.Errorf("line %d: explicit tag %s isn't supported in scenarios", n.Line, n.Tag)
+	}
+	for i, c := range n.Content {
+		if n.Kind == yaml.MappingNode && i%2 == 0 && (c.Kind != yaml.ScalarNode || c.Tag != "!!str") {
+			return fmt.Errorf("line %d: mapping keys must be strings", c.Line)
+		}
+		if err := checkYAMLNode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}